@@ -2,39 +2,288 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
-	"github.com/mark3labs/mcp-go/server"
-
+	"github.com/kagent-dev/meta-kagent/internal/ci"
+	"github.com/kagent-dev/meta-kagent/internal/config"
+	"github.com/kagent-dev/meta-kagent/internal/elevation"
+	"github.com/kagent-dev/meta-kagent/internal/i18n"
 	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
 	mcpserver "github.com/kagent-dev/meta-kagent/internal/server"
+	"github.com/kagent-dev/meta-kagent/internal/signing"
+	"github.com/kagent-dev/meta-kagent/internal/stats"
+	"github.com/kagent-dev/meta-kagent/internal/tenancy"
 	"github.com/kagent-dev/meta-kagent/internal/tools"
+	"github.com/kagent-dev/meta-kagent/pkg/toolpack"
 )
 
 func main() {
-	// Get namespace from environment or default to "kagent"
-	namespace := os.Getenv("KAGENT_NAMESPACE")
-	if namespace == "" {
-		namespace = "kagent"
+	// Load configuration from environment, then apply flag overrides
+	cfg := config.Load()
+	flag.BoolVar(&cfg.StrictPreflight, "strict-preflight", cfg.StrictPreflight, "Refuse to start if any preflight check fails")
+	flag.StringVar(&cfg.Mode, "mode", cfg.Mode, "What the server may do to the cluster: apply, propose or readonly")
+	flag.StringVar(&cfg.Transport, "transport", cfg.Transport, "MCP transport: stdio or http")
+	flag.StringVar(&cfg.ListenAddress, "listen-address", cfg.ListenAddress, "Address the http transport listens on")
+	elevationRequest := flag.String("elevation-token", "", "Print the approval token of an elevation request and exit")
+	signManifest := flag.String("sign-manifest", "", "Print the digest and signature of a manifest file ('-' for stdin) and exit")
+	ciDir := flag.String("ci", "", "Check the manifests in a directory (or file) with the server's validation and security review, print the findings and exit non-zero when they fail the build")
+	ciFormat := flag.String("ci-format", defaultCIFormat(), "Format of --ci findings: text, github (workflow command annotations) or junit (JUnit XML)")
+	ciFailOn := flag.String("ci-fail-on", ci.SeverityError, "Lowest severity of --ci findings that fails the build: error or warning")
+	ciStrict := flag.Bool("ci-strict", true, "Validate --ci manifests in strict mode, as validate_manifest does by default")
+	ciCheckToolNames := flag.Bool("ci-check-tool-names", false, "Check the tool names --ci agents reference against their MCP servers' tool lists")
+	flag.Parse()
+
+	// Approve elevation requests out-of-band: operators run this next to
+	// the server, which shares the secret, and pass the token on
+	if *elevationRequest != "" {
+		if cfg.ElevationSecret == "" {
+			fmt.Fprintf(os.Stderr, "KAGENT_ELEVATION_SECRET is not set\n")
+			os.Exit(1)
+		}
+		fmt.Println(elevation.Token(cfg.ElevationSecret, *elevationRequest))
+		return
+	}
+
+	// Sign reviewed manifests out-of-band, the same way: approvers run this
+	// with the signing key and pass the signature to apply_manifest
+	if *signManifest != "" {
+		if cfg.SigningKey == "" {
+			fmt.Fprintf(os.Stderr, "KAGENT_SIGNING_KEY is not set\n")
+			os.Exit(1)
+		}
+		var (
+			manifest []byte
+			err      error
+		)
+		if *signManifest == "-" {
+			manifest, err = io.ReadAll(os.Stdin)
+		} else {
+			manifest, err = os.ReadFile(*signManifest)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read manifest: %v\n", err)
+			os.Exit(1)
+		}
+		digest := signing.Digest(string(manifest))
+		fmt.Printf("digest: %s\nsignature: %s\n", digest, signing.Sign(cfg.SigningKey, digest))
+		return
+	}
+
+	// Check manifests for CI pipelines with the same checks as the tools,
+	// against the cluster the kubeconfig points at
+	if *ciDir != "" {
+		os.Exit(runCI(cfg, *ciDir, *ciFormat, *ciFailOn, *ciStrict, *ciCheckToolNames))
+	}
+
+	if cfg.Transport != mcpserver.TransportStdio && cfg.Transport != mcpserver.TransportHTTP {
+		fmt.Fprintf(os.Stderr, "Invalid transport %q: must be %s or %s\n", cfg.Transport, mcpserver.TransportStdio, mcpserver.TransportHTTP)
+		os.Exit(1)
+	}
+	if cfg.Mode != tools.ModeApply && cfg.Mode != tools.ModePropose && cfg.Mode != tools.ModeReadOnly {
+		fmt.Fprintf(os.Stderr, "Invalid mode %q: must be %s, %s or %s\n", cfg.Mode, tools.ModeApply, tools.ModePropose, tools.ModeReadOnly)
+		os.Exit(1)
+	}
+
+	// Without tenants, HTTP clients are not authenticated, so only a
+	// read-only server may be reached that way
+	if cfg.Transport == mcpserver.TransportHTTP && cfg.TenantsFile == "" {
+		if cfg.Mode != tools.ModeReadOnly {
+			fmt.Fprintf(os.Stderr, "The http transport needs KAGENT_TENANTS_FILE in %s mode: without tenants, any client that reaches %s could change the cluster\n", cfg.Mode, cfg.ListenAddress)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "WARNING: serving HTTP on %s without tenants; clients are not authenticated\n", cfg.ListenAddress)
 	}
 
 	// Initialize Kubernetes client
-	k8sClient, err := kubernetes.NewClient(namespace)
+	k8sClient, err := kubernetes.NewClient(cfg.Namespace)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create Kubernetes client: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Run preflight checks before serving
+	if err := runPreflight(k8sClient, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Preflight failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Simulate API failures for resilience testing; after preflight, which
+	// should see the real cluster
+	faults := kubernetes.Faults{
+		NotFound: cfg.FaultNotFound,
+		Conflict: cfg.FaultConflict,
+		Timeout:  cfg.FaultTimeout,
+		Seed:     int64(cfg.FaultSeed),
+	}
+	if !faults.IsZero() {
+		fmt.Fprintf(os.Stderr, "WARNING: injecting Kubernetes API faults (%s); do not use in production\n", faults)
+		k8sClient, err = k8sClient.WithFaults(faults)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to enable fault injection: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Serve reads of kagent resources from informers kept up to date by
+	// watches; reads fall back to the API server until the cache has synced
+	if cfg.InformerCache {
+		cache := kubernetes.NewInformerCache(k8sClient, kubernetes.CachedGVRs...)
+		go func() {
+			if err := cache.Run(context.Background()); err != nil {
+				fmt.Fprintf(os.Stderr, "Informer cache stopped: %v\n", err)
+			}
+		}()
+		k8sClient = k8sClient.WithInformerCache(cache)
+	}
+
+	// Record resource count snapshots in the background; a read-only
+	// server writes nothing to the cluster, so it records none
+	if cfg.StatsInterval > 0 && cfg.Mode != tools.ModeReadOnly {
+		store := stats.NewStore(k8sClient, cfg.StatsConfigMap, cfg.StatsRetention)
+		go store.Run(context.Background(), cfg.StatsInterval)
+	}
+
 	// Create MCP server
-	s := mcpserver.New(k8sClient)
+	s := mcpserver.New(k8sClient, cfg)
+
+	// Bind authenticated clients to their tenant's identity and namespace
+	if cfg.TenantsFile != "" {
+		registry, err := tenancy.Load(cfg.TenantsFile, k8sClient)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load tenants: %v\n", err)
+			os.Exit(1)
+		}
+		s.SetTenants(registry)
+	}
+
+	// Translate tool descriptions and results for non-English teams
+	catalog, err := i18n.Load(cfg.Locale, cfg.MessageCatalogs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load message catalogs: %v\n", err)
+		os.Exit(1)
+	}
+	s.SetCatalog(catalog)
 
 	// Register all tools
 	tools.RegisterAll(s)
 
-	// Start server with stdio transport
-	if err := server.ServeStdio(s.MCPServer()); err != nil {
+	// Register tool packs compiled in or loaded as plugins
+	if err := registerPacks(s, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to register tool packs: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Apply the config ConfigMap and keep watching it (and SIGHUP) so
+	// reloadable settings change without dropping client sessions
+	if err := s.ReloadConfig(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration from ConfigMap: %v\n", err)
+	}
+	go s.WatchConfig(context.Background())
+
+	// Start server with the configured transport
+	if cfg.Transport == mcpserver.TransportHTTP {
+		err = s.ListenAndServe(cfg.ListenAddress, cfg.BaseURL)
+	} else {
+		if cfg.TenantsFile != "" {
+			fmt.Fprintf(os.Stderr, "Tenants are ignored with the stdio transport: clients are not authenticated\n")
+		}
+		err = s.ServeStdio()
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// defaultCIFormat annotates pull requests when running in GitHub Actions.
+func defaultCIFormat() string {
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		return ci.FormatGitHub
+	}
+	return ci.FormatText
+}
+
+// runCI checks the manifests under dir, writes the findings to stdout and
+// returns the exit code: 1 when the findings fail the build, 2 when the
+// checks could not run.
+func runCI(cfg *config.Config, dir, format, failOn string, strict, checkToolNames bool) int {
+	if !slices.Contains(ci.Formats, format) {
+		fmt.Fprintf(os.Stderr, "Invalid --ci-format %q: must be one of %s\n", format, strings.Join(ci.Formats, ", "))
+		return 2
+	}
+	if failOn != ci.SeverityError && failOn != ci.SeverityWarning {
+		fmt.Fprintf(os.Stderr, "Invalid --ci-fail-on %q: must be %s or %s\n", failOn, ci.SeverityError, ci.SeverityWarning)
+		return 2
+	}
+
+	docs, err := ci.LoadDir(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+	if len(docs) == 0 {
+		fmt.Fprintf(os.Stderr, "No manifests found in %s\n", dir)
+		return 2
+	}
+
+	k8sClient, err := kubernetes.NewClient(cfg.Namespace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create Kubernetes client: %v\n", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	report := tools.CheckDocuments(ctx, mcpserver.New(k8sClient, cfg), docs, strict, checkToolNames)
+	if err := report.Write(os.Stdout, format); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+	if report.Failed(failOn) {
+		return 1
+	}
+	return 0
+}
+
+// runPreflight logs the preflight report to stderr and, in strict mode,
+// returns an error if any check failed.
+func runPreflight(k8sClient *kubernetes.Client, cfg *config.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	report := k8sClient.Preflight(ctx, tools.PreflightOptions(cfg))
+
+	output, _ := json.Marshal(report)
+	fmt.Fprintf(os.Stderr, "Preflight report: %s\n", output)
+
+	if cfg.StrictPreflight && !report.Passed() {
+		return fmt.Errorf("%d check(s) failed in strict mode", len(report.Failures()))
+	}
+	return nil
+}
+
+// registerPacks registers the build-time tool packs and the plugin
+// executables listed in the configuration.
+func registerPacks(s *mcpserver.Server, cfg *config.Config) error {
+	ctx := context.Background()
+
+	packs := toolpack.Packs()
+	for _, path := range cfg.Plugins {
+		pack, err := toolpack.NewExecPack(ctx, path, cfg.PluginTimeout)
+		if err != nil {
+			return err
+		}
+		packs = append(packs, pack)
+	}
+
+	return tools.RegisterPacks(ctx, s, packs)
+}
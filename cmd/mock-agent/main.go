@@ -0,0 +1,35 @@
+// Package main provides the entry point for the mock A2A agent deployed by
+// the deploy_mock_agent tool. It ships in the kmeta-agent image.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kagent-dev/meta-kagent/internal/mockagent"
+)
+
+func main() {
+	listenAddress := flag.String("listen-address", ":8080", "Address the agent listens on")
+	flag.Parse()
+
+	cfg, err := mockagent.ConfigFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	server := &http.Server{
+		Addr:              *listenAddress,
+		Handler:           mockagent.New(cfg).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	fmt.Fprintf(os.Stderr, "Mock agent '%s' (%s mode, %d skill(s)) listening on %s\n", cfg.Name, cfg.Mode, len(cfg.Skills), *listenAddress)
+	if err := server.ListenAndServe(); err != nil {
+		fmt.Fprintf(os.Stderr, "Mock agent stopped: %v\n", err)
+		os.Exit(1)
+	}
+}
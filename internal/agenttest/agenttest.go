@@ -0,0 +1,370 @@
+// Package agenttest runs declarative test cases against a deployed agent
+// over A2A: each case sends a prompt and checks the reply against expected
+// content and, optionally, a natural-language description of the expected
+// behavior judged by an LLM.
+package agenttest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DataKey is the ConfigMap key holding the test suite.
+const DataKey = "tests.yaml"
+
+// Result statuses.
+const (
+	StatusPass  = "pass"
+	StatusFail  = "fail"
+	StatusError = "error"
+)
+
+// maxReply bounds the reply text kept in results.
+const maxReply = 2000
+
+// pollInterval is how often a task that is still running is polled.
+const pollInterval = 2 * time.Second
+
+// Case is a prompt and the behavior expected in reply.
+type Case struct {
+	Name   string      `json:"name"`
+	Input  string      `json:"input"`
+	Expect Expectation `json:"expect"`
+}
+
+// Expectation describes an acceptable reply. Every set field must hold.
+type Expectation struct {
+	// Contains lists substrings the reply must include (case-insensitive).
+	Contains []string `json:"contains,omitempty"`
+	// NotContains lists substrings the reply must not include (case-insensitive).
+	NotContains []string `json:"notContains,omitempty"`
+	// Matches is a regular expression the reply must match.
+	Matches string `json:"matches,omitempty"`
+	// Behavior describes the expected reply in natural language. It is
+	// judged by an LLM when one is available.
+	Behavior string `json:"behavior,omitempty"`
+}
+
+// Suite is the set of test cases attached to an agent.
+type Suite struct {
+	Tests []Case `json:"tests"`
+}
+
+// Validate reports cases that cannot be run.
+func (s *Suite) Validate() error {
+	if len(s.Tests) == 0 {
+		return fmt.Errorf("suite has no tests")
+	}
+	seen := map[string]bool{}
+	for i, c := range s.Tests {
+		switch {
+		case c.Name == "":
+			return fmt.Errorf("tests[%d]: name is required", i)
+		case seen[c.Name]:
+			return fmt.Errorf("tests[%d]: duplicate name '%s'", i, c.Name)
+		case strings.TrimSpace(c.Input) == "":
+			return fmt.Errorf("test '%s': input is required", c.Name)
+		case len(c.Expect.Contains) == 0 && len(c.Expect.NotContains) == 0 && c.Expect.Matches == "" && c.Expect.Behavior == "":
+			return fmt.Errorf("test '%s': expect must set at least one of contains, notContains, matches or behavior", c.Name)
+		}
+		if c.Expect.Matches != "" {
+			if _, err := regexp.Compile(c.Expect.Matches); err != nil {
+				return fmt.Errorf("test '%s': invalid matches pattern: %w", c.Name, err)
+			}
+		}
+		seen[c.Name] = true
+	}
+	return nil
+}
+
+// Parse reads and validates a suite from ConfigMap data.
+func Parse(data map[string]string) (*Suite, error) {
+	raw, ok := data[DataKey]
+	if !ok {
+		return nil, fmt.Errorf("missing key %s", DataKey)
+	}
+	var suite Suite
+	if err := yaml.UnmarshalStrict([]byte(raw), &suite); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", DataKey, err)
+	}
+	if err := suite.Validate(); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+// Marshal renders a suite as ConfigMap data.
+func (s *Suite) Marshal() (map[string]string, error) {
+	raw, err := yaml.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{DataKey: string(raw)}, nil
+}
+
+// Judge decides whether reply to input shows the described behavior. It
+// returns the verdict and a short reason.
+type Judge func(ctx context.Context, behavior, input, reply string) (bool, string, error)
+
+// Options controls a run.
+type Options struct {
+	// Timeout bounds each case, including waiting for the agent's reply.
+	Timeout time.Duration
+	// Judge evaluates behavior expectations. When nil they are not checked
+	// and reported as a note.
+	Judge Judge
+	// Only limits the run to the named cases.
+	Only []string
+}
+
+// Result is the outcome of one case.
+type Result struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Failures []string `json:"failures,omitempty"`
+	Notes    []string `json:"notes,omitempty"`
+	Reply    string   `json:"reply,omitempty"`
+	Duration string   `json:"duration"`
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Endpoint string   `json:"endpoint"`
+	Passed   int      `json:"passed"`
+	Failed   int      `json:"failed"`
+	Errors   int      `json:"errors"`
+	Results  []Result `json:"results"`
+}
+
+// Run sends every case to the agent's A2A endpoint in order and checks the
+// replies.
+func Run(ctx context.Context, client *http.Client, endpoint string, suite *Suite, opts Options) *Report {
+	report := &Report{Endpoint: endpoint}
+	only := map[string]bool{}
+	for _, name := range opts.Only {
+		only[name] = true
+	}
+
+	for _, c := range suite.Tests {
+		if len(only) > 0 && !only[c.Name] {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		start := time.Now()
+		result := runCase(ctx, client, endpoint, c, opts)
+		result.Duration = time.Since(start).Round(time.Millisecond).String()
+
+		switch result.Status {
+		case StatusPass:
+			report.Passed++
+		case StatusFail:
+			report.Failed++
+		default:
+			report.Errors++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+func runCase(ctx context.Context, client *http.Client, endpoint string, c Case, opts Options) Result {
+	result := Result{Name: c.Name}
+
+	caseCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	reply, err := Send(caseCtx, client, endpoint, c.Input)
+	if err != nil {
+		result.Status = StatusError
+		result.Failures = []string{err.Error()}
+		return result
+	}
+	result.Reply = reply
+	if len(result.Reply) > maxReply {
+		result.Reply = result.Reply[:maxReply] + "..."
+	}
+
+	lower := strings.ToLower(reply)
+	for _, s := range c.Expect.Contains {
+		if !strings.Contains(lower, strings.ToLower(s)) {
+			result.Failures = append(result.Failures, fmt.Sprintf("reply does not contain %q", s))
+		}
+	}
+	for _, s := range c.Expect.NotContains {
+		if strings.Contains(lower, strings.ToLower(s)) {
+			result.Failures = append(result.Failures, fmt.Sprintf("reply contains %q", s))
+		}
+	}
+	if c.Expect.Matches != "" {
+		if !regexp.MustCompile(c.Expect.Matches).MatchString(reply) {
+			result.Failures = append(result.Failures, fmt.Sprintf("reply does not match /%s/", c.Expect.Matches))
+		}
+	}
+
+	if c.Expect.Behavior != "" {
+		if opts.Judge == nil {
+			result.Notes = append(result.Notes, "behavior not judged: no LLM available")
+		} else {
+			ok, reason, err := opts.Judge(caseCtx, c.Expect.Behavior, c.Input, reply)
+			switch {
+			case err != nil:
+				result.Notes = append(result.Notes, fmt.Sprintf("behavior not judged: %v", err))
+			case !ok:
+				result.Failures = append(result.Failures, "behavior: "+reason)
+			default:
+				result.Notes = append(result.Notes, "behavior: "+reason)
+			}
+		}
+	}
+
+	result.Status = StatusPass
+	if len(result.Failures) > 0 {
+		result.Status = StatusFail
+	}
+	return result
+}
+
+// Send sends text to the agent with message/send and returns the text of
+// its reply, polling tasks/get until a returned task finishes.
+func Send(ctx context.Context, client *http.Client, endpoint, text string) (string, error) {
+	result, err := call(ctx, client, endpoint, "message/send", map[string]interface{}{
+		"message": map[string]interface{}{
+			"kind":      "message",
+			"role":      "user",
+			"messageId": newID(),
+			"parts":     []interface{}{map[string]interface{}{"kind": "text", "text": text}},
+		},
+		"configuration": map[string]interface{}{"blocking": true},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		switch result["kind"] {
+		case "message":
+			return partsText(result["parts"]), nil
+		case "task":
+		default:
+			return "", fmt.Errorf("message/send returned kind %v, want 'task' or 'message'", result["kind"])
+		}
+
+		status, _ := result["status"].(map[string]interface{})
+		switch state, _ := status["state"].(string); state {
+		case "completed":
+			return taskText(result), nil
+		case "failed", "rejected", "canceled":
+			return "", fmt.Errorf("task %s: %s", state, taskText(result))
+		case "input-required", "auth-required":
+			return "", fmt.Errorf("task is %s: %s", state, taskText(result))
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("no reply before the timeout: %w", ctx.Err())
+		case <-time.After(pollInterval):
+		}
+		id, _ := result["id"].(string)
+		if result, err = call(ctx, client, endpoint, "tasks/get", map[string]interface{}{"id": id}); err != nil {
+			return "", err
+		}
+	}
+}
+
+// call sends a JSON-RPC request and returns its result object.
+func call(ctx context.Context, client *http.Client, endpoint, method string, params interface{}) (map[string]interface{}, error) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      newID(),
+		"method":  method,
+		"params":  params,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", endpoint, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read response: %w", method, err)
+	}
+
+	var rpc struct {
+		Result map[string]interface{} `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &rpc); err != nil {
+		return nil, fmt.Errorf("%s: response is not JSON-RPC (HTTP %d)", method, resp.StatusCode)
+	}
+	if rpc.Error != nil {
+		return nil, fmt.Errorf("%s returned error %d: %s", method, rpc.Error.Code, rpc.Error.Message)
+	}
+	if rpc.Result == nil {
+		return nil, fmt.Errorf("%s: response has no result", method)
+	}
+	return rpc.Result, nil
+}
+
+// taskText returns the text of a task's artifacts, falling back to its
+// status message.
+func taskText(task map[string]interface{}) string {
+	var texts []string
+	artifacts, _ := task["artifacts"].([]interface{})
+	for _, a := range artifacts {
+		artifact, _ := a.(map[string]interface{})
+		if text := partsText(artifact["parts"]); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	if len(texts) > 0 {
+		return strings.Join(texts, "\n")
+	}
+
+	status, _ := task["status"].(map[string]interface{})
+	message, _ := status["message"].(map[string]interface{})
+	return partsText(message["parts"])
+}
+
+// partsText concatenates the text parts of a message or artifact.
+func partsText(parts interface{}) string {
+	list, _ := parts.([]interface{})
+	var texts []string
+	for _, p := range list {
+		part, _ := p.(map[string]interface{})
+		if text, _ := part["text"].(string); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,114 @@
+// Package archive stores the manifests of retired agents so they can be
+// restored later.
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// Entry is an archived agent.
+type Entry struct {
+	Name        string    `json:"name"`
+	Namespace   string    `json:"namespace"`
+	Description string    `json:"description,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	ArchivedAt  time.Time `json:"archivedAt"`
+	Manifest    string    `json:"manifest"`
+}
+
+// Store persists archived agents to a ConfigMap, one data key per agent.
+type Store struct {
+	k8sClient     *kubernetes.Client
+	configMapName string
+}
+
+// NewStore creates an archive store backed by the named ConfigMap.
+func NewStore(k8sClient *kubernetes.Client, configMapName string) *Store {
+	return &Store{
+		k8sClient:     k8sClient,
+		configMapName: configMapName,
+	}
+}
+
+// List returns all archived agents, most recently archived first.
+func (s *Store) List(ctx context.Context) ([]Entry, error) {
+	data, _, err := s.k8sClient.GetConfigMapData(ctx, s.configMapName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(data))
+	for key, raw := range data {
+		var entry Entry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode archive entry %s: %w", key, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ArchivedAt.After(entries[j].ArchivedAt)
+	})
+	return entries, nil
+}
+
+// Get returns the archived agent with the given name.
+func (s *Store) Get(ctx context.Context, name string) (*Entry, bool, error) {
+	data, _, err := s.k8sClient.GetConfigMapData(ctx, s.configMapName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	raw, ok := data[dataKey(name)]
+	if !ok {
+		return nil, false, nil
+	}
+
+	var entry Entry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		return nil, false, fmt.Errorf("failed to decode archive entry %s: %w", name, err)
+	}
+	return &entry, true, nil
+}
+
+// Add records an archived agent, replacing any earlier archive of the same
+// name.
+func (s *Store) Add(ctx context.Context, entry Entry) error {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode archive entry: %w", err)
+	}
+
+	return s.update(ctx, func(data map[string]string) {
+		data[dataKey(entry.Name)] = string(encoded)
+	})
+}
+
+// Remove deletes an archived agent.
+func (s *Store) Remove(ctx context.Context, name string) error {
+	return s.update(ctx, func(data map[string]string) {
+		delete(data, dataKey(name))
+	})
+}
+
+func (s *Store) update(ctx context.Context, mutate func(data map[string]string)) error {
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "kmeta-agent",
+		"app.kubernetes.io/component":  "archive",
+	}
+	return s.k8sClient.UpdateConfigMapData(ctx, s.configMapName, labels, func(data map[string]string) error {
+		mutate(data)
+		return nil
+	})
+}
+
+// dataKey returns the ConfigMap data key holding an archived agent.
+func dataKey(name string) string {
+	return name + ".json"
+}
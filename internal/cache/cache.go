@@ -0,0 +1,107 @@
+// Package cache memoizes derived tool results, keyed by the resourceVersions
+// of the resources they were computed from.
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Cache is a bounded LRU of results. An entry is only returned while the
+// fingerprint of its inputs is unchanged, so any change to an underlying
+// resource invalidates it.
+type Cache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type entry struct {
+	key         string
+	fingerprint string
+	value       string
+}
+
+// New creates a cache holding at most max entries. A cache with max <= 0
+// stores nothing.
+func New(max int) *Cache {
+	return &Cache{
+		max:     max,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored under key if it was computed from inputs
+// with the same fingerprint.
+func (c *Cache) Get(key, fingerprint string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	e := el.Value.(*entry)
+	if e.fingerprint != fingerprint {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// Put stores value under key, evicting the least recently used entry when
+// the cache is full.
+func (c *Cache) Put(key, fingerprint, value string) {
+	if c.max <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = &entry{key: key, fingerprint: fingerprint, value: value}
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, fingerprint: fingerprint, value: value})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Fingerprint identifies a set of resources by kind, namespace, name and
+// resourceVersion. Adding, removing or changing any of them changes it.
+func Fingerprint(objs []unstructured.Unstructured) string {
+	ids := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		ids = append(ids, obj.GetAPIVersion()+"/"+obj.GetKind()+"/"+obj.GetNamespace()+"/"+obj.GetName()+"@"+obj.GetResourceVersion())
+	}
+	sort.Strings(ids)
+
+	h := sha256.New()
+	for _, id := range ids {
+		h.Write([]byte(id))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,144 @@
+// Package capabilities detects the add-ons a cluster runs, such as
+// cert-manager or the Prometheus Operator, by the kinds their CRDs serve.
+// Tools generating resources of those kinds are only offered where the
+// resources can run.
+package capabilities
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// Names of the known capabilities.
+const (
+	CertManager           = "cert-manager"
+	GatewayAPI            = "gateway-api"
+	PrometheusOperator    = "prometheus-operator"
+	OpenTelemetryOperator = "opentelemetry-operator"
+	ExternalSecrets       = "external-secrets"
+)
+
+// Capability is an add-on, detected by a kind it serves.
+type Capability struct {
+	Name        string
+	Description string
+	Kind        schema.GroupKind
+	// Install points at the add-on's installation instructions.
+	Install string
+}
+
+// Known are the capabilities Detect probes for.
+var Known = []Capability{
+	{CertManager, "cert-manager, issuing TLS certificates", schema.GroupKind{Group: "cert-manager.io", Kind: "Certificate"}, "https://cert-manager.io/docs/installation/"},
+	{GatewayAPI, "Gateway API routes", schema.GroupKind{Group: "gateway.networking.k8s.io", Kind: "HTTPRoute"}, "https://gateway-api.sigs.k8s.io/guides/"},
+	{PrometheusOperator, "Prometheus Operator alerting rules", schema.GroupKind{Group: "monitoring.coreos.com", Kind: "PrometheusRule"}, "https://prometheus-operator.dev/docs/getting-started/installation/"},
+	{OpenTelemetryOperator, "OpenTelemetry Operator collectors and instrumentation", schema.GroupKind{Group: "opentelemetry.io", Kind: "OpenTelemetryCollector"}, "https://opentelemetry.io/docs/kubernetes/operator/"},
+	{ExternalSecrets, "External Secrets Operator", schema.GroupKind{Group: "external-secrets.io", Kind: "ExternalSecret"}, "https://external-secrets.io/latest/introduction/getting-started/"},
+}
+
+// Lookup returns the known capability with the given name.
+func Lookup(name string) (Capability, bool) {
+	for _, c := range Known {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Capability{}, false
+}
+
+// Status is what detection found out about a capability.
+type Status struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Available bool   `json:"available"`
+	// Source is how availability was decided: detected, assumed (from
+	// configuration) or unknown (detection failed; assumed available).
+	Source string `json:"source"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Set is the result of a detection.
+type Set struct {
+	Statuses   []Status  `json:"capabilities"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// Detect probes the cluster for every known capability. Capabilities
+// named in assumed are taken as available without probing, and "all"
+// assumes every one. A capability whose probe fails is assumed available,
+// so that a flaky API server does not hide tools.
+func Detect(ctx context.Context, client *kubernetes.Client, assumed []string) *Set {
+	set := &Set{DetectedAt: time.Now()}
+	for _, c := range Known {
+		status := Status{Name: c.Name, Kind: c.Kind.String()}
+		switch {
+		case contains(assumed, c.Name) || contains(assumed, "all"):
+			status.Available, status.Source = true, "assumed"
+		default:
+			served, err := client.ServesKind(ctx, c.Kind)
+			status.Available, status.Source = served, "detected"
+			if err != nil {
+				status.Available, status.Source, status.Error = true, "unknown", err.Error()
+			}
+		}
+		set.Statuses = append(set.Statuses, status)
+	}
+	return set
+}
+
+// Has reports whether the named capability is available.
+func (s *Set) Has(name string) bool {
+	for _, status := range s.Statuses {
+		if status.Name == name {
+			return status.Available
+		}
+	}
+	return false
+}
+
+// Missing returns those of names that are not available, sorted.
+func (s *Set) Missing(names []string) []string {
+	var missing []string
+	for _, name := range names {
+		if !s.Has(name) {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// CheckNames returns an error naming the entries of names that are not
+// known capabilities.
+func CheckNames(names []string) error {
+	var unknown []string
+	for _, name := range names {
+		if _, ok := Lookup(name); !ok && name != "all" {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	known := make([]string, 0, len(Known))
+	for _, c := range Known {
+		known = append(known, c.Name)
+	}
+	return fmt.Errorf("unknown capabilities %s; known: %s, all", strings.Join(unknown, ", "), strings.Join(known, ", "))
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+// Package ci runs the server's manifest checks outside of an MCP session,
+// for CI pipelines: it loads the manifests of a directory, collects the
+// findings of the checks, and reports them as plain text, GitHub Actions
+// workflow commands or JUnit XML.
+package ci
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Severities of findings.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Document is one YAML document of a manifest file.
+type Document struct {
+	File string
+	// Line is the 1-based line of the file the document starts at.
+	Line    int
+	Content string
+}
+
+// Finding is one problem a check found in a document.
+type Finding struct {
+	// Document is the index of the document in the checked list.
+	Document int    `json:"-"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Resource string `json:"resource"`
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// manifestExtensions are the file extensions loaded from a directory.
+var manifestExtensions = map[string]bool{".yaml": true, ".yml": true}
+
+// LoadDir reads the YAML manifests under dir, recursively and in path
+// order, skipping hidden files and directories. A path to a single file is
+// accepted too.
+func LoadDir(dir string) ([]Document, error) {
+	var docs []Document
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || (path != dir && !manifestExtensions[strings.ToLower(filepath.Ext(path))]) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, SplitDocuments(path, string(data))...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifests: %w", err)
+	}
+	return docs, nil
+}
+
+// SplitDocuments splits a file on "---" separators as
+// kubernetes.SplitManifests does, keeping the line each document starts
+// at.
+func SplitDocuments(file, content string) []Document {
+	var docs []Document
+	var current []string
+	start := 1
+	flush := func(next int) {
+		lines := current
+		first := start
+		current, start = nil, next
+		// Report the first line with content, not the separator's blank
+		// or comment lines
+		for len(lines) > 0 {
+			trimmed := strings.TrimSpace(lines[0])
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				break
+			}
+			lines, first = lines[1:], first+1
+		}
+		if len(lines) > 0 {
+			docs = append(docs, Document{File: file, Line: first, Content: strings.TrimSpace(strings.Join(lines, "\n")) + "\n"})
+		}
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		if strings.TrimRight(line, " \t\r") == "---" {
+			flush(i + 2)
+			continue
+		}
+		current = append(current, line)
+	}
+	flush(0)
+	return docs
+}
+
+// Report is the outcome of checking a set of documents.
+type Report struct {
+	Documents []Document
+	// Checks are the names of the checks that ran, whether or not they
+	// found anything.
+	Checks   []string
+	Findings []Finding
+}
+
+// Sort orders findings by file, line and check, keeping the order of the
+// findings of one check on one document.
+func (r *Report) Sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Check < b.Check
+	})
+}
+
+// Count returns the number of findings with the given severity.
+func (r *Report) Count(severity string) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed reports whether the report fails the build: with errors, or with
+// warnings too when failOn is SeverityWarning.
+func (r *Report) Failed(failOn string) bool {
+	if r.Count(SeverityError) > 0 {
+		return true
+	}
+	return failOn == SeverityWarning && r.Count(SeverityWarning) > 0
+}
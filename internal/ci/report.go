@@ -0,0 +1,182 @@
+package ci
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats.
+const (
+	FormatText   = "text"
+	FormatGitHub = "github"
+	FormatJUnit  = "junit"
+)
+
+// Formats lists the output formats.
+var Formats = []string{FormatText, FormatGitHub, FormatJUnit}
+
+// Write renders the report in the given format.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText:
+		return r.writeText(w)
+	case FormatGitHub:
+		return r.writeGitHub(w)
+	case FormatJUnit:
+		return r.writeJUnit(w)
+	}
+	return fmt.Errorf("unknown format %q: must be one of %s", format, strings.Join(Formats, ", "))
+}
+
+func (r *Report) summary() string {
+	return fmt.Sprintf("%d document(s) checked: %d error(s), %d warning(s)", len(r.Documents), r.Count(SeverityError), r.Count(SeverityWarning))
+}
+
+// where locates a finding for people reading a log.
+func (f Finding) where() string {
+	where := fmt.Sprintf("%s:%d", f.File, f.Line)
+	if f.Resource != "" {
+		where += " " + f.Resource
+	}
+	if f.Field != "" {
+		where += " " + f.Field
+	}
+	return where
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	for _, f := range r.Findings {
+		prefix := "⚠️  WARNING"
+		if f.Severity == SeverityError {
+			prefix = "❌ ERROR"
+		}
+		if _, err := fmt.Fprintf(w, "%s [%s] %s: %s\n", prefix, f.Check, f.where(), f.Message); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, r.summary())
+	return err
+}
+
+// writeGitHub emits GitHub Actions workflow commands, which the runner
+// turns into annotations on the files and lines of the pull request.
+func (r *Report) writeGitHub(w io.Writer) error {
+	for _, f := range r.Findings {
+		title := f.Check
+		if f.Resource != "" {
+			title += ": " + f.Resource
+		}
+		message := f.Message
+		if f.Field != "" {
+			message = f.Field + ": " + message
+		}
+		if _, err := fmt.Fprintf(w, "::%s file=%s,line=%d,title=%s::%s\n", f.Severity, escapeProperty(f.File), f.Line, escapeProperty(title), escapeData(message)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, r.summary())
+	return err
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      int           `xml:"line,attr"`
+	Failures  []junitResult `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit emits one test suite per check with one test case per
+// document: errors are failures, warnings are written to the case's output.
+func (r *Report) writeJUnit(w io.Writer) error {
+	byCheck := map[string]map[int][]Finding{}
+	for _, check := range r.Checks {
+		byCheck[check] = map[int][]Finding{}
+	}
+	for _, f := range r.Findings {
+		if _, ok := byCheck[f.Check]; ok {
+			byCheck[f.Check][f.Document] = append(byCheck[f.Check][f.Document], f)
+		}
+	}
+
+	var suites junitSuites
+	for _, check := range r.Checks {
+		suite := junitSuite{Name: check}
+		for i, doc := range r.Documents {
+			c := junitCase{
+				Name:      fmt.Sprintf("%s:%d", doc.File, doc.Line),
+				Classname: check,
+				File:      doc.File,
+				Line:      doc.Line,
+			}
+			var out []string
+			for _, f := range byCheck[check][i] {
+				if f.Resource != "" {
+					c.Name = fmt.Sprintf("%s (%s:%d)", f.Resource, doc.File, doc.Line)
+				}
+				text := f.Message
+				if f.Field != "" {
+					text = f.Field + ": " + text
+				}
+				if f.Severity == SeverityError {
+					c.Failures = append(c.Failures, junitResult{Message: text, Type: f.Severity, Text: f.where() + ": " + f.Message})
+				} else {
+					out = append(out, "warning: "+text)
+				}
+			}
+			c.SystemOut = strings.Join(out, "\n")
+			suite.Tests++
+			if len(c.Failures) > 0 {
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
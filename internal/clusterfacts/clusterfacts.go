@@ -0,0 +1,305 @@
+// Package clusterfacts discovers facts about the cluster the server
+// manages: its name, cloud provider, region and ingress domain. Generated
+// prompts, manifests and endpoint URLs use them as template variables, so
+// the same template adapts to every cluster it is rendered in.
+package clusterfacts
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// Placeholders of cluster facts in templates.
+const (
+	PlaceholderCluster       = "{{cluster}}"
+	PlaceholderCloud         = "{{cloud}}"
+	PlaceholderRegion        = "{{region}}"
+	PlaceholderIngressDomain = "{{ingress_domain}}"
+)
+
+// refreshInterval is how long discovered facts are reused.
+const refreshInterval = 10 * time.Minute
+
+// Facts are the facts known about a cluster. Unknown facts are empty.
+type Facts struct {
+	ClusterName   string `json:"clusterName,omitempty"`
+	CloudProvider string `json:"cloudProvider,omitempty"`
+	Region        string `json:"region,omitempty"`
+	IngressDomain string `json:"ingressDomain,omitempty"`
+	// Sources tells where each known fact came from, by placeholder.
+	Sources map[string]string `json:"sources,omitempty"`
+	// Notes explain why facts could not be discovered.
+	Notes        []string  `json:"notes,omitempty"`
+	DiscoveredAt time.Time `json:"discoveredAt"`
+}
+
+// fact describes one fact: its placeholder, the variable that overrides
+// it and where it is stored.
+type fact struct {
+	placeholder string
+	env         string
+	value       func(*Facts) *string
+}
+
+var facts = []fact{
+	{PlaceholderCluster, "KAGENT_CLUSTER_NAME", func(f *Facts) *string { return &f.ClusterName }},
+	{PlaceholderCloud, "KAGENT_CLOUD_PROVIDER", func(f *Facts) *string { return &f.CloudProvider }},
+	{PlaceholderRegion, "KAGENT_CLUSTER_REGION", func(f *Facts) *string { return &f.Region }},
+	{PlaceholderIngressDomain, "KAGENT_INGRESS_DOMAIN", func(f *Facts) *string { return &f.IngressDomain }},
+}
+
+// Uses reports whether s contains a cluster fact placeholder.
+func Uses(s string) bool {
+	for _, f := range facts {
+		if strings.Contains(s, f.placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sample replaces the cluster fact placeholders in s with sample values,
+// to check that a template produces valid output in any cluster.
+func Sample(s string) string {
+	return strings.NewReplacer(
+		PlaceholderCluster, "cluster",
+		PlaceholderCloud, "cloud",
+		PlaceholderRegion, "region",
+		PlaceholderIngressDomain, "apps.example.com",
+	).Replace(s)
+}
+
+// Expand replaces the cluster fact placeholders in s. It fails when s uses
+// a fact that is not known, naming the variable that sets it.
+func (f *Facts) Expand(s string) (string, error) {
+	var pairs, unknown []string
+	for _, fc := range facts {
+		if !strings.Contains(s, fc.placeholder) {
+			continue
+		}
+		value := *fc.value(f)
+		if value == "" {
+			unknown = append(unknown, fmt.Sprintf("%s (set %s)", fc.placeholder, fc.env))
+			continue
+		}
+		pairs = append(pairs, fc.placeholder, value)
+	}
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown cluster facts: %s", strings.Join(unknown, ", "))
+	}
+	if len(pairs) == 0 {
+		return s, nil
+	}
+	return strings.NewReplacer(pairs...).Replace(s), nil
+}
+
+// Provider discovers the facts of a cluster and reuses them for a while.
+type Provider struct {
+	client    *kubernetes.Client
+	overrides Facts
+
+	mu    sync.Mutex
+	facts *Facts
+}
+
+// NewProvider returns a provider of the facts of the client's cluster.
+// Facts set in overrides are used as given instead of discovered.
+func NewProvider(client *kubernetes.Client, overrides Facts) *Provider {
+	return &Provider{client: client, overrides: overrides}
+}
+
+// Get returns the cluster's facts, discovering them again when refresh is
+// set or they are older than refreshInterval.
+func (p *Provider) Get(ctx context.Context, refresh bool) *Facts {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.facts == nil || refresh || time.Since(p.facts.DiscoveredAt) > refreshInterval {
+		p.facts = Discover(ctx, p.client, p.overrides)
+	}
+	return p.facts
+}
+
+// Discover finds the facts of the client's cluster. Facts set in
+// overrides win. The rest come from the nodes' provider IDs and labels,
+// the kubeconfig context, OpenShift's ingress configuration and the hosts
+// of the Ingresses in the client's namespace.
+func Discover(ctx context.Context, client *kubernetes.Client, overrides Facts) *Facts {
+	f := &Facts{Sources: map[string]string{}, DiscoveredAt: time.Now()}
+	set := func(placeholder, value, source string) {
+		for _, fc := range facts {
+			if fc.placeholder == placeholder && value != "" && *fc.value(f) == "" {
+				*fc.value(f) = value
+				f.Sources[placeholder] = source
+			}
+		}
+	}
+	for _, fc := range facts {
+		set(fc.placeholder, *fc.value(&overrides), fc.env)
+	}
+	if client == nil {
+		return f
+	}
+
+	nodes, known, err := client.NodeInfos(ctx)
+	switch {
+	case err != nil:
+		f.Notes = append(f.Notes, err.Error())
+	case !known:
+		f.Notes = append(f.Notes, "not allowed to list nodes (needs a ClusterRole); cloud provider and region are unknown")
+	}
+	for _, node := range nodes {
+		cloud, cluster := parseProviderID(node.ProviderID)
+		set(PlaceholderCloud, cloud, "node provider IDs")
+		set(PlaceholderCluster, cluster, "node provider IDs")
+		set(PlaceholderRegion, firstLabel(node.Labels, regionLabels), "node labels")
+		set(PlaceholderCluster, firstLabel(node.Labels, clusterNameLabels), "node labels")
+	}
+
+	if contextCluster := client.ContextCluster(); contextCluster != "" {
+		cloud, cluster, region := parseContextCluster(contextCluster)
+		set(PlaceholderCloud, cloud, "kubeconfig context")
+		set(PlaceholderRegion, region, "kubeconfig context")
+		set(PlaceholderCluster, cluster, "kubeconfig context")
+	}
+
+	if f.IngressDomain == "" {
+		domain, _, err := client.ClusterIngressDomain(ctx)
+		if err != nil {
+			f.Notes = append(f.Notes, err.Error())
+		}
+		set(PlaceholderIngressDomain, domain, "OpenShift ingress configuration")
+	}
+	if f.IngressDomain == "" {
+		hosts, known, err := client.IngressHosts(ctx)
+		switch {
+		case err != nil:
+			f.Notes = append(f.Notes, err.Error())
+		case !known:
+			f.Notes = append(f.Notes, "not allowed to list Ingresses; ingress domain is unknown")
+		}
+		set(PlaceholderIngressDomain, commonDomain(hosts), fmt.Sprintf("Ingress hosts in %s", client.Namespace()))
+	}
+	return f
+}
+
+// providerClouds renames provider ID schemes that differ from the cloud's
+// usual name.
+var providerClouds = map[string]string{
+	"gce":      "gcp",
+	"oci":      "oracle",
+	"hcloud":   "hetzner",
+	"alicloud": "alibaba",
+}
+
+// regionLabels are the node labels holding the region, the deprecated one
+// last.
+var regionLabels = []string{
+	"topology.kubernetes.io/region",
+	"failure-domain.beta.kubernetes.io/region",
+}
+
+// clusterNameLabels are the node labels some installers set to the
+// cluster's name.
+var clusterNameLabels = []string{
+	"alpha.eksctl.io/cluster-name",
+	"cluster.x-k8s.io/cluster-name",
+}
+
+// parseProviderID returns the cloud of a node's provider ID, e.g. aws for
+// aws:///us-east-1a/i-0abc, and the cluster's name when the ID holds it,
+// as kind's kind://docker/<cluster>/<node> does.
+func parseProviderID(providerID string) (cloud, cluster string) {
+	u, err := url.Parse(providerID)
+	if err != nil || u.Scheme == "" {
+		return "", ""
+	}
+	cloud = u.Scheme
+	if renamed, ok := providerClouds[cloud]; ok {
+		cloud = renamed
+	}
+	if cloud == "kind" {
+		if parts := strings.Split(strings.Trim(u.Path, "/"), "/"); len(parts) == 2 {
+			cluster = parts[0]
+		}
+	}
+	return cloud, cluster
+}
+
+// parseContextCluster reads the facts managed clusters encode in the
+// cluster names their CLIs write to kubeconfig: EKS's
+// arn:aws:eks:<region>:<account>:cluster/<name> and GKE's
+// gke_<project>_<location>_<name>. Other names are the cluster's name.
+func parseContextCluster(name string) (cloud, cluster, region string) {
+	if rest, ok := strings.CutPrefix(name, "arn:aws:eks:"); ok {
+		parts := strings.SplitN(rest, ":", 3)
+		if len(parts) == 3 {
+			if clusterName, ok := strings.CutPrefix(parts[2], "cluster/"); ok {
+				return "aws", clusterName, parts[0]
+			}
+		}
+	}
+	if rest, ok := strings.CutPrefix(name, "gke_"); ok {
+		parts := strings.SplitN(rest, "_", 3)
+		if len(parts) == 3 {
+			return "gcp", parts[2], gkeRegion(parts[1])
+		}
+	}
+	return "", name, ""
+}
+
+// gkeRegion returns the region of a GKE location, which is either a region
+// (us-central1) or a zone (us-central1-a).
+func gkeRegion(location string) string {
+	if parts := strings.Split(location, "-"); len(parts) == 3 && len(parts[2]) == 1 {
+		return parts[0] + "-" + parts[1]
+	}
+	return location
+}
+
+// firstLabel returns the value of the first of keys labels has.
+func firstLabel(labels map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value := labels[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// commonDomain returns the parent domain most of hosts share, e.g.
+// apps.example.com for a.apps.example.com and b.apps.example.com. Hosts
+// under a top-level domain only give no domain.
+func commonDomain(hosts []string) string {
+	counts := map[string]int{}
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+		// A wildcard host names the domain itself
+		if domain, ok := strings.CutPrefix(host, "*."); ok {
+			host = "_." + domain
+		}
+		if _, parent, ok := strings.Cut(host, "."); ok && strings.Contains(parent, ".") {
+			counts[parent]++
+		}
+	}
+	domains := make([]string, 0, len(counts))
+	for domain := range counts {
+		domains = append(domains, domain)
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		if counts[domains[i]] != counts[domains[j]] {
+			return counts[domains[i]] > counts[domains[j]]
+		}
+		return domains[i] < domains[j]
+	})
+	if len(domains) == 0 {
+		return ""
+	}
+	return domains[0]
+}
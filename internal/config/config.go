@@ -0,0 +1,365 @@
+// Package config provides the runtime configuration for the meta-agent server.
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the server configuration, loaded from environment variables
+// and optionally overridden by command-line flags.
+type Config struct {
+	// Namespace is the namespace the server manages.
+	Namespace string
+	// ControllerName is the name of the kagent controller Deployment.
+	ControllerName string
+	// ControllerNamespace is the namespace of the kagent controller Deployment.
+	ControllerNamespace string
+	// StrictPreflight refuses to start the server when a preflight check fails.
+	StrictPreflight bool
+	// Mode is what the server may do to the cluster: "apply" changes it,
+	// "propose" records changes for an operator to apply, "readonly" offers
+	// no tools that change it.
+	Mode string
+
+	// Transport is how clients reach the server: "stdio" or "http".
+	Transport string
+	// ListenAddress is the address the HTTP transport listens on.
+	ListenAddress string
+	// BaseURL is the externally reachable URL of the HTTP transport,
+	// advertised to clients for posting messages (empty: relative path).
+	BaseURL string
+
+	// StatsConfigMap is the ConfigMap where resource count snapshots are stored.
+	StatsConfigMap string
+	// StatsInterval is how often resource counts are recorded (0 disables recording).
+	StatsInterval time.Duration
+	// StatsRetention is the maximum number of snapshots kept.
+	StatsRetention int
+
+	// RevisionsConfigMap is the ConfigMap where agent revisions are stored.
+	RevisionsConfigMap string
+	// RevisionsRetention is the maximum number of revisions kept per agent.
+	RevisionsRetention int
+
+	// ArchiveConfigMap is the ConfigMap where archived agents are stored.
+	ArchiveConfigMap string
+
+	// JobWorkers is the number of background jobs run concurrently.
+	JobWorkers int
+	// JobTimeout bounds how long a background job may run.
+	JobTimeout time.Duration
+
+	// ListPageSize is how many items list tools return per page unless the
+	// call passes a limit.
+	ListPageSize int
+
+	// SchedulesFile lists read-only tools to run on cron schedules (empty
+	// disables the scheduler).
+	SchedulesFile string
+	// ScheduleConfigMap is the ConfigMap where the last result of each
+	// schedule is stored.
+	ScheduleConfigMap string
+	// ScheduleWebhook receives a JSON notification for scheduled runs, as
+	// each schedule's notify policy asks.
+	ScheduleWebhook string
+
+	// CacheEntries bounds the number of cached derived tool results
+	// (0 disables caching).
+	CacheEntries int
+	// ToolSchemaTTL is how long tool listings fetched from deployed MCP
+	// servers are reused before the server is asked again.
+	ToolSchemaTTL time.Duration
+	// InformerCache serves reads of kagent resources in the server's
+	// namespace from watch-backed informers instead of listing per call.
+	InformerCache bool
+
+	// PrometheusURL is the Prometheus server SLO compliance is measured
+	// against (empty disables check_slo_compliance).
+	PrometheusURL string
+	// SLOAvailabilityQuery and SLOLatencyQuery override the PromQL templates
+	// used to measure agent availability and latency.
+	SLOAvailabilityQuery string
+	SLOLatencyQuery      string
+
+	// ApplyAllowedKinds lists the core kinds (beyond kagent kinds) that
+	// apply_manifest may apply, e.g. ServiceAccount, Role, RoleBinding.
+	ApplyAllowedKinds []string
+	// AllowedImageRegistries lists the registries security_review accepts
+	// images from (empty allows any registry).
+	AllowedImageRegistries []string
+	// CRDSchemas lists CustomResourceDefinition manifests, or directories
+	// of them, whose schemas validate_manifest checks resources against
+	// instead of those the cluster publishes.
+	CRDSchemas []string
+	// EnforcedRules lists the validation rules, by name or prefix, whose
+	// issues block apply_manifest and import_resources.
+	EnforcedRules []string
+	// PolicyEnforcement is "enforce" to block on enforced rules, or
+	// "audit" to only report what would be blocked.
+	PolicyEnforcement string
+	// AssumeCapabilities lists cluster capabilities (cert-manager,
+	// prometheus-operator, ...) taken as present without detecting them,
+	// or "all".
+	AssumeCapabilities []string
+	// ValidationRules sets the severity of validation rules, as
+	// "<rule>=error|warning|off"; a bare rule name turns it off.
+	ValidationRules []string
+	// ValidationPoliciesFile declares the policies validate_manifest
+	// checks resources against besides its built-in rules.
+	ValidationPoliciesFile string
+	// EnvironmentsFile lists the environments agents are served in, with
+	// the URL template of their A2A endpoints and the domains allowed there.
+	EnvironmentsFile string
+	// Environment is the environment this server manages, overriding the
+	// file's default.
+	Environment string
+	// ClusterName, CloudProvider, ClusterRegion and IngressDomain set the
+	// cluster facts templates use, instead of discovering them.
+	ClusterName   string
+	CloudProvider string
+	ClusterRegion string
+	IngressDomain string
+	// MockAgentImage is the image deploy_mock_agent runs: the kmeta-agent
+	// image, which ships the mock agent next to the server.
+	MockAgentImage string
+
+	// Plugins lists executables loaded as tool packs at startup.
+	Plugins []string
+	// PluginTimeout bounds each invocation of a plugin executable.
+	PluginTimeout time.Duration
+
+	// SamplingMode selects how tools get LLM assistance: "client" asks the
+	// connected MCP client through sampling, "off" disables it.
+	SamplingMode string
+
+	// Locale is the language of tool descriptions and results, e.g. "de"
+	// or "pt-BR". English is built in; other locales need a catalog.
+	Locale string
+	// MessageCatalogs lists message catalog files, or directories of them,
+	// translating the server's text.
+	MessageCatalogs []string
+
+	// DisabledTools lists tools that are not offered to clients.
+	DisabledTools []string
+
+	// ElevationSecret, when set, makes sessions read-only: tools that change
+	// the cluster require an elevation approved with a token derived from
+	// this secret.
+	ElevationSecret string
+	// MaxElevation bounds how long an approved elevation lasts.
+	MaxElevation time.Duration
+
+	// SigningKey verifies the signatures approvers put on reviewed
+	// manifests; apply_manifest rejects a signature that does not match.
+	SigningKey string
+	// RequireSignature makes apply_manifest refuse manifests without a
+	// valid signature, except for dry runs.
+	RequireSignature bool
+
+	// StateStore is where stateful tools keep diff IDs, elevations and
+	// pending changes: "memory" (lost on restart), "configmap" or "file".
+	StateStore string
+	// StateConfigMap is the ConfigMap the "configmap" state store uses.
+	StateConfigMap string
+	// StateDir is the directory the "file" state store uses, typically a
+	// mounted PersistentVolumeClaim.
+	StateDir string
+
+	// TenantsFile lists the tenants of a shared server. When set, clients
+	// authenticated by an HTTP transport act as their tenant's ServiceAccount
+	// in their tenant's namespace.
+	TenantsFile string
+
+	// ConfigMap is the ConfigMap whose data overrides the reloadable
+	// settings. Keys are the environment variable names.
+	ConfigMap string
+
+	// FaultNotFound, FaultConflict and FaultTimeout are the probabilities
+	// (0 to 1) of failing Kubernetes API requests with simulated errors, for
+	// integration tests and demos. FaultSeed makes the failures
+	// reproducible.
+	FaultNotFound float64
+	FaultConflict float64
+	FaultTimeout  float64
+	FaultSeed     int
+}
+
+// reloadableKeys are the settings a running server picks up on reload.
+// Everything else is read once at startup.
+var reloadableKeys = []string{
+	"KAGENT_APPLY_ALLOWED_KINDS",
+	"KAGENT_DISABLED_TOOLS",
+	"KAGENT_SAMPLING",
+	"KAGENT_VALIDATION_RULES",
+	"KAGENT_ENFORCED_RULES",
+	"KAGENT_POLICY_ENFORCEMENT",
+}
+
+// IsReloadable reports whether the setting with the given environment
+// variable name is applied on reload.
+func IsReloadable(key string) bool {
+	for _, k := range reloadableKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads the configuration from the environment, applying defaults.
+func Load() *Config {
+	return load(os.Getenv)
+}
+
+// Reload returns a copy of c with the reloadable settings read from
+// overrides, falling back to the environment for keys it does not set.
+func (c *Config) Reload(overrides map[string]string) *Config {
+	fresh := load(func(key string) string {
+		if v, ok := overrides[key]; ok {
+			return v
+		}
+		return os.Getenv(key)
+	})
+
+	next := *c
+	next.ApplyAllowedKinds = fresh.ApplyAllowedKinds
+	next.DisabledTools = fresh.DisabledTools
+	next.SamplingMode = fresh.SamplingMode
+	next.ValidationRules = fresh.ValidationRules
+	next.EnforcedRules = fresh.EnforcedRules
+	next.PolicyEnforcement = fresh.PolicyEnforcement
+	return &next
+}
+
+// ToolEnabled reports whether the named tool is offered to clients.
+func (c *Config) ToolEnabled(name string) bool {
+	for _, disabled := range c.DisabledTools {
+		if disabled == name {
+			return false
+		}
+	}
+	return true
+}
+
+func load(env lookupFunc) *Config {
+	namespace := env.get("KAGENT_NAMESPACE", "kagent")
+
+	return &Config{
+		Namespace:              namespace,
+		ControllerName:         env.get("KAGENT_CONTROLLER_NAME", "kagent-controller"),
+		ControllerNamespace:    env.get("KAGENT_CONTROLLER_NAMESPACE", namespace),
+		StrictPreflight:        env.boolean("KAGENT_STRICT_PREFLIGHT", false),
+		Mode:                   env.get("KAGENT_MCP_MODE", "apply"),
+		Transport:              env.get("KAGENT_MCP_TRANSPORT", "stdio"),
+		ListenAddress:          env.get("KAGENT_MCP_LISTEN_ADDRESS", "127.0.0.1:8080"),
+		BaseURL:                env.get("KAGENT_MCP_BASE_URL", ""),
+		StatsConfigMap:         env.get("KAGENT_STATS_CONFIGMAP", "kmeta-agent-stats"),
+		StatsInterval:          env.duration("KAGENT_STATS_INTERVAL", time.Hour),
+		StatsRetention:         env.integer("KAGENT_STATS_RETENTION", 720),
+		RevisionsConfigMap:     env.get("KAGENT_REVISIONS_CONFIGMAP", "kmeta-agent-revisions"),
+		RevisionsRetention:     env.integer("KAGENT_REVISIONS_RETENTION", 20),
+		ArchiveConfigMap:       env.get("KAGENT_ARCHIVE_CONFIGMAP", "kmeta-agent-archive"),
+		JobWorkers:             env.integer("KAGENT_JOB_WORKERS", 2),
+		JobTimeout:             env.duration("KAGENT_JOB_TIMEOUT", 10*time.Minute),
+		ListPageSize:           env.integer("KAGENT_LIST_PAGE_SIZE", 50),
+		SchedulesFile:          env.get("KAGENT_SCHEDULES_FILE", ""),
+		ScheduleConfigMap:      env.get("KAGENT_SCHEDULE_CONFIGMAP", "kmeta-agent-schedules"),
+		ScheduleWebhook:        env.get("KAGENT_SCHEDULE_WEBHOOK", ""),
+		CacheEntries:           env.integer("KAGENT_CACHE_ENTRIES", 256),
+		ToolSchemaTTL:          env.duration("KAGENT_TOOL_SCHEMA_TTL", 15*time.Minute),
+		InformerCache:          env.boolean("KAGENT_INFORMER_CACHE", true),
+		PrometheusURL:          env.get("KAGENT_PROMETHEUS_URL", ""),
+		SLOAvailabilityQuery:   env.get("KAGENT_SLO_AVAILABILITY_QUERY", ""),
+		SLOLatencyQuery:        env.get("KAGENT_SLO_LATENCY_QUERY", ""),
+		ApplyAllowedKinds:      env.list("KAGENT_APPLY_ALLOWED_KINDS"),
+		AllowedImageRegistries: env.list("KAGENT_ALLOWED_IMAGE_REGISTRIES"),
+		CRDSchemas:             env.list("KAGENT_CRD_SCHEMAS"),
+		ValidationRules:        env.list("KAGENT_VALIDATION_RULES"),
+		AssumeCapabilities:     env.list("KAGENT_ASSUME_CAPABILITIES"),
+		EnforcedRules:          env.list("KAGENT_ENFORCED_RULES"),
+		PolicyEnforcement:      env.get("KAGENT_POLICY_ENFORCEMENT", "enforce"),
+		ValidationPoliciesFile: env.get("KAGENT_VALIDATION_POLICIES_FILE", ""),
+		EnvironmentsFile:       env.get("KAGENT_ENVIRONMENTS_FILE", ""),
+		Environment:            env.get("KAGENT_ENVIRONMENT", ""),
+		ClusterName:            env.get("KAGENT_CLUSTER_NAME", ""),
+		CloudProvider:          env.get("KAGENT_CLOUD_PROVIDER", ""),
+		ClusterRegion:          env.get("KAGENT_CLUSTER_REGION", ""),
+		IngressDomain:          env.get("KAGENT_INGRESS_DOMAIN", ""),
+		MockAgentImage:         env.get("KAGENT_MOCK_AGENT_IMAGE", ""),
+		Plugins:                env.list("KAGENT_PLUGINS"),
+		PluginTimeout:          env.duration("KAGENT_PLUGIN_TIMEOUT", 30*time.Second),
+		SamplingMode:           env.get("KAGENT_SAMPLING", "client"),
+		Locale:                 env.get("KAGENT_LOCALE", "en"),
+		MessageCatalogs:        env.list("KAGENT_MESSAGE_CATALOGS"),
+		DisabledTools:          env.list("KAGENT_DISABLED_TOOLS"),
+		ConfigMap:              env.get("KAGENT_CONFIGMAP", "kmeta-agent-config"),
+		TenantsFile:            env.get("KAGENT_TENANTS_FILE", ""),
+		ElevationSecret:        env.get("KAGENT_ELEVATION_SECRET", ""),
+		MaxElevation:           env.duration("KAGENT_MAX_ELEVATION", time.Hour),
+		SigningKey:             env.get("KAGENT_SIGNING_KEY", ""),
+		RequireSignature:       env.boolean("KAGENT_REQUIRE_SIGNATURE", false),
+		StateStore:             env.get("KAGENT_STATE_STORE", "memory"),
+		StateConfigMap:         env.get("KAGENT_STATE_CONFIGMAP", "kmeta-agent-state"),
+		StateDir:               env.get("KAGENT_STATE_DIR", "/var/lib/kmeta-agent"),
+		FaultNotFound:          env.probability("KAGENT_FAULT_NOTFOUND"),
+		FaultConflict:          env.probability("KAGENT_FAULT_CONFLICT"),
+		FaultTimeout:           env.probability("KAGENT_FAULT_TIMEOUT"),
+		FaultSeed:              env.integer("KAGENT_FAULT_SEED", 0),
+	}
+}
+
+// lookupFunc returns the raw value of a setting, or "" when it is unset.
+type lookupFunc func(key string) string
+
+func (l lookupFunc) get(key, defaultValue string) string {
+	if v := l(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+func (l lookupFunc) boolean(key string, defaultValue bool) bool {
+	v, err := strconv.ParseBool(l(key))
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
+
+func (l lookupFunc) list(key string) []string {
+	var values []string
+	for _, v := range strings.Split(l(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func (l lookupFunc) integer(key string, defaultValue int) int {
+	v, err := strconv.Atoi(l(key))
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
+
+func (l lookupFunc) duration(key string, defaultValue time.Duration) time.Duration {
+	v, err := time.ParseDuration(l(key))
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
+
+// probability reads a value between 0 and 1; anything else disables it.
+func (l lookupFunc) probability(key string) float64 {
+	v, err := strconv.ParseFloat(l(key), 64)
+	if err != nil || v < 0 || v > 1 {
+		return 0
+	}
+	return v
+}
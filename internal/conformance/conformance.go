@@ -0,0 +1,451 @@
+// Package conformance checks a deployed agent's A2A endpoint against the
+// protocol: Agent Card discovery, the JSON-RPC task lifecycle, and error
+// handling.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Check statuses.
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Check categories.
+const (
+	CategoryDiscovery = "discovery"
+	CategoryLifecycle = "lifecycle"
+	CategoryErrors    = "errors"
+)
+
+// JSON-RPC and A2A error codes the suite expects.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeTaskNotFound   = -32001
+	codeNotCancelable  = -32002
+)
+
+// cardPaths are the well-known Agent Card locations, current first.
+var cardPaths = []string{"/.well-known/agent-card.json", "/.well-known/agent.json"}
+
+// taskStates are the task states defined by the protocol.
+var taskStates = map[string]bool{
+	"submitted": true, "working": true, "input-required": true, "completed": true,
+	"canceled": true, "failed": true, "rejected": true, "auth-required": true, "unknown": true,
+}
+
+// terminalStates are the task states a task cannot leave.
+var terminalStates = map[string]bool{"completed": true, "canceled": true, "failed": true, "rejected": true}
+
+// Options configures a conformance run.
+type Options struct {
+	// Lifecycle sends a real message to the agent. It costs a model call.
+	Lifecycle bool
+	// Message is the text sent by the lifecycle checks.
+	Message string
+	// RequestTimeout bounds each request except message/send.
+	RequestTimeout time.Duration
+	// SendTimeout bounds message/send, which waits for the model.
+	SendTimeout time.Duration
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	ID       string `json:"id"`
+	Category string `json:"category"`
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+}
+
+// Report aggregates the results of a conformance run.
+type Report struct {
+	Endpoint string   `json:"endpoint"`
+	CardURL  string   `json:"cardUrl,omitempty"`
+	RPCURL   string   `json:"rpcUrl,omitempty"`
+	Passed   int      `json:"passed"`
+	Failed   int      `json:"failed"`
+	Skipped  int      `json:"skipped"`
+	Results  []Result `json:"results"`
+}
+
+// Conformant reports whether no check failed.
+func (r *Report) Conformant() bool {
+	return r.Failed == 0
+}
+
+func (r *Report) add(id, category, status, format string, args ...interface{}) {
+	r.Results = append(r.Results, Result{
+		ID:       id,
+		Category: category,
+		Status:   status,
+		Message:  fmt.Sprintf(format, args...),
+	})
+	switch status {
+	case StatusPass:
+		r.Passed++
+	case StatusFail:
+		r.Failed++
+	default:
+		r.Skipped++
+	}
+}
+
+// skipRest records the remaining checks of a category as skipped.
+func (r *Report) skipRest(category, reason string, ids ...string) {
+	for _, id := range ids {
+		r.add(id, category, StatusSkip, "%s", reason)
+	}
+}
+
+// runner holds the state shared by the checks of one run.
+type runner struct {
+	client *http.Client
+	opts   Options
+	report *Report
+	rpcURL string
+}
+
+// Run exercises the A2A endpoint and returns the report. Checks that depend
+// on an earlier failed check are skipped rather than failed.
+func Run(ctx context.Context, client *http.Client, endpoint string, opts Options) *Report {
+	r := &runner{
+		client: client,
+		opts:   opts,
+		report: &Report{Endpoint: endpoint},
+	}
+
+	card := r.checkDiscovery(ctx, strings.TrimSuffix(endpoint, "/"))
+	if card == nil {
+		r.report.skipRest(CategoryLifecycle, "no Agent Card", "message_send", "tasks_get", "tasks_cancel")
+		r.report.skipRest(CategoryErrors, "no Agent Card", "parse_error", "method_not_found", "invalid_params", "task_not_found")
+		return r.report
+	}
+
+	r.rpcURL = endpoint
+	if url, _ := card["url"].(string); url != "" {
+		r.rpcURL = url
+	}
+	r.report.RPCURL = r.rpcURL
+
+	r.checkLifecycle(ctx)
+	r.checkErrors(ctx)
+	return r.report
+}
+
+// checkDiscovery fetches the Agent Card and checks its required fields. It
+// returns nil when no card could be read.
+func (r *runner) checkDiscovery(ctx context.Context, endpoint string) map[string]interface{} {
+	var card map[string]interface{}
+	var lastErr error
+	for _, path := range cardPaths {
+		url := endpoint + path
+		body, status, err := r.do(ctx, http.MethodGet, url, nil, r.opts.RequestTimeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if status != http.StatusOK {
+			lastErr = fmt.Errorf("GET %s returned %d", url, status)
+			continue
+		}
+		if err := json.Unmarshal(body, &card); err != nil {
+			r.report.add("card_fetch", CategoryDiscovery, StatusFail, "%s is not valid JSON: %v", url, err)
+			r.report.skipRest(CategoryDiscovery, "no Agent Card", "card_fields", "card_skills")
+			return nil
+		}
+		r.report.CardURL = url
+		r.report.add("card_fetch", CategoryDiscovery, StatusPass, "Agent Card served at %s", url)
+		break
+	}
+	if card == nil {
+		r.report.add("card_fetch", CategoryDiscovery, StatusFail, "no Agent Card at %s: %v", strings.Join(cardPaths, " or "), lastErr)
+		r.report.skipRest(CategoryDiscovery, "no Agent Card", "card_fields", "card_skills")
+		return nil
+	}
+
+	var missing []string
+	for _, field := range []string{"name", "description", "url", "version", "capabilities", "defaultInputModes", "defaultOutputModes", "skills"} {
+		if _, ok := card[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		r.report.add("card_fields", CategoryDiscovery, StatusFail, "Agent Card is missing required fields: %s", strings.Join(missing, ", "))
+	} else {
+		r.report.add("card_fields", CategoryDiscovery, StatusPass, "Agent Card has all required fields")
+	}
+
+	skills, _ := card["skills"].([]interface{})
+	var problems []string
+	for i, s := range skills {
+		skill, ok := s.(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("skills[%d] is not an object", i))
+			continue
+		}
+		for _, field := range []string{"id", "name", "description", "tags"} {
+			if _, ok := skill[field]; !ok {
+				problems = append(problems, fmt.Sprintf("skills[%d] is missing %s", i, field))
+			}
+		}
+	}
+	switch {
+	case len(problems) > 0:
+		r.report.add("card_skills", CategoryDiscovery, StatusFail, "%s", strings.Join(problems, "; "))
+	case len(skills) == 0:
+		r.report.add("card_skills", CategoryDiscovery, StatusPass, "Agent Card declares no skills")
+	default:
+		r.report.add("card_skills", CategoryDiscovery, StatusPass, "%d skill(s) well-formed", len(skills))
+	}
+
+	return card
+}
+
+// checkLifecycle sends a message and follows the resulting task through
+// tasks/get and tasks/cancel.
+func (r *runner) checkLifecycle(ctx context.Context) {
+	if !r.opts.Lifecycle {
+		r.report.skipRest(CategoryLifecycle, "lifecycle checks disabled", "message_send", "tasks_get", "tasks_cancel")
+		return
+	}
+
+	params := map[string]interface{}{
+		"message": map[string]interface{}{
+			"kind":      "message",
+			"role":      "user",
+			"messageId": newID(),
+			"parts":     []interface{}{map[string]interface{}{"kind": "text", "text": r.opts.Message}},
+		},
+	}
+	resp, err := r.call(ctx, "message/send", params, r.opts.SendTimeout)
+	if err != nil {
+		r.report.add("message_send", CategoryLifecycle, StatusFail, "%v", err)
+		r.report.skipRest(CategoryLifecycle, "message/send failed", "tasks_get", "tasks_cancel")
+		return
+	}
+	if resp.Error != nil {
+		r.report.add("message_send", CategoryLifecycle, StatusFail, "message/send returned error %d: %s", resp.Error.Code, resp.Error.Message)
+		r.report.skipRest(CategoryLifecycle, "message/send failed", "tasks_get", "tasks_cancel")
+		return
+	}
+
+	var result map[string]interface{}
+	_ = json.Unmarshal(resp.Result, &result)
+	switch result["kind"] {
+	case "message":
+		r.report.add("message_send", CategoryLifecycle, StatusPass, "agent replied with a message")
+		r.report.skipRest(CategoryLifecycle, "agent replied without creating a task", "tasks_get", "tasks_cancel")
+		return
+	case "task":
+	default:
+		r.report.add("message_send", CategoryLifecycle, StatusFail, "result kind is %v, want 'task' or 'message'", result["kind"])
+		r.report.skipRest(CategoryLifecycle, "message/send failed", "tasks_get", "tasks_cancel")
+		return
+	}
+
+	taskID, _ := result["id"].(string)
+	state := taskState(result)
+	if taskID == "" || !taskStates[state] {
+		r.report.add("message_send", CategoryLifecycle, StatusFail, "task has id %q and state %q", taskID, state)
+		r.report.skipRest(CategoryLifecycle, "message/send failed", "tasks_get", "tasks_cancel")
+		return
+	}
+	r.report.add("message_send", CategoryLifecycle, StatusPass, "created task %s in state %s", taskID, state)
+
+	resp, err = r.call(ctx, "tasks/get", map[string]interface{}{"id": taskID}, r.opts.RequestTimeout)
+	switch {
+	case err != nil:
+		r.report.add("tasks_get", CategoryLifecycle, StatusFail, "%v", err)
+	case resp.Error != nil:
+		r.report.add("tasks_get", CategoryLifecycle, StatusFail, "tasks/get returned error %d: %s", resp.Error.Code, resp.Error.Message)
+	default:
+		var task map[string]interface{}
+		_ = json.Unmarshal(resp.Result, &task)
+		if id, _ := task["id"].(string); id != taskID || !taskStates[taskState(task)] {
+			r.report.add("tasks_get", CategoryLifecycle, StatusFail, "tasks/get returned task %q in state %q", id, taskState(task))
+		} else {
+			state = taskState(task)
+			r.report.add("tasks_get", CategoryLifecycle, StatusPass, "task %s is %s", taskID, state)
+		}
+	}
+
+	resp, err = r.call(ctx, "tasks/cancel", map[string]interface{}{"id": taskID}, r.opts.RequestTimeout)
+	switch {
+	case err != nil:
+		r.report.add("tasks_cancel", CategoryLifecycle, StatusFail, "%v", err)
+	case resp.Error != nil && resp.Error.Code == codeNotCancelable && terminalStates[state]:
+		r.report.add("tasks_cancel", CategoryLifecycle, StatusPass, "finished task correctly reported as not cancelable")
+	case resp.Error != nil:
+		r.report.add("tasks_cancel", CategoryLifecycle, StatusFail, "tasks/cancel returned error %d: %s", resp.Error.Code, resp.Error.Message)
+	default:
+		var task map[string]interface{}
+		_ = json.Unmarshal(resp.Result, &task)
+		if s := taskState(task); s != "canceled" {
+			r.report.add("tasks_cancel", CategoryLifecycle, StatusFail, "task is %q after tasks/cancel, want 'canceled'", s)
+		} else {
+			r.report.add("tasks_cancel", CategoryLifecycle, StatusPass, "task canceled")
+		}
+	}
+}
+
+// checkErrors sends malformed and invalid requests and checks the JSON-RPC
+// error codes.
+func (r *runner) checkErrors(ctx context.Context) {
+	body, status, err := r.do(ctx, http.MethodPost, r.rpcURL, []byte(`{"jsonrpc": "2.0", "id": 1, "method": `), r.opts.RequestTimeout)
+	if err != nil {
+		r.report.add("parse_error", CategoryErrors, StatusFail, "%v", err)
+	} else {
+		r.expectError("parse_error", body, status, codeParseError)
+	}
+
+	cases := []struct {
+		id     string
+		method string
+		params interface{}
+		codes  []int
+	}{
+		{"method_not_found", "conformance/unknown", map[string]interface{}{}, []int{codeMethodNotFound}},
+		{"invalid_params", "message/send", map[string]interface{}{"message": "not an object"}, []int{codeInvalidParams, codeInvalidRequest}},
+		{"task_not_found", "tasks/get", map[string]interface{}{"id": "conformance-" + newID()}, []int{codeTaskNotFound}},
+	}
+	for _, c := range cases {
+		body, status, err := r.post(ctx, c.method, c.params, r.opts.RequestTimeout)
+		if err != nil {
+			r.report.add(c.id, CategoryErrors, StatusFail, "%v", err)
+			continue
+		}
+		r.expectError(c.id, body, status, c.codes...)
+	}
+}
+
+// expectError checks that body is a JSON-RPC error with one of codes.
+func (r *runner) expectError(id string, body []byte, status int, codes ...int) {
+	var resp rpcResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Error == nil {
+		r.report.add(id, CategoryErrors, StatusFail, "expected a JSON-RPC error (HTTP %d), got: %s", status, truncate(body))
+		return
+	}
+	for _, code := range codes {
+		if resp.Error.Code == code {
+			r.report.add(id, CategoryErrors, StatusPass, "returned error %d", code)
+			return
+		}
+	}
+	r.report.add(id, CategoryErrors, StatusFail, "returned error %d (%s), want %v", resp.Error.Code, resp.Error.Message, codes)
+}
+
+// rpcResponse is a JSON-RPC 2.0 response.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// call sends a JSON-RPC request and decodes the response, checking the
+// envelope.
+func (r *runner) call(ctx context.Context, method string, params interface{}, timeout time.Duration) (*rpcResponse, error) {
+	body, status, err := r.post(ctx, method, params, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return nil, fmt.Errorf("%s: endpoint requires authentication (HTTP %d)", method, status)
+	}
+
+	var resp rpcResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("%s: response is not JSON-RPC (HTTP %d): %s", method, status, truncate(body))
+	}
+	if resp.JSONRPC != "2.0" {
+		return nil, fmt.Errorf("%s: response jsonrpc is %q, want \"2.0\"", method, resp.JSONRPC)
+	}
+	if resp.Result == nil && resp.Error == nil {
+		return nil, fmt.Errorf("%s: response has neither result nor error", method)
+	}
+	return &resp, nil
+}
+
+// post sends a JSON-RPC request and returns the raw response.
+func (r *runner) post(ctx context.Context, method string, params interface{}, timeout time.Duration) ([]byte, int, error) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      newID(),
+		"method":  method,
+		"params":  params,
+	})
+	return r.do(ctx, http.MethodPost, r.rpcURL, payload, timeout)
+}
+
+// do sends an HTTP request and reads the response body.
+func (r *runner) do(ctx context.Context, method, url string, payload []byte, timeout time.Duration) ([]byte, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid URL %s: %w", url, err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s %s: %w", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response from %s: %w", url, err)
+	}
+	return data, resp.StatusCode, nil
+}
+
+// taskState returns status.state of a task object.
+func taskState(task map[string]interface{}) string {
+	status, _ := task["status"].(map[string]interface{})
+	state, _ := status["state"].(string)
+	return state
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func truncate(body []byte) string {
+	const max = 200
+	s := strings.TrimSpace(string(body))
+	if len(s) > max {
+		return s[:max] + "..."
+	}
+	return s
+}
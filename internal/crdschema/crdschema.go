@@ -0,0 +1,500 @@
+// Package crdschema checks manifests against the OpenAPI v3 schemas of
+// CustomResourceDefinitions without the API server: unknown fields (a
+// misspelled systemMesage is silently dropped on apply), wrong types,
+// missing required fields and values outside an enum, pattern or range.
+// CEL rules (x-kubernetes-validations) are left to the server.
+package crdschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// Schema is the part of an OpenAPI v3 schema manifests are checked
+// against.
+type Schema struct {
+	Type       string             `json:"type,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	// AdditionalProperties is a schema, or a boolean allowing any.
+	AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+	Required             []string        `json:"required,omitempty"`
+	Enum                 []interface{}   `json:"enum,omitempty"`
+	Pattern              string          `json:"pattern,omitempty"`
+	MinLength            *int64          `json:"minLength,omitempty"`
+	MaxLength            *int64          `json:"maxLength,omitempty"`
+	Minimum              *float64        `json:"minimum,omitempty"`
+	Maximum              *float64        `json:"maximum,omitempty"`
+	MinItems             *int64          `json:"minItems,omitempty"`
+	MaxItems             *int64          `json:"maxItems,omitempty"`
+	Nullable             bool            `json:"nullable,omitempty"`
+	// Ref and AllOf point at shared schemas such as ObjectMeta, which are
+	// not checked.
+	Ref   string    `json:"$ref,omitempty"`
+	AllOf []*Schema `json:"allOf,omitempty"`
+
+	PreserveUnknownFields bool `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	IntOrString           bool `json:"x-kubernetes-int-or-string,omitempty"`
+	EmbeddedResource      bool `json:"x-kubernetes-embedded-resource,omitempty"`
+}
+
+// Schemas are the schemas of resource kinds by GroupVersionKind.
+type Schemas map[schema.GroupVersionKind]*Schema
+
+// ParseOpenAPI reads the schemas of the kinds in an OpenAPI v3 document, as
+// the API server publishes for a group version at
+// /openapi/v3/apis/<group>/<version>.
+func ParseOpenAPI(data []byte) (Schemas, error) {
+	var doc struct {
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	schemas := Schemas{}
+	for name, raw := range doc.Components.Schemas {
+		var kinds struct {
+			GVKs []struct {
+				Group   string `json:"group"`
+				Version string `json:"version"`
+				Kind    string `json:"kind"`
+			} `json:"x-kubernetes-group-version-kind"`
+		}
+		if err := json.Unmarshal(raw, &kinds); err != nil || len(kinds.GVKs) == 0 {
+			continue
+		}
+		var s Schema
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("failed to parse schema %s: %w", name, err)
+		}
+		for _, gvk := range kinds.GVKs {
+			schemas[schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}] = &s
+		}
+	}
+	return schemas, nil
+}
+
+// LoadCRDs reads the schemas of every version of the
+// CustomResourceDefinitions in paths, which are manifest files (possibly
+// with several documents) or directories of them.
+func LoadCRDs(paths []string) (Schemas, error) {
+	schemas := Schemas{}
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".yaml", ".yml", ".json":
+			default:
+				if p != root {
+					return nil
+				}
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			if err := parseCRDs(data, schemas); err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CRD schemas: %w", err)
+		}
+	}
+	return schemas, nil
+}
+
+// documentSeparator splits multi-document YAML.
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// parseCRDs adds the schemas of the CustomResourceDefinitions in data.
+// Other documents are skipped.
+func parseCRDs(data []byte, schemas Schemas) error {
+	for _, doc := range documentSeparator.Split(string(data), -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		var crd struct {
+			Kind string `json:"kind"`
+			Spec struct {
+				Group string `json:"group"`
+				Names struct {
+					Kind string `json:"kind"`
+				} `json:"names"`
+				Versions []struct {
+					Name   string `json:"name"`
+					Schema struct {
+						OpenAPIV3Schema *Schema `json:"openAPIV3Schema"`
+					} `json:"schema"`
+				} `json:"versions"`
+			} `json:"spec"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &crd); err != nil {
+			return fmt.Errorf("failed to parse document: %w", err)
+		}
+		if crd.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		for _, v := range crd.Spec.Versions {
+			if v.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: v.Name, Kind: crd.Spec.Names.Kind}
+			schemas[gvk] = v.Schema.OpenAPIV3Schema
+		}
+	}
+	return nil
+}
+
+// Violation is a place where a manifest does not match its schema.
+type Violation struct {
+	// Field is the path of the field, e.g. "spec.declarative.tools[0].type",
+	// and Pointer the same as a JSON pointer.
+	Field   string
+	Pointer string
+	Message string
+	// Suggestion is the field an unknown field most likely meant, when the
+	// object does not set it already.
+	Suggestion string
+}
+
+// location is where in a manifest a value is.
+type location struct {
+	field, pointer string
+}
+
+func (l location) key(key string) location {
+	field := key
+	if l.field != "" {
+		field = l.field + "." + key
+	}
+	return location{field: field, pointer: l.pointer + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)}
+}
+
+func (l location) index(i int) location {
+	return location{field: fmt.Sprintf("%s[%d]", l.field, i), pointer: fmt.Sprintf("%s/%d", l.pointer, i)}
+}
+
+// Validate checks a resource against the schema of its kind. metadata and
+// status are not checked: the API server owns their shape.
+func Validate(obj map[string]interface{}, s *Schema) []Violation {
+	v := &validator{}
+	root := location{}
+	for _, key := range sortedKeys(obj) {
+		if key == "metadata" || key == "status" {
+			continue
+		}
+		prop, ok := s.Properties[key]
+		if !ok {
+			if key != "apiVersion" && key != "kind" && !s.PreserveUnknownFields {
+				v.unknown(root, key, obj, s)
+			}
+			continue
+		}
+		v.check(root.key(key), obj[key], prop)
+	}
+	for _, key := range s.Required {
+		if _, ok := obj[key]; !ok && key != "metadata" && key != "status" {
+			v.add(root.key(key), "required field is missing")
+		}
+	}
+	return v.violations
+}
+
+type validator struct {
+	violations []Violation
+}
+
+func (v *validator) add(at location, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Field: at.field, Pointer: at.pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+// unknown reports a field of the object at parent that its schema does not
+// define, with the defined field it most likely meant.
+func (v *validator) unknown(parent location, key string, obj map[string]interface{}, s *Schema) {
+	known := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+	at := parent.key(key)
+	violation := Violation{Field: at.field, Pointer: at.pointer, Message: fmt.Sprintf("unknown field '%s'; the API server drops it on apply", key)}
+	if suggestion, ok := closest(known, key); ok {
+		violation.Message = fmt.Sprintf("unknown field '%s' (did you mean '%s'?); the API server drops it on apply", key, suggestion)
+		if _, set := obj[suggestion]; !set {
+			violation.Suggestion = suggestion
+		}
+	}
+	v.violations = append(v.violations, violation)
+}
+
+func (v *validator) check(at location, value interface{}, s *Schema) {
+	if s == nil || s.Ref != "" || len(s.AllOf) > 0 || s.EmbeddedResource {
+		return
+	}
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			v.add(at, "must be %s, not null", article(s.Type))
+		}
+		return
+	}
+	if s.IntOrString {
+		if _, isString := value.(string); !isString && !isInteger(value) {
+			v.add(at, "must be an integer or a string, not %s", typeOf(value))
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			v.add(at, "must be an object, not %s", typeOf(value))
+			return
+		}
+		v.checkObject(at, m, s)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			v.add(at, "must be an array, not %s", typeOf(value))
+			return
+		}
+		if s.MinItems != nil && int64(len(items)) < *s.MinItems {
+			v.add(at, "must have at least %d item(s), has %d", *s.MinItems, len(items))
+		}
+		if s.MaxItems != nil && int64(len(items)) > *s.MaxItems {
+			v.add(at, "must have at most %d item(s), has %d", *s.MaxItems, len(items))
+		}
+		for i, item := range items {
+			v.check(at.index(i), item, s.Items)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			v.add(at, "must be a string, not %s", typeOf(value))
+			return
+		}
+		v.checkString(at, str, s)
+	case "integer":
+		if !isInteger(value) {
+			v.add(at, "must be an integer, not %s", typeOf(value))
+			return
+		}
+		v.checkNumber(at, value, s)
+	case "number":
+		if _, ok := number(value); !ok {
+			v.add(at, "must be a number, not %s", typeOf(value))
+			return
+		}
+		v.checkNumber(at, value, s)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.add(at, "must be a boolean, not %s", typeOf(value))
+			return
+		}
+	}
+	v.checkEnum(at, value, s)
+}
+
+func (v *validator) checkObject(at location, m map[string]interface{}, s *Schema) {
+	additional, allowsAny := s.additional()
+	for _, key := range sortedKeys(m) {
+		if prop, ok := s.Properties[key]; ok {
+			v.check(at.key(key), m[key], prop)
+			continue
+		}
+		switch {
+		case additional != nil:
+			v.check(at.key(key), m[key], additional)
+		case allowsAny || s.PreserveUnknownFields || len(s.Properties) == 0:
+		default:
+			v.unknown(at, key, m, s)
+		}
+	}
+	for _, key := range s.Required {
+		if _, ok := m[key]; !ok {
+			v.add(at.key(key), "required field is missing")
+		}
+	}
+}
+
+func (v *validator) checkString(at location, str string, s *Schema) {
+	length := int64(len([]rune(str)))
+	if s.MinLength != nil && length < *s.MinLength {
+		v.add(at, "must be at least %d character(s) long", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.add(at, "must be at most %d character(s) long", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		// Patterns are ECMA 262; those RE2 cannot compile are skipped
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+			v.add(at, "'%s' does not match the pattern %s", str, s.Pattern)
+		}
+	}
+}
+
+func (v *validator) checkNumber(at location, value interface{}, s *Schema) {
+	n, _ := number(value)
+	if s.Minimum != nil && n < *s.Minimum {
+		v.add(at, "must be at least %s", formatNumber(*s.Minimum))
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		v.add(at, "must be at most %s", formatNumber(*s.Maximum))
+	}
+}
+
+func (v *validator) checkEnum(at location, value interface{}, s *Schema) {
+	if len(s.Enum) == 0 {
+		return
+	}
+	allowed := make([]string, len(s.Enum))
+	for i, e := range s.Enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return
+		}
+		allowed[i] = fmt.Sprint(e)
+	}
+	v.add(at, "'%v' is not one of %s", value, strings.Join(allowed, ", "))
+}
+
+// additional returns the schema of properties not listed in Properties,
+// or whether any are allowed when additionalProperties is a boolean.
+func (s *Schema) additional() (*Schema, bool) {
+	if len(s.AdditionalProperties) == 0 {
+		return nil, false
+	}
+	var allowed bool
+	if err := json.Unmarshal(s.AdditionalProperties, &allowed); err == nil {
+		return nil, allowed
+	}
+	var additional Schema
+	if err := json.Unmarshal(s.AdditionalProperties, &additional); err != nil {
+		return nil, true
+	}
+	return &additional, true
+}
+
+// number returns a JSON or YAML number as a float64.
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func isInteger(value interface{}) bool {
+	n, ok := number(value)
+	return ok && n == math.Trunc(n)
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// typeOf names the JSON type of a value for messages.
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case nil:
+		return "null"
+	}
+	if _, ok := number(value); ok {
+		return "a number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func article(t string) string {
+	if t == "object" || t == "array" || t == "integer" {
+		return "an " + t
+	}
+	return "a " + t
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// closest returns the name most likely meant by a misspelled one: the same
+// but for case, or the nearest by edit distance within a few characters.
+func closest(names []string, want string) (string, bool) {
+	best, bestDistance := "", -1
+	for _, name := range names {
+		if strings.EqualFold(name, want) {
+			return name, true
+		}
+		if d := editDistance(strings.ToLower(name), strings.ToLower(want)); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	if bestDistance < 0 || bestDistance > max(2, len(want)/4) {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,308 @@
+package crdschema
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// agentCRD is a trimmed Agent CRD with one of each construct the walker
+// handles.
+const agentCRD = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: agents.kagent.dev
+spec:
+  group: kagent.dev
+  names:
+    kind: Agent
+  versions:
+    - name: v1alpha2
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            apiVersion:
+              type: string
+            kind:
+              type: string
+            metadata:
+              type: object
+            spec:
+              type: object
+              required: [type]
+              properties:
+                type:
+                  type: string
+                  enum: [Declarative, BYO]
+                description:
+                  type: string
+                  maxLength: 20
+                declarative:
+                  type: object
+                  required: [systemMessage]
+                  properties:
+                    systemMessage:
+                      type: string
+                      minLength: 1
+                    modelConfig:
+                      type: string
+                      pattern: '^[a-z0-9-]+$'
+                    replicas:
+                      type: integer
+                      minimum: 1
+                      maximum: 5
+                    stream:
+                      type: boolean
+                    tools:
+                      type: array
+                      maxItems: 2
+                      items:
+                        type: object
+                        required: [type]
+                        properties:
+                          type:
+                            type: string
+                            enum: [McpServer, Agent]
+                          headers:
+                            type: object
+                            additionalProperties:
+                              type: string
+                    extra:
+                      type: object
+                      additionalProperties: true
+                    config:
+                      type: object
+                      x-kubernetes-preserve-unknown-fields: true
+                      properties:
+                        level:
+                          type: integer
+                    port:
+                      x-kubernetes-int-or-string: true
+                    note:
+                      type: string
+                      nullable: true
+          required: [spec]
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-crd
+`
+
+func agentSchema(t *testing.T) *Schema {
+	t.Helper()
+	schemas := Schemas{}
+	if err := parseCRDs([]byte(agentCRD), schemas); err != nil {
+		t.Fatal(err)
+	}
+	s, ok := schemas[schema.GroupVersionKind{Group: "kagent.dev", Version: "v1alpha2", Kind: "Agent"}]
+	if !ok {
+		t.Fatalf("Agent schema not loaded, have %v", schemas)
+	}
+	return s
+}
+
+func TestValidate(t *testing.T) {
+	s := agentSchema(t)
+
+	tests := []struct {
+		name     string
+		manifest string
+		want     []Violation
+	}{
+		{
+			name: "valid",
+			manifest: `
+spec:
+  type: Declarative
+  declarative:
+    systemMessage: You triage incidents.
+    modelConfig: default-model
+    replicas: 2
+    stream: true
+    port: http
+    note: null
+    tools:
+      - type: McpServer
+        headers:
+          Authorization: Bearer x
+`,
+		},
+		{
+			name:     "missing required fields",
+			manifest: `{spec: {declarative: {}}}`,
+			want: []Violation{
+				{Field: "spec.declarative.systemMessage", Pointer: "/spec/declarative/systemMessage", Message: "required field is missing"},
+				{Field: "spec.type", Pointer: "/spec/type", Message: "required field is missing"},
+			},
+		},
+		{
+			name:     "missing required root field",
+			manifest: `{kind: Agent}`,
+			want: []Violation{
+				{Field: "spec", Pointer: "/spec", Message: "required field is missing"},
+			},
+		},
+		{
+			name:     "required in list items",
+			manifest: `{spec: {type: BYO, declarative: {systemMessage: x, tools: [{headers: {}}]}}}`,
+			want: []Violation{
+				{Field: "spec.declarative.tools[0].type", Pointer: "/spec/declarative/tools/0/type", Message: "required field is missing"},
+			},
+		},
+		{
+			name:     "enums",
+			manifest: `{spec: {type: declarative, declarative: {systemMessage: x, tools: [{type: Agent}, {type: Http}]}}}`,
+			want: []Violation{
+				{Field: "spec.declarative.tools[1].type", Pointer: "/spec/declarative/tools/1/type", Message: "'Http' is not one of McpServer, Agent"},
+				{Field: "spec.type", Pointer: "/spec/type", Message: "'declarative' is not one of Declarative, BYO"},
+			},
+		},
+		{
+			name:     "unknown fields with suggestions",
+			manifest: `{spec: {type: BYO, declarative: {systemMessage: x, systemMesage: y, Stream: true, colour: red}}}`,
+			want: []Violation{
+				{Field: "spec.declarative.Stream", Pointer: "/spec/declarative/Stream", Message: "unknown field 'Stream' (did you mean 'stream'?); the API server drops it on apply", Suggestion: "stream"},
+				{Field: "spec.declarative.colour", Pointer: "/spec/declarative/colour", Message: "unknown field 'colour'; the API server drops it on apply"},
+				// systemMessage is set already, so there is nothing to rename to
+				{Field: "spec.declarative.systemMesage", Pointer: "/spec/declarative/systemMesage", Message: "unknown field 'systemMesage' (did you mean 'systemMessage'?); the API server drops it on apply"},
+			},
+		},
+		{
+			name:     "unknown root field",
+			manifest: `{apiVersion: kagent.dev/v1alpha2, kind: Agent, metadata: {name: a, anything: 1}, status: {x: 1}, spce: {}, spec: {type: BYO}}`,
+			want: []Violation{
+				{Field: "spce", Pointer: "/spce", Message: "unknown field 'spce' (did you mean 'spec'?); the API server drops it on apply"},
+			},
+		},
+		{
+			name:     "preserve unknown fields",
+			manifest: `{spec: {type: BYO, declarative: {systemMessage: x, config: {anything: {nested: [1]}, level: high}}}}`,
+			want: []Violation{
+				{Field: "spec.declarative.config.level", Pointer: "/spec/declarative/config/level", Message: "must be an integer, not a string"},
+			},
+		},
+		{
+			name:     "additional properties",
+			manifest: `{spec: {type: BYO, declarative: {systemMessage: x, extra: {a: 1, b: [true]}, tools: [{type: Agent, headers: {X-Token: abc, X-Retries: 3, a/b: c}}]}}}`,
+			want: []Violation{
+				{Field: "spec.declarative.tools[0].headers.X-Retries", Pointer: "/spec/declarative/tools/0/headers/X-Retries", Message: "must be a string, not a number"},
+			},
+		},
+		{
+			name:     "types",
+			manifest: `{spec: {type: BYO, declarative: {systemMessage: [x], stream: "yes", replicas: 1.5, tools: {type: Agent}, port: true}}}`,
+			want: []Violation{
+				{Field: "spec.declarative.port", Pointer: "/spec/declarative/port", Message: "must be an integer or a string, not a boolean"},
+				{Field: "spec.declarative.replicas", Pointer: "/spec/declarative/replicas", Message: "must be an integer, not a number"},
+				{Field: "spec.declarative.stream", Pointer: "/spec/declarative/stream", Message: "must be a boolean, not a string"},
+				{Field: "spec.declarative.systemMessage", Pointer: "/spec/declarative/systemMessage", Message: "must be a string, not an array"},
+				{Field: "spec.declarative.tools", Pointer: "/spec/declarative/tools", Message: "must be an array, not an object"},
+			},
+		},
+		{
+			name:     "null where not nullable",
+			manifest: `{spec: {type: BYO, description: null}}`,
+			want: []Violation{
+				{Field: "spec.description", Pointer: "/spec/description", Message: "must be a string, not null"},
+			},
+		},
+		{
+			name:     "limits",
+			manifest: `{spec: {type: BYO, description: "far more than twenty characters", declarative: {systemMessage: "", modelConfig: Default_Model, replicas: 9, tools: [{type: Agent}, {type: Agent}, {type: Agent}]}}}`,
+			want: []Violation{
+				{Field: "spec.declarative.modelConfig", Pointer: "/spec/declarative/modelConfig", Message: "'Default_Model' does not match the pattern ^[a-z0-9-]+$"},
+				{Field: "spec.declarative.replicas", Pointer: "/spec/declarative/replicas", Message: "must be at most 5"},
+				{Field: "spec.declarative.systemMessage", Pointer: "/spec/declarative/systemMessage", Message: "must be at least 1 character(s) long"},
+				{Field: "spec.declarative.tools", Pointer: "/spec/declarative/tools", Message: "must have at most 2 item(s), has 3"},
+				{Field: "spec.description", Pointer: "/spec/description", Message: "must be at most 20 character(s) long"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var obj map[string]interface{}
+			if err := yaml.Unmarshal([]byte(tt.manifest), &obj); err != nil {
+				t.Fatal(err)
+			}
+			got := Validate(obj, s)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() =\n%s\nwant\n%s", describe(got), describe(tt.want))
+			}
+		})
+	}
+}
+
+func describe(violations []Violation) string {
+	var out string
+	for _, v := range violations {
+		out += "  " + v.Pointer + ": " + v.Message
+		if v.Suggestion != "" {
+			out += " [" + v.Suggestion + "]"
+		}
+		out += "\n"
+	}
+	return out
+}
+
+func TestValidatePreservesUnknownFieldsAtTheRoot(t *testing.T) {
+	s := &Schema{
+		Type:                  "object",
+		PreserveUnknownFields: true,
+		Properties:            map[string]*Schema{"spec": {Type: "object"}},
+	}
+	obj := map[string]interface{}{"spec": map[string]interface{}{}, "data": "x"}
+	if got := Validate(obj, s); len(got) != 0 {
+		t.Errorf("Validate() = %s", describe(got))
+	}
+}
+
+func TestLoadCRDs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "agent.yaml"), []byte(agentCRD), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# not yaml: ["), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := LoadCRDs([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 1 {
+		t.Fatalf("loaded %d schemas, want 1", len(schemas))
+	}
+	if _, err := LoadCRDs([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("LoadCRDs of a missing path succeeded")
+	}
+}
+
+func TestParseOpenAPI(t *testing.T) {
+	schemas, err := ParseOpenAPI([]byte(`{
+  "components": {"schemas": {
+    "dev.kagent.v1alpha2.Agent": {
+      "type": "object",
+      "properties": {"spec": {"type": "object", "properties": {"type": {"type": "string"}}}},
+      "x-kubernetes-group-version-kind": [{"group": "kagent.dev", "version": "v1alpha2", "kind": "Agent"}]
+    },
+    "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": {"type": "object"}
+  }}
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok := schemas[schema.GroupVersionKind{Group: "kagent.dev", Version: "v1alpha2", Kind: "Agent"}]
+	if !ok || len(schemas) != 1 {
+		t.Fatalf("ParseOpenAPI() = %v", schemas)
+	}
+	if s.Properties["spec"].Properties["type"].Type != "string" {
+		t.Errorf("spec.type not parsed: %+v", s.Properties["spec"])
+	}
+}
@@ -0,0 +1,259 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MergePatch applies an RFC 7386 JSON merge patch to a document and returns
+// the result; the document is not modified. Objects are merged key by key,
+// null removes a key, and any other value replaces the target value whole.
+func MergePatch(doc, patch map[string]interface{}) map[string]interface{} {
+	merged, _ := mergeValue(deepCopy(doc), patch).(map[string]interface{})
+	return merged
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return deepCopy(patch)
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergeValue(t[k], v)
+	}
+	return t
+}
+
+// ApplyPatch applies RFC 6902 JSON Patch operations to a document and
+// returns the result; the document is not modified. Operations apply in
+// order and the first one that fails aborts the patch.
+func ApplyPatch(doc map[string]interface{}, ops []Operation) (map[string]interface{}, error) {
+	var root interface{} = deepCopy(doc)
+	for i, op := range ops {
+		var err error
+		root, err = applyOperation(root, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	result, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the patch replaced the document with a non-object")
+	}
+	return result, nil
+}
+
+func applyOperation(root interface{}, op Operation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return insert(root, path, deepCopy(op.Value), false)
+	case "replace":
+		if _, err := lookup(root, path); err != nil {
+			return nil, err
+		}
+		return insert(root, path, deepCopy(op.Value), true)
+	case "remove":
+		return remove(root, path)
+	case "test":
+		got, err := lookup(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(got, op.Value) {
+			return nil, fmt.Errorf("test failed: value is %s", compactJSON(got))
+		}
+		return root, nil
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		value, err := lookup(root, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return nil, fmt.Errorf("cannot move a value into itself")
+			}
+			if root, err = remove(root, from); err != nil {
+				return nil, err
+			}
+		}
+		return insert(root, path, deepCopy(value), false)
+	default:
+		return nil, fmt.Errorf("unknown op '%s' (expected add, remove, replace, move, copy or test)", op.Op)
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path '%s' must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+// lookup returns the value at a path.
+func lookup(root interface{}, path []string) (interface{}, error) {
+	current := root
+	for i, token := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%s does not exist", pointer(path[:i+1]))
+			}
+			current = v
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", pointer(path[:i+1]), err)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("%s does not exist", pointer(path[:i+1]))
+		}
+	}
+	return current, nil
+}
+
+// insert sets the value at a path, whose parent must exist. In a list it
+// inserts before the index, or replaces the item when replace is set; "-"
+// appends.
+func insert(root interface{}, path []string, value interface{}, replace bool) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := lookup(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return root, nil
+	case []interface{}:
+		index, err := arrayIndex(last, len(node), !replace)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pointer(path), err)
+		}
+		var updated []interface{}
+		if replace {
+			updated = append([]interface{}(nil), node...)
+			updated[index] = value
+		} else {
+			updated = append(append(append([]interface{}(nil), node[:index]...), value), node[index:]...)
+		}
+		return insert(root, path[:len(path)-1], updated, true)
+	default:
+		return nil, fmt.Errorf("%s is not an object or list", pointer(path[:len(path)-1]))
+	}
+}
+
+// remove deletes the value at a path.
+func remove(root interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	if _, err := lookup(root, path); err != nil {
+		return nil, err
+	}
+	parent, _ := lookup(root, path[:len(path)-1])
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		delete(node, last)
+		return root, nil
+	case []interface{}:
+		index, _ := arrayIndex(last, len(node), false)
+		updated := append(append([]interface{}(nil), node[:index]...), node[index+1:]...)
+		return insert(root, path[:len(path)-1], updated, true)
+	}
+	return root, nil
+}
+
+// arrayIndex parses a list index. With forInsert, the length itself and "-"
+// (the end) are allowed.
+func arrayIndex(token string, length int, forInsert bool) (int, error) {
+	if token == "-" && forInsert {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid list index '%s'", token)
+	}
+	if index > length || (index == length && !forInsert) {
+		return 0, fmt.Errorf("index %d is out of range (length %d)", index, length)
+	}
+	return index, nil
+}
+
+// deepCopy copies JSON-like values so patches never alias their input.
+func deepCopy(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			out[k] = deepCopy(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, item := range value {
+			out[i] = deepCopy(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// jsonEqual compares values by their JSON encoding, so numbers decoded from
+// YAML and from JSON compare equal.
+func jsonEqual(a, b interface{}) bool {
+	var na, nb interface{}
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	if json.Unmarshal(da, &na) != nil || json.Unmarshal(db, &nb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(na, nb)
+}
+
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,119 @@
+// Package diff renders the difference between two versions of a resource in
+// the format the caller reads best: unified text for terminals, a list of
+// changed field paths for YAML readers, a side-by-side markdown table for
+// chat UIs, or an RFC 6902 JSON Patch for automation. It also applies JSON Patch and JSON merge patch documents.
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formats accepted by For.
+const (
+	Unified    = "unified"
+	Semantic   = "semantic"
+	SideBySide = "side-by-side"
+	JSONPatch  = "json-patch"
+)
+
+// Renderer renders the difference between two documents.
+type Renderer interface {
+	// Render returns the difference from before to after, or "" when there
+	// is none.
+	Render(before, after map[string]interface{}) (string, error)
+	// Legend explains how to read the output; it may be empty.
+	Legend() string
+}
+
+var renderers = map[string]Renderer{
+	Unified:    unifiedRenderer{},
+	Semantic:   semanticRenderer{},
+	SideBySide: tableRenderer{},
+	JSONPatch:  patchRenderer{},
+}
+
+// Formats returns the supported formats, the default first.
+func Formats() []string {
+	return []string{Unified, Semantic, SideBySide, JSONPatch}
+}
+
+// For returns the renderer of a format.
+func For(format string) (Renderer, error) {
+	r, ok := renderers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown diff format '%s' (expected one of %s)", format, strings.Join(Formats(), ", "))
+	}
+	return r, nil
+}
+
+// change is a value that differs between two documents. A nil side means
+// the value is absent there.
+type change struct {
+	path   []string
+	before interface{}
+	after  interface{}
+	// added and removed distinguish an absent value from an explicit null.
+	added, removed bool
+}
+
+// changes walks two documents and returns the differing values, ordered by
+// path. Objects are compared key by key and lists of equal length item by
+// item; any other difference is reported for the whole value.
+func changes(before, after map[string]interface{}) []change {
+	var out []change
+	walk(nil, before, after, &out)
+	return out
+}
+
+func walk(path []string, before, after interface{}, out *[]change) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+
+	if b, ok := before.(map[string]interface{}); ok {
+		if a, ok := after.(map[string]interface{}); ok {
+			keys := map[string]bool{}
+			for k := range b {
+				keys[k] = true
+			}
+			for k := range a {
+				keys[k] = true
+			}
+			sorted := make([]string, 0, len(keys))
+			for k := range keys {
+				sorted = append(sorted, k)
+			}
+			sort.Strings(sorted)
+
+			for _, k := range sorted {
+				bv, inBefore := b[k]
+				av, inAfter := a[k]
+				child := append(append([]string(nil), path...), k)
+				switch {
+				case !inBefore:
+					*out = append(*out, change{path: child, after: av, added: true})
+				case !inAfter:
+					*out = append(*out, change{path: child, before: bv, removed: true})
+				default:
+					walk(child, bv, av, out)
+				}
+			}
+			return
+		}
+	}
+
+	if b, ok := before.([]interface{}); ok {
+		if a, ok := after.([]interface{}); ok && len(a) == len(b) {
+			for i := range b {
+				walk(append(append([]string(nil), path...), strconv.Itoa(i)), b[i], a[i], out)
+			}
+			return
+		}
+	}
+
+	*out = append(*out, change{path: path, before: before, after: after})
+}
@@ -0,0 +1,213 @@
+package diff
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenCases are the directories under testdata, each holding a
+// before.yaml and an after.yaml and the expected output of every format as
+// <format>.golden.
+var goldenCases = []string{"identical", "keys", "lists", "types"}
+
+func readDocument(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return doc
+}
+
+func TestRenderGolden(t *testing.T) {
+	for _, name := range goldenCases {
+		dir := filepath.Join("testdata", name)
+		before := readDocument(t, filepath.Join(dir, "before.yaml"))
+		after := readDocument(t, filepath.Join(dir, "after.yaml"))
+
+		for _, format := range Formats() {
+			t.Run(name+"/"+format, func(t *testing.T) {
+				r, err := For(format)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := r.Render(before, after)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				golden := filepath.Join(dir, format+".golden")
+				if *update {
+					if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("%v (run go test with -update to create it)", err)
+				}
+				if got != string(want) {
+					t.Errorf("%s output differs from %s:\n--- got\n%s\n--- want\n%s", format, golden, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestRenderIdenticalIsEmpty(t *testing.T) {
+	doc := readDocument(t, filepath.Join("testdata", "identical", "before.yaml"))
+	for _, format := range Formats() {
+		r, _ := For(format)
+		got, err := r.Render(doc, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "" {
+			t.Errorf("%s: identical documents rendered %q, want no output", format, got)
+		}
+	}
+}
+
+func TestForUnknownFormat(t *testing.T) {
+	if _, err := For("html"); err == nil {
+		t.Error("For(\"html\") succeeded, want an error")
+	}
+}
+
+func TestPatchRoundTrip(t *testing.T) {
+	for _, name := range goldenCases {
+		dir := filepath.Join("testdata", name)
+		before := readDocument(t, filepath.Join(dir, "before.yaml"))
+		after := readDocument(t, filepath.Join(dir, "after.yaml"))
+
+		// Operations survive encoding, as clients send them back
+		data, err := json.Marshal(Patch(before, after))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ops []Operation
+		if err := json.Unmarshal(data, &ops); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ApplyPatch(before, ops)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, after) {
+			t.Errorf("%s: patched document differs from after.yaml", name)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+		err  bool
+	}{
+		{in: "spec.declarative.tools[0].mcpServer", want: []string{"spec", "declarative", "tools", "0", "mcpServer"}},
+		{in: "$.spec.replicas", want: []string{"spec", "replicas"}},
+		{in: "metadata.annotations['kagent.dev/tests']", want: []string{"metadata", "annotations", "kagent.dev/tests"}},
+		{in: `metadata.annotations["it's"]`, want: []string{"metadata", "annotations", "it's"}},
+		{in: "spec.tools[*].name", want: []string{"spec", "tools", Wildcard, "name"}},
+		{in: "metadata.labels.*", want: []string{"metadata", "labels", Wildcard}},
+		{in: "", err: true},
+		{in: "spec..replicas", err: true},
+		{in: "spec.", err: true},
+		{in: "spec.tools[name]", err: true},
+		{in: "spec.tools[0", err: true},
+		{in: "metadata.annotations['open", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePath(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParsePath(%q) = %q, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePath(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFieldPathRoundTrip(t *testing.T) {
+	for _, path := range [][]string{
+		{"spec", "declarative", "tools", "0", "mcpServer", "toolNames", "2"},
+		{"metadata", "annotations", "kagent.dev/tests"},
+		{"metadata", "labels", "app.kubernetes.io/name"},
+		{"data", "it's"},
+	} {
+		rendered := fieldPath(path)
+		parsed, err := ParsePath(rendered)
+		if err != nil {
+			t.Errorf("ParsePath(%q): %v", rendered, err)
+			continue
+		}
+		if !reflect.DeepEqual(parsed, path) {
+			t.Errorf("ParsePath(fieldPath(%q)) = %q", path, parsed)
+		}
+	}
+}
+
+func TestWithout(t *testing.T) {
+	doc := readDocument(t, filepath.Join("testdata", "lists", "after.yaml"))
+	var paths [][]string
+	for _, p := range []string{"spec.declarative.tools[*].mcpServer.toolNames", "spec.declarative.env[0]", "spec.missing.field"} {
+		path, err := ParsePath(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	got := Without(doc, paths)
+	var want map[string]interface{}
+	if err := yaml.Unmarshal([]byte(`
+spec:
+  declarative:
+    tools:
+      - type: McpServer
+        mcpServer:
+          name: k8s-tools
+      - type: Agent
+        agent:
+          name: reviewer
+      - type: McpServer
+        mcpServer:
+          name: prom-tools
+    env: []
+  matrix:
+    - [1, 2]
+    - [3, 5]
+`), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotYAML, _ := yaml.Marshal(got)
+		t.Errorf("Without =\n%s", gotYAML)
+	}
+
+	if original := readDocument(t, filepath.Join("testdata", "lists", "after.yaml")); !reflect.DeepEqual(doc, original) {
+		t.Error("Without modified its input")
+	}
+}
@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Operation is a single RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	// From is the source path of move and copy operations.
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON keeps the value of add, replace and test operations even when
+// it is null, as RFC 6902 requires the member for them.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type operation Operation
+	switch o.Op {
+	case "add", "replace", "test":
+		return json.Marshal(struct {
+			operation
+			Value interface{} `json:"value"`
+		}{operation(o), o.Value})
+	}
+	return json.Marshal(operation(o))
+}
+
+// patchRenderer renders the JSON Patch that turns before into after.
+type patchRenderer struct{}
+
+func (patchRenderer) Legend() string {
+	return ""
+}
+
+func (patchRenderer) Render(before, after map[string]interface{}) (string, error) {
+	ops := Patch(before, after)
+	if len(ops) == 0 {
+		return "", nil
+	}
+	data, err := json.MarshalIndent(ops, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to render JSON patch: %w", err)
+	}
+	return string(data), nil
+}
+
+// Patch returns the JSON Patch operations that turn before into after.
+// Lists whose length changed are replaced as a whole.
+func Patch(before, after map[string]interface{}) []Operation {
+	var ops []Operation
+	for _, c := range changes(before, after) {
+		op := Operation{Path: pointer(c.path), Value: c.after}
+		switch {
+		case c.added:
+			op.Op = "add"
+		case c.removed:
+			op.Op = "remove"
+			op.Value = nil
+		default:
+			op.Op = "replace"
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// pointer renders a path as an RFC 6901 JSON Pointer.
+func pointer(path []string) string {
+	var b strings.Builder
+	for _, p := range path {
+		b.WriteByte('/')
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(p))
+	}
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// plainKey matches the keys a field path writes after a dot; others, like
+// annotation keys, are written in brackets.
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// fieldPath renders a path the way kubectl explain does, e.g.
+// "spec.declarative.tools[0].mcpServer", quoting keys that are not plain
+// words: "metadata.annotations['kagent.dev/tests']".
+func fieldPath(path []string) string {
+	if len(path) == 0 {
+		return "(document)"
+	}
+	var b strings.Builder
+	for i, p := range path {
+		switch {
+		case isIndex(p):
+			b.WriteString("[" + p + "]")
+		case !plainKey.MatchString(p):
+			b.WriteString("['" + strings.ReplaceAll(p, "'", `\'`) + "']")
+		default:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(p)
+		}
+	}
+	return b.String()
+}
+
+func isIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Wildcard is the path segment ParsePath returns for "*" and "[*]", which
+// matches any key or list index.
+const Wildcard = "*"
+
+// ParsePath parses a field path as fieldPath renders it, optionally
+// prefixed with "$.": dotted keys, [n] list indexes, ['key'] for keys with
+// dots or slashes, and * or [*] for any key or index.
+func ParsePath(s string) ([]string, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(s), "$")
+	rest = strings.TrimPrefix(rest, ".")
+	if rest == "" {
+		return nil, fmt.Errorf("empty field path")
+	}
+
+	var path []string
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "['") || strings.HasPrefix(rest, `["`):
+			quote := rest[1]
+			var key strings.Builder
+			i := 2
+			for ; i < len(rest) && rest[i] != quote; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				key.WriteByte(rest[i])
+			}
+			if i+1 >= len(rest) || rest[i+1] != ']' {
+				return nil, fmt.Errorf("field path %q: unterminated [%c", s, quote)
+			}
+			path = append(path, key.String())
+			rest = rest[i+2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("field path %q: unterminated [", s)
+			}
+			index := rest[1:end]
+			if index != Wildcard && !isIndex(index) {
+				return nil, fmt.Errorf("field path %q: [%s] is not a list index; quote keys as ['%s']", s, index, index)
+			}
+			path = append(path, index)
+			rest = rest[end+1:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("field path %q: empty key", s)
+			}
+			path = append(path, rest[:end])
+			rest = rest[end:]
+		}
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+			if rest == "" || rest[0] == '.' || rest[0] == '[' {
+				return nil, fmt.Errorf("field path %q: empty key", s)
+			}
+		}
+	}
+	return path, nil
+}
+
+// Without returns a copy of doc without the fields at paths, as parsed by
+// ParsePath. Paths that do not exist are ignored.
+func Without(doc map[string]interface{}, paths [][]string) map[string]interface{} {
+	out, _ := deepCopy(doc).(map[string]interface{})
+	for _, path := range paths {
+		if len(path) > 0 {
+			removePath(out, path)
+		}
+	}
+	return out
+}
+
+// removePath deletes the fields at path below v, returning v without them.
+// Lists lose the matched items.
+func removePath(v interface{}, path []string) interface{} {
+	last := len(path) == 1
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			if path[0] != Wildcard && path[0] != k {
+				continue
+			}
+			if last {
+				delete(value, k)
+			} else {
+				value[k] = removePath(item, path[1:])
+			}
+		}
+		return value
+	case []interface{}:
+		kept := []interface{}{}
+		for i, item := range value {
+			if path[0] != Wildcard && path[0] != fmt.Sprint(i) {
+				kept = append(kept, item)
+				continue
+			}
+			if !last {
+				kept = append(kept, removePath(item, path[1:]))
+			}
+		}
+		return kept
+	}
+	return v
+}
@@ -0,0 +1,71 @@
+package diff
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// semanticRenderer renders one line per changed field, keyed by its field
+// path, for readers who think in YAML fields rather than lines.
+type semanticRenderer struct{}
+
+func (semanticRenderer) Legend() string {
+	return "Legend: + added, - removed, ~ changed (before → after)"
+}
+
+func (semanticRenderer) Render(before, after map[string]interface{}) (string, error) {
+	var lines []string
+	for _, c := range changes(before, after) {
+		for _, c := range itemChanges(c) {
+			path := fieldPath(c.path)
+			switch {
+			case c.added:
+				lines = append(lines, "+ "+path+": "+inline(c.after))
+			case c.removed:
+				lines = append(lines, "- "+path+": "+inline(c.before))
+			default:
+				lines = append(lines, "~ "+path+": "+inline(c.before)+" → "+inline(c.after))
+			}
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// itemChanges splits a change of a list whose length changed into changes
+// of its items: the common items are compared by position and the extra
+// ones are added or removed, so appending a tool reads as one added item
+// rather than a new list.
+func itemChanges(c change) []change {
+	b, okBefore := c.before.([]interface{})
+	a, okAfter := c.after.([]interface{})
+	if !okBefore || !okAfter || c.added || c.removed {
+		return []change{c}
+	}
+
+	var out []change
+	common := min(len(a), len(b))
+	for i := 0; i < common; i++ {
+		var item []change
+		walk(append(append([]string(nil), c.path...), strconv.Itoa(i)), b[i], a[i], &item)
+		for _, ic := range item {
+			out = append(out, itemChanges(ic)...)
+		}
+	}
+	for i := common; i < len(b); i++ {
+		out = append(out, change{path: append(append([]string(nil), c.path...), strconv.Itoa(i)), before: b[i], removed: true})
+	}
+	for i := common; i < len(a); i++ {
+		out = append(out, change{path: append(append([]string(nil), c.path...), strconv.Itoa(i)), after: a[i], added: true})
+	}
+	return out
+}
+
+// inline renders a value as one line of JSON.
+func inline(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "?"
+	}
+	return string(data)
+}
@@ -0,0 +1,55 @@
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// maxCellLength is the longest value shown in a table cell.
+const maxCellLength = 80
+
+// tableRenderer renders one markdown table row per changed field, with the
+// value before and after side by side.
+type tableRenderer struct{}
+
+func (tableRenderer) Legend() string {
+	return "Legend: _(absent)_ marks a field that does not exist on that side"
+}
+
+func (tableRenderer) Render(before, after map[string]interface{}) (string, error) {
+	diffs := changes(before, after)
+	if len(diffs) == 0 {
+		return "", nil
+	}
+
+	rows := []string{"| Field | Before | After |", "|---|---|---|"}
+	for _, c := range diffs {
+		b, a := cell(c.before), cell(c.after)
+		if c.added {
+			b = "_(absent)_"
+		}
+		if c.removed {
+			a = "_(absent)_"
+		}
+		rows = append(rows, "| `"+fieldPath(c.path)+"` | "+b+" | "+a+" |")
+	}
+	return strings.Join(rows, "\n"), nil
+}
+
+// cell renders a value on one line, escaped for a markdown table and
+// shortened to maxCellLength.
+func cell(v interface{}) string {
+	var s string
+	if str, ok := v.(string); ok {
+		s = str
+	} else {
+		data, _ := json.Marshal(v)
+		s = string(data)
+	}
+	s = strings.ReplaceAll(s, "\n", "⏎")
+	if len([]rune(s)) > maxCellLength {
+		s = string([]rune(s)[:maxCellLength-1]) + "…"
+	}
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return "`" + strings.ReplaceAll(s, "`", "'") + "`"
+}
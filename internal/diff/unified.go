@@ -0,0 +1,171 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// contextLines is how many unchanged lines surround each hunk.
+const contextLines = 3
+
+// unifiedRenderer renders a unified diff of the YAML of both documents.
+type unifiedRenderer struct{}
+
+func (unifiedRenderer) Legend() string {
+	return "Legend: - removed, + added"
+}
+
+func (unifiedRenderer) Render(before, after map[string]interface{}) (string, error) {
+	a, err := yamlLines(before)
+	if err != nil {
+		return "", err
+	}
+	b, err := yamlLines(after)
+	if err != nil {
+		return "", err
+	}
+
+	edits := lineEdits(a, b)
+	var out strings.Builder
+	for _, h := range hunks(edits) {
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", h.beforeStart, h.beforeLen, h.afterStart, h.afterLen)
+		for _, e := range edits[h.from:h.to] {
+			out.WriteByte(e.op)
+			out.WriteString(e.line)
+			out.WriteByte('\n')
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+func yamlLines(doc map[string]interface{}) ([]string, error) {
+	if len(doc) == 0 {
+		return nil, nil
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render YAML: %w", err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
+}
+
+// edit is one line of a line diff: ' ' kept, '-' removed or '+' added.
+type edit struct {
+	op   byte
+	line string
+}
+
+// lineEdits computes a shortest edit script from a to b through their
+// longest common subsequence. Common leading and trailing lines are matched
+// first, which keeps the table small for typical manifest changes.
+func lineEdits(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []edit
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{' ', line})
+	}
+
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			edits = append(edits, edit{' ', x[i]})
+			i++
+			j++
+		case j < len(y) && (i == len(x) || lcs[i][j+1] > lcs[i+1][j]):
+			edits = append(edits, edit{'+', y[j]})
+			j++
+		default:
+			edits = append(edits, edit{'-', x[i]})
+			i++
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{' ', line})
+	}
+	return edits
+}
+
+// hunk is a run of edits with its surrounding context and the 1-based line
+// ranges it covers on each side.
+type hunk struct {
+	from, to               int
+	beforeStart, beforeLen int
+	afterStart, afterLen   int
+}
+
+// hunks groups changed lines that are close together, with contextLines of
+// unchanged lines around them.
+func hunks(edits []edit) []hunk {
+	var out []hunk
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		from := max(i-contextLines, 0)
+		// Extend while the next change is within two contexts of this one
+		to, unchanged := i, 0
+		for to < len(edits) && unchanged <= 2*contextLines {
+			if edits[to].op == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+			to++
+		}
+		to -= max(unchanged-contextLines, 0)
+
+		h := hunk{from: from, to: to}
+		for _, e := range edits[:from] {
+			if e.op != '+' {
+				h.beforeStart++
+			}
+			if e.op != '-' {
+				h.afterStart++
+			}
+		}
+		for _, e := range edits[from:to] {
+			if e.op != '+' {
+				h.beforeLen++
+			}
+			if e.op != '-' {
+				h.afterLen++
+			}
+		}
+		if h.beforeLen > 0 {
+			h.beforeStart++
+		}
+		if h.afterLen > 0 {
+			h.afterStart++
+		}
+		out = append(out, h)
+		i = to
+	}
+	return out
+}
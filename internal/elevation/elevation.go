@@ -0,0 +1,234 @@
+// Package elevation grants sessions time-boxed rights to change the cluster
+// when the server gates changes behind elevation. A session requests elevation with a reason; an
+// operator approves it out-of-band by handing over a token derived from the
+// request ID and a secret the server shares only with operators. Every step
+// is written to the audit log.
+package elevation
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kagent-dev/meta-kagent/internal/state"
+)
+
+// requestTTL is how long a request can be approved.
+const requestTTL = 15 * time.Minute
+
+// tokenLength is the number of hex characters in an approval token.
+const tokenLength = 16
+
+// Request is a pending request for elevated access.
+type Request struct {
+	ID        string        `json:"id"`
+	Owner     string        `json:"owner,omitempty"`
+	Reason    string        `json:"reason"`
+	Duration  time.Duration `json:"-"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+// Grant is an approved elevation of a session.
+type Grant struct {
+	RequestID string    `json:"requestId"`
+	Owner     string    `json:"owner,omitempty"`
+	Reason    string    `json:"reason"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Token returns the approval token of a request, derived from the operator
+// secret so that only holders of the secret can produce it.
+func Token(secret, requestID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(requestID))
+	return hex.EncodeToString(mac.Sum(nil))[:tokenLength]
+}
+
+// Store keeps pending requests and active grants per session owner in a
+// state store, so that with persistence configured they survive restarts.
+// It is safe for concurrent use.
+type Store struct {
+	secret string
+	audit  io.Writer
+	state  state.Store
+
+	// mu serializes read-modify-write cycles and audit writes.
+	mu sync.Mutex
+}
+
+// storedRequest is a request as kept in the state store, with the duration
+// that Request leaves out of its JSON.
+type storedRequest struct {
+	Request
+	DurationSeconds int64 `json:"durationSeconds"`
+}
+
+// NewStore creates a store approving requests with secret, keeping state in
+// st and writing audit records to audit.
+func NewStore(secret string, audit io.Writer, st state.Store) *Store {
+	return &Store{
+		secret: secret,
+		audit:  audit,
+		state:  st,
+	}
+}
+
+// Request records a request by owner for elevated access lasting duration.
+func (s *Store) Request(ctx context.Context, owner, reason string, duration time.Duration) (Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	r := Request{
+		ID:        "elev-" + hex.EncodeToString(b),
+		Owner:     owner,
+		Reason:    reason,
+		Duration:  duration,
+		CreatedAt: time.Now(),
+	}
+	stored := storedRequest{Request: r, DurationSeconds: int64(duration / time.Second)}
+	if err := state.PutJSON(ctx, s.state, requestKey(r.ID), stored, requestTTL); err != nil {
+		return Request{}, err
+	}
+	s.record("elevation.requested", owner, r.ID, map[string]interface{}{"reason": reason, "duration": duration.String()})
+	return r, nil
+}
+
+// Approve grants the elevation requested under id when token matches. Only
+// the session that made the request may approve it.
+func (s *Store) Approve(ctx context.Context, id, owner, token string) (Grant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var r storedRequest
+	ok, err := state.GetJSON(ctx, s.state, requestKey(id), &r)
+	if err != nil {
+		return Grant{}, err
+	}
+	if !ok || r.Owner != owner {
+		return Grant{}, fmt.Errorf("elevation request '%s' not found or expired", id)
+	}
+	if !hmac.Equal([]byte(token), []byte(Token(s.secret, id))) {
+		s.record("elevation.rejected", owner, id, nil)
+		return Grant{}, errors.New("invalid approval token")
+	}
+
+	duration := time.Duration(r.DurationSeconds) * time.Second
+	g := Grant{
+		RequestID: id,
+		Owner:     owner,
+		Reason:    r.Reason,
+		ExpiresAt: time.Now().Add(duration),
+	}
+	// Keep the grant past its expiry so Active can audit the expiry
+	if err := state.PutJSON(ctx, s.state, grantKey(owner), g, duration+requestTTL); err != nil {
+		return Grant{}, err
+	}
+	if _, err := s.state.Delete(ctx, requestKey(id)); err != nil {
+		return Grant{}, err
+	}
+	s.record("elevation.granted", owner, id, map[string]interface{}{"expiresAt": g.ExpiresAt.UTC().Format(time.RFC3339)})
+	return g, nil
+}
+
+// Active returns the unexpired grant of owner.
+func (s *Store) Active(ctx context.Context, owner string) (Grant, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var g Grant
+	ok, err := state.GetJSON(ctx, s.state, grantKey(owner), &g)
+	if err != nil || !ok {
+		return Grant{}, false, err
+	}
+	if time.Now().After(g.ExpiresAt) {
+		if _, err := s.state.Delete(ctx, grantKey(owner)); err != nil {
+			return Grant{}, false, err
+		}
+		s.record("elevation.expired", owner, g.RequestID, nil)
+		return Grant{}, false, nil
+	}
+	return g, true, nil
+}
+
+// Pending returns the requests of owner awaiting approval.
+func (s *Store) Pending(ctx context.Context, owner string) ([]Request, error) {
+	values, err := s.state.List(ctx, requestKey(""))
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Request
+	for _, value := range values {
+		var r storedRequest
+		if json.Unmarshal(value, &r) != nil || r.Owner != owner {
+			continue
+		}
+		r.Duration = time.Duration(r.DurationSeconds) * time.Second
+		pending = append(pending, r.Request)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].CreatedAt.Before(pending[j].CreatedAt) })
+	return pending, nil
+}
+
+// Release ends the grant of owner before it expires. It reports whether
+// there was one.
+func (s *Store) Release(ctx context.Context, owner string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var g Grant
+	ok, err := state.GetJSON(ctx, s.state, grantKey(owner), &g)
+	if err != nil || !ok {
+		return false, err
+	}
+	if _, err := s.state.Delete(ctx, grantKey(owner)); err != nil {
+		return false, err
+	}
+	if time.Now().After(g.ExpiresAt) {
+		s.record("elevation.expired", owner, g.RequestID, nil)
+		return false, nil
+	}
+	s.record("elevation.released", owner, g.RequestID, nil)
+	return true, nil
+}
+
+// Audit records a change made under a grant.
+func (s *Store) Audit(owner, tool string, g Grant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record("elevation.used", owner, g.RequestID, map[string]interface{}{"tool": tool})
+}
+
+func requestKey(id string) string {
+	return "elevation/request/" + id
+}
+
+func grantKey(owner string) string {
+	return "elevation/grant/" + owner
+}
+
+// record writes an audit record as a JSON line. The caller holds mu.
+func (s *Store) record(event, owner, requestID string, fields map[string]interface{}) {
+	entry := map[string]interface{}{
+		"audit":   event,
+		"time":    time.Now().UTC().Format(time.RFC3339),
+		"owner":   owner,
+		"request": requestID,
+	}
+	for k, v := range fields {
+		entry[k] = v
+	}
+	line, _ := json.Marshal(entry)
+	fmt.Fprintf(s.audit, "%s\n", line)
+}
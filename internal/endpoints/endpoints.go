@@ -0,0 +1,203 @@
+// Package endpoints builds the public URLs of agents' A2A endpoints from
+// templates configured centrally per environment, e.g. *.dev.corp for dev
+// and *.ai.corp for prod, and checks URLs against the domains each
+// environment allows.
+package endpoints
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/clusterfacts"
+)
+
+// Placeholders of URL templates.
+const (
+	PlaceholderAgent       = "{{agent}}"
+	PlaceholderNamespace   = "{{namespace}}"
+	PlaceholderEnvironment = "{{environment}}"
+)
+
+// Environment is where agents are served and how they are reached there.
+type Environment struct {
+	Name string `json:"name"`
+	// URLTemplate is the endpoint URL of an agent, with the {{agent}},
+	// {{namespace}} and {{environment}} placeholders and those of cluster
+	// facts, e.g. {{ingress_domain}}.
+	URLTemplate string `json:"urlTemplate"`
+	// AllowedDomains are the domains endpoint URLs may use. A domain
+	// allows its subdomains too; "*.ai.corp" allows only subdomains.
+	AllowedDomains []string `json:"allowedDomains,omitempty"`
+}
+
+// file is the layout of the environments file.
+type file struct {
+	// Default is the environment used when a call names none.
+	Default      string        `json:"default,omitempty"`
+	Environments []Environment `json:"environments"`
+}
+
+// Environments are the configured environments. A nil *Environments has
+// none.
+type Environments struct {
+	byName map[string]*Environment
+	names  []string
+	def    string
+}
+
+// Load reads the environments file at path. current, when set, overrides
+// the file's default environment.
+func Load(path, current string) (*Environments, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read environments: %w", err)
+	}
+	var f file
+	if err := yaml.UnmarshalStrict(raw, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse environments %s: %w", path, err)
+	}
+
+	e := &Environments{byName: map[string]*Environment{}, def: f.Default}
+	for i := range f.Environments {
+		env := &f.Environments[i]
+		if env.Name == "" {
+			return nil, fmt.Errorf("environment %d: name is required", i)
+		}
+		if _, ok := e.byName[env.Name]; ok {
+			return nil, fmt.Errorf("environment %q is defined more than once", env.Name)
+		}
+		for j, domain := range env.AllowedDomains {
+			env.AllowedDomains[j] = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+		}
+		// A template must produce URLs its own environment accepts. The
+		// domain of one using cluster facts is only known once rendered.
+		sample := clusterfacts.Sample(env.expand("agent", "namespace"))
+		if _, err := endpointHost(sample); err != nil {
+			return nil, fmt.Errorf("environment %q: urlTemplate: %w", env.Name, err)
+		}
+		if err := env.Check(sample); err != nil && !clusterfacts.Uses(env.URLTemplate) {
+			return nil, fmt.Errorf("environment %q: urlTemplate: %w", env.Name, err)
+		}
+		e.byName[env.Name] = env
+		e.names = append(e.names, env.Name)
+	}
+	sort.Strings(e.names)
+
+	if current != "" {
+		e.def = current
+	}
+	if _, ok := e.byName[e.def]; e.def != "" && !ok {
+		return nil, fmt.Errorf("default environment %q is not defined", e.def)
+	}
+	return e, nil
+}
+
+// Names returns the names of the environments, sorted.
+func (e *Environments) Names() []string {
+	if e == nil {
+		return nil
+	}
+	return e.names
+}
+
+// Lookup returns the named environment, or the default one when name is
+// empty. It returns nil without an error when no environment applies.
+func (e *Environments) Lookup(name string) (*Environment, error) {
+	if name == "" {
+		if e == nil || e.def == "" {
+			return nil, nil
+		}
+		name = e.def
+	}
+	if e == nil {
+		return nil, fmt.Errorf("unknown environment '%s': no environments are configured (KAGENT_ENVIRONMENTS_FILE)", name)
+	}
+	env, ok := e.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown environment '%s' (expected one of %s)", name, strings.Join(e.names, ", "))
+	}
+	return env, nil
+}
+
+// URL returns the endpoint URL of an agent in the environment, in the
+// cluster facts describe. It fails when the template uses a fact that is
+// not known.
+func (env *Environment) URL(agent, namespace string, facts *clusterfacts.Facts) (string, error) {
+	endpointURL, err := facts.Expand(env.expand(agent, namespace))
+	if err != nil {
+		return "", fmt.Errorf("environment '%s': urlTemplate: %w", env.Name, err)
+	}
+	return endpointURL, nil
+}
+
+// Host returns the hostname of an agent's endpoint in the environment.
+func (env *Environment) Host(agent, namespace string, facts *clusterfacts.Facts) (string, error) {
+	endpointURL, err := env.URL(agent, namespace, facts)
+	if err != nil {
+		return "", err
+	}
+	return endpointHost(endpointURL)
+}
+
+// expand substitutes the agent placeholders of the URL template.
+func (env *Environment) expand(agent, namespace string) string {
+	return strings.NewReplacer(
+		PlaceholderAgent, agent,
+		PlaceholderNamespace, namespace,
+		PlaceholderEnvironment, env.Name,
+	).Replace(env.URLTemplate)
+}
+
+// Check reports why an endpoint URL does not belong in the environment,
+// or nil when its host is in one of the allowed domains. Environments
+// without allowed domains accept any URL.
+func (env *Environment) Check(rawURL string) error {
+	host, err := endpointHost(rawURL)
+	if err != nil {
+		return err
+	}
+	if len(env.AllowedDomains) == 0 {
+		return nil
+	}
+	if !env.AllowsHost(host) {
+		return fmt.Errorf("host '%s' is outside the domains of environment '%s' (%s)", host, env.Name, strings.Join(env.AllowedDomains, ", "))
+	}
+	return nil
+}
+
+// AllowsHost reports whether a hostname is in one of the environment's
+// allowed domains.
+func (env *Environment) AllowsHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, domain := range env.AllowedDomains {
+		if sub, ok := strings.CutPrefix(domain, "*."); ok {
+			if strings.HasSuffix(host, "."+sub) {
+				return true
+			}
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// endpointHost returns the hostname of an http(s) endpoint URL.
+func endpointHost(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", fmt.Errorf("'%s' is not an http(s) URL", rawURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+		return "", fmt.Errorf("'%s' is not a valid hostname: %s", host, strings.Join(errs, "; "))
+	}
+	return host, nil
+}
@@ -0,0 +1,398 @@
+// Package i18n translates the text the server shows to people: tool and
+// argument descriptions, and the messages of tool results. English is
+// built in. As with gettext, a catalog is keyed by the English text, so it
+// lists only what it translates and anything it leaves out stays in
+// English; handlers keep their string literals.
+package i18n
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultLocale is the language of the built-in text.
+const DefaultLocale = "en"
+
+// file is the on-disk format of a message catalog.
+type file struct {
+	// Locale is the language the catalog translates to, e.g. "de" or
+	// "pt-BR".
+	Locale string `json:"locale"`
+	// Tools translates tool descriptions by tool name.
+	Tools map[string]toolText `json:"tools,omitempty"`
+	// Arguments translates argument descriptions by argument name, for
+	// every tool that does not translate the argument itself.
+	Arguments map[string]string `json:"arguments,omitempty"`
+	// Messages translates lines of tool results, keyed by the format
+	// string the server writes them with, e.g. "Agent not found: %v".
+	Messages map[string]string `json:"messages,omitempty"`
+}
+
+type toolText struct {
+	Description string            `json:"description,omitempty"`
+	Arguments   map[string]string `json:"arguments,omitempty"`
+}
+
+// Catalog holds the translations of one locale. A nil Catalog translates
+// nothing.
+type Catalog struct {
+	locale    string
+	tools     map[string]toolText
+	arguments map[string]string
+	// exact holds messages without verbs, patterns the others.
+	exact    map[string]string
+	patterns []*message
+}
+
+// Load reads the catalogs for locale from paths, which are catalog files
+// or directories of them (.yaml, .yml or .json). A catalog for the base
+// language ("pt") applies to regional locales ("pt-BR"), which override
+// it. It returns nil for the default locale when no catalog rewords it.
+func Load(locale string, paths []string) (*Catalog, error) {
+	locale = strings.TrimSpace(locale)
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	base, _, _ := strings.Cut(locale, "-")
+
+	var general, specific []file
+	for _, path := range paths {
+		files, err := readCatalogs(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			switch {
+			case strings.EqualFold(f.Locale, locale):
+				specific = append(specific, f)
+			case strings.EqualFold(f.Locale, base):
+				general = append(general, f)
+			}
+		}
+	}
+	if len(general)+len(specific) == 0 {
+		if strings.EqualFold(base, DefaultLocale) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("no message catalog for locale %q", locale)
+	}
+
+	c := &Catalog{
+		locale:    locale,
+		tools:     map[string]toolText{},
+		arguments: map[string]string{},
+		exact:     map[string]string{},
+	}
+	byFormat := map[string]*message{}
+	for _, f := range append(general, specific...) {
+		for name, t := range f.Tools {
+			merged := c.tools[name]
+			if t.Description != "" {
+				merged.Description = t.Description
+			}
+			for arg, text := range t.Arguments {
+				if merged.Arguments == nil {
+					merged.Arguments = map[string]string{}
+				}
+				merged.Arguments[arg] = text
+			}
+			c.tools[name] = merged
+		}
+		for arg, text := range f.Arguments {
+			c.arguments[arg] = text
+		}
+		for source, target := range f.Messages {
+			m, err := compile(source, target)
+			if err != nil {
+				return nil, fmt.Errorf("catalog %q: message %q: %w", f.Locale, source, err)
+			}
+			if m.pattern == nil {
+				c.exact[m.literal] = m.parts[0]
+				continue
+			}
+			if existing, ok := byFormat[source]; ok {
+				*existing = *m
+				continue
+			}
+			byFormat[source] = m
+			c.patterns = append(c.patterns, m)
+		}
+	}
+
+	// A line written with "Agent %s not found: %v" also matches
+	// "Agent %s", so the most specific message is tried first
+	sort.SliceStable(c.patterns, func(i, j int) bool {
+		if len(c.patterns[i].literal) != len(c.patterns[j].literal) {
+			return len(c.patterns[i].literal) > len(c.patterns[j].literal)
+		}
+		return c.patterns[i].pattern.String() < c.patterns[j].pattern.String()
+	})
+	return c, nil
+}
+
+// readCatalogs parses the catalog at path, or every catalog in the
+// directory at path.
+func readCatalogs(path string) ([]file, error) {
+	var files []file
+	err := filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			if p != path {
+				return nil
+			}
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		var f file
+		if err := yaml.UnmarshalStrict(data, &f); err != nil {
+			return fmt.Errorf("failed to parse message catalog %s: %w", p, err)
+		}
+		if f.Locale == "" {
+			return fmt.Errorf("message catalog %s: locale is required", p)
+		}
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message catalogs: %w", err)
+	}
+	return files, nil
+}
+
+// Locale returns the locale the catalog translates to.
+func (c *Catalog) Locale() string {
+	if c == nil {
+		return DefaultLocale
+	}
+	return c.locale
+}
+
+// Tool returns tool with its description and argument descriptions
+// translated.
+func (c *Catalog) Tool(tool mcp.Tool) mcp.Tool {
+	if c == nil {
+		return tool
+	}
+	t := c.tools[tool.Name]
+	if t.Description != "" {
+		tool.Description = t.Description
+	}
+
+	// Properties are shared with the tool definition, so translated ones
+	// are copied
+	properties := make(map[string]interface{}, len(tool.InputSchema.Properties))
+	for arg, schema := range tool.InputSchema.Properties {
+		properties[arg] = schema
+		text := t.Arguments[arg]
+		if text == "" {
+			text = c.arguments[arg]
+		}
+		prop, ok := schema.(map[string]interface{})
+		if text == "" || !ok {
+			continue
+		}
+		translated := make(map[string]interface{}, len(prop))
+		for k, v := range prop {
+			translated[k] = v
+		}
+		translated["description"] = text
+		properties[arg] = translated
+	}
+	tool.InputSchema.Properties = properties
+	return tool
+}
+
+// Result translates the text of a tool result in place: the whole text
+// when the catalog has it, otherwise line by line. Lines the catalog does
+// not have, such as generated manifests, are left alone.
+func (c *Catalog) Result(result *mcp.CallToolResult) {
+	if c == nil || result == nil {
+		return
+	}
+	for i, content := range result.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+		text.Text = c.Text(text.Text)
+		result.Content[i] = text
+	}
+}
+
+// Text translates a result text.
+func (c *Catalog) Text(text string) string {
+	if c == nil {
+		return text
+	}
+	if translated, ok := c.line(text); ok {
+		return translated
+	}
+	if !strings.Contains(text, "\n") {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		// Keep the indentation of list items and nested lines
+		trimmed := strings.TrimLeft(line, " \t")
+		if translated, ok := c.line(trimmed); ok {
+			lines[i] = line[:len(line)-len(trimmed)] + translated
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *Catalog) line(s string) (string, bool) {
+	if s == "" {
+		return s, false
+	}
+	if translated, ok := c.exact[s]; ok {
+		return translated, true
+	}
+	for _, m := range c.patterns {
+		if translated, ok := m.apply(s); ok {
+			return translated, true
+		}
+	}
+	return s, false
+}
+
+// verbPattern matches the fmt verbs of a format string, and "%%".
+var verbPattern = regexp.MustCompile(`%(\[(\d+)\])?[-+# 0]*(\d+|\*)?(\.(\d+|\*)?)?[a-zA-Z%]`)
+
+// message translates the lines written with one format string.
+type message struct {
+	// prefix is the literal text before the first verb, checked before
+	// the pattern.
+	prefix string
+	// literal is the text of the format without its verbs: the whole
+	// message when it has none.
+	literal string
+	pattern *regexp.Regexp
+	// args maps the pattern's groups to the format's arguments (0-based).
+	args []int
+	// target is the translation, split around its verbs: parts has one
+	// more element than refs, which are the arguments the verbs print.
+	parts []string
+	refs  []int
+}
+
+// compile turns a format string and its translation into a message, with
+// a nil pattern when the format has no verbs.
+func compile(source, target string) (*message, error) {
+	m := &message{}
+	var expr strings.Builder
+	expr.WriteString("^")
+	next, last := 0, 0
+	literal := ""
+	for _, loc := range verbPattern.FindAllStringSubmatchIndex(source, -1) {
+		literal += source[last:loc[0]]
+		expr.WriteString(regexp.QuoteMeta(source[last:loc[0]]))
+		last = loc[1]
+		if source[loc[1]-1] == '%' {
+			literal += "%"
+			expr.WriteString("%")
+			continue
+		}
+		if m.pattern == nil && len(m.args) == 0 {
+			m.prefix = literal
+		}
+		index := next
+		if loc[4] >= 0 {
+			n, _ := strconv.Atoi(source[loc[4]:loc[5]])
+			index = n - 1
+		}
+		next = index + 1
+		m.args = append(m.args, index)
+		expr.WriteString("(.*?)")
+	}
+	literal += source[last:]
+	expr.WriteString(regexp.QuoteMeta(source[last:]) + "$")
+
+	parts, refs, err := splitTarget(target, next)
+	if err != nil {
+		return nil, err
+	}
+	m.parts, m.refs, m.literal = parts, refs, literal
+	if len(m.args) == 0 {
+		return m, nil
+	}
+	if strings.TrimSpace(literal) == "" {
+		return nil, fmt.Errorf("a message needs text besides its verbs")
+	}
+	m.pattern = regexp.MustCompile("(?s)" + expr.String())
+	return m, nil
+}
+
+// splitTarget splits a translation around its verbs, which print the
+// arguments in order unless indexed ("%[2]s").
+func splitTarget(target string, argc int) ([]string, []int, error) {
+	var parts []string
+	var refs []int
+	current := ""
+	next, last := 0, 0
+	for _, loc := range verbPattern.FindAllStringSubmatchIndex(target, -1) {
+		current += target[last:loc[0]]
+		last = loc[1]
+		if target[loc[1]-1] == '%' {
+			current += "%"
+			continue
+		}
+		index := next
+		if loc[4] >= 0 {
+			n, _ := strconv.Atoi(target[loc[4]:loc[5]])
+			index = n - 1
+		}
+		if index < 0 || index >= argc {
+			return nil, nil, fmt.Errorf("translation refers to argument %d, the message has %d", index+1, argc)
+		}
+		next = index + 1
+		parts = append(parts, current)
+		refs = append(refs, index)
+		current = ""
+	}
+	parts = append(parts, current+target[last:])
+	return parts, refs, nil
+}
+
+// apply translates s when it was written with the message's format.
+func (m *message) apply(s string) (string, bool) {
+	if !strings.HasPrefix(s, m.prefix) {
+		return "", false
+	}
+	groups := m.pattern.FindStringSubmatch(s)
+	if groups == nil {
+		return "", false
+	}
+	values := map[int]string{}
+	for i, index := range m.args {
+		if _, ok := values[index]; !ok {
+			values[index] = groups[i+1]
+		}
+	}
+
+	var b strings.Builder
+	for i, ref := range m.refs {
+		b.WriteString(m.parts[i])
+		b.WriteString(values[ref])
+	}
+	b.WriteString(m.parts[len(m.parts)-1])
+	return b.String(), true
+}
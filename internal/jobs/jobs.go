@@ -0,0 +1,183 @@
+// Package jobs runs long operations in the background so tool calls can
+// return a job ID immediately instead of hitting client timeouts.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Job statuses.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// jobTTL is how long finished jobs are kept for polling.
+const jobTTL = time.Hour
+
+// Func is the work performed by a job. The returned text is the job result.
+type Func func(ctx context.Context) (string, error)
+
+// Job is the state of a submitted job.
+type Job struct {
+	ID         string     `json:"id"`
+	Operation  string     `json:"operation"`
+	Owner      string     `json:"owner,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Done reports whether the job has finished.
+func (j *Job) Done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// Manager queues jobs and runs them on a fixed number of workers.
+type Manager struct {
+	queue   workqueue.Interface
+	timeout time.Duration
+
+	mu     sync.Mutex
+	jobs   map[string]*Job
+	funcs  map[string]Func
+	nextID int
+}
+
+// NewManager starts a manager with the given number of workers. Each job is
+// cancelled after timeout. Workers stop when ctx is cancelled.
+func NewManager(ctx context.Context, workers int, timeout time.Duration) *Manager {
+	if workers < 1 {
+		workers = 1
+	}
+
+	m := &Manager{
+		queue:   workqueue.NewNamed("kmeta-agent-jobs"),
+		timeout: timeout,
+		jobs:    make(map[string]*Job),
+		funcs:   make(map[string]Func),
+	}
+	for i := 0; i < workers; i++ {
+		go m.worker(ctx)
+	}
+	go func() {
+		<-ctx.Done()
+		m.queue.ShutDown()
+	}()
+	return m
+}
+
+// Submit queues fn on behalf of owner (empty for the server's own
+// sessions) and returns the new job.
+func (m *Manager) Submit(operation, owner string, fn Func) Job {
+	m.mu.Lock()
+	m.evictExpired()
+	m.nextID++
+	job := &Job{
+		ID:        fmt.Sprintf("job-%d-%d", time.Now().Unix(), m.nextID),
+		Operation: operation,
+		Owner:     owner,
+		Status:    StatusQueued,
+		CreatedAt: time.Now().UTC(),
+	}
+	m.jobs[job.ID] = job
+	m.funcs[job.ID] = fn
+	snapshot := *job
+	m.mu.Unlock()
+
+	m.queue.Add(job.ID)
+	return snapshot
+}
+
+// Get returns a copy of the job with the given ID.
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evictExpired()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// List returns copies of all known jobs, newest first.
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evictExpired()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+func (m *Manager) worker(ctx context.Context) {
+	for {
+		item, shutdown := m.queue.Get()
+		if shutdown {
+			return
+		}
+		m.run(ctx, item.(string))
+		m.queue.Done(item)
+	}
+}
+
+func (m *Manager) run(ctx context.Context, id string) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	fn := m.funcs[id]
+	delete(m.funcs, id)
+	if !ok || fn == nil {
+		m.mu.Unlock()
+		return
+	}
+	started := time.Now().UTC()
+	job.Status = StatusRunning
+	job.StartedAt = &started
+	m.mu.Unlock()
+
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+	result, err := fn(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	finished := time.Now().UTC()
+	job.FinishedAt = &finished
+	job.Result = result
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		return
+	}
+	job.Status = StatusSucceeded
+}
+
+func (m *Manager) evictExpired() {
+	for id, job := range m.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > jobTTL {
+			delete(m.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupVersionResource definitions for core resources used by capacity checks.
+var (
+	ResourceQuotaGVR = schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "resourcequotas",
+	}
+
+	NodeGVR = schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "nodes",
+	}
+)
+
+// ResourceQuota is the hard limits and current usage of a ResourceQuota.
+type ResourceQuota struct {
+	Name string
+	Hard map[string]resource.Quantity
+	Used map[string]resource.Quantity
+}
+
+// NodeCapacity is the allocatable capacity of a node.
+type NodeCapacity struct {
+	Name          string
+	CPU           resource.Quantity
+	Memory        resource.Quantity
+	Unschedulable bool
+}
+
+// ResourceQuotas returns the ResourceQuotas of a namespace. known is false
+// when the identity is not allowed to list them.
+func (c *Client) ResourceQuotas(ctx context.Context, namespace string) (quotas []ResourceQuota, known bool, err error) {
+	list, err := c.dynamicClient.Resource(ResourceQuotaGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsForbidden(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list resource quotas in %s: %w", namespace, err)
+	}
+
+	for _, item := range list.Items {
+		hard, _, _ := unstructured.NestedStringMap(item.Object, "status", "hard")
+		if hard == nil {
+			hard, _, _ = unstructured.NestedStringMap(item.Object, "spec", "hard")
+		}
+		used, _, _ := unstructured.NestedStringMap(item.Object, "status", "used")
+		quotas = append(quotas, ResourceQuota{
+			Name: item.GetName(),
+			Hard: parseQuantities(hard),
+			Used: parseQuantities(used),
+		})
+	}
+	return quotas, true, nil
+}
+
+// Nodes returns the allocatable capacity of the cluster's nodes. known is
+// false when the identity is not allowed to list nodes.
+func (c *Client) Nodes(ctx context.Context) (nodes []NodeCapacity, known bool, err error) {
+	list, err := c.dynamicClient.Resource(NodeGVR).List(ctx, metav1.ListOptions{})
+	if apierrors.IsForbidden(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	for _, item := range list.Items {
+		allocatable, _, _ := unstructured.NestedStringMap(item.Object, "status", "allocatable")
+		quantities := parseQuantities(allocatable)
+		unschedulable, _, _ := unstructured.NestedBool(item.Object, "spec", "unschedulable")
+		nodes = append(nodes, NodeCapacity{
+			Name:          item.GetName(),
+			CPU:           quantities["cpu"],
+			Memory:        quantities["memory"],
+			Unschedulable: unschedulable,
+		})
+	}
+	return nodes, true, nil
+}
+
+// parseQuantities parses resource quantities, skipping malformed values.
+func parseQuantities(values map[string]string) map[string]resource.Quantity {
+	quantities := make(map[string]resource.Quantity, len(values))
+	for name, v := range values {
+		if q, err := resource.ParseQuantity(v); err == nil {
+			quantities[name] = q
+		}
+	}
+	return quantities
+}
@@ -3,13 +3,22 @@ package kubernetes
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/yaml"
@@ -20,7 +29,13 @@ import (
 // Client wraps the Kubernetes dynamic client for kagent resources.
 type Client struct {
 	dynamicClient dynamic.Interface
+	mapper        *RESTMapper
 	namespace     string
+	config        *rest.Config
+	informers     *InformerCache
+	// contextCluster is the cluster of the kubeconfig's current context,
+	// empty in-cluster.
+	contextCluster string
 }
 
 // GroupVersionResource definitions for kagent CRDs.
@@ -53,6 +68,7 @@ var (
 // NewClient creates a new Kubernetes client.
 // It tries in-cluster config first, then falls back to kubeconfig.
 func NewClient(namespace string) (*Client, error) {
+	var contextCluster string
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		// Fall back to kubeconfig
@@ -63,6 +79,11 @@ func NewClient(namespace string) (*Client, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create kubernetes config: %w", err)
 		}
+		if raw, err := kubeConfig.RawConfig(); err == nil {
+			if current, ok := raw.Contexts[raw.CurrentContext]; ok {
+				contextCluster = current.Cluster
+			}
+		}
 	}
 
 	dynamicClient, err := dynamic.NewForConfig(config)
@@ -70,33 +91,148 @@ func NewClient(namespace string) (*Client, error) {
 		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
+	mapper, err := NewRESTMapper(config)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Client{
-		dynamicClient: dynamicClient,
-		namespace:     namespace,
+		dynamicClient:  dynamicClient,
+		mapper:         mapper,
+		namespace:      namespace,
+		config:         config,
+		contextCluster: contextCluster,
 	}, nil
 }
 
+// Impersonate returns a client that acts as the given ServiceAccount and is
+// scoped to namespace. Every request it makes is authorized against the
+// ServiceAccount's RBAC, not the server's. The REST mapper is shared.
+func (c *Client) Impersonate(serviceAccountNamespace, serviceAccount, namespace string) (*Client, error) {
+	config := rest.CopyConfig(c.config)
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", serviceAccountNamespace, serviceAccount),
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client for %s/%s: %w", serviceAccountNamespace, serviceAccount, err)
+	}
+
+	return &Client{
+		dynamicClient:  dynamicClient,
+		mapper:         c.mapper,
+		namespace:      namespace,
+		config:         config,
+		contextCluster: c.contextCluster,
+	}, nil
+}
+
+// InNamespace returns a client scoped to namespace that shares the
+// connection, identity and REST mapper of c.
+func (c *Client) InNamespace(namespace string) *Client {
+	scoped := *c
+	scoped.namespace = namespace
+	return &scoped
+}
+
+// WithInformerCache returns a client that reads the kinds in cache from it
+// when operating in the cache's namespace. Impersonating clients never use
+// it, since the cache was filled with the server's identity.
+func (c *Client) WithInformerCache(cache *InformerCache) *Client {
+	cached := *c
+	cached.informers = cache
+	return &cached
+}
+
+// Uncached returns a client that always reads from the API server.
+func (c *Client) Uncached() *Client {
+	if c.informers == nil {
+		return c
+	}
+	uncached := *c
+	uncached.informers = nil
+	return &uncached
+}
+
+// AllNamespaces returns a client whose list calls span every namespace the
+// identity may read. It is only meant for listing: namespaced gets, applies
+// and deletes need a concrete namespace.
+func (c *Client) AllNamespaces() *Client {
+	return c.InNamespace(metav1.NamespaceAll)
+}
+
+// ListOptions filter and page list calls. The zero value lists every
+// resource in the namespace.
+type ListOptions struct {
+	// LabelSelector restricts the list to resources with matching labels
+	// (e.g., "team=platform,tier!=experimental").
+	LabelSelector string
+	// FieldSelector restricts the list by field; custom resources support
+	// metadata.name and metadata.namespace.
+	FieldSelector string
+	// Limit is the maximum number of resources returned; 0 means no limit.
+	Limit int64
+	// Continue is the token returned by a previous limited list call, to
+	// fetch the next page.
+	Continue string
+}
+
+// cacheContinuePrefix marks continue tokens issued for pages read from the
+// informer cache, which are not valid for the API server.
+const cacheContinuePrefix = "cache:"
+
+// cacheable reports whether the informer cache can answer the list: it
+// filters by label and pages by name, but cannot select fields or continue
+// a list the API server started.
+func (o ListOptions) cacheable() bool {
+	return o.FieldSelector == "" && (o.Continue == "" || strings.HasPrefix(o.Continue, cacheContinuePrefix))
+}
+
+func (o ListOptions) toMeta() metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: o.LabelSelector,
+		FieldSelector: o.FieldSelector,
+		Limit:         o.Limit,
+		Continue:      o.Continue,
+	}
+}
+
 // ListAgents lists all agents in the configured namespace.
 func (c *Client) ListAgents(ctx context.Context) ([]types.Agent, error) {
-	list, err := c.dynamicClient.Resource(AgentGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	return c.ListAgentsBySelector(ctx, "")
+}
+
+// ListAgentsBySelector lists the agents in the configured namespace that
+// match a label selector (e.g., "team=platform,tier!=experimental").
+func (c *Client) ListAgentsBySelector(ctx context.Context, selector string) ([]types.Agent, error) {
+	agents, _, err := c.ListAgentsWithOptions(ctx, ListOptions{LabelSelector: selector})
+	return agents, err
+}
+
+// ListAgentsWithOptions lists the agents in the configured namespace that
+// match opts. With a limit, it also returns the token to continue from, or
+// "" on the last page.
+func (c *Client) ListAgentsWithOptions(ctx context.Context, opts ListOptions) ([]types.Agent, string, error) {
+	items, next, err := c.listItems(ctx, AgentGVR, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list agents: %w", err)
+		return nil, "", fmt.Errorf("failed to list agents: %w", err)
 	}
 
 	var agents []types.Agent
-	for _, item := range list.Items {
+	for _, item := range items {
 		agent, err := unstructuredToAgent(&item)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		agents = append(agents, *agent)
 	}
-	return agents, nil
+	return agents, next, nil
 }
 
 // GetAgent gets a specific agent by name.
 func (c *Client) GetAgent(ctx context.Context, name string) (*types.Agent, error) {
-	obj, err := c.dynamicClient.Resource(AgentGVR).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	obj, err := c.getItem(ctx, AgentGVR, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agent %s: %w", name, err)
 	}
@@ -105,25 +241,33 @@ func (c *Client) GetAgent(ctx context.Context, name string) (*types.Agent, error
 
 // ListModelConfigs lists all model configs in the configured namespace.
 func (c *Client) ListModelConfigs(ctx context.Context) ([]types.ModelConfig, error) {
-	list, err := c.dynamicClient.Resource(ModelConfigGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	items, _, err := c.ListModelConfigsWithOptions(ctx, ListOptions{})
+	return items, err
+}
+
+// ListModelConfigsWithOptions lists the model configs in the configured
+// namespace that match opts, returning the token to continue from like
+// ListAgentsWithOptions.
+func (c *Client) ListModelConfigsWithOptions(ctx context.Context, opts ListOptions) ([]types.ModelConfig, string, error) {
+	items, next, err := c.listItems(ctx, ModelConfigGVR, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list model configs: %w", err)
+		return nil, "", fmt.Errorf("failed to list model configs: %w", err)
 	}
 
 	var configs []types.ModelConfig
-	for _, item := range list.Items {
+	for _, item := range items {
 		config, err := unstructuredToModelConfig(&item)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		configs = append(configs, *config)
 	}
-	return configs, nil
+	return configs, next, nil
 }
 
 // GetModelConfig gets a specific model config by name.
 func (c *Client) GetModelConfig(ctx context.Context, name string) (*types.ModelConfig, error) {
-	obj, err := c.dynamicClient.Resource(ModelConfigGVR).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	obj, err := c.getItem(ctx, ModelConfigGVR, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get model config %s: %w", name, err)
 	}
@@ -132,76 +276,282 @@ func (c *Client) GetModelConfig(ctx context.Context, name string) (*types.ModelC
 
 // ListMCPServers lists all MCPServers in the configured namespace.
 func (c *Client) ListMCPServers(ctx context.Context) ([]types.MCPServer, error) {
-	list, err := c.dynamicClient.Resource(MCPServerGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	items, _, err := c.ListMCPServersWithOptions(ctx, ListOptions{})
+	return items, err
+}
+
+// ListMCPServersWithOptions lists the MCPServers in the configured namespace
+// that match opts, returning the token to continue from like
+// ListAgentsWithOptions.
+func (c *Client) ListMCPServersWithOptions(ctx context.Context, opts ListOptions) ([]types.MCPServer, string, error) {
+	items, next, err := c.listItems(ctx, MCPServerGVR, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list mcp servers: %w", err)
+		return nil, "", fmt.Errorf("failed to list mcp servers: %w", err)
 	}
 
 	var servers []types.MCPServer
-	for _, item := range list.Items {
+	for _, item := range items {
 		server, err := unstructuredToMCPServer(&item)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		servers = append(servers, *server)
 	}
-	return servers, nil
+	return servers, next, nil
+}
+
+// GetMCPServer gets a specific MCPServer by name.
+func (c *Client) GetMCPServer(ctx context.Context, name string) (*types.MCPServer, error) {
+	obj, err := c.getItem(ctx, MCPServerGVR, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mcp server %s: %w", name, err)
+	}
+	return unstructuredToMCPServer(obj)
 }
 
 // ListRemoteMCPServers lists all RemoteMCPServers in the configured namespace.
 func (c *Client) ListRemoteMCPServers(ctx context.Context) ([]types.RemoteMCPServer, error) {
-	list, err := c.dynamicClient.Resource(RemoteMCPServerGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	items, _, err := c.ListRemoteMCPServersWithOptions(ctx, ListOptions{})
+	return items, err
+}
+
+// ListRemoteMCPServersWithOptions lists the RemoteMCPServers in the
+// configured namespace that match opts, returning the token to continue
+// from like ListAgentsWithOptions.
+func (c *Client) ListRemoteMCPServersWithOptions(ctx context.Context, opts ListOptions) ([]types.RemoteMCPServer, string, error) {
+	items, next, err := c.listItems(ctx, RemoteMCPServerGVR, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list remote mcp servers: %w", err)
+		return nil, "", fmt.Errorf("failed to list remote mcp servers: %w", err)
 	}
 
 	var servers []types.RemoteMCPServer
-	for _, item := range list.Items {
+	for _, item := range items {
 		server, err := unstructuredToRemoteMCPServer(&item)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		servers = append(servers, *server)
 	}
-	return servers, nil
+	return servers, next, nil
 }
 
-// Apply applies a manifest (YAML string) to the cluster.
-func (c *Client) Apply(ctx context.Context, manifest string, dryRun bool) (*ApplyResult, error) {
-	// Parse the manifest
+// GetRemoteMCPServer gets a specific RemoteMCPServer by name.
+func (c *Client) GetRemoteMCPServer(ctx context.Context, name string) (*types.RemoteMCPServer, error) {
+	obj, err := c.getItem(ctx, RemoteMCPServerGVR, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote mcp server %s: %w", name, err)
+	}
+	return unstructuredToRemoteMCPServer(obj)
+}
+
+// listItems lists gvr in the configured namespace, from the informer cache
+// when it serves the namespace and opts only select by label. It returns
+// the token to continue a limited list from.
+func (c *Client) listItems(ctx context.Context, gvr schema.GroupVersionResource, opts ListOptions) ([]unstructured.Unstructured, string, error) {
+	if informer, ok := c.informers.serves(gvr, c.namespace); ok && opts.cacheable() {
+		items, err := c.informers.list(informer, opts.LabelSelector)
+		if err != nil {
+			return nil, "", err
+		}
+		return pageByName(items, opts)
+	}
+	if strings.HasPrefix(opts.Continue, cacheContinuePrefix) {
+		// The cache stopped serving mid-listing; its token means nothing to
+		// the API server, so restart rather than fail
+		return nil, "", fmt.Errorf("the listing changed source while paging; list again from the first page")
+	}
+	list, err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).List(ctx, opts.toMeta())
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.GetContinue(), nil
+}
+
+// pageByName returns the page of items, sorted by name, that opts ask for:
+// up to opts.Limit items after the name encoded in opts.Continue. Names are
+// stable across calls, unlike offsets, when items are added or removed.
+func pageByName(items []unstructured.Unstructured, opts ListOptions) ([]unstructured.Unstructured, string, error) {
+	if opts.Continue != "" {
+		after, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(opts.Continue, cacheContinuePrefix))
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid continue token")
+		}
+		start := sort.Search(len(items), func(i int) bool { return pageKey(&items[i]) > string(after) })
+		items = items[start:]
+	}
+	if opts.Limit <= 0 || int64(len(items)) <= opts.Limit {
+		return items, "", nil
+	}
+	items = items[:opts.Limit]
+	last := pageKey(&items[len(items)-1])
+	return items, cacheContinuePrefix + base64.RawURLEncoding.EncodeToString([]byte(last)), nil
+}
+
+// pageKey orders cached items: by namespace, then name.
+func pageKey(obj *unstructured.Unstructured) string {
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// getItem gets gvr by name in the configured namespace, from the informer
+// cache when it serves the namespace.
+func (c *Client) getItem(ctx context.Context, gvr schema.GroupVersionResource, name string) (*unstructured.Unstructured, error) {
+	if informer, ok := c.informers.serves(gvr, c.namespace); ok {
+		return c.informers.get(informer, gvr, name)
+	}
+	return c.dynamicClient.Resource(gvr).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// ParseManifest parses a single YAML manifest into an unstructured object.
+func ParseManifest(manifest string) (*unstructured.Unstructured, error) {
 	var obj unstructured.Unstructured
 	if err := yaml.Unmarshal([]byte(manifest), &obj.Object); err != nil {
 		return nil, fmt.Errorf("failed to parse manifest: %w", err)
 	}
+	if obj.Object == nil {
+		return nil, fmt.Errorf("manifest is empty")
+	}
+	return &obj, nil
+}
+
+// SplitManifests splits a multi-document YAML bundle on "---" separators,
+// dropping documents that are empty or contain only comments.
+func SplitManifests(bundle string) []string {
+	var docs []string
+	var current []string
+	flush := func() {
+		doc := strings.Join(current, "\n")
+		current = nil
+		for _, line := range strings.Split(doc, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				docs = append(docs, strings.TrimSpace(doc)+"\n")
+				return
+			}
+		}
+	}
+
+	for _, line := range strings.Split(bundle, "\n") {
+		if strings.TrimRight(line, " \t\r") == "---" {
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return docs
+}
 
-	gvr, err := gvrFromObject(&obj)
+// ListResources lists resources of any kind in the configured namespace as
+// unstructured objects.
+func (c *Client) ListResources(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	list, err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+	}
+	return list.Items, nil
+}
+
+// ListResourcesBySelector lists the resources of any kind in the configured
+// namespace that match a label selector, reading from the API server.
+func (c *Client) ListResourcesBySelector(ctx context.Context, gvr schema.GroupVersionResource, selector string) ([]unstructured.Unstructured, error) {
+	list, err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+	}
+	return list.Items, nil
+}
+
+// GetResource gets a resource of any kind in the configured namespace as an
+// unstructured object.
+func (c *Client) GetResource(ctx context.Context, gvr schema.GroupVersionResource, name string) (*unstructured.Unstructured, error) {
+	return c.GetResourceIn(ctx, gvr, c.namespace, name)
+}
+
+// GetResourceIn gets a resource of any kind in the given namespace, for
+// following namespace-qualified references. An empty namespace means the
+// configured namespace.
+func (c *Client) GetResourceIn(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	if namespace == "" {
+		namespace = c.namespace
+	}
+	obj, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", gvr.Resource, namespace, name, err)
+	}
+	return obj, nil
+}
+
+// Apply applies a manifest (YAML string) to the cluster. The resource is
+// changed only if it satisfies pre; an update then carries the
+// resourceVersion pre was checked against, so a concurrent change fails it
+// with a conflict.
+func (c *Client) Apply(ctx context.Context, manifest string, dryRun bool, pre Preconditions) (*ApplyResult, error) {
+	// Parse the manifest
+	parsed, err := ParseManifest(manifest)
 	if err != nil {
 		return nil, err
 	}
+	obj := *parsed
+
+	mapping, err := c.mapper.RESTMapping(ctx, obj.GroupVersionKind())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve resource for %s: %w", obj.GetAPIVersion()+"/"+obj.GetKind(), err)
+	}
 
-	// Set namespace if not specified
-	if obj.GetNamespace() == "" {
+	// Set namespace if not specified; cluster-scoped kinds have none
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		obj.SetNamespace("")
+	} else if obj.GetNamespace() == "" {
 		obj.SetNamespace(c.namespace)
 	}
+	resource := c.resourceFor(mapping.Resource, obj.GetNamespace())
 
-	opts := metav1.CreateOptions{}
+	opts := metav1.CreateOptions{FieldManager: FieldManager}
 	if dryRun {
 		opts.DryRun = []string{metav1.DryRunAll}
 	}
 
 	// Try to get existing resource
-	existing, err := c.dynamicClient.Resource(gvr).Namespace(obj.GetNamespace()).Get(ctx, obj.GetName(), metav1.GetOptions{})
-	if err == nil {
+	existing, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		// Preconditions cannot be judged against a resource that was not read
+		if !apierrors.IsNotFound(err) && !pre.IsZero() {
+			return nil, fmt.Errorf("failed to get resource to check preconditions: %w", err)
+		}
+		existing = nil
+	}
+	if err := pre.check(existing); err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		// Skip the update when it would not change anything
+		if sameContent(existing, &obj) {
+			return &ApplyResult{
+				Action:    "unchanged",
+				Kind:      obj.GetKind(),
+				Name:      obj.GetName(),
+				Namespace: obj.GetNamespace(),
+				DryRun:    dryRun,
+			}, nil
+		}
+
 		// Resource exists, update it
 		obj.SetResourceVersion(existing.GetResourceVersion())
-		updateOpts := metav1.UpdateOptions{}
+		updateOpts := metav1.UpdateOptions{FieldManager: FieldManager}
 		if dryRun {
 			updateOpts.DryRun = []string{metav1.DryRunAll}
 		}
-		_, err = c.dynamicClient.Resource(gvr).Namespace(obj.GetNamespace()).Update(ctx, &obj, updateOpts)
+		updated, err := resource.Update(ctx, &obj, updateOpts)
+		if apierrors.IsConflict(err) && !pre.IsZero() {
+			return nil, &PreconditionError{Failures: []string{"the resource changed while it was being applied"}}
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to update resource: %w", err)
 		}
+		if !dryRun {
+			c.informers.observe(mapping.Resource, updated)
+		}
 		return &ApplyResult{
 			Action:    "updated",
 			Kind:      obj.GetKind(),
@@ -212,10 +562,13 @@ func (c *Client) Apply(ctx context.Context, manifest string, dryRun bool) (*Appl
 	}
 
 	// Resource doesn't exist, create it
-	_, err = c.dynamicClient.Resource(gvr).Namespace(obj.GetNamespace()).Create(ctx, &obj, opts)
+	created, err := resource.Create(ctx, &obj, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
+	if !dryRun {
+		c.informers.observe(mapping.Resource, created)
+	}
 
 	return &ApplyResult{
 		Action:    "created",
@@ -226,56 +579,211 @@ func (c *Client) Apply(ctx context.Context, manifest string, dryRun bool) (*Appl
 	}, nil
 }
 
+// sameContent reports whether applying desired over existing would be a no-op:
+// every top-level field other than metadata and status matches, as do the
+// labels and annotations.
+func sameContent(existing, desired *unstructured.Unstructured) bool {
+	for key, value := range desired.Object {
+		if key == "metadata" || key == "status" {
+			continue
+		}
+		if !equality.Semantic.DeepEqual(existing.Object[key], value) {
+			return false
+		}
+	}
+	for key := range existing.Object {
+		if _, ok := desired.Object[key]; !ok && key != "metadata" && key != "status" {
+			return false
+		}
+	}
+	return equality.Semantic.DeepEqual(existing.GetLabels(), desired.GetLabels()) &&
+		equality.Semantic.DeepEqual(existing.GetAnnotations(), desired.GetAnnotations())
+}
+
+// resourceFor returns the dynamic resource interface for gvr, scoped to
+// namespace unless it is empty.
+func (c *Client) resourceFor(gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	if namespace == "" {
+		return c.dynamicClient.Resource(gvr)
+	}
+	return c.dynamicClient.Resource(gvr).Namespace(namespace)
+}
+
+// resourceForKind resolves kind (and optional apiVersion) through the
+// RESTMapper and returns its dynamic resource interface in the configured
+// namespace.
+func (c *Client) resourceForKind(ctx context.Context, apiVersion, kind string) (dynamic.ResourceInterface, error) {
+	mapping, err := c.mapper.KindFor(ctx, apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		return c.resourceFor(mapping.Resource, ""), nil
+	}
+	return c.resourceFor(mapping.Resource, c.namespace), nil
+}
+
+// Cascade policies for DeleteCascade, as in kubectl delete --cascade.
+const (
+	CascadeBackground = "background"
+	CascadeForeground = "foreground"
+	CascadeOrphan     = "orphan"
+)
+
+// cascadePolicies maps cascade policies to deletion propagation policies.
+var cascadePolicies = map[string]metav1.DeletionPropagation{
+	CascadeBackground: metav1.DeletePropagationBackground,
+	CascadeForeground: metav1.DeletePropagationForeground,
+	CascadeOrphan:     metav1.DeletePropagationOrphan,
+}
+
 // Delete deletes a resource from the cluster.
 func (c *Client) Delete(ctx context.Context, kind, name string, dryRun bool) error {
-	gvr, err := gvrFromKind(kind)
+	return c.DeleteCascade(ctx, kind, name, dryRun, "")
+}
+
+// DeleteCascade deletes a resource with a cascade policy deciding what
+// happens to the objects it owns: "background" deletes them after the
+// resource, "foreground" before it, and "orphan" keeps them. An empty
+// policy leaves the choice to the API server.
+func (c *Client) DeleteCascade(ctx context.Context, kind, name string, dryRun bool, cascade string) error {
+	mapping, err := c.mapper.KindFor(ctx, "", kind)
 	if err != nil {
 		return err
 	}
+	namespace := c.namespace
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		namespace = ""
+	}
 
 	opts := metav1.DeleteOptions{}
 	if dryRun {
 		opts.DryRun = []string{metav1.DryRunAll}
 	}
+	if cascade != "" {
+		policy, ok := cascadePolicies[cascade]
+		if !ok {
+			return fmt.Errorf("unknown cascade policy %q", cascade)
+		}
+		opts.PropagationPolicy = &policy
+	}
+
+	if err := c.resourceFor(mapping.Resource, namespace).Delete(ctx, name, opts); err != nil {
+		return err
+	}
+	if !dryRun {
+		c.informers.forget(mapping.Resource, namespace, name)
+	}
+	return nil
+}
 
-	return c.dynamicClient.Resource(gvr).Namespace(c.namespace).Delete(ctx, name, opts)
+// DeleteUID deletes a resource in the configured namespace only while it is
+// still the object with the given UID, so that a resource recreated under
+// the same name is kept. A UID mismatch fails with a conflict error.
+func (c *Client) DeleteUID(ctx context.Context, gvr schema.GroupVersionResource, name string, uid k8stypes.UID, cascade string) error {
+	opts := metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}}
+	if cascade != "" {
+		policy, ok := cascadePolicies[cascade]
+		if !ok {
+			return fmt.Errorf("unknown cascade policy %q", cascade)
+		}
+		opts.PropagationPolicy = &policy
+	}
+
+	if err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).Delete(ctx, name, opts); err != nil {
+		return err
+	}
+	c.informers.forget(gvr, c.namespace, name)
+	return nil
 }
 
 // GetCurrentState gets the current state of a resource for diffing.
-func (c *Client) GetCurrentState(ctx context.Context, kind, name string) (string, error) {
-	gvr, err := gvrFromKind(kind)
+// apiVersion may be empty, in which case the kind is resolved by name.
+func (c *Client) GetCurrentState(ctx context.Context, apiVersion, kind, name string) (string, error) {
+	state, _, err := c.GetCurrentStateVersion(ctx, apiVersion, kind, name)
+	return state, err
+}
+
+// GetCurrentStateVersion is GetCurrentState that also returns the
+// resourceVersion the state was read at, for use as an apply precondition.
+// Secret values are redacted.
+func (c *Client) GetCurrentStateVersion(ctx context.Context, apiVersion, kind, name string) (string, string, error) {
+	resource, err := c.resourceForKind(ctx, apiVersion, kind)
+	if err != nil {
+		return "", "", err
+	}
+
+	obj, err := resource.Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
+	resourceVersion := obj.GetResourceVersion()
+	CleanForDiff(obj)
+	RedactSecretValues(obj)
 
-	obj, err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	yamlBytes, err := yaml.Marshal(obj.Object)
 	if err != nil {
-		return "", err
+		return "", "", fmt.Errorf("failed to marshal to yaml: %w", err)
 	}
 
-	// Remove server-managed fields for cleaner diff
+	return string(yamlBytes), resourceVersion, nil
+}
+
+// CleanForDiff removes the status and the metadata the server manages,
+// which a manifest never sets, for a cleaner diff.
+func CleanForDiff(obj *unstructured.Unstructured) {
 	delete(obj.Object, "status")
 	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
 	unstructured.RemoveNestedField(obj.Object, "metadata", "generation")
 	unstructured.RemoveNestedField(obj.Object, "metadata", "resourceVersion")
 	unstructured.RemoveNestedField(obj.Object, "metadata", "uid")
 	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+}
 
-	yamlBytes, err := yaml.Marshal(obj.Object)
+// PreviewUpdate returns an existing resource as it is and as it would be
+// once Apply replaced it with obj: a server-side dry run of the update, so
+// defaults, admission webhooks and the removal of fields obj leaves out
+// show as the API server would apply them. obj is not modified. Secret
+// values are redacted in both results.
+func (c *Client) PreviewUpdate(ctx context.Context, obj *unstructured.Unstructured) (live, updated *unstructured.Unstructured, err error) {
+	mapping, err := c.mapper.RESTMapping(ctx, obj.GroupVersionKind())
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal to yaml: %w", err)
+		return nil, nil, fmt.Errorf("failed to resolve resource for %s: %w", obj.GetAPIVersion()+"/"+obj.GetKind(), err)
+	}
+
+	desired := obj.DeepCopy()
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		desired.SetNamespace("")
+	} else if desired.GetNamespace() == "" {
+		desired.SetNamespace(c.namespace)
 	}
+	resource := c.resourceFor(mapping.Resource, desired.GetNamespace())
 
-	return string(yamlBytes), nil
+	live, err = resource.Get(ctx, desired.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	desired.SetResourceVersion(live.GetResourceVersion())
+	updated, err = resource.Update(ctx, desired, metav1.UpdateOptions{
+		FieldManager: FieldManager,
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("server-side dry run failed: %w", err)
+	}
+	RedactSecretValues(live)
+	RedactSecretValues(updated)
+	return live, updated, nil
 }
 
 // ApplyResult contains the result of an apply operation.
 type ApplyResult struct {
-	Action    string `json:"action"`    // "created" or "updated"
+	Action    string `json:"action"` // "created", "updated", "unchanged", "failed", or "skipped"
 	Kind      string `json:"kind"`
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
 	DryRun    bool   `json:"dryRun"`
+	Error     string `json:"error,omitempty"`
 }
 
 // Helper functions
@@ -328,21 +836,8 @@ func unstructuredToRemoteMCPServer(obj *unstructured.Unstructured) (*types.Remot
 	return &server, nil
 }
 
-func gvrFromObject(obj *unstructured.Unstructured) (schema.GroupVersionResource, error) {
-	return gvrFromKind(obj.GetKind())
-}
-
-func gvrFromKind(kind string) (schema.GroupVersionResource, error) {
-	switch kind {
-	case "Agent":
-		return AgentGVR, nil
-	case "ModelConfig":
-		return ModelConfigGVR, nil
-	case "MCPServer":
-		return MCPServerGVR, nil
-	case "RemoteMCPServer":
-		return RemoteMCPServerGVR, nil
-	default:
-		return schema.GroupVersionResource{}, fmt.Errorf("unknown kind: %s", kind)
-	}
+// NewInformerFactory returns a shared informer factory for the configured
+// namespace, for components that maintain state from watch events.
+func (c *Client) NewInformerFactory(resync time.Duration) dynamicinformer.DynamicSharedInformerFactory {
+	return dynamicinformer.NewFilteredDynamicSharedInformerFactory(c.dynamicClient, resync, c.namespace, nil)
 }
@@ -0,0 +1,111 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// OpenShiftIngressGVR is the GroupVersionResource of OpenShift's cluster
+// ingress configuration, which holds the default domain of routes.
+var OpenShiftIngressGVR = schema.GroupVersionResource{
+	Group:    "config.openshift.io",
+	Version:  "v1",
+	Resource: "ingresses",
+}
+
+// maxNodeInfos bounds how many nodes NodeInfos reads; the labels that
+// identify a cluster are the same on every node.
+const maxNodeInfos = 20
+
+// NodeInfo is what a node tells about the cluster it belongs to.
+type NodeInfo struct {
+	Name string
+	// ProviderID identifies the node's machine at its cloud provider, e.g.
+	// aws:///us-east-1a/i-0abc.
+	ProviderID string
+	Labels     map[string]string
+}
+
+// ContextCluster returns the cluster of the kubeconfig's current context,
+// or "" when running in-cluster.
+func (c *Client) ContextCluster() string {
+	return c.contextCluster
+}
+
+// NodeInfos returns the provider IDs and labels of some of the cluster's
+// nodes. known is false when the identity is not allowed to list nodes.
+func (c *Client) NodeInfos(ctx context.Context) (nodes []NodeInfo, known bool, err error) {
+	list, err := c.dynamicClient.Resource(NodeGVR).List(ctx, metav1.ListOptions{Limit: maxNodeInfos})
+	if apierrors.IsForbidden(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	for _, item := range list.Items {
+		providerID, _, _ := unstructured.NestedString(item.Object, "spec", "providerID")
+		nodes = append(nodes, NodeInfo{Name: item.GetName(), ProviderID: providerID, Labels: item.GetLabels()})
+	}
+	return nodes, true, nil
+}
+
+// ClusterIngressDomain returns the default ingress domain OpenShift
+// configures for the cluster, or "" on other distributions. known is
+// false when the identity may not read the configuration.
+func (c *Client) ClusterIngressDomain(ctx context.Context) (domain string, known bool, err error) {
+	obj, err := c.dynamicClient.Resource(OpenShiftIngressGVR).Get(ctx, "cluster", metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err) || meta.IsNoMatchError(err):
+		return "", true, nil
+	case apierrors.IsForbidden(err):
+		return "", false, nil
+	case err != nil:
+		return "", false, fmt.Errorf("failed to get the cluster ingress configuration: %w", err)
+	}
+	domain, _, _ = unstructured.NestedString(obj.Object, "spec", "domain")
+	return domain, true, nil
+}
+
+// IngressHosts returns the hosts of the Ingress rules in the configured
+// namespace, sorted. known is false when the identity may not list
+// Ingresses.
+func (c *Client) IngressHosts(ctx context.Context) (hosts []string, known bool, err error) {
+	list, err := c.dynamicClient.Resource(IngressGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsForbidden(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	for _, item := range list.Items {
+		rules, _, _ := unstructured.NestedSlice(item.Object, "spec", "rules")
+		for _, rule := range rules {
+			if r, ok := rule.(map[string]interface{}); ok {
+				if host, _ := r["host"].(string); host != "" {
+					hosts = append(hosts, host)
+				}
+			}
+		}
+	}
+	sort.Strings(hosts)
+	return hosts, true, nil
+}
+
+// ServesKind reports whether the cluster serves gk at any version, for
+// instance whether the CRD of an add-on is installed.
+func (c *Client) ServesKind(ctx context.Context, gk schema.GroupKind) (bool, error) {
+	served, err := c.mapper.Serves(ctx, gk)
+	if err != nil {
+		return false, fmt.Errorf("failed to discover %s: %w", gk.String(), err)
+	}
+	return served, nil
+}
@@ -0,0 +1,117 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/util/retry"
+)
+
+// ConfigMapGVR is the GroupVersionResource for core ConfigMaps.
+var ConfigMapGVR = schema.GroupVersionResource{
+	Group:    "",
+	Version:  "v1",
+	Resource: "configmaps",
+}
+
+// GetConfigMapData returns the data of a ConfigMap in the configured namespace.
+// A missing ConfigMap is reported as empty data with found=false.
+func (c *Client) GetConfigMapData(ctx context.Context, name string) (map[string]string, bool, error) {
+	obj, err := c.dynamicClient.Resource(ConfigMapGVR).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return map[string]string{}, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get configmap %s: %w", name, err)
+	}
+
+	data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+	if data == nil {
+		data = map[string]string{}
+	}
+	return data, true, nil
+}
+
+// UpdateConfigMapData applies change to the data of a ConfigMap in the
+// configured namespace, creating the ConfigMap with labels if it is missing.
+// The update carries the resourceVersion that was read, so a concurrent
+// writer makes it fail with a conflict, and a concurrent creation makes the
+// create fail; either way the cycle is retried on fresh data. change may
+// therefore run more than once and should only modify data; an error it
+// returns aborts the update.
+func (c *Client) UpdateConfigMapData(ctx context.Context, name string, labels map[string]string, change func(data map[string]string) error) error {
+	client := c.dynamicClient.Resource(ConfigMapGVR).Namespace(c.namespace)
+
+	retriable := func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, retriable, func() error {
+		existing, err := client.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			data := map[string]string{}
+			if err := change(data); err != nil {
+				return err
+			}
+
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+			}}
+			obj.SetName(name)
+			obj.SetNamespace(c.namespace)
+			obj.SetLabels(labels)
+			if err := unstructured.SetNestedStringMap(obj.Object, data, "data"); err != nil {
+				return fmt.Errorf("failed to set configmap data: %w", err)
+			}
+			if _, err := client.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create configmap %s: %w", name, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get configmap %s: %w", name, err)
+		}
+
+		data, _, _ := unstructured.NestedStringMap(existing.Object, "data")
+		if data == nil {
+			data = map[string]string{}
+		}
+		if err := change(data); err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedStringMap(existing.Object, data, "data"); err != nil {
+			return fmt.Errorf("failed to set configmap data: %w", err)
+		}
+		if _, err := client.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update configmap %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// CountResources returns the number of resources of the given kind in the
+// configured namespace.
+func (c *Client) CountResources(ctx context.Context, gvr schema.GroupVersionResource) (int, error) {
+	list, err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+	}
+	return len(list.Items), nil
+}
+
+// WatchConfigMap watches a single ConfigMap in the configured namespace.
+func (c *Client) WatchConfigMap(ctx context.Context, name string) (watch.Interface, error) {
+	w, err := c.dynamicClient.Resource(ConfigMapGVR).Namespace(c.namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch configmap %s: %w", name, err)
+	}
+	return w, nil
+}
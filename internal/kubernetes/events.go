@@ -0,0 +1,106 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// EventGVR is the GroupVersionResource of core Kubernetes Events.
+var EventGVR = schema.GroupVersionResource{
+	Group:    "",
+	Version:  "v1",
+	Resource: "events",
+}
+
+// Event is a Kubernetes Event, reduced to what tools report.
+type Event struct {
+	// Kind and Name identify the involved object.
+	Kind      string
+	Name      string
+	Type      string
+	Reason    string
+	Message   string
+	Count     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// ListEvents lists the Events in the configured namespace. known is false
+// when the identity may not list events.
+func (c *Client) ListEvents(ctx context.Context) (events []Event, known bool, err error) {
+	list, err := c.dynamicClient.Resource(EventGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsForbidden(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	for _, item := range list.Items {
+		events = append(events, eventFromUnstructured(item))
+	}
+	return events, true, nil
+}
+
+// eventFromUnstructured reads an Event recorded by either the core/v1 or the
+// events.k8s.io API, which fill different timestamp fields.
+func eventFromUnstructured(obj unstructured.Unstructured) Event {
+	kind, _, _ := unstructured.NestedString(obj.Object, "involvedObject", "kind")
+	name, _, _ := unstructured.NestedString(obj.Object, "involvedObject", "name")
+	eventType, _, _ := unstructured.NestedString(obj.Object, "type")
+	reason, _, _ := unstructured.NestedString(obj.Object, "reason")
+	message, _, _ := unstructured.NestedString(obj.Object, "message")
+
+	count, _, _ := unstructured.NestedInt64(obj.Object, "count")
+	if seriesCount, ok, _ := unstructured.NestedInt64(obj.Object, "series", "count"); ok && seriesCount > count {
+		count = seriesCount
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	eventTime := timestamp(obj, "eventTime")
+	firstSeen := firstTime(timestamp(obj, "firstTimestamp"), eventTime, obj.GetCreationTimestamp().Time)
+	lastSeen := firstTime(timestamp(obj, "lastTimestamp"), timestamp(obj, "series", "lastObservedTime"), eventTime, firstSeen)
+
+	return Event{
+		Kind:      kind,
+		Name:      name,
+		Type:      eventType,
+		Reason:    reason,
+		Message:   message,
+		Count:     int(count),
+		FirstSeen: firstSeen,
+		LastSeen:  lastSeen,
+	}
+}
+
+// timestamp parses an RFC 3339 timestamp field, returning the zero time when
+// it is absent.
+func timestamp(obj unstructured.Unstructured, fields ...string) time.Time {
+	value, _, _ := unstructured.NestedString(obj.Object, fields...)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// firstTime returns the first non-zero time.
+func firstTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,166 @@
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// Faults are the probabilities, between 0 and 1, of failing a request to
+// the API server with a simulated error. They exist to check that tools and
+// the workflows built on them degrade gracefully; never enable them against
+// a cluster that matters. The zero value injects nothing.
+type Faults struct {
+	// NotFound fails reads, updates and deletes with 404 NotFound.
+	NotFound float64
+	// Conflict fails creates and updates with 409 Conflict, as if the
+	// resource changed since it was read.
+	Conflict float64
+	// Timeout fails any request with 504 Timeout.
+	Timeout float64
+	// Seed makes the sequence of faults reproducible; 0 picks a random one.
+	Seed int64
+}
+
+// IsZero reports whether no faults are injected.
+func (f Faults) IsZero() bool {
+	return f.NotFound <= 0 && f.Conflict <= 0 && f.Timeout <= 0
+}
+
+func (f Faults) String() string {
+	return fmt.Sprintf("NotFound=%g Conflict=%g Timeout=%g", f.NotFound, f.Conflict, f.Timeout)
+}
+
+// WithFaults returns a client whose requests fail at random as configured
+// by faults. Only requests for resources are affected: discovery and
+// watches (which informers retry on their own) pass through.
+func (c *Client) WithFaults(faults Faults) (*Client, error) {
+	if faults.IsZero() {
+		return c, nil
+	}
+	seed := faults.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	injector := &faultInjector{faults: faults, rand: rand.New(rand.NewSource(seed))}
+
+	// Impersonating clients copy the config, and with it the injector
+	config := rest.CopyConfig(c.config)
+	wrap := config.WrapTransport
+	config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wrap != nil {
+			rt = wrap(rt)
+		}
+		return &faultTransport{next: rt, injector: injector}
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client with fault injection: %w", err)
+	}
+
+	faulty := *c
+	faulty.dynamicClient = dynamicClient
+	faulty.config = config
+	return &faulty, nil
+}
+
+// faultInjector draws faults; it is shared by every transport built from
+// the same config.
+type faultInjector struct {
+	faults Faults
+	mu     sync.Mutex
+	rand   *rand.Rand
+}
+
+// draw returns the fault to inject into a request, or "" for none.
+func (f *faultInjector) draw(method string) metav1.StatusReason {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	write := method == http.MethodPut || method == http.MethodPatch || method == http.MethodPost
+	switch {
+	case f.rand.Float64() < f.faults.Timeout:
+		return metav1.StatusReasonTimeout
+	case method != http.MethodPost && f.rand.Float64() < f.faults.NotFound:
+		return metav1.StatusReasonNotFound
+	case write && f.rand.Float64() < f.faults.Conflict:
+		return metav1.StatusReasonConflict
+	}
+	return ""
+}
+
+type faultTransport struct {
+	next     http.RoundTripper
+	injector *faultInjector
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isResourceRequest(req) {
+		return t.next.RoundTrip(req)
+	}
+	reason := t.injector.draw(req.Method)
+	if reason == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	status := metav1.Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusFailure,
+		Reason:   reason,
+	}
+	switch reason {
+	case metav1.StatusReasonNotFound:
+		status.Code = http.StatusNotFound
+		status.Message = fmt.Sprintf("injected fault: %s not found", req.URL.Path)
+	case metav1.StatusReasonConflict:
+		status.Code = http.StatusConflict
+		status.Message = fmt.Sprintf("injected fault: operation on %s cannot be fulfilled: the object has been modified; please apply your changes to the latest version and try again", req.URL.Path)
+	case metav1.StatusReasonTimeout:
+		status.Code = http.StatusGatewayTimeout
+		status.Message = fmt.Sprintf("injected fault: the server was unable to return a response in the time allotted for %s %s", req.Method, req.URL.Path)
+	}
+
+	body, _ := json.Marshal(status)
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status.Code, http.StatusText(int(status.Code))),
+		StatusCode:    int(status.Code),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// isResourceRequest reports whether a request addresses resources, e.g.
+// /api/v1/namespaces/x/secrets or /apis/kagent.dev/v1alpha2/agents, rather
+// than discovery, and is not a watch.
+func isResourceRequest(req *http.Request) bool {
+	if req.URL.Query().Get("watch") == "true" {
+		return false
+	}
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(segments) > 0 && segments[0] == "api":
+		return len(segments) >= 3
+	case len(segments) > 0 && segments[0] == "apis":
+		return len(segments) >= 4
+	}
+	return false
+}
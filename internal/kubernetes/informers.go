@@ -0,0 +1,138 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// informerResync is how often the cache's informers replay their full state.
+const informerResync = 10 * time.Minute
+
+// CachedGVRs are the kinds the informer cache serves reads for.
+var CachedGVRs = []schema.GroupVersionResource{
+	AgentGVR,
+	ModelConfigGVR,
+	MCPServerGVR,
+	RemoteMCPServerGVR,
+}
+
+// InformerCache serves reads of kagent resources in one namespace from
+// shared informers, which keep it up to date from watch events instead of
+// listing on every call. It is safe for concurrent use.
+type InformerCache struct {
+	namespace string
+	factory   dynamicinformer.DynamicSharedInformerFactory
+	informers map[schema.GroupVersionResource]toolscache.SharedIndexInformer
+	synced    atomic.Bool
+}
+
+// NewInformerCache creates a cache of gvrs in the client's namespace. It
+// serves nothing until Run has synced it.
+func NewInformerCache(client *Client, gvrs ...schema.GroupVersionResource) *InformerCache {
+	c := &InformerCache{
+		namespace: client.namespace,
+		factory:   client.NewInformerFactory(informerResync),
+		informers: map[schema.GroupVersionResource]toolscache.SharedIndexInformer{},
+	}
+	for _, gvr := range gvrs {
+		c.informers[gvr] = c.factory.ForResource(gvr).Informer()
+	}
+	return c
+}
+
+// Run starts the informers and keeps the cache up to date until ctx is
+// cancelled.
+func (c *InformerCache) Run(ctx context.Context) error {
+	var syncs []toolscache.InformerSynced
+	for _, informer := range c.informers {
+		syncs = append(syncs, informer.HasSynced)
+	}
+
+	c.factory.Start(ctx.Done())
+	if !toolscache.WaitForCacheSync(ctx.Done(), syncs...) {
+		return fmt.Errorf("informer cache did not sync: %w", ctx.Err())
+	}
+	c.synced.Store(true)
+
+	<-ctx.Done()
+	c.synced.Store(false)
+	c.factory.Shutdown()
+	return nil
+}
+
+// Synced reports whether the initial list of every cached kind is loaded.
+func (c *InformerCache) Synced() bool {
+	return c.synced.Load()
+}
+
+// serves returns the informer for gvr when the cache can answer reads of it
+// in namespace.
+func (c *InformerCache) serves(gvr schema.GroupVersionResource, namespace string) (toolscache.SharedIndexInformer, bool) {
+	if c == nil || namespace != c.namespace || !c.Synced() {
+		return nil, false
+	}
+	informer, ok := c.informers[gvr]
+	return informer, ok
+}
+
+// list returns the objects in informer matching selector.
+func (c *InformerCache) list(informer toolscache.SharedIndexInformer, selector string) ([]unstructured.Unstructured, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+	}
+
+	var items []unstructured.Unstructured
+	for _, obj := range informer.GetStore().List() {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok || !parsed.Matches(labels.Set(u.GetLabels())) {
+			continue
+		}
+		items = append(items, *u.DeepCopy())
+	}
+	// Keep the order of the API server, which lists by name
+	sort.Slice(items, func(i, j int) bool { return items[i].GetName() < items[j].GetName() })
+	return items, nil
+}
+
+// get returns the object in informer named name, or a NotFound error for gvr.
+func (c *InformerCache) get(informer toolscache.SharedIndexInformer, gvr schema.GroupVersionResource, name string) (*unstructured.Unstructured, error) {
+	obj, exists, err := informer.GetStore().GetByKey(c.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !exists || !ok {
+		return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+	}
+	return u.DeepCopy(), nil
+}
+
+// observe records a write made through the client, so that reads following
+// it see the change before its watch event arrives.
+func (c *InformerCache) observe(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) {
+	if informer, ok := c.serves(gvr, obj.GetNamespace()); ok {
+		_ = informer.GetStore().Update(obj)
+	}
+}
+
+// forget drops a deleted object, like observe.
+func (c *InformerCache) forget(gvr schema.GroupVersionResource, namespace, name string) {
+	informer, ok := c.serves(gvr, namespace)
+	if !ok {
+		return
+	}
+	if obj, exists, _ := informer.GetStore().GetByKey(namespace + "/" + name); exists {
+		_ = informer.GetStore().Delete(obj)
+	}
+}
@@ -0,0 +1,61 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// LeaseGVR is the GroupVersionResource of coordination Leases, which
+// controllers hold to elect a leader.
+var LeaseGVR = schema.GroupVersionResource{
+	Group:    "coordination.k8s.io",
+	Version:  "v1",
+	Resource: "leases",
+}
+
+// Lease is a leader election Lease.
+type Lease struct {
+	Name string `json:"name"`
+	// Holder is the identity of the leader, which controller-runtime
+	// starts with the leader's pod name.
+	Holder      string        `json:"holder,omitempty"`
+	RenewTime   time.Time     `json:"renewTime,omitempty"`
+	Duration    time.Duration `json:"-"`
+	Transitions int64         `json:"transitions"`
+}
+
+// Expired reports whether the holder has not renewed the Lease within its
+// duration, so another candidate may take it over.
+func (l Lease) Expired(now time.Time) bool {
+	return l.Holder == "" || l.RenewTime.IsZero() || now.Sub(l.RenewTime) > l.Duration
+}
+
+// ListLeases lists the Leases in the configured namespace, ordered by
+// name. known is false when the identity may not list Leases.
+func (c *Client) ListLeases(ctx context.Context) (leases []Lease, known bool, err error) {
+	list, err := c.dynamicClient.Resource(LeaseGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsForbidden(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list leases: %w", err)
+	}
+
+	for _, item := range list.Items {
+		lease := Lease{Name: item.GetName(), RenewTime: timestamp(item, "spec", "renewTime")}
+		lease.Holder, _, _ = unstructured.NestedString(item.Object, "spec", "holderIdentity")
+		seconds, _, _ := unstructured.NestedInt64(item.Object, "spec", "leaseDurationSeconds")
+		lease.Duration = time.Duration(seconds) * time.Second
+		lease.Transitions, _, _ = unstructured.NestedInt64(item.Object, "spec", "leaseTransitions")
+		leases = append(leases, lease)
+	}
+	sort.Slice(leases, func(i, j int) bool { return leases[i].Name < leases[j].Name })
+	return leases, true, nil
+}
@@ -0,0 +1,27 @@
+package kubernetes
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FieldManager is the field manager the server writes resources as, so
+// managedFields tell its changes apart from those of kubectl, controllers
+// and GitOps tools.
+const FieldManager = "kmeta-agent"
+
+// GetManagedFields returns the managedFields of a resource. apiVersion may
+// be empty, in which case the kind is resolved by name.
+func (c *Client) GetManagedFields(ctx context.Context, apiVersion, kind, name string) ([]metav1.ManagedFieldsEntry, error) {
+	resource, err := c.resourceForKind(ctx, apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.GetManagedFields(), nil
+}
@@ -0,0 +1,26 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceExists reports whether the named namespace exists. known is false
+// when the identity is not allowed to read namespaces, in which case exists
+// should be ignored.
+func (c *Client) NamespaceExists(ctx context.Context, name string) (exists bool, known bool, err error) {
+	_, err = c.dynamicClient.Resource(NamespaceGVR).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return true, true, nil
+	case apierrors.IsNotFound(err):
+		return false, true, nil
+	case apierrors.IsForbidden(err):
+		return false, false, nil
+	default:
+		return false, false, fmt.Errorf("failed to get namespace %s: %w", name, err)
+	}
+}
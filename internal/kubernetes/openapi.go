@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kagent-dev/meta-kagent/internal/crdschema"
+)
+
+// openAPICache holds the schemas of group versions by the URL the API
+// server publishes their OpenAPI v3 document at, which carries a hash of
+// the document: a CRD upgrade changes the URL.
+type openAPICache struct {
+	mu      sync.Mutex
+	entries map[schema.GroupVersion]openAPIEntry
+}
+
+type openAPIEntry struct {
+	url     string
+	schemas crdschema.Schemas
+}
+
+// Schema returns the OpenAPI v3 schema the cluster publishes for a kind,
+// or nil when it publishes none (OpenAPI v3 is served from Kubernetes
+// 1.27, or earlier behind a feature gate).
+func (c *Client) Schema(ctx context.Context, gvk schema.GroupVersionKind) (*crdschema.Schema, error) {
+	schemas, err := c.mapper.openAPISchemas(ctx, gvk.GroupVersion())
+	if err != nil {
+		return nil, err
+	}
+	return schemas[gvk], nil
+}
+
+// openAPISchemas returns the (cached) schemas of the kinds of a group
+// version.
+func (m *RESTMapper) openAPISchemas(ctx context.Context, gv schema.GroupVersion) (crdschema.Schemas, error) {
+	// The index is small and names the current document of every group
+	var index struct {
+		Paths map[string]struct {
+			ServerRelativeURL string `json:"serverRelativeURL"`
+		} `json:"paths"`
+	}
+	found, err := m.get(ctx, "/openapi/v3", &index)
+	if err != nil || !found {
+		return nil, err
+	}
+	p := path.Join("apis", gv.Group, gv.Version)
+	if gv.Group == "" {
+		p = path.Join("api", gv.Version)
+	}
+	ref, ok := index.Paths[p]
+	if !ok || ref.ServerRelativeURL == "" {
+		return nil, nil
+	}
+
+	m.openapi.mu.Lock()
+	cached, ok := m.openapi.entries[gv]
+	m.openapi.mu.Unlock()
+	if ok && cached.url == ref.ServerRelativeURL {
+		return cached.schemas, nil
+	}
+
+	var doc json.RawMessage
+	found, err = m.get(ctx, ref.ServerRelativeURL, &doc)
+	if err != nil || !found {
+		return nil, err
+	}
+	schemas, err := crdschema.ParseOpenAPI(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	m.openapi.mu.Lock()
+	if m.openapi.entries == nil {
+		m.openapi.entries = map[schema.GroupVersion]openAPIEntry{}
+	}
+	m.openapi.entries[gv] = openAPIEntry{url: ref.ServerRelativeURL, schemas: schemas}
+	m.openapi.mu.Unlock()
+	return schemas, nil
+}
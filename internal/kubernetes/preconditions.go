@@ -0,0 +1,171 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ResourceAbsent is the expected resourceVersion of a resource that must
+// not exist yet.
+const ResourceAbsent = "absent"
+
+// Preconditions are assertions about the current state of a resource that
+// must hold for Apply to change it. The zero value asserts nothing.
+type Preconditions struct {
+	// ResourceVersion is the resourceVersion the resource must have, or
+	// ResourceAbsent.
+	ResourceVersion string
+	// Fields maps field paths (e.g., "spec.declarative.modelConfig" or
+	// "spec.declarative.tools[0].mcpServer.name") to the value they must
+	// have. A nil value asserts the field is not set.
+	Fields map[string]interface{}
+}
+
+// IsZero reports whether the preconditions assert nothing.
+func (p Preconditions) IsZero() bool {
+	return p.ResourceVersion == "" && len(p.Fields) == 0
+}
+
+// PreconditionError reports the preconditions that did not hold.
+type PreconditionError struct {
+	Failures []string
+}
+
+func (e *PreconditionError) Error() string {
+	return "precondition failed: " + strings.Join(e.Failures, "; ")
+}
+
+// check returns a PreconditionError when existing, nil if the resource does
+// not exist, does not satisfy the preconditions.
+func (p Preconditions) check(existing *unstructured.Unstructured) error {
+	var failures []string
+
+	switch {
+	case existing == nil && p.ResourceVersion != "" && p.ResourceVersion != ResourceAbsent:
+		failures = append(failures, fmt.Sprintf("expected resourceVersion %s, but the resource does not exist", p.ResourceVersion))
+	case existing != nil && p.ResourceVersion == ResourceAbsent:
+		failures = append(failures, fmt.Sprintf("expected the resource not to exist, but it does (resourceVersion %s)", existing.GetResourceVersion()))
+	case existing != nil && p.ResourceVersion != "" && existing.GetResourceVersion() != p.ResourceVersion:
+		failures = append(failures, fmt.Sprintf("resourceVersion is %s, expected %s", existing.GetResourceVersion(), p.ResourceVersion))
+	}
+
+	paths := make([]string, 0, len(p.Fields))
+	for path := range p.Fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		want := p.Fields[path]
+		var got interface{}
+		found := false
+		if existing != nil {
+			var err error
+			got, found, err = FieldValue(existing.Object, path)
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+		}
+		switch {
+		case want == nil && !found:
+		case want == nil:
+			failures = append(failures, fmt.Sprintf("%s is %s, expected it unset", path, compact(got)))
+		case !found:
+			failures = append(failures, fmt.Sprintf("%s is unset, expected %s", path, compact(want)))
+		case !sameJSON(got, want):
+			failures = append(failures, fmt.Sprintf("%s is %s, expected %s", path, compact(got), compact(want)))
+		}
+	}
+
+	if len(failures) > 0 {
+		return &PreconditionError{Failures: failures}
+	}
+	return nil
+}
+
+// FieldValue returns the value at a dotted field path with optional list
+// indices, e.g. "spec.declarative.tools[0].mcpServer.name".
+func FieldValue(obj map[string]interface{}, path string) (interface{}, bool, error) {
+	var current interface{} = obj
+	for _, segment := range strings.Split(path, ".") {
+		name, indices, err := splitIndices(segment)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid field path '%s': %v", path, err)
+		}
+		if name != "" {
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+			if current, ok = m[name]; !ok {
+				return nil, false, nil
+			}
+		}
+		for _, i := range indices {
+			list, ok := current.([]interface{})
+			if !ok || i >= len(list) {
+				return nil, false, nil
+			}
+			current = list[i]
+		}
+	}
+	return current, true, nil
+}
+
+// splitIndices splits "tools[0][1]" into "tools" and [0, 1].
+func splitIndices(segment string) (string, []int, error) {
+	open := strings.IndexByte(segment, '[')
+	if open < 0 {
+		if segment == "" {
+			return "", nil, fmt.Errorf("empty segment")
+		}
+		return segment, nil, nil
+	}
+
+	name, rest := segment[:open], segment[open:]
+	var indices []int
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return "", nil, fmt.Errorf("malformed index in '%s'", segment)
+		}
+		i, err := strconv.Atoi(rest[1:end])
+		if err != nil || i < 0 {
+			return "", nil, fmt.Errorf("malformed index in '%s'", segment)
+		}
+		indices = append(indices, i)
+		rest = rest[end+1:]
+	}
+	return name, indices, nil
+}
+
+// sameJSON compares two values by their JSON encoding, so an int64 read from
+// the cluster equals the float64 decoded from a JSON argument.
+func sameJSON(a, b interface{}) bool {
+	var na, nb interface{}
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	if json.Unmarshal(da, &na) != nil || json.Unmarshal(db, &nb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(na, nb)
+}
+
+// compact renders a value as one-line JSON.
+func compact(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,239 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupVersionResource definitions for core resources used by preflight checks.
+var (
+	NamespaceGVR = schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "namespaces",
+	}
+
+	DeploymentGVR = schema.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "deployments",
+	}
+
+	SelfSubjectAccessReviewGVR = schema.GroupVersionResource{
+		Group:    "authorization.k8s.io",
+		Version:  "v1",
+		Resource: "selfsubjectaccessreviews",
+	}
+)
+
+// Preflight check statuses.
+const (
+	PreflightPass = "pass"
+	PreflightWarn = "warn"
+	PreflightFail = "fail"
+)
+
+// AccessRequirement declares the verbs a tool set needs on a resource.
+type AccessRequirement struct {
+	GVR   schema.GroupVersionResource
+	Verbs []string
+}
+
+// PreflightOptions configures a preflight run.
+type PreflightOptions struct {
+	// ControllerName is the name of the kagent controller Deployment.
+	ControllerName string
+	// ControllerNamespace is the namespace of the controller (defaults to the client namespace).
+	ControllerNamespace string
+	// Requirements are the permissions needed by the registered tools.
+	Requirements []AccessRequirement
+}
+
+// PreflightCheck is the outcome of a single preflight check.
+type PreflightCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // "pass", "warn", or "fail"
+	Message string `json:"message"`
+}
+
+// PreflightReport aggregates the results of all preflight checks.
+type PreflightReport struct {
+	Namespace string           `json:"namespace"`
+	Checks    []PreflightCheck `json:"checks"`
+}
+
+// Passed returns true if no check failed.
+func (r *PreflightReport) Passed() bool {
+	for _, c := range r.Checks {
+		if c.Status == PreflightFail {
+			return false
+		}
+	}
+	return true
+}
+
+// Failures returns the checks that failed.
+func (r *PreflightReport) Failures() []PreflightCheck {
+	var failures []PreflightCheck
+	for _, c := range r.Checks {
+		if c.Status == PreflightFail {
+			failures = append(failures, c)
+		}
+	}
+	return failures
+}
+
+func (r *PreflightReport) add(name, status, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, PreflightCheck{
+		Name:    name,
+		Status:  status,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Namespace returns the namespace the client is configured for.
+func (c *Client) Namespace() string {
+	return c.namespace
+}
+
+// Preflight verifies that the cluster is set up for the meta-agent: the
+// namespace exists, the kagent CRDs are installed, the service account has the
+// permissions required by the tool set, and the kagent controller is running.
+func (c *Client) Preflight(ctx context.Context, opts PreflightOptions) *PreflightReport {
+	report := &PreflightReport{Namespace: c.namespace}
+
+	c.checkNamespace(ctx, report)
+	c.checkCRDs(ctx, report)
+	c.checkAccess(ctx, report, opts.Requirements)
+	c.checkController(ctx, report, opts)
+
+	return report
+}
+
+func (c *Client) checkNamespace(ctx context.Context, report *PreflightReport) {
+	const name = "namespace"
+
+	_, err := c.dynamicClient.Resource(NamespaceGVR).Get(ctx, c.namespace, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		report.add(name, PreflightPass, "namespace '%s' exists", c.namespace)
+	case apierrors.IsNotFound(err):
+		report.add(name, PreflightFail, "namespace '%s' does not exist", c.namespace)
+	case apierrors.IsForbidden(err):
+		// Namespaces are cluster-scoped; a namespaced Role cannot read them.
+		report.add(name, PreflightWarn, "cannot verify namespace '%s' (forbidden to read namespaces)", c.namespace)
+	default:
+		report.add(name, PreflightFail, "failed to check namespace '%s': %v", c.namespace, err)
+	}
+}
+
+func (c *Client) checkCRDs(ctx context.Context, report *PreflightReport) {
+	for _, gvr := range []schema.GroupVersionResource{AgentGVR, ModelConfigGVR, MCPServerGVR, RemoteMCPServerGVR} {
+		name := fmt.Sprintf("crd:%s.%s", gvr.Resource, gvr.Group)
+
+		_, err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).List(ctx, metav1.ListOptions{Limit: 1})
+		switch {
+		case err == nil:
+			report.add(name, PreflightPass, "%s/%s %s is served", gvr.Group, gvr.Version, gvr.Resource)
+		case apierrors.IsNotFound(err):
+			report.add(name, PreflightFail, "%s/%s %s is not served; is kagent installed?", gvr.Group, gvr.Version, gvr.Resource)
+		case apierrors.IsForbidden(err):
+			report.add(name, PreflightWarn, "cannot verify %s/%s %s (forbidden to list)", gvr.Group, gvr.Version, gvr.Resource)
+		default:
+			report.add(name, PreflightFail, "failed to check %s/%s %s: %v", gvr.Group, gvr.Version, gvr.Resource, err)
+		}
+	}
+}
+
+func (c *Client) checkAccess(ctx context.Context, report *PreflightReport, requirements []AccessRequirement) {
+	for _, req := range requirements {
+		name := fmt.Sprintf("rbac:%s", req.GVR.Resource)
+
+		var denied []string
+		var checkErr error
+		for _, verb := range req.Verbs {
+			allowed, err := c.CanI(ctx, req.GVR, verb)
+			if err != nil {
+				checkErr = err
+				break
+			}
+			if !allowed {
+				denied = append(denied, verb)
+			}
+		}
+
+		switch {
+		case checkErr != nil:
+			report.add(name, PreflightWarn, "cannot verify access to %s: %v", req.GVR.Resource, checkErr)
+		case len(denied) > 0:
+			report.add(name, PreflightFail, "missing permissions on %s: %s", req.GVR.Resource, strings.Join(denied, ", "))
+		default:
+			report.add(name, PreflightPass, "allowed %s on %s", strings.Join(req.Verbs, ", "), req.GVR.Resource)
+		}
+	}
+}
+
+func (c *Client) checkController(ctx context.Context, report *PreflightReport, opts PreflightOptions) {
+	const name = "controller"
+
+	if opts.ControllerName == "" {
+		return
+	}
+	namespace := opts.ControllerNamespace
+	if namespace == "" {
+		namespace = c.namespace
+	}
+
+	obj, err := c.dynamicClient.Resource(DeploymentGVR).Namespace(namespace).Get(ctx, opts.ControllerName, metav1.GetOptions{})
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		report.add(name, PreflightFail, "controller Deployment '%s/%s' not found", namespace, opts.ControllerName)
+		return
+	case apierrors.IsForbidden(err):
+		report.add(name, PreflightWarn, "cannot verify controller Deployment '%s/%s' (forbidden to read deployments)", namespace, opts.ControllerName)
+		return
+	default:
+		report.add(name, PreflightFail, "failed to check controller Deployment '%s/%s': %v", namespace, opts.ControllerName, err)
+		return
+	}
+
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	if available == 0 {
+		report.add(name, PreflightFail, "controller Deployment '%s/%s' has no available replicas", namespace, opts.ControllerName)
+		return
+	}
+	report.add(name, PreflightPass, "controller Deployment '%s/%s' has %d available replica(s)", namespace, opts.ControllerName, available)
+}
+
+// CanI checks whether the current identity may perform verb on the given
+// resource in the configured namespace, using a SelfSubjectAccessReview.
+func (c *Client) CanI(ctx context.Context, gvr schema.GroupVersionResource, verb string) (bool, error) {
+	review := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "authorization.k8s.io/v1",
+		"kind":       "SelfSubjectAccessReview",
+		"spec": map[string]interface{}{
+			"resourceAttributes": map[string]interface{}{
+				"namespace": c.namespace,
+				"group":     gvr.Group,
+				"version":   gvr.Version,
+				"resource":  gvr.Resource,
+				"verb":      verb,
+			},
+		},
+	}}
+
+	result, err := c.dynamicClient.Resource(SelfSubjectAccessReviewGVR).Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to create access review: %w", err)
+	}
+
+	allowed, _, _ := unstructured.NestedBool(result.Object, "status", "allowed")
+	return allowed, nil
+}
@@ -0,0 +1,67 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupVersionResource definitions for RBAC resources.
+var (
+	RoleGVR = schema.GroupVersionResource{
+		Group:    "rbac.authorization.k8s.io",
+		Version:  "v1",
+		Resource: "roles",
+	}
+
+	ClusterRoleGVR = schema.GroupVersionResource{
+		Group:    "rbac.authorization.k8s.io",
+		Version:  "v1",
+		Resource: "clusterroles",
+	}
+)
+
+// PolicyRule is a rule of a Role or ClusterRole.
+type PolicyRule struct {
+	APIGroups       []string `json:"apiGroups,omitempty"`
+	Resources       []string `json:"resources,omitempty"`
+	ResourceNames   []string `json:"resourceNames,omitempty"`
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+	Verbs           []string `json:"verbs"`
+}
+
+// RoleRules returns the rules of the Role name in namespace (the client's
+// namespace when empty).
+func (c *Client) RoleRules(ctx context.Context, namespace, name string) ([]PolicyRule, error) {
+	obj, err := c.GetResourceIn(ctx, RoleGVR, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return rulesOf(obj.Object)
+}
+
+// ClusterRoleRules returns the rules of the ClusterRole name, including
+// those aggregated into it.
+func (c *Client) ClusterRoleRules(ctx context.Context, name string) ([]PolicyRule, error) {
+	obj, err := c.dynamicClient.Resource(ClusterRoleGVR).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clusterrole %s: %w", name, err)
+	}
+	return rulesOf(obj.Object)
+}
+
+// rulesOf decodes the rules of a Role or ClusterRole.
+func rulesOf(object map[string]interface{}) ([]PolicyRule, error) {
+	raw, err := json.Marshal(object["rules"])
+	if err != nil {
+		return nil, err
+	}
+	var rules []PolicyRule
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	return rules, nil
+}
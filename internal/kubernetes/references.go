@@ -0,0 +1,148 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// Reference is a field of one resource that names another.
+type Reference struct {
+	// Kind and Name identify the referencing resource.
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Field string `json:"field"`
+}
+
+// ReferenceIndex maps resources to the resources referencing them. It is a
+// snapshot of the configured namespace taken by BuildReferenceIndex or
+// IndexReferences.
+type ReferenceIndex struct {
+	refs map[string][]Reference
+}
+
+// BuildReferenceIndex lists the Agents and ModelConfigs in the configured
+// namespace and indexes the resources they reference: model configs, MCP
+// servers, Services and agents used as tools, and API key Secrets.
+func (c *Client) BuildReferenceIndex(ctx context.Context) (*ReferenceIndex, error) {
+	snapshot, err := c.ListSnapshot(ctx, AgentGVR, ModelConfigGVR)
+	if err != nil {
+		return nil, err
+	}
+	return IndexReferences(snapshot), nil
+}
+
+// IndexReferences indexes the references of the Agents and ModelConfigs in
+// a snapshot, for callers that read other kinds in the same snapshot.
+func IndexReferences(snapshot *Snapshot) *ReferenceIndex {
+	x := &ReferenceIndex{refs: map[string][]Reference{}}
+
+	for _, agent := range snapshot.Items(AgentGVR) {
+		x.addAgent(agent)
+	}
+
+	for _, config := range snapshot.Items(ModelConfigGVR) {
+		if secret, _, _ := unstructured.NestedString(config.Object, "spec", "apiKeySecret"); secret != "" {
+			x.add("Secret", config.GetNamespace(), secret, Reference{Kind: "ModelConfig", Name: config.GetName(), Field: "spec.apiKeySecret"})
+		}
+	}
+	return x
+}
+
+// addAgent indexes the references of an Agent.
+func (x *ReferenceIndex) addAgent(agent unstructured.Unstructured) {
+	from := func(field string) Reference {
+		return Reference{Kind: "Agent", Name: agent.GetName(), Field: field}
+	}
+
+	if ref, _, _ := unstructured.NestedString(agent.Object, "spec", "declarative", "modelConfig"); ref != "" {
+		x.add("ModelConfig", agent.GetNamespace(), ref, from("spec.declarative.modelConfig"))
+	}
+
+	tools, _, _ := unstructured.NestedSlice(agent.Object, "spec", "declarative", "tools")
+	for i, t := range tools {
+		tool, _ := t.(map[string]interface{})
+		if name, _, _ := unstructured.NestedString(tool, "mcpServer", "name"); name != "" {
+			kind, _, _ := unstructured.NestedString(tool, "mcpServer", "kind")
+			if kind == "" {
+				kind = "MCPServer"
+			}
+			x.add(kind, agent.GetNamespace(), name, from(fmt.Sprintf("spec.declarative.tools[%d].mcpServer", i)))
+		}
+		if name, _, _ := unstructured.NestedString(tool, "agent", "name"); name != "" {
+			x.add("Agent", agent.GetNamespace(), name, from(fmt.Sprintf("spec.declarative.tools[%d].agent", i)))
+		}
+	}
+}
+
+// add records a reference to kind/name, resolving ref relative to the
+// referencing resource's namespace.
+func (x *ReferenceIndex) add(kind, namespace, ref string, r Reference) {
+	parsed, err := types.ParseObjectRef(ref, namespace)
+	if err != nil {
+		return
+	}
+	key := kind + "/" + parsed.Namespace + "/" + parsed.Name
+	x.refs[key] = append(x.refs[key], r)
+}
+
+// ReferencesTo returns the references to a resource, ordered by referencing
+// resource.
+func (x *ReferenceIndex) ReferencesTo(kind, namespace, name string) []Reference {
+	refs := append([]Reference(nil), x.refs[kind+"/"+namespace+"/"+name]...)
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Kind != refs[j].Kind {
+			return refs[i].Kind < refs[j].Kind
+		}
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].Field < refs[j].Field
+	})
+	return refs
+}
+
+// ownedKinds are the kinds the kagent controller creates for the resources
+// it reconciles, with owner references back to them.
+var ownedKinds = []struct {
+	Kind string
+	GVR  schema.GroupVersionResource
+}{
+	{"Deployment", DeploymentGVR},
+	{"Service", ServiceGVR},
+	{"ConfigMap", ConfigMapGVR},
+}
+
+// OwnedBy lists the objects in the configured namespace that have an owner
+// reference to uid, as "Kind/name". Kinds the identity may not list are
+// skipped.
+func (c *Client) OwnedBy(ctx context.Context, uid k8stypes.UID) ([]string, error) {
+	var owned []string
+	for _, k := range ownedKinds {
+		list, err := c.dynamicClient.Resource(k.GVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+		if apierrors.IsForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", k.GVR.Resource, err)
+		}
+		for _, item := range list.Items {
+			for _, ref := range item.GetOwnerReferences() {
+				if ref.UID == uid {
+					owned = append(owned, k.Kind+"/"+item.GetName())
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(owned)
+	return owned, nil
+}
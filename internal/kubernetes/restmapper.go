@@ -0,0 +1,292 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// RESTMapper resolves GroupVersionKinds to resources using the API server's
+// discovery endpoints. Discovery documents are cached per group version.
+//
+// It talks to the discovery endpoints directly rather than through
+// client-go's discovery package to avoid pulling the typed API and OpenAPI
+// dependencies into the module.
+type RESTMapper struct {
+	httpClient *http.Client
+	baseURL    *url.URL
+
+	mu     sync.RWMutex
+	cache  map[schema.GroupVersion]*metav1.APIResourceList
+	groups *metav1.APIGroupList
+
+	openapi openAPICache
+}
+
+// NewRESTMapper creates a discovery-backed RESTMapper for the given config.
+func NewRESTMapper(config *rest.Config) (*RESTMapper, error) {
+	httpClient, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery http client: %w", err)
+	}
+
+	baseURL, _, err := rest.DefaultServerUrlFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine api server url: %w", err)
+	}
+
+	return &RESTMapper{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		cache:      make(map[schema.GroupVersion]*metav1.APIResourceList),
+	}, nil
+}
+
+// RESTMapping returns the resource mapping for the given kind. If the kind is
+// not found, the cached discovery data is refreshed once in case a CRD was
+// installed after it was cached. When the kind is served at a different
+// version of the same group, the error names the served versions.
+func (m *RESTMapper) RESTMapping(ctx context.Context, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := m.lookup(ctx, gvk)
+	if err == nil || !meta.IsNoMatchError(err) {
+		return mapping, err
+	}
+
+	m.Reset()
+	mapping, err = m.lookup(ctx, gvk)
+	if err == nil || !meta.IsNoMatchError(err) {
+		return mapping, err
+	}
+
+	if served := m.servedVersions(ctx, gvk.GroupKind()); len(served) > 0 {
+		return nil, fmt.Errorf("%s is not served at %s; the cluster serves it at %s",
+			gvk.Kind, gvk.GroupVersion().String(), strings.Join(served, ", "))
+	}
+	return nil, err
+}
+
+// KindFor resolves a kind given only by name (and optionally apiVersion) to
+// its resource mapping. Without an apiVersion, kagent.dev is searched first,
+// then the core group, then every other group at its preferred version.
+func (m *RESTMapper) KindFor(ctx context.Context, apiVersion, kind string) (*meta.RESTMapping, error) {
+	if apiVersion != "" {
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid apiVersion %q: %w", apiVersion, err)
+		}
+		return m.RESTMapping(ctx, gv.WithKind(kind))
+	}
+
+	groups, err := m.groupList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []schema.GroupVersion{{Version: "v1"}}
+	for _, g := range groups.Groups {
+		gv, err := schema.ParseGroupVersion(g.PreferredVersion.GroupVersion)
+		if err != nil {
+			continue
+		}
+		if g.Name == AgentGVR.Group {
+			candidates = append([]schema.GroupVersion{gv}, candidates...)
+		} else {
+			candidates = append(candidates, gv)
+		}
+	}
+
+	for _, gv := range candidates {
+		mapping, err := m.lookup(ctx, gv.WithKind(kind))
+		if err == nil {
+			return mapping, nil
+		}
+		if !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("unknown kind: %s (not served by the cluster)", kind)
+}
+
+// lookup finds gvk in the (cached) discovery document for its group version.
+func (m *RESTMapper) lookup(ctx context.Context, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	resources, err := m.resourcesFor(ctx, gvk.GroupVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range resources.APIResources {
+		// Skip subresources such as agents/status
+		if r.Kind != gvk.Kind || strings.Contains(r.Name, "/") {
+			continue
+		}
+		scope := meta.RESTScopeRoot
+		if r.Namespaced {
+			scope = meta.RESTScopeNamespace
+		}
+		return &meta.RESTMapping{
+			Resource:         gvk.GroupVersion().WithResource(r.Name),
+			GroupVersionKind: gvk,
+			Scope:            scope,
+		}, nil
+	}
+
+	return nil, &meta.NoKindMatchError{
+		GroupKind:        gvk.GroupKind(),
+		SearchedVersions: []string{gvk.Version},
+	}
+}
+
+// servedVersions returns the group versions at which the cluster serves gk.
+func (m *RESTMapper) servedVersions(ctx context.Context, gk schema.GroupKind) []string {
+	groups, err := m.groupList(ctx)
+	if err != nil {
+		return nil
+	}
+
+	var served []string
+	for _, g := range groups.Groups {
+		if g.Name != gk.Group {
+			continue
+		}
+		for _, v := range g.Versions {
+			if _, err := m.lookup(ctx, schema.GroupVersionKind{Group: gk.Group, Version: v.Version, Kind: gk.Kind}); err == nil {
+				served = append(served, v.GroupVersion)
+			}
+		}
+	}
+	return served
+}
+
+// Serves reports whether the cluster serves gk at any version, reading the
+// discovery documents afresh so that CRDs installed since they were cached
+// are found.
+func (m *RESTMapper) Serves(ctx context.Context, gk schema.GroupKind) (bool, error) {
+	m.Reset()
+	groups, err := m.groupList(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, g := range groups.Groups {
+		if g.Name != gk.Group {
+			continue
+		}
+		for _, v := range g.Versions {
+			_, err := m.lookup(ctx, schema.GroupVersionKind{Group: gk.Group, Version: v.Version, Kind: gk.Kind})
+			if err == nil {
+				return true, nil
+			}
+			if !meta.IsNoMatchError(err) {
+				return false, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// groupList returns the (cached) list of API groups served by the cluster.
+func (m *RESTMapper) groupList(ctx context.Context) (*metav1.APIGroupList, error) {
+	m.mu.RLock()
+	cached := m.groups
+	m.mu.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	var groups metav1.APIGroupList
+	if _, err := m.get(ctx, "/apis", &groups); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.groups = &groups
+	m.mu.Unlock()
+	return &groups, nil
+}
+
+// Reset drops all cached discovery documents.
+func (m *RESTMapper) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache = make(map[schema.GroupVersion]*metav1.APIResourceList)
+	m.groups = nil
+}
+
+// resourcesFor returns the (cached) discovery document for a group version.
+func (m *RESTMapper) resourcesFor(ctx context.Context, gv schema.GroupVersion) (*metav1.APIResourceList, error) {
+	m.mu.RLock()
+	cached, ok := m.cache[gv]
+	m.mu.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	// Core resources live under /api, everything else under /apis
+	p := path.Join("/apis", gv.Group, gv.Version)
+	if gv.Group == "" {
+		p = path.Join("/api", gv.Version)
+	}
+
+	var list metav1.APIResourceList
+	found, err := m.get(ctx, p, &list)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &meta.NoKindMatchError{
+			GroupKind:        schema.GroupKind{Group: gv.Group},
+			SearchedVersions: []string{gv.Version},
+		}
+	}
+
+	m.mu.Lock()
+	m.cache[gv] = &list
+	m.mu.Unlock()
+	return &list, nil
+}
+
+// get fetches a discovery document. p may carry a query. found is false if
+// the server returned 404.
+func (m *RESTMapper) get(ctx context.Context, p string, into interface{}) (bool, error) {
+	ref, err := url.Parse(p)
+	if err != nil {
+		return false, fmt.Errorf("invalid discovery path %s: %w", p, err)
+	}
+	u := *m.baseURL
+	u.Path = path.Join(u.Path, ref.Path)
+	u.RawQuery = ref.RawQuery
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to build discovery request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("discovery request to %s failed: %w", p, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("discovery request to %s returned %s", p, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
+		return false, fmt.Errorf("failed to decode discovery document %s: %w", p, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupVersionResource definitions for core resources referenced by agents.
+var (
+	SecretGVR = schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "secrets",
+	}
+
+	ServiceGVR = schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "services",
+	}
+)
+
+// IngressGVR is the GroupVersionResource for Ingresses, which expose agents'
+// A2A endpoints outside the cluster.
+var IngressGVR = schema.GroupVersionResource{
+	Group:    "networking.k8s.io",
+	Version:  "v1",
+	Resource: "ingresses",
+}
+
+// RedactedValue replaces Secret values in resources the server returns.
+const RedactedValue = "(redacted)"
+
+// RedactSecretValues replaces the values of a Secret's data and stringData
+// with RedactedValue, keeping the keys, so credentials never reach the
+// conversation. It reports whether obj is a Secret; other resources are left
+// unchanged.
+func RedactSecretValues(obj *unstructured.Unstructured) bool {
+	if gvk := obj.GroupVersionKind(); gvk.Group != "" || gvk.Kind != "Secret" {
+		return false
+	}
+	for _, field := range []string{"data", "stringData"} {
+		values, _, _ := unstructured.NestedMap(obj.Object, field)
+		if len(values) == 0 {
+			continue
+		}
+		for key := range values {
+			values[key] = RedactedValue
+		}
+		_ = unstructured.SetNestedMap(obj.Object, values, field)
+	}
+	return true
+}
+
+// SecretKeys returns the data keys of a Secret in the configured namespace.
+// Secret values are never returned. known is false when the identity is not
+// allowed to read secrets, in which case found and keys should be ignored.
+func (c *Client) SecretKeys(ctx context.Context, name string) (keys []string, found bool, known bool, err error) {
+	return c.SecretKeysIn(ctx, c.namespace, name)
+}
+
+// SecretKeysIn is SecretKeys for a Secret in the given namespace.
+func (c *Client) SecretKeysIn(ctx context.Context, namespace, name string) (keys []string, found bool, known bool, err error) {
+	obj, err := c.dynamicClient.Resource(SecretGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil, false, true, nil
+	case apierrors.IsForbidden(err):
+		return nil, false, false, nil
+	case err != nil:
+		return nil, false, false, fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+
+	data, _, _ := unstructured.NestedMap(obj.Object, "data")
+	for key := range data {
+		keys = append(keys, key)
+	}
+	return keys, true, true, nil
+}
@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// snapshotAttempts is how many times ListSnapshot tries to pin a
+// resourceVersion before settling for lists that may be inconsistent.
+const snapshotAttempts = 3
+
+// Snapshot holds lists of several kinds in the configured namespace read
+// from the API server at the same resourceVersion, so that reports built
+// from them do not see a resource that references another which, in the
+// same report, was already deleted or not yet created.
+type Snapshot struct {
+	// ResourceVersion is the version every list was read at.
+	ResourceVersion string
+	// Consistent is false when the API server could not serve lists at a
+	// pinned version; the lists are then each current when read, and a
+	// change made while they were read may show in some but not others.
+	Consistent bool
+
+	items map[schema.GroupVersionResource][]unstructured.Unstructured
+}
+
+// Items returns the resources of gvr in the snapshot.
+func (s *Snapshot) Items(gvr schema.GroupVersionResource) []unstructured.Unstructured {
+	return s.items[gvr]
+}
+
+// ListSnapshot lists each of gvrs in the configured namespace at one
+// resourceVersion. The first list is a quorum read that fixes the version;
+// the others are read at exactly that version. resourceVersions are
+// revisions of the one etcd store behind every kind, so the lists show the
+// namespace as it was at a single point in time. When the version can no
+// longer be served, e.g. it was compacted while the lists were read, the
+// snapshot is retried at a fresh version. The informer cache is never used:
+// it is only eventually consistent.
+func (c *Client) ListSnapshot(ctx context.Context, gvrs ...schema.GroupVersionResource) (*Snapshot, error) {
+	for attempt := 0; attempt < snapshotAttempts; attempt++ {
+		snapshot, err := c.listPinned(ctx, gvrs)
+		if err == nil {
+			return snapshot, nil
+		}
+		if !unpinnable(err) {
+			return nil, err
+		}
+	}
+
+	// The server cannot serve pinned lists (or keeps compacting before the
+	// reads complete): fall back to a quorum read of each kind
+	snapshot := &Snapshot{items: map[schema.GroupVersionResource][]unstructured.Unstructured{}}
+	for _, gvr := range gvrs {
+		list, err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+		}
+		snapshot.items[gvr] = list.Items
+		snapshot.ResourceVersion = list.GetResourceVersion()
+	}
+	return snapshot, nil
+}
+
+// listPinned reads one snapshot attempt.
+func (c *Client) listPinned(ctx context.Context, gvrs []schema.GroupVersionResource) (*Snapshot, error) {
+	snapshot := &Snapshot{Consistent: true, items: map[schema.GroupVersionResource][]unstructured.Unstructured{}}
+	for i, gvr := range gvrs {
+		opts := metav1.ListOptions{}
+		if i > 0 {
+			opts.ResourceVersion = snapshot.ResourceVersion
+			opts.ResourceVersionMatch = metav1.ResourceVersionMatchExact
+		}
+		list, err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+		}
+		if i == 0 {
+			snapshot.ResourceVersion = list.GetResourceVersion()
+		}
+		snapshot.items[gvr] = list.Items
+	}
+	return snapshot, nil
+}
+
+// unpinnable reports whether a list failed because it could not be served
+// at the requested resourceVersion, rather than for a reason that retrying
+// or reading unpinned would not fix.
+func unpinnable(err error) bool {
+	return apierrors.IsResourceExpired(err) || apierrors.IsGone(err) || apierrors.IsBadRequest(err) || apierrors.IsInvalid(err)
+}
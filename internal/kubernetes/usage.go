@@ -0,0 +1,101 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupVersionResource definitions for resource usage sources.
+var (
+	VerticalPodAutoscalerGVR = schema.GroupVersionResource{
+		Group:    "autoscaling.k8s.io",
+		Version:  "v1",
+		Resource: "verticalpodautoscalers",
+	}
+
+	PodMetricsGVR = schema.GroupVersionResource{
+		Group:    "metrics.k8s.io",
+		Version:  "v1beta1",
+		Resource: "pods",
+	}
+)
+
+// ContainerUsage is the CPU and memory used or recommended for a container.
+type ContainerUsage struct {
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+// VPARecommendations returns the target recommendation per container of the
+// VerticalPodAutoscaler targeting a Deployment. The map is empty when no VPA
+// targets it or it has no recommendation yet. known is false when the VPA
+// API is not installed or the identity may not list VPAs.
+func (c *Client) VPARecommendations(ctx context.Context, deployment string) (recs map[string]ContainerUsage, known bool, err error) {
+	list, err := c.dynamicClient.Resource(VerticalPodAutoscalerGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list vertical pod autoscalers: %w", err)
+	}
+
+	recs = map[string]ContainerUsage{}
+	for _, item := range list.Items {
+		kind, _, _ := unstructured.NestedString(item.Object, "spec", "targetRef", "kind")
+		name, _, _ := unstructured.NestedString(item.Object, "spec", "targetRef", "name")
+		if kind != "Deployment" || name != deployment {
+			continue
+		}
+
+		containers, _, _ := unstructured.NestedSlice(item.Object, "status", "recommendation", "containerRecommendations")
+		for _, c := range containers {
+			rec, _ := c.(map[string]interface{})
+			containerName, _, _ := unstructured.NestedString(rec, "containerName")
+			target, _, _ := unstructured.NestedStringMap(rec, "target")
+			quantities := parseQuantities(target)
+			recs[containerName] = ContainerUsage{CPU: quantities["cpu"], Memory: quantities["memory"]}
+		}
+		break
+	}
+	return recs, true, nil
+}
+
+// PodUsage returns the peak current usage per container across the pods
+// matching selector, as reported by metrics-server. known is false when
+// the metrics API is not installed or the identity may not read it.
+func (c *Client) PodUsage(ctx context.Context, selector string) (usage map[string]ContainerUsage, known bool, err error) {
+	list, err := c.dynamicClient.Resource(PodMetricsGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list pod metrics: %w", err)
+	}
+
+	usage = map[string]ContainerUsage{}
+	for _, item := range list.Items {
+		containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+		for _, c := range containers {
+			container, _ := c.(map[string]interface{})
+			name, _, _ := unstructured.NestedString(container, "name")
+			values, _, _ := unstructured.NestedStringMap(container, "usage")
+			quantities := parseQuantities(values)
+
+			peak := usage[name]
+			if cpu := quantities["cpu"]; cpu.Cmp(peak.CPU) > 0 {
+				peak.CPU = cpu
+			}
+			if memory := quantities["memory"]; memory.Cmp(peak.Memory) > 0 {
+				peak.Memory = memory
+			}
+			usage[name] = peak
+		}
+	}
+	return usage, true, nil
+}
@@ -0,0 +1,177 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// watchRetryInterval is how long a managed watch waits before listing
+// again after the API server refused or ended it with an error.
+const watchRetryInterval = 5 * time.Second
+
+// WatchEvent reports a change to a resource seen by a WatchManager.
+type WatchEvent struct {
+	GVR       schema.GroupVersionResource
+	Namespace string
+	// Name is the resource that changed. It is empty when the watch was
+	// re-established after missing events, so any resource of the kind may
+	// have changed.
+	Name string
+	// Type is Added, Modified or Deleted; it is empty with Name.
+	Type watch.EventType
+}
+
+// WatchManager shares one watch per kind and namespace among any number of
+// subscribers, and reports every change to its handler and every failed
+// list or watch, which is retried, to its error handler. A watch starts
+// with the first Acquire of its kind and namespace and stops with the last
+// Release. It is safe for concurrent use.
+type WatchManager struct {
+	client  *Client
+	handler func(WatchEvent)
+	onError func(error)
+
+	mu      sync.Mutex
+	watches map[watchKey]*managedWatch
+}
+
+type watchKey struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+type managedWatch struct {
+	refs   int
+	cancel context.CancelFunc
+}
+
+// NewWatchManager creates a watch manager reporting changes to handler
+// and failures to onError. Both are called from the watches' goroutines and
+// must not block.
+func NewWatchManager(client *Client, handler func(WatchEvent), onError func(error)) *WatchManager {
+	return &WatchManager{
+		client:  client,
+		handler: handler,
+		onError: onError,
+		watches: map[watchKey]*managedWatch{},
+	}
+}
+
+// Acquire starts watching gvr in namespace, unless it is watched already.
+// Every Acquire must be matched by a Release.
+func (m *WatchManager) Acquire(gvr schema.GroupVersionResource, namespace string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := watchKey{gvr: gvr, namespace: namespace}
+	if w, ok := m.watches[key]; ok {
+		w.refs++
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.watches[key] = &managedWatch{refs: 1, cancel: cancel}
+	go m.run(ctx, key)
+}
+
+// Release gives up a watch taken with Acquire, stopping it when no one
+// else holds it.
+func (m *WatchManager) Release(gvr schema.GroupVersionResource, namespace string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := watchKey{gvr: gvr, namespace: namespace}
+	w, ok := m.watches[key]
+	if !ok {
+		return
+	}
+	if w.refs--; w.refs == 0 {
+		w.cancel()
+		delete(m.watches, key)
+	}
+}
+
+// run keeps a watch open until ctx is cancelled. It lists first so that
+// existing resources are not reported as added, then watches from the
+// list's resourceVersion, resuming from the last event seen when the API
+// server closes the watch. When the version has expired it lists again and
+// reports that anything may have changed.
+func (m *WatchManager) run(ctx context.Context, key watchKey) {
+	resources := m.client.dynamicClient.Resource(key.gvr).Namespace(key.namespace)
+	resourceVersion := ""
+	for ctx.Err() == nil {
+		if resourceVersion == "" {
+			list, err := resources.List(ctx, metav1.ListOptions{})
+			if err != nil {
+				m.retry(ctx, key, err)
+				continue
+			}
+			resourceVersion = list.GetResourceVersion()
+		}
+
+		w, err := resources.Watch(ctx, metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		})
+		if err != nil {
+			if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+				resourceVersion = ""
+				m.handler(WatchEvent{GVR: key.gvr, Namespace: key.namespace})
+				continue
+			}
+			m.retry(ctx, key, err)
+			continue
+		}
+		resourceVersion = m.consume(key, w, resourceVersion)
+		w.Stop()
+	}
+}
+
+// consume reports the events of w until it ends, and returns the
+// resourceVersion to resume from, or "" to list again.
+func (m *WatchManager) consume(key watchKey, w watch.Interface, resourceVersion string) string {
+	for event := range w.ResultChan() {
+		switch event.Type {
+		case watch.Error:
+			// Typically 410 Gone: the version was compacted away
+			m.handler(WatchEvent{GVR: key.gvr, Namespace: key.namespace})
+			return ""
+		case watch.Bookmark:
+			if obj, ok := event.Object.(*unstructured.Unstructured); ok {
+				resourceVersion = obj.GetResourceVersion()
+			}
+		case watch.Added, watch.Modified, watch.Deleted:
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			resourceVersion = obj.GetResourceVersion()
+			m.handler(WatchEvent{
+				GVR:       key.gvr,
+				Namespace: key.namespace,
+				Name:      obj.GetName(),
+				Type:      event.Type,
+			})
+		}
+	}
+	return resourceVersion
+}
+
+// retry reports a failed list or watch and waits before the next attempt.
+func (m *WatchManager) retry(ctx context.Context, key watchKey, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	m.onError(fmt.Errorf("failed to watch %s in namespace %s: %w", key.gvr.Resource, key.namespace, err))
+	select {
+	case <-ctx.Done():
+	case <-time.After(watchRetryInterval):
+	}
+}
@@ -0,0 +1,219 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// PodGVR is the GroupVersionResource of core Pods.
+var PodGVR = schema.GroupVersionResource{
+	Group:    "",
+	Version:  "v1",
+	Resource: "pods",
+}
+
+// WorkloadCondition is a condition of a Deployment or Pod.
+type WorkloadCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// DeploymentStatus is the rollout state of a Deployment.
+type DeploymentStatus struct {
+	Name              string              `json:"name"`
+	Replicas          int64               `json:"replicas"`
+	ReadyReplicas     int64               `json:"readyReplicas"`
+	UpdatedReplicas   int64               `json:"updatedReplicas"`
+	AvailableReplicas int64               `json:"availableReplicas"`
+	Conditions        []WorkloadCondition `json:"conditions,omitempty"`
+	// Selector selects the Deployment's pods.
+	Selector string `json:"-"`
+}
+
+// ContainerState is the state of a container in a Pod.
+type ContainerState struct {
+	Name     string `json:"name"`
+	Ready    bool   `json:"ready"`
+	Restarts int64  `json:"restarts"`
+	// State is "running", "waiting" or "terminated", with the reason and
+	// message of the latter two (e.g., CrashLoopBackOff, OOMKilled).
+	State   string `json:"state"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// LastTermination is the reason the previous instance ended, and
+	// LastTerminatedAt when.
+	LastTermination  string    `json:"lastTermination,omitempty"`
+	LastTerminatedAt time.Time `json:"-"`
+}
+
+// PodStatus is the state of a Pod and its containers.
+type PodStatus struct {
+	Name       string              `json:"name"`
+	Phase      string              `json:"phase"`
+	Node       string              `json:"node,omitempty"`
+	Conditions []WorkloadCondition `json:"conditions,omitempty"`
+	Containers []ContainerState    `json:"containers,omitempty"`
+}
+
+// GetDeploymentStatus returns the status of a Deployment in the configured
+// namespace. known is false when the identity may not read Deployments.
+func (c *Client) GetDeploymentStatus(ctx context.Context, name string) (status *DeploymentStatus, known bool, err error) {
+	obj, err := c.dynamicClient.Resource(DeploymentGVR).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsForbidden(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get deployment %s: %w", name, err)
+	}
+
+	status = &DeploymentStatus{Name: name, Conditions: workloadConditions(obj.Object)}
+	status.Replicas, _, _ = unstructured.NestedInt64(obj.Object, "status", "replicas")
+	status.ReadyReplicas, _, _ = unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+	status.UpdatedReplicas, _, _ = unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	status.AvailableReplicas, _, _ = unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	if matchLabels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector", "matchLabels"); len(matchLabels) > 0 {
+		status.Selector = labels.SelectorFromSet(matchLabels).String()
+	}
+	return status, true, nil
+}
+
+// ListPodStatuses returns the status of the Pods matching a label selector
+// in the configured namespace, ordered by name. known is false when the
+// identity may not list Pods.
+func (c *Client) ListPodStatuses(ctx context.Context, selector string) (pods []PodStatus, known bool, err error) {
+	list, err := c.dynamicClient.Resource(PodGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if apierrors.IsForbidden(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	for _, item := range list.Items {
+		phase, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+		node, _, _ := unstructured.NestedString(item.Object, "spec", "nodeName")
+		pod := PodStatus{
+			Name:       item.GetName(),
+			Phase:      phase,
+			Node:       node,
+			Conditions: workloadConditions(item.Object),
+		}
+		statuses, _, _ := unstructured.NestedSlice(item.Object, "status", "containerStatuses")
+		for _, s := range statuses {
+			if status, ok := s.(map[string]interface{}); ok {
+				pod.Containers = append(pod.Containers, containerState(status))
+			}
+		}
+		pods = append(pods, pod)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods, true, nil
+}
+
+// workloadConditions reads status.conditions of a Deployment or Pod.
+func workloadConditions(obj map[string]interface{}) []WorkloadCondition {
+	raw, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	var conditions []WorkloadCondition
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		c := WorkloadCondition{}
+		c.Type, _, _ = unstructured.NestedString(m, "type")
+		c.Status, _, _ = unstructured.NestedString(m, "status")
+		c.Reason, _, _ = unstructured.NestedString(m, "reason")
+		c.Message, _, _ = unstructured.NestedString(m, "message")
+		conditions = append(conditions, c)
+	}
+	return conditions
+}
+
+// containerState reads an entry of status.containerStatuses.
+func containerState(status map[string]interface{}) ContainerState {
+	c := ContainerState{}
+	c.Name, _, _ = unstructured.NestedString(status, "name")
+	c.Ready, _, _ = unstructured.NestedBool(status, "ready")
+	c.Restarts, _, _ = unstructured.NestedInt64(status, "restartCount")
+
+	for _, state := range []string{"waiting", "terminated", "running"} {
+		if _, ok, _ := unstructured.NestedMap(status, "state", state); ok {
+			c.State = state
+			c.Reason, _, _ = unstructured.NestedString(status, "state", state, "reason")
+			c.Message, _, _ = unstructured.NestedString(status, "state", state, "message")
+			break
+		}
+	}
+	c.LastTermination, _, _ = unstructured.NestedString(status, "lastState", "terminated", "reason")
+	c.LastTerminatedAt = timestamp(unstructured.Unstructured{Object: status}, "lastState", "terminated", "finishedAt")
+	return c
+}
+
+// maxPodLogBytes bounds how much of a container's log GetPodLogs reads.
+const maxPodLogBytes = 4 << 20
+
+// GetPodLogs returns the log lines a container of a Pod in the configured
+// namespace wrote since the given time, up to tailLines lines (all when
+// zero). known is false when the identity may not read pod logs.
+func (c *Client) GetPodLogs(ctx context.Context, pod, container string, since time.Time, tailLines int) (logs string, known bool, err error) {
+	// Logs are a subresource the dynamic client cannot read
+	httpClient, err := rest.HTTPClientFor(c.config)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create http client: %w", err)
+	}
+	u, _, err := rest.DefaultServerUrlFor(c.config)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to determine api server url: %w", err)
+	}
+	u.Path = path.Join(u.Path, "api", "v1", "namespaces", c.namespace, "pods", pod, "log")
+	query := url.Values{"limitBytes": {strconv.Itoa(maxPodLogBytes)}}
+	if container != "" {
+		query.Set("container", container)
+	}
+	if !since.IsZero() {
+		query.Set("sinceTime", since.UTC().Format(time.RFC3339))
+	}
+	if tailLines > 0 {
+		query.Set("tailLines", strconv.Itoa(tailLines))
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to build log request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read logs of pod %s: %w", pod, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		return "", false, nil
+	default:
+		return "", false, fmt.Errorf("failed to read logs of pod %s: %s", pod, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPodLogBytes))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read logs of pod %s: %w", pod, err)
+	}
+	return string(body), true, nil
+}
@@ -0,0 +1,304 @@
+// Package mockagent is a minimal A2A agent that answers without a model:
+// it echoes messages back, or replies with a fixed response. It exists to
+// test A2A wiring, Agent Cards and invocation flows without spending model
+// tokens or building a real agent first.
+package mockagent
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// Reply modes.
+const (
+	// ModeEcho replies with the text of the message received.
+	ModeEcho = "echo"
+	// ModeFixed replies with the configured response.
+	ModeFixed = "fixed"
+)
+
+// Environment variables configuring the mock agent.
+const (
+	EnvName        = "MOCK_AGENT_NAME"
+	EnvDescription = "MOCK_AGENT_DESCRIPTION"
+	EnvURL         = "MOCK_AGENT_URL"
+	EnvSkills      = "MOCK_AGENT_SKILLS"
+	EnvMode        = "MOCK_AGENT_MODE"
+	EnvResponse    = "MOCK_AGENT_RESPONSE"
+	EnvDelay       = "MOCK_AGENT_DELAY"
+)
+
+// JSON-RPC and A2A error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeTaskNotFound   = -32001
+	codeNotCancelable  = -32002
+)
+
+// maxTasks bounds the tasks kept for tasks/get; the oldest are dropped.
+const maxTasks = 1000
+
+// Config configures a mock agent.
+type Config struct {
+	Name        string
+	Description string
+	// URL is the JSON-RPC endpoint advertised in the Agent Card.
+	URL    string
+	Skills []types.Skill
+	// Mode is ModeEcho or ModeFixed.
+	Mode     string
+	Response string
+	// Delay is waited before each reply, to simulate a model call.
+	Delay time.Duration
+}
+
+// ConfigFromEnv reads the configuration from the MOCK_AGENT_* variables.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Name:        envOr(EnvName, "mock-agent"),
+		Description: envOr(EnvDescription, "Mock A2A agent that answers without a model"),
+		URL:         os.Getenv(EnvURL),
+		Mode:        envOr(EnvMode, ModeEcho),
+		Response:    envOr(EnvResponse, "This is a mock response."),
+	}
+	if raw := os.Getenv(EnvSkills); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.Skills); err != nil {
+			return cfg, fmt.Errorf("invalid %s: %w", EnvSkills, err)
+		}
+	}
+	if raw := os.Getenv(EnvDelay); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s: %w", EnvDelay, err)
+		}
+		cfg.Delay = d
+	}
+	if cfg.Mode != ModeEcho && cfg.Mode != ModeFixed {
+		return cfg, fmt.Errorf("invalid %s '%s': expected %s or %s", EnvMode, cfg.Mode, ModeEcho, ModeFixed)
+	}
+	if len(cfg.Skills) == 0 {
+		cfg.Skills = []types.Skill{{
+			ID:          cfg.Mode,
+			Name:        cfg.Mode,
+			Description: "Replies to every message without calling a model",
+			Tags:        []string{"mock"},
+			Examples:    []string{"hello"},
+		}}
+	}
+	return cfg, nil
+}
+
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// Agent serves the Agent Card and the JSON-RPC methods message/send,
+// tasks/get and tasks/cancel. Every task completes immediately.
+type Agent struct {
+	cfg Config
+
+	mu    sync.Mutex
+	tasks map[string]map[string]interface{}
+	order []string
+}
+
+// New creates a mock agent.
+func New(cfg Config) *Agent {
+	return &Agent{cfg: cfg, tasks: map[string]map[string]interface{}{}}
+}
+
+// Handler returns the HTTP handler of the agent.
+func (a *Agent) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/agent-card.json", a.serveCard)
+	mux.HandleFunc("/.well-known/agent.json", a.serveCard)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/", a.serveRPC)
+	return mux
+}
+
+func (a *Agent) serveCard(w http.ResponseWriter, r *http.Request) {
+	url := a.cfg.URL
+	if url == "" {
+		url = fmt.Sprintf("http://%s/", r.Host)
+	}
+	card := map[string]interface{}{
+		"name":               a.cfg.Name,
+		"description":        a.cfg.Description,
+		"url":                url,
+		"version":            "1.0.0",
+		"protocolVersion":    "0.3.0",
+		"capabilities":       map[string]interface{}{"streaming": false, "pushNotifications": false},
+		"defaultInputModes":  []string{"text"},
+		"defaultOutputModes": []string{"text"},
+		"skills":             a.cfg.Skills,
+	}
+	writeJSON(w, card)
+}
+
+// rpcRequest is a JSON-RPC 2.0 request.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+func (a *Agent) serveRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "A2A JSON-RPC requests are POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeError(w, nil, codeParseError, "failed to read request")
+		return
+	}
+	var req rpcRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, nil, codeParseError, "invalid JSON")
+		return
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		writeError(w, req.ID, codeInvalidRequest, "not a JSON-RPC 2.0 request")
+		return
+	}
+
+	switch req.Method {
+	case "message/send":
+		var params struct {
+			Message struct {
+				MessageID string                   `json:"messageId"`
+				ContextID string                   `json:"contextId"`
+				Parts     []map[string]interface{} `json:"parts"`
+			} `json:"message"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params.Message.Parts) == 0 {
+			writeError(w, req.ID, codeInvalidParams, "params.message must be a message with parts")
+			return
+		}
+		if a.cfg.Delay > 0 {
+			select {
+			case <-time.After(a.cfg.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		writeResult(w, req.ID, a.reply(params.Message.ContextID, params.Message.Parts))
+
+	case "tasks/get", "tasks/cancel":
+		var params struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.ID == "" {
+			writeError(w, req.ID, codeInvalidParams, "params.id is required")
+			return
+		}
+		a.mu.Lock()
+		task, ok := a.tasks[params.ID]
+		a.mu.Unlock()
+		switch {
+		case !ok:
+			writeError(w, req.ID, codeTaskNotFound, "task not found")
+		case req.Method == "tasks/cancel":
+			// Mock tasks complete as soon as they are created
+			writeError(w, req.ID, codeNotCancelable, "task has already completed")
+		default:
+			writeResult(w, req.ID, task)
+		}
+
+	default:
+		writeError(w, req.ID, codeMethodNotFound, fmt.Sprintf("method '%s' not found", req.Method))
+	}
+}
+
+// reply creates the completed task answering a message.
+func (a *Agent) reply(contextID string, parts []map[string]interface{}) map[string]interface{} {
+	text := a.cfg.Response
+	if a.cfg.Mode == ModeEcho {
+		var texts []string
+		for _, part := range parts {
+			if t, ok := part["text"].(string); ok {
+				texts = append(texts, t)
+			}
+		}
+		text = strings.Join(texts, "\n")
+	}
+	if contextID == "" {
+		contextID = newID()
+	}
+
+	taskID := newID()
+	task := map[string]interface{}{
+		"kind":      "task",
+		"id":        taskID,
+		"contextId": contextID,
+		"status": map[string]interface{}{
+			"state":     "completed",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"message": map[string]interface{}{
+				"kind":      "message",
+				"role":      "agent",
+				"messageId": newID(),
+				"taskId":    taskID,
+				"contextId": contextID,
+				"parts":     []interface{}{map[string]interface{}{"kind": "text", "text": text}},
+			},
+		},
+		"artifacts": []interface{}{map[string]interface{}{
+			"artifactId": newID(),
+			"parts":      []interface{}{map[string]interface{}{"kind": "text", "text": text}},
+		}},
+	}
+
+	a.mu.Lock()
+	a.tasks[taskID] = task
+	a.order = append(a.order, taskID)
+	if len(a.order) > maxTasks {
+		delete(a.tasks, a.order[0])
+		a.order = a.order[1:]
+	}
+	a.mu.Unlock()
+	return task
+}
+
+func writeResult(w http.ResponseWriter, id interface{}, result interface{}) {
+	writeJSON(w, map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result})
+}
+
+func writeError(w http.ResponseWriter, id interface{}, code int, message string) {
+	writeJSON(w, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   map[string]interface{}{"code": code, "message": message},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,257 @@
+// Package params parses and coerces MCP tool call arguments.
+//
+// Clients differ in how they encode arguments: numbers always arrive as
+// float64, and some clients send booleans and numbers as strings. Args
+// accepts all of these, applies defaults, and collects argument errors so a
+// handler can report them together with one uniform message.
+package params
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Args wraps the arguments of a tool call.
+type Args struct {
+	raw  map[string]interface{}
+	errs []string
+}
+
+// From returns the arguments of a tool call request.
+func From(req mcp.CallToolRequest) *Args {
+	return New(req.Params.Arguments)
+}
+
+// New wraps a raw argument map.
+func New(raw map[string]interface{}) *Args {
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+	return &Args{raw: raw}
+}
+
+// Has reports whether the argument was provided with a non-empty value.
+func (a *Args) Has(name string) bool {
+	v, ok := a.raw[name]
+	if !ok || v == nil {
+		return false
+	}
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s) != ""
+	}
+	return true
+}
+
+// String returns a string argument, or "" if it is absent. Numbers and
+// booleans are formatted as strings.
+func (a *Args) String(name string) string {
+	return a.StringDefault(name, "")
+}
+
+// StringDefault returns a string argument, or def if it is absent or empty.
+func (a *Args) StringDefault(name, def string) string {
+	if !a.Has(name) {
+		return def
+	}
+
+	switch v := a.raw[name].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		a.fail("%s must be a string", name)
+		return def
+	}
+}
+
+// RequiredString returns a string argument, recording an error if it is
+// absent or empty.
+func (a *Args) RequiredString(name string) string {
+	if !a.Has(name) {
+		a.fail("%s is required", name)
+		return ""
+	}
+	return a.String(name)
+}
+
+// Bool returns a boolean argument, or def if it is absent. The strings
+// "true"/"false", "yes"/"no", "1"/"0" and the numbers 1 and 0 are accepted.
+func (a *Args) Bool(name string, def bool) bool {
+	if !a.Has(name) {
+		return def
+	}
+
+	switch v := a.raw[name].(type) {
+	case bool:
+		return v
+	case float64:
+		if v == 0 || v == 1 {
+			return v == 1
+		}
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "1":
+			return true
+		case "false", "no", "0":
+			return false
+		}
+	}
+
+	a.fail("%s must be a boolean", name)
+	return def
+}
+
+// Int returns an integer argument, or def if it is absent. Whole float64
+// values and numeric strings are accepted.
+func (a *Args) Int(name string, def int) int {
+	if !a.Has(name) {
+		return def
+	}
+
+	switch v := a.raw[name].(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= math.MaxInt32 {
+			return int(v)
+		}
+	case int:
+		return v
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n
+		}
+	}
+
+	a.fail("%s must be an integer", name)
+	return def
+}
+
+// Float returns a numeric argument, or def if it is absent. Numeric strings
+// are accepted.
+func (a *Args) Float(name string, def float64) float64 {
+	if !a.Has(name) {
+		return def
+	}
+
+	switch v := a.raw[name].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f
+		}
+	}
+
+	a.fail("%s must be a number", name)
+	return def
+}
+
+// IntRange returns an integer argument that must lie within [lo, hi].
+func (a *Args) IntRange(name string, def, lo, hi int) int {
+	n := a.Int(name, def)
+	if a.Has(name) && (n < lo || n > hi) {
+		a.fail("%s must be between %d and %d", name, lo, hi)
+		return def
+	}
+	return n
+}
+
+// Enum returns a string argument that must be one of allowed, or def if it
+// is absent. Matching is case-insensitive; the canonical spelling from
+// allowed is returned.
+func (a *Args) Enum(name, def string, allowed ...string) string {
+	if !a.Has(name) {
+		return def
+	}
+
+	v := a.String(name)
+	for _, option := range allowed {
+		if strings.EqualFold(option, strings.TrimSpace(v)) {
+			return option
+		}
+	}
+
+	a.fail("%s must be one of: %s (got '%s')", name, strings.Join(allowed, ", "), v)
+	return def
+}
+
+// RequiredEnum returns a string argument that must be one of allowed,
+// recording an error if it is absent.
+func (a *Args) RequiredEnum(name string, allowed ...string) string {
+	if !a.Has(name) {
+		a.fail("%s is required (one of: %s)", name, strings.Join(allowed, ", "))
+		return ""
+	}
+	return a.Enum(name, "", allowed...)
+}
+
+// Duration returns a positive duration argument such as "10m", or def if it
+// is absent.
+func (a *Args) Duration(name string, def time.Duration) time.Duration {
+	if !a.Has(name) {
+		return def
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(a.String(name)))
+	if err != nil || d <= 0 {
+		a.fail("%s must be a positive duration like '10m' (got '%s')", name, a.String(name))
+		return def
+	}
+	return d
+}
+
+// StringList returns a list argument given either as an array of strings or
+// as a comma-separated string. Empty items are dropped.
+func (a *Args) StringList(name string) []string {
+	if !a.Has(name) {
+		return nil
+	}
+
+	var items []string
+	switch v := a.raw[name].(type) {
+	case string:
+		items = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				a.fail("%s must be a list of strings", name)
+				return nil
+			}
+			items = append(items, s)
+		}
+	default:
+		a.fail("%s must be a list of strings", name)
+		return nil
+	}
+
+	var result []string
+	for _, item := range items {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// Err returns all argument errors recorded so far, or nil.
+func (a *Args) Err() error {
+	if len(a.errs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(a.errs, "; "))
+}
+
+func (a *Args) fail(format string, args ...interface{}) {
+	a.errs = append(a.errs, fmt.Sprintf(format, args...))
+}
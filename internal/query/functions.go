@@ -0,0 +1,429 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// function is a built-in function. arity is the number of arguments, or
+// the minimum number when variadic.
+type function struct {
+	arity    int
+	variadic bool
+	call     func(args []interface{}) (interface{}, error)
+}
+
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"abs":         {1, false, numeric(math.Abs)},
+		"avg":         {1, false, fnAvg},
+		"ceil":        {1, false, numeric(math.Ceil)},
+		"contains":    {2, false, fnContains},
+		"ends_with":   {2, false, stringPredicate(strings.HasSuffix)},
+		"floor":       {1, false, numeric(math.Floor)},
+		"join":        {2, false, fnJoin},
+		"keys":        {1, false, fnKeys},
+		"length":      {1, false, fnLength},
+		"map":         {2, false, fnMap},
+		"max":         {1, false, extreme(1)},
+		"max_by":      {2, false, extremeBy(1)},
+		"merge":       {1, true, fnMerge},
+		"min":         {1, false, extreme(-1)},
+		"min_by":      {2, false, extremeBy(-1)},
+		"not_null":    {1, true, fnNotNull},
+		"reverse":     {1, false, fnReverse},
+		"sort":        {1, false, fnSort},
+		"sort_by":     {2, false, fnSortBy},
+		"starts_with": {2, false, stringPredicate(strings.HasPrefix)},
+		"sum":         {1, false, fnSum},
+		"to_array":    {1, false, fnToArray},
+		"to_number":   {1, false, fnToNumber},
+		"to_string":   {1, false, fnToString},
+		"type":        {1, false, func(args []interface{}) (interface{}, error) { return typeOf(args[0]), nil }},
+		"values":      {1, false, fnValues},
+	}
+}
+
+// call calls the named function.
+func call(name string, args []interface{}) (interface{}, error) {
+	fn, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s()", name)
+	}
+	if len(args) < fn.arity || (!fn.variadic && len(args) > fn.arity) {
+		return nil, fmt.Errorf("%s() takes %d argument(s), got %d", name, fn.arity, len(args))
+	}
+	result, err := fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %w", name, err)
+	}
+	return result, nil
+}
+
+// typeOf returns the JMESPath type of a value.
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case expRef:
+		return "expref"
+	}
+	return "unknown"
+}
+
+func invalidType(v interface{}, want string) error {
+	return fmt.Errorf("expected %s, got %s", want, typeOf(v))
+}
+
+func numeric(f func(float64) float64) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		n, ok := args[0].(float64)
+		if !ok {
+			return nil, invalidType(args[0], "number")
+		}
+		return f(n), nil
+	}
+}
+
+func stringPredicate(f func(s, affix string) bool) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, ok1 := args[0].(string)
+		affix, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("expected two strings, got %s and %s", typeOf(args[0]), typeOf(args[1]))
+		}
+		return f(s, affix), nil
+	}
+}
+
+// numbers returns v as a list of numbers.
+func numbers(v interface{}) ([]float64, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, invalidType(v, "array of numbers")
+	}
+	result := make([]float64, len(list))
+	for i, e := range list {
+		n, ok := e.(float64)
+		if !ok {
+			return nil, invalidType(e, "number")
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
+func fnAvg(args []interface{}) (interface{}, error) {
+	nums, err := numbers(args[0])
+	if err != nil || len(nums) == 0 {
+		return nil, err
+	}
+	sum := 0.0
+	for _, n := range nums {
+		sum += n
+	}
+	return sum / float64(len(nums)), nil
+}
+
+func fnSum(args []interface{}) (interface{}, error) {
+	nums, err := numbers(args[0])
+	if err != nil {
+		return nil, err
+	}
+	sum := 0.0
+	for _, n := range nums {
+		sum += n
+	}
+	return sum, nil
+}
+
+func fnContains(args []interface{}) (interface{}, error) {
+	switch subject := args[0].(type) {
+	case string:
+		search, ok := args[1].(string)
+		return ok && strings.Contains(subject, search), nil
+	case []interface{}:
+		for _, e := range subject {
+			if compare(tEQ, e, args[1]) == true {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return nil, invalidType(args[0], "array or string")
+}
+
+func fnJoin(args []interface{}) (interface{}, error) {
+	sep, ok := args[0].(string)
+	if !ok {
+		return nil, invalidType(args[0], "string separator")
+	}
+	list, ok := args[1].([]interface{})
+	if !ok {
+		return nil, invalidType(args[1], "array of strings")
+	}
+	parts := make([]string, len(list))
+	for i, e := range list {
+		s, ok := e.(string)
+		if !ok {
+			return nil, invalidType(e, "string")
+		}
+		parts[i] = s
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func fnKeys(args []interface{}) (interface{}, error) {
+	m, ok := args[0].(map[string]interface{})
+	if !ok {
+		return nil, invalidType(args[0], "object")
+	}
+	keys := []interface{}{}
+	for _, k := range sortedKeys(m) {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func fnValues(args []interface{}) (interface{}, error) {
+	m, ok := args[0].(map[string]interface{})
+	if !ok {
+		return nil, invalidType(args[0], "object")
+	}
+	values := []interface{}{}
+	for _, k := range sortedKeys(m) {
+		values = append(values, m[k])
+	}
+	return values, nil
+}
+
+func fnLength(args []interface{}) (interface{}, error) {
+	switch t := args[0].(type) {
+	case string:
+		return float64(len([]rune(t))), nil
+	case []interface{}:
+		return float64(len(t)), nil
+	case map[string]interface{}:
+		return float64(len(t)), nil
+	}
+	return nil, invalidType(args[0], "string, array or object")
+}
+
+func fnMap(args []interface{}) (interface{}, error) {
+	ref, ok := args[0].(expRef)
+	if !ok {
+		return nil, invalidType(args[0], "expression reference (&expr)")
+	}
+	list, ok := args[1].([]interface{})
+	if !ok {
+		return nil, invalidType(args[1], "array")
+	}
+	result := make([]interface{}, len(list))
+	for i, e := range list {
+		v, err := eval(ref.node, e)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+func fnMerge(args []interface{}) (interface{}, error) {
+	merged := map[string]interface{}{}
+	for _, arg := range args {
+		m, ok := arg.(map[string]interface{})
+		if !ok {
+			return nil, invalidType(arg, "object")
+		}
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged, nil
+}
+
+func fnNotNull(args []interface{}) (interface{}, error) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+func fnReverse(args []interface{}) (interface{}, error) {
+	switch t := args[0].(type) {
+	case string:
+		runes := []rune(t)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	case []interface{}:
+		reversed := make([]interface{}, len(t))
+		for i, e := range t {
+			reversed[len(t)-1-i] = e
+		}
+		return reversed, nil
+	}
+	return nil, invalidType(args[0], "array or string")
+}
+
+// sortKeys checks that keys are all numbers or all strings, as sorting
+// requires.
+func sortKeys(keys []interface{}) error {
+	for _, k := range keys {
+		if typeOf(k) != typeOf(keys[0]) {
+			return fmt.Errorf("cannot order %s and %s", typeOf(keys[0]), typeOf(k))
+		}
+		if _, ok := k.(float64); !ok {
+			if _, ok := k.(string); !ok {
+				return invalidType(k, "number or string")
+			}
+		}
+	}
+	return nil
+}
+
+// less orders two sort keys of the same type.
+func less(a, b interface{}) bool {
+	if x, ok := a.(float64); ok {
+		return x < b.(float64)
+	}
+	return a.(string) < b.(string)
+}
+
+func fnSort(args []interface{}) (interface{}, error) {
+	list, ok := args[0].([]interface{})
+	if !ok {
+		return nil, invalidType(args[0], "array")
+	}
+	if err := sortKeys(list); err != nil {
+		return nil, err
+	}
+	sorted := append([]interface{}{}, list...)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted, nil
+}
+
+// keysBy evaluates an expression reference against each element of a list.
+func keysBy(listArg, refArg interface{}) ([]interface{}, []interface{}, error) {
+	list, ok := listArg.([]interface{})
+	if !ok {
+		return nil, nil, invalidType(listArg, "array")
+	}
+	ref, ok := refArg.(expRef)
+	if !ok {
+		return nil, nil, invalidType(refArg, "expression reference (&expr)")
+	}
+	keys := make([]interface{}, len(list))
+	for i, e := range list {
+		k, err := eval(ref.node, e)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys[i] = k
+	}
+	return list, keys, sortKeys(keys)
+}
+
+func fnSortBy(args []interface{}) (interface{}, error) {
+	list, keys, err := keysBy(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, len(list))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return less(keys[order[i]], keys[order[j]]) })
+	sorted := make([]interface{}, len(list))
+	for i, o := range order {
+		sorted[i] = list[o]
+	}
+	return sorted, nil
+}
+
+// extreme returns max (sign 1) or min (sign -1) of a list.
+func extreme(sign int) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		list, ok := args[0].([]interface{})
+		if !ok {
+			return nil, invalidType(args[0], "array")
+		}
+		if err := sortKeys(list); err != nil || len(list) == 0 {
+			return nil, err
+		}
+		best := list[0]
+		for _, e := range list[1:] {
+			if (sign > 0 && less(best, e)) || (sign < 0 && less(e, best)) {
+				best = e
+			}
+		}
+		return best, nil
+	}
+}
+
+// extremeBy returns max_by (sign 1) or min_by (sign -1) of a list.
+func extremeBy(sign int) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		list, keys, err := keysBy(args[0], args[1])
+		if err != nil || len(list) == 0 {
+			return nil, err
+		}
+		best := 0
+		for i := 1; i < len(list); i++ {
+			if (sign > 0 && less(keys[best], keys[i])) || (sign < 0 && less(keys[i], keys[best])) {
+				best = i
+			}
+		}
+		return list[best], nil
+	}
+}
+
+func fnToArray(args []interface{}) (interface{}, error) {
+	if list, ok := args[0].([]interface{}); ok {
+		return list, nil
+	}
+	return []interface{}{args[0]}, nil
+}
+
+func fnToNumber(args []interface{}) (interface{}, error) {
+	switch t := args[0].(type) {
+	case float64:
+		return t, nil
+	case string:
+		if n, err := strconv.ParseFloat(t, 64); err == nil {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func fnToString(args []interface{}) (interface{}, error) {
+	if s, ok := args[0].(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,206 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type tokenType int
+
+const (
+	tEOF tokenType = iota
+	tUnquoted
+	tQuoted
+	tRawString
+	tLiteral
+	tNumber
+	tDot
+	tStar
+	tFilter
+	tFlatten
+	tLbracket
+	tRbracket
+	tLbrace
+	tRbrace
+	tLparen
+	tRparen
+	tComma
+	tColon
+	tPipe
+	tOr
+	tAnd
+	tNot
+	tEQ
+	tNE
+	tLT
+	tLTE
+	tGT
+	tGTE
+	tCurrent
+	tExpref
+)
+
+// bindingPowers are the left binding powers of the tokens, as in the
+// JMESPath reference implementation.
+var bindingPowers = map[tokenType]int{
+	tPipe:     1,
+	tOr:       2,
+	tAnd:      3,
+	tEQ:       5,
+	tNE:       5,
+	tLT:       5,
+	tLTE:      5,
+	tGT:       5,
+	tGTE:      5,
+	tFlatten:  9,
+	tStar:     20,
+	tFilter:   21,
+	tDot:      40,
+	tNot:      45,
+	tLbrace:   50,
+	tLbracket: 55,
+	tLparen:   60,
+}
+
+var tokenNames = map[tokenType]string{
+	tEOF: "end of expression", tUnquoted: "identifier", tQuoted: "quoted identifier",
+	tRawString: "raw string", tLiteral: "literal", tNumber: "number", tDot: "'.'",
+	tStar: "'*'", tFilter: "'[?'", tFlatten: "'[]'", tLbracket: "'['", tRbracket: "']'",
+	tLbrace: "'{'", tRbrace: "'}'", tLparen: "'('", tRparen: "')'", tComma: "','",
+	tColon: "':'", tPipe: "'|'", tOr: "'||'", tAnd: "'&&'", tNot: "'!'", tEQ: "'=='",
+	tNE: "'!='", tLT: "'<'", tLTE: "'<='", tGT: "'>'", tGTE: "'>='", tCurrent: "'@'",
+	tExpref: "'&'",
+}
+
+func (t tokenType) String() string { return tokenNames[t] }
+
+type token struct {
+	typ   tokenType
+	value string
+	pos   int
+}
+
+// singleChars are the tokens of one character that never start a longer
+// token.
+var singleChars = map[byte]tokenType{
+	'.': tDot, '*': tStar, ']': tRbracket, '{': tLbrace, '}': tRbrace,
+	'(': tLparen, ')': tRparen, ',': tComma, ':': tColon, '@': tCurrent,
+}
+
+// lex splits an expression into tokens, ending with tEOF.
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		start := i
+		emit := func(typ tokenType, value string, width int) {
+			tokens = append(tokens, token{typ: typ, value: value, pos: start})
+			i += width
+		}
+		// next reports whether the character after c is want
+		next := func(want byte) bool { return i+1 < len(expr) && expr[i+1] == want }
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c):
+			end := i + 1
+			for end < len(expr) && isIdentChar(expr[end]) {
+				end++
+			}
+			emit(tUnquoted, expr[i:end], end-i)
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(expr) && expr[end] >= '0' && expr[end] <= '9' {
+				end++
+			}
+			if expr[i:end] == "-" {
+				return nil, fmt.Errorf("invalid number at position %d", start)
+			}
+			emit(tNumber, expr[i:end], end-i)
+		case c == '"':
+			end, err := closing(expr, i, '"')
+			if err != nil {
+				return nil, err
+			}
+			var name string
+			if err := json.Unmarshal([]byte(expr[i:end+1]), &name); err != nil {
+				return nil, fmt.Errorf("invalid quoted identifier at position %d: %v", start, err)
+			}
+			emit(tQuoted, name, end+1-i)
+		case c == '\'':
+			end, err := closing(expr, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			emit(tRawString, strings.ReplaceAll(expr[i+1:end], `\'`, `'`), end+1-i)
+		case c == '`':
+			end, err := closing(expr, i, '`')
+			if err != nil {
+				return nil, err
+			}
+			emit(tLiteral, strings.ReplaceAll(expr[i+1:end], "\\`", "`"), end+1-i)
+		case c == '[' && next('?'):
+			emit(tFilter, "[?", 2)
+		case c == '[' && next(']'):
+			emit(tFlatten, "[]", 2)
+		case c == '[':
+			emit(tLbracket, "[", 1)
+		case c == '|' && next('|'):
+			emit(tOr, "||", 2)
+		case c == '|':
+			emit(tPipe, "|", 1)
+		case c == '&' && next('&'):
+			emit(tAnd, "&&", 2)
+		case c == '&':
+			emit(tExpref, "&", 1)
+		case c == '!' && next('='):
+			emit(tNE, "!=", 2)
+		case c == '!':
+			emit(tNot, "!", 1)
+		case c == '=' && next('='):
+			emit(tEQ, "==", 2)
+		case c == '<' && next('='):
+			emit(tLTE, "<=", 2)
+		case c == '<':
+			emit(tLT, "<", 1)
+		case c == '>' && next('='):
+			emit(tGTE, ">=", 2)
+		case c == '>':
+			emit(tGT, ">", 1)
+		default:
+			typ, ok := singleChars[c]
+			if !ok {
+				if c == '=' {
+					return nil, fmt.Errorf("unexpected '=' at position %d: use '==' to compare", start)
+				}
+				return nil, fmt.Errorf("unexpected character '%c' at position %d", c, start)
+			}
+			emit(typ, string(c), 1)
+		}
+	}
+	return append(tokens, token{typ: tEOF, pos: len(expr)}), nil
+}
+
+// closing returns the index of the quote closing the one at start, skipping
+// quotes escaped with a backslash.
+func closing(expr string, start int, quote byte) (int, error) {
+	for i := start + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			i++
+		case quote:
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated %c at position %d", quote, start)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,412 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type nodeKind int
+
+const (
+	nCurrent nodeKind = iota
+	nField
+	nSubexpression
+	nIndex
+	nSlice
+	nProjection
+	nValueProjection
+	nFilterProjection
+	nFlatten
+	nPipe
+	nOr
+	nAnd
+	nNot
+	nComparator
+	nMultiSelectList
+	nMultiSelectHash
+	nLiteral
+	nFunction
+	nExpRef
+)
+
+// node is a node of a parsed expression. value holds the field name, index,
+// slice bounds, literal, comparator or function name, depending on kind.
+type node struct {
+	kind     nodeKind
+	value    interface{}
+	keys     []string
+	children []*node
+}
+
+var current = &node{kind: nCurrent}
+
+type parser struct {
+	expr   string
+	tokens []token
+	i      int
+}
+
+// parse parses a JMESPath expression.
+func parse(expr string) (*node, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{expr: expr, tokens: tokens}
+	n, err := p.expression(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.current() != tEOF {
+		return nil, p.unexpected()
+	}
+	return n, nil
+}
+
+func (p *parser) lookahead(n int) tokenType {
+	if p.i+n >= len(p.tokens) {
+		return tEOF
+	}
+	return p.tokens[p.i+n].typ
+}
+
+func (p *parser) current() tokenType { return p.lookahead(0) }
+
+func (p *parser) advance() { p.i++ }
+
+func (p *parser) match(t tokenType) error {
+	if p.current() != t {
+		return fmt.Errorf("expected %s at position %d, found %s", t, p.tokens[p.i].pos, p.current())
+	}
+	p.advance()
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	tok := p.tokens[p.i]
+	return fmt.Errorf("unexpected %s at position %d", tok.typ, tok.pos)
+}
+
+// expression parses the expression starting at the current token, binding
+// operators stronger than bindingPower.
+func (p *parser) expression(bindingPower int) (*node, error) {
+	tok := p.tokens[p.i]
+	p.advance()
+	left, err := p.nud(tok)
+	if err != nil {
+		return nil, err
+	}
+	for bindingPower < bindingPowers[p.current()] {
+		tok := p.tokens[p.i]
+		p.advance()
+		if left, err = p.led(tok, left); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+// nud parses a token that starts an expression.
+func (p *parser) nud(tok token) (*node, error) {
+	switch tok.typ {
+	case tLiteral:
+		var v interface{}
+		if err := json.Unmarshal([]byte(tok.value), &v); err != nil {
+			return nil, fmt.Errorf("invalid literal at position %d: %v", tok.pos, err)
+		}
+		return &node{kind: nLiteral, value: v}, nil
+	case tRawString:
+		return &node{kind: nLiteral, value: tok.value}, nil
+	case tUnquoted:
+		return &node{kind: nField, value: tok.value}, nil
+	case tQuoted:
+		if p.current() == tLparen {
+			return nil, fmt.Errorf("quoted identifier at position %d cannot be a function name", tok.pos)
+		}
+		return &node{kind: nField, value: tok.value}, nil
+	case tStar:
+		right := current
+		if p.current() != tRbracket {
+			var err error
+			if right, err = p.projectionRHS(bindingPowers[tStar]); err != nil {
+				return nil, err
+			}
+		}
+		return &node{kind: nValueProjection, children: []*node{current, right}}, nil
+	case tFilter:
+		return p.filter(current)
+	case tLbrace:
+		return p.multiSelectHash()
+	case tFlatten:
+		right, err := p.projectionRHS(bindingPowers[tFlatten])
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nProjection, children: []*node{{kind: nFlatten, children: []*node{current}}, right}}, nil
+	case tLbracket:
+		switch {
+		case p.current() == tNumber || p.current() == tColon:
+			right, err := p.indexExpression()
+			if err != nil {
+				return nil, err
+			}
+			return p.projectIfSlice(current, right)
+		case p.current() == tStar && p.lookahead(1) == tRbracket:
+			p.advance()
+			p.advance()
+			right, err := p.projectionRHS(bindingPowers[tStar])
+			if err != nil {
+				return nil, err
+			}
+			return &node{kind: nProjection, children: []*node{current, right}}, nil
+		}
+		return p.multiSelectList()
+	case tCurrent:
+		return current, nil
+	case tExpref:
+		expr, err := p.expression(bindingPowers[tExpref])
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nExpRef, children: []*node{expr}}, nil
+	case tNot:
+		expr, err := p.expression(bindingPowers[tNot])
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nNot, children: []*node{expr}}, nil
+	case tLparen:
+		expr, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.match(tRparen)
+	}
+	p.i--
+	return nil, p.unexpected()
+}
+
+// led parses a token that continues the expression left.
+func (p *parser) led(tok token, left *node) (*node, error) {
+	switch tok.typ {
+	case tDot:
+		if p.current() != tStar {
+			right, err := p.dotRHS(bindingPowers[tDot])
+			if err != nil {
+				return nil, err
+			}
+			return &node{kind: nSubexpression, children: []*node{left, right}}, nil
+		}
+		p.advance()
+		right, err := p.projectionRHS(bindingPowers[tDot])
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nValueProjection, children: []*node{left, right}}, nil
+	case tPipe, tOr, tAnd:
+		right, err := p.expression(bindingPowers[tok.typ])
+		if err != nil {
+			return nil, err
+		}
+		kind := map[tokenType]nodeKind{tPipe: nPipe, tOr: nOr, tAnd: nAnd}[tok.typ]
+		return &node{kind: kind, children: []*node{left, right}}, nil
+	case tLparen:
+		if left.kind != nField {
+			return nil, fmt.Errorf("unexpected '(' at position %d: only functions can be called", tok.pos)
+		}
+		var args []*node
+		for p.current() != tRparen {
+			arg, err := p.expression(0)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.current() == tComma {
+				p.advance()
+			} else if p.current() != tRparen {
+				return nil, p.unexpected()
+			}
+		}
+		p.advance()
+		return &node{kind: nFunction, value: left.value, children: args}, nil
+	case tFilter:
+		return p.filter(left)
+	case tFlatten:
+		right, err := p.projectionRHS(bindingPowers[tFlatten])
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nProjection, children: []*node{{kind: nFlatten, children: []*node{left}}, right}}, nil
+	case tEQ, tNE, tLT, tLTE, tGT, tGTE:
+		right, err := p.expression(bindingPowers[tok.typ])
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nComparator, value: tok.typ, children: []*node{left, right}}, nil
+	case tLbracket:
+		if p.current() == tNumber || p.current() == tColon {
+			right, err := p.indexExpression()
+			if err != nil {
+				return nil, err
+			}
+			return p.projectIfSlice(left, right)
+		}
+		if err := p.match(tStar); err != nil {
+			return nil, err
+		}
+		if err := p.match(tRbracket); err != nil {
+			return nil, err
+		}
+		right, err := p.projectionRHS(bindingPowers[tStar])
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nProjection, children: []*node{left, right}}, nil
+	}
+	p.i--
+	return nil, p.unexpected()
+}
+
+// indexExpression parses an index or slice after '['.
+func (p *parser) indexExpression() (*node, error) {
+	if p.lookahead(1) == tColon || p.current() == tColon {
+		return p.slice()
+	}
+	index, err := strconv.Atoi(p.tokens[p.i].value)
+	if err != nil {
+		return nil, err
+	}
+	p.advance()
+	return &node{kind: nIndex, value: index}, p.match(tRbracket)
+}
+
+// slice parses [start:stop:step], each part optional.
+func (p *parser) slice() (*node, error) {
+	var parts [3]*int
+	for part := 0; part < 3 && p.current() != tRbracket; part++ {
+		if p.current() == tNumber {
+			v, err := strconv.Atoi(p.tokens[p.i].value)
+			if err != nil {
+				return nil, err
+			}
+			parts[part] = &v
+			p.advance()
+		}
+		if p.current() == tColon && part < 2 {
+			p.advance()
+		} else if p.current() != tRbracket {
+			return nil, p.unexpected()
+		}
+	}
+	return &node{kind: nSlice, value: parts}, p.match(tRbracket)
+}
+
+// projectIfSlice makes a slice a projection, as it produces a list.
+func (p *parser) projectIfSlice(left, right *node) (*node, error) {
+	indexed := &node{kind: nSubexpression, children: []*node{left, right}}
+	if right.kind != nSlice {
+		return indexed, nil
+	}
+	rhs, err := p.projectionRHS(bindingPowers[tStar])
+	if err != nil {
+		return nil, err
+	}
+	return &node{kind: nProjection, children: []*node{indexed, rhs}}, nil
+}
+
+// filter parses a filter projection after '[?'.
+func (p *parser) filter(left *node) (*node, error) {
+	condition, err := p.expression(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.match(tRbracket); err != nil {
+		return nil, err
+	}
+	right := current
+	if p.current() != tFlatten {
+		if right, err = p.projectionRHS(bindingPowers[tFilter]); err != nil {
+			return nil, err
+		}
+	}
+	return &node{kind: nFilterProjection, children: []*node{left, right, condition}}, nil
+}
+
+// projectionRHS parses what a projection applies to each element.
+func (p *parser) projectionRHS(bindingPower int) (*node, error) {
+	switch {
+	case bindingPowers[p.current()] < 10:
+		return current, nil
+	case p.current() == tLbracket, p.current() == tFilter:
+		return p.expression(bindingPower)
+	case p.current() == tDot:
+		p.advance()
+		return p.dotRHS(bindingPower)
+	}
+	return nil, p.unexpected()
+}
+
+// dotRHS parses what follows a '.'.
+func (p *parser) dotRHS(bindingPower int) (*node, error) {
+	switch p.current() {
+	case tUnquoted, tQuoted, tStar:
+		return p.expression(bindingPower)
+	case tLbracket:
+		p.advance()
+		return p.multiSelectList()
+	case tLbrace:
+		p.advance()
+		return p.multiSelectHash()
+	}
+	return nil, p.unexpected()
+}
+
+// multiSelectList parses [expr, ...] after '['.
+func (p *parser) multiSelectList() (*node, error) {
+	var items []*node
+	for {
+		item, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.current() == tRbracket {
+			break
+		}
+		if err := p.match(tComma); err != nil {
+			return nil, err
+		}
+	}
+	p.advance()
+	return &node{kind: nMultiSelectList, children: items}, nil
+}
+
+// multiSelectHash parses {key: expr, ...} after '{'.
+func (p *parser) multiSelectHash() (*node, error) {
+	n := &node{kind: nMultiSelectHash}
+	for {
+		key := p.tokens[p.i]
+		if key.typ != tUnquoted && key.typ != tQuoted {
+			return nil, fmt.Errorf("expected a key at position %d, found %s", key.pos, key.typ)
+		}
+		p.advance()
+		if err := p.match(tColon); err != nil {
+			return nil, err
+		}
+		value, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		n.keys = append(n.keys, key.value)
+		n.children = append(n.children, value)
+		if p.current() == tRbrace {
+			break
+		}
+		if err := p.match(tComma); err != nil {
+			return nil, err
+		}
+	}
+	p.advance()
+	return n, nil
+}
@@ -0,0 +1,384 @@
+// Package query evaluates JMESPath expressions (https://jmespath.org) over
+// JSON documents, such as Kubernetes objects read as unstructured data. It
+// implements the JMESPath specification: sub-expressions, indexes and
+// slices, list, object and filter projections, flattening, pipes,
+// multi-selects, literals, comparisons and boolean operators, and the
+// built-in functions.
+package query
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Expression is a compiled JMESPath expression. It is safe for concurrent
+// use.
+type Expression struct {
+	source string
+	root   *node
+}
+
+// Compile parses a JMESPath expression.
+func Compile(expr string) (*Expression, error) {
+	root, err := parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+	return &Expression{source: expr, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string { return e.source }
+
+// Search evaluates the expression against data: JSON values as decoded by
+// encoding/json, where integers of any size are accepted too. Numbers in
+// the result are float64.
+func (e *Expression) Search(data interface{}) (interface{}, error) {
+	return eval(e.root, normalize(data))
+}
+
+// expRef is the value of an expression reference (&expr), passed to
+// functions such as sort_by.
+type expRef struct{ node *node }
+
+func eval(n *node, value interface{}) (interface{}, error) {
+	switch n.kind {
+	case nCurrent:
+		return value, nil
+	case nLiteral:
+		return n.value, nil
+	case nField:
+		if m, ok := value.(map[string]interface{}); ok {
+			return m[n.value.(string)], nil
+		}
+		return nil, nil
+	case nSubexpression:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		return eval(n.children[1], left)
+	case nIndex:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		index := n.value.(int)
+		if index < 0 {
+			index += len(list)
+		}
+		if index < 0 || index >= len(list) {
+			return nil, nil
+		}
+		return list[index], nil
+	case nSlice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		return slice(list, n.value.([3]*int))
+	case nProjection:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := left.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		return project(list, n.children[1])
+	case nValueProjection:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := left.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		values := make([]interface{}, 0, len(m))
+		for _, key := range sortedKeys(m) {
+			values = append(values, m[key])
+		}
+		return project(values, n.children[1])
+	case nFilterProjection:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := left.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		var matching []interface{}
+		for _, element := range list {
+			keep, err := eval(n.children[2], element)
+			if err != nil {
+				return nil, err
+			}
+			if truthy(keep) {
+				matching = append(matching, element)
+			}
+		}
+		return project(matching, n.children[1])
+	case nFlatten:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := left.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		flat := []interface{}{}
+		for _, element := range list {
+			if inner, ok := element.([]interface{}); ok {
+				flat = append(flat, inner...)
+			} else {
+				flat = append(flat, element)
+			}
+		}
+		return flat, nil
+	case nPipe:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		return eval(n.children[1], left)
+	case nOr, nAnd:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(left) == (n.kind == nOr) {
+			return left, nil
+		}
+		return eval(n.children[1], value)
+	case nNot:
+		v, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		return !truthy(v), nil
+	case nComparator:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		right, err := eval(n.children[1], value)
+		if err != nil {
+			return nil, err
+		}
+		return compare(n.value.(tokenType), left, right), nil
+	case nMultiSelectList:
+		if value == nil {
+			return nil, nil
+		}
+		result := make([]interface{}, len(n.children))
+		for i, child := range n.children {
+			v, err := eval(child, value)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = v
+		}
+		return result, nil
+	case nMultiSelectHash:
+		if value == nil {
+			return nil, nil
+		}
+		result := make(map[string]interface{}, len(n.children))
+		for i, child := range n.children {
+			v, err := eval(child, value)
+			if err != nil {
+				return nil, err
+			}
+			result[n.keys[i]] = v
+		}
+		return result, nil
+	case nExpRef:
+		return expRef{n.children[0]}, nil
+	case nFunction:
+		args := make([]interface{}, len(n.children))
+		for i, child := range n.children {
+			v, err := eval(child, value)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		return call(n.value.(string), args)
+	}
+	return nil, fmt.Errorf("unknown expression node %d", n.kind)
+}
+
+// project evaluates right against every element, dropping null results.
+func project(list []interface{}, right *node) (interface{}, error) {
+	result := []interface{}{}
+	for _, element := range list {
+		v, err := eval(right, element)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// slice implements [start:stop:step] as Python does.
+func slice(list []interface{}, parts [3]*int) (interface{}, error) {
+	step := 1
+	if parts[2] != nil {
+		step = *parts[2]
+	}
+	if step == 0 {
+		return nil, fmt.Errorf("slice step cannot be 0")
+	}
+	length := len(list)
+	bound := func(v *int, forward, backward int) int {
+		if v == nil {
+			if step < 0 {
+				return backward
+			}
+			return forward
+		}
+		i := *v
+		switch {
+		case i < 0:
+			i += length
+			if i < 0 {
+				i = 0
+				if step < 0 {
+					i = -1
+				}
+			}
+		case i >= length:
+			i = length
+			if step < 0 {
+				i = length - 1
+			}
+		}
+		return i
+	}
+	start := bound(parts[0], 0, length-1)
+	stop := bound(parts[1], length, -1)
+
+	result := []interface{}{}
+	for i := start; (step > 0 && i < stop) || (step < 0 && i > stop); i += step {
+		result = append(result, list[i])
+	}
+	return result, nil
+}
+
+// compare applies a comparator. Equality compares any values; ordering is
+// only defined for numbers, and is null otherwise.
+func compare(op tokenType, left, right interface{}) interface{} {
+	switch op {
+	case tEQ:
+		return reflect.DeepEqual(left, right)
+	case tNE:
+		return !reflect.DeepEqual(left, right)
+	}
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil
+	}
+	switch op {
+	case tLT:
+		return l < r
+	case tLTE:
+		return l <= r
+	case tGT:
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+// Truthy reports whether a search result counts as true: anything but
+// false, null, and empty strings, lists and objects.
+func Truthy(v interface{}) bool {
+	return truthy(v)
+}
+
+// truthy reports whether a value is true in JMESPath: everything but null,
+// false and empty strings, lists and objects.
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		return t != ""
+	case []interface{}:
+		return len(t) > 0
+	case map[string]interface{}:
+		return len(t) > 0
+	}
+	return true
+}
+
+// normalize converts every number to float64 and typed maps and slices to
+// their generic forms, so that comparisons behave as in JSON.
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[k] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, e := range t {
+			l[i] = normalize(e)
+		}
+		return l
+	case []map[string]interface{}:
+		l := make([]interface{}, len(t))
+		for i, e := range t {
+			l[i] = normalize(e)
+		}
+		return l
+	case map[string]string:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[k] = e
+		}
+		return m
+	case []string:
+		l := make([]interface{}, len(t))
+		for i, e := range t {
+			l[i] = e
+		}
+		return l
+	case int:
+		return float64(t)
+	case int32:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case float32:
+		return float64(t)
+	case float64:
+		if math.IsNaN(t) {
+			return nil
+		}
+	}
+	return v
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
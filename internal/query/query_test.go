@@ -0,0 +1,324 @@
+package query
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// The cases follow the JMESPath compliance suite
+// (https://github.com/jmespath/jmespath.test): each group evaluates
+// expressions against one document.
+type complianceCase struct {
+	expr   string
+	result string // JSON; ignored when err is set
+	err    bool
+}
+
+func runCompliance(t *testing.T, document string, cases []complianceCase) {
+	t.Helper()
+	var data interface{}
+	if err := json.Unmarshal([]byte(document), &data); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	for _, tc := range cases {
+		expr, err := Compile(tc.expr)
+		if err == nil {
+			var got interface{}
+			got, err = expr.Search(data)
+			if err == nil && !tc.err {
+				var want interface{}
+				if jerr := json.Unmarshal([]byte(tc.result), &want); jerr != nil {
+					t.Fatalf("%s: invalid result %s: %v", tc.expr, tc.result, jerr)
+				}
+				if !reflect.DeepEqual(got, want) {
+					gotJSON, _ := json.Marshal(got)
+					t.Errorf("%s = %s, want %s", tc.expr, gotJSON, tc.result)
+				}
+				continue
+			}
+		}
+		if tc.err && err == nil {
+			t.Errorf("%s: expected an error", tc.expr)
+		}
+		if !tc.err && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.expr, err)
+		}
+	}
+}
+
+func TestBasic(t *testing.T) {
+	runCompliance(t, `{"foo": {"bar": {"baz": "correct"}}, "a-b": 1, "": "empty"}`, []complianceCase{
+		{expr: `foo`, result: `{"bar": {"baz": "correct"}}`},
+		{expr: `foo.bar`, result: `{"baz": "correct"}`},
+		{expr: `foo.bar.baz`, result: `"correct"`},
+		{expr: `foo.bar.baz.bad`, result: `null`},
+		{expr: `foo.bad`, result: `null`},
+		{expr: `bad.morebad.morebad`, result: `null`},
+		{expr: `"a-b"`, result: `1`},
+		{expr: `foo."bar".baz`, result: `"correct"`},
+		{expr: `@.foo.bar.baz`, result: `"correct"`},
+		{expr: `foo.`, err: true},
+		{expr: `.foo`, err: true},
+		{expr: `foo..bar`, err: true},
+		{expr: `a-b`, err: true},
+	})
+	runCompliance(t, `["one", "two", "three"]`, []complianceCase{
+		{expr: `one`, result: `null`},
+		{expr: `[1]`, result: `"two"`},
+		{expr: `[-1]`, result: `"three"`},
+		{expr: `[3]`, result: `null`},
+		{expr: `[-4]`, result: `null`},
+	})
+}
+
+func TestLiterals(t *testing.T) {
+	runCompliance(t, `{"foo": [{"name": "a"}, {"name": "b"}]}`, []complianceCase{
+		{expr: "`\"foo\"`", result: `"foo"`},
+		{expr: "`[1, 2]`", result: `[1, 2]`},
+		{expr: "`{\"a\": true}`", result: `{"a": true}`},
+		{expr: "`null`", result: `null`},
+		{expr: `'raw'`, result: `"raw"`},
+		{expr: `'it\'s'`, result: `"it's"`},
+		{expr: "`foo`", err: true},
+		{expr: "`\"unterminated`", err: true},
+	})
+}
+
+func TestSlices(t *testing.T) {
+	runCompliance(t, `{"foo": [0, 1, 2, 3, 4, 5, 6, 7, 8, 9], "bar": {"baz": 1}}`, []complianceCase{
+		{expr: `foo[0:10:1]`, result: `[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]`},
+		{expr: `foo[0:10]`, result: `[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]`},
+		{expr: `foo[0:10:]`, result: `[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]`},
+		{expr: `foo[0::1]`, result: `[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]`},
+		{expr: `foo[::1]`, result: `[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]`},
+		{expr: `foo[1:9]`, result: `[1, 2, 3, 4, 5, 6, 7, 8]`},
+		{expr: `foo[0:10:2]`, result: `[0, 2, 4, 6, 8]`},
+		{expr: `foo[5:]`, result: `[5, 6, 7, 8, 9]`},
+		{expr: `foo[5::2]`, result: `[5, 7, 9]`},
+		{expr: `foo[::2]`, result: `[0, 2, 4, 6, 8]`},
+		{expr: `foo[::-1]`, result: `[9, 8, 7, 6, 5, 4, 3, 2, 1, 0]`},
+		{expr: `foo[1::2]`, result: `[1, 3, 5, 7, 9]`},
+		{expr: `foo[10:0:-1]`, result: `[9, 8, 7, 6, 5, 4, 3, 2, 1]`},
+		{expr: `foo[10:5:-1]`, result: `[9, 8, 7, 6]`},
+		{expr: `foo[8:2:-2]`, result: `[8, 6, 4]`},
+		{expr: `foo[0:20]`, result: `[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]`},
+		{expr: `foo[10:-20:-1]`, result: `[9, 8, 7, 6, 5, 4, 3, 2, 1, 0]`},
+		{expr: `foo[-4:-1]`, result: `[6, 7, 8]`},
+		{expr: `foo[:-5:-1]`, result: `[9, 8, 7, 6]`},
+		{expr: `foo[8:2:0]`, err: true},
+		{expr: `foo[8:2:0:1]`, err: true},
+		{expr: `foo[8:2&]`, err: true},
+		{expr: `foo[2:a:3]`, err: true},
+		{expr: `bar[0:1]`, result: `null`},
+	})
+	runCompliance(t, `{"foo": [{"a": 1}, {"a": 2}, {"a": 3}], "bar": [{"a": {"b": 1}}, {"a": {"b": 2}}, {"a": {"b": 3}}]}`, []complianceCase{
+		{expr: `foo[:2].a`, result: `[1, 2]`},
+		{expr: `foo[:2].b`, result: `[]`},
+		{expr: `foo[:2].a.b`, result: `[]`},
+		{expr: `bar[::-1].a.b`, result: `[3, 2, 1]`},
+		{expr: `bar[:2].a.b`, result: `[1, 2]`},
+	})
+}
+
+func TestProjections(t *testing.T) {
+	runCompliance(t, `{"foo": {"bar": [{"baz": 1}, {"baz": 2}, {"qux": 3}]}, "obj": {"a": {"v": 1}, "b": {"v": 2}, "c": {"w": 3}}}`, []complianceCase{
+		{expr: `foo.bar[*].baz`, result: `[1, 2]`},
+		{expr: `foo.bar[*]`, result: `[{"baz": 1}, {"baz": 2}, {"qux": 3}]`},
+		{expr: `foo.bar[*].baz[0]`, result: `[]`},
+		{expr: `foo.bar[*].missing`, result: `[]`},
+		{expr: `obj.*.v`, result: `[1, 2]`},
+		{expr: `foo.*.baz`, result: `[]`},
+		{expr: `foo.*[*].baz`, result: `[[1, 2]]`},
+		{expr: `missing[*]`, result: `null`},
+		{expr: `missing.*`, result: `null`},
+	})
+	runCompliance(t, `{"foo": [[1, 2], [3, [4, 5]], 6], "bar": [[{"a": 1}], [{"a": 2}, {"a": 3}]]}`, []complianceCase{
+		{expr: `foo[]`, result: `[1, 2, 3, [4, 5], 6]`},
+		{expr: `foo[][]`, result: `[1, 2, 3, 4, 5, 6]`},
+		{expr: `bar[].a`, result: `[1, 2, 3]`},
+		{expr: `bar[*].a`, result: `[]`},
+		{expr: `bar[*][*].a`, result: `[[1], [2, 3]]`},
+		{expr: `bar[][0]`, result: `[]`},
+		{expr: `foo[0][]`, result: `[1, 2]`},
+	})
+}
+
+func TestFilters(t *testing.T) {
+	runCompliance(t, `{"foo": [{"name": "a", "age": 20, "on": true}, {"name": "b", "age": 30, "on": false}, {"name": "c", "age": 40}], "num": 30}`, []complianceCase{
+		{expr: `foo[?name == 'a'].age`, result: `[20]`},
+		{expr: `foo[?age > ` + "`25`" + `].name`, result: `["b", "c"]`},
+		{expr: `foo[?age >= ` + "`30`" + `].name`, result: `["b", "c"]`},
+		{expr: `foo[?age < ` + "`30`" + `].name`, result: `["a"]`},
+		{expr: `foo[?age <= ` + "`30`" + `].name`, result: `["a", "b"]`},
+		{expr: `foo[?age != ` + "`30`" + `].name`, result: `["a", "c"]`},
+		{expr: `foo[?age == $.num]`, err: true},
+		{expr: `foo[?on].name`, result: `["a"]`},
+		{expr: `foo[?!on].name`, result: `["b", "c"]`},
+		{expr: `foo[?on || age > ` + "`35`" + `].name`, result: `["a", "c"]`},
+		{expr: `foo[?age > ` + "`10`" + ` && age < ` + "`35`" + `].name`, result: `["a", "b"]`},
+		{expr: `foo[?(on || age > ` + "`35`" + `) && name != 'c'].name`, result: `["a"]`},
+		{expr: `foo[?name > 'a']`, result: `[]`},
+		{expr: `foo[?missing == null].name`, result: `["a", "b", "c"]`},
+		{expr: `foo[?age == ` + "`20.0`" + `].name`, result: `["a"]`},
+		{expr: `foo[?name == 'a']`, result: `[{"name": "a", "age": 20, "on": true}]`},
+		{expr: `foo[?name == 'a'] | [0].age`, result: `20`},
+		{expr: `foo[?name ==]`, err: true},
+	})
+}
+
+func TestMultiSelectAndBooleans(t *testing.T) {
+	runCompliance(t, `{"foo": {"bar": 1, "baz": 2, "qux": [3, 4]}, "t": true, "f": false, "e": "", "z": 0}`, []complianceCase{
+		{expr: `foo.[bar, baz]`, result: `[1, 2]`},
+		{expr: `foo.{a: bar, b: qux[0]}`, result: `{"a": 1, "b": 3}`},
+		{expr: `[foo.bar, missing]`, result: `[1, null]`},
+		{expr: `missing.[a, b]`, result: `null`},
+		{expr: `missing.{a: b}`, result: `null`},
+		{expr: `t || f`, result: `true`},
+		{expr: `f || t`, result: `true`},
+		{expr: `e || z`, result: `0`},
+		{expr: `missing || foo.bar`, result: `1`},
+		{expr: `t && foo.bar`, result: `1`},
+		{expr: `e && t`, result: `""`},
+		{expr: `!f`, result: `true`},
+		{expr: `!z`, result: `false`},
+		{expr: `!e`, result: `true`},
+		{expr: `foo.{a: bar,}`, err: true},
+		{expr: `foo.[bar,]`, err: true},
+		{expr: `foo.{bar}`, err: true},
+	})
+}
+
+func TestPipes(t *testing.T) {
+	runCompliance(t, `{"foo": {"bar": {"baz": "subkey"}, "other": {"baz": "subkey2"}}, "list": [{"a": [1, 2]}, {"a": [3]}]}`, []complianceCase{
+		{expr: `foo.*.baz | [0]`, result: `"subkey"`},
+		{expr: `foo.*.baz | [1]`, result: `"subkey2"`},
+		{expr: `foo.*.baz | [2]`, result: `null`},
+		{expr: `foo.bar | baz`, result: `"subkey"`},
+		{expr: `list[*].a | [0]`, result: `[1, 2]`},
+		{expr: `list[*].a[0]`, result: `[1, 3]`},
+		{expr: `list[].a[] | length(@)`, result: `3`},
+		{expr: `foo | bar | baz`, result: `"subkey"`},
+		{expr: `foo |`, err: true},
+		{expr: `| foo`, err: true},
+	})
+}
+
+func TestFunctions(t *testing.T) {
+	runCompliance(t, `{
+		"foo": -1, "zero": 0, "numbers": [-1, 3, 4, 5], "decimals": [1.01, 1.2, -1.5],
+		"strings": ["a", "b", "c"], "empty_list": [], "str": "Str", "array": ["a", "b"],
+		"objects": {"foo": "bar", "bar": "baz"}, "people": [{"age": 20, "name": "b"}, {"age": 10, "name": "a"}, {"age": 30, "name": "c"}],
+		"nested": [[1, 2], [3]], "null_key": null
+	}`, []complianceCase{
+		{expr: `abs(foo)`, result: `1`},
+		{expr: `abs(str)`, err: true},
+		{expr: `abs(` + "`-24`" + `)`, result: `24`},
+		{expr: `avg(numbers)`, result: `2.75`},
+		{expr: `avg(empty_list)`, result: `null`},
+		{expr: `avg(strings)`, err: true},
+		{expr: `ceil(` + "`1.2`" + `)`, result: `2`},
+		{expr: `floor(decimals[0])`, result: `1`},
+		{expr: `contains('abc', 'a')`, result: `true`},
+		{expr: `contains('abc', 'd')`, result: `false`},
+		{expr: `contains(strings, 'a')`, result: `true`},
+		{expr: `contains(` + "`false`" + `, 'd')`, err: true},
+		{expr: `ends_with(str, 'r')`, result: `true`},
+		{expr: `starts_with(str, 'S')`, result: `true`},
+		{expr: `starts_with(str, 's')`, result: `false`},
+		{expr: `join(', ', strings)`, result: `"a, b, c"`},
+		{expr: `join(', ', numbers)`, err: true},
+		{expr: `keys(objects)`, result: `["bar", "foo"]`},
+		{expr: `keys(foo)`, err: true},
+		{expr: `values(objects)`, result: `["baz", "bar"]`},
+		{expr: `length('abc')`, result: `3`},
+		{expr: `length(strings)`, result: `3`},
+		{expr: `length(objects)`, result: `2`},
+		{expr: `length(foo)`, err: true},
+		{expr: `map(&[0], nested)`, result: `[1, 3]`},
+		{expr: `map(&age, people)`, result: `[20, 10, 30]`},
+		{expr: `max(numbers)`, result: `5`},
+		{expr: `min(numbers)`, result: `-1`},
+		{expr: `max(strings)`, result: `"c"`},
+		{expr: `max(empty_list)`, result: `null`},
+		{expr: `max(objects)`, err: true},
+		{expr: `max_by(people, &age).name`, result: `"c"`},
+		{expr: `min_by(people, &age).name`, result: `"a"`},
+		{expr: `max_by(people, &name).age`, result: `30`},
+		{expr: `max_by(people, &missing)`, err: true},
+		{expr: `merge(` + "`{\"a\": 1}`" + `, ` + "`{\"a\": 2, \"b\": 3}`" + `)`, result: `{"a": 2, "b": 3}`},
+		{expr: `not_null(null_key, missing, str)`, result: `"Str"`},
+		{expr: `not_null(null_key, missing)`, result: `null`},
+		{expr: `not_null()`, err: true},
+		{expr: `reverse(array)`, result: `["b", "a"]`},
+		{expr: `reverse(str)`, result: `"rtS"`},
+		{expr: `sort(numbers)`, result: `[-1, 3, 4, 5]`},
+		{expr: `sort(` + "`[\"b\", \"a\"]`" + `)`, result: `["a", "b"]`},
+		{expr: `sort(` + "`[1, \"a\"]`" + `)`, err: true},
+		{expr: `sort_by(people, &age)[*].name`, result: `["a", "b", "c"]`},
+		{expr: `sort_by(people, &name)[*].age`, result: `[10, 20, 30]`},
+		{expr: `sum(numbers)`, result: `11`},
+		{expr: `sum(empty_list)`, result: `0`},
+		{expr: `to_array(str)`, result: `["Str"]`},
+		{expr: `to_array(array)`, result: `["a", "b"]`},
+		{expr: `to_number('1.5')`, result: `1.5`},
+		{expr: `to_number('abc')`, result: `null`},
+		{expr: `to_number(foo)`, result: `-1`},
+		{expr: `to_string(numbers)`, result: `"[-1,3,4,5]"`},
+		{expr: `to_string(str)`, result: `"Str"`},
+		{expr: `type(str)`, result: `"string"`},
+		{expr: `type(foo)`, result: `"number"`},
+		{expr: `type(objects)`, result: `"object"`},
+		{expr: `type(array)`, result: `"array"`},
+		{expr: `type(null_key)`, result: `"null"`},
+		{expr: `type(` + "`true`" + `)`, result: `"boolean"`},
+		{expr: `length(people[?age > ` + "`15`" + `])`, result: `2`},
+		{expr: `unknown(foo)`, err: true},
+		{expr: `length()`, err: true},
+		{expr: `length(str, str)`, err: true},
+		{expr: `length(str`, err: true},
+	})
+}
+
+func TestIntegerInput(t *testing.T) {
+	expr, err := Compile(`items[?replicas > ` + "`1`" + `].name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "a", "replicas": int64(1)},
+			map[string]interface{}{"name": "b", "replicas": int64(3)},
+		},
+	}
+	got, err := expr.Search(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{"b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestTruthy(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{nil, false},
+		{false, false},
+		{"", false},
+		{[]interface{}{}, false},
+		{map[string]interface{}{}, false},
+		{true, true},
+		{float64(0), true},
+		{"a", true},
+		{[]interface{}{nil}, true},
+		{map[string]interface{}{"a": nil}, true},
+	}
+	for _, tt := range tests {
+		if got := Truthy(tt.value); got != tt.want {
+			t.Errorf("Truthy(%#v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package revisions records the history of the specs of kagent resources
+// applied through the server so past versions can be compared and restored.
+package revisions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// Live refers to the current state in the cluster rather than a recorded
+// revision.
+const Live = "live"
+
+// ActionObserved is the action of a revision recording a spec the server
+// found in the cluster when it updated the resource, because the resource
+// was created or last changed elsewhere.
+const ActionObserved = "observed"
+
+// Revision is a recorded version of a resource's spec.
+type Revision struct {
+	Number    int                    `json:"number"`
+	Timestamp time.Time              `json:"timestamp"`
+	Action    string                 `json:"action"`
+	Spec      map[string]interface{} `json:"spec"`
+}
+
+// Store persists revisions to a ConfigMap, one data key per resource.
+type Store struct {
+	k8sClient     *kubernetes.Client
+	configMapName string
+	maxRevisions  int
+}
+
+// NewStore creates a revision store backed by the named ConfigMap, keeping at
+// most maxRevisions entries per resource.
+func NewStore(k8sClient *kubernetes.Client, configMapName string, maxRevisions int) *Store {
+	return &Store{
+		k8sClient:     k8sClient,
+		configMapName: configMapName,
+		maxRevisions:  maxRevisions,
+	}
+}
+
+// List returns the recorded revisions of a resource, oldest first.
+func (s *Store) List(ctx context.Context, kind, name string) ([]Revision, error) {
+	data, _, err := s.k8sClient.GetConfigMapData(ctx, s.configMapName)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data[dataKey(kind, name)])
+}
+
+// Record appends spec as a new revision of a resource, trimming the history
+// to the configured maximum. previous is the spec spec replaced, or nil for
+// a created resource; when the history does not end with it, it is recorded
+// first as an ActionObserved revision so that it can be restored. Nothing
+// is recorded if spec is identical to the latest revision.
+func (s *Store) Record(ctx context.Context, kind, name, action string, previous, spec map[string]interface{}) (*Revision, error) {
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "kmeta-agent",
+		"app.kubernetes.io/component":  "revisions",
+	}
+
+	var revision Revision
+	err := s.k8sClient.UpdateConfigMapData(ctx, s.configMapName, labels, func(data map[string]string) error {
+		key := dataKey(kind, name)
+		revisions, err := decode(data[key])
+		if err != nil {
+			return err
+		}
+
+		if previous != nil {
+			revisions, _ = appendRevision(revisions, ActionObserved, previous)
+		}
+		revisions, revision = appendRevision(revisions, action, spec)
+		if s.maxRevisions > 0 && len(revisions) > s.maxRevisions {
+			revisions = revisions[len(revisions)-s.maxRevisions:]
+		}
+
+		encoded, err := json.Marshal(revisions)
+		if err != nil {
+			return fmt.Errorf("failed to encode revisions: %w", err)
+		}
+		data[key] = string(encoded)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// appendRevision appends spec to revisions unless it is identical to the
+// latest one, and returns the revision now holding spec.
+func appendRevision(revisions []Revision, action string, spec map[string]interface{}) ([]Revision, Revision) {
+	number := 1
+	if len(revisions) > 0 {
+		latest := revisions[len(revisions)-1]
+		if reflect.DeepEqual(latest.Spec, spec) {
+			return revisions, latest
+		}
+		number = latest.Number + 1
+	}
+
+	revision := Revision{
+		Number:    number,
+		Timestamp: time.Now().UTC(),
+		Action:    action,
+		Spec:      spec,
+	}
+	return append(revisions, revision), revision
+}
+
+// Resolve finds the revision a reference points to. A reference is a
+// revision number, an RFC 3339 timestamp, or a date (YYYY-MM-DD, meaning the
+// end of that day in UTC); timestamps and dates select the latest revision
+// recorded at or before that time.
+func Resolve(revisions []Revision, ref string) (*Revision, error) {
+	ref = strings.TrimSpace(ref)
+
+	if n, err := strconv.Atoi(ref); err == nil {
+		for i := range revisions {
+			if revisions[i].Number == n {
+				return &revisions[i], nil
+			}
+		}
+		return nil, fmt.Errorf("revision %d not found (it may have been trimmed from the history)", n)
+	}
+
+	at, err := time.Parse(time.RFC3339, ref)
+	if err != nil {
+		day, dayErr := time.Parse("2006-01-02", ref)
+		if dayErr != nil {
+			return nil, fmt.Errorf("invalid revision reference '%s': expected a revision number, an RFC 3339 timestamp, a date (YYYY-MM-DD), or '%s'", ref, Live)
+		}
+		at = day.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	var found *Revision
+	for i := range revisions {
+		if revisions[i].Timestamp.After(at) {
+			break
+		}
+		found = &revisions[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no revision recorded at or before %s", at.Format(time.RFC3339))
+	}
+	return found, nil
+}
+
+// dataKey returns the ConfigMap data key holding a resource's revisions.
+// Agents keep the key they had before other kinds were recorded; other
+// kinds are prefixed with theirs, which cannot clash since resource names
+// never contain underscores.
+func dataKey(kind, name string) string {
+	if kind == "Agent" {
+		return name + ".json"
+	}
+	return kind + "_" + name + ".json"
+}
+
+func decode(raw string) ([]Revision, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var revisions []Revision
+	if err := json.Unmarshal([]byte(raw), &revisions); err != nil {
+		return nil, fmt.Errorf("failed to decode revisions: %w", err)
+	}
+	return revisions, nil
+}
@@ -0,0 +1,187 @@
+// Package sampling lets tools ask an LLM for assistance through MCP sampling,
+// using the connected client's model instead of in-cluster credentials.
+package sampling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Sampling modes selectable through configuration.
+const (
+	ModeClient = "client"
+	ModeOff    = "off"
+)
+
+// ErrUnavailable is returned when no LLM is available for sampling, either
+// because sampling is disabled or because the client does not support it.
+var ErrUnavailable = errors.New("sampling is not available: it is disabled on the server or not supported by the client")
+
+// Request is a single-turn completion request.
+type Request struct {
+	SystemPrompt string
+	Prompt       string
+	MaxTokens    int
+}
+
+// Sampler completes prompts with an LLM.
+type Sampler interface {
+	Sample(ctx context.Context, req Request) (string, error)
+}
+
+// Disabled is a Sampler that always returns ErrUnavailable.
+type Disabled struct{}
+
+// Sample implements Sampler.
+func (Disabled) Sample(ctx context.Context, req Request) (string, error) {
+	return "", ErrUnavailable
+}
+
+// ClientSampler sends sampling/createMessage requests to the connected MCP
+// client and waits for the matching responses. Responses must be passed to
+// HandleMessage by the transport.
+type ClientSampler struct {
+	out io.Writer
+	wmu *sync.Mutex
+
+	supported atomic.Bool
+	nextID    atomic.Int64
+
+	mu      sync.Mutex
+	pending map[string]chan response
+}
+
+type response struct {
+	result *mcp.CreateMessageResult
+	err    error
+}
+
+// idPrefix marks request IDs issued by the sampler so responses can be told
+// apart from client requests.
+const idPrefix = "kmeta-sampling-"
+
+// NewClientSampler creates a sampler writing requests to out. wmu must be the
+// lock guarding all writes to out.
+func NewClientSampler(out io.Writer, wmu *sync.Mutex) *ClientSampler {
+	return &ClientSampler{
+		out:     out,
+		wmu:     wmu,
+		pending: make(map[string]chan response),
+	}
+}
+
+// SetClientCapabilities records whether the client supports sampling.
+func (s *ClientSampler) SetClientCapabilities(caps mcp.ClientCapabilities) {
+	s.supported.Store(caps.Sampling != nil)
+}
+
+// Sample implements Sampler.
+func (s *ClientSampler) Sample(ctx context.Context, req Request) (string, error) {
+	if !s.supported.Load() {
+		return "", ErrUnavailable
+	}
+
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 1024
+	}
+
+	id := fmt.Sprintf("%s%d", idPrefix, s.nextID.Add(1))
+	ch := make(chan response, 1)
+	s.mu.Lock()
+	s.pending[id] = ch
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	message := map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      id,
+		"method":  "sampling/createMessage",
+		"params": map[string]interface{}{
+			"messages": []mcp.SamplingMessage{{
+				Role:    mcp.RoleUser,
+				Content: mcp.TextContent{Type: "text", Text: req.Prompt},
+			}},
+			"systemPrompt": req.SystemPrompt,
+			"maxTokens":    maxTokens,
+		},
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode sampling request: %w", err)
+	}
+
+	s.wmu.Lock()
+	_, err = fmt.Fprintf(s.out, "%s\n", data)
+	s.wmu.Unlock()
+	if err != nil {
+		return "", fmt.Errorf("failed to send sampling request: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case resp := <-ch:
+		if resp.err != nil {
+			return "", resp.err
+		}
+		content, _ := resp.result.Content.(map[string]interface{})
+		if text, ok := content["text"].(string); ok {
+			return text, nil
+		}
+		return "", errors.New("sampling response contained no text")
+	}
+}
+
+// HandleMessage delivers a JSON-RPC response to a pending sampling request.
+// It reports whether the message was consumed; other messages must be passed
+// on to the MCP server.
+func (s *ClientSampler) HandleMessage(line []byte) bool {
+	var msg struct {
+		ID     interface{}     `json:"id"`
+		Method string          `json:"method"`
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(line, &msg); err != nil || msg.Method != "" {
+		return false
+	}
+
+	id, ok := msg.ID.(string)
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	ch, ok := s.pending[id]
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	if msg.Error != nil {
+		ch <- response{err: fmt.Errorf("client rejected sampling request: %s (code %d)", msg.Error.Message, msg.Error.Code)}
+		return true
+	}
+
+	var result mcp.CreateMessageResult
+	if err := json.Unmarshal(msg.Result, &result); err != nil {
+		ch <- response{err: fmt.Errorf("failed to decode sampling response: %w", err)}
+		return true
+	}
+	ch <- response{result: &result}
+	return true
+}
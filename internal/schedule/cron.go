@@ -0,0 +1,193 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros are the shorthands accepted in place of five fields.
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// Cron is a parsed five-field cron expression: minute, hour, day of month,
+// month and day of week. Each field is a set of allowed values.
+type Cron struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	// As in cron, when both day fields are restricted a time matches
+	// either; when one is "*" only the other applies.
+	domAny, dowAny bool
+	// hourAny is set when the hour field is "*": such schedules also run in
+	// the hour repeated when clocks go back, while others run only once.
+	hourAny bool
+}
+
+// ParseCron parses a cron expression such as "0 2 * * *", "*/15 * * * 1-5"
+// or "@daily". Fields accept "*", numbers, ranges, lists and steps; day of
+// week runs from 0 (Sunday) to 6, with 7 also meaning Sunday.
+func ParseCron(expr string) (*Cron, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := cronMacros[spec]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression '%s': expected 5 fields (minute hour day-of-month month day-of-week) or a macro like @daily", expr)
+	}
+
+	c := &Cron{expr: expr}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': minute: %w", expr, err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': hour: %w", expr, err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': day of month: %w", expr, err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': month: %w", expr, err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': day of week: %w", expr, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	c.hourAny = fields[1] == "*"
+	return c, nil
+}
+
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// parseCronField parses one field into a bit set of the values in [lo, hi].
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step '%s'", stepPart)
+			}
+			step = n
+		}
+
+		start, end := lo, hi
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = cronValue(from, lo, hi); err != nil {
+				return 0, err
+			}
+			if end, err = cronValue(to, lo, hi); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("range '%s' is backwards", rangePart)
+			}
+		default:
+			n, err := cronValue(rangePart, lo, hi)
+			if err != nil {
+				return 0, err
+			}
+			start = n
+			if !hasStep {
+				end = n
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func cronValue(s string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a number", s)
+	}
+	if n < lo || n > hi {
+		return 0, fmt.Errorf("%d is outside %d-%d", n, lo, hi)
+	}
+	return n, nil
+}
+
+// Next returns the first minute strictly after t that matches, in t's
+// location. It returns the zero time if nothing matches within five years,
+// e.g. for "0 0 30 2 *". Times skipped when clocks go forward never match,
+// and times repeated when they go back only match once unless the hour
+// field is "*".
+func (c *Cron) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for next.Before(limit) {
+		if c.month&(1<<uint(next.Month())) == 0 {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !c.dayMatches(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if c.hour&(1<<uint(next.Hour())) == 0 {
+			// Step in elapsed time: an hour that occurs twice when clocks
+			// go back is then seen twice, as it happens
+			next = next.Add(time.Duration(60-next.Minute()) * time.Minute)
+			continue
+		}
+		if c.minute&(1<<uint(next.Minute())) == 0 || (!c.hourAny && repeatedWallClock(next)) {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+	return time.Time{}
+}
+
+// repeatedWallClock reports whether t is the second occurrence of its wall
+// clock time, in the hour repeated when clocks go back.
+func repeatedWallClock(t time.Time) bool {
+	_, offset := t.Zone()
+	_, earlierOffset := t.Add(-2 * time.Hour).Zone()
+	shift := earlierOffset - offset
+	if shift <= 0 {
+		return false
+	}
+	first := t.Add(-time.Duration(shift) * time.Second)
+	return first.Hour() == t.Hour() && first.Minute() == t.Minute()
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,260 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseCronRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@every 5m",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/-1 * * * *",
+		"1-2-3 * * * *",
+		"1,,2 * * * *",
+		"a * * * *",
+		"* * * JAN *",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "every minute is strictly after from",
+			spec: "* * * * *",
+			from: time.Date(2026, 3, 4, 10, 15, 30, 0, time.UTC),
+			want: []time.Time{utc(2026, 3, 4, 10, 16), utc(2026, 3, 4, 10, 17)},
+		},
+		{
+			name: "fixed time",
+			spec: "0 2 * * *",
+			from: utc(2026, 3, 4, 2, 0),
+			want: []time.Time{utc(2026, 3, 5, 2, 0), utc(2026, 3, 6, 2, 0)},
+		},
+		{
+			name: "range",
+			spec: "0 9-11 * * *",
+			from: utc(2026, 3, 4, 10, 30),
+			want: []time.Time{utc(2026, 3, 4, 11, 0), utc(2026, 3, 5, 9, 0), utc(2026, 3, 5, 10, 0)},
+		},
+		{
+			name: "step over the whole field",
+			spec: "*/15 * * * *",
+			from: utc(2026, 3, 4, 10, 50),
+			want: []time.Time{utc(2026, 3, 4, 11, 0), utc(2026, 3, 4, 11, 15), utc(2026, 3, 4, 11, 30)},
+		},
+		{
+			name: "step from a start value",
+			spec: "5/20 * * * *",
+			from: utc(2026, 3, 4, 10, 0),
+			want: []time.Time{utc(2026, 3, 4, 10, 5), utc(2026, 3, 4, 10, 25), utc(2026, 3, 4, 10, 45), utc(2026, 3, 4, 11, 5)},
+		},
+		{
+			name: "step over a range",
+			spec: "0 8-18/4 * * *",
+			from: utc(2026, 3, 4, 12, 0),
+			want: []time.Time{utc(2026, 3, 4, 16, 0), utc(2026, 3, 5, 8, 0)},
+		},
+		{
+			name: "list",
+			spec: "0,30 6,18 * * *",
+			from: utc(2026, 3, 4, 6, 0),
+			want: []time.Time{utc(2026, 3, 4, 6, 30), utc(2026, 3, 4, 18, 0), utc(2026, 3, 4, 18, 30), utc(2026, 3, 5, 6, 0)},
+		},
+		{
+			name: "weekdays",
+			spec: "0 9 * * 1-5",
+			from: utc(2026, 3, 6, 9, 0), // Friday
+			want: []time.Time{utc(2026, 3, 9, 9, 0), utc(2026, 3, 10, 9, 0)},
+		},
+		{
+			name: "7 is Sunday",
+			spec: "0 0 * * 7",
+			from: utc(2026, 3, 4, 0, 0),
+			want: []time.Time{utc(2026, 3, 8, 0, 0), utc(2026, 3, 15, 0, 0)},
+		},
+		{
+			name: "both day fields restricted match either",
+			spec: "0 0 13 * 5",
+			from: utc(2026, 2, 1, 0, 0),
+			// Fridays in February 2026, and the 13th (a Friday too)
+			want: []time.Time{utc(2026, 2, 6, 0, 0), utc(2026, 2, 13, 0, 0), utc(2026, 2, 20, 0, 0), utc(2026, 2, 27, 0, 0), utc(2026, 3, 6, 0, 0), utc(2026, 3, 13, 0, 0)},
+		},
+		{
+			name: "day of month alone",
+			spec: "0 0 15 * *",
+			from: utc(2026, 1, 20, 0, 0),
+			want: []time.Time{utc(2026, 2, 15, 0, 0), utc(2026, 3, 15, 0, 0)},
+		},
+		{
+			name: "day 31 skips shorter months",
+			spec: "0 0 31 * *",
+			from: utc(2026, 1, 31, 0, 0),
+			want: []time.Time{utc(2026, 3, 31, 0, 0), utc(2026, 5, 31, 0, 0), utc(2026, 7, 31, 0, 0), utc(2026, 8, 31, 0, 0)},
+		},
+		{
+			name: "month-end rollover into the next year",
+			spec: "30 23 31 12 *",
+			from: utc(2026, 12, 31, 23, 30),
+			want: []time.Time{utc(2027, 12, 31, 23, 30)},
+		},
+		{
+			name: "29 February waits for a leap year",
+			spec: "0 0 29 2 *",
+			from: utc(2026, 3, 1, 0, 0),
+			want: []time.Time{utc(2028, 2, 29, 0, 0), utc(2032, 2, 29, 0, 0)},
+		},
+		{
+			name: "@hourly",
+			spec: "@hourly",
+			from: utc(2026, 3, 4, 23, 59),
+			want: []time.Time{utc(2026, 3, 5, 0, 0), utc(2026, 3, 5, 1, 0)},
+		},
+		{
+			name: "@daily",
+			spec: "@daily",
+			from: utc(2026, 3, 4, 0, 0),
+			want: []time.Time{utc(2026, 3, 5, 0, 0)},
+		},
+		{
+			name: "@weekly",
+			spec: "@weekly",
+			from: utc(2026, 3, 4, 0, 0),
+			want: []time.Time{utc(2026, 3, 8, 0, 0), utc(2026, 3, 15, 0, 0)},
+		},
+		{
+			name: "@monthly",
+			spec: "@monthly",
+			from: utc(2026, 12, 15, 0, 0),
+			want: []time.Time{utc(2027, 1, 1, 0, 0), utc(2027, 2, 1, 0, 0)},
+		},
+		{
+			name: "@yearly",
+			spec: "@yearly",
+			from: utc(2026, 1, 1, 0, 0),
+			want: []time.Time{utc(2027, 1, 1, 0, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.spec, err)
+			}
+			from := tt.from
+			for _, want := range tt.want {
+				got := c.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("Next(%s) = %s, want %s", from, got, want)
+				}
+				from = got
+			}
+		})
+	}
+}
+
+func TestCronNextNeverMatches(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("Next = %s, want the zero time", got)
+	}
+}
+
+func TestCronNextAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, berlin)
+	}
+	// Clocks go forward from 02:00 to 03:00 on 29 March 2026, and back
+	// from 03:00 to 02:00 on 25 October 2026.
+	springGap := local(3, 29, 1, 59).Add(time.Minute)
+	fallFirst := time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC).In(berlin) // 02:30 CEST
+	fallSecond := fallFirst.Add(time.Hour)                                 // 02:30 CET
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "skipped time does not run that day",
+			spec: "30 2 * * *",
+			from: local(3, 28, 2, 30),
+			want: []time.Time{local(3, 30, 2, 30)},
+		},
+		{
+			name: "wall clock time holds after the change",
+			spec: "0 9 * * *",
+			from: local(3, 28, 9, 0),
+			want: []time.Time{local(3, 29, 9, 0), local(3, 30, 9, 0)},
+		},
+		{
+			name: "every minute continues across the gap",
+			spec: "* * * * *",
+			from: local(3, 29, 1, 59),
+			want: []time.Time{springGap},
+		},
+		{
+			name: "repeated time runs once",
+			spec: "30 2 * * *",
+			from: local(10, 24, 2, 30),
+			want: []time.Time{fallFirst, local(10, 26, 2, 30)},
+		},
+		{
+			name: "hourly schedules run in the repeated hour",
+			spec: "30 * * * *",
+			from: local(10, 25, 1, 30),
+			want: []time.Time{fallFirst, fallSecond, local(10, 25, 3, 30)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.spec, err)
+			}
+			from := tt.from
+			for _, want := range tt.want {
+				got := c.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("Next(%s) = %s, want %s", from, got, want)
+				}
+				from = got
+			}
+		})
+	}
+}
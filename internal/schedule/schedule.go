@@ -0,0 +1,378 @@
+// Package schedule runs read-only tools on cron schedules and records their
+// results, so governance checks run continuously instead of on demand.
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// Notification policies.
+const (
+	NotifyNever     = "never"
+	NotifyAlways    = "always"
+	NotifyOnChange  = "on-change"
+	NotifyOnFailure = "on-failure"
+)
+
+// Run statuses.
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// maxStoredResult bounds the result text kept per schedule, so that every
+// schedule's last result fits in one ConfigMap.
+const maxStoredResult = 32 * 1024
+
+// maxNotifiedResult bounds the result text sent with a notification.
+const maxNotifiedResult = 2 * 1024
+
+// Entry is one scheduled tool run.
+type Entry struct {
+	// Name identifies the schedule; it keys its result in the ConfigMap.
+	Name string `json:"name"`
+	// Tool is the tool to run; it must be read-only.
+	Tool string `json:"tool"`
+	// Arguments are passed to the tool as given.
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	// Schedule is a cron expression, e.g. "0 2 * * *" or "@hourly", in UTC.
+	Schedule string `json:"schedule"`
+	// Notify is when the webhook is called: "on-change" (default),
+	// "on-failure", "always" or "never".
+	Notify string `json:"notify,omitempty"`
+
+	cron *Cron
+}
+
+// file is the layout of the schedules file.
+type file struct {
+	Schedules []Entry `json:"schedules"`
+}
+
+// Load reads schedules from a YAML file. allowed reports whether a tool
+// may be scheduled, with the reason when it may not.
+func Load(path string, allowed func(tool string) error) ([]Entry, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	var f file
+	if err := yaml.UnmarshalStrict(raw, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse schedules %s: %w", path, err)
+	}
+
+	seen := map[string]bool{}
+	for i := range f.Schedules {
+		e := &f.Schedules[i]
+		if errs := validation.IsConfigMapKey(e.Name); e.Name == "" || len(errs) > 0 {
+			return nil, fmt.Errorf("schedule %d: invalid name '%s': %s", i+1, e.Name, strings.Join(errs, "; "))
+		}
+		if seen[e.Name] {
+			return nil, fmt.Errorf("schedule '%s' is defined twice", e.Name)
+		}
+		seen[e.Name] = true
+		if e.Tool == "" {
+			return nil, fmt.Errorf("schedule '%s': tool is required", e.Name)
+		}
+		if err := allowed(e.Tool); err != nil {
+			return nil, fmt.Errorf("schedule '%s': %w", e.Name, err)
+		}
+		if e.cron, err = ParseCron(e.Schedule); err != nil {
+			return nil, fmt.Errorf("schedule '%s': %w", e.Name, err)
+		}
+		switch e.Notify {
+		case "":
+			e.Notify = NotifyOnChange
+		case NotifyNever, NotifyAlways, NotifyOnChange, NotifyOnFailure:
+		default:
+			return nil, fmt.Errorf("schedule '%s': invalid notify '%s' (expected always, on-change, on-failure or never)", e.Name, e.Notify)
+		}
+	}
+	return f.Schedules, nil
+}
+
+// Next returns the next time the entry runs after t.
+func (e *Entry) Next(t time.Time) time.Time {
+	return e.cron.Next(t.UTC())
+}
+
+// Result is the outcome of the last run of a schedule.
+type Result struct {
+	Schedule   string    `json:"schedule"`
+	Tool       string    `json:"tool"`
+	Status     string    `json:"status"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	// Changed reports whether the result differs from the previous run.
+	Changed bool `json:"changed"`
+	// Digest identifies the result text, to detect changes between runs.
+	Digest    string `json:"digest"`
+	Result    string `json:"result"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// CallFunc runs a tool and returns its text output; failed reports a tool
+// error result.
+type CallFunc func(ctx context.Context, tool string, arguments map[string]interface{}) (output string, failed bool, err error)
+
+// Runner runs schedules and records their results in a ConfigMap, or in
+// memory when no ConfigMap is named.
+type Runner struct {
+	entries       []Entry
+	call          CallFunc
+	k8sClient     *kubernetes.Client
+	configMapName string
+	webhookURL    string
+	timeout       time.Duration
+	httpClient    *http.Client
+
+	// mu serializes writes of the results
+	mu     sync.Mutex
+	memory map[string]string
+}
+
+// NewRunner creates a runner for entries. Results are stored in the named
+// ConfigMap, or kept in memory when configMapName is empty, and, when webhookURL is set, posted there as notifications.
+// Each run is cancelled after timeout.
+func NewRunner(entries []Entry, call CallFunc, k8sClient *kubernetes.Client, configMapName, webhookURL string, timeout time.Duration) *Runner {
+	return &Runner{
+		entries:       entries,
+		call:          call,
+		k8sClient:     k8sClient,
+		configMapName: configMapName,
+		webhookURL:    webhookURL,
+		timeout:       timeout,
+		httpClient:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Entries returns the configured schedules.
+func (r *Runner) Entries() []Entry {
+	return r.entries
+}
+
+// Run starts each schedule on its own loop until ctx is cancelled. A run
+// that is still going when the next one is due delays it rather than
+// overlapping.
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := range r.entries {
+		wg.Add(1)
+		go func(e *Entry) {
+			defer wg.Done()
+			for {
+				next := e.Next(time.Now())
+				if next.IsZero() {
+					fmt.Fprintf(os.Stderr, "Schedule %s never runs: %s matches no date\n", e.Name, e.Schedule)
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Until(next)):
+				}
+				if _, err := r.RunNow(ctx, e.Name); err != nil {
+					fmt.Fprintf(os.Stderr, "Scheduled run of %s failed: %v\n", e.Name, err)
+				}
+			}
+		}(&r.entries[i])
+	}
+	wg.Wait()
+}
+
+// RunNow runs the named schedule immediately, records the result and sends
+// the notification its policy asks for.
+func (r *Runner) RunNow(ctx context.Context, name string) (*Result, error) {
+	var entry *Entry
+	for i := range r.entries {
+		if r.entries[i].Name == name {
+			entry = &r.entries[i]
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("schedule '%s' not found", name)
+	}
+
+	result := Result{Schedule: entry.Name, Tool: entry.Tool, StartedAt: time.Now().UTC()}
+	runCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	output, failed, err := r.call(runCtx, entry.Tool, entry.Arguments)
+	cancel()
+	result.FinishedAt = time.Now().UTC()
+	result.Status = StatusSucceeded
+	if err != nil {
+		output = err.Error()
+	}
+	if err != nil || failed {
+		result.Status = StatusFailed
+	}
+
+	sum := sha256.Sum256([]byte(result.Status + "\n" + output))
+	result.Digest = hex.EncodeToString(sum[:8])
+	result.Result, result.Truncated = truncate(output, maxStoredResult)
+
+	if err := r.record(ctx, &result); err != nil {
+		return &result, err
+	}
+
+	if r.shouldNotify(entry, result) {
+		if err := r.notify(ctx, result); err != nil {
+			return &result, fmt.Errorf("failed to send notification: %w", err)
+		}
+	}
+	return &result, nil
+}
+
+// Results returns the last recorded result of each schedule, by name.
+func (r *Runner) Results(ctx context.Context) (map[string]Result, error) {
+	data, err := r.storedResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := map[string]Result{}
+	for key, raw := range data {
+		var result Result
+		if err := json.Unmarshal([]byte(raw), &result); err != nil {
+			continue
+		}
+		results[strings.TrimSuffix(key, ".json")] = result
+	}
+	return results, nil
+}
+
+// record stores result as the schedule's last result, first marking
+// whether it differs from the one it replaces.
+func (r *Runner) record(ctx context.Context, result *Result) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	change := func(data map[string]string) error {
+		key := result.Schedule + ".json"
+
+		var previous Result
+		result.Changed = json.Unmarshal([]byte(data[key]), &previous) != nil || previous.Digest != result.Digest
+
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to encode result: %w", err)
+		}
+		data[key] = string(encoded)
+
+		// Drop results of schedules that were removed from the file
+		known := map[string]bool{}
+		for _, e := range r.entries {
+			known[e.Name+".json"] = true
+		}
+		for k := range data {
+			if !known[k] {
+				delete(data, k)
+			}
+		}
+		return nil
+	}
+
+	if r.configMapName == "" {
+		if r.memory == nil {
+			r.memory = map[string]string{}
+		}
+		return change(r.memory)
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "kmeta-agent",
+		"app.kubernetes.io/component":  "schedule",
+	}
+	return r.k8sClient.UpdateConfigMapData(ctx, r.configMapName, labels, change)
+}
+
+// storedResults returns the stored results, keyed as record keys them.
+func (r *Runner) storedResults(ctx context.Context) (map[string]string, error) {
+	if r.configMapName != "" {
+		data, _, err := r.k8sClient.GetConfigMapData(ctx, r.configMapName)
+		return data, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data := make(map[string]string, len(r.memory))
+	for k, v := range r.memory {
+		data[k] = v
+	}
+	return data, nil
+}
+
+func (r *Runner) shouldNotify(e *Entry, result Result) bool {
+	if r.webhookURL == "" {
+		return false
+	}
+	switch e.Notify {
+	case NotifyAlways:
+		return true
+	case NotifyOnFailure:
+		return result.Status == StatusFailed
+	case NotifyOnChange:
+		return result.Changed
+	}
+	return false
+}
+
+// notify posts a result to the webhook. The "text" field makes the payload
+// readable by Slack and Teams incoming webhooks as is.
+func (r *Runner) notify(ctx context.Context, result Result) error {
+	excerpt, _ := truncate(result.Result, maxNotifiedResult)
+	change := "unchanged"
+	if result.Changed {
+		change = "changed"
+	}
+	payload := map[string]interface{}{
+		"text":       fmt.Sprintf("[kmeta-agent] Scheduled %s (%s) %s, result %s:\n%s", result.Schedule, result.Tool, result.Status, change, excerpt),
+		"schedule":   result.Schedule,
+		"tool":       result.Tool,
+		"status":     result.Status,
+		"changed":    result.Changed,
+		"startedAt":  result.StartedAt,
+		"finishedAt": result.FinishedAt,
+		"configMap":  r.configMapName,
+	}
+	body, _ := json.Marshal(payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// truncate shortens s to at most n bytes, on a line boundary when possible.
+func truncate(s string, n int) (string, bool) {
+	if len(s) <= n {
+		return s, false
+	}
+	cut := s[:n]
+	if i := strings.LastIndex(cut, "\n"); i > n/2 {
+		cut = cut[:i]
+	}
+	return cut + "\n... (truncated)", true
+}
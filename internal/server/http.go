@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Transports the server can be served over.
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+)
+
+// shutdownTimeout bounds how long in-flight HTTP requests may finish after
+// the process is signalled.
+const shutdownTimeout = 10 * time.Second
+
+// maxMessageSize bounds the client messages read by the server itself.
+const maxMessageSize = 10 << 20
+
+// ListenAndServe serves the MCP server over HTTP with Server-Sent Events on
+// addr until the process is signalled. Clients connect to /sse and post
+// messages to /message. baseURL is the externally reachable URL advertised
+// for the message endpoint; when empty, a relative path is advertised.
+//
+// When multi-tenancy is enabled, every request must carry a tenant's bearer
+// token, and tool calls run as that tenant.
+func (s *Server) ListenAndServe(addr, baseURL string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	// Sampling requests can only be sent over stdio
+	s.transport = TransportHTTP
+
+	mux := http.NewServeMux()
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	sse := server.NewSSEServer(s.mcpServer,
+		server.WithBaseURL(baseURL),
+		server.WithKeepAlive(true),
+		server.WithHTTPServer(httpServer),
+		server.WithSSEContextFunc(s.httpContext),
+	)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/", s.authenticate(s.interceptSubscriptions(sse)))
+
+	errs := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "Serving MCP over HTTP on %s\n", addr)
+		errs <- sse.Start(addr)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	if err := sse.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// authenticate rejects requests without a valid tenant token when
+// multi-tenancy is enabled.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.tenants == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := s.tenants.Bind(r.Context(), r.Header.Get("Authorization")); !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="kmeta-agent"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// interceptSubscriptions handles subscription requests posted to the
+// message endpoint before the SSE server sees them. Successful requests are
+// passed on rewritten, so their reply arrives over the event stream like
+// any other; failures are answered in the POST's response, as the SSE
+// server does for messages it cannot accept.
+func (s *Server) interceptSubscriptions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.URL.Query().Get("sessionId")
+		if r.Method != http.MethodPost || sessionID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+		if err != nil {
+			http.Error(w, "Failed to read message", http.StatusBadRequest)
+			return
+		}
+
+		forward, reply := s.interceptSubscription(s.httpContext(r.Context(), r), sessionID, body)
+		if reply != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write(reply)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(forward))
+		r.ContentLength = int64(len(forward))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// httpContext prepares the context a client message is handled in.
+func (s *Server) httpContext(ctx context.Context, r *http.Request) context.Context {
+	// The message is handled after the POST that delivered it returns, and
+	// the response is sent over the event stream, so the request's
+	// cancellation must not reach the tool call
+	ctx = context.WithoutCancel(ctx)
+
+	if s.tenants != nil {
+		ctx, _ = s.tenants.Bind(ctx, r.Header.Get("Authorization"))
+	}
+	return ctx
+}
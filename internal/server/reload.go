@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/kagent-dev/meta-kagent/internal/config"
+)
+
+// watchRetryInterval is how long to wait before re-establishing a config
+// ConfigMap watch that failed or was closed by the API server.
+const watchRetryInterval = 30 * time.Second
+
+// ReloadConfig re-reads the reloadable settings from the config ConfigMap,
+// falling back to the environment, and applies them to the running server.
+// Client sessions are kept; clients are notified if the tool list changes.
+func (s *Server) ReloadConfig(ctx context.Context) error {
+	current := s.Config()
+
+	data, _, err := s.k8sClient.GetConfigMapData(ctx, current.ConfigMap)
+	if err != nil {
+		return err
+	}
+
+	var ignored []string
+	for key := range data {
+		if !config.IsReloadable(key) {
+			ignored = append(ignored, key)
+		}
+	}
+	if len(ignored) > 0 {
+		sort.Strings(ignored)
+		fmt.Fprintf(os.Stderr, "Ignoring settings in ConfigMap %s that require a restart: %v\n", current.ConfigMap, ignored)
+	}
+
+	s.config.Store(current.Reload(data))
+	s.applyToolEnablement()
+	return nil
+}
+
+// WatchConfig reloads the configuration on SIGHUP and whenever the config
+// ConfigMap changes, until ctx is cancelled.
+func (s *Server) WatchConfig(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	changes := make(chan struct{}, 1)
+	go s.watchConfigMap(ctx, changes)
+
+	for {
+		var trigger string
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			trigger = "SIGHUP"
+		case <-changes:
+			trigger = "ConfigMap change"
+		}
+
+		if err := s.ReloadConfig(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to reload configuration (%s): %v\n", trigger, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Reloaded configuration (%s)\n", trigger)
+	}
+}
+
+// watchConfigMap signals changes whenever the config ConfigMap is created,
+// updated or deleted. The watch is re-established when it ends.
+func (s *Server) watchConfigMap(ctx context.Context, changes chan<- struct{}) {
+	name := s.Config().ConfigMap
+	for {
+		w, err := s.k8sClient.WatchConfigMap(ctx, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to watch configuration: %v\n", err)
+		} else {
+			for range w.ResultChan() {
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			}
+			w.Stop()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryInterval):
+		}
+	}
+}
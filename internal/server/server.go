@@ -2,31 +2,73 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/kagent-dev/meta-kagent/internal/config"
+	"github.com/kagent-dev/meta-kagent/internal/i18n"
 	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/sampling"
+	"github.com/kagent-dev/meta-kagent/internal/tenancy"
 )
 
 // Server wraps the MCP server with kagent-specific functionality.
 type Server struct {
-	mcpServer *server.MCPServer
-	k8sClient *kubernetes.Client
+	mcpServer     *server.MCPServer
+	k8sClient     *kubernetes.Client
+	config        atomic.Pointer[config.Config]
+	writeMu       *sync.Mutex
+	clientSampler *sampling.ClientSampler
+	tenants       *tenancy.Registry
+	catalog       *i18n.Catalog
+	transport     string
+	subs          *subscriptions
+
+	toolsMu  sync.Mutex
+	tools    map[string]server.ServerTool
+	active   map[string]bool
+	reserved map[string]bool
 }
 
 // New creates a new MCP server for the meta-kagent.
-func New(k8sClient *kubernetes.Client) *Server {
-	mcpServer := server.NewMCPServer(
+func New(k8sClient *kubernetes.Client, cfg *config.Config) *Server {
+	s := &Server{
+		k8sClient: k8sClient,
+		writeMu:   &sync.Mutex{},
+		transport: TransportStdio,
+		tools:     map[string]server.ServerTool{},
+		active:    map[string]bool{},
+		reserved:  map[string]bool{},
+	}
+	s.config.Store(cfg)
+
+	// Sampling requests are written to stdout alongside responses. The
+	// sampler is created even when sampling is off so that a reload can
+	// turn it on.
+	s.clientSampler = sampling.NewClientSampler(os.Stdout, s.writeMu)
+
+	hooks := &server.Hooks{}
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, req *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		s.clientSampler.SetClientCapabilities(req.Params.Capabilities)
+	})
+	s.enableSubscriptions(hooks)
+
+	s.mcpServer = server.NewMCPServer(
 		"kmeta-agent-tools",
 		"1.0.0",
 		server.WithResourceCapabilities(true, true),
+		server.WithToolCapabilities(true),
+		server.WithPromptCapabilities(false),
 		server.WithLogging(),
+		server.WithHooks(hooks),
 	)
-
-	return &Server{
-		mcpServer: mcpServer,
-		k8sClient: k8sClient,
-	}
+	return s
 }
 
 // MCPServer returns the underlying MCP server.
@@ -39,7 +81,183 @@ func (s *Server) K8sClient() *kubernetes.Client {
 	return s.k8sClient
 }
 
-// AddTool is a convenience wrapper for adding tools.
+// K8sClientFor returns the Kubernetes client for the session in ctx: the
+// tenant's impersonating client when the session is bound to a tenant, the
+// server's own client otherwise, scoped to the namespace selected with
+// WithNamespace if any.
+func (s *Server) K8sClientFor(ctx context.Context) *kubernetes.Client {
+	client := s.k8sClient
+	if t, ok := tenancy.FromContext(ctx); ok && s.tenants != nil {
+		client = s.tenants.Client(t)
+	}
+	if namespace, ok := NamespaceFromContext(ctx); ok && namespace != client.Namespace() {
+		client = client.InNamespace(namespace)
+	}
+	return client
+}
+
+type namespaceKey struct{}
+
+// WithNamespace returns ctx scoped to namespace, so that K8sClientFor
+// operates there instead of the session's default namespace.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// NamespaceFromContext returns the namespace selected with WithNamespace.
+func NamespaceFromContext(ctx context.Context) (string, bool) {
+	namespace, ok := ctx.Value(namespaceKey{}).(string)
+	return namespace, ok
+}
+
+// SetTenants enables multi-tenancy. Transports that authenticate clients
+// bind each session to a tenant of the registry.
+func (s *Server) SetTenants(r *tenancy.Registry) {
+	s.tenants = r
+}
+
+// Tenants returns the tenant registry, or nil when multi-tenancy is off.
+func (s *Server) Tenants() *tenancy.Registry {
+	return s.tenants
+}
+
+// SetCatalog translates the tools registered from now on, and their
+// results, with the message catalog of the configured locale.
+func (s *Server) SetCatalog(c *i18n.Catalog) {
+	s.catalog = c
+}
+
+// Catalog returns the message catalog, or nil when the server speaks
+// English.
+func (s *Server) Catalog() *i18n.Catalog {
+	return s.catalog
+}
+
+// Config returns the current server configuration. Callers should not
+// hold on to it across requests, since a reload replaces it.
+func (s *Server) Config() *config.Config {
+	return s.config.Load()
+}
+
+// Sampler returns the sampler tools use for LLM assistance. It returns a
+// disabled sampler when sampling is turned off or the transport cannot
+// carry sampling requests.
+func (s *Server) Sampler() sampling.Sampler {
+	if s.Config().SamplingMode != sampling.ModeClient || s.transport != TransportStdio {
+		return sampling.Disabled{}
+	}
+	return s.clientSampler
+}
+
+// AddTool is a convenience wrapper for adding tools. Tools disabled in the
+// configuration are remembered but not offered until a reload enables them.
+// Descriptions and results are translated when a catalog is set.
 func (s *Server) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
-	s.mcpServer.AddTool(tool, handler)
+	if s.catalog != nil {
+		tool = s.catalog.Tool(tool)
+		handler = translated(s.catalog, handler)
+	}
+
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+
+	s.tools[tool.Name] = server.ServerTool{Tool: tool, Handler: handler}
+	if s.Config().ToolEnabled(tool.Name) {
+		s.mcpServer.AddTool(tool, handler)
+		s.active[tool.Name] = true
+	}
+}
+
+// ReserveToolName keeps name for a built-in tool that is not registered,
+// because the server's mode does not offer it or the cluster lacks what it
+// needs, so that no tool pack can take the name.
+func (s *Server) ReserveToolName(name string) {
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+	s.reserved[name] = true
+}
+
+// ToolNameTaken reports whether a tool is registered as name or the name
+// is reserved for one.
+func (s *Server) ToolNameTaken(name string) bool {
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+	_, ok := s.tools[name]
+	return ok || s.reserved[name]
+}
+
+// translated translates the results of handler with catalog.
+func translated(catalog *i18n.Catalog, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, req)
+		catalog.Result(result)
+		return result, err
+	}
+}
+
+// AddResource registers a resource with a fixed URI.
+func (s *Server) AddResource(resource mcp.Resource, handler server.ResourceHandlerFunc) {
+	s.mcpServer.AddResource(resource, handler)
+}
+
+// AddResourceTemplate registers a family of resources whose URIs match an
+// RFC 6570 template; the template's variables are passed to the handler as
+// the request's arguments.
+func (s *Server) AddResourceTemplate(template mcp.ResourceTemplate, handler server.ResourceTemplateHandlerFunc) {
+	s.mcpServer.AddResourceTemplate(template, handler)
+}
+
+// AddPrompt registers a prompt.
+func (s *Server) AddPrompt(prompt mcp.Prompt, handler server.PromptHandlerFunc) {
+	s.mcpServer.AddPrompt(prompt, handler)
+}
+
+// CallTool runs a registered tool directly rather than on behalf of a
+// client session, for background callers such as the scheduler. Tools
+// disabled in the configuration are refused.
+func (s *Server) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	s.toolsMu.Lock()
+	t, ok := s.tools[name]
+	active := s.active[name]
+	s.toolsMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("tool '%s' is not registered", name)
+	}
+	if !active {
+		return nil, fmt.Errorf("tool '%s' is disabled", name)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = arguments
+	return t.Handler(ctx, req)
+}
+
+// applyToolEnablement adds and removes registered tools to match the
+// current configuration. Clients are notified when the tool list changes.
+func (s *Server) applyToolEnablement() {
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+
+	cfg := s.Config()
+	var add []server.ServerTool
+	var remove []string
+	for name, t := range s.tools {
+		enabled := cfg.ToolEnabled(name)
+		switch {
+		case enabled && !s.active[name]:
+			add = append(add, t)
+			s.active[name] = true
+		case !enabled && s.active[name]:
+			remove = append(remove, name)
+			delete(s.active, name)
+		}
+	}
+
+	if len(remove) > 0 {
+		s.mcpServer.DeleteTools(remove...)
+	}
+	if len(add) > 0 {
+		s.mcpServer.AddTools(add...)
+	}
 }
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// inputBuffer is how many client messages may queue while a tool call is in
+// progress, so responses to sampling requests are never stuck behind them.
+const inputBuffer = 256
+
+// lockedWriter serializes writes so server-initiated requests do not
+// interleave with responses.
+type lockedWriter struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// ServeStdio serves the MCP server over stdin and stdout until the input is
+// closed or the process is signalled. Responses to sampling requests are
+// delivered to the sampler and subscription requests handled by the server;
+// every other message goes to the MCP server.
+func (s *Server) ServeStdio() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	stdio := server.NewStdioServer(s.mcpServer)
+	stdio.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+
+	stdout := &lockedWriter{w: os.Stdout, mu: s.writeMu}
+	return stdio.Listen(ctx, s.filterInput(os.Stdin, stdout), stdout)
+}
+
+// stdioSessionID is the ID mcp-go gives the single stdio session.
+const stdioSessionID = "stdio"
+
+// filterInput reads client messages, hands sampling responses to the
+// sampler, handles subscription requests, writing failures to out, and
+// returns a reader with the remaining messages.
+func (s *Server) filterInput(in io.Reader, out io.Writer) io.Reader {
+	pr, pw := io.Pipe()
+	lines := make(chan []byte, inputBuffer)
+
+	go func() {
+		defer close(lines)
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 && !s.clientSampler.HandleMessage(line) {
+				message := bytes.TrimRight(line, "\r\n")
+				forward, reply := s.interceptSubscription(context.Background(), stdioSessionID, message)
+				if reply != nil {
+					_, _ = out.Write(append(reply, '\n'))
+				} else {
+					lines <- append(forward, '\n')
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		for line := range lines {
+			if _, err := pw.Write(line); err != nil {
+				break
+			}
+		}
+		pw.Close()
+	}()
+
+	return pr
+}
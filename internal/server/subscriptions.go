@@ -0,0 +1,245 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/tenancy"
+)
+
+// Methods of resource subscriptions. mcp-go advertises subscriptions but
+// does not handle them, so the transports hand these requests to the
+// server before the MCP server sees them.
+const (
+	methodSubscribe   = "resources/subscribe"
+	methodUnsubscribe = "resources/unsubscribe"
+)
+
+// maxSubscriptions bounds the resources one session may subscribe to, and
+// so the watches it can make the server open.
+const maxSubscriptions = 100
+
+// subscribableScheme is the URI scheme of the resources that can be
+// subscribed to, matching the kagent resources registered by the tools.
+const subscribableScheme = "kagent://"
+
+// subscription is a resource a session subscribed to. Name is empty for a
+// kind's list.
+type subscription struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+// subscriptions tracks the resources each client session subscribed to,
+// and holds a watch on each of their kinds and namespaces.
+type subscriptions struct {
+	watches *kubernetes.WatchManager
+
+	mu sync.Mutex
+	// live are the sessions currently registered with the MCP server.
+	live      map[string]bool
+	bySession map[string]map[string]subscription
+}
+
+// enableSubscriptions sets up resource subscriptions. Changes seen by the
+// watches are sent to subscribed sessions as resources/updated
+// notifications.
+func (s *Server) enableSubscriptions(hooks *server.Hooks) {
+	s.subs = &subscriptions{
+		live:      map[string]bool{},
+		bySession: map[string]map[string]subscription{},
+	}
+	s.subs.watches = kubernetes.NewWatchManager(s.k8sClient, s.notifySubscribers, func(err error) {
+		fmt.Fprintf(os.Stderr, "Resource subscriptions: %v\n", err)
+	})
+
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.subs.mu.Lock()
+		defer s.subs.mu.Unlock()
+		s.subs.live[session.SessionID()] = true
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.unsubscribeAll(session.SessionID())
+	})
+}
+
+// interceptSubscription handles message when it is a subscribe or
+// unsubscribe request of sessionID. It returns the message to pass on to
+// the MCP server: message itself when it is anything else, or a ping with
+// the request's ID, whose empty result is the reply a successful request
+// expects. When the request fails, it returns the error reply to send to
+// the client instead.
+func (s *Server) interceptSubscription(ctx context.Context, sessionID string, message []byte) (forward []byte, reply []byte) {
+	var req struct {
+		JSONRPC string `json:"jsonrpc"`
+		ID      any    `json:"id"`
+		Method  string `json:"method"`
+		Params  struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &req); err != nil || req.ID == nil {
+		return message, nil
+	}
+	if req.Method != methodSubscribe && req.Method != methodUnsubscribe {
+		return message, nil
+	}
+
+	var err error
+	if req.Method == methodSubscribe {
+		err = s.subscribe(ctx, sessionID, req.Params.URI)
+	} else {
+		s.unsubscribe(sessionID, req.Params.URI)
+	}
+	if err != nil {
+		reply, _ = json.Marshal(mcp.NewJSONRPCError(req.ID, mcp.INVALID_PARAMS, err.Error(), nil))
+		return nil, reply
+	}
+
+	forward, _ = json.Marshal(map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      req.ID,
+		"method":  string(mcp.MethodPing),
+	})
+	return forward, nil
+}
+
+// subscribe subscribes sessionID to uri. Tenant sessions may only
+// subscribe to their own namespace.
+func (s *Server) subscribe(ctx context.Context, sessionID, uri string) error {
+	sub, err := parseSubscription(uri, s.K8sClientFor(ctx).Namespace())
+	if err != nil {
+		return err
+	}
+	if t, ok := tenancy.FromContext(ctx); ok && sub.namespace != t.Namespace {
+		return fmt.Errorf("tenant '%s' may only subscribe to namespace '%s'", t.Name, t.Namespace)
+	}
+
+	s.subs.mu.Lock()
+	defer s.subs.mu.Unlock()
+	if !s.subs.live[sessionID] {
+		return fmt.Errorf("unknown session '%s'", sessionID)
+	}
+	uris := s.subs.bySession[sessionID]
+	if uris == nil {
+		uris = map[string]subscription{}
+		s.subs.bySession[sessionID] = uris
+	}
+	if _, ok := uris[uri]; ok {
+		return nil
+	}
+	if len(uris) >= maxSubscriptions {
+		return fmt.Errorf("a session may subscribe to at most %d resources", maxSubscriptions)
+	}
+	uris[uri] = sub
+	s.subs.watches.Acquire(sub.gvr, sub.namespace)
+	return nil
+}
+
+// unsubscribe cancels a subscription of sessionID. Unknown URIs are
+// ignored, so that unsubscribing twice is harmless.
+func (s *Server) unsubscribe(sessionID, uri string) {
+	s.subs.mu.Lock()
+	defer s.subs.mu.Unlock()
+	if sub, ok := s.subs.bySession[sessionID][uri]; ok {
+		delete(s.subs.bySession[sessionID], uri)
+		s.subs.watches.Release(sub.gvr, sub.namespace)
+	}
+}
+
+// unsubscribeAll cancels every subscription of a session that ended.
+func (s *Server) unsubscribeAll(sessionID string) {
+	s.subs.mu.Lock()
+	defer s.subs.mu.Unlock()
+	for _, sub := range s.subs.bySession[sessionID] {
+		s.subs.watches.Release(sub.gvr, sub.namespace)
+	}
+	delete(s.subs.bySession, sessionID)
+	delete(s.subs.live, sessionID)
+}
+
+// notifySubscribers sends resources/updated to every session subscribed to
+// a resource the event changed. A list changes when a resource is added or
+// deleted; after missed events, every subscription of the kind is notified.
+func (s *Server) notifySubscribers(event kubernetes.WatchEvent) {
+	type target struct{ sessionID, uri string }
+	var targets []target
+
+	s.subs.mu.Lock()
+	for sessionID, uris := range s.subs.bySession {
+		for uri, sub := range uris {
+			if sub.gvr != event.GVR || sub.namespace != event.Namespace {
+				continue
+			}
+			switch {
+			case event.Name == "":
+			case sub.name == "" && event.Type != watch.Modified:
+			case sub.name == event.Name:
+			default:
+				continue
+			}
+			targets = append(targets, target{sessionID, uri})
+		}
+	}
+	s.subs.mu.Unlock()
+
+	for _, t := range targets {
+		err := s.mcpServer.SendNotificationToSpecificClient(t.sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": t.uri})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to notify session %s of a change to %s: %v\n", t.sessionID, t.uri, err)
+		}
+	}
+}
+
+// parseSubscription parses a kagent resource URI:
+//
+//	kagent://agents
+//	kagent://agents/{name}
+//	kagent://namespaces/{namespace}/agents/{name}
+//
+// Lists and the short form refer to sessionNamespace.
+func parseSubscription(uri, sessionNamespace string) (subscription, error) {
+	path, ok := strings.CutPrefix(uri, subscribableScheme)
+	if !ok {
+		return subscription{}, fmt.Errorf("cannot subscribe to '%s': only %s resources support subscriptions", uri, subscribableScheme)
+	}
+
+	sub := subscription{namespace: sessionNamespace}
+	parts := strings.Split(path, "/")
+	if len(parts) == 4 && parts[0] == "namespaces" {
+		sub.namespace = parts[1]
+		parts = parts[2:]
+		if errs := validation.IsDNS1123Label(sub.namespace); len(errs) > 0 {
+			return subscription{}, fmt.Errorf("invalid namespace '%s' in %s", sub.namespace, uri)
+		}
+	}
+	if len(parts) > 2 {
+		return subscription{}, fmt.Errorf("unknown resource '%s'", uri)
+	}
+	if len(parts) == 2 {
+		sub.name = parts[1]
+		if errs := validation.IsDNS1123Subdomain(sub.name); len(errs) > 0 {
+			return subscription{}, fmt.Errorf("invalid name '%s' in %s", sub.name, uri)
+		}
+	}
+
+	for _, gvr := range kubernetes.CachedGVRs {
+		if gvr.Resource == parts[0] {
+			sub.gvr = gvr
+			return sub, nil
+		}
+	}
+	return subscription{}, fmt.Errorf("unknown resource '%s'", uri)
+}
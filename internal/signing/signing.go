@@ -0,0 +1,53 @@
+// Package signing signs reviewed manifests so that approval pipelines can
+// guarantee that what was approved is exactly what gets applied. A manifest
+// is identified by its digest; an approver holding the signing key, which
+// the server shares only with approvers, signs the digest with an HMAC, and
+// the server verifies the signature before applying.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// digestPrefix and signaturePrefix name the algorithms, so that others
+	// can be added without ambiguity.
+	digestPrefix    = "sha256:"
+	signaturePrefix = "hmac-sha256:"
+)
+
+// ErrMismatch is returned when a signature does not match the manifest.
+var ErrMismatch = errors.New("signature does not match the manifest")
+
+// Digest returns the digest of a manifest. Line endings and surrounding
+// whitespace are normalized, so that copying the manifest between tools
+// does not change it; anything else does.
+func Digest(manifest string) string {
+	normalized := strings.TrimSpace(strings.ReplaceAll(manifest, "\r\n", "\n"))
+	sum := sha256.Sum256([]byte(normalized))
+	return digestPrefix + hex.EncodeToString(sum[:])
+}
+
+// Sign returns the signature of a manifest digest with key.
+func Sign(key, digest string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(digest))
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that signature is key's signature of manifest.
+func Verify(key, manifest, signature string) error {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return fmt.Errorf("unsupported signature format: expected %s<hex>", signaturePrefix)
+	}
+	expected := Sign(key, Digest(manifest))
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+		return ErrMismatch
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package slo
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// burnRateAlert is one multi-window burn-rate alert. It fires when the
+// error budget is consumed factor times faster than the rate that would
+// exhaust it exactly at the end of the SLO window, over both a long and a
+// short window (the short window makes the alert reset quickly).
+type burnRateAlert struct {
+	suffix   string
+	long     string
+	short    string
+	factor   float64
+	severity string
+}
+
+// burnRateAlerts follow the multi-window, multi-burn-rate recommendation:
+// page on 2% of a 30-day budget spent in an hour, open a ticket on 5% spent
+// in six hours.
+var burnRateAlerts = []burnRateAlert{
+	{suffix: "FastBurn", long: "1h", short: "5m", factor: 14.4, severity: "critical"},
+	{suffix: "SlowBurn", long: "6h", short: "30m", factor: 6, severity: "warning"},
+}
+
+// latencyAlertWindow and latencyAlertFor bound how long latency may exceed
+// its objective before alerting.
+const (
+	latencyAlertWindow = "5m"
+	latencyAlertFor    = "15m"
+)
+
+// Target is an agent and its objectives.
+type Target struct {
+	Agent string
+	SLO   SLO
+}
+
+// PrometheusRule returns a monitoring.coreos.com/v1 PrometheusRule with one
+// rule group per agent.
+func PrometheusRule(name, namespace string, queries Queries, targets []Target) map[string]interface{} {
+	groups := make([]interface{}, 0, len(targets))
+	for _, t := range targets {
+		groups = append(groups, map[string]interface{}{
+			"name":  "kagent-slo-" + t.Agent,
+			"rules": alertRules(namespace, queries, t),
+		})
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "PrometheusRule",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "kmeta-agent",
+			},
+		},
+		"spec": map[string]interface{}{
+			"groups": groups,
+		},
+	}
+}
+
+func alertRules(namespace string, queries Queries, t Target) []interface{} {
+	var rules []interface{}
+	labels := func(severity string) map[string]interface{} {
+		return map[string]interface{}{
+			"severity":  severity,
+			"agent":     t.Agent,
+			"namespace": namespace,
+		}
+	}
+	prefix := "KagentAgent" + alertName(t.Agent)
+
+	if t.SLO.Availability > 0 {
+		budget := 1 - t.SLO.Availability/100
+		errorRatio := func(window string) string {
+			return fmt.Sprintf("(1 - (%s))", Render(queries.Availability, namespace, t.Agent, window, t.SLO.LatencyPercentile))
+		}
+		for _, a := range burnRateAlerts {
+			threshold := strconv.FormatFloat(a.factor*budget, 'f', -1, 64)
+			rules = append(rules, map[string]interface{}{
+				"alert":  prefix + "ErrorBudget" + a.suffix,
+				"expr":   fmt.Sprintf("%s > %s and %s > %s", errorRatio(a.long), threshold, errorRatio(a.short), threshold),
+				"for":    "2m",
+				"labels": labels(a.severity),
+				"annotations": map[string]interface{}{
+					"summary": fmt.Sprintf("Agent %s/%s is burning its error budget %gx too fast", namespace, t.Agent, a.factor),
+					"description": fmt.Sprintf("Over the last %s the error ratio exceeded %gx the budget of a %s%% availability objective (%s window).",
+						a.long, a.factor, strconv.FormatFloat(t.SLO.Availability, 'f', -1, 64), t.SLO.Window),
+				},
+			})
+		}
+	}
+
+	if t.SLO.Latency > 0 {
+		percentile := strconv.FormatFloat(t.SLO.LatencyPercentile, 'f', -1, 64)
+		rules = append(rules, map[string]interface{}{
+			"alert":  prefix + "LatencyHigh",
+			"expr":   fmt.Sprintf("%s > %s", Render(queries.Latency, namespace, t.Agent, latencyAlertWindow, t.SLO.LatencyPercentile), strconv.FormatFloat(t.SLO.Latency.Seconds(), 'f', -1, 64)),
+			"for":    latencyAlertFor,
+			"labels": labels("warning"),
+			"annotations": map[string]interface{}{
+				"summary":     fmt.Sprintf("Agent %s/%s p%s latency is above %s", namespace, t.Agent, percentile, t.SLO.Latency),
+				"description": fmt.Sprintf("The p%s response latency over %s windows has exceeded the %s objective for %s.", percentile, latencyAlertWindow, t.SLO.Latency, latencyAlertFor),
+			},
+		})
+	}
+	return rules
+}
+
+// alertName converts an agent name such as k8s-agent to K8sAgent.
+func alertName(agent string) string {
+	var out []byte
+	upper := true
+	for i := 0; i < len(agent); i++ {
+		c := agent[i]
+		if c == '-' || c == '.' {
+			upper = true
+			continue
+		}
+		if upper && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		out = append(out, c)
+	}
+	return string(out)
+}
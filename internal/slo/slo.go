@@ -0,0 +1,297 @@
+// Package slo defines per-agent service level objectives, evaluates them
+// against Prometheus metrics and generates the matching alert rules.
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Annotations recording an agent's objectives.
+const (
+	AnnotationAvailability      = "kagent.dev/slo-availability"
+	AnnotationLatency           = "kagent.dev/slo-latency"
+	AnnotationLatencyPercentile = "kagent.dev/slo-latency-percentile"
+	AnnotationWindow            = "kagent.dev/slo-window"
+)
+
+// Defaults for objectives that leave fields unset.
+const (
+	DefaultLatencyPercentile = 95.0
+	DefaultWindow            = "30d"
+)
+
+// Compliance statuses.
+const (
+	StatusMet      = "met"
+	StatusViolated = "violated"
+	StatusNoData   = "no_data"
+)
+
+// windowPattern matches a Prometheus range duration such as 30d or 6h.
+var windowPattern = regexp.MustCompile(`^[0-9]+[smhdw]$`)
+
+// SLO is the set of objectives for one agent. Zero values mean the
+// objective is not defined.
+type SLO struct {
+	// Availability is the target percentage of successful requests, e.g. 99.5.
+	Availability float64 `json:"availability,omitempty"`
+	// Latency is the target response latency at LatencyPercentile.
+	Latency time.Duration `json:"-"`
+	// LatencyPercentile is the percentile Latency applies to, e.g. 95.
+	LatencyPercentile float64 `json:"latencyPercentile,omitempty"`
+	// Window is the Prometheus range the objectives are evaluated over.
+	Window string `json:"window"`
+}
+
+// MarshalJSON renders Latency as a duration string.
+func (s SLO) MarshalJSON() ([]byte, error) {
+	type plain SLO
+	var latency string
+	if s.Latency > 0 {
+		latency = s.Latency.String()
+	}
+	return json.Marshal(struct {
+		plain
+		Latency string `json:"latency,omitempty"`
+	}{plain(s), latency})
+}
+
+// Validate reports objectives that are out of range.
+func (s SLO) Validate() error {
+	if s.Availability == 0 && s.Latency == 0 {
+		return fmt.Errorf("at least one of availability or latency must be set")
+	}
+	if s.Availability != 0 && (s.Availability <= 0 || s.Availability >= 100) {
+		return fmt.Errorf("availability must be a percentage between 0 and 100 (exclusive), got %g", s.Availability)
+	}
+	if s.Latency < 0 {
+		return fmt.Errorf("latency must be positive, got %s", s.Latency)
+	}
+	if s.Latency > 0 && (s.LatencyPercentile <= 0 || s.LatencyPercentile >= 100) {
+		return fmt.Errorf("latency percentile must be between 0 and 100 (exclusive), got %g", s.LatencyPercentile)
+	}
+	if !windowPattern.MatchString(s.Window) {
+		return fmt.Errorf("window must be a Prometheus duration such as 30d or 7d, got '%s'", s.Window)
+	}
+	return nil
+}
+
+// Annotations returns the annotations that record s.
+func (s SLO) Annotations() map[string]string {
+	annotations := map[string]string{
+		AnnotationWindow: s.Window,
+	}
+	if s.Availability > 0 {
+		annotations[AnnotationAvailability] = strconv.FormatFloat(s.Availability, 'f', -1, 64)
+	}
+	if s.Latency > 0 {
+		annotations[AnnotationLatency] = s.Latency.String()
+		annotations[AnnotationLatencyPercentile] = strconv.FormatFloat(s.LatencyPercentile, 'f', -1, 64)
+	}
+	return annotations
+}
+
+// FromAnnotations reads the objectives recorded on an agent. ok is false
+// when the agent has none.
+func FromAnnotations(annotations map[string]string) (s SLO, ok bool, err error) {
+	availability, hasAvailability := annotations[AnnotationAvailability]
+	latency, hasLatency := annotations[AnnotationLatency]
+	if !hasAvailability && !hasLatency {
+		return SLO{}, false, nil
+	}
+
+	s = SLO{LatencyPercentile: DefaultLatencyPercentile, Window: DefaultWindow}
+	if hasAvailability {
+		if s.Availability, err = strconv.ParseFloat(availability, 64); err != nil {
+			return SLO{}, true, fmt.Errorf("invalid %s annotation: %w", AnnotationAvailability, err)
+		}
+	}
+	if hasLatency {
+		if s.Latency, err = time.ParseDuration(latency); err != nil {
+			return SLO{}, true, fmt.Errorf("invalid %s annotation: %w", AnnotationLatency, err)
+		}
+	}
+	if v, found := annotations[AnnotationLatencyPercentile]; found {
+		if s.LatencyPercentile, err = strconv.ParseFloat(v, 64); err != nil {
+			return SLO{}, true, fmt.Errorf("invalid %s annotation: %w", AnnotationLatencyPercentile, err)
+		}
+	}
+	if v, found := annotations[AnnotationWindow]; found {
+		s.Window = v
+	}
+	return s, true, s.Validate()
+}
+
+// Queries are PromQL templates for the measured indicators. The
+// placeholders {{agent}}, {{namespace}}, {{window}} and {{quantile}} are
+// substituted per agent.
+type Queries struct {
+	// Availability evaluates to the ratio of successful requests (0-1).
+	Availability string
+	// Latency evaluates to the response latency in seconds at {{quantile}}.
+	Latency string
+}
+
+// DefaultQueries read the request metrics exported by kagent agents.
+var DefaultQueries = Queries{
+	Availability: `sum(rate(kagent_agent_requests_total{namespace="{{namespace}}",agent="{{agent}}",status!="error"}[{{window}}])) / sum(rate(kagent_agent_requests_total{namespace="{{namespace}}",agent="{{agent}}"}[{{window}}]))`,
+	Latency:      `histogram_quantile({{quantile}}, sum by (le) (rate(kagent_agent_request_duration_seconds_bucket{namespace="{{namespace}}",agent="{{agent}}"}[{{window}}])))`,
+}
+
+// Render substitutes the placeholders of a query template.
+func Render(template, namespace, agent, window string, percentile float64) string {
+	return strings.NewReplacer(
+		"{{namespace}}", namespace,
+		"{{agent}}", agent,
+		"{{window}}", window,
+		"{{quantile}}", strconv.FormatFloat(percentile/100, 'f', -1, 64),
+	).Replace(template)
+}
+
+// Prometheus runs instant queries against the Prometheus HTTP API.
+type Prometheus struct {
+	url    string
+	client *http.Client
+}
+
+// NewPrometheus creates a client for the Prometheus server at baseURL.
+func NewPrometheus(baseURL string, client *http.Client) *Prometheus {
+	return &Prometheus{url: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+// Query evaluates query and returns the value of its first sample. ok is
+// false when the result is empty or not a number, e.g. when no requests
+// were recorded in the window.
+func (p *Prometheus) Query(ctx context.Context, query string) (value float64, ok bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/api/v1/query?"+url.Values{"query": {query}}.Encode(), nil)
+	if err != nil {
+		return 0, false, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, false, err
+	}
+
+	var result struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string `json:"resultType"`
+			Result     []struct {
+				Value []interface{} `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, false, fmt.Errorf("unexpected response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return 0, false, fmt.Errorf("query failed: %s", result.Error)
+	}
+	if result.Data.ResultType != "vector" || len(result.Data.Result) == 0 || len(result.Data.Result[0].Value) != 2 {
+		return 0, false, nil
+	}
+
+	raw, _ := result.Data.Result[0].Value[1].(string)
+	value, err = strconv.ParseFloat(raw, 64)
+	if err != nil || value != value { // NaN when the denominator is zero
+		return 0, false, nil
+	}
+	return value, true, nil
+}
+
+// Indicator is the measured value of one objective.
+type Indicator struct {
+	Objective string `json:"objective"`
+	Measured  string `json:"measured,omitempty"`
+	Status    string `json:"status"`
+	// ErrorBudgetRemaining is the share of the availability error budget
+	// left in the window, in percent. Negative when the budget is exhausted.
+	ErrorBudgetRemaining *float64 `json:"errorBudgetRemaining,omitempty"`
+	Query                string   `json:"query"`
+}
+
+// Compliance is the evaluation of one agent's objectives.
+type Compliance struct {
+	Agent        string     `json:"agent"`
+	SLO          SLO        `json:"slo"`
+	Status       string     `json:"status"`
+	Availability *Indicator `json:"availability,omitempty"`
+	Latency      *Indicator `json:"latency,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// Evaluate measures the objectives of one agent.
+func Evaluate(ctx context.Context, prom *Prometheus, queries Queries, namespace, agent string, s SLO) Compliance {
+	c := Compliance{Agent: agent, SLO: s}
+
+	if s.Availability > 0 {
+		query := Render(queries.Availability, namespace, agent, s.Window, s.LatencyPercentile)
+		ind := &Indicator{Objective: strconv.FormatFloat(s.Availability, 'f', -1, 64) + "%", Query: query, Status: StatusNoData}
+		ratio, ok, err := prom.Query(ctx, query)
+		if err != nil {
+			c.Error = fmt.Sprintf("availability: %v", err)
+		} else if ok {
+			measured := ratio * 100
+			budget := 100 - s.Availability
+			remaining := (budget - (100 - measured)) / budget * 100
+			ind.Measured = strconv.FormatFloat(measured, 'f', 3, 64) + "%"
+			ind.ErrorBudgetRemaining = &remaining
+			ind.Status = StatusMet
+			if measured < s.Availability {
+				ind.Status = StatusViolated
+			}
+		}
+		c.Availability = ind
+	}
+
+	if s.Latency > 0 {
+		query := Render(queries.Latency, namespace, agent, s.Window, s.LatencyPercentile)
+		objective := fmt.Sprintf("p%s < %s", strconv.FormatFloat(s.LatencyPercentile, 'f', -1, 64), s.Latency)
+		ind := &Indicator{Objective: objective, Query: query, Status: StatusNoData}
+		seconds, ok, err := prom.Query(ctx, query)
+		if err != nil {
+			if c.Error != "" {
+				c.Error += "; "
+			}
+			c.Error += fmt.Sprintf("latency: %v", err)
+		} else if ok {
+			measured := time.Duration(seconds * float64(time.Second))
+			ind.Measured = measured.Round(time.Millisecond).String()
+			ind.Status = StatusMet
+			if measured > s.Latency {
+				ind.Status = StatusViolated
+			}
+		}
+		c.Latency = ind
+	}
+
+	c.Status = StatusNoData
+	for _, ind := range []*Indicator{c.Availability, c.Latency} {
+		if ind == nil {
+			continue
+		}
+		switch {
+		case ind.Status == StatusViolated:
+			c.Status = StatusViolated
+		case ind.Status == StatusMet && c.Status == StatusNoData:
+			c.Status = StatusMet
+		}
+	}
+	return c
+}
@@ -0,0 +1,121 @@
+package state
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// ConfigMapStore persists entries in a ConfigMap, one data key per entry.
+// Writes retry on conflicts, so several replicas may share the ConfigMap.
+// A ConfigMap holds at most 1 MiB, which bounds the stored state.
+type ConfigMapStore struct {
+	client *kubernetes.Client
+	name   string
+
+	// mu serializes this replica's read-modify-write cycles.
+	mu sync.Mutex
+}
+
+// NewConfigMapStore creates a store backed by the named ConfigMap in
+// client's namespace. The ConfigMap is created on the first write.
+func NewConfigMapStore(client *kubernetes.Client, name string) *ConfigMapStore {
+	return &ConfigMapStore{client: client, name: name}
+}
+
+// Get implements Store.
+func (s *ConfigMapStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, _, err := s.client.GetConfigMapData(ctx, s.name)
+	if err != nil {
+		return nil, false, err
+	}
+	e, ok := decodeEntry(data[dataKey(key)])
+	if !ok || e.expired(time.Now()) {
+		return nil, false, nil
+	}
+	return e.Value, true, nil
+}
+
+// Put implements Store.
+func (s *ConfigMapStore) Put(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	encoded, _ := json.Marshal(newEntry(value, ttl))
+	return s.update(ctx, func(data map[string]string) {
+		data[dataKey(key)] = string(encoded)
+	})
+}
+
+// Delete implements Store.
+func (s *ConfigMapStore) Delete(ctx context.Context, key string) (bool, error) {
+	var found bool
+	err := s.update(ctx, func(data map[string]string) {
+		_, found = data[dataKey(key)]
+		delete(data, dataKey(key))
+	})
+	return found, err
+}
+
+// List implements Store.
+func (s *ConfigMapStore) List(ctx context.Context, prefix string) (map[string][]byte, error) {
+	data, _, err := s.client.GetConfigMapData(ctx, s.name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	values := make(map[string][]byte)
+	for k, raw := range data {
+		key, ok := decodeKey(k)
+		if !ok || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if e, ok := decodeEntry(raw); ok && !e.expired(now) {
+			values[key] = e.Value
+		}
+	}
+	return values, nil
+}
+
+// update applies change to the ConfigMap data with expired entries
+// evicted. Writes by other replicas make the update conflict, and it is
+// retried on the data they wrote.
+func (s *ConfigMapStore) update(ctx context.Context, change func(data map[string]string)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "kmeta-agent",
+	}
+	return s.client.UpdateConfigMapData(ctx, s.name, labels, func(data map[string]string) error {
+		now := time.Now()
+		for k, raw := range data {
+			if e, ok := decodeEntry(raw); !ok || e.expired(now) {
+				delete(data, k)
+			}
+		}
+		change(data)
+		return nil
+	})
+}
+
+// dataKey encodes a key into the characters ConfigMap data keys allow.
+func dataKey(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeKey(k string) (string, bool) {
+	key, err := base64.RawURLEncoding.DecodeString(k)
+	return string(key), err == nil
+}
+
+func decodeEntry(raw string) (entry, bool) {
+	var e entry
+	if raw == "" || json.Unmarshal([]byte(raw), &e) != nil {
+		return entry{}, false
+	}
+	return e, true
+}
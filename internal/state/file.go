@@ -0,0 +1,134 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStore persists entries as files in a directory, one file per entry.
+// Mounted from a PersistentVolumeClaim, it survives restarts and holds more
+// state than a ConfigMap, but can only be shared by replicas when the
+// volume is ReadWriteMany.
+type FileStore struct {
+	dir string
+
+	mu sync.Mutex
+}
+
+// NewFileStore creates a store in dir, creating the directory if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("a directory is required for the file state store")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory %s: %w", dir, err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Get implements Store.
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok, err := s.read(dataKey(key))
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return e.Value, true, nil
+}
+
+// Put implements Store.
+func (s *FileStore) Put(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encoded, _ := json.Marshal(newEntry(value, ttl))
+
+	// Write then rename so readers never see a partial entry
+	path := filepath.Join(s.dir, dataKey(key))
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(encoded); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(filepath.Join(s.dir, dataKey(key)))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete state %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// List implements Store.
+func (s *FileStore) List(ctx context.Context, prefix string) (map[string][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state directory %s: %w", s.dir, err)
+	}
+
+	values := make(map[string][]byte)
+	for _, f := range files {
+		key, ok := decodeKey(f.Name())
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") || !ok || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		e, ok, err := s.read(f.Name())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			values[key] = e.Value
+		}
+	}
+	return values, nil
+}
+
+// read returns the entry in the named file, removing it once expired. The
+// caller holds mu.
+func (s *FileStore) read(name string) (entry, bool, error) {
+	path := filepath.Join(s.dir, name)
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return entry{}, false, nil
+	}
+	if err != nil {
+		return entry{}, false, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+
+	e, ok := decodeEntry(string(raw))
+	if !ok || e.expired(time.Now()) {
+		_ = os.Remove(path)
+		return entry{}, false, nil
+	}
+	return e, true, nil
+}
@@ -0,0 +1,162 @@
+// Package state keeps the short-lived state of stateful tools, such as
+// reviewed diffs and elevation requests, behind one key-value interface.
+// The state lives in memory by default; with a ConfigMap or a directory on
+// a persistent volume it survives server restarts.
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// Backends selectable with Open.
+const (
+	BackendMemory    = "memory"
+	BackendConfigMap = "configmap"
+	BackendFile      = "file"
+)
+
+// Store is a key-value store whose entries expire. Implementations are safe
+// for concurrent use. Keys are namespaced by the caller with a prefix such
+// as "review/".
+type Store interface {
+	// Get returns the value of key, with found=false when it is missing or
+	// expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Put stores value under key until ttl elapses (ttl <= 0: no expiry).
+	Put(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key, reporting whether it was present.
+	Delete(ctx context.Context, key string) (bool, error)
+	// List returns the unexpired entries whose keys start with prefix.
+	List(ctx context.Context, prefix string) (map[string][]byte, error)
+}
+
+// Open returns the store of the given backend. The ConfigMap backend stores
+// entries in the named ConfigMap of client's namespace; the file backend in
+// dir, typically a mounted PersistentVolumeClaim.
+func Open(backend string, client *kubernetes.Client, configMap, dir string) (Store, error) {
+	switch backend {
+	case "", BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendConfigMap:
+		return NewConfigMapStore(client, configMap), nil
+	case BackendFile:
+		return NewFileStore(dir)
+	default:
+		return nil, fmt.Errorf("unknown state store '%s': expected %s, %s or %s", backend, BackendMemory, BackendConfigMap, BackendFile)
+	}
+}
+
+// GetJSON decodes the value of key into v, reporting whether it was found.
+func GetJSON(ctx context.Context, s Store, key string, v interface{}) (bool, error) {
+	value, ok, err := s.Get(ctx, key)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := json.Unmarshal(value, v); err != nil {
+		return false, fmt.Errorf("failed to decode state %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// PutJSON stores v encoded as JSON under key until ttl elapses.
+func PutJSON(ctx context.Context, s Store, key string, v interface{}, ttl time.Duration) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode state %s: %w", key, err)
+	}
+	return s.Put(ctx, key, value, ttl)
+}
+
+// entry is a stored value with its expiry, as persisted by the ConfigMap
+// and file backends.
+type entry struct {
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+func newEntry(value []byte, ttl time.Duration) entry {
+	e := entry{Value: value}
+	if ttl > 0 {
+		e.ExpiresAt = time.Now().Add(ttl)
+	}
+	return e
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+// MemoryStore keeps entries in memory; they are lost when the server
+// restarts.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]entry)}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictExpired()
+
+	e, ok := s.entries[key]
+	return e.Value, ok, nil
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictExpired()
+
+	s.entries[key] = newEntry(value, ttl)
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictExpired()
+
+	_, ok := s.entries[key]
+	delete(s.entries, key)
+	return ok, nil
+}
+
+// List implements Store.
+func (s *MemoryStore) List(ctx context.Context, prefix string) (map[string][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictExpired()
+
+	values := make(map[string][]byte)
+	for key, e := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			values[key] = e.Value
+		}
+	}
+	return values, nil
+}
+
+// evictExpired drops expired entries. The caller holds mu.
+func (s *MemoryStore) evictExpired() {
+	now := time.Now()
+	for key, e := range s.entries {
+		if e.expired(now) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package stats records periodic snapshots of kagent resource counts.
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// snapshotsKey is the ConfigMap data key holding the JSON-encoded snapshots.
+const snapshotsKey = "snapshots.json"
+
+// Kinds tracked by the stats store, keyed by kind name.
+var trackedKinds = map[string]schema.GroupVersionResource{
+	"Agent":           kubernetes.AgentGVR,
+	"ModelConfig":     kubernetes.ModelConfigGVR,
+	"MCPServer":       kubernetes.MCPServerGVR,
+	"RemoteMCPServer": kubernetes.RemoteMCPServerGVR,
+}
+
+// Snapshot is a point-in-time count of resources per kind.
+type Snapshot struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Counts    map[string]int `json:"counts"`
+}
+
+// Store persists resource count snapshots to a ConfigMap.
+type Store struct {
+	k8sClient     *kubernetes.Client
+	configMapName string
+	maxSnapshots  int
+}
+
+// NewStore creates a stats store backed by the named ConfigMap, keeping at
+// most maxSnapshots entries.
+func NewStore(k8sClient *kubernetes.Client, configMapName string, maxSnapshots int) *Store {
+	return &Store{
+		k8sClient:     k8sClient,
+		configMapName: configMapName,
+		maxSnapshots:  maxSnapshots,
+	}
+}
+
+// Snapshots returns all recorded snapshots, oldest first.
+func (s *Store) Snapshots(ctx context.Context) ([]Snapshot, error) {
+	data, _, err := s.k8sClient.GetConfigMapData(ctx, s.configMapName)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := data[snapshotsKey]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+
+	var snapshots []Snapshot
+	if err := json.Unmarshal([]byte(raw), &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// Record counts the tracked resources and appends a snapshot, trimming the
+// history to the configured maximum.
+func (s *Store) Record(ctx context.Context) (*Snapshot, error) {
+	snapshot := Snapshot{
+		Timestamp: time.Now().UTC(),
+		Counts:    make(map[string]int),
+	}
+	for kind, gvr := range trackedKinds {
+		count, err := s.k8sClient.CountResources(ctx, gvr)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Counts[kind] = count
+	}
+
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "kmeta-agent",
+		"app.kubernetes.io/component":  "stats",
+	}
+	err := s.k8sClient.UpdateConfigMapData(ctx, s.configMapName, labels, func(data map[string]string) error {
+		var snapshots []Snapshot
+		if raw := data[snapshotsKey]; raw != "" {
+			if err := json.Unmarshal([]byte(raw), &snapshots); err != nil {
+				return fmt.Errorf("failed to decode snapshots: %w", err)
+			}
+		}
+		snapshots = append(snapshots, snapshot)
+		if s.maxSnapshots > 0 && len(snapshots) > s.maxSnapshots {
+			snapshots = snapshots[len(snapshots)-s.maxSnapshots:]
+		}
+
+		encoded, err := json.Marshal(snapshots)
+		if err != nil {
+			return fmt.Errorf("failed to encode snapshots: %w", err)
+		}
+		data[snapshotsKey] = string(encoded)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Run records a snapshot immediately and then every interval until ctx is
+// cancelled. Errors are logged to stderr and do not stop the loop.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Record(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to record resource stats: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Trend describes how the count of one kind changed over a window.
+type Trend struct {
+	Kind        string  `json:"kind"`
+	First       int     `json:"first"`
+	Last        int     `json:"last"`
+	Delta       int     `json:"delta"`
+	GrowthPct   float64 `json:"growthPercent"`
+	Peak        int     `json:"peak"`
+	SampleCount int     `json:"samples"`
+}
+
+// Trends computes per-kind trends over the snapshots taken at or after since.
+func Trends(snapshots []Snapshot, since time.Time) []Trend {
+	var window []Snapshot
+	for _, snap := range snapshots {
+		if !snap.Timestamp.Before(since) {
+			window = append(window, snap)
+		}
+	}
+	if len(window) == 0 {
+		return nil
+	}
+
+	var trends []Trend
+	for _, kind := range Kinds() {
+		first := window[0].Counts[kind]
+		last := window[len(window)-1].Counts[kind]
+		trend := Trend{
+			Kind:        kind,
+			First:       first,
+			Last:        last,
+			Delta:       last - first,
+			SampleCount: len(window),
+		}
+		if first > 0 {
+			trend.GrowthPct = float64(last-first) / float64(first) * 100
+		}
+		for _, snap := range window {
+			if snap.Counts[kind] > trend.Peak {
+				trend.Peak = snap.Counts[kind]
+			}
+		}
+		trends = append(trends, trend)
+	}
+	return trends
+}
+
+// Kinds returns the tracked kind names in a stable order.
+func Kinds() []string {
+	return []string{"Agent", "ModelConfig", "MCPServer", "RemoteMCPServer"}
+}
@@ -0,0 +1,127 @@
+// Package tenancy binds authenticated clients of a shared server to their
+// own Kubernetes identity and namespace, so one tenant's session can never
+// act on another tenant's resources.
+package tenancy
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// Tenant is a client identity and the Kubernetes identity it acts as.
+type Tenant struct {
+	// Name identifies the tenant in logs and job ownership.
+	Name string `json:"name"`
+	// TokenSHA256 is the hex-encoded SHA-256 of the tenant's bearer token.
+	TokenSHA256 string `json:"tokenSHA256"`
+	// ServiceAccount is impersonated for every Kubernetes call.
+	ServiceAccount string `json:"serviceAccount"`
+	// ServiceAccountNamespace defaults to Namespace.
+	ServiceAccountNamespace string `json:"serviceAccountNamespace,omitempty"`
+	// Namespace is the only namespace the tenant's tools operate in.
+	Namespace string `json:"namespace"`
+}
+
+// file is the on-disk format of the tenants file.
+type file struct {
+	Tenants []Tenant `json:"tenants"`
+}
+
+// Registry authenticates clients and hands out their scoped clients.
+type Registry struct {
+	tenants []Tenant
+	clients map[string]*kubernetes.Client
+}
+
+// Load reads the tenants file at path and creates an impersonating client
+// for each tenant.
+func Load(path string, base *kubernetes.Client) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+
+	var f file
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants file: %w", err)
+	}
+
+	r := &Registry{clients: map[string]*kubernetes.Client{}}
+	for i, t := range f.Tenants {
+		if t.Name == "" || t.TokenSHA256 == "" || t.ServiceAccount == "" || t.Namespace == "" {
+			return nil, fmt.Errorf("tenant %d: name, tokenSHA256, serviceAccount and namespace are required", i)
+		}
+		if _, ok := r.clients[t.Name]; ok {
+			return nil, fmt.Errorf("tenant %q is defined more than once", t.Name)
+		}
+		if t.ServiceAccountNamespace == "" {
+			t.ServiceAccountNamespace = t.Namespace
+		}
+		t.TokenSHA256 = strings.ToLower(t.TokenSHA256)
+
+		client, err := base.Impersonate(t.ServiceAccountNamespace, t.ServiceAccount, t.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %q: %w", t.Name, err)
+		}
+		r.tenants = append(r.tenants, t)
+		r.clients[t.Name] = client
+	}
+	return r, nil
+}
+
+// Authenticate returns the tenant owning the bearer token.
+func (r *Registry) Authenticate(token string) (*Tenant, bool) {
+	if token == "" {
+		return nil, false
+	}
+	sum := sha256.Sum256([]byte(token))
+	digest := []byte(hex.EncodeToString(sum[:]))
+
+	for i := range r.tenants {
+		if subtle.ConstantTimeCompare(digest, []byte(r.tenants[i].TokenSHA256)) == 1 {
+			return &r.tenants[i], true
+		}
+	}
+	return nil, false
+}
+
+// Bind authenticates an HTTP Authorization header ("Bearer <token>") and
+// returns ctx bound to the owning tenant.
+func (r *Registry) Bind(ctx context.Context, authorization string) (context.Context, bool) {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return ctx, false
+	}
+	t, ok := r.Authenticate(strings.TrimSpace(token))
+	if !ok {
+		return ctx, false
+	}
+	return WithTenant(ctx, t), true
+}
+
+// Client returns the impersonating client of a tenant.
+func (r *Registry) Client(t *Tenant) *kubernetes.Client {
+	return r.clients[t.Name]
+}
+
+type contextKey struct{}
+
+// WithTenant returns a context carrying the session's tenant.
+func WithTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the session's tenant, if the session is bound to one.
+func FromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(contextKey{}).(*Tenant)
+	return t, ok && t != nil
+}
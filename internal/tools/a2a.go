@@ -7,11 +7,17 @@ import (
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
 
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
 	"github.com/kagent-dev/meta-kagent/pkg/types"
 )
 
+// agentInputs are the inputs of tools derived only from Agents.
+var agentInputs = []schema.GroupVersionResource{kubernetes.AgentGVR}
+
 // registerListAgentSkills registers the list_agent_skills tool.
 func (ts *ToolServer) registerListAgentSkills() {
 	tool := mcp.NewTool("list_agent_skills",
@@ -22,16 +28,23 @@ func (ts *ToolServer) registerListAgentSkills() {
 		mcp.WithString("tag",
 			mcp.Description("Filter skills by tag (e.g., 'monitoring', 'kubernetes')"),
 		),
+		withLimitOption(),
+		withContinueOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleListAgentSkills)
+	ts.addTool(tool, ts.withResultCache("list_agent_skills", agentInputs, ts.handleListAgentSkills))
 }
 
 func (ts *ToolServer) handleListAgentSkills(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	agentName, _ := req.Params.Arguments["agent_name"].(string)
-	tag, _ := req.Params.Arguments["tag"].(string)
+	args := params.From(req)
+	agentName := args.String("agent_name")
+	tag := args.String("tag")
+	p := ts.pageFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	agents, err := ts.k8sClient.ListAgents(ctx)
+	agents, err := ts.kube(ctx).ListAgents(ctx)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to list agents: %v", err)), nil
 	}
@@ -94,8 +107,13 @@ func (ts *ToolServer) handleListAgentSkills(ctx context.Context, req mcp.CallToo
 		return mcp.NewToolResultText("No A2A skills found in any agents."), nil
 	}
 
+	results, next, err := paginate(results, p)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	output, _ := json.MarshalIndent(results, "", "  ")
-	return mcp.NewToolResultText(string(output)), nil
+	return mcp.NewToolResultText(string(output) + continueNote(len(results), next)), nil
 }
 
 // registerDiscoverA2AAgents registers the discover_a2a_agents tool.
@@ -107,13 +125,17 @@ func (ts *ToolServer) registerDiscoverA2AAgents() {
 		),
 	)
 
-	ts.server.AddTool(tool, ts.handleDiscoverA2AAgents)
+	ts.addTool(tool, ts.withResultCache("discover_a2a_agents", agentInputs, ts.handleDiscoverA2AAgents))
 }
 
 func (ts *ToolServer) handleDiscoverA2AAgents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	skillTag, _ := req.Params.Arguments["skill_tag"].(string)
+	args := params.From(req)
+	skillTag := args.String("skill_tag")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	agents, err := ts.k8sClient.ListAgents(ctx)
+	agents, err := ts.kube(ctx).ListAgents(ctx)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to list agents: %v", err)), nil
 	}
@@ -191,46 +213,74 @@ func (ts *ToolServer) registerGetAgentCard() {
 			mcp.Description("Name of the agent to generate the Agent Card for"),
 		),
 		mcp.WithString("endpoint_url",
-			mcp.Description("Custom endpoint URL for the agent (defaults to Kubernetes service URL: http://<name>.<namespace>.svc.cluster.local)"),
+			mcp.Description("Custom endpoint URL for the agent, where cluster facts such as {{ingress_domain}} are substituted (defaults to the environment's URL template, or without environments to the Kubernetes service URL: http://<name>.<namespace>.svc.cluster.local)"),
 		),
+		withEnvironmentOption(),
 		mcp.WithString("output_format",
 			mcp.Description("Output format: 'json' (default) or 'yaml'"),
 		),
 	)
 
-	ts.server.AddTool(tool, ts.handleGetAgentCard)
+	ts.addTool(tool, ts.handleGetAgentCard)
 }
 
 func (ts *ToolServer) handleGetAgentCard(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name, ok := req.Params.Arguments["name"].(string)
-	if !ok || name == "" {
-		return mcp.NewToolResultError("name is required"), nil
-	}
-
-	endpointURL, _ := req.Params.Arguments["endpoint_url"].(string)
-	format := "json"
-	if v, ok := req.Params.Arguments["output_format"].(string); ok && v != "" {
-		format = v
+	args := params.From(req)
+	name := args.RequiredString("name")
+	endpointURL := args.String("endpoint_url")
+	environment := args.String("environment")
+	format := args.Enum("output_format", "json", "json", "yaml")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	env, err := ts.environments.Lookup(environment)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	agent, err := ts.k8sClient.GetAgent(ctx, name)
+	agent, err := ts.kube(ctx).GetAgent(ctx, name)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
 	}
 
-	// Generate default endpoint URL from Kubernetes service naming
-	if endpointURL == "" {
-		namespace := agent.Namespace
-		if namespace == "" {
-			namespace = "kagent"
-		}
-		endpointURL = fmt.Sprintf("http://%s.%s.svc.cluster.local", name, namespace)
+	if err := ts.expandFacts(ctx, &endpointURL); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid endpoint_url: %v", err)), nil
 	}
+	endpointURL, warning := ts.agentEndpoint(ctx, env, agent, endpointURL)
+	card := buildAgentCard(agent, endpointURL)
 
-	// Build Agent Card
+	var output []byte
+	if format == "yaml" {
+		output, _ = yaml.Marshal(card)
+	} else {
+		output, _ = json.MarshalIndent(card, "", "  ")
+	}
+
+	result := fmt.Sprintf(`# A2A Agent Card for '%s'
+# This Agent Card can be published for A2A discovery.
+# URL: %s`, name, endpointURL)
+	if env != nil {
+		result += "\n# Environment: " + env.Name
+	}
+	if warning != "" {
+		result += "\n# WARNING: " + warning
+	}
+	result += "\n\n" + string(output)
+
+	return mcp.NewToolResultText(result), nil
+}
+
+// defaultAgentEndpoint is an agent's URL from Kubernetes service naming.
+func defaultAgentEndpoint(agent *types.Agent) string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local", agent.Name, agentNamespace(agent))
+}
+
+// buildAgentCard generates the A2A Agent Card of an agent served at
+// endpointURL.
+func buildAgentCard(agent *types.Agent, endpointURL string) types.AgentCard {
 	card := types.AgentCard{
-		AgentID:          name,
-		Name:             name,
+		AgentID:          agent.Name,
+		Name:             agent.Name,
 		Description:      agent.Spec.Description,
 		URL:              endpointURL,
 		ProtocolVersions: []string{"1.0"},
@@ -241,36 +291,15 @@ func (ts *ToolServer) handleGetAgentCard(ctx context.Context, req mcp.CallToolRe
 			Streaming:         false,
 			PushNotifications: false,
 		},
-		SecuritySchemes: map[string]types.SecurityScheme{
-			"bearerAuth": {
-				Type:        "http",
-				Scheme:      "bearer",
-				Description: "Bearer token authentication",
-			},
-		},
-		Security: []string{"bearerAuth"},
 	}
+	card.SecuritySchemes, card.Security = a2aSecurityFrom(agent.GetAnnotations()).cardSchemes()
 
 	// Add skills if present
 	a2aConfig := getA2AConfig(agent)
 	if a2aConfig != nil && len(a2aConfig.Skills) > 0 {
 		card.Skills = a2aConfig.Skills
 	}
-
-	var output []byte
-	if format == "yaml" {
-		output, _ = yaml.Marshal(card)
-	} else {
-		output, _ = json.MarshalIndent(card, "", "  ")
-	}
-
-	result := fmt.Sprintf(`# A2A Agent Card for '%s'
-# This Agent Card can be published for A2A discovery.
-# URL: %s
-
-%s`, name, endpointURL, string(output))
-
-	return mcp.NewToolResultText(result), nil
+	return card
 }
 
 // registerCreateSkillManifest registers the create_skill_manifest tool.
@@ -303,20 +332,20 @@ func (ts *ToolServer) registerCreateSkillManifest() {
 		),
 	)
 
-	ts.server.AddTool(tool, ts.handleCreateSkillManifest)
+	ts.addTool(tool, ts.handleCreateSkillManifest)
 }
 
 func (ts *ToolServer) handleCreateSkillManifest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	id, _ := req.Params.Arguments["id"].(string)
-	name, _ := req.Params.Arguments["name"].(string)
-	description, _ := req.Params.Arguments["description"].(string)
-	inputModes, _ := req.Params.Arguments["input_modes"].(string)
-	outputModes, _ := req.Params.Arguments["output_modes"].(string)
-	tags, _ := req.Params.Arguments["tags"].(string)
-	examples, _ := req.Params.Arguments["examples"].(string)
-
-	if id == "" || name == "" || description == "" {
-		return mcp.NewToolResultError("id, name, and description are required"), nil
+	args := params.From(req)
+	id := args.RequiredString("id")
+	name := args.RequiredString("name")
+	description := args.RequiredString("description")
+	inputModes := args.StringList("input_modes")
+	outputModes := args.StringList("output_modes")
+	tags := args.StringList("tags")
+	examples := args.StringList("examples")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	skill := types.Skill{
@@ -325,29 +354,18 @@ func (ts *ToolServer) handleCreateSkillManifest(ctx context.Context, req mcp.Cal
 		Description: description,
 	}
 
-	// Parse input modes
-	if inputModes != "" {
-		skill.InputModes = splitAndTrim(inputModes)
-	} else {
+	// Default input and output modes to plain text
+	skill.InputModes = inputModes
+	if len(skill.InputModes) == 0 {
 		skill.InputModes = []string{"text/plain"}
 	}
-
-	// Parse output modes
-	if outputModes != "" {
-		skill.OutputModes = splitAndTrim(outputModes)
-	} else {
+	skill.OutputModes = outputModes
+	if len(skill.OutputModes) == 0 {
 		skill.OutputModes = []string{"text/plain"}
 	}
 
-	// Parse tags
-	if tags != "" {
-		skill.Tags = splitAndTrim(tags)
-	}
-
-	// Parse examples
-	if examples != "" {
-		skill.Examples = splitAndTrim(examples)
-	}
+	skill.Tags = tags
+	skill.Examples = examples
 
 	output, _ := yaml.Marshal(skill)
 
@@ -374,20 +392,19 @@ func (ts *ToolServer) registerValidateSkill() {
 		mcp.WithBoolean("strict",
 			mcp.Description("Enable strict validation including best practice checks (default: true)"),
 		),
+		withStructuredOutputOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleValidateSkill)
+	ts.addTool(tool, ts.handleValidateSkill)
 }
 
 func (ts *ToolServer) handleValidateSkill(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	skillJSON, _ := req.Params.Arguments["skill_json"].(string)
-	if skillJSON == "" {
-		return mcp.NewToolResultError("skill_json is required"), nil
-	}
-
-	strict := true
-	if v, ok := req.Params.Arguments["strict"].(bool); ok {
-		strict = v
+	args := params.From(req)
+	skillJSON := args.RequiredString("skill_json")
+	strict := args.Bool("strict", true)
+	asStructured := structuredOutputFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	var skill types.Skill
@@ -395,31 +412,80 @@ func (ts *ToolServer) handleValidateSkill(ctx context.Context, req mcp.CallToolR
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid JSON: %v", err)), nil
 	}
 
-	type issue struct {
-		Severity string `json:"severity"` // "error" or "warning"
-		Field    string `json:"field"`
-		Message  string `json:"message"`
+	issues := validateSkill(skill, strict)
+
+	// Count errors
+	errorCount := 0
+	warningCount := 0
+	for _, i := range issues {
+		if i.Severity == "error" {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+
+	if asStructured {
+		r := structuredResult{Summary: "Skill validation passed.", Issues: issues}
+		for _, i := range issues {
+			message := fmt.Sprintf("[%s]: %s", i.Field, i.Message)
+			if i.Severity == "error" {
+				r.Errors = append(r.Errors, message)
+			} else {
+				r.Warnings = append(r.Warnings, message)
+			}
+		}
+		valid := errorCount == 0
+		r.Valid = &valid
+		if len(issues) > 0 {
+			r.Summary = fmt.Sprintf("Skill validation found %d error(s) and %d warning(s).", errorCount, warningCount)
+		}
+		return structured(r)
 	}
 
-	var issues []issue
+	if len(issues) == 0 {
+		return mcp.NewToolResultText("✓ Skill validation passed. No issues found."), nil
+	}
+
+	output, _ := json.MarshalIndent(issues, "", "  ")
+	summary := fmt.Sprintf("# Skill Validation Results\n# Errors: %d, Warnings: %d\n\n%s", errorCount, warningCount, string(output))
+
+	if errorCount > 0 {
+		return mcp.NewToolResultText(summary + "\n\n⚠ Validation failed with errors. Fix the errors before using this skill."), nil
+	}
+
+	return mcp.NewToolResultText(summary + "\n\n✓ Validation passed with warnings. Consider addressing the warnings."), nil
+}
+
+// SkillIssue is a problem found in an A2A skill definition.
+type SkillIssue struct {
+	Severity string `json:"severity"` // "error" or "warning"
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+}
+
+// validateSkill checks the required fields of a skill and, when strict,
+// the best practices that make it discoverable.
+func validateSkill(skill types.Skill, strict bool) []SkillIssue {
+	var issues []SkillIssue
 
 	// Required field validation
 	if skill.ID == "" {
-		issues = append(issues, issue{
+		issues = append(issues, SkillIssue{
 			Severity: "error",
 			Field:    "id",
 			Message:  "skill id is required",
 		})
 	}
 	if skill.Name == "" {
-		issues = append(issues, issue{
+		issues = append(issues, SkillIssue{
 			Severity: "error",
 			Field:    "name",
 			Message:  "skill name is required",
 		})
 	}
 	if skill.Description == "" {
-		issues = append(issues, issue{
+		issues = append(issues, SkillIssue{
 			Severity: "error",
 			Field:    "description",
 			Message:  "skill description is required",
@@ -429,65 +495,42 @@ func (ts *ToolServer) handleValidateSkill(ctx context.Context, req mcp.CallToolR
 	// Strict validation (best practices)
 	if strict {
 		if len(skill.Description) < 20 {
-			issues = append(issues, issue{
+			issues = append(issues, SkillIssue{
 				Severity: "warning",
 				Field:    "description",
 				Message:  "description is short; consider providing more detail for A2A discovery",
 			})
 		}
 		if len(skill.Examples) == 0 {
-			issues = append(issues, issue{
+			issues = append(issues, SkillIssue{
 				Severity: "warning",
 				Field:    "examples",
 				Message:  "consider adding examples to help other agents understand how to use this skill",
 			})
 		}
 		if len(skill.Tags) == 0 {
-			issues = append(issues, issue{
+			issues = append(issues, SkillIssue{
 				Severity: "warning",
 				Field:    "tags",
 				Message:  "consider adding tags to improve skill discoverability",
 			})
 		}
 		if len(skill.InputModes) == 0 {
-			issues = append(issues, issue{
+			issues = append(issues, SkillIssue{
 				Severity: "warning",
 				Field:    "inputModes",
 				Message:  "consider specifying input modes (e.g., 'text/plain', 'application/json')",
 			})
 		}
 		if len(skill.OutputModes) == 0 {
-			issues = append(issues, issue{
+			issues = append(issues, SkillIssue{
 				Severity: "warning",
 				Field:    "outputModes",
 				Message:  "consider specifying output modes",
 			})
 		}
 	}
-
-	// Count errors
-	errorCount := 0
-	warningCount := 0
-	for _, i := range issues {
-		if i.Severity == "error" {
-			errorCount++
-		} else {
-			warningCount++
-		}
-	}
-
-	if len(issues) == 0 {
-		return mcp.NewToolResultText("✓ Skill validation passed. No issues found."), nil
-	}
-
-	output, _ := json.MarshalIndent(issues, "", "  ")
-	summary := fmt.Sprintf("# Skill Validation Results\n# Errors: %d, Warnings: %d\n\n%s", errorCount, warningCount, string(output))
-
-	if errorCount > 0 {
-		return mcp.NewToolResultText(summary + "\n\n⚠ Validation failed with errors. Fix the errors before using this skill."), nil
-	}
-
-	return mcp.NewToolResultText(summary + "\n\n✓ Validation passed with warnings. Consider addressing the warnings."), nil
+	return issues
 }
 
 // registerAddSkillToAgent registers the add_skill_to_agent tool.
@@ -502,17 +545,20 @@ func (ts *ToolServer) registerAddSkillToAgent() {
 			mcp.Required(),
 			mcp.Description("JSON representation of the skill to add"),
 		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleAddSkillToAgent)
+	ts.addTool(tool, ts.handleAddSkillToAgent)
 }
 
 func (ts *ToolServer) handleAddSkillToAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	agentName, _ := req.Params.Arguments["agent_name"].(string)
-	skillJSON, _ := req.Params.Arguments["skill_json"].(string)
-
-	if agentName == "" || skillJSON == "" {
-		return mcp.NewToolResultError("agent_name and skill_json are required"), nil
+	args := params.From(req)
+	agentName := args.RequiredString("agent_name")
+	skillJSON := args.RequiredString("skill_json")
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	// Parse skill
@@ -527,7 +573,7 @@ func (ts *ToolServer) handleAddSkillToAgent(ctx context.Context, req mcp.CallToo
 	}
 
 	// Get existing agent
-	agent, err := ts.k8sClient.GetAgent(ctx, agentName)
+	agent, err := ts.kube(ctx).GetAgent(ctx, agentName)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
 	}
@@ -555,14 +601,12 @@ func (ts *ToolServer) handleAddSkillToAgent(ctx context.Context, req mcp.CallToo
 
 	output, _ := yaml.Marshal(agent)
 
-	result := fmt.Sprintf(`# Updated Agent Manifest
+	header := fmt.Sprintf(`# Updated Agent Manifest
 # IMPORTANT: Review the changes before applying.
 # The skill '%s' has been added to the agent's a2aConfig.
-# Use diff_manifest to see changes, then apply_manifest to deploy.
-
-%s`, skill.Name, string(output))
+# Use diff_manifest to see changes, then apply_manifest to deploy.`, skill.Name)
 
-	return mcp.NewToolResultText(result), nil
+	return out.render(header, string(output))
 }
 
 // registerRemoveSkillFromAgent registers the remove_skill_from_agent tool.
@@ -577,21 +621,24 @@ func (ts *ToolServer) registerRemoveSkillFromAgent() {
 			mcp.Required(),
 			mcp.Description("ID of the skill to remove"),
 		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleRemoveSkillFromAgent)
+	ts.addTool(tool, ts.handleRemoveSkillFromAgent)
 }
 
 func (ts *ToolServer) handleRemoveSkillFromAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	agentName, _ := req.Params.Arguments["agent_name"].(string)
-	skillID, _ := req.Params.Arguments["skill_id"].(string)
-
-	if agentName == "" || skillID == "" {
-		return mcp.NewToolResultError("agent_name and skill_id are required"), nil
+	args := params.From(req)
+	agentName := args.RequiredString("agent_name")
+	skillID := args.RequiredString("skill_id")
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	// Get existing agent
-	agent, err := ts.k8sClient.GetAgent(ctx, agentName)
+	agent, err := ts.kube(ctx).GetAgent(ctx, agentName)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
 	}
@@ -625,14 +672,12 @@ func (ts *ToolServer) handleRemoveSkillFromAgent(ctx context.Context, req mcp.Ca
 
 	output, _ := yaml.Marshal(agent)
 
-	result := fmt.Sprintf(`# Updated Agent Manifest
+	header := fmt.Sprintf(`# Updated Agent Manifest
 # IMPORTANT: Review the changes before applying.
 # The skill '%s' has been removed from the agent's a2aConfig.
-# Use diff_manifest to see changes, then apply_manifest to deploy.
-
-%s`, skillID, string(output))
+# Use diff_manifest to see changes, then apply_manifest to deploy.`, skillID)
 
-	return mcp.NewToolResultText(result), nil
+	return out.render(header, string(output))
 }
 
 // Helper functions
@@ -656,18 +701,6 @@ func setA2AConfig(agent *types.Agent, config *types.A2AConfig) {
 	agent.Spec.Declarative.A2AConfig = config
 }
 
-func splitAndTrim(s string) []string {
-	parts := strings.Split(s, ",")
-	var result []string
-	for _, p := range parts {
-		trimmed := strings.TrimSpace(p)
-		if trimmed != "" {
-			result = append(result, trimmed)
-		}
-	}
-	return result
-}
-
 func mustJSON(v interface{}) string {
 	b, _ := json.Marshal(v)
 	return string(b)
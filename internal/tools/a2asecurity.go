@@ -0,0 +1,429 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/capabilities"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// A2A security schemes an agent endpoint can require.
+const (
+	SecurityNone   = "none"
+	SecurityBearer = "bearer"
+	SecurityOAuth2 = "oauth2"
+	SecurityMTLS   = "mtls"
+)
+
+// Annotations carrying A2A security configuration. The Agent CRD has no
+// fields for it, so it is kept alongside the agent as metadata.
+const (
+	annotationA2AScheme   = "a2a.kagent.dev/security-scheme"
+	annotationA2ATokenURL = "a2a.kagent.dev/token-url"
+	annotationA2AScopes   = "a2a.kagent.dev/scopes"
+	annotationA2ASecret   = "a2a.kagent.dev/secret"
+	// annotationA2ACredentialsPrefix is followed by the name of an agent the
+	// annotated agent calls; the value names the Secret with its credentials.
+	annotationA2ACredentialsPrefix = "a2a.kagent.dev/credentials-"
+)
+
+// serverSecretKeys are the keys an agent's own A2A secret must hold.
+var serverSecretKeys = map[string][]string{
+	SecurityBearer: {"token"},
+	SecurityMTLS:   {"ca.crt", "tls.crt", "tls.key"},
+}
+
+// clientSecretKeys are the keys a consumer's credentials secret must hold.
+var clientSecretKeys = map[string][]string{
+	SecurityBearer: {"token"},
+	SecurityOAuth2: {"client-id", "client-secret"},
+	SecurityMTLS:   {"tls.crt", "tls.key"},
+}
+
+// a2aSecurity is an agent's A2A security configuration.
+type a2aSecurity struct {
+	Scheme   string
+	TokenURL string
+	Scopes   []string
+	Secret   string
+}
+
+// a2aSecurityFrom reads the security configuration from agent annotations.
+// Scheme is empty when the agent has none configured.
+func a2aSecurityFrom(annotations map[string]string) a2aSecurity {
+	sec := a2aSecurity{
+		Scheme:   annotations[annotationA2AScheme],
+		TokenURL: annotations[annotationA2ATokenURL],
+		Secret:   annotations[annotationA2ASecret],
+	}
+	for _, scope := range strings.Split(annotations[annotationA2AScopes], ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			sec.Scopes = append(sec.Scopes, scope)
+		}
+	}
+	return sec
+}
+
+// annotations returns the annotations that record the configuration.
+func (s a2aSecurity) annotations() map[string]string {
+	annotations := map[string]string{annotationA2AScheme: s.Scheme}
+	if s.TokenURL != "" {
+		annotations[annotationA2ATokenURL] = s.TokenURL
+	}
+	if len(s.Scopes) > 0 {
+		annotations[annotationA2AScopes] = strings.Join(s.Scopes, ",")
+	}
+	if s.Secret != "" {
+		annotations[annotationA2ASecret] = s.Secret
+	}
+	return annotations
+}
+
+// cardSchemes returns the security schemes and requirements advertised in
+// the agent card. Agents without configuration advertise bearer
+// authentication, as kagent endpoints accept it by default.
+func (s a2aSecurity) cardSchemes() (map[string]types.SecurityScheme, []string) {
+	switch s.Scheme {
+	case SecurityNone:
+		return nil, nil
+	case SecurityOAuth2:
+		scopes := make(map[string]string, len(s.Scopes))
+		for _, scope := range s.Scopes {
+			scopes[scope] = ""
+		}
+		return map[string]types.SecurityScheme{
+			"oauth2": {
+				Type:        "oauth2",
+				Flows:       &types.OAuthFlows{ClientCredentials: &types.OAuthFlow{TokenURL: s.TokenURL, Scopes: scopes}},
+				Description: "OAuth2 client credentials",
+			},
+		}, []string{"oauth2"}
+	case SecurityMTLS:
+		return map[string]types.SecurityScheme{
+			"mtls": {
+				Type:        "mutualTLS",
+				Description: "Mutual TLS with a client certificate",
+			},
+		}, []string{"mtls"}
+	default:
+		return map[string]types.SecurityScheme{
+			"bearerAuth": {
+				Type:        "http",
+				Scheme:      "bearer",
+				Description: "Bearer token authentication",
+			},
+		}, []string{"bearerAuth"}
+	}
+}
+
+// registerConfigureA2ASecurity registers the configure_a2a_security tool.
+func (ts *ToolServer) registerConfigureA2ASecurity() {
+	tool := mcp.NewTool("configure_a2a_security",
+		mcp.WithDescription("Configure how an agent's A2A endpoint authenticates callers (none, bearer, oauth2, mtls). Returns the updated agent manifest with the security settings, the Secret or cert-manager Certificate it relies on, and credential references for consumer agents. The agent card (get_agent_card) advertises the configured scheme."),
+		mcp.WithString("agent_name",
+			mcp.Required(),
+			mcp.Description("Name of the agent whose A2A endpoint is secured"),
+		),
+		mcp.WithString("scheme",
+			mcp.Required(),
+			mcp.Description("Security scheme: 'none', 'bearer', 'oauth2' (client credentials), or 'mtls'"),
+		),
+		mcp.WithString("token_url",
+			mcp.Description("OAuth2 token endpoint (required for oauth2; must be https)"),
+		),
+		mcp.WithString("scopes",
+			mcp.Description("Comma-separated OAuth2 scopes callers must request"),
+		),
+		mcp.WithString("secret_name",
+			mcp.Description("Secret holding the agent's bearer token or TLS material (default: '<agent>-a2a-<scheme>')"),
+		),
+		mcp.WithString("issuer",
+			mcp.Description("cert-manager ClusterIssuer for mtls certificates (default: 'kagent-ca')"),
+		),
+		mcp.WithString("consumer_agents",
+			mcp.Description("Comma-separated agents that call this agent; their manifests are updated to reference a credentials Secret '<consumer>-<agent>-a2a'"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleConfigureA2ASecurity)
+}
+
+func (ts *ToolServer) handleConfigureA2ASecurity(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	agentName := args.RequiredString("agent_name")
+	scheme := args.RequiredEnum("scheme", SecurityNone, SecurityBearer, SecurityOAuth2, SecurityMTLS)
+	tokenURL := args.String("token_url")
+	scopes := args.StringList("scopes")
+	secretName := args.String("secret_name")
+	issuer := args.StringDefault("issuer", "kagent-ca")
+	consumers := args.StringList("consumer_agents")
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if scheme == SecurityOAuth2 {
+		if err := checkTokenURL(tokenURL); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	if _, ok := serverSecretKeys[scheme]; ok && secretName == "" {
+		secretName = fmt.Sprintf("%s-a2a-%s", agentName, scheme)
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, agentName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	sec := a2aSecurity{Scheme: scheme}
+	if scheme == SecurityOAuth2 {
+		sec.TokenURL = tokenURL
+		sec.Scopes = scopes
+	}
+	if _, ok := serverSecretKeys[scheme]; ok {
+		sec.Secret = secretName
+	}
+
+	// Replace any previous security configuration
+	annotations := agent.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for _, key := range []string{annotationA2AScheme, annotationA2ATokenURL, annotationA2AScopes, annotationA2ASecret} {
+		delete(annotations, key)
+	}
+	for k, v := range sec.annotations() {
+		annotations[k] = v
+	}
+	agent.SetAnnotations(annotations)
+	agent.APIVersion = "kagent.dev/v1alpha2"
+	agent.Kind = "Agent"
+
+	output, _ := yaml.Marshal(agent)
+	docs := []string{string(output)}
+	notes := []string{fmt.Sprintf("# Agent '%s' will require '%s' for A2A calls.", agentName, scheme)}
+
+	switch scheme {
+	case SecurityBearer:
+		notes = append(notes, fmt.Sprintf("# Create the token Secret without putting the token in a manifest:\n#   kubectl create secret generic %s -n %s --from-literal=token=<token>", secretName, agent.Namespace))
+	case SecurityMTLS:
+		docs = append(docs, certificateManifest(agentName, agent.Namespace, secretName, issuer, false))
+		notes = append(notes, fmt.Sprintf("# The cert-manager Certificate issues '%s' from ClusterIssuer '%s'.", secretName, issuer))
+		if !ts.hasCapability(capabilities.CertManager) {
+			notes = append(notes, "# WARNING: cert-manager is not installed in this cluster; the Certificates cannot be issued until it is (see list_unavailable_tools).")
+		}
+	}
+
+	// Point each consumer at its own credentials secret
+	for _, consumerName := range consumers {
+		consumer, err := ts.kube(ctx).GetAgent(ctx, consumerName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get consumer agent: %v", err)), nil
+		}
+		credentials := fmt.Sprintf("%s-%s-a2a", consumerName, agentName)
+
+		consumerAnnotations := consumer.GetAnnotations()
+		if consumerAnnotations == nil {
+			consumerAnnotations = map[string]string{}
+		}
+		if scheme == SecurityNone {
+			delete(consumerAnnotations, annotationA2ACredentialsPrefix+agentName)
+		} else {
+			consumerAnnotations[annotationA2ACredentialsPrefix+agentName] = credentials
+		}
+		consumer.SetAnnotations(consumerAnnotations)
+		consumer.APIVersion = "kagent.dev/v1alpha2"
+		consumer.Kind = "Agent"
+
+		output, _ := yaml.Marshal(consumer)
+		docs = append(docs, string(output))
+
+		switch scheme {
+		case SecurityMTLS:
+			docs = append(docs, certificateManifest(consumerName, consumer.Namespace, credentials, issuer, true))
+		case SecurityBearer, SecurityOAuth2:
+			notes = append(notes, fmt.Sprintf("# Consumer '%s' needs Secret '%s' with keys: %s", consumerName, credentials, strings.Join(clientSecretKeys[scheme], ", ")))
+		}
+	}
+
+	header := fmt.Sprintf(`# A2A Security Configuration for '%s'
+%s
+# Use validate_manifest to check that secrets and consumer credentials exist, then apply_manifest to deploy.`, agentName, strings.Join(notes, "\n"))
+
+	return out.render(header, strings.Join(docs, "---\n"))
+}
+
+// certificateManifest returns a cert-manager Certificate for an agent's A2A
+// server or client certificate.
+func certificateManifest(agentName, namespace, secretName, issuer string, client bool) string {
+	usage := "server auth"
+	dnsNames := fmt.Sprintf(`
+  dnsNames:
+    - %s
+    - %s.%s.svc
+    - %s.%s.svc.cluster.local`, agentName, agentName, namespace, agentName, namespace)
+	if client {
+		usage = "client auth"
+		dnsNames = ""
+	}
+
+	return fmt.Sprintf(`apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    app.kubernetes.io/name: %s
+    app.kubernetes.io/component: a2a-security
+spec:
+  secretName: %s
+  commonName: %s%s
+  usages:
+    - %s
+  issuerRef:
+    kind: ClusterIssuer
+    name: %s
+`, secretName, namespace, agentName, secretName, agentName, dnsNames, usage, issuer)
+}
+
+// checkTokenURL verifies an OAuth2 token endpoint.
+func checkTokenURL(tokenURL string) error {
+	if tokenURL == "" {
+		return fmt.Errorf("token_url is required for oauth2")
+	}
+	u, err := url.Parse(tokenURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("token_url '%s' is not a valid URL", tokenURL)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("token_url must use https (got '%s')", u.Scheme)
+	}
+	return nil
+}
+
+// validateA2ASecurity checks an agent's own security configuration and,
+// for every agent it calls, that it holds matching credentials.
+func (ts *ToolServer) validateA2ASecurity(ctx context.Context, obj *unstructured.Unstructured) []ValidationIssue {
+	var issues []ValidationIssue
+	annotations := obj.GetAnnotations()
+
+	sec := a2aSecurityFrom(annotations)
+	switch sec.Scheme {
+	case "", SecurityNone:
+	case SecurityBearer, SecurityOAuth2, SecurityMTLS:
+		if sec.Scheme == SecurityOAuth2 {
+			if err := checkTokenURL(sec.TokenURL); err != nil {
+				issues = append(issues, ValidationIssue{
+					Severity: "error",
+					Field:    "metadata.annotations." + annotationA2ATokenURL,
+					Message:  err.Error(),
+				})
+			}
+		}
+		if keys, ok := serverSecretKeys[sec.Scheme]; ok {
+			issues = append(issues, ts.checkSecretHasKeys(ctx, "metadata.annotations."+annotationA2ASecret, sec.Secret, keys)...)
+		}
+	default:
+		issues = append(issues, ValidationIssue{
+			Severity: "error",
+			Field:    "metadata.annotations." + annotationA2AScheme,
+			Message:  fmt.Sprintf("Unknown A2A security scheme '%s'. Must be one of: none, bearer, oauth2, mtls", sec.Scheme),
+			Fix:      []PatchOperation{{Op: "replace", Path: "/metadata/annotations/" + jsonPointerEscape(annotationA2AScheme), Value: SecurityBearer}},
+		})
+	}
+
+	// Agents used as tools must be called with credentials they accept
+	tools, _, _ := unstructured.NestedSlice(obj.Object, "spec", "declarative", "tools")
+	for i, t := range tools {
+		tool, _ := t.(map[string]interface{})
+		target, _, _ := unstructured.NestedString(tool, "agent", "name")
+		if target == "" {
+			continue
+		}
+
+		targetAgent, err := ts.kube(ctx).GetAgent(ctx, target)
+		if err != nil {
+			issues = append(issues, ValidationIssue{
+				Severity: "warning",
+				Field:    fmt.Sprintf("spec.declarative.tools[%d].agent.name", i),
+				Message:  fmt.Sprintf("Agent '%s' not found; cannot check A2A credentials", target),
+			})
+			continue
+		}
+
+		required := a2aSecurityFrom(targetAgent.GetAnnotations()).Scheme
+		keys, needsCredentials := clientSecretKeys[required]
+		if !needsCredentials {
+			continue
+		}
+
+		field := "metadata.annotations." + annotationA2ACredentialsPrefix + target
+		credentials := annotations[annotationA2ACredentialsPrefix+target]
+		if credentials == "" {
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    field,
+				Message:  fmt.Sprintf("Agent '%s' requires %s for A2A calls, but no credentials Secret is configured. Use configure_a2a_security with consumer_agents to wire one.", target, required),
+			})
+			continue
+		}
+		issues = append(issues, ts.checkSecretHasKeys(ctx, field, credentials, keys)...)
+	}
+
+	return issues
+}
+
+// checkSecretHasKeys reports a missing secret or missing keys. Values are
+// never read.
+func (ts *ToolServer) checkSecretHasKeys(ctx context.Context, field, secret string, required []string) []ValidationIssue {
+	if secret == "" {
+		return []ValidationIssue{{
+			Severity: "error",
+			Field:    field,
+			Message:  "a Secret is required for this security scheme",
+		}}
+	}
+
+	keys, found, known, err := ts.kube(ctx).SecretKeys(ctx, secret)
+	switch {
+	case err != nil:
+		return []ValidationIssue{{Severity: "warning", Field: field, Message: fmt.Sprintf("Could not verify secret '%s': %v", secret, err)}}
+	case !known:
+		return []ValidationIssue{{Severity: "warning", Field: field, Message: fmt.Sprintf("Could not verify secret '%s' (not allowed to read secrets)", secret)}}
+	case !found:
+		return []ValidationIssue{{Severity: "error", Field: field, Message: fmt.Sprintf("Secret '%s' does not exist", secret)}}
+	}
+
+	var missing []string
+	for _, key := range required {
+		if !containsString(keys, key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return []ValidationIssue{{
+		Severity: "error",
+		Field:    field,
+		Message:  fmt.Sprintf("Secret '%s' is missing key(s): %s", secret, strings.Join(missing, ", ")),
+	}}
+}
+
+// jsonPointerEscape escapes a map key for use in a JSON pointer path.
+func jsonPointerEscape(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
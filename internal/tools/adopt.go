@@ -0,0 +1,278 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// registerAdoptWorkload registers the adopt_workload tool.
+func (ts *ToolServer) registerAdoptWorkload() {
+	tool := mcp.NewTool("adopt_workload",
+		mcp.WithDescription("Inspect an existing Deployment running an MCP server or agent container and generate the MCPServer or BYO Agent manifest that brings it under kagent management. Image, command, args, env, ports and resources are mapped from the container spec."),
+		mcp.WithString("deployment",
+			mcp.Required(),
+			mcp.Description("Name of the Deployment to adopt"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Resource to generate: 'auto' (detect from labels, image and args), 'MCPServer', or 'Agent'. Default: 'auto'"),
+		),
+		mcp.WithString("container",
+			mcp.Description("Container to adopt when the pod has several (default: the first container)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name for the generated resource (default: the Deployment name)"),
+		),
+		mcp.WithString("description",
+			mcp.Description("Description for the generated resource"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleAdoptWorkload)
+}
+
+func (ts *ToolServer) handleAdoptWorkload(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	deploymentName := args.RequiredString("deployment")
+	kind := args.Enum("kind", "auto", "auto", "MCPServer", "Agent")
+	containerName := args.String("container")
+	name := args.StringDefault("name", deploymentName)
+	description := args.String("description")
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	deployment, err := ts.kube(ctx).GetResource(ctx, kubernetes.DeploymentGVR, deploymentName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get deployment '%s': %v", deploymentName, err)), nil
+	}
+
+	container, err := selectContainer(deployment, containerName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	workload := adoptedContainerFrom(container)
+	if kind == "auto" {
+		kind = "Agent"
+		if looksLikeMCPServer(deployment, workload) {
+			kind = "MCPServer"
+		}
+	}
+	if description == "" {
+		description = fmt.Sprintf("Adopted from Deployment '%s'", deploymentName)
+	}
+
+	notes := workload.notes
+	var output []byte
+	switch kind {
+	case "MCPServer":
+		server := types.MCPServer{
+			Spec: types.MCPServerSpec{
+				Description: description,
+				Deployment: &types.DeploymentSpec{
+					Image:     workload.image,
+					Cmd:       workload.cmd,
+					Args:      workload.args,
+					Port:      workload.port,
+					Env:       workload.env,
+					Resources: workload.resources,
+				},
+				TransportType:  "stdio",
+				StdioTransport: &types.StdioTransport{},
+			},
+		}
+		server.APIVersion = "kagent.dev/v1alpha1"
+		server.Kind = "MCPServer"
+		server.Name = name
+		server.Namespace = ts.kube(ctx).Namespace()
+		if workload.port != 0 {
+			notes = append(notes, fmt.Sprintf("The container listens on port %d. If the server speaks HTTP rather than stdio, keep the Deployment and register its Service with a RemoteMCPServer instead.", workload.port))
+		}
+		output, _ = yaml.Marshal(server)
+
+	case "Agent":
+		var replicas *int32
+		if r, found, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas"); found {
+			v := int32(r)
+			replicas = &v
+		}
+		agent := types.Agent{
+			Spec: types.AgentSpec{
+				Type:        "BYO",
+				Description: description,
+				BYO: &types.BYOSpec{
+					Deployment: &types.BYODeploymentSpec{
+						Image:     workload.image,
+						Cmd:       workload.cmd,
+						Args:      workload.args,
+						Replicas:  replicas,
+						Env:       workload.env,
+						Resources: workload.resources,
+					},
+				},
+			},
+		}
+		agent.APIVersion = "kagent.dev/v1alpha2"
+		agent.Kind = "Agent"
+		agent.Name = name
+		agent.Namespace = ts.kube(ctx).Namespace()
+		if workload.port != 0 {
+			notes = append(notes, fmt.Sprintf("The container listens on port %d; kagent manages the agent's Service, so make sure the image serves A2A on the port kagent expects.", workload.port))
+		}
+		output, _ = yaml.Marshal(agent)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated %s Manifest adopted from Deployment '%s' (container '%s')\n", kind, deploymentName, workload.name)
+	for _, note := range notes {
+		fmt.Fprintf(&b, "# NOTE: %s\n", note)
+	}
+	fmt.Fprintf(&b, "# kagent creates its own Deployment for this resource. Once it is healthy, scale down or delete '%s'.\n", deploymentName)
+	b.WriteString("# Use validate_manifest to check, then apply_manifest to deploy.")
+
+	return out.render(b.String(), string(output))
+}
+
+// selectContainer returns the named container from a Deployment's pod
+// template, or the first container when name is empty.
+func selectContainer(deployment *unstructured.Unstructured, name string) (map[string]interface{}, error) {
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("deployment '%s' has no containers", deployment.GetName())
+	}
+
+	var names []string
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		containerName, _, _ := unstructured.NestedString(container, "name")
+		if name == "" || containerName == name {
+			return container, nil
+		}
+		names = append(names, containerName)
+	}
+	return nil, fmt.Errorf("container '%s' not found in deployment '%s' (available: %s)", name, deployment.GetName(), strings.Join(names, ", "))
+}
+
+// adoptedContainer holds the fields mapped from a Deployment container.
+type adoptedContainer struct {
+	name      string
+	image     string
+	cmd       string
+	args      []string
+	port      int32
+	env       []types.EnvVar
+	resources *types.ResourceRequirements
+	notes     []string
+}
+
+// adoptedContainerFrom maps a container spec onto kagent deployment fields.
+// The first command element becomes cmd; the remaining command elements and
+// the container args become args.
+func adoptedContainerFrom(container map[string]interface{}) adoptedContainer {
+	var w adoptedContainer
+	w.name, _, _ = unstructured.NestedString(container, "name")
+	w.image, _, _ = unstructured.NestedString(container, "image")
+
+	command, _, _ := unstructured.NestedStringSlice(container, "command")
+	containerArgs, _, _ := unstructured.NestedStringSlice(container, "args")
+	if len(command) > 0 {
+		w.cmd = command[0]
+		w.args = append(w.args, command[1:]...)
+	}
+	w.args = append(w.args, containerArgs...)
+
+	ports, _, _ := unstructured.NestedSlice(container, "ports")
+	for i, p := range ports {
+		port, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		number, _, _ := unstructured.NestedInt64(port, "containerPort")
+		if i == 0 {
+			w.port = int32(number)
+		} else {
+			w.notes = append(w.notes, fmt.Sprintf("Additional container port %d is not mapped.", number))
+		}
+	}
+
+	env, _, _ := unstructured.NestedSlice(container, "env")
+	for _, e := range env {
+		item, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		envVar, note := adoptEnvVar(item)
+		if note != "" {
+			w.notes = append(w.notes, note)
+			continue
+		}
+		w.env = append(w.env, envVar)
+	}
+	if envFrom, _, _ := unstructured.NestedSlice(container, "envFrom"); len(envFrom) > 0 {
+		w.notes = append(w.notes, "envFrom sources are not mapped; add the variables to env explicitly.")
+	}
+
+	requests, _, _ := unstructured.NestedStringMap(container, "resources", "requests")
+	limits, _, _ := unstructured.NestedStringMap(container, "resources", "limits")
+	if len(requests) > 0 || len(limits) > 0 {
+		w.resources = &types.ResourceRequirements{Requests: requests, Limits: limits}
+	}
+
+	return w
+}
+
+// adoptEnvVar maps a container env entry. Sources kagent cannot express
+// (fieldRef, resourceFieldRef) are returned as a note instead.
+func adoptEnvVar(item map[string]interface{}) (types.EnvVar, string) {
+	name, _, _ := unstructured.NestedString(item, "name")
+	envVar := types.EnvVar{Name: name}
+
+	if value, found, _ := unstructured.NestedString(item, "value"); found {
+		envVar.Value = value
+		return envVar, ""
+	}
+	if _, found, _ := unstructured.NestedMap(item, "valueFrom"); !found {
+		return envVar, ""
+	}
+
+	if ref, found, _ := unstructured.NestedStringMap(item, "valueFrom", "secretKeyRef"); found {
+		envVar.ValueFrom = &types.EnvVarSource{SecretKeyRef: &types.KeySelector{Name: ref["name"], Key: ref["key"]}}
+		return envVar, ""
+	}
+	if ref, found, _ := unstructured.NestedStringMap(item, "valueFrom", "configMapKeyRef"); found {
+		envVar.ValueFrom = &types.EnvVarSource{ConfigMapKeyRef: &types.KeySelector{Name: ref["name"], Key: ref["key"]}}
+		return envVar, ""
+	}
+	return envVar, fmt.Sprintf("Environment variable '%s' uses an unsupported valueFrom source and is not mapped.", name)
+}
+
+// looksLikeMCPServer reports whether a Deployment appears to run an MCP
+// server, based on its labels, image and arguments.
+func looksLikeMCPServer(deployment *unstructured.Unstructured, w adoptedContainer) bool {
+	hints := []string{w.image, w.cmd}
+	hints = append(hints, w.args...)
+	for k, v := range deployment.GetLabels() {
+		hints = append(hints, k, v)
+	}
+	for _, hint := range hints {
+		if strings.Contains(strings.ToLower(hint), "mcp") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,358 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// agentEnvJSONSchema summarizes the env_json argument for tool descriptions.
+const agentEnvJSONSchema = `Environment variables for the agent's container: either a JSON object of name to literal value ({"LOG_LEVEL": "debug"}), or a JSON array of Kubernetes env entries, each {"name": string (required), "value": string} or {"name": string, "valueFrom": {"secretKeyRef" | "configMapKeyRef": {"name": string, "key": string, "optional": bool}}}. A value may also be a ${SECRET:<secret-name>:<key>} placeholder. Unknown fields are rejected`
+
+// envNamePattern matches the names Kubernetes accepts for env variables.
+var envNamePattern = regexp.MustCompile(`^[-._a-zA-Z][-._a-zA-Z0-9]*$`)
+
+// withEnvJSONOption adds the env_json argument of the agent manifest tools.
+func withEnvJSONOption() mcp.ToolOption {
+	return mcp.WithString("env_json",
+		mcp.Description(agentEnvJSONSchema+". Variables replace existing ones of the same name"),
+	)
+}
+
+// withEnvFromOption adds the env_from argument of the agent manifest tools.
+func withEnvFromOption() mcp.ToolOption {
+	return mcp.WithString("env_from",
+		mcp.Description("Comma-separated Secrets and ConfigMaps to inject whole: 'secret/<name>' or 'configmap/<name>'. The Agent CRD only takes single variables, so each key becomes a variable read through secretKeyRef or configMapKeyRef; keys added later need the manifest regenerated"),
+	)
+}
+
+// agentEnvFrom builds the variables requested by the env_json and env_from
+// arguments, env_json last so it overrides expanded keys. Keys of env_from
+// sources that cannot be variables are returned as skipped.
+func (ts *ToolServer) agentEnvFrom(ctx context.Context, envJSON string, envFrom []string) ([]types.EnvVar, []string, error) {
+	env, skipped, err := ts.expandEnvFrom(ctx, envFrom)
+	if err != nil {
+		return nil, nil, err
+	}
+	if envJSON != "" {
+		parsed, err := parseAgentEnv("env_json", envJSON)
+		if err != nil {
+			return nil, nil, err
+		}
+		env = mergeEnv(env, parsed)
+	}
+	return env, skipped, nil
+}
+
+// parseAgentEnv parses an env_json argument, given either as an object of
+// literal values or as an array of env entries.
+func parseAgentEnv(name, raw string) ([]types.EnvVar, error) {
+	if strings.HasPrefix(strings.TrimSpace(raw), "{") {
+		var values map[string]string
+		if err := json.Unmarshal([]byte(raw), &values); err != nil {
+			return nil, jsonPositionError(name, raw, err, 0)
+		}
+		var env []types.EnvVar
+		for _, key := range sortedKeys(values) {
+			if !envNamePattern.MatchString(key) {
+				return nil, fmt.Errorf("%s: '%s' is not a valid environment variable name", name, key)
+			}
+			env = append(env, types.EnvVar{Name: key, Value: values[key]})
+		}
+		return env, nil
+	}
+
+	env, _, err := decodeJSONArray(name, raw, false, func(e types.EnvVar) error {
+		if !envNamePattern.MatchString(e.Name) {
+			return fmt.Errorf("'%s' is not a valid environment variable name", e.Name)
+		}
+		if e.ValueFrom == nil {
+			return nil
+		}
+		if e.Value != "" {
+			return errors.New("value and valueFrom are mutually exclusive")
+		}
+		refs := 0
+		for _, ref := range []*types.KeySelector{e.ValueFrom.SecretKeyRef, e.ValueFrom.ConfigMapKeyRef} {
+			if ref == nil {
+				continue
+			}
+			refs++
+			if ref.Name == "" || ref.Key == "" {
+				return errors.New("valueFrom references need a name and a key")
+			}
+		}
+		if refs != 1 {
+			return errors.New("valueFrom must set exactly one of secretKeyRef and configMapKeyRef")
+		}
+		return nil
+	})
+	return env, err
+}
+
+// expandEnvFrom turns env_from references into one variable per key of the
+// referenced Secrets and ConfigMaps. Keys that are not valid variable names
+// are returned as skipped. Only Secret key names are read.
+func (ts *ToolServer) expandEnvFrom(ctx context.Context, refs []string) ([]types.EnvVar, []string, error) {
+	var env []types.EnvVar
+	var skipped []string
+	for _, ref := range refs {
+		kind, name, ok := strings.Cut(ref, "/")
+		if !ok || name == "" {
+			return nil, nil, fmt.Errorf("invalid env_from entry '%s': expected 'secret/<name>' or 'configmap/<name>'", ref)
+		}
+
+		var keys []string
+		switch strings.ToLower(kind) {
+		case "secret":
+			found, exists, known, err := ts.kube(ctx).SecretKeys(ctx, name)
+			switch {
+			case err != nil:
+				return nil, nil, err
+			case !known:
+				return nil, nil, fmt.Errorf("env_from: not allowed to read Secret '%s'; list its keys in env_json with secretKeyRef instead", name)
+			case !exists:
+				return nil, nil, fmt.Errorf("env_from: Secret '%s' does not exist in namespace '%s'", name, ts.kube(ctx).Namespace())
+			}
+			keys = found
+		case "configmap":
+			data, exists, err := ts.kube(ctx).GetConfigMapData(ctx, name)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !exists {
+				return nil, nil, fmt.Errorf("env_from: ConfigMap '%s' does not exist in namespace '%s'", name, ts.kube(ctx).Namespace())
+			}
+			keys = sortedKeys(data)
+		default:
+			return nil, nil, fmt.Errorf("invalid env_from entry '%s': kind must be 'secret' or 'configmap'", ref)
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			if !envNamePattern.MatchString(key) {
+				skipped = append(skipped, fmt.Sprintf("%s key '%s' is not a valid environment variable name", ref, key))
+				continue
+			}
+			selector := &types.KeySelector{Name: name, Key: key}
+			source := &types.EnvVarSource{ConfigMapKeyRef: selector}
+			if strings.EqualFold(kind, "secret") {
+				source = &types.EnvVarSource{SecretKeyRef: selector}
+			}
+			env = append(env, types.EnvVar{Name: key, ValueFrom: source})
+		}
+	}
+	return env, skipped, nil
+}
+
+// setAgentEnv merges env into the agent's container environment and drops
+// the variables named in remove, keeping its volumes and mounts.
+func setAgentEnv(agent *types.Agent, env []types.EnvVar, remove []string) error {
+	current, volumes, mounts := agentDeploymentSettings(agent)
+	var kept []types.EnvVar
+	for _, e := range current {
+		if !containsString(remove, e.Name) {
+			kept = append(kept, e)
+		}
+	}
+	return setAgentDeploymentSettings(agent, mergeEnv(kept, env), volumes, mounts)
+}
+
+// agentEnvPaths are where an Agent's container environment lives.
+var agentEnvPaths = [][]string{
+	{"spec", "declarative", "deployment", "env"},
+	{"spec", "byo", "deployment", "env"},
+}
+
+// checkAgentEnv checks the environment of an Agent: names must be valid and
+// unique, and the Secrets and ConfigMaps read through valueFrom must exist
+// with the referenced keys, unless the reference is optional.
+func (ts *ToolServer) checkAgentEnv(ctx context.Context, obj *unstructured.Unstructured) []ValidationIssue {
+	var issues []ValidationIssue
+
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = ts.kube(ctx).Namespace()
+	}
+	configMaps := map[string]map[string]string{}
+
+	for _, path := range agentEnvPaths {
+		env, _, _ := unstructured.NestedSlice(obj.Object, path...)
+		seen := map[string]bool{}
+		for i, item := range env {
+			e, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			field := fmt.Sprintf("%s[%d]", strings.Join(path, "."), i)
+			pointer := fmt.Sprintf("/%s/%d", strings.Join(path, "/"), i)
+
+			name, _, _ := unstructured.NestedString(e, "name")
+			switch {
+			case !envNamePattern.MatchString(name):
+				issues = append(issues, ValidationIssue{
+					Severity: "error",
+					Field:    field + ".name",
+					Message:  fmt.Sprintf("'%s' is not a valid environment variable name", name),
+				})
+			case seen[name]:
+				issues = append(issues, ValidationIssue{
+					Severity: "warning",
+					Field:    field + ".name",
+					Message:  fmt.Sprintf("environment variable '%s' is set more than once; the last entry wins", name),
+				})
+			}
+			seen[name] = true
+
+			if ref, ok, _ := unstructured.NestedMap(e, "valueFrom", "secretKeyRef"); ok {
+				issues = append(issues, ts.checkEnvSecretRef(ctx, namespace, field+".valueFrom.secretKeyRef", pointer+"/valueFrom/secretKeyRef", ref)...)
+			}
+			if ref, ok, _ := unstructured.NestedMap(e, "valueFrom", "configMapKeyRef"); ok {
+				issues = append(issues, ts.checkEnvConfigMapRef(ctx, namespace, field+".valueFrom.configMapKeyRef", pointer+"/valueFrom/configMapKeyRef", ref, configMaps)...)
+			}
+		}
+	}
+	return issues
+}
+
+// checkEnvSecretRef checks a secretKeyRef of an Agent env entry.
+func (ts *ToolServer) checkEnvSecretRef(ctx context.Context, namespace, field, pointer string, ref map[string]interface{}) []ValidationIssue {
+	name, _, _ := unstructured.NestedString(ref, "name")
+	key, _, _ := unstructured.NestedString(ref, "key")
+	optional, _, _ := unstructured.NestedBool(ref, "optional")
+	if name == "" || key == "" {
+		return []ValidationIssue{{Severity: "error", Field: field, Message: "secretKeyRef needs a name and a key"}}
+	}
+	if optional {
+		return nil
+	}
+
+	v := ts.verifySecretKey(ctx, namespace, name, key)
+	switch v.Status {
+	case secretUnverifiable:
+		return []ValidationIssue{{Severity: "warning", Field: field, Message: fmt.Sprintf("Could not verify Secret '%s': %s", name, v.Message)}}
+	case secretMissing:
+		return []ValidationIssue{{Severity: "error", Field: field + ".name", Message: v.Message + "; the agent's pod will not start until it is created"}}
+	case secretKeyMissing:
+		issue := ValidationIssue{Severity: "error", Field: field + ".key", Message: v.Message}
+		if v.Suggestion != "" {
+			issue.Fix = []PatchOperation{{Op: "replace", Path: pointer + "/key", Value: v.Suggestion}}
+		}
+		return []ValidationIssue{issue}
+	}
+	return nil
+}
+
+// checkEnvConfigMapRef checks a configMapKeyRef of an Agent env entry.
+// ConfigMaps already read are cached in configMaps.
+func (ts *ToolServer) checkEnvConfigMapRef(ctx context.Context, namespace, field, pointer string, ref map[string]interface{}, configMaps map[string]map[string]string) []ValidationIssue {
+	name, _, _ := unstructured.NestedString(ref, "name")
+	key, _, _ := unstructured.NestedString(ref, "key")
+	optional, _, _ := unstructured.NestedBool(ref, "optional")
+	if name == "" || key == "" {
+		return []ValidationIssue{{Severity: "error", Field: field, Message: "configMapKeyRef needs a name and a key"}}
+	}
+	if optional {
+		return nil
+	}
+
+	data, cached := configMaps[name]
+	if !cached {
+		var exists bool
+		var err error
+		data, exists, err = ts.kube(ctx).InNamespace(namespace).GetConfigMapData(ctx, name)
+		if err != nil {
+			return []ValidationIssue{{Severity: "warning", Field: field, Message: fmt.Sprintf("Could not verify ConfigMap '%s': %v", name, err)}}
+		}
+		if !exists {
+			data = nil
+		}
+		configMaps[name] = data
+	}
+
+	if data == nil {
+		return []ValidationIssue{{
+			Severity: "error",
+			Field:    field + ".name",
+			Message:  fmt.Sprintf("ConfigMap '%s' does not exist in namespace '%s'; the agent's pod will not start until it is created", name, namespace),
+		}}
+	}
+	if _, ok := data[key]; ok {
+		return nil
+	}
+	keys := sortedKeys(data)
+	issue := ValidationIssue{
+		Severity: "error",
+		Field:    field + ".key",
+		Message:  fmt.Sprintf("ConfigMap '%s' has no key '%s'. Available keys: %s.", name, key, strings.Join(keys, ", ")),
+	}
+	if len(keys) == 0 {
+		issue.Message = fmt.Sprintf("ConfigMap '%s' has no key '%s'. The ConfigMap has no keys.", name, key)
+	}
+	if suggestion, ok := closestKey(keys, key); ok {
+		issue.Message = fmt.Sprintf("ConfigMap '%s' has no key '%s'. Did you mean '%s'?", name, key, suggestion)
+		issue.Fix = []PatchOperation{{Op: "replace", Path: pointer + "/key", Value: suggestion}}
+	}
+	return []ValidationIssue{issue}
+}
+
+// envChanges summarizes how an Agent's environment changes between two
+// versions, by variable name: the list diff alone shows index shifts when a
+// variable is inserted.
+func envChanges(current, proposed map[string]interface{}) string {
+	before, after := map[string]interface{}{}, map[string]interface{}{}
+	for _, path := range agentEnvPaths {
+		for _, pair := range []struct {
+			obj  map[string]interface{}
+			into map[string]interface{}
+		}{{current, before}, {proposed, after}} {
+			env, _, _ := unstructured.NestedSlice(pair.obj, path...)
+			for _, item := range env {
+				if e, ok := item.(map[string]interface{}); ok {
+					name, _, _ := unstructured.NestedString(e, "name")
+					pair.into[name] = e
+				}
+			}
+		}
+	}
+
+	var added, changed, removed []string
+	for _, name := range sortedKeys(after) {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case !reflect.DeepEqual(old, after[name]):
+			changed = append(changed, name)
+		}
+	}
+	for _, name := range sortedKeys(before) {
+		if _, ok := after[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	var parts []string
+	for _, group := range []struct {
+		label string
+		names []string
+	}{{"added", added}, {"changed", changed}, {"removed", removed}} {
+		if len(group.names) > 0 {
+			parts = append(parts, group.label+" "+strings.Join(group.names, ", "))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Environment: " + strings.Join(parts, "; ") + ". Environment changes restart the agent's pods."
+}
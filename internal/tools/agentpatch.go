@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/diff"
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// registerPatchAgent registers the patch_agent tool.
+func (ts *ToolServer) registerPatchAgent() {
+	tool := mcp.NewTool("patch_agent",
+		mcp.WithDescription("Generate an updated manifest for an existing Agent by patching any field, for changes update_agent_manifest does not cover (memory, a2aConfig, deployment settings, labels, annotations). Takes an RFC 7386 JSON merge patch or RFC 6902 JSON Patch operations against the agent (paths start at the object root, e.g. /spec/a2aConfig or /metadata/labels), and/or labels and annotations to set or remove. The result is validated, checked with a server-side dry run, shown as a diff, and registered for review: apply it with apply_manifest and the returned diff_id."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the agent to patch"),
+		),
+		mcp.WithString("merge_patch_json",
+			mcp.Description(`JSON merge patch (RFC 7386): an object merged into the agent, where null removes a field. Example: {"metadata": {"labels": {"team": "sre"}}, "spec": {"declarative": {"systemMessage": "..."}}}`),
+		),
+		mcp.WithString("json_patch_json",
+			mcp.Description(`JSON Patch (RFC 6902): an array of add, remove, replace, move, copy and test operations. Example: [{"op": "add", "path": "/spec/declarative/tools/-", "value": {"type": "McpServer", "mcpServer": {"name": "k8s-tools"}}}]`),
+		),
+		withLabelsOption(),
+		withAnnotationsOption(),
+		mcp.WithBoolean("server_dry_run",
+			mcp.Description("Check the result with a server-side dry run, so the API server's schema validation and admission webhooks run (default: true). Skipped outside apply mode, where the server has no write access"),
+		),
+		withDiffFormatOption(),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handlePatchAgent)
+}
+
+func (ts *ToolServer) handlePatchAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	mergePatchJSON := args.String("merge_patch_json")
+	jsonPatchJSON := args.String("json_patch_json")
+	labelItems := args.StringList("labels")
+	annotationItems := args.StringList("annotations")
+	serverDryRun := args.Bool("server_dry_run", true)
+	renderer := diffRendererFrom(args)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if mergePatchJSON != "" && jsonPatchJSON != "" {
+		return mcp.NewToolResultError("pass either merge_patch_json or json_patch_json, not both"), nil
+	}
+	labelChanges, err := parseMetadataChanges("labels", labelItems, true)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	annotationChanges, err := parseMetadataChanges("annotations", annotationItems, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if mergePatchJSON == "" && jsonPatchJSON == "" && labelChanges.isZero() && annotationChanges.isZero() {
+		return mcp.NewToolResultError("nothing to change: pass merge_patch_json, json_patch_json, labels or annotations"), nil
+	}
+
+	currentYAML, resourceVersion, err := ts.kube(ctx).GetCurrentStateVersion(ctx, kagentAPIVersions["Agent"], "Agent", name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+	var current map[string]interface{}
+	if err := yaml.Unmarshal([]byte(currentYAML), &current); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse agent: %v", err)), nil
+	}
+
+	var patched map[string]interface{}
+	switch {
+	case mergePatchJSON != "":
+		var patch map[string]interface{}
+		if err := json.Unmarshal([]byte(mergePatchJSON), &patch); err != nil {
+			return mcp.NewToolResultError(jsonPositionError("merge_patch_json", mergePatchJSON, err, 0).Error()), nil
+		}
+		patched = diff.MergePatch(current, patch)
+	case jsonPatchJSON != "":
+		var ops []diff.Operation
+		if err := json.Unmarshal([]byte(jsonPatchJSON), &ops); err != nil {
+			return mcp.NewToolResultError(jsonPositionError("json_patch_json", jsonPatchJSON, err, 0).Error()), nil
+		}
+		patched, err = diff.ApplyPatch(current, ops)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to apply json_patch_json: %v", err)), nil
+		}
+	default:
+		// An empty patch copies current, which the label changes must not touch
+		patched = diff.MergePatch(current, map[string]interface{}{})
+	}
+
+	// Labels and annotations apply on top of the patch
+	obj := &unstructured.Unstructured{Object: patched}
+	if !labelChanges.isZero() {
+		obj.SetLabels(labelChanges.apply(obj.GetLabels()))
+	}
+	if !annotationChanges.isZero() {
+		obj.SetAnnotations(annotationChanges.apply(obj.GetAnnotations()))
+	}
+
+	if err := checkPatchIdentity(current, patched); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	changes, err := renderer.Render(current, patched)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if changes == "" {
+		return mcp.NewToolResultText(fmt.Sprintf("The patch does not change agent '%s'.", name)), nil
+	}
+
+	output, _ := yaml.Marshal(patched)
+	manifest := string(output)
+
+	var header strings.Builder
+	fmt.Fprintf(&header, "# Patched Agent Manifest for '%s' (from resourceVersion %s)\n", name, resourceVersion)
+
+	// Local checks first: they explain problems better than the API server
+	var issues []resourceIssue
+	for _, issue := range ts.validateObject(ctx, obj, false, false) {
+		issues = append(issues, resourceIssue{Resource: "Agent/" + name, Issue: issue})
+	}
+	header.WriteString(validationComment(dedupeIssues(issues)) + "\n")
+
+	if noDryRun := ts.dryRunUnavailable(); serverDryRun && noDryRun != nil {
+		header.WriteString(fmt.Sprintf("# Server-side dry run skipped: %v.\n", noDryRun))
+	} else if serverDryRun {
+		_, err := ts.applyDocument(ctx, manifest, true, kubernetes.Preconditions{ResourceVersion: resourceVersion})
+		if err != nil {
+			header.WriteString(fmt.Sprintf("# ❌ Server-side dry run rejected the change: %v\n", err))
+		} else {
+			header.WriteString("# ✓ Server-side dry run accepted the change.\n")
+		}
+	}
+
+	diffID, err := ts.reviews.Add(ctx, manifest, "Agent", name, sessionOwner(ctx))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to record the change for review: %v", err)), nil
+	}
+	fmt.Fprintf(&header, "# Diff ID: %s (apply with apply_manifest diff_id=%s expected_resource_version=%s)\n#\n# Changes:\n", diffID, diffID, resourceVersion)
+	if legend := renderer.Legend(); legend != "" {
+		header.WriteString("# " + legend + "\n")
+	}
+	for _, line := range strings.Split(changes, "\n") {
+		header.WriteString("# " + line + "\n")
+	}
+
+	return out.render(strings.TrimSuffix(header.String(), "\n"), manifest)
+}
+
+// checkPatchIdentity refuses patches that would turn the agent into a
+// different resource or write its status.
+func checkPatchIdentity(current, patched map[string]interface{}) error {
+	for _, path := range [][]string{{"apiVersion"}, {"kind"}, {"metadata", "name"}, {"metadata", "namespace"}} {
+		before, _, _ := unstructured.NestedFieldNoCopy(current, path...)
+		after, _, _ := unstructured.NestedFieldNoCopy(patched, path...)
+		if !reflect.DeepEqual(before, after) {
+			return fmt.Errorf("the patch must not change %s; create a new agent instead", strings.Join(path, "."))
+		}
+	}
+	if _, found := patched["status"]; found {
+		return fmt.Errorf("the patch must not set status, which the controller owns")
+	}
+	return nil
+}
@@ -4,11 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"sigs.k8s.io/yaml"
 
+	"github.com/kagent-dev/meta-kagent/internal/params"
 	"github.com/kagent-dev/meta-kagent/pkg/types"
 )
 
@@ -19,24 +19,43 @@ func (ts *ToolServer) registerListAgents() {
 		mcp.WithBoolean("include_status",
 			mcp.Description("Include status information (ready, accepted) in the output"),
 		),
+		withLabelSelectorOption(),
+		withFieldSelectorOption(),
+		withAllNamespacesOption(),
+		withRefreshOption(),
+		withLimitOption(),
+		withContinueOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleListAgents)
+	ts.addTool(tool, ts.handleListAgents)
 }
 
 func (ts *ToolServer) handleListAgents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	includeStatus := false
-	if v, ok := req.Params.Arguments["include_status"].(bool); ok {
-		includeStatus = v
+	args := params.From(req)
+	includeStatus := args.Bool("include_status", false)
+	opts, selectorErr := listOptionsFrom(args)
+	allNamespaces := args.Bool("all_namespaces", false)
+	refresh := args.Bool("refresh", false)
+	p := ts.pageFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if selectorErr != nil {
+		return mcp.NewToolResultError(selectorErr.Error()), nil
+	}
+	opts.Limit, opts.Continue = int64(p.limit), p.token
 
-	agents, err := ts.k8sClient.ListAgents(ctx)
+	client, err := ts.listClient(ctx, allNamespaces, refresh)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	agents, next, err := client.ListAgentsWithOptions(ctx, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to list agents: %v", err)), nil
 	}
 
 	if len(agents) == 0 {
-		return mcp.NewToolResultText("No agents found in the namespace."), nil
+		return mcp.NewToolResultText(fmt.Sprintf("No agents%s found in the namespace.", describeSelectors(opts))), nil
 	}
 
 	var result []map[string]interface{}
@@ -47,6 +66,9 @@ func (ts *ToolServer) handleListAgents(ctx context.Context, req mcp.CallToolRequ
 			"type":        agent.Spec.Type,
 			"description": agent.Spec.Description,
 		}
+		if len(agent.Labels) > 0 {
+			item["labels"] = agent.Labels
+		}
 		if agent.Spec.Declarative != nil {
 			item["modelConfig"] = agent.Spec.Declarative.ModelConfig
 			item["toolCount"] = len(agent.Spec.Declarative.Tools)
@@ -59,7 +81,7 @@ func (ts *ToolServer) handleListAgents(ctx context.Context, req mcp.CallToolRequ
 	}
 
 	output, _ := json.MarshalIndent(result, "", "  ")
-	return mcp.NewToolResultText(string(output)), nil
+	return mcp.NewToolResultText(string(output) + continueNote(len(result), next)), nil
 }
 
 // registerGetAgent registers the get_agent tool.
@@ -73,23 +95,22 @@ func (ts *ToolServer) registerGetAgent() {
 		mcp.WithString("output_format",
 			mcp.Description("Output format: 'yaml' (default) or 'json'"),
 		),
+		withRefreshOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleGetAgent)
+	ts.addTool(tool, ts.handleGetAgent)
 }
 
 func (ts *ToolServer) handleGetAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name, ok := req.Params.Arguments["name"].(string)
-	if !ok || name == "" {
-		return mcp.NewToolResultError("name is required"), nil
+	args := params.From(req)
+	name := args.RequiredString("name")
+	format := args.Enum("output_format", "yaml", "yaml", "json")
+	refresh := args.Bool("refresh", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	format := "yaml"
-	if v, ok := req.Params.Arguments["output_format"].(string); ok && v != "" {
-		format = v
-	}
-
-	agent, err := ts.k8sClient.GetAgent(ctx, name)
+	agent, err := ts.readClient(ctx, refresh).GetAgent(ctx, name)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
 	}
@@ -122,36 +143,101 @@ func (ts *ToolServer) registerCreateAgentManifest() {
 		),
 		mcp.WithString("system_message",
 			mcp.Required(),
-			mcp.Description("The system prompt that defines the agent's behavior, capabilities, and constraints"),
+			mcp.Description("The system prompt that defines the agent's behavior, capabilities, and constraints. Cluster facts ({{cluster}}, {{cloud}}, {{region}}, {{ingress_domain}}) are substituted"),
 		),
 		mcp.WithString("model_config",
 			mcp.Required(),
-			mcp.Description("Name of the ModelConfig resource to use for LLM configuration"),
+			mcp.Description("ModelConfig to use for LLM configuration: 'name' for one in the agent's namespace, or 'namespace/name' for a shared one (e.g., 'shared-models/gpt4o')"),
 		),
 		mcp.WithString("tools_json",
-			mcp.Description("JSON array of tool configurations. Format: [{\"mcpServer\": \"server-name\", \"kind\": \"MCPServer\", \"tools\": [\"tool1\", \"tool2\"]}]"),
+			mcp.Description(toolsJSONSchema+`. Example: [{"mcpServer": "server-name", "kind": "MCPServer", "tools": ["tool1", "tool2"]}]`),
 		),
 		mcp.WithString("skills_json",
-			mcp.Description("JSON array of A2A skill configurations. Format: [{\"id\": \"skill-id\", \"name\": \"Skill Name\", \"description\": \"...\"}]"),
+			mcp.Description(skillsJSONSchema+`. Example: [{"id": "skill-id", "name": "Skill Name", "description": "..."}]`),
+		),
+		withEnvJSONOption(),
+		withEnvFromOption(),
+		withPartialOption(),
+		mcp.WithBoolean("include_namespace",
+			mcp.Description("Prepend a Namespace manifest so the bundle can be applied to a cluster where the namespace does not exist yet (default: false)"),
 		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleCreateAgentManifest)
+	ts.addTool(tool, ts.handleCreateAgentManifest)
 }
 
 func (ts *ToolServer) handleCreateAgentManifest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name, _ := req.Params.Arguments["name"].(string)
-	description, _ := req.Params.Arguments["description"].(string)
-	systemMessage, _ := req.Params.Arguments["system_message"].(string)
-	modelConfig, _ := req.Params.Arguments["model_config"].(string)
-	toolsJSON, _ := req.Params.Arguments["tools_json"].(string)
-	skillsJSON, _ := req.Params.Arguments["skills_json"].(string)
+	args := params.From(req)
+	name := args.RequiredString("name")
+	description := args.String("description")
+	systemMessage := args.RequiredString("system_message")
+	modelConfig := args.RequiredString("model_config")
+	toolsJSON := args.String("tools_json")
+	skillsJSON := args.String("skills_json")
+	envJSON := args.String("env_json")
+	envFrom := args.StringList("env_from")
+	includeNamespace := args.Bool("include_namespace", false)
+	partial := args.Bool("partial", false)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := ts.expandFacts(ctx, &description, &systemMessage); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if _, err := types.ParseObjectRef(modelConfig, ""); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid model_config: %v", err)), nil
+	}
+
+	var skipped []string
+	var tools []types.ToolSpec
+	if toolsJSON != "" {
+		parsed, problems, err := parseToolConfigs("tools_json", toolsJSON, partial)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		tools = parsed
+		skipped = append(skipped, problems...)
+	}
+
+	var skills []types.Skill
+	if skillsJSON != "" {
+		parsed, problems, err := parseSkills("skills_json", skillsJSON, partial)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		skills = parsed
+		skipped = append(skipped, problems...)
+	}
 
-	if name == "" || systemMessage == "" || modelConfig == "" {
-		return mcp.NewToolResultError("name, system_message, and model_config are required"), nil
+	env, envSkipped, err := ts.agentEnvFrom(ctx, envJSON, envFrom)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	skipped = append(skipped, envSkipped...)
+
+	agent := newDeclarativeAgent(name, ts.kube(ctx).Namespace(), description, systemMessage, modelConfig, tools, skills)
+	if len(env) > 0 {
+		if err := setAgentEnv(&agent, env, nil); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 	}
 
-	// Build agent manifest
+	output, _ := yaml.Marshal(agent)
+
+	header := `# Generated Agent Manifest
+# IMPORTANT: Review this manifest carefully before applying.
+# Use validate_manifest to check for issues, then apply_manifest to deploy.` + skippedItemsComment(skipped)
+
+	return out.render(header, withNamespaceDocument(includeNamespace, agent.Namespace, string(output)))
+}
+
+// newDeclarativeAgent builds a Declarative agent, declaring skills in its
+// A2A config when there are any.
+func newDeclarativeAgent(name, namespace, description, systemMessage, modelConfig string, tools []types.ToolSpec, skills []types.Skill) types.Agent {
 	agent := types.Agent{
 		Spec: types.AgentSpec{
 			Type:        "Declarative",
@@ -159,58 +245,21 @@ func (ts *ToolServer) handleCreateAgentManifest(ctx context.Context, req mcp.Cal
 			Declarative: &types.DeclarativeSpec{
 				ModelConfig:   modelConfig,
 				SystemMessage: systemMessage,
+				Tools:         tools,
 			},
 		},
 	}
 	agent.APIVersion = "kagent.dev/v1alpha2"
 	agent.Kind = "Agent"
 	agent.Name = name
-	agent.Namespace = "kagent"
+	agent.Namespace = namespace
 
-	// Parse tools if provided
-	if toolsJSON != "" {
-		var toolConfigs []struct {
-			MCPServer string   `json:"mcpServer"`
-			Kind      string   `json:"kind"`
-			Tools     []string `json:"tools"`
-		}
-		if err := json.Unmarshal([]byte(toolsJSON), &toolConfigs); err == nil {
-			for _, tc := range toolConfigs {
-				kind := tc.Kind
-				if kind == "" {
-					kind = "MCPServer"
-				}
-				agent.Spec.Declarative.Tools = append(agent.Spec.Declarative.Tools, types.ToolSpec{
-					Type: "McpServer",
-					McpServer: &types.McpServerRef{
-						Name:      tc.MCPServer,
-						Kind:      kind,
-						ToolNames: tc.Tools,
-					},
-				})
-			}
-		}
-	}
-
-	// Parse skills if provided
-	if skillsJSON != "" {
-		var skills []types.Skill
-		if err := json.Unmarshal([]byte(skillsJSON), &skills); err == nil {
-			agent.Spec.A2AConfig = &types.A2AConfig{
-				Skills: skills,
-			}
+	if len(skills) > 0 {
+		agent.Spec.A2AConfig = &types.A2AConfig{
+			Skills: skills,
 		}
 	}
-
-	output, _ := yaml.Marshal(agent)
-
-	result := fmt.Sprintf(`# Generated Agent Manifest
-# IMPORTANT: Review this manifest carefully before applying.
-# Use validate_manifest to check for issues, then apply_manifest to deploy.
-
-%s`, string(output))
-
-	return mcp.NewToolResultText(result), nil
+	return agent
 }
 
 // registerUpdateAgentManifest registers the update_agent_manifest tool.
@@ -228,51 +277,114 @@ func (ts *ToolServer) registerUpdateAgentManifest() {
 			mcp.Description("New description (optional)"),
 		),
 		mcp.WithString("model_config",
-			mcp.Description("New ModelConfig reference (optional)"),
+			mcp.Description("New ModelConfig reference: 'name' or 'namespace/name' (optional)"),
 		),
 		mcp.WithString("add_tools_json",
-			mcp.Description("JSON array of tools to add. Format: [{\"mcpServer\": \"name\", \"kind\": \"MCPServer\", \"tools\": [\"tool1\"]}]"),
+			mcp.Description("Tools to add. "+toolsJSONSchema+`. Example: [{"mcpServer": "name", "kind": "MCPServer", "tools": ["tool1"]}]`),
 		),
 		mcp.WithString("remove_tool_servers",
 			mcp.Description("Comma-separated list of MCP server names to remove from the agent"),
 		),
+		withEnvJSONOption(),
+		withEnvFromOption(),
+		mcp.WithString("remove_env",
+			mcp.Description("Comma-separated names of environment variables to remove from the agent's container"),
+		),
+		withLabelsOption(),
+		withAnnotationsOption(),
+		withPartialOption(),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleUpdateAgentManifest)
+	ts.addTool(tool, ts.handleUpdateAgentManifest)
 }
 
 func (ts *ToolServer) handleUpdateAgentManifest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name, _ := req.Params.Arguments["name"].(string)
-	if name == "" {
-		return mcp.NewToolResultError("name is required"), nil
+	args := params.From(req)
+	name := args.RequiredString("name")
+	description := args.String("description")
+	systemMessage := args.String("system_message")
+	modelConfig := args.String("model_config")
+	removeServers := args.StringList("remove_tool_servers")
+	addToolsJSON := args.String("add_tools_json")
+	envJSON := args.String("env_json")
+	envFrom := args.StringList("env_from")
+	removeEnv := args.StringList("remove_env")
+	labelItems := args.StringList("labels")
+	annotationItems := args.StringList("annotations")
+	partial := args.Bool("partial", false)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := ts.expandFacts(ctx, &description, &systemMessage); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	labelChanges, err := parseMetadataChanges("labels", labelItems, true)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	annotationChanges, err := parseMetadataChanges("annotations", annotationItems, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if modelConfig != "" {
+		if _, err := types.ParseObjectRef(modelConfig, ""); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid model_config: %v", err)), nil
+		}
+	}
+
+	var skipped []string
+	var addTools []types.ToolSpec
+	if addToolsJSON != "" {
+		parsed, problems, err := parseToolConfigs("add_tools_json", addToolsJSON, partial)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		addTools = parsed
+		skipped = problems
 	}
 
+	env, envSkipped, err := ts.agentEnvFrom(ctx, envJSON, envFrom)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	skipped = append(skipped, envSkipped...)
+
 	// Get current agent
-	agent, err := ts.k8sClient.GetAgent(ctx, name)
+	agent, err := ts.kube(ctx).GetAgent(ctx, name)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
 	}
 
 	// Apply updates
-	if v, ok := req.Params.Arguments["description"].(string); ok && v != "" {
-		agent.Spec.Description = v
+	if description != "" {
+		agent.Spec.Description = description
+	}
+	if !labelChanges.isZero() {
+		agent.SetLabels(labelChanges.apply(agent.GetLabels()))
+	}
+	if !annotationChanges.isZero() {
+		agent.SetAnnotations(annotationChanges.apply(agent.GetAnnotations()))
 	}
 
 	if agent.Spec.Declarative != nil {
-		if v, ok := req.Params.Arguments["system_message"].(string); ok && v != "" {
-			agent.Spec.Declarative.SystemMessage = v
+		if systemMessage != "" {
+			agent.Spec.Declarative.SystemMessage = systemMessage
 		}
-		if v, ok := req.Params.Arguments["model_config"].(string); ok && v != "" {
-			agent.Spec.Declarative.ModelConfig = v
+		if modelConfig != "" {
+			agent.Spec.Declarative.ModelConfig = modelConfig
 		}
 	}
 
 	// Remove tools
-	if removeServers, ok := req.Params.Arguments["remove_tool_servers"].(string); ok && removeServers != "" {
-		serverNames := strings.Split(removeServers, ",")
+	if len(removeServers) > 0 {
 		removeMap := make(map[string]bool)
-		for _, s := range serverNames {
-			removeMap[strings.TrimSpace(s)] = true
+		for _, s := range removeServers {
+			removeMap[s] = true
 		}
 
 		if agent.Spec.Declarative != nil {
@@ -287,27 +399,17 @@ func (ts *ToolServer) handleUpdateAgentManifest(ctx context.Context, req mcp.Cal
 	}
 
 	// Add tools
-	if addToolsJSON, ok := req.Params.Arguments["add_tools_json"].(string); ok && addToolsJSON != "" {
-		var toolConfigs []struct {
-			MCPServer string   `json:"mcpServer"`
-			Kind      string   `json:"kind"`
-			Tools     []string `json:"tools"`
+	if len(addTools) > 0 {
+		if agent.Spec.Declarative == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' is not declarative; tools cannot be added", name)), nil
 		}
-		if err := json.Unmarshal([]byte(addToolsJSON), &toolConfigs); err == nil && agent.Spec.Declarative != nil {
-			for _, tc := range toolConfigs {
-				kind := tc.Kind
-				if kind == "" {
-					kind = "MCPServer"
-				}
-				agent.Spec.Declarative.Tools = append(agent.Spec.Declarative.Tools, types.ToolSpec{
-					Type: "McpServer",
-					McpServer: &types.McpServerRef{
-						Name:      tc.MCPServer,
-						Kind:      kind,
-						ToolNames: tc.Tools,
-					},
-				})
-			}
+		agent.Spec.Declarative.Tools = append(agent.Spec.Declarative.Tools, addTools...)
+	}
+
+	// Environment
+	if len(env) > 0 || len(removeEnv) > 0 {
+		if err := setAgentEnv(agent, env, removeEnv); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 	}
 
@@ -317,19 +419,17 @@ func (ts *ToolServer) handleUpdateAgentManifest(ctx context.Context, req mcp.Cal
 
 	output, _ := yaml.Marshal(agent)
 
-	result := fmt.Sprintf(`# Updated Agent Manifest
+	header := `# Updated Agent Manifest
 # IMPORTANT: Review the changes before applying.
-# Use diff_manifest to see changes, then apply_manifest to deploy.
+# Use diff_manifest to see changes, then apply_manifest to deploy.` + skippedItemsComment(skipped)
 
-%s`, string(output))
-
-	return mcp.NewToolResultText(result), nil
+	return out.render(header, string(output))
 }
 
 // registerDeleteAgent registers the delete_agent tool.
 func (ts *ToolServer) registerDeleteAgent() {
 	tool := mcp.NewTool("delete_agent",
-		mcp.WithDescription("Delete a kagent Agent from the cluster. IMPORTANT: This action is destructive. Use dry_run=true to preview without deleting."),
+		mcp.WithDescription("Delete a kagent Agent from the cluster. Refuses when other agents still call it as a tool, unless force=true. IMPORTANT: This action is destructive. Use dry_run=true to preview without deleting."),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the agent to delete"),
@@ -337,28 +437,32 @@ func (ts *ToolServer) registerDeleteAgent() {
 		mcp.WithBoolean("dry_run",
 			mcp.Description("If true, only simulate the deletion without actually removing the agent"),
 		),
+		withForceOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleDeleteAgent)
+	ts.addTool(tool, ts.handleDeleteAgent)
 }
 
 func (ts *ToolServer) handleDeleteAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name, _ := req.Params.Arguments["name"].(string)
-	if name == "" {
-		return mcp.NewToolResultError("name is required"), nil
-	}
-
-	dryRun := false
-	if v, ok := req.Params.Arguments["dry_run"].(bool); ok {
-		dryRun = v
+	args := params.From(req)
+	name := args.RequiredString("name")
+	dryRun := args.Bool("dry_run", false)
+	force := args.Bool("force", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	// Verify agent exists first
-	agent, err := ts.k8sClient.GetAgent(ctx, name)
+	agent, err := ts.kube(ctx).GetAgent(ctx, name)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Agent not found: %v", err)), nil
 	}
 
+	refs, err := ts.referencesTo(ctx, "Agent", name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	if dryRun {
 		return mcp.NewToolResultText(fmt.Sprintf(`# Dry Run: Delete Agent
 
@@ -366,15 +470,19 @@ The following agent would be deleted:
 - Name: %s
 - Namespace: %s
 - Description: %s
-
+%s
 To actually delete, call delete_agent with dry_run=false.`,
-			agent.Name, agent.Namespace, agent.Spec.Description)), nil
+			agent.Name, agent.Namespace, agent.Spec.Description, deletionImpact(refs, force))), nil
+	}
+
+	if len(refs) > 0 && !force {
+		return mcp.NewToolResultError(refusedDeletion("Agent", name, refs)), nil
 	}
 
-	err = ts.k8sClient.Delete(ctx, "Agent", name, false)
+	err = ts.kube(ctx).Delete(ctx, "Agent", name, false)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete agent: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Successfully deleted agent '%s'.", name)), nil
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully deleted agent '%s'.", name) + brokenReferences(refs)), nil
 }
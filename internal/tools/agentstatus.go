@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// AgentStatusReport is the observed state of an agent and the workload
+// running it.
+type AgentStatusReport struct {
+	Agent              string                       `json:"agent"`
+	Ready              bool                         `json:"ready"`
+	Accepted           bool                         `json:"accepted"`
+	Message            string                       `json:"message,omitempty"`
+	Generation         int64                        `json:"generation"`
+	ObservedGeneration int64                        `json:"observedGeneration"`
+	Conditions         []types.Condition            `json:"conditions"`
+	Deployment         *kubernetes.DeploymentStatus `json:"deployment,omitempty"`
+	Pods               []kubernetes.PodStatus       `json:"pods,omitempty"`
+	Events             []EventGroup                 `json:"events"`
+	// Problems lists what stands between the agent and Ready.
+	Problems []string `json:"problems,omitempty"`
+	// Unavailable lists what could not be read with the server's identity.
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// registerGetAgentStatus registers the get_agent_status tool.
+func (ts *ToolServer) registerGetAgentStatus() {
+	tool := mcp.NewTool("get_agent_status",
+		mcp.WithDescription("Explain why an agent is or is not Ready: its full status conditions and the controller's message, the rollout state of its Deployment, the state of its Pods and containers (e.g., CrashLoopBackOff, ImagePullBackOff, OOMKilled), and the recent Kubernetes Events on the agent, its Deployment and Pods. Use it to debug an agent that list_agents shows as not ready."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the agent"),
+		),
+		mcp.WithString("window",
+			mcp.Description("How far back to include events, as a duration (e.g., '15m', '6h'). Default: '1h'"),
+		),
+		withRefreshOption(),
+	)
+
+	ts.addTool(tool, ts.handleGetAgentStatus)
+}
+
+func (ts *ToolServer) handleGetAgentStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	window := args.Duration("window", time.Hour)
+	refresh := args.Bool("refresh", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client := ts.readClient(ctx, refresh)
+	agent, err := client.GetAgent(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	report := &AgentStatusReport{
+		Agent:              name,
+		Ready:              agent.Status.IsReady(),
+		Accepted:           agent.Status.IsAccepted(),
+		Generation:         agent.Generation,
+		ObservedGeneration: agent.Status.ObservedGeneration,
+		Conditions:         agent.Status.Conditions,
+		Events:             []EventGroup{},
+	}
+	report.Message = controllerMessage(agent.Status.Conditions)
+
+	if len(agent.Status.Conditions) == 0 {
+		report.Problems = append(report.Problems, "the controller has not reported any status: check that the kagent controller is healthy (controller_health)")
+	}
+	for _, c := range agent.Status.Conditions {
+		if c.Status != "True" {
+			report.Problems = append(report.Problems, fmt.Sprintf("condition %s is %s (%s): %s", c.Type, c.Status, c.Reason, c.Message))
+		}
+	}
+	if agent.Status.ObservedGeneration > 0 && agent.Status.ObservedGeneration < agent.Generation {
+		report.Problems = append(report.Problems, fmt.Sprintf("the controller has not yet reconciled the latest spec (generation %d, observed %d)", agent.Generation, agent.Status.ObservedGeneration))
+	}
+
+	// The controller runs each agent as a Deployment named after it
+	kube := ts.kube(ctx)
+	deployment, known, err := kube.GetDeploymentStatus(ctx, name)
+	switch {
+	case err != nil && !apierrors.IsNotFound(err):
+		return mcp.NewToolResultError(err.Error()), nil
+	case err != nil:
+		report.Problems = append(report.Problems, fmt.Sprintf("Deployment '%s' does not exist: the controller has not created the agent's workload", name))
+	case !known:
+		report.Unavailable = append(report.Unavailable, "deployments (grant 'get' on deployments)")
+	default:
+		report.Deployment = deployment
+		if deployment.ReadyReplicas < deployment.Replicas {
+			report.Problems = append(report.Problems, fmt.Sprintf("Deployment has %d of %d replicas ready", deployment.ReadyReplicas, deployment.Replicas))
+		}
+		if deployment.Selector != "" {
+			pods, known, err := kube.ListPodStatuses(ctx, deployment.Selector)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if !known {
+				report.Unavailable = append(report.Unavailable, "pods (grant 'list' on pods)")
+			}
+			report.Pods = pods
+			report.Problems = append(report.Problems, podProblems(pods)...)
+		}
+	}
+
+	events, known, err := kube.ListEvents(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list events: %v", err)), nil
+	}
+	if known {
+		owners, err := eventOwners(ctx, kube)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if groups := groupEvents(events, owners, time.Now().Add(-window), false, name); len(groups) > 0 {
+			report.Events = groups
+		}
+	} else {
+		report.Unavailable = append(report.Unavailable, "events (grant 'list' on events)")
+	}
+
+	verdict := "Ready"
+	if !report.Ready {
+		verdict = "Not Ready"
+	}
+	header := fmt.Sprintf("# Agent Status: %s\n\nStatus: %s", name, verdict)
+	if report.Message != "" {
+		header += "\nController: " + report.Message
+	}
+	if len(report.Problems) > 0 {
+		header += "\n\nProblems:\n- " + strings.Join(report.Problems, "\n- ")
+	}
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	return mcp.NewToolResultText(header + "\n\n" + string(output)), nil
+}
+
+// controllerMessage returns the message the controller reports for the
+// agent: that of the first condition that is not True, or else of Ready.
+func controllerMessage(conditions []types.Condition) string {
+	for _, c := range conditions {
+		if c.Status != "True" && c.Message != "" {
+			return c.Message
+		}
+	}
+	for _, c := range conditions {
+		if c.Type == "Ready" {
+			return c.Message
+		}
+	}
+	return ""
+}
+
+// podProblems describes the pods and containers that keep an agent from
+// becoming Ready.
+func podProblems(pods []kubernetes.PodStatus) []string {
+	var problems []string
+	for _, pod := range pods {
+		if pod.Phase == "Pending" {
+			for _, c := range pod.Conditions {
+				if c.Type == "PodScheduled" && c.Status != "True" {
+					problems = append(problems, fmt.Sprintf("Pod %s cannot be scheduled (%s): %s", pod.Name, c.Reason, c.Message))
+				}
+			}
+		}
+		for _, c := range pod.Containers {
+			switch {
+			case c.State == "waiting" && c.Reason != "" && c.Reason != "ContainerCreating":
+				problems = append(problems, strings.TrimSpace(fmt.Sprintf("Pod %s container %s is waiting: %s %s", pod.Name, c.Name, c.Reason, c.Message)))
+			case c.State == "terminated":
+				problems = append(problems, strings.TrimSpace(fmt.Sprintf("Pod %s container %s terminated: %s %s", pod.Name, c.Name, c.Reason, c.Message)))
+			case c.Restarts > 0 && c.LastTermination != "":
+				problems = append(problems, fmt.Sprintf("Pod %s container %s restarted %d time(s), last because of %s", pod.Name, c.Name, c.Restarts, c.LastTermination))
+			}
+		}
+	}
+	return problems
+}
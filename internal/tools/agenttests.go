@@ -0,0 +1,238 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/agenttest"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/sampling"
+)
+
+// testsAnnotation names the ConfigMap holding an agent's test cases when it
+// is not the default <agent>-tests.
+const testsAnnotation = "kagent.dev/tests"
+
+// testsLabel marks test ConfigMaps with the agent they belong to.
+const testsLabel = "kagent.dev/agent-tests"
+
+// agentTestTimeout bounds each test case, which waits for a model call.
+const agentTestTimeout = 2 * time.Minute
+
+// testsConfigMapName returns the ConfigMap holding an agent's test cases.
+func testsConfigMapName(agentName string, annotations map[string]string) string {
+	if name := annotations[testsAnnotation]; name != "" {
+		return name
+	}
+	return agentName + "-tests"
+}
+
+// registerCreateAgentTests registers the create_agent_tests tool.
+func (ts *ToolServer) registerCreateAgentTests() {
+	tool := mcp.NewTool("create_agent_tests",
+		mcp.WithDescription("Generate a ConfigMap attaching test cases to an agent: golden prompts with the content or behavior expected in reply. Run them with run_agent_tests after changing the agent's prompt, model or tools."),
+		mcp.WithString("agent_name",
+			mcp.Required(),
+			mcp.Description("Name of the agent the tests belong to"),
+		),
+		mcp.WithString("tests_json",
+			mcp.Required(),
+			mcp.Description(`JSON array of test cases: [{"name": "greets", "input": "Hello", "expect": {"contains": ["help"], "notContains": ["error"], "matches": "(?i)hello|hi", "behavior": "Greets the user and offers help"}}]. Each case needs at least one expectation; behavior is judged by an LLM.`),
+		),
+		withPartialOption(),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleCreateAgentTests)
+}
+
+func (ts *ToolServer) handleCreateAgentTests(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	agentName := args.RequiredString("agent_name")
+	testsJSON := args.RequiredString("tests_json")
+	partial := args.Bool("partial", false)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cases, skipped, err := decodeJSONArray("tests_json", testsJSON, partial, func(c agenttest.Case) error {
+		return (&agenttest.Suite{Tests: []agenttest.Case{c}}).Validate()
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	suite := &agenttest.Suite{Tests: cases}
+	if err := suite.Validate(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid tests: %v", err)), nil
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, agentName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	data, err := suite.Marshal()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to render tests: %v", err)), nil
+	}
+	configMap := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      testsConfigMapName(agentName, agent.Annotations),
+			"namespace": ts.kube(ctx).Namespace(),
+			"labels": map[string]interface{}{
+				testsLabel: agentName,
+			},
+		},
+		"data": data,
+	}
+	output, _ := yaml.Marshal(configMap)
+
+	header := fmt.Sprintf(`# Test cases for agent '%s' (%d)
+# Apply with kubectl, or with apply_manifest when ConfigMap is listed in
+# KAGENT_APPLY_ALLOWED_KINDS. Run them with run_agent_tests.`, agentName, len(cases)) + skippedItemsComment(skipped)
+
+	return out.render(header, string(output))
+}
+
+// registerRunAgentTests registers the run_agent_tests tool.
+func (ts *ToolServer) registerRunAgentTests() {
+	tool := mcp.NewTool("run_agent_tests",
+		mcp.WithDescription("Run an agent's test cases (see create_agent_tests) against the deployed agent over A2A and report pass/fail per case. Content expectations are checked directly; behavior expectations are judged by the client's LLM through sampling when available."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the agent to test"),
+		),
+		mcp.WithString("endpoint_url",
+			mcp.Description("A2A endpoint URL (defaults to the Kubernetes service URL: http://<name>.<namespace>.svc.cluster.local)"),
+		),
+		mcp.WithString("tests",
+			mcp.Description("Comma-separated names of the cases to run (default: all)"),
+		),
+		mcp.WithBoolean("judge",
+			mcp.Description("Judge behavior expectations with an LLM (default: true)"),
+		),
+		withAsyncOption(),
+	)
+
+	ts.addTool(tool, ts.withAsync("run_agent_tests", ts.handleRunAgentTests))
+}
+
+func (ts *ToolServer) handleRunAgentTests(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	endpointURL := args.String("endpoint_url")
+	only := args.StringList("tests")
+	judge := args.Bool("judge", true)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	configMapName := testsConfigMapName(name, agent.Annotations)
+	data, found, err := ts.kube(ctx).GetConfigMapData(ctx, configMapName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read tests: %v", err)), nil
+	}
+	if !found {
+		return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' has no tests (ConfigMap '%s' not found). Use create_agent_tests to add them.", name, configMapName)), nil
+	}
+	suite, err := agenttest.Parse(data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid tests in ConfigMap '%s': %v", configMapName, err)), nil
+	}
+
+	if endpointURL == "" {
+		namespace := agent.Namespace
+		if namespace == "" {
+			namespace = ts.kube(ctx).Namespace()
+		}
+		endpointURL = fmt.Sprintf("http://%s.%s.svc.cluster.local", name, namespace)
+	}
+
+	opts := agenttest.Options{Timeout: agentTestTimeout, Only: only}
+	if judge {
+		opts.Judge = ts.judgeBehavior
+	}
+	report := agenttest.Run(ctx, http.DefaultClient, endpointURL, suite, opts)
+	if len(report.Results) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("No test cases matched: %s", strings.Join(only, ", "))), nil
+	}
+
+	verdict := "PASSED"
+	if report.Failed > 0 || report.Errors > 0 {
+		verdict = "FAILED"
+	}
+	output, _ := json.MarshalIndent(report, "", "  ")
+
+	return mcp.NewToolResultText(fmt.Sprintf(`# Agent Tests for '%s': %s
+# %d passed, %d failed, %d errors
+
+%s`, name, verdict, report.Passed, report.Failed, report.Errors, string(output))), nil
+}
+
+// judgeBehavior asks the sampler whether a reply shows the expected
+// behavior. Sampling being unavailable is reported as an error, so the
+// expectation is noted rather than failed.
+func (ts *ToolServer) judgeBehavior(ctx context.Context, behavior, input, reply string) (bool, string, error) {
+	verdict, err := ts.server.Sampler().Sample(ctx, sampling.Request{
+		SystemPrompt: "You grade replies of AI agents in regression tests. Be strict but fair, and judge only the described behavior.",
+		Prompt: fmt.Sprintf("Expected behavior: %s\n\nUser input:\n%s\n\nAgent reply:\n%s\n\n"+
+			"Does the reply show the expected behavior? Answer with PASS or FAIL on the first line, then one sentence explaining why.", behavior, input, reply),
+		MaxTokens: 256,
+	})
+	if errors.Is(err, sampling.ErrUnavailable) {
+		return false, "", errors.New("no LLM available")
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	verdict = strings.TrimSpace(verdict)
+	first, reason, _ := strings.Cut(verdict, "\n")
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = first
+	}
+	switch strings.ToUpper(strings.Trim(strings.TrimSpace(first), "*.:")) {
+	case "PASS":
+		return true, reason, nil
+	case "FAIL":
+		return false, reason, nil
+	}
+	return false, "", fmt.Errorf("unexpected verdict %q", first)
+}
+
+// checkAgentTests warns when an agent has no test cases attached. Agents in
+// other namespaces and unreadable ConfigMaps are not judged.
+func (ts *ToolServer) checkAgentTests(ctx context.Context, obj *unstructured.Unstructured) []ValidationIssue {
+	if ns := obj.GetNamespace(); ns != "" && ns != ts.kube(ctx).Namespace() {
+		return nil
+	}
+
+	name := testsConfigMapName(obj.GetName(), obj.GetAnnotations())
+	if _, found, err := ts.kube(ctx).GetConfigMapData(ctx, name); err != nil || found {
+		return nil
+	}
+	return []ValidationIssue{{
+		Severity: "warning",
+		Field:    "metadata.name",
+		Message:  fmt.Sprintf("Agent has no test cases (ConfigMap '%s'). Consider adding them with create_agent_tests to catch regressions from prompt changes.", name),
+	}}
+}
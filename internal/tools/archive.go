@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kagent-dev/meta-kagent/internal/archive"
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// registerArchiveAgent registers the archive_agent tool.
+func (ts *ToolServer) registerArchiveAgent() {
+	tool := mcp.NewTool("archive_agent",
+		mcp.WithDescription("Retire an agent without losing it: exports the agent manifest to the archive, then deletes the live resource. Archived agents can be brought back with restore_archived_agent. Use dry_run=true to preview."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the agent to archive"),
+		),
+		mcp.WithString("reason",
+			mcp.Description("Why the agent is being retired, recorded with the archive entry"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("If true, only show what would be archived without deleting the agent"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleArchiveAgent)
+}
+
+func (ts *ToolServer) handleArchiveAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	reason := args.String("reason")
+	dryRun := args.Bool("dry_run", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Agent not found: %v", err)), nil
+	}
+
+	manifest, err := ts.kube(ctx).GetCurrentState(ctx, "", "Agent", name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to export agent: %v", err)), nil
+	}
+
+	if dryRun {
+		return mcp.NewToolResultText(fmt.Sprintf(`# Dry Run: Archive Agent
+
+Agent '%s' in namespace '%s' would be archived and deleted.
+Archived manifest:
+---
+%s
+To actually archive, call archive_agent with dry_run=false.`, name, agent.Namespace, manifest)), nil
+	}
+
+	// Store the manifest before deleting so the agent is never lost
+	entry := archive.Entry{
+		Name:        name,
+		Namespace:   agent.Namespace,
+		Description: agent.Spec.Description,
+		Reason:      reason,
+		ArchivedAt:  time.Now().UTC(),
+		Manifest:    manifest,
+	}
+	if err := ts.archiveStore(ctx).Add(ctx, entry); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to archive agent: %v", err)), nil
+	}
+
+	if err := ts.kube(ctx).Delete(ctx, "Agent", name, false); err != nil {
+		// Keep the archive consistent with the cluster
+		_ = ts.archiveStore(ctx).Remove(ctx, name)
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete agent: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Archived and deleted agent '%s'. Restore it with restore_archived_agent.", name)), nil
+}
+
+// registerListArchivedAgents registers the list_archived_agents tool.
+func (ts *ToolServer) registerListArchivedAgents() {
+	tool := mcp.NewTool("list_archived_agents",
+		mcp.WithDescription("List agents retired with archive_agent, most recent first, with when and why they were archived."),
+		mcp.WithBoolean("include_manifests",
+			mcp.Description("Include the archived manifests (default: false)"),
+		),
+		withLimitOption(),
+		withContinueOption(),
+	)
+
+	ts.addTool(tool, ts.handleListArchivedAgents)
+}
+
+func (ts *ToolServer) handleListArchivedAgents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	includeManifests := args.Bool("include_manifests", false)
+	p := ts.pageFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	entries, err := ts.archiveStore(ctx).List(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list archived agents: %v", err)), nil
+	}
+	if len(entries) == 0 {
+		return mcp.NewToolResultText("No archived agents."), nil
+	}
+	entries, next, err := paginate(entries, p)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if !includeManifests {
+		for i := range entries {
+			entries[i].Manifest = ""
+		}
+	}
+
+	output, _ := json.MarshalIndent(entries, "", "  ")
+	return mcp.NewToolResultText(string(output) + continueNote(len(entries), next)), nil
+}
+
+// registerRestoreArchivedAgent registers the restore_archived_agent tool.
+func (ts *ToolServer) registerRestoreArchivedAgent() {
+	tool := mcp.NewTool("restore_archived_agent",
+		mcp.WithDescription("Recreate an agent from the archive and remove its archive entry. Fails if an agent with the same name already exists. Use dry_run=true to preview."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the archived agent to restore"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("If true, validate the restore against the cluster without creating the agent"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleRestoreArchivedAgent)
+}
+
+func (ts *ToolServer) handleRestoreArchivedAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	dryRun := args.Bool("dry_run", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	entry, found, err := ts.archiveStore(ctx).Get(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read archive: %v", err)), nil
+	}
+	if !found {
+		return mcp.NewToolResultError(fmt.Sprintf("No archived agent named '%s'. Use list_archived_agents to see archived agents.", name)), nil
+	}
+
+	if _, err := ts.kube(ctx).GetAgent(ctx, name); err == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' already exists. Delete or rename it before restoring.", name)), nil
+	} else if !apierrors.IsNotFound(err) {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to check for existing agent: %v", err)), nil
+	}
+
+	result, err := ts.applyDocument(ctx, entry.Manifest, dryRun, kubernetes.Preconditions{})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to restore agent: %v", err)), nil
+	}
+
+	if dryRun {
+		return mcp.NewToolResultText(fmt.Sprintf("# Dry Run Successful\n\nAgent '%s' in namespace '%s' would be restored (archived %s).\n\nTo actually restore, call restore_archived_agent with dry_run=false.",
+			name, result.Namespace, entry.ArchivedAt.Format(time.RFC3339))), nil
+	}
+
+	if err := ts.archiveStore(ctx).Remove(ctx, name); err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("Restored agent '%s', but failed to remove its archive entry: %v", name, err)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Restored agent '%s' in namespace '%s'.", name, result.Namespace)), nil
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kagent-dev/meta-kagent/internal/cache"
+)
+
+// withResultCache wraps a derived tool so that repeated calls return the
+// previous result while none of its inputs changed. Inputs are the kinds the
+// tool reads; the cache key covers the session, namespace and arguments, and
+// the entry is invalidated when any input resource's resourceVersion changes.
+// Only successful text results are cached.
+func (ts *ToolServer) withResultCache(name string, inputs []schema.GroupVersionResource, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var objs []unstructured.Unstructured
+		for _, gvr := range inputs {
+			items, err := ts.kube(ctx).ListResources(ctx, gvr)
+			if err != nil {
+				// Without a fingerprint the result cannot be validated
+				return handler(ctx, req)
+			}
+			objs = append(objs, items...)
+		}
+
+		arguments, _ := json.Marshal(req.Params.Arguments)
+		key := name + "\x00" + sessionOwner(ctx) + "\x00" + ts.kube(ctx).Namespace() + "\x00" + string(arguments)
+		fingerprint := cache.Fingerprint(objs)
+
+		if text, ok := ts.results.Get(key, fingerprint); ok {
+			return mcp.NewToolResultText(text), nil
+		}
+
+		result, err := handler(ctx, req)
+		if err == nil && !result.IsError {
+			ts.results.Put(key, fingerprint, resultText(result))
+		}
+		return result, err
+	}
+}
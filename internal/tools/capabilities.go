@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/capabilities"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// capabilityProbeTimeout bounds the detection of the cluster's
+// capabilities.
+const capabilityProbeTimeout = 15 * time.Second
+
+// gatedTool is a tool only offered where the cluster has the capabilities
+// its output needs.
+type gatedTool struct {
+	name       string
+	needs      []string
+	register   func()
+	registered bool
+}
+
+// toolGates holds the detected capabilities and the tools waiting for
+// them.
+type toolGates struct {
+	mu           sync.Mutex
+	capabilities *capabilities.Set
+	tools        []*gatedTool
+}
+
+// detectCapabilities probes the cluster for its capabilities and registers
+// the gated tools they make available. It returns the names of the tools
+// it registered.
+func (ts *ToolServer) detectCapabilities(ctx context.Context) []string {
+	ctx, cancel := context.WithTimeout(ctx, capabilityProbeTimeout)
+	defer cancel()
+	set := capabilities.Detect(ctx, ts.server.K8sClient(), ts.server.Config().AssumeCapabilities)
+
+	ts.gates.mu.Lock()
+	ts.gates.capabilities = set
+	var ready []*gatedTool
+	for _, t := range ts.gates.tools {
+		if !t.registered && len(set.Missing(t.needs)) == 0 {
+			t.registered = true
+			ready = append(ready, t)
+		}
+	}
+	ts.gates.mu.Unlock()
+
+	var names []string
+	for _, t := range ready {
+		t.register()
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// registerWhenAvailable registers a tool with register when the cluster
+// has the capabilities it needs; otherwise the tool is listed by
+// list_unavailable_tools until a refresh finds them.
+func (ts *ToolServer) registerWhenAvailable(name string, register func(), needs ...string) {
+	ts.gates.mu.Lock()
+	t := &gatedTool{name: name, needs: needs, register: register}
+	ts.gates.tools = append(ts.gates.tools, t)
+	available := ts.gates.capabilities == nil || len(ts.gates.capabilities.Missing(needs)) == 0
+	t.registered = available
+	ts.gates.mu.Unlock()
+
+	if available {
+		register()
+		return
+	}
+	ts.server.ReserveToolName(name)
+	fmt.Fprintf(os.Stderr, "Tool %s is unavailable: the cluster lacks %s\n", name, strings.Join(needs, ", "))
+}
+
+// hasCapability reports whether the cluster has the named capability, as
+// last detected. Before detection every capability counts as available.
+func (ts *ToolServer) hasCapability(name string) bool {
+	ts.gates.mu.Lock()
+	defer ts.gates.mu.Unlock()
+	return ts.gates.capabilities == nil || ts.gates.capabilities.Has(name)
+}
+
+// registerListUnavailableTools registers the list_unavailable_tools tool.
+func (ts *ToolServer) registerListUnavailableTools() {
+	tool := mcp.NewTool("list_unavailable_tools",
+		mcp.WithDescription("List the tools this server does not offer because the cluster lacks an add-on their output needs (cert-manager, Prometheus Operator, OpenTelemetry Operator, ...), what is missing and how to install it, together with the capabilities detected in the cluster. With refresh, probes the cluster again and offers the tools whose add-ons have been installed since."),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Detect the cluster's capabilities again instead of using those detected at startup (default: false)"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleListUnavailableTools)
+}
+
+// unavailableTool describes a tool in list_unavailable_tools.
+type unavailableTool struct {
+	Name    string   `json:"name"`
+	Missing []string `json:"missing"`
+	Install []string `json:"install"`
+}
+
+func (ts *ToolServer) handleListUnavailableTools(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	refresh := args.Bool("refresh", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var registered []string
+	if refresh {
+		registered = ts.detectCapabilities(ctx)
+	}
+
+	ts.gates.mu.Lock()
+	result := struct {
+		*capabilities.Set
+		UnavailableTools []unavailableTool `json:"unavailableTools"`
+		// NowAvailable are the tools the refresh registered.
+		NowAvailable []string `json:"nowAvailable,omitempty"`
+	}{Set: ts.gates.capabilities, UnavailableTools: []unavailableTool{}, NowAvailable: registered}
+	for _, t := range ts.gates.tools {
+		if t.registered {
+			continue
+		}
+		tool := unavailableTool{Name: t.name, Missing: ts.gates.capabilities.Missing(t.needs)}
+		for _, name := range tool.Missing {
+			c, _ := capabilities.Lookup(name)
+			tool.Install = append(tool.Install, fmt.Sprintf("%s: %s", c.Description, c.Install))
+		}
+		result.UnavailableTools = append(result.UnavailableTools, tool)
+	}
+	ts.gates.mu.Unlock()
+
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode unavailable tools: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(output)), nil
+}
@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/endpoints"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// Agent card export formats.
+const (
+	cardExportJSONL = "jsonl"
+	cardExportZip   = "zip"
+)
+
+// cardUploadTimeout bounds the upload of an export to object storage.
+const cardUploadTimeout = 60 * time.Second
+
+// cardExportManifest is the index written at the root of a zip export.
+type cardExportManifest struct {
+	Environment string    `json:"environment,omitempty"`
+	ExportedAt  time.Time `json:"exportedAt"`
+	Cards       []string  `json:"cards"`
+}
+
+// registerExportAgentCards registers the export_agent_cards tool.
+func (ts *ToolServer) registerExportAgentCards() {
+	tool := mcp.NewTool("export_agent_cards",
+		mcp.WithDescription("Generate the A2A Agent Cards of all agents that declare A2A skills and package them for an external agent registry or marketplace: as JSON lines (one card per line) or a zip with one JSON file per card plus an index. Agents can be filtered by label selector, skill tag or name, and endpoint URLs are built from a template per environment. The package is returned, or uploaded to object storage through a pre-signed URL."),
+		mcp.WithString("format",
+			mcp.Description("Package format: 'jsonl' (default) or 'zip' (<namespace>/<name>.json per card and index.json; returned base64-encoded unless uploaded)"),
+		),
+		mcp.WithString("selector",
+			mcp.Description("Label selector the agents must match (e.g., 'team=platform')"),
+		),
+		mcp.WithString("skill_tag",
+			mcp.Description("Only export agents with a skill carrying this tag"),
+		),
+		mcp.WithString("agents",
+			mcp.Description("Comma-separated names of the agents to export (default: all A2A-enabled agents)"),
+		),
+		mcp.WithString("endpoint_template",
+			mcp.Description("Template of each card's URL with the placeholders {{agent}}, {{namespace}}, {{environment}} and the cluster facts ({{cluster}}, {{region}}, {{ingress_domain}}...), e.g. 'https://{{agent}}.{{environment}}.agents.example.com' (default: the environment's URL template, or without environments the in-cluster Service URL)"),
+		),
+		mcp.WithString("environment",
+			mcp.Description("Environment name substituted for {{environment}} and recorded in the zip index (e.g., 'staging'). When environments are configured in KAGENT_ENVIRONMENTS_FILE, one of them (default: the server's environment), whose URL template and allowed domains apply"),
+		),
+		mcp.WithString("upload_url",
+			mcp.Description("Pre-signed object storage URL (S3, GCS or Azure Blob) to upload the package to with HTTP PUT, instead of returning it"),
+		),
+		withAllNamespacesOption(),
+	)
+
+	ts.addTool(tool, ts.handleExportAgentCards)
+}
+
+func (ts *ToolServer) handleExportAgentCards(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	format := args.Enum("format", cardExportJSONL, cardExportJSONL, cardExportZip)
+	selector := args.String("selector")
+	skillTag := args.String("skill_tag")
+	names := args.StringList("agents")
+	endpointTemplate := args.String("endpoint_template")
+	environment := args.String("environment")
+	uploadURL := args.String("upload_url")
+	allNamespaces := args.Bool("all_namespaces", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Without configured environments, environment is only a label
+	var env *endpoints.Environment
+	if ts.environments != nil {
+		var err error
+		if env, err = ts.environments.Lookup(environment); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if env != nil {
+			environment = env.Name
+		}
+	}
+	if strings.Contains(endpointTemplate, endpoints.PlaceholderEnvironment) && environment == "" {
+		return mcp.NewToolResultError("endpoint_template uses {{environment}}; set environment"), nil
+	}
+	if err := ts.expandFacts(ctx, &endpointTemplate); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid endpoint_template: %v", err)), nil
+	}
+	if uploadURL != "" {
+		if u, err := url.Parse(uploadURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return mcp.NewToolResultError("upload_url must be an http(s) URL"), nil
+		}
+	}
+
+	client, err := ts.listClient(ctx, allNamespaces, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	agents, err := client.ListAgentsBySelector(ctx, selector)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list agents: %v", err)), nil
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		if agents[i].Namespace != agents[j].Namespace {
+			return agents[i].Namespace < agents[j].Namespace
+		}
+		return agents[i].Name < agents[j].Name
+	})
+
+	var cards []types.AgentCard
+	var keys, warnings []string
+	for i := range agents {
+		agent := &agents[i]
+		a2a := getA2AConfig(agent)
+		if a2a == nil || len(a2a.Skills) == 0 {
+			continue
+		}
+		if len(names) > 0 && !containsString(names, agent.Name) {
+			continue
+		}
+		if skillTag != "" && !hasSkillTag(a2a.Skills, skillTag) {
+			continue
+		}
+
+		var explicit string
+		if endpointTemplate != "" {
+			explicit = strings.NewReplacer(
+				endpoints.PlaceholderAgent, agent.Name,
+				endpoints.PlaceholderNamespace, agent.Namespace,
+				endpoints.PlaceholderEnvironment, environment,
+			).Replace(endpointTemplate)
+		}
+		endpointURL, warning := ts.agentEndpoint(ctx, env, agent, explicit)
+		if warning != "" {
+			warnings = append(warnings, fmt.Sprintf("%s/%s: %s", agent.Namespace, agent.Name, warning))
+		}
+		cards = append(cards, buildAgentCard(agent, endpointURL))
+		keys = append(keys, agent.Namespace+"/"+agent.Name)
+	}
+
+	if len(cards) == 0 {
+		return mcp.NewToolResultText("No A2A-enabled agents match the filters. Agents are exported when they declare skills in their a2aConfig."), nil
+	}
+
+	var data []byte
+	contentType := "application/x-ndjson"
+	if format == cardExportZip {
+		data, err = zipAgentCards(cards, keys, environment)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		contentType = "application/zip"
+	} else {
+		var b bytes.Buffer
+		for _, card := range cards {
+			line, _ := json.Marshal(card)
+			b.Write(line)
+			b.WriteByte('\n')
+		}
+		data = b.Bytes()
+	}
+
+	header := fmt.Sprintf("# Exported %d A2A Agent Card(s) as %s: %s", len(cards), format, strings.Join(keys, ", "))
+	for _, warning := range warnings {
+		header += "\n# WARNING: " + warning
+	}
+	if uploadURL != "" {
+		if err := uploadExport(ctx, uploadURL, contentType, data); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to upload the export: %v", err)), nil
+		}
+		// The query string of a pre-signed URL carries its signature
+		u, _ := url.Parse(uploadURL)
+		u.RawQuery = ""
+		return mcp.NewToolResultText(fmt.Sprintf("%s\n# Uploaded %d bytes to %s", header, len(data), u)), nil
+	}
+
+	if format == cardExportZip {
+		return mcp.NewToolResultText(header + "\n# Zip archive, base64-encoded:\n\n" + base64.StdEncoding.EncodeToString(data)), nil
+	}
+	return mcp.NewToolResultText(header + "\n\n" + string(data)), nil
+}
+
+// hasSkillTag reports whether any skill carries the tag.
+func hasSkillTag(skills []types.Skill, tag string) bool {
+	for _, skill := range skills {
+		if containsString(skill.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// zipAgentCards packages cards as <namespace>/<name>.json files with an
+// index.json listing them.
+func zipAgentCards(cards []types.AgentCard, keys []string, environment string) ([]byte, error) {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	index := cardExportManifest{Environment: environment, ExportedAt: time.Now().UTC()}
+
+	for i, card := range cards {
+		path := keys[i] + ".json"
+		data, _ := json.MarshalIndent(card, "", "  ")
+		f, err := w.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to the archive: %w", path, err)
+		}
+		if _, err := f.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to add %s to the archive: %w", path, err)
+		}
+		index.Cards = append(index.Cards, path)
+	}
+
+	data, _ := json.MarshalIndent(index, "", "  ")
+	f, err := w.Create("index.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to add index.json to the archive: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to add index.json to the archive: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write the archive: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// uploadExport PUTs data to a pre-signed object storage URL.
+func uploadExport(ctx context.Context, uploadURL, contentType string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	// Azure Blob requires the blob type; other stores ignore it
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+
+	resp, err := (&http.Client{Timeout: cardUploadTimeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/ci"
+	mcpserver "github.com/kagent-dev/meta-kagent/internal/server"
+	"github.com/kagent-dev/meta-kagent/internal/state"
+)
+
+// Checks run by CheckDocuments, as named in CI reports.
+const (
+	checkParse      = "parse"
+	checkValidation = "validation"
+	checkSecurity   = "security-review"
+)
+
+// CheckDocuments runs the checks of validate_manifest and security_review
+// on documents as one bundle, so references between files resolve, and
+// reports what they find for CI. strict and checkToolNames are the
+// validate_manifest options of the same name.
+func CheckDocuments(ctx context.Context, s *mcpserver.Server, docs []ci.Document, strict, checkToolNames bool) *ci.Report {
+	ts := newToolServer(s, state.NewMemoryStore())
+	ts.loadCRDSchemas()
+	ts.loadValidationPolicies()
+	report := &ci.Report{Documents: docs, Checks: []string{checkParse, checkValidation, checkSecurity}}
+	add := func(doc int, check, severity, resource, field, message string) {
+		report.Findings = append(report.Findings, ci.Finding{
+			Document: doc,
+			File:     docs[doc].File,
+			Line:     docs[doc].Line,
+			Resource: resource,
+			Check:    check,
+			Severity: severity,
+			Field:    field,
+			Message:  message,
+		})
+	}
+
+	// Documents that do not parse are reported and left out of the bundle
+	var objs []*unstructured.Unstructured
+	var indexes []int
+	for i, doc := range docs {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(doc.Content), &obj.Object); err != nil {
+			add(i, checkParse, ci.SeverityError, "", "", fmt.Sprintf("Failed to parse document: %v", err))
+			continue
+		}
+		objs = append(objs, obj)
+		indexes = append(indexes, i)
+	}
+	ctx = withBundle(ctx, objs, ts.kube(ctx).Namespace())
+
+	// Security findings name resources as bundleResource does
+	positions := map[string]int{}
+	for i, obj := range objs {
+		positions[bundleResource(objs, i)] = i
+		name := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+		for _, issue := range ts.validateObject(ctx, obj, strict, checkToolNames) {
+			add(indexes[i], checkValidation, issue.Severity, name, issue.Field, issue.Message)
+		}
+	}
+
+	review := ts.reviewSecurity(ctx, objs)
+	for _, f := range review.Findings {
+		i, ok := positions[f.Resource]
+		if !ok {
+			continue
+		}
+		severity := ci.SeverityWarning
+		if f.Severity == SecurityBlock {
+			severity = ci.SeverityError
+		}
+		add(indexes[i], checkSecurity, severity, fmt.Sprintf("%s/%s", objs[i].GetKind(), objs[i].GetName()), f.Field, fmt.Sprintf("[%s] %s", f.Category, f.Message))
+	}
+
+	report.Sort()
+	return report
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/clusterfacts"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// facts returns the facts of the cluster the server manages, discovered
+// with the server's identity.
+func (ts *ToolServer) facts(ctx context.Context) *clusterfacts.Facts {
+	return ts.clusterFacts.Get(ctx, false)
+}
+
+// expandFacts replaces the cluster fact placeholders in values in place.
+// Facts are only discovered when a value uses them.
+func (ts *ToolServer) expandFacts(ctx context.Context, values ...*string) error {
+	var facts *clusterfacts.Facts
+	for _, v := range values {
+		if !clusterfacts.Uses(*v) {
+			continue
+		}
+		if facts == nil {
+			facts = ts.facts(ctx)
+		}
+		expanded, err := facts.Expand(*v)
+		if err != nil {
+			return err
+		}
+		*v = expanded
+	}
+	return nil
+}
+
+// registerGetClusterFacts registers the get_cluster_facts tool.
+func (ts *ToolServer) registerGetClusterFacts() {
+	tool := mcp.NewTool("get_cluster_facts",
+		mcp.WithDescription("Show the facts known about the cluster (name, cloud provider, region, ingress domain) and where each came from. Manifest generators, prompts and endpoint URL templates substitute them for {{cluster}}, {{cloud}}, {{region}} and {{ingress_domain}}."),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Discover the facts again instead of using those discovered in the last 10 minutes (default: false)"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleGetClusterFacts)
+}
+
+func (ts *ToolServer) handleGetClusterFacts(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	refresh := args.Bool("refresh", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	facts := ts.clusterFacts.Get(ctx, refresh)
+	output, err := json.MarshalIndent(facts, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode cluster facts: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(output)), nil
+}
@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// rbacPresets are the permission presets of generate_rbac_manifest.
+var rbacPresets = []string{"readonly", "standard", "admin"}
+
+// registerCompareRBAC registers the compare_rbac tool.
+func (ts *ToolServer) registerCompareRBAC() {
+	tool := mcp.NewTool("compare_rbac",
+		mcp.WithDescription("Compare the effective permissions of two RBAC sources and list the verbs gained and lost per resource. Each side is a generate_rbac_manifest preset (readonly, standard, admin), an existing Role (Role/<name>) or an existing ClusterRole (ClusterRole/<name>). Use it when tightening or widening an agent's access to state the exact change. Wildcards are taken into account: a permission covered by a '*' on the other side is neither gained nor lost."),
+		mcp.WithString("from",
+			mcp.Required(),
+			mcp.Description("Current permissions: a preset ('readonly', 'standard', 'admin'), 'Role/<name>' or 'ClusterRole/<name>'"),
+		),
+		mcp.WithString("to",
+			mcp.Required(),
+			mcp.Description("Proposed permissions, in the same forms as 'from'"),
+		),
+		withStructuredOutputOption(),
+	)
+
+	ts.addTool(tool, ts.handleCompareRBAC)
+}
+
+func (ts *ToolServer) handleCompareRBAC(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	fromRef := args.RequiredString("from")
+	toRef := args.RequiredString("to")
+	asStructured := structuredOutputFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	fromRules, fromLabel, err := ts.rbacRules(ctx, fromRef)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("from: %v", err)), nil
+	}
+	toRules, toLabel, err := ts.rbacRules(ctx, toRef)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("to: %v", err)), nil
+	}
+
+	from, to := expandRules(fromRules), expandRules(toRules)
+	gained := uncovered(to, from)
+	lost := uncovered(from, to)
+
+	var lines []string
+	for _, line := range groupPermissions(gained) {
+		lines = append(lines, "+ "+line)
+	}
+	for _, line := range groupPermissions(lost) {
+		lines = append(lines, "- "+line)
+	}
+
+	if len(lines) == 0 {
+		summary := fmt.Sprintf("%s and %s grant the same effective permissions.", fromLabel, toLabel)
+		if asStructured {
+			return structured(structuredResult{Summary: summary})
+		}
+		return mcp.NewToolResultText(summary), nil
+	}
+	summary := fmt.Sprintf("From %s to %s: %d permission(s) gained, %d lost.", fromLabel, toLabel, len(gained), len(lost))
+	if asStructured {
+		return structured(structuredResult{Summary: summary, Diff: strings.Join(lines, "\n")})
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf(`# RBAC Comparison
+# From: %s
+# To:   %s
+# %d permission(s) gained (+), %d lost (-), as resource: verbs.
+
+%s`, fromLabel, toLabel, len(gained), len(lost), strings.Join(lines, "\n"))), nil
+}
+
+// rbacRules resolves one side of compare_rbac to its rules and a label
+// describing it.
+func (ts *ToolServer) rbacRules(ctx context.Context, ref string) ([]kubernetes.PolicyRule, string, error) {
+	if containsString(rbacPresets, ref) {
+		_, role, _ := rbacManifests("preset", ts.kube(ctx).Namespace(), ref)
+		var parsed struct {
+			Rules []kubernetes.PolicyRule `json:"rules"`
+		}
+		if err := yaml.Unmarshal([]byte(role), &parsed); err != nil {
+			return nil, "", fmt.Errorf("invalid preset '%s': %w", ref, err)
+		}
+		return parsed.Rules, fmt.Sprintf("preset '%s'", ref), nil
+	}
+
+	kind, name, ok := strings.Cut(ref, "/")
+	if !ok || name == "" {
+		return nil, "", fmt.Errorf("'%s' is not a preset (%s), Role/<name> or ClusterRole/<name>", ref, strings.Join(rbacPresets, ", "))
+	}
+	client := ts.kube(ctx)
+	switch strings.ToLower(kind) {
+	case "role":
+		rules, err := client.RoleRules(ctx, "", name)
+		return rules, fmt.Sprintf("Role '%s' in namespace '%s'", name, client.Namespace()), err
+	case "clusterrole":
+		rules, err := client.ClusterRoleRules(ctx, name)
+		return rules, fmt.Sprintf("ClusterRole '%s'", name), err
+	}
+	return nil, "", fmt.Errorf("unknown kind '%s': expected Role or ClusterRole", kind)
+}
+
+// rbacPermission is a single verb a rule allows on a resource (optionally
+// one named resource) or on a non-resource URL.
+type rbacPermission struct {
+	Group, Resource, ResourceName string
+	NonResourceURL                string
+	Verb                          string
+}
+
+// expandRules flattens rules into the distinct permissions they grant.
+func expandRules(rules []kubernetes.PolicyRule) []rbacPermission {
+	var perms []rbacPermission
+	seen := map[rbacPermission]bool{}
+	add := func(p rbacPermission) {
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, p)
+		}
+	}
+	for _, rule := range rules {
+		for _, verb := range rule.Verbs {
+			for _, url := range rule.NonResourceURLs {
+				add(rbacPermission{NonResourceURL: url, Verb: verb})
+			}
+			names := rule.ResourceNames
+			if len(names) == 0 {
+				names = []string{""}
+			}
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					for _, name := range names {
+						add(rbacPermission{Group: group, Resource: resource, ResourceName: name, Verb: verb})
+					}
+				}
+			}
+		}
+	}
+	return perms
+}
+
+// uncovered returns the permissions of perms that no permission of other
+// grants.
+func uncovered(perms, other []rbacPermission) []rbacPermission {
+	var result []rbacPermission
+	for _, p := range perms {
+		covered := false
+		for _, o := range other {
+			if o.covers(p) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// covers reports whether p grants everything q grants.
+func (p rbacPermission) covers(q rbacPermission) bool {
+	if !matchesRBAC(p.Verb, q.Verb) {
+		return false
+	}
+	if p.NonResourceURL != "" || q.NonResourceURL != "" {
+		if p.NonResourceURL == "" || q.NonResourceURL == "" {
+			return false
+		}
+		if prefix, ok := strings.CutSuffix(p.NonResourceURL, "*"); ok {
+			return strings.HasPrefix(q.NonResourceURL, prefix)
+		}
+		return p.NonResourceURL == q.NonResourceURL
+	}
+	return matchesRBAC(p.Group, q.Group) &&
+		matchesRBAC(p.Resource, q.Resource) &&
+		(p.ResourceName == "" || p.ResourceName == q.ResourceName)
+}
+
+// matchesRBAC reports whether a rule value (possibly '*') matches value.
+func matchesRBAC(ruleValue, value string) bool {
+	return ruleValue == "*" || ruleValue == value
+}
+
+// groupPermissions renders perms as one sorted line per resource, listing
+// its verbs.
+func groupPermissions(perms []rbacPermission) []string {
+	verbs := map[string][]string{}
+	for _, p := range perms {
+		var target string
+		switch {
+		case p.NonResourceURL != "":
+			target = "nonResourceURL " + p.NonResourceURL
+		case p.Group == "":
+			target = p.Resource
+		default:
+			target = p.Resource + "." + p.Group
+		}
+		if p.ResourceName != "" {
+			target += fmt.Sprintf(" (name: %s)", p.ResourceName)
+		}
+		if !containsString(verbs[target], p.Verb) {
+			verbs[target] = append(verbs[target], p.Verb)
+		}
+	}
+
+	var lines []string
+	for _, target := range sortedKeys(verbs) {
+		sort.Strings(verbs[target])
+		lines = append(lines, fmt.Sprintf("%s: %s", target, strings.Join(verbs[target], ", ")))
+	}
+	return lines
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/conformance"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// Timeouts for conformance requests. message/send waits for a model call.
+const (
+	conformanceRequestTimeout = 30 * time.Second
+	conformanceSendTimeout    = 2 * time.Minute
+)
+
+// registerRunA2AConformance registers the run_a2a_conformance tool.
+func (ts *ToolServer) registerRunA2AConformance() {
+	tool := mcp.NewTool("run_a2a_conformance",
+		mcp.WithDescription("Run the A2A conformance suite against a deployed agent's endpoint: Agent Card discovery and required fields, the message/send, tasks/get and tasks/cancel lifecycle, and JSON-RPC error handling. Returns a pass/fail report per check."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the agent to test"),
+		),
+		mcp.WithString("endpoint_url",
+			mcp.Description("A2A endpoint URL (defaults to the Kubernetes service URL: http://<name>.<namespace>.svc.cluster.local)"),
+		),
+		mcp.WithBoolean("lifecycle",
+			mcp.Description("Send a real message to exercise the task lifecycle. This costs a model call (default: true)"),
+		),
+		mcp.WithString("message",
+			mcp.Description("Text sent by the lifecycle checks (default: a short ping)"),
+		),
+		withAsyncOption(),
+	)
+
+	ts.addTool(tool, ts.withAsync("run_a2a_conformance", ts.handleRunA2AConformance))
+}
+
+func (ts *ToolServer) handleRunA2AConformance(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	endpointURL := args.String("endpoint_url")
+	lifecycle := args.Bool("lifecycle", true)
+	message := args.StringDefault("message", "Reply with the single word: pong")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	if endpointURL == "" {
+		namespace := agent.Namespace
+		if namespace == "" {
+			namespace = ts.kube(ctx).Namespace()
+		}
+		endpointURL = fmt.Sprintf("http://%s.%s.svc.cluster.local", name, namespace)
+	}
+
+	report := conformance.Run(ctx, http.DefaultClient, endpointURL, conformance.Options{
+		Lifecycle:      lifecycle,
+		Message:        message,
+		RequestTimeout: conformanceRequestTimeout,
+		SendTimeout:    conformanceSendTimeout,
+	})
+
+	verdict := "CONFORMANT"
+	if !report.Conformant() {
+		verdict = "NOT CONFORMANT"
+	}
+	output, _ := json.MarshalIndent(report, "", "  ")
+
+	return mcp.NewToolResultText(fmt.Sprintf(`# A2A Conformance for '%s': %s
+# %d passed, %d failed, %d skipped
+
+%s`, name, verdict, report.Passed, report.Failed, report.Skipped, string(output))), nil
+}
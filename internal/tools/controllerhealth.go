@@ -0,0 +1,390 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/slo"
+)
+
+// Controller health verdicts.
+const (
+	ControllerHealthy   = "healthy"
+	ControllerDegraded  = "degraded"
+	ControllerUnhealthy = "unhealthy"
+)
+
+// Reconcile error rates (errors per reconcile) above which controller_health
+// warns and fails.
+const (
+	reconcileErrorRateWarn = 0.05
+	reconcileErrorRateFail = 0.5
+)
+
+// reconcilerErrorLog is the message controller-runtime logs when a
+// reconcile returns an error.
+const reconcilerErrorLog = "Reconciler error"
+
+// maxErrorLogLength bounds the error log line controller_health quotes.
+const maxErrorLogLength = 500
+
+// ControllerHealthReport is the health of the kagent controller.
+type ControllerHealthReport struct {
+	Controller string `json:"controller"`
+	Namespace  string `json:"namespace"`
+	Verdict    string `json:"verdict"`
+	// Leader is the controller's leader election Lease.
+	Leader *kubernetes.Lease `json:"leader,omitempty"`
+	// Reconcile is the reconcile error rate over the window.
+	Reconcile *ReconcileErrors `json:"reconcile,omitempty"`
+	Checks    []ReadinessCheck `json:"checks"`
+}
+
+// ReconcileErrors counts failed reconciles, from the controller-runtime
+// metrics in Prometheus or, without them, from the controller's logs.
+type ReconcileErrors struct {
+	Source string `json:"source"` // "metrics" or "logs"
+	Errors int64  `json:"errors"`
+	// Reconciles and Rate are only known from metrics.
+	Reconciles int64   `json:"reconciles,omitempty"`
+	Rate       float64 `json:"rate,omitempty"`
+	// LastError is the latest error logged.
+	LastError string `json:"lastError,omitempty"`
+}
+
+func (r *ControllerHealthReport) add(dependency, name, status, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, ReadinessCheck{
+		Dependency: dependency,
+		Name:       name,
+		Status:     status,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// hint sets the remediation of the check added last.
+func (r *ControllerHealthReport) hint(format string, args ...interface{}) {
+	r.Checks[len(r.Checks)-1].Remediation = fmt.Sprintf(format, args...)
+}
+
+// decide sets the verdict from the checks: unhealthy if any failed,
+// degraded if any warned.
+func (r *ControllerHealthReport) decide() {
+	r.Verdict = ControllerHealthy
+	for _, c := range r.Checks {
+		if c.Status == kubernetes.PreflightFail {
+			r.Verdict = ControllerUnhealthy
+			return
+		}
+		if c.Status == kubernetes.PreflightWarn {
+			r.Verdict = ControllerDegraded
+		}
+	}
+}
+
+// registerControllerHealth registers the controller_health tool.
+func (ts *ToolServer) registerControllerHealth() {
+	tool := mcp.NewTool("controller_health",
+		mcp.WithDescription("Check the health of the kagent controller that reconciles agents: its Deployment's availability, whether a replica holds and renews the leader election Lease, recent container restarts, and the reconcile error rate (from controller-runtime metrics in Prometheus when KAGENT_PROMETHEUS_URL is set, otherwise from the controller's logs). Use it first when all agents stop updating, to tell a controller problem from a manifest problem."),
+		mcp.WithString("window",
+			mcp.Description("How far back to count restarts and reconcile errors, as a duration (e.g., '15m', '1h'). Default: '15m'"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleControllerHealth)
+}
+
+func (ts *ToolServer) handleControllerHealth(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	window := args.Duration("window", 15*time.Minute)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cfg := ts.server.Config()
+	client := ts.kube(ctx).InNamespace(cfg.ControllerNamespace)
+	report := &ControllerHealthReport{Controller: cfg.ControllerName, Namespace: cfg.ControllerNamespace}
+	since := time.Now().Add(-window)
+
+	pods, err := ts.checkControllerDeployment(ctx, client, report, since)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	leader, err := ts.checkControllerLeader(ctx, client, report, pods)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(pods) > 0 {
+		ts.checkReconcileErrors(ctx, client, report, pods, leader, window)
+	}
+	report.decide()
+
+	summary := "The controller looks healthy. If agents are not updating, the cause is more likely in their manifests: run diagnose_agent or get_agent_status on one of them."
+	switch report.Verdict {
+	case ControllerUnhealthy:
+		summary = "The controller is not reconciling reliably. Agents will not pick up changes until it is fixed; their manifests are probably not the cause."
+	case ControllerDegraded:
+		summary = "The controller runs, but some checks warn or could not be verified."
+	}
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	return mcp.NewToolResultText(fmt.Sprintf("# Controller Health: %s/%s\n\nVerdict: %s (last %s)\n%s\n\n%s",
+		cfg.ControllerNamespace, cfg.ControllerName, report.Verdict, window, summary, string(output))), nil
+}
+
+// checkControllerDeployment checks the controller Deployment is available
+// and its containers have not restarted since the given time, and returns
+// its pods.
+func (ts *ToolServer) checkControllerDeployment(ctx context.Context, client *kubernetes.Client, report *ControllerHealthReport, since time.Time) ([]kubernetes.PodStatus, error) {
+	name := report.Controller
+	deployment, known, err := client.GetDeploymentStatus(ctx, name)
+	switch {
+	case apierrors.IsNotFound(err):
+		report.add("Deployment", name, kubernetes.PreflightFail, "Deployment not found in namespace '%s'", report.Namespace)
+		report.hint("If the controller is installed under another name, set KAGENT_CONTROLLER_NAME and KAGENT_CONTROLLER_NAMESPACE")
+		return nil, nil
+	case err != nil:
+		return nil, err
+	case !known:
+		report.add("Deployment", name, kubernetes.PreflightWarn, "could not read the controller Deployment")
+		report.hint("Grant the server's ServiceAccount 'get' on deployments in namespace '%s'", report.Namespace)
+		return nil, nil
+	}
+
+	switch {
+	case deployment.Replicas == 0:
+		report.add("Deployment", name, kubernetes.PreflightFail, "scaled to zero replicas: nothing reconciles agents")
+		report.hint("kubectl scale deployment %s -n %s --replicas=1", name, report.Namespace)
+	case deployment.AvailableReplicas == 0:
+		report.add("Deployment", name, kubernetes.PreflightFail, "no available replicas (%d of %d ready)", deployment.ReadyReplicas, deployment.Replicas)
+		report.hint("Check the controller pods below and: kubectl describe deployment %s -n %s", name, report.Namespace)
+	case deployment.AvailableReplicas < deployment.Replicas:
+		report.add("Deployment", name, kubernetes.PreflightWarn, "%d of %d replicas available", deployment.AvailableReplicas, deployment.Replicas)
+	default:
+		report.add("Deployment", name, kubernetes.PreflightPass, "%d of %d replicas available", deployment.AvailableReplicas, deployment.Replicas)
+	}
+	if deployment.Selector == "" {
+		return nil, nil
+	}
+
+	pods, known, err := client.ListPodStatuses(ctx, deployment.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		report.add("Pods", name, kubernetes.PreflightWarn, "could not list the controller pods")
+		report.hint("Grant the server's ServiceAccount 'list' on pods in namespace '%s'", report.Namespace)
+		return nil, nil
+	}
+
+	for _, pod := range pods {
+		var total int64
+		var recent []string
+		for _, c := range pod.Containers {
+			total += c.Restarts
+			if c.Restarts > 0 && c.LastTerminatedAt.After(since) {
+				recent = append(recent, fmt.Sprintf("container %s restarted %s ago (%s)", c.Name, time.Since(c.LastTerminatedAt).Round(time.Second), c.LastTermination))
+			}
+		}
+		switch {
+		case controllerCrashing(pod):
+			report.add("Pod", pod.Name, kubernetes.PreflightFail, "%s", strings.Join(podProblems([]kubernetes.PodStatus{pod}), "; "))
+			report.hint("%s", podRemediation(pod, report.Namespace))
+		case len(recent) > 0:
+			report.add("Pod", pod.Name, kubernetes.PreflightWarn, "%s", strings.Join(recent, "; "))
+			report.hint("%s", podRemediation(pod, report.Namespace))
+		case pod.Phase != "Running" || !podReady(pod):
+			report.add("Pod", pod.Name, kubernetes.PreflightWarn, "phase %s, not ready", pod.Phase)
+			report.hint("%s", podRemediation(pod, report.Namespace))
+		default:
+			report.add("Pod", pod.Name, kubernetes.PreflightPass, "Running, %d restart(s) in total, none recently", total)
+		}
+	}
+	return pods, nil
+}
+
+// controllerCrashing reports whether a container of a pod is down, e.g.
+// in CrashLoopBackOff, rather than starting or running.
+func controllerCrashing(pod kubernetes.PodStatus) bool {
+	for _, c := range pod.Containers {
+		if c.State == "terminated" || (c.State == "waiting" && c.Reason != "" && c.Reason != "ContainerCreating") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkControllerLeader checks that a controller pod holds the leader
+// election Lease and keeps renewing it, and returns the leader's pod. It
+// returns nil when the leader is unknown.
+func (ts *ToolServer) checkControllerLeader(ctx context.Context, client *kubernetes.Client, report *ControllerHealthReport, pods []kubernetes.PodStatus) (*kubernetes.PodStatus, error) {
+	leases, known, err := client.ListLeases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		report.add("LeaderElection", report.Controller, kubernetes.PreflightWarn, "could not list Leases")
+		report.hint("Grant the server's ServiceAccount 'list' on leases (coordination.k8s.io) in namespace '%s'", report.Namespace)
+		return nil, nil
+	}
+
+	// controller-runtime identifies the holder as "<pod>_<uuid>"; a Lease
+	// held by a pod that is gone is matched by the Deployment's pod prefix
+	holderPod := func(holder string) (*kubernetes.PodStatus, bool) {
+		for i := range pods {
+			if holder == pods[i].Name || strings.HasPrefix(holder, pods[i].Name+"_") {
+				return &pods[i], true
+			}
+		}
+		return nil, strings.HasPrefix(holder, report.Controller+"-")
+	}
+	var lease *kubernetes.Lease
+	var leader *kubernetes.PodStatus
+	for i := range leases {
+		pod, ok := holderPod(leases[i].Holder)
+		if !ok {
+			continue
+		}
+		// Prefer a Lease held by a running pod over a stale one
+		if lease == nil || (leader == nil && pod != nil) {
+			lease, leader = &leases[i], pod
+		}
+	}
+	if lease == nil {
+		if len(pods) > 1 {
+			report.add("LeaderElection", report.Controller, kubernetes.PreflightWarn, "%d replicas but no Lease held by a controller pod", len(pods))
+			report.hint("Without leader election, replicas reconcile the same agents concurrently; enable it in the controller's flags")
+			return nil, nil
+		}
+		report.add("LeaderElection", report.Controller, kubernetes.PreflightPass, "no Lease held by a controller pod: leader election is not in use")
+		return nil, nil
+	}
+	report.Leader = lease
+
+	now := time.Now()
+	switch {
+	case lease.Expired(now):
+		report.add("LeaderElection", lease.Name, kubernetes.PreflightFail, "Lease expired: %s last renewed it %s ago (duration %s), so no replica is reconciling", lease.Holder, now.Sub(lease.RenewTime).Round(time.Second), lease.Duration)
+		report.hint("The leader stopped renewing: check the controller logs for API server errors and whether a replica can take over")
+	case leader == nil && len(pods) > 0:
+		report.add("LeaderElection", lease.Name, kubernetes.PreflightWarn, "held by %s, which is not a current controller pod", lease.Holder)
+		report.hint("A replica should take over once the Lease expires in at most %s", lease.Duration)
+	default:
+		report.add("LeaderElection", lease.Name, kubernetes.PreflightPass, "held by %s, renewed %s ago (%d transition(s))", lease.Holder, now.Sub(lease.RenewTime).Round(time.Second), lease.Transitions)
+	}
+	return leader, nil
+}
+
+// checkReconcileErrors measures the rate of failed reconciles over the
+// window, from Prometheus when configured, otherwise from the logs of the
+// leader (or of every pod when the leader is unknown).
+func (ts *ToolServer) checkReconcileErrors(ctx context.Context, client *kubernetes.Client, report *ControllerHealthReport, pods []kubernetes.PodStatus, leader *kubernetes.PodStatus, window time.Duration) {
+	if url := ts.server.Config().PrometheusURL; url != "" {
+		errs, err := reconcileErrorsFromMetrics(ctx, url, report.Namespace, report.Controller, window)
+		if err == nil {
+			report.Reconcile = errs
+			rateReconcileErrors(report, window)
+			return
+		}
+		report.add("Reconcile", report.Controller, kubernetes.PreflightWarn, "could not query reconcile metrics: %v; counted errors in the logs instead", err)
+	}
+
+	if leader != nil {
+		pods = []kubernetes.PodStatus{*leader}
+	}
+	errs := &ReconcileErrors{Source: "logs"}
+	for _, pod := range pods {
+		logs, known, err := client.GetPodLogs(ctx, pod.Name, controllerContainer(pod, report.Controller), time.Now().Add(-window), 0)
+		if err != nil {
+			report.add("Reconcile", pod.Name, kubernetes.PreflightWarn, "could not read the controller logs: %v", err)
+			return
+		}
+		if !known {
+			report.add("Reconcile", report.Controller, kubernetes.PreflightWarn, "could not read the controller logs to count reconcile errors")
+			report.hint("Grant the server's ServiceAccount 'get' on pods/log in namespace '%s', or set KAGENT_PROMETHEUS_URL", report.Namespace)
+			return
+		}
+		for _, line := range strings.Split(logs, "\n") {
+			if strings.Contains(line, reconcilerErrorLog) {
+				errs.Errors++
+				errs.LastError = strings.TrimSpace(line)
+			}
+		}
+	}
+	if len(errs.LastError) > maxErrorLogLength {
+		errs.LastError = errs.LastError[:maxErrorLogLength] + "..."
+	}
+	report.Reconcile = errs
+	rateReconcileErrors(report, window)
+}
+
+// rateReconcileErrors adds the check of the measured reconcile errors.
+func rateReconcileErrors(report *ControllerHealthReport, window time.Duration) {
+	errs := report.Reconcile
+	switch {
+	case errs.Errors == 0:
+		report.add("Reconcile", report.Controller, kubernetes.PreflightPass, "no reconcile errors in the last %s (%s)", window, errs.Source)
+	case errs.Reconciles > 0 && errs.Rate >= reconcileErrorRateFail:
+		report.add("Reconcile", report.Controller, kubernetes.PreflightFail, "%d of %d reconciles failed in the last %s (%.0f%%)", errs.Errors, errs.Reconciles, window, errs.Rate*100)
+		report.hint("Most reconciles fail, for every agent alike: read the controller logs (kubectl logs deploy/%s -n %s) for the common error", report.Controller, report.Namespace)
+	case errs.Reconciles > 0 && errs.Rate < reconcileErrorRateWarn:
+		report.add("Reconcile", report.Controller, kubernetes.PreflightPass, "%d of %d reconciles failed in the last %s (%.1f%%)", errs.Errors, errs.Reconciles, window, errs.Rate*100)
+	case errs.Reconciles > 0:
+		report.add("Reconcile", report.Controller, kubernetes.PreflightWarn, "%d of %d reconciles failed in the last %s (%.0f%%)", errs.Errors, errs.Reconciles, window, errs.Rate*100)
+		report.hint("Errors on a few resources usually point at their manifests: get_agent_status shows the controller's message for each agent")
+	default:
+		report.add("Reconcile", report.Controller, kubernetes.PreflightWarn, "%d reconcile error(s) logged in the last %s", errs.Errors, window)
+		report.hint("Errors on a few resources usually point at their manifests: get_agent_status shows the controller's message for each agent")
+	}
+}
+
+// reconcileErrorsFromMetrics reads the controller-runtime reconcile
+// counters of the controller's pods from Prometheus.
+func reconcileErrorsFromMetrics(ctx context.Context, url, namespace, controller string, window time.Duration) (*ReconcileErrors, error) {
+	prom := slo.NewPrometheus(url, &http.Client{Timeout: sloQueryTimeout})
+	selector := fmt.Sprintf(`namespace=%q,pod=~%q`, namespace, controller+"-.*")
+	rng := fmt.Sprintf("%ds", int(window.Seconds()))
+
+	failed, _, err := prom.Query(ctx, fmt.Sprintf("sum(increase(controller_runtime_reconcile_errors_total{%s}[%s]))", selector, rng))
+	if err != nil {
+		return nil, err
+	}
+	total, ok, err := prom.Query(ctx, fmt.Sprintf("sum(increase(controller_runtime_reconcile_total{%s}[%s]))", selector, rng))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("no controller_runtime_reconcile_total series for pods %s-* in namespace '%s'", controller, namespace)
+	}
+
+	errs := &ReconcileErrors{Source: "metrics", Errors: int64(failed + 0.5), Reconciles: int64(total + 0.5)}
+	if total > 0 {
+		errs.Rate = failed / total
+	}
+	return errs, nil
+}
+
+// controllerContainer picks the container of a controller pod to read logs
+// from: the only one, or the one named like the controller.
+func controllerContainer(pod kubernetes.PodStatus, controller string) string {
+	if len(pod.Containers) == 1 {
+		return pod.Containers[0].Name
+	}
+	for _, c := range pod.Containers {
+		if c.Name == "controller" || c.Name == controller {
+			return c.Name
+		}
+	}
+	if len(pod.Containers) > 0 {
+		return pod.Containers[0].Name
+	}
+	return ""
+}
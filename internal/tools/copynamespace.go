@@ -0,0 +1,387 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/signing"
+	"github.com/kagent-dev/meta-kagent/internal/upgrade"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// annotationCopiedFrom records the resource a copy was made from.
+const annotationCopiedFrom = "kagent.dev/copied-from"
+
+// copyKindOrder is the order copied resources are applied in, so that
+// references resolve as soon as each resource is created.
+var copyKindOrder = []string{"ModelConfig", "MCPServer", "RemoteMCPServer", "Agent"}
+
+// registerCopyNamespace registers the copy_namespace tool.
+func (ts *ToolServer) registerCopyNamespace() {
+	tool := mcp.NewTool("copy_namespace",
+		mcp.WithDescription("Copy the kagent resources of one namespace into another, e.g. to spin up a parallel environment. References between copied resources and in-cluster service URLs are rewritten to the target namespace, and Secret names can be remapped. Nothing is applied: the result is a plan and a bundle registered for review, applied with apply_manifest diff_id. Secrets themselves are never copied."),
+		mcp.WithString("source_namespace",
+			mcp.Required(),
+			mcp.Description("Namespace to copy from"),
+		),
+		mcp.WithString("target_namespace",
+			mcp.Required(),
+			mcp.Description("Namespace to copy into"),
+		),
+		mcp.WithString("kinds",
+			mcp.Description("Comma-separated kinds to copy: ModelConfig, MCPServer, RemoteMCPServer, Agent (default: all)"),
+		),
+		withLabelSelectorOption(),
+		mcp.WithString("secret_map",
+			mcp.Description("Comma-separated old=new Secret names to use in the target, e.g. 'openai-prod=openai-staging'. Unmapped Secrets keep their names"),
+		),
+		mcp.WithString("url_map",
+			mcp.Description("Comma-separated old=new replacements for URLs, applied after '.<source>.svc' hosts are rewritten to '.<target>.svc', e.g. 'https://api.example.com=https://staging-api.example.com'"),
+		),
+		mcp.WithBoolean("include_namespace",
+			mcp.Description("Prepend a Namespace manifest for the target namespace to the bundle (default: true when it does not exist)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleCopyNamespace)
+}
+
+// namespaceCopy rewrites resources copied from source into target.
+type namespaceCopy struct {
+	source, target string
+	secretMap      map[string]string
+	urlMap         [][2]string
+	// copied holds the "Kind/name" of every resource being copied
+	copied map[string]bool
+	// secrets collects the Secret names the copies reference
+	secrets map[string]bool
+}
+
+// copyPlanItem is one resource of a copy_namespace plan.
+type copyPlanItem struct {
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name"`
+	Action   string   `json:"action"`
+	Changes  []string `json:"changes,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func (ts *ToolServer) handleCopyNamespace(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	source := args.RequiredString("source_namespace")
+	target := args.RequiredString("target_namespace")
+	kinds := args.StringList("kinds")
+	selector := args.String("label_selector")
+	secretItems := args.StringList("secret_map")
+	urlItems := args.StringList("url_map")
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if source == target {
+		return mcp.NewToolResultError("source_namespace and target_namespace must differ"), nil
+	}
+	if errs := validation.IsDNS1123Label(source); len(errs) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid source_namespace '%s': %s", source, strings.Join(errs, "; "))), nil
+	}
+	if errs := validation.IsDNS1123Label(target); len(errs) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid target_namespace '%s': %s", target, strings.Join(errs, "; "))), nil
+	}
+	if len(kinds) == 0 {
+		kinds = copyKindOrder
+	}
+	for _, kind := range kinds {
+		if !containsString(copyKindOrder, kind) {
+			return mcp.NewToolResultError(fmt.Sprintf("Unsupported kind '%s'. Expected: %s", kind, strings.Join(copyKindOrder, ", "))), nil
+		}
+	}
+	if err := checkLabelSelector(selector); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	c := &namespaceCopy{
+		source:    source,
+		target:    target,
+		secretMap: map[string]string{},
+		copied:    map[string]bool{},
+		secrets:   map[string]bool{},
+	}
+	for _, item := range secretItems {
+		from, to, found := strings.Cut(item, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !found || from == "" || to == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("secret_map: '%s' must be old=new", item)), nil
+		}
+		c.secretMap[from] = to
+	}
+	for _, item := range urlItems {
+		from, to, found := strings.Cut(item, "=")
+		if !found || from == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("url_map: '%s' must be old=new", item)), nil
+		}
+		c.urlMap = append(c.urlMap, [2]string{from, to})
+	}
+
+	// List everything first, so references can be told apart by whether
+	// their target is copied too
+	var objects []unstructured.Unstructured
+	for _, kind := range copyKindOrder {
+		if !containsString(kinds, kind) {
+			continue
+		}
+		gvr, _ := kagentGVR(kind)
+		items, err := ts.kube(ctx).InNamespace(source).ListResourcesBySelector(ctx, gvr, selector)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list %s in namespace '%s': %v", kind, source, err)), nil
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].GetName() < items[j].GetName() })
+		for _, item := range items {
+			item.SetKind(kind)
+			c.copied[kind+"/"+item.GetName()] = true
+			objects = append(objects, item)
+		}
+	}
+	if len(objects) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No %s found in namespace '%s'%s. Nothing to copy.", strings.Join(kinds, ", "), source, describeSelectors(kubernetes.ListOptions{LabelSelector: selector}))), nil
+	}
+
+	exists, known, err := ts.kube(ctx).NamespaceExists(ctx, target)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to check namespace: %v", err)), nil
+	}
+	includeNamespace := args.Bool("include_namespace", known && !exists)
+
+	var plan []copyPlanItem
+	var docs []string
+	for i := range objects {
+		obj := &objects[i]
+		copied, item := c.rewrite(obj)
+
+		gvr, _ := kagentGVR(item.Kind)
+		item.Action = "create"
+		if exists || !known {
+			_, err := ts.kube(ctx).GetResourceIn(ctx, gvr, target, item.Name)
+			switch {
+			case err == nil:
+				item.Action = "update"
+			case !apierrors.IsNotFound(err):
+				item.Action = "create or update"
+				item.Warnings = append(item.Warnings, fmt.Sprintf("Could not check whether it exists in '%s': %v", target, err))
+			}
+		}
+		plan = append(plan, item)
+
+		output, _ := yaml.Marshal(copied.Object)
+		docs = append(docs, string(output))
+	}
+
+	// Secrets are referenced, not copied: they must exist in the target
+	var missingSecrets []string
+	for _, name := range sortedKeys(c.secrets) {
+		if known && !exists {
+			missingSecrets = append(missingSecrets, name)
+			continue
+		}
+		if _, err := ts.kube(ctx).GetResourceIn(ctx, kubernetes.SecretGVR, target, name); apierrors.IsNotFound(err) {
+			missingSecrets = append(missingSecrets, name)
+		}
+	}
+
+	bundle := withNamespaceDocument(includeNamespace, target, strings.Join(docs, "---\n"))
+	diffID, err := ts.reviews.Add(ctx, bundle, "Namespace", target, sessionOwner(ctx))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to record the copy for review: %v", err)), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Copy of %d resource(s) from namespace '%s' to '%s'\n", len(plan), source, target)
+	switch {
+	case !known:
+		fmt.Fprintf(&b, "# Could not verify whether namespace '%s' exists (not allowed to read namespaces).\n", target)
+	case !exists && includeNamespace:
+		fmt.Fprintf(&b, "# Namespace '%s' does not exist; the bundle creates it (Namespace must be in KAGENT_APPLY_ALLOWED_KINDS).\n", target)
+	case !exists:
+		fmt.Fprintf(&b, "# Namespace '%s' does not exist: create it before applying.\n", target)
+	}
+	b.WriteString("# Plan:\n")
+	for _, item := range plan {
+		fmt.Fprintf(&b, "#   %s %s/%s\n", item.Action, item.Kind, item.Name)
+		for _, change := range item.Changes {
+			fmt.Fprintf(&b, "#     - %s\n", change)
+		}
+		for _, warning := range item.Warnings {
+			fmt.Fprintf(&b, "#     WARNING: %s\n", warning)
+		}
+	}
+	if len(missingSecrets) > 0 {
+		fmt.Fprintf(&b, "# Secrets referenced but missing in '%s' (create them, or remap with secret_map): %s\n", target, strings.Join(missingSecrets, ", "))
+	}
+	fmt.Fprintf(&b, "# Digest: %s\n", signing.Digest(bundle))
+	fmt.Fprintf(&b, "# Diff ID: %s (review the bundle, then apply it with apply_manifest diff_id=%s)", diffID, diffID)
+
+	return out.render(b.String(), bundle)
+}
+
+// rewrite returns the copy of obj for the target namespace and the plan
+// item describing what was changed on the way.
+func (c *namespaceCopy) rewrite(obj *unstructured.Unstructured) (*unstructured.Unstructured, copyPlanItem) {
+	item := copyPlanItem{Kind: obj.GetKind(), Name: obj.GetName()}
+
+	copied := upgrade.Clean(obj)
+	copied.SetNamespace(c.target)
+	copied.SetOwnerReferences(nil)
+	unstructured.RemoveNestedField(copied.Object, "metadata", "finalizers")
+
+	annotations := copied.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if secret, ok := annotations[annotationA2ASecret]; ok {
+		annotations[annotationA2ASecret] = c.secret(secret, "metadata.annotations["+annotationA2ASecret+"]", &item)
+	}
+	annotations[annotationCopiedFrom] = c.source + "/" + obj.GetName()
+	copied.SetAnnotations(annotations)
+
+	if item.Kind == "Agent" {
+		c.rewriteAgentReferences(copied, &item)
+	}
+	copied.Object["spec"] = c.rewriteValue(copied.Object["spec"], "spec", &item)
+	sort.Strings(item.Changes)
+	return copied, item
+}
+
+// rewriteAgentReferences points an agent's model config, tool server and
+// agent references at the copies. References into other namespaces are
+// kept; references to resources that are not copied are reported.
+func (c *namespaceCopy) rewriteAgentReferences(agent *unstructured.Unstructured, item *copyPlanItem) {
+	if ref, found, _ := unstructured.NestedString(agent.Object, "spec", "declarative", "modelConfig"); found && ref != "" {
+		if next, ok := c.reference("ModelConfig", ref, "spec.declarative.modelConfig", item); ok {
+			_ = unstructured.SetNestedField(agent.Object, next, "spec", "declarative", "modelConfig")
+		}
+	}
+
+	tools, found, _ := unstructured.NestedSlice(agent.Object, "spec", "declarative", "tools")
+	if !found {
+		return
+	}
+	for i, t := range tools {
+		tool, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ref, _, _ := unstructured.NestedString(tool, "mcpServer", "name"); ref != "" {
+			kind, _, _ := unstructured.NestedString(tool, "mcpServer", "kind")
+			if kind == "" {
+				kind = "MCPServer"
+			}
+			if next, ok := c.reference(kind, ref, fmt.Sprintf("spec.declarative.tools[%d].mcpServer.name", i), item); ok {
+				_ = unstructured.SetNestedField(tool, next, "mcpServer", "name")
+			}
+		}
+		if ref, _, _ := unstructured.NestedString(tool, "agent", "name"); ref != "" {
+			if next, ok := c.reference("Agent", ref, fmt.Sprintf("spec.declarative.tools[%d].agent.name", i), item); ok {
+				_ = unstructured.SetNestedField(tool, next, "agent", "name")
+			}
+		}
+	}
+	_ = unstructured.SetNestedSlice(agent.Object, tools, "spec", "declarative", "tools")
+}
+
+// reference returns the rewritten form of a reference and whether it
+// changed. Qualified references to the source namespace move to the target;
+// unqualified ones already resolve there.
+func (c *namespaceCopy) reference(kind, ref, field string, item *copyPlanItem) (string, bool) {
+	parsed, err := types.ParseObjectRef(ref, c.source)
+	if err != nil || parsed.Namespace != c.source {
+		return ref, false
+	}
+	if !c.copied[kind+"/"+parsed.Name] {
+		item.Warnings = append(item.Warnings, fmt.Sprintf("%s: %s '%s' is not part of the copy and must exist in '%s'", field, kind, parsed.Name, c.target))
+	}
+	if !strings.Contains(ref, "/") {
+		return ref, false
+	}
+	next := c.target + "/" + parsed.Name
+	item.Changes = append(item.Changes, fmt.Sprintf("%s: %s -> %s", field, ref, next))
+	return next, true
+}
+
+// rewriteValue walks a spec, remapping Secret names and rewriting URLs.
+func (c *namespaceCopy) rewriteValue(value interface{}, path string, item *copyPlanItem) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := path + "." + key
+			if name, ok := child.(string); ok && isSecretNameField(key, path) {
+				v[key] = c.secret(name, childPath, item)
+				continue
+			}
+			v[key] = c.rewriteValue(child, childPath, item)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = c.rewriteValue(child, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+		return v
+	case string:
+		return c.url(v, path, item)
+	}
+	return value
+}
+
+// isSecretNameField reports whether a key holds a Secret name: a
+// ModelConfig's apiKeySecret, a volume's secretName, or the name of a
+// secretKeyRef or secretRef.
+func isSecretNameField(key, parentPath string) bool {
+	switch key {
+	case "apiKeySecret", "secretName":
+		return true
+	case "name":
+		return strings.HasSuffix(parentPath, ".secretKeyRef") || strings.HasSuffix(parentPath, ".secretRef")
+	}
+	return false
+}
+
+// secret returns the target name of a referenced Secret, recording it.
+func (c *namespaceCopy) secret(name, field string, item *copyPlanItem) string {
+	if name == "" {
+		return name
+	}
+	next, ok := c.secretMap[name]
+	if !ok {
+		c.secrets[name] = true
+		return name
+	}
+	c.secrets[next] = true
+	item.Changes = append(item.Changes, fmt.Sprintf("%s: Secret %s -> %s", field, name, next))
+	return next
+}
+
+// url rewrites in-cluster hosts of the source namespace to the target, then
+// applies url_map.
+func (c *namespaceCopy) url(s, field string, item *copyPlanItem) string {
+	next := strings.ReplaceAll(s, "."+c.source+".svc", "."+c.target+".svc")
+	if strings.Contains(next, "://") {
+		for _, m := range c.urlMap {
+			next = strings.ReplaceAll(next, m[0], m[1])
+		}
+	}
+	if next != s {
+		item.Changes = append(item.Changes, fmt.Sprintf("%s: %s -> %s", field, s, next))
+	}
+	return next
+}
@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// deletableKinds are the kinds delete_resource removes.
+var deletableKinds = map[string]schema.GroupVersionResource{
+	"ModelConfig":     kubernetes.ModelConfigGVR,
+	"MCPServer":       kubernetes.MCPServerGVR,
+	"RemoteMCPServer": kubernetes.RemoteMCPServerGVR,
+}
+
+// withForceOption adds the force argument to a delete tool.
+func withForceOption() mcp.ToolOption {
+	return mcp.WithBoolean("force",
+		mcp.Description("Delete even when other resources still reference it; they will break until updated (default: false)"),
+	)
+}
+
+// registerDeleteResource registers the delete_resource tool.
+func (ts *ToolServer) registerDeleteResource() {
+	tool := mcp.NewTool("delete_resource",
+		mcp.WithDescription("Delete a kagent ModelConfig, MCPServer or RemoteMCPServer from the cluster. Refuses when agents or other resources still reference it, unless force=true. IMPORTANT: This action is destructive. Use dry_run=true to preview the deletion and its impact."),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Kind of the resource: ModelConfig, MCPServer or RemoteMCPServer"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the resource to delete"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("If true, only report what would be deleted and what references it"),
+		),
+		mcp.WithString("cascade",
+			mcp.Description("What happens to the Deployments, Services and ConfigMaps the controller created for it: 'background' (deleted after the resource), 'foreground' (deleted before it) or 'orphan' (kept). Default: 'background'"),
+		),
+		withForceOption(),
+	)
+
+	ts.addTool(tool, ts.handleDeleteResource)
+}
+
+func (ts *ToolServer) handleDeleteResource(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.RequiredEnum("kind", "ModelConfig", "MCPServer", "RemoteMCPServer")
+	name := args.RequiredString("name")
+	dryRun := args.Bool("dry_run", false)
+	force := args.Bool("force", false)
+	cascade := args.Enum("cascade", kubernetes.CascadeBackground, kubernetes.CascadeBackground, kubernetes.CascadeForeground, kubernetes.CascadeOrphan)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	obj, err := ts.kube(ctx).GetResource(ctx, deletableKinds[kind], name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s not found: %v", kind, err)), nil
+	}
+
+	refs, err := ts.referencesTo(ctx, kind, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if dryRun {
+		owned, err := ts.kube(ctx).OwnedBy(ctx, obj.GetUID())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf(`# Dry Run: Delete %s
+
+The following resource would be deleted:
+- Kind: %s
+- Name: %s
+- Namespace: %s
+%s%s
+To actually delete, call delete_resource with dry_run=false.`,
+			kind, kind, name, ts.kube(ctx).Namespace(), cascadeImpact(owned, cascade), deletionImpact(refs, force))), nil
+	}
+
+	if len(refs) > 0 && !force {
+		return mcp.NewToolResultError(refusedDeletion(kind, name, refs)), nil
+	}
+
+	if err := ts.kube(ctx).DeleteCascade(ctx, kind, name, false, cascade); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete %s: %v", kind, err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully deleted %s '%s'.", kind, name) + brokenReferences(refs)), nil
+}
+
+// referencesTo returns the resources in the session's namespace that
+// reference kind/name. An agent calling itself does not count.
+func (ts *ToolServer) referencesTo(ctx context.Context, kind, name string) ([]kubernetes.Reference, error) {
+	client := ts.kube(ctx)
+	index, err := client.BuildReferenceIndex(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check references: %w", err)
+	}
+
+	var refs []kubernetes.Reference
+	for _, r := range index.ReferencesTo(kind, client.Namespace(), name) {
+		if r.Kind != kind || r.Name != name {
+			refs = append(refs, r)
+		}
+	}
+	return refs, nil
+}
+
+// formatReferences lists references one per line.
+func formatReferences(refs []kubernetes.Reference) string {
+	lines := make([]string, 0, len(refs))
+	for _, r := range refs {
+		lines = append(lines, fmt.Sprintf("- %s '%s' (%s)", r.Kind, r.Name, r.Field))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cascadeImpact describes what a cascade policy does to the owned objects.
+func cascadeImpact(owned []string, cascade string) string {
+	if len(owned) == 0 {
+		return ""
+	}
+	verb := "deleted with it"
+	if cascade == kubernetes.CascadeOrphan {
+		verb = "kept (orphaned)"
+	}
+	return fmt.Sprintf("\nObjects it owns, %s (cascade=%s):\n- %s\n", verb, cascade, strings.Join(owned, "\n- "))
+}
+
+// deletionImpact describes the references a dry run found.
+func deletionImpact(refs []kubernetes.Reference, force bool) string {
+	if len(refs) == 0 {
+		return "\nNo other resources reference it.\n"
+	}
+	impact := "\n⚠️ Referenced by:\n" + formatReferences(refs) + "\n"
+	if !force {
+		impact += "\nThe deletion will be refused unless force=true.\n"
+	}
+	return impact
+}
+
+// refusedDeletion explains why a deletion was refused.
+func refusedDeletion(kind, name string, refs []kubernetes.Reference) string {
+	return fmt.Sprintf("Refusing to delete %s '%s': it is still referenced by:\n%s\n\nUpdate or delete these resources first, or call again with force=true to delete anyway.",
+		kind, name, formatReferences(refs))
+}
+
+// brokenReferences warns about references left dangling by a forced
+// deletion.
+func brokenReferences(refs []kubernetes.Reference) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	return "\n\n⚠️ These resources still reference it and will fail until updated:\n" + formatReferences(refs)
+}
@@ -0,0 +1,255 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/state"
+)
+
+// deletionPlanTTL is how long a deletion plan can be carried out.
+const deletionPlanTTL = 15 * time.Minute
+
+// matchDeletableKinds are the kinds delete_matching removes.
+var matchDeletableKinds = map[string]schema.GroupVersionResource{
+	"Agent":           kubernetes.AgentGVR,
+	"ModelConfig":     kubernetes.ModelConfigGVR,
+	"MCPServer":       kubernetes.MCPServerGVR,
+	"RemoteMCPServer": kubernetes.RemoteMCPServerGVR,
+}
+
+// deletionPlan is the exact set of resources a delete_matching call will
+// delete once confirmed.
+type deletionPlan struct {
+	Kind      string            `json:"kind"`
+	Selector  string            `json:"selector"`
+	Namespace string            `json:"namespace"`
+	Owner     string            `json:"owner,omitempty"`
+	Cascade   string            `json:"cascade"`
+	Items     []plannedDeletion `json:"items"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+// plannedDeletion is a resource in a deletion plan, pinned by UID.
+type plannedDeletion struct {
+	Name string       `json:"name"`
+	UID  k8stypes.UID `json:"uid"`
+}
+
+// registerDeleteMatching registers the delete_matching tool.
+func (ts *ToolServer) registerDeleteMatching() {
+	tool := mcp.NewTool("delete_matching",
+		mcp.WithDescription("Delete every Agent, ModelConfig, MCPServer or RemoteMCPServer matching a label selector, e.g. to tear down an experiment (team=sandbox) without naming each resource. Called without plan_id it only returns a plan: the exact resources that would be deleted and anything outside the plan that still references them, with a plan ID. Show the plan to the user and, after they confirm, call again with plan_id to delete exactly those resources. IMPORTANT: This action is destructive."),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Kind of the resources: Agent, ModelConfig, MCPServer or RemoteMCPServer"),
+		),
+		mcp.WithString("selector",
+			mcp.Required(),
+			mcp.Description("Label selector the resources must match (e.g., 'team=sandbox' or 'experiment in (a,b)'). An empty selector is refused"),
+		),
+		mcp.WithString("plan_id",
+			mcp.Description("Plan ID returned by an earlier call with the same kind and selector. Deletes exactly the planned resources; omit to get a plan"),
+		),
+		mcp.WithString("cascade",
+			mcp.Description("What happens to the Deployments, Services and ConfigMaps the controller created: 'background', 'foreground' or 'orphan'. Fixed when the plan is made. Default: 'background'"),
+		),
+		withForceOption(),
+	)
+
+	ts.addTool(tool, ts.handleDeleteMatching)
+}
+
+func (ts *ToolServer) handleDeleteMatching(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.RequiredEnum("kind", "Agent", "ModelConfig", "MCPServer", "RemoteMCPServer")
+	selector := strings.TrimSpace(args.RequiredString("selector"))
+	planID := args.String("plan_id")
+	cascade := args.Enum("cascade", kubernetes.CascadeBackground, kubernetes.CascadeBackground, kubernetes.CascadeForeground, kubernetes.CascadeOrphan)
+	force := args.Bool("force", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid selector '%s': %v", selector, err)), nil
+	}
+	if parsed.Empty() {
+		return mcp.NewToolResultError("selector must not be empty: delete_matching does not delete every resource of a kind"), nil
+	}
+
+	if planID == "" {
+		return ts.planDeletion(ctx, kind, parsed.String(), cascade)
+	}
+	return ts.executeDeletion(ctx, kind, parsed.String(), planID, force)
+}
+
+// planDeletion lists the resources matching selector and records them as
+// a plan to be confirmed.
+func (ts *ToolServer) planDeletion(ctx context.Context, kind, selector, cascade string) (*mcp.CallToolResult, error) {
+	client := ts.kube(ctx)
+	items, err := client.ListResourcesBySelector(ctx, matchDeletableKinds[kind], selector)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list %s resources: %v", kind, err)), nil
+	}
+	if len(items) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No %s resources match '%s' in namespace '%s'. Nothing to delete.", kind, selector, client.Namespace())), nil
+	}
+
+	plan := deletionPlan{
+		Kind:      kind,
+		Selector:  selector,
+		Namespace: client.Namespace(),
+		Owner:     sessionOwner(ctx),
+		Cascade:   cascade,
+		CreatedAt: time.Now(),
+	}
+	for _, item := range items {
+		plan.Items = append(plan.Items, plannedDeletion{Name: item.GetName(), UID: item.GetUID()})
+	}
+	sort.Slice(plan.Items, func(i, j int) bool { return plan.Items[i].Name < plan.Items[j].Name })
+
+	external, err := ts.externalReferences(ctx, plan)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	planID := "plan-" + randomHex(8)
+	if err := state.PutJSON(ctx, ts.state, deletionPlanKey(planID), plan, deletionPlanTTL); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to record the plan: %v", err)), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Deletion Plan: %d %s resource(s) matching '%s' in namespace '%s'\n# Plan ID: %s\n\n", len(plan.Items), kind, selector, plan.Namespace, planID)
+	b.WriteString("The following resources would be deleted:\n")
+	for _, item := range plan.Items {
+		fmt.Fprintf(&b, "- %s '%s'\n", kind, item.Name)
+	}
+	fmt.Fprintf(&b, "\nObjects the controller created for them are handled with cascade=%s.\n", cascade)
+	if len(external) > 0 {
+		b.WriteString("\n⚠️ Resources outside the plan still reference them:\n")
+		for _, name := range sortedKeys(external) {
+			fmt.Fprintf(&b, "- %s '%s' is referenced by %s\n", kind, name, strings.Join(external[name], ", "))
+		}
+		b.WriteString("\nThe deletion will be refused unless force=true. To delete them too, widen the selector or delete them separately.\n")
+	}
+	fmt.Fprintf(&b, "\nAfter the user confirms, call delete_matching with kind=%s, selector='%s' and plan_id=%s. Resources created or labeled since this plan are not deleted. The plan expires in %s.",
+		kind, selector, planID, deletionPlanTTL)
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// executeDeletion deletes the resources recorded in a plan, skipping those
+// that were deleted or recreated since.
+func (ts *ToolServer) executeDeletion(ctx context.Context, kind, selector, planID string, force bool) (*mcp.CallToolResult, error) {
+	client := ts.kube(ctx)
+
+	var plan deletionPlan
+	ok, err := state.GetJSON(ctx, ts.state, deletionPlanKey(planID), &plan)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to look up plan '%s': %v", planID, err)), nil
+	}
+	if !ok || plan.Owner != sessionOwner(ctx) {
+		return mcp.NewToolResultError(fmt.Sprintf("plan_id '%s' not found or expired. Call delete_matching without plan_id to plan again.", planID)), nil
+	}
+	if plan.Kind != kind || plan.Selector != selector || plan.Namespace != client.Namespace() {
+		return mcp.NewToolResultError(fmt.Sprintf("plan_id '%s' was made for %s matching '%s' in namespace '%s'. Call delete_matching without plan_id to plan this deletion.",
+			planID, plan.Kind, plan.Selector, plan.Namespace)), nil
+	}
+
+	// References may have appeared since the plan was made
+	external, err := ts.externalReferences(ctx, plan)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(external) > 0 && !force {
+		var lines []string
+		for _, name := range sortedKeys(external) {
+			lines = append(lines, fmt.Sprintf("- %s '%s' is referenced by %s", kind, name, strings.Join(external[name], ", ")))
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Refusing to delete: resources outside the plan still reference these:\n%s\n\nUpdate or delete them first, or call again with force=true to delete anyway.", strings.Join(lines, "\n"))), nil
+	}
+
+	// The plan is used up whatever the outcome, so it cannot be replayed
+	if _, err := ts.state.Delete(ctx, deletionPlanKey(planID)); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to consume plan '%s': %v", planID, err)), nil
+	}
+
+	var deleted, skipped, failed []string
+	for _, item := range plan.Items {
+		err := client.DeleteUID(ctx, matchDeletableKinds[kind], item.Name, item.UID, plan.Cascade)
+		switch {
+		case err == nil:
+			deleted = append(deleted, item.Name)
+		case apierrors.IsNotFound(err):
+			skipped = append(skipped, item.Name+" (already deleted)")
+		case apierrors.IsConflict(err):
+			skipped = append(skipped, item.Name+" (recreated since the plan)")
+		default:
+			failed = append(failed, fmt.Sprintf("%s: %v", item.Name, err))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Deleted %d of %d %s resource(s) matching '%s'\n", len(deleted), len(plan.Items), kind, selector)
+	for _, section := range []struct {
+		title string
+		names []string
+	}{
+		{"Deleted", deleted},
+		{"Skipped", skipped},
+		{"Failed", failed},
+	} {
+		if len(section.names) > 0 {
+			fmt.Fprintf(&b, "\n%s:\n- %s\n", section.title, strings.Join(section.names, "\n- "))
+		}
+	}
+	if len(external) > 0 {
+		b.WriteString("\n⚠️ Resources outside the plan still reference deleted resources and will fail until updated.\n")
+	}
+
+	if len(failed) > 0 {
+		return mcp.NewToolResultError(b.String()), nil
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// externalReferences returns, per planned resource, the resources outside
+// the plan that reference it as "Kind 'name' (field)".
+func (ts *ToolServer) externalReferences(ctx context.Context, plan deletionPlan) (map[string][]string, error) {
+	index, err := ts.kube(ctx).BuildReferenceIndex(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check references: %w", err)
+	}
+
+	planned := map[string]bool{}
+	for _, item := range plan.Items {
+		planned[item.Name] = true
+	}
+
+	external := map[string][]string{}
+	for _, item := range plan.Items {
+		for _, r := range index.ReferencesTo(plan.Kind, plan.Namespace, item.Name) {
+			if r.Kind == plan.Kind && planned[r.Name] {
+				continue
+			}
+			external[item.Name] = append(external[item.Name], fmt.Sprintf("%s '%s' (%s)", r.Kind, r.Name, r.Field))
+		}
+	}
+	return external, nil
+}
+
+func deletionPlanKey(id string) string {
+	return "deletion-plan/" + id
+}
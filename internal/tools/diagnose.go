@@ -0,0 +1,201 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// registerDiagnoseAgent registers the diagnose_agent tool.
+func (ts *ToolServer) registerDiagnoseAgent() {
+	tool := mcp.NewTool("diagnose_agent",
+		mcp.WithDescription("Run a battery of health checks for one agent and report pass, warn or fail per check with a remediation hint: the controller reports it Ready, its ModelConfig exists and is valid, the API key Secret and key exist, every referenced MCP server exists and is Ready, the pods of its Deployment are Running, and its A2A skills validate."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the agent to diagnose"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleDiagnoseAgent)
+}
+
+func (ts *ToolServer) handleDiagnoseAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	report := &ReadinessReport{Agent: name}
+
+	if agent.Status.IsReady() {
+		report.add("Agent", name, kubernetes.PreflightPass, "controller reports Ready")
+	} else {
+		message := "controller does not report Ready"
+		if m := controllerMessage(agent.Status.Conditions); m != "" {
+			message += ": " + m
+		}
+		report.add("Agent", name, kubernetes.PreflightFail, "%s", message)
+		report.hint("Run get_agent_status for the agent's conditions and events")
+	}
+
+	if agent.Spec.Declarative != nil {
+		ts.checkModelConfigReadiness(ctx, report, agent.Spec.Declarative.ModelConfig, agent.Namespace)
+		for _, tool := range agent.Spec.Declarative.Tools {
+			if tool.McpServer != nil {
+				ts.checkToolServerReadiness(ctx, report, tool.McpServer, agent.Namespace)
+			}
+		}
+	}
+
+	if err := ts.checkAgentPods(ctx, report, name); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	checkAgentSkills(report, agent)
+
+	report.decide()
+
+	counts := map[string]int{}
+	for _, c := range report.Checks {
+		counts[c.Status]++
+	}
+	output, _ := json.MarshalIndent(report, "", "  ")
+	return mcp.NewToolResultText(fmt.Sprintf("# Diagnosis: %s\n\nVerdict: %s (%d passed, %d warnings, %d failed)\n\n%s",
+		name, report.Verdict, counts[kubernetes.PreflightPass], counts[kubernetes.PreflightWarn], counts[kubernetes.PreflightFail], string(output))), nil
+}
+
+// checkAgentPods checks that the agent's Deployment has its pods Running
+// with every container ready.
+func (ts *ToolServer) checkAgentPods(ctx context.Context, report *ReadinessReport, name string) error {
+	client := ts.kube(ctx)
+	deployment, known, err := client.GetDeploymentStatus(ctx, name)
+	switch {
+	case apierrors.IsNotFound(err):
+		report.add("Deployment", name, kubernetes.PreflightFail, "the controller has not created the agent's Deployment")
+		report.hint("Check the agent's Accepted condition and run controller_health to check the controller")
+		return nil
+	case err != nil:
+		return err
+	case !known:
+		report.add("Deployment", name, kubernetes.PreflightWarn, "could not read the agent's Deployment")
+		report.hint("Grant the server's ServiceAccount 'get' on deployments")
+		return nil
+	}
+
+	if deployment.Replicas == 0 {
+		report.add("Deployment", name, kubernetes.PreflightWarn, "scaled to zero replicas")
+		report.hint("Scale the agent up to serve requests")
+		return nil
+	}
+	if deployment.Selector == "" {
+		report.add("Deployment", name, kubernetes.PreflightWarn, "has no pod selector")
+		return nil
+	}
+
+	pods, known, err := client.ListPodStatuses(ctx, deployment.Selector)
+	if err != nil {
+		return err
+	}
+	if !known {
+		report.add("Pods", name, kubernetes.PreflightWarn, "could not list the agent's pods")
+		report.hint("Grant the server's ServiceAccount 'list' on pods")
+		return nil
+	}
+	if len(pods) == 0 {
+		report.add("Pods", name, kubernetes.PreflightFail, "Deployment has no pods")
+		report.hint("summarize_recent_events with name=%s shows why the ReplicaSet cannot create them (e.g., quota)", name)
+		return nil
+	}
+
+	for _, pod := range pods {
+		problems := podProblems([]kubernetes.PodStatus{pod})
+		switch {
+		case len(problems) > 0:
+			report.add("Pod", pod.Name, kubernetes.PreflightFail, "%s", strings.Join(problems, "; "))
+			report.hint("%s", podRemediation(pod, client.Namespace()))
+		case pod.Phase != "Running" || !podReady(pod):
+			report.add("Pod", pod.Name, kubernetes.PreflightWarn, "phase %s, not ready yet", pod.Phase)
+			report.hint("Wait for the pod to start; if it stays unready, check its readiness probe and events")
+		default:
+			report.add("Pod", pod.Name, kubernetes.PreflightPass, "Running, all containers ready")
+		}
+	}
+	return nil
+}
+
+// podReady reports whether every container of a pod is ready.
+func podReady(pod kubernetes.PodStatus) bool {
+	for _, c := range pod.Containers {
+		if !c.Ready {
+			return false
+		}
+	}
+	return len(pod.Containers) > 0
+}
+
+// podRemediation suggests a fix for the most telling container problem of
+// a pod.
+func podRemediation(pod kubernetes.PodStatus, namespace string) string {
+	for _, c := range pod.Containers {
+		switch {
+		case c.Reason == "ImagePullBackOff" || c.Reason == "ErrImagePull" || c.Reason == "InvalidImageName":
+			return fmt.Sprintf("Check the image of container %s exists and the pod can pull it (imagePullSecrets)", c.Name)
+		case c.Reason == "CreateContainerConfigError":
+			return fmt.Sprintf("A Secret or ConfigMap container %s reads is missing: kubectl describe pod %s -n %s", c.Name, pod.Name, namespace)
+		case c.Reason == "OOMKilled" || c.LastTermination == "OOMKilled":
+			return "Raise the memory limit of the agent (recommend_resources suggests values)"
+		case c.Reason == "CrashLoopBackOff" || c.State == "terminated" || c.LastTermination != "":
+			return fmt.Sprintf("Read the crash output: kubectl logs %s -n %s -c %s --previous", pod.Name, namespace, c.Name)
+		}
+	}
+	if pod.Phase == "Pending" {
+		return "advise_agent_placement shows whether quotas or node capacity keep the pod from scheduling"
+	}
+	return fmt.Sprintf("kubectl describe pod %s -n %s", pod.Name, namespace)
+}
+
+// checkAgentSkills validates the agent's A2A skills and checks their IDs
+// are unique.
+func checkAgentSkills(report *ReadinessReport, agent *types.Agent) {
+	a2a := getA2AConfig(agent)
+	if a2a == nil || len(a2a.Skills) == 0 {
+		return
+	}
+
+	seen := map[string]bool{}
+	for i, skill := range a2a.Skills {
+		name := skill.ID
+		if name == "" {
+			name = fmt.Sprintf("skills[%d]", i)
+		}
+
+		var errs []string
+		for _, issue := range validateSkill(skill, false) {
+			errs = append(errs, issue.Message)
+		}
+		if skill.ID != "" && seen[skill.ID] {
+			errs = append(errs, "duplicate skill id")
+		}
+		seen[skill.ID] = true
+
+		if len(errs) > 0 {
+			report.add("Skill", name, kubernetes.PreflightFail, "%s", strings.Join(errs, "; "))
+			report.hint("Check the skill with validate_skill, then replace it with remove_skill_from_agent and add_skill_to_agent")
+			continue
+		}
+		report.add("Skill", name, kubernetes.PreflightPass, "valid")
+	}
+}
@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// mutatingTools are the tools that change the cluster. In apply mode with
+// elevation enabled they run only for sessions holding an active grant,
+// except as a dry run; other modes do not offer them.
+var mutatingTools = map[string]bool{
+	"apply_manifest":         true,
+	"delete_agent":           true,
+	"delete_resource":        true,
+	"delete_matching":        true,
+	"archive_agent":          true,
+	"restore_archived_agent": true,
+	"deploy_mock_agent":      true,
+	"rollback_resource":      true,
+	"import_resources":       true,
+}
+
+// planningArgs names, for mutating tools that only return a plan unless
+// given a plan to carry out, the argument carrying it.
+var planningArgs = map[string]string{
+	"delete_matching": "plan_id",
+}
+
+// withElevation gates a mutating tool behind an active elevation of the
+// calling session, and records each use in the audit log. Servers that do
+// not apply changes need no elevation.
+func (ts *ToolServer) withElevation(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if !mutatingTools[name] || ts.server.Config().Mode != ModeApply {
+		return handler
+	}
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if ts.server.Config().ElevationSecret == "" {
+			return handler(ctx, req)
+		}
+		args := params.From(req)
+		if args.Bool("dry_run", false) && args.Err() == nil {
+			return handler(ctx, req)
+		}
+		if arg, ok := planningArgs[name]; ok && args.String(arg) == "" && args.Err() == nil {
+			return handler(ctx, req)
+		}
+
+		owner := sessionOwner(ctx)
+		grant, ok, err := ts.elevations.Active(ctx, owner)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to check elevation: %v", err)), nil
+		}
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("This server gates changes behind elevation (KAGENT_ELEVATION_SECRET): %s needs elevated access. Call request_elevation with a reason, have an operator approve it, and retry. Dry runs and plans are allowed without elevation.", name)), nil
+		}
+		ts.elevations.Audit(owner, name, grant)
+		return handler(ctx, req)
+	}
+}
+
+// registerRequestElevation registers the request_elevation tool.
+func (ts *ToolServer) registerRequestElevation() {
+	tool := mcp.NewTool("request_elevation",
+		mcp.WithDescription("Request time-limited rights to change the cluster (apply_manifest, delete_agent, delete_resource, delete_matching, archive and restore) for this session, when the server runs in apply mode with elevation enabled (KAGENT_ELEVATION_SECRET). Returns a request ID for an operator to approve out-of-band; pass the token they hand back to approve_elevation. Requests are recorded in the audit log."),
+		mcp.WithString("reason",
+			mcp.Required(),
+			mcp.Description("Why elevated access is needed, for the operator and the audit log"),
+		),
+		mcp.WithString("duration",
+			mcp.Description("How long the access should last once approved (e.g., '15m'). Default: '30m', at most KAGENT_MAX_ELEVATION"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleRequestElevation)
+}
+
+func (ts *ToolServer) handleRequestElevation(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	reason := args.RequiredString("reason")
+	duration := args.Duration("duration", 30*time.Minute)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cfg := ts.server.Config()
+	if cfg.ElevationSecret == "" {
+		return mcp.NewToolResultError("Elevation is not enabled: KAGENT_ELEVATION_SECRET is not set, so sessions change the cluster without elevation."), nil
+	}
+	if duration <= 0 || duration > cfg.MaxElevation {
+		return mcp.NewToolResultError(fmt.Sprintf("duration must be between 0 and %s, got %s", cfg.MaxElevation, duration)), nil
+	}
+
+	r, err := ts.elevations.Request(ctx, sessionOwner(ctx), reason, duration)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to record elevation request: %v", err)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf(`# Elevation Requested
+
+Request ID: %s
+Duration: %s once approved
+Reason: %s
+
+Ask an operator to approve it. They generate the approval token with:
+
+  kubectl exec -n <server-namespace> deploy/kmeta-agent-tools -- /kmeta-agent-server --elevation-token %s
+
+Then call approve_elevation with request_id=%s and the token. The request expires if not approved within 15 minutes.`,
+		r.ID, duration, reason, r.ID, r.ID)), nil
+}
+
+// registerApproveElevation registers the approve_elevation tool.
+func (ts *ToolServer) registerApproveElevation() {
+	tool := mcp.NewTool("approve_elevation",
+		mcp.WithDescription("Activate a pending elevation request with the approval token an operator handed over out-of-band. The session may then change the cluster until the elevation expires."),
+		mcp.WithString("request_id",
+			mcp.Required(),
+			mcp.Description("ID returned by request_elevation"),
+		),
+		mcp.WithString("token",
+			mcp.Required(),
+			mcp.Description("Approval token from the operator"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleApproveElevation)
+}
+
+func (ts *ToolServer) handleApproveElevation(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	id := args.RequiredString("request_id")
+	token := args.RequiredString("token")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if ts.server.Config().ElevationSecret == "" {
+		return mcp.NewToolResultError("Elevation is not enabled on this server: KAGENT_ELEVATION_SECRET is not set."), nil
+	}
+
+	grant, err := ts.elevations.Approve(ctx, id, sessionOwner(ctx), token)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to approve elevation: %v", err)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Elevation active until %s (%s from now). Changes made meanwhile are recorded in the audit log; call release_elevation when done.",
+		grant.ExpiresAt.UTC().Format(time.RFC3339), time.Until(grant.ExpiresAt).Round(time.Second))), nil
+}
+
+// registerElevationStatus registers the elevation_status tool.
+func (ts *ToolServer) registerElevationStatus() {
+	tool := mcp.NewTool("elevation_status",
+		mcp.WithDescription("Show whether this session can change the cluster: the active elevation and when it expires, and any requests awaiting approval."),
+	)
+
+	ts.server.AddTool(tool, ts.handleElevationStatus)
+}
+
+func (ts *ToolServer) handleElevationStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if ts.server.Config().ElevationSecret == "" {
+		return mcp.NewToolResultText("Elevation is not enabled: KAGENT_ELEVATION_SECRET is not set, so sessions change the cluster without elevation."), nil
+	}
+
+	owner := sessionOwner(ctx)
+	pending, err := ts.elevations.Pending(ctx, owner)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list elevation requests: %v", err)), nil
+	}
+	grant, ok, err := ts.elevations.Active(ctx, owner)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to check elevation: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"readOnly": true,
+		"pending":  pending,
+	}
+	if ok {
+		result["readOnly"] = false
+		result["grant"] = grant
+		result["remaining"] = time.Until(grant.ExpiresAt).Round(time.Second).String()
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// registerReleaseElevation registers the release_elevation tool.
+func (ts *ToolServer) registerReleaseElevation() {
+	tool := mcp.NewTool("release_elevation",
+		mcp.WithDescription("End this session's elevation before it expires, after which mutating tools only run as dry runs and plans again."),
+	)
+
+	ts.server.AddTool(tool, ts.handleReleaseElevation)
+}
+
+func (ts *ToolServer) handleReleaseElevation(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	released, err := ts.elevations.Release(ctx, sessionOwner(ctx))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to release elevation: %v", err)), nil
+	}
+	if !released {
+		return mcp.NewToolResultText("This session has no active elevation."), nil
+	}
+	return mcp.NewToolResultText("Elevation released. Mutating tools only run as dry runs and plans again."), nil
+}
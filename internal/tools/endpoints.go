@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/clusterfacts"
+	"github.com/kagent-dev/meta-kagent/internal/endpoints"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// loadEnvironments loads KAGENT_ENVIRONMENTS_FILE. An invalid file is
+// logged and leaves endpoint URLs to the in-cluster default, as without
+// one.
+func (ts *ToolServer) loadEnvironments() {
+	cfg := ts.server.Config()
+	if cfg.EnvironmentsFile == "" {
+		return
+	}
+	envs, err := endpoints.Load(cfg.EnvironmentsFile, cfg.Environment)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Endpoint environments disabled: %v\n", err)
+		return
+	}
+	ts.environments = envs
+	fmt.Fprintf(os.Stderr, "Loaded endpoint environments %s from %s\n", strings.Join(envs.Names(), ", "), cfg.EnvironmentsFile)
+}
+
+// withEnvironmentOption adds the environment argument to a tool building
+// endpoint URLs.
+func withEnvironmentOption() mcp.ToolOption {
+	return mcp.WithString("environment",
+		mcp.Description("Environment whose URL template and allowed domains apply, as configured in KAGENT_ENVIRONMENTS_FILE (default: the server's environment)"),
+	)
+}
+
+// agentEndpoint returns the A2A endpoint URL of an agent: explicit when
+// given, else from the environment's template, else the in-cluster
+// Service URL. The warning is set when the URL is outside the
+// environment's allowed domains, or when the template uses a cluster fact
+// that is not known and the Service URL is used instead.
+func (ts *ToolServer) agentEndpoint(ctx context.Context, env *endpoints.Environment, agent *types.Agent, explicit string) (endpointURL, warning string) {
+	switch {
+	case explicit != "":
+		endpointURL = explicit
+	case env != nil:
+		var err error
+		if endpointURL, err = env.URL(agent.Name, agentNamespace(agent), ts.facts(ctx)); err != nil {
+			return defaultAgentEndpoint(agent), err.Error() + "; using the in-cluster Service URL"
+		}
+		// Templates using cluster facts are checked once rendered
+		if !clusterfacts.Uses(env.URLTemplate) {
+			return endpointURL, ""
+		}
+	default:
+		return defaultAgentEndpoint(agent), ""
+	}
+	if env != nil {
+		if err := env.Check(endpointURL); err != nil {
+			warning = err.Error()
+		}
+	}
+	return endpointURL, warning
+}
+
+// agentNamespace is the namespace of an agent, kagent when unset.
+func agentNamespace(agent *types.Agent) string {
+	if agent.Namespace == "" {
+		return "kagent"
+	}
+	return agent.Namespace
+}
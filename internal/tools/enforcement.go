@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	ruleset "github.com/kagent-dev/meta-kagent/internal/validation"
+)
+
+// policyEnforcementAudit reports what enforced rules would block without
+// blocking it.
+const policyEnforcementAudit = "audit"
+
+// policyViolationError is returned when a bundle breaks rules the server
+// enforces. Unlike validation issues it cannot be overridden per call.
+type policyViolationError struct {
+	Violations []resourceIssue
+}
+
+func (e *policyViolationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Policy violation: not applied. The manifest breaks %d rule(s) the server enforces (KAGENT_ENFORCED_RULES):\n", len(e.Violations))
+	b.WriteString(formatViolations(e.Violations))
+	b.WriteString("\nChange the manifest to comply; enforced rules cannot be disabled per call.")
+	return b.String()
+}
+
+// formatViolations lists policy violations, one per line.
+func formatViolations(violations []resourceIssue) string {
+	var b strings.Builder
+	for _, v := range violations {
+		fmt.Fprintf(&b, "⛔ [%s] (%s) %s: %s\n", v.Issue.Rule, v.Resource, v.Issue.Field, v.Issue.Message)
+	}
+	return b.String()
+}
+
+// enforcePolicies runs the rules of KAGENT_ENFORCED_RULES on a bundle about
+// to be applied. Every issue they find, warnings included, is a violation:
+// in enforce mode the bundle is refused with a policyViolationError, in
+// audit mode the violations are returned as a note for the result.
+func (ts *ToolServer) enforcePolicies(ctx context.Context, docs []string) (string, error) {
+	cfg := ts.server.Config()
+	if len(cfg.EnforcedRules) == 0 {
+		return "", nil
+	}
+	// Names that match no rule were reported at startup
+	rules, _ := ts.rules.Select(cfg.EnforcedRules)
+
+	var objs []*unstructured.Unstructured
+	for _, doc := range docs {
+		obj := &unstructured.Unstructured{}
+		// Documents that do not parse are refused by the apply itself
+		if err := yaml.Unmarshal([]byte(doc), &obj.Object); err != nil || obj.Object == nil {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	ctx = withBundle(ctx, objs, ts.kube(ctx).Namespace())
+
+	var violations []resourceIssue
+	for i, obj := range objs {
+		for _, issue := range rules.Validate(ctx, obj, ruleset.Options{Strict: true}, nil) {
+			violations = append(violations, resourceIssue{Resource: bundleResource(objs, i), Issue: issue})
+		}
+	}
+	if len(violations) == 0 {
+		return "", nil
+	}
+	if cfg.PolicyEnforcement == policyEnforcementAudit {
+		return fmt.Sprintf("\n\n# Policy audit: enforcement would have blocked this apply:\n%s", formatViolations(violations)), nil
+	}
+	return "", &policyViolationError{Violations: violations}
+}
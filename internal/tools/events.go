@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/sampling"
+)
+
+// workloadKinds are the kinds kagent creates to run agents and MCP servers.
+// Their events are attributed to the kagent resource they belong to.
+var workloadKinds = map[string]bool{
+	"Deployment": true,
+	"ReplicaSet": true,
+	"Pod":        true,
+	"Service":    true,
+}
+
+// EventGroup aggregates the events with the same reason for one kagent
+// resource.
+type EventGroup struct {
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Count     int       `json:"count"`
+	Objects   []string  `json:"objects"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Message   string    `json:"message"`
+}
+
+// registerSummarizeRecentEvents registers the summarize_recent_events tool.
+func (ts *ToolServer) registerSummarizeRecentEvents() {
+	tool := mcp.NewTool("summarize_recent_events",
+		mcp.WithDescription("Summarize the Kubernetes Events for kagent resources in the namespace over a recent time window: events on Agents, ModelConfigs and MCP servers and on the Deployments, Pods and Services running them, grouped by resource and reason. The quickest answer to 'what happened in this namespace in the last hour?'."),
+		mcp.WithString("window",
+			mcp.Description("How far back to look, as a duration (e.g., '15m', '6h'). Default: '1h'"),
+		),
+		mcp.WithBoolean("warnings_only",
+			mcp.Description("Only include Warning events (default: false)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Only include events for the kagent resource with this name"),
+		),
+		mcp.WithBoolean("condense",
+			mcp.Description("Ask the client's LLM (via MCP sampling) for a short narrative of what happened (default: false)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleSummarizeRecentEvents)
+}
+
+func (ts *ToolServer) handleSummarizeRecentEvents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	window := args.Duration("window", time.Hour)
+	warningsOnly := args.Bool("warnings_only", false)
+	name := args.String("name")
+	condense := args.Bool("condense", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client := ts.kube(ctx)
+	events, known, err := client.ListEvents(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list events: %v", err)), nil
+	}
+	if !known {
+		return mcp.NewToolResultError("The server is not allowed to list events in this namespace. Grant 'list' on events to its ServiceAccount."), nil
+	}
+
+	owners, err := eventOwners(ctx, client)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	since := time.Now().Add(-window)
+	groups := groupEvents(events, owners, since, warningsOnly, name)
+
+	header := fmt.Sprintf("# Events in namespace '%s' over the last %s", client.Namespace(), window)
+	if len(groups) == 0 {
+		return mcp.NewToolResultText(header + "\n\nNo events for kagent resources in this window."), nil
+	}
+
+	total, warnings := 0, 0
+	resources := map[string]bool{}
+	var lines []string
+	for _, g := range groups {
+		total += g.Count
+		if g.Type == "Warning" {
+			warnings += g.Count
+		}
+		resources[g.Kind+"/"+g.Name] = true
+		lines = append(lines, fmt.Sprintf("- %s/%s: %s %dx %s (last %s ago, on %s): %s",
+			g.Kind, g.Name, g.Type, g.Count, g.Reason, time.Since(g.LastSeen).Round(time.Second), strings.Join(g.Objects, ", "), g.Message))
+	}
+
+	result := fmt.Sprintf("%s\n# %d event(s) for %d resource(s), %d warning(s)\n\n%s", header, total, len(resources), warnings, strings.Join(lines, "\n"))
+	if condense {
+		result += "\n\n" + ts.condenseEvents(ctx, client.Namespace(), window, lines)
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// eventOwners maps the names of the kagent resources in the client's
+// namespace to their kinds, for attributing events to them.
+func eventOwners(ctx context.Context, client *kubernetes.Client) (map[string]string, error) {
+	owners := map[string]string{}
+	for _, k := range staleKinds {
+		items, err := client.ListResources(ctx, k.GVR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s resources: %w", k.Kind, err)
+		}
+		for _, item := range items {
+			owners[item.GetName()] = k.Kind
+		}
+	}
+	return owners, nil
+}
+
+// groupEvents attributes events seen since the given time to the kagent
+// resources in owners (name to kind), and groups them by resource, type and
+// reason. Warnings come first, then the most recent groups.
+func groupEvents(events []kubernetes.Event, owners map[string]string, since time.Time, warningsOnly bool, name string) []EventGroup {
+	byKey := map[string]*EventGroup{}
+	objects := map[string]map[string]bool{}
+	for _, e := range events {
+		if e.LastSeen.Before(since) || (warningsOnly && e.Type != "Warning") {
+			continue
+		}
+		kind, owner, ok := eventOwner(e, owners)
+		if !ok || (name != "" && owner != name) {
+			continue
+		}
+
+		key := kind + "/" + owner + "\x00" + e.Type + "\x00" + e.Reason
+		g, ok := byKey[key]
+		if !ok {
+			g = &EventGroup{Kind: kind, Name: owner, Type: e.Type, Reason: e.Reason, FirstSeen: e.FirstSeen}
+			byKey[key] = g
+			objects[key] = map[string]bool{}
+		}
+		g.Count += e.Count
+		if e.FirstSeen.Before(g.FirstSeen) {
+			g.FirstSeen = e.FirstSeen
+		}
+		if !e.LastSeen.Before(g.LastSeen) {
+			g.LastSeen = e.LastSeen
+			g.Message = e.Message
+		}
+		objects[key][e.Kind+"/"+e.Name] = true
+	}
+
+	groups := make([]EventGroup, 0, len(byKey))
+	for key, g := range byKey {
+		for object := range objects[key] {
+			g.Objects = append(g.Objects, object)
+		}
+		sort.Strings(g.Objects)
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if (groups[i].Type == "Warning") != (groups[j].Type == "Warning") {
+			return groups[i].Type == "Warning"
+		}
+		return groups[i].LastSeen.After(groups[j].LastSeen)
+	})
+	return groups
+}
+
+// eventOwner returns the kagent resource an event is about: the involved
+// object itself when it is a kagent resource, or the resource whose name
+// prefixes a workload object's name (agent "triage" owns Pod
+// "triage-7d9f-x2k4"). The longest matching name wins.
+func eventOwner(e kubernetes.Event, owners map[string]string) (string, string, bool) {
+	for _, k := range staleKinds {
+		if k.Kind == e.Kind {
+			return e.Kind, e.Name, true
+		}
+	}
+	if !workloadKinds[e.Kind] {
+		return "", "", false
+	}
+
+	best := ""
+	for name := range owners {
+		if (e.Name == name || strings.HasPrefix(e.Name, name+"-")) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return "", "", false
+	}
+	return owners[best], best, true
+}
+
+// condenseEvents asks the sampler for a short narrative of the grouped
+// events. The groups are always returned, so failures are reported inline.
+func (ts *ToolServer) condenseEvents(ctx context.Context, namespace string, window time.Duration, lines []string) string {
+	summary, err := ts.server.Sampler().Sample(ctx, sampling.Request{
+		SystemPrompt: "You are a Kubernetes operator summarizing cluster events for kagent agents and MCP servers. Be brief and concrete.",
+		Prompt: fmt.Sprintf("In a few sentences, say what happened in namespace '%s' over the last %s, which resources need attention and the likely cause. "+
+			"Each line is a resource, the event type, count and reason, and the latest message.\n\n%s", namespace, window, strings.Join(lines, "\n")),
+		MaxTokens: 512,
+	})
+	if errors.Is(err, sampling.ErrUnavailable) {
+		return "Summary: not available (" + err.Error() + ")."
+	}
+	if err != nil {
+		return fmt.Sprintf("Summary: failed to generate: %v", err)
+	}
+	return "Summary:\n" + strings.TrimSpace(summary)
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/upgrade"
+)
+
+// registerExportResources registers the export_resources tool.
+func (ts *ToolServer) registerExportResources() {
+	tool := mcp.NewTool("export_resources",
+		mcp.WithDescription("Export the Agents, ModelConfigs, MCPServers and RemoteMCPServers of the namespace as one multi-document YAML, with status and server-managed fields stripped, for backups or bootstrapping a GitOps repository. The documents are in the order to apply them and are read at a single point in time. Secrets are not exported; the ones referenced are listed."),
+		mcp.WithString("kinds",
+			mcp.Description("Comma-separated kinds to export: ModelConfig, MCPServer, RemoteMCPServer, Agent (default: all)"),
+		),
+		withLabelSelectorOption(),
+		mcp.WithBoolean("strip_namespace",
+			mcp.Description("Omit metadata.namespace, so the export can be applied to any namespace (default: false)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleExportResources)
+}
+
+func (ts *ToolServer) handleExportResources(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kinds := args.StringList("kinds")
+	selector := args.String("label_selector")
+	stripNamespace := args.Bool("strip_namespace", false)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if len(kinds) == 0 {
+		kinds = copyKindOrder
+	}
+	var gvrs []schema.GroupVersionResource
+	for _, kind := range kinds {
+		gvr, ok := kagentGVR(kind)
+		if !ok || !containsString(copyKindOrder, kind) {
+			return mcp.NewToolResultError(fmt.Sprintf("Unsupported kind '%s'. Expected: %s", kind, strings.Join(copyKindOrder, ", "))), nil
+		}
+		gvrs = append(gvrs, gvr)
+	}
+	matcher, err := labels.Parse(selector)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid label_selector '%s': %v", selector, err)), nil
+	}
+
+	client := ts.kube(ctx)
+	snapshot, err := client.ListSnapshot(ctx, gvrs...)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list resources: %v", err)), nil
+	}
+
+	var docs, counts []string
+	secrets := map[string]bool{}
+	for _, kind := range copyKindOrder {
+		if !containsString(kinds, kind) {
+			continue
+		}
+		gvr, _ := kagentGVR(kind)
+		items := snapshot.Items(gvr)
+		sort.Slice(items, func(i, j int) bool { return items[i].GetName() < items[j].GetName() })
+
+		count := 0
+		for i := range items {
+			if !matcher.Matches(labels.Set(items[i].GetLabels())) {
+				continue
+			}
+			obj := exportedObject(&items[i], kind, stripNamespace)
+			if secret, _, _ := unstructured.NestedString(obj.Object, "spec", "apiKeySecret"); secret != "" {
+				secrets[secret] = true
+			}
+			if secret := obj.GetAnnotations()[annotationA2ASecret]; secret != "" {
+				secrets[secret] = true
+			}
+			output, _ := yaml.Marshal(obj.Object)
+			docs = append(docs, string(output))
+			count++
+		}
+		if count > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", count, kind))
+		}
+	}
+	if len(docs) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No %s found in namespace '%s'%s. Nothing to export.", strings.Join(kinds, ", "), client.Namespace(), describeSelectors(kubernetes.ListOptions{LabelSelector: selector}))), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Export of namespace '%s' at %s (resourceVersion %s)\n", client.Namespace(), time.Now().UTC().Format(time.RFC3339), snapshot.ResourceVersion)
+	if !snapshot.Consistent {
+		b.WriteString("# WARNING: the API server could not serve a consistent snapshot; kinds were read one after another.\n")
+	}
+	fmt.Fprintf(&b, "# Resources: %s, in the order to apply them.\n", strings.Join(counts, ", "))
+	if len(secrets) > 0 {
+		fmt.Fprintf(&b, "# Secrets referenced but not exported (back them up separately): %s\n", strings.Join(sortedKeys(secrets), ", "))
+	}
+	b.WriteString("# Restore with validate_manifest, then apply_manifest.")
+
+	return out.render(b.String(), strings.Join(docs, "---\n"))
+}
+
+// exportedObject returns obj as it should be stored: without status,
+// server-set metadata, owner references and finalizers, which the API
+// server rejects or recomputes on a restore.
+func exportedObject(obj *unstructured.Unstructured, kind string, stripNamespace bool) *unstructured.Unstructured {
+	exported := upgrade.Clean(obj)
+	exported.SetKind(kind)
+	exported.SetOwnerReferences(nil)
+	unstructured.RemoveNestedField(exported.Object, "metadata", "finalizers")
+	unstructured.RemoveNestedField(exported.Object, "metadata", "generateName")
+	if stripNamespace {
+		unstructured.RemoveNestedField(exported.Object, "metadata", "namespace")
+	}
+	return exported
+}
@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// ExternalDNS annotations read from Ingresses.
+const (
+	externalDNSHostname = "external-dns.alpha.kubernetes.io/hostname"
+	externalDNSTTL      = "external-dns.alpha.kubernetes.io/ttl"
+	externalDNSTarget   = "external-dns.alpha.kubernetes.io/target"
+)
+
+// registerExposeAgent registers the expose_agent tool.
+func (ts *ToolServer) registerExposeAgent() {
+	tool := mcp.NewTool("expose_agent",
+		mcp.WithDescription("Generate the manifests that expose an agent's A2A endpoint on a public hostname with TLS: an Ingress routing the hostname to the agent's Service, annotated for ExternalDNS to publish the DNS record, and a cert-manager Certificate for the hostname from the given issuer. Requires an ingress controller, cert-manager and, for DNS, ExternalDNS."),
+		mcp.WithString("agent_name",
+			mcp.Required(),
+			mcp.Description("Name of the agent to expose"),
+		),
+		mcp.WithString("hostname",
+			mcp.Description("Public hostname of the A2A endpoint (e.g., 'triage.agents.example.com', or 'triage.{{ingress_domain}}' in the cluster's ingress domain). Default: the host of the environment's URL template; required without environments"),
+		),
+		withEnvironmentOption(),
+		mcp.WithString("issuer",
+			mcp.Required(),
+			mcp.Description("cert-manager issuer that signs the certificate (e.g., 'letsencrypt-prod')"),
+		),
+		mcp.WithString("issuer_kind",
+			mcp.Description("Kind of the issuer: 'ClusterIssuer' or 'Issuer' (default: 'ClusterIssuer')"),
+		),
+		mcp.WithString("ingress_class",
+			mcp.Description("IngressClass that serves the hostname (default: the cluster's default class)"),
+		),
+		mcp.WithNumber("port",
+			mcp.Description("Port of the agent's Service (default: 80, as in http://<name>.<namespace>.svc.cluster.local)"),
+		),
+		mcp.WithBoolean("external_dns",
+			mcp.Description("Annotate the Ingress for ExternalDNS (default: true)"),
+		),
+		mcp.WithNumber("dns_ttl",
+			mcp.Description("TTL of the DNS record in seconds (default: ExternalDNS's default)"),
+		),
+		mcp.WithString("dns_target",
+			mcp.Description("Override the DNS record target, e.g. a load balancer hostname (default: the Ingress address)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleExposeAgent)
+}
+
+func (ts *ToolServer) handleExposeAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	agentName := args.RequiredString("agent_name")
+	hostname := strings.ToLower(strings.TrimSuffix(args.String("hostname"), "."))
+	environment := args.String("environment")
+	issuer := args.RequiredString("issuer")
+	issuerKind := args.Enum("issuer_kind", "ClusterIssuer", "ClusterIssuer", "Issuer")
+	ingressClass := args.String("ingress_class")
+	port := args.IntRange("port", 80, 1, 65535)
+	externalDNS := args.Bool("external_dns", true)
+	ttl := args.IntRange("dns_ttl", 0, 0, 86400)
+	target := args.String("dns_target")
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := ts.expandFacts(ctx, &hostname); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid hostname: %v", err)), nil
+	}
+
+	env, err := ts.environments.Lookup(environment)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if hostname == "" && env == nil {
+		return mcp.NewToolResultError("hostname is required: no environment is configured to derive it from"), nil
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, agentName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	var warning string
+	if hostname == "" {
+		if hostname, err = env.Host(agent.Name, agentNamespace(agent), ts.facts(ctx)); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to derive hostname: %v", err)), nil
+		}
+	}
+	if env != nil {
+		if err := env.Check("https://" + hostname); err != nil {
+			warning = err.Error()
+		}
+	}
+	if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 || !strings.Contains(hostname, ".") {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid hostname '%s': expected a fully qualified DNS name", hostname)), nil
+	}
+
+	namespace := agent.Namespace
+	name := agentName + "-a2a"
+	tlsSecret := name + "-tls"
+	labels := map[string]interface{}{
+		"app.kubernetes.io/name":      agentName,
+		"app.kubernetes.io/component": "a2a-ingress",
+	}
+
+	annotations := map[string]interface{}{}
+	if externalDNS {
+		annotations[externalDNSHostname] = hostname
+		if ttl > 0 {
+			annotations[externalDNSTTL] = fmt.Sprintf("%d", ttl)
+		}
+		if target != "" {
+			annotations[externalDNSTarget] = target
+		}
+	}
+
+	ingressSpec := map[string]interface{}{
+		"tls": []interface{}{
+			map[string]interface{}{
+				"hosts":      []interface{}{hostname},
+				"secretName": tlsSecret,
+			},
+		},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"host": hostname,
+				"http": map[string]interface{}{
+					"paths": []interface{}{
+						map[string]interface{}{
+							"path":     "/",
+							"pathType": "Prefix",
+							"backend": map[string]interface{}{
+								"service": map[string]interface{}{
+									"name": agentName,
+									"port": map[string]interface{}{"number": port},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if ingressClass != "" {
+		ingressSpec["ingressClassName"] = ingressClass
+	}
+
+	ingressMeta := map[string]interface{}{
+		"name":      name,
+		"namespace": namespace,
+		"labels":    labels,
+	}
+	if len(annotations) > 0 {
+		ingressMeta["annotations"] = annotations
+	}
+	ingress := map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata":   ingressMeta,
+		"spec":       ingressSpec,
+	}
+
+	certificate := map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    labels,
+		},
+		"spec": map[string]interface{}{
+			"secretName": tlsSecret,
+			"commonName": hostname,
+			"dnsNames":   []interface{}{hostname},
+			"usages":     []interface{}{"server auth"},
+			"issuerRef": map[string]interface{}{
+				"kind": issuerKind,
+				"name": issuer,
+			},
+		},
+	}
+
+	var docs []string
+	for _, obj := range []interface{}{certificate, ingress} {
+		output, _ := yaml.Marshal(obj)
+		docs = append(docs, string(output))
+	}
+
+	header := fmt.Sprintf(`# Public A2A endpoint for agent '%s'
+# URL: https://%s (Ingress '%s' -> Service '%s':%d)
+# TLS: Certificate '%s' from %s '%s', stored in Secret '%s'`,
+		agentName, hostname, name, agentName, port, name, issuerKind, issuer, tlsSecret)
+	if env != nil {
+		header += "\n# Environment: " + env.Name
+	}
+	if warning != "" {
+		header += "\n# WARNING: " + warning
+	}
+	if externalDNS {
+		header += fmt.Sprintf("\n# DNS: ExternalDNS publishes %s once the Ingress has an address.", hostname)
+	} else {
+		header += fmt.Sprintf("\n# DNS: create a record for %s pointing at the Ingress address.", hostname)
+	}
+	header += `
+# Anyone who can resolve the hostname can reach the agent: secure it first
+# with configure_a2a_security. Apply with kubectl (Ingress and Certificate
+# are not kagent kinds).`
+
+	return out.render(header, strings.Join(docs, "---\n"))
+}
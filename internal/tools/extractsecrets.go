@@ -0,0 +1,330 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// sensitiveQueryParams are URL query parameters that carry credentials.
+var sensitiveQueryParams = []string{"token", "access_token", "api_key", "apikey", "key", "password", "secret", "client_secret"}
+
+// urlCredential describes the credential embedded in a URL, or returns ""
+// when it has none.
+func urlCredential(raw string) string {
+	if !strings.Contains(raw, "://") || strings.ContainsAny(raw, " \n") {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if _, ok := u.User.Password(); ok {
+		return "a password in the URL's user info"
+	}
+	query := u.Query()
+	for _, name := range sensitiveQueryParams {
+		for param, values := range query {
+			if strings.EqualFold(param, name) && len(values) > 0 && values[0] != "" && !strings.HasPrefix(values[0], secretPlaceholderPrefix) {
+				return fmt.Sprintf("the query parameter '%s'", param)
+			}
+		}
+	}
+	return ""
+}
+
+// registerExtractSecrets registers the extract_secrets tool.
+func (ts *ToolServer) registerExtractSecrets() {
+	tool := mcp.NewTool("extract_secrets",
+		mcp.WithDescription("Find credentials embedded in manifests (literal env values of KEY/TOKEN/PASSWORD-like variables, API keys and tokens, passwords and tokens in URLs, API keys in place of a ModelConfig's secret name) and move them to Secrets: returns the Secrets, the resources rewritten to reference them, and the findings as security issues. Scans the given manifest, or the kagent resources in the namespace. Nothing is applied."),
+		mcp.WithString("manifest",
+			mcp.Description("YAML manifest or multi-document bundle to scan (default: the kagent resources in the namespace)"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Without manifest, only scan this kind: Agent, ModelConfig, MCPServer, or RemoteMCPServer"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Without manifest, only scan the resource of kind with this name"),
+		),
+		mcp.WithString("secret_name",
+			mcp.Description("Name of the Secret to move the credentials to (default: '<resource>-credentials' per resource)"),
+		),
+		withOutputFormatOption(),
+	)
+
+	ts.addTool(tool, ts.handleExtractSecrets)
+}
+
+func (ts *ToolServer) handleExtractSecrets(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	manifest := args.String("manifest")
+	kind := args.String("kind")
+	name := args.String("name")
+	secretName := args.String("secret_name")
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if secretName != "" {
+		if errs := validation.IsDNS1123Subdomain(secretName); len(errs) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid secret_name '%s': %s", secretName, strings.Join(errs, "; "))), nil
+		}
+	}
+
+	client := ts.kube(ctx)
+	var objs []*unstructured.Unstructured
+	switch {
+	case manifest != "":
+		if kind != "" || name != "" {
+			return mcp.NewToolResultError("Pass either manifest or kind and name, not both"), nil
+		}
+		for i, doc := range kubernetes.SplitManifests(manifest) {
+			obj := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal([]byte(doc), &obj.Object); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to parse document %d: %v", i+1, err)), nil
+			}
+			objs = append(objs, obj)
+		}
+		if len(objs) == 0 {
+			return mcp.NewToolResultError("manifest is empty"), nil
+		}
+	case name != "":
+		gvr, ok := kagentGVR(kind)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("name requires kind: one of %s", strings.Join(copyKindOrder, ", "))), nil
+		}
+		obj, err := client.GetResource(ctx, gvr, name)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get %s: %v", kind, err)), nil
+		}
+		objs = append(objs, exportedObject(obj, kind, false))
+	default:
+		kinds := copyKindOrder
+		if kind != "" {
+			if _, ok := kagentGVR(kind); !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("Unsupported kind '%s'. Expected: %s", kind, strings.Join(copyKindOrder, ", "))), nil
+			}
+			kinds = []string{kind}
+		}
+		for _, k := range kinds {
+			gvr, _ := kagentGVR(k)
+			items, err := client.ListResources(ctx, gvr)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to list %s: %v", gvr.Resource, err)), nil
+			}
+			sort.Slice(items, func(i, j int) bool { return items[i].GetName() < items[j].GetName() })
+			for i := range items {
+				objs = append(objs, exportedObject(&items[i], k, false))
+			}
+		}
+	}
+
+	x := &secretExtraction{namespace: client.Namespace(), secretName: secretName, secrets: map[string]map[string]string{}}
+	changed := make([]bool, len(objs))
+	for i, obj := range objs {
+		changed[i] = x.extract(obj, bundleResource(objs, i))
+	}
+	if len(x.findings) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("✓ No inline credentials found in %d resource(s).", len(objs))), nil
+	}
+
+	// The Secrets come first so the references resolve when applied in order
+	var docs []string
+	for _, key := range sortedKeys(x.secrets) {
+		namespace, secret, _ := strings.Cut(key, "/")
+		output, _ := yaml.Marshal(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": secret, "namespace": namespace},
+			"type":       "Opaque",
+			"stringData": x.secrets[key],
+		})
+		docs = append(docs, string(output))
+	}
+	for i, obj := range objs {
+		// A scan of the cluster only returns what changes
+		if manifest == "" && !changed[i] {
+			continue
+		}
+		output, _ := yaml.Marshal(obj.Object)
+		docs = append(docs, string(output))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Extracted Secrets: %d credential(s) moved to %d Secret(s)\n", x.moved, len(x.secrets))
+	for _, f := range x.findings {
+		where := f.Resource
+		if f.Field != "" {
+			where += " " + f.Field
+		}
+		fmt.Fprintf(&b, "# ⛔ [%s] (%s): %s\n", f.Category, where, f.Message)
+	}
+	b.WriteString(`# IMPORTANT: The Secrets below carry the credentials. Create them out of band
+# (kubectl, Sealed Secrets, External Secrets) and never commit them; commit only the
+# other resources. Rotate every credential that was ever committed in plain text.`)
+
+	return out.render(b.String(), strings.Join(docs, "---\n"))
+}
+
+// secretExtraction moves the credentials of resources into Secrets.
+type secretExtraction struct {
+	namespace  string
+	secretName string
+	// secrets holds the values of each Secret, by namespace/name.
+	secrets  map[string]map[string]string
+	findings []SecurityFinding
+	moved    int
+}
+
+// extract rewrites obj to reference Secrets instead of the credentials it
+// embeds, recording a finding for each. Credentials in fields that cannot
+// reference a Secret are only reported. It reports whether obj changed.
+func (x *secretExtraction) extract(obj *unstructured.Unstructured, resource string) bool {
+	if obj.GetKind() == "Secret" {
+		if hasData(obj) && !hasOnlyPlaceholders(obj) {
+			x.report(resource, "data", "Secret values are embedded in the manifest; create the Secret out of band and keep it out of version control")
+		}
+		return false
+	}
+
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = x.namespace
+	}
+	secret := x.secretName
+	if secret == "" {
+		secret = obj.GetName() + "-credentials"
+	}
+	changed := false
+
+	// An API key pasted where the ModelConfig names its Secret
+	if obj.GetKind() == "ModelConfig" {
+		value, _, _ := unstructured.NestedString(obj.Object, "spec", "apiKeySecret")
+		if value != "" && matchesSecretLiteral(value) {
+			provider, _, _ := unstructured.NestedString(obj.Object, "spec", "provider")
+			key := x.store(namespace, secret, defaultAPIKeySecretKey(provider), value)
+			_ = unstructured.SetNestedField(obj.Object, secret, "spec", "apiKeySecret")
+			_ = unstructured.SetNestedField(obj.Object, key, "spec", "apiKeySecretKey")
+			x.report(resource, "spec.apiKeySecret", fmt.Sprintf("holds an API key instead of a Secret name; moved to Secret '%s' key '%s'", secret, key))
+			changed = true
+		}
+	}
+
+	var walk func(value interface{}, field string)
+	walk = func(value interface{}, field string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if strings.HasSuffix(field, "]") && strings.Contains(field, "env[") {
+				if x.extractEnv(v, namespace, secret, resource, field) {
+					changed = true
+					return
+				}
+			}
+			for _, k := range sortedKeys(v) {
+				walk(v[k], joinField(field, k))
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(child, fmt.Sprintf("%s[%d]", field, i))
+			}
+		case string:
+			if field == "spec.apiKeySecret" {
+				return
+			}
+			if what := urlCredential(v); what != "" {
+				x.report(resource, field, fmt.Sprintf("URL embeds %s, and this field cannot reference a Secret; remove it from the URL and pass it from a Secret instead (e.g. as a header)", what))
+			} else if matchesSecretLiteral(v) {
+				x.report(resource, field, "value looks like a credential, and this field cannot reference a Secret; remove it")
+			}
+		}
+	}
+	walk(obj.Object, "")
+	return changed
+}
+
+// extractEnv moves the literal value of an env var holding a credential to
+// the Secret and references it with valueFrom.secretKeyRef.
+func (x *secretExtraction) extractEnv(env map[string]interface{}, namespace, secret, resource, field string) bool {
+	name, _ := env["name"].(string)
+	value, _ := env["value"].(string)
+	if name == "" || value == "" || strings.HasPrefix(value, secretPlaceholderPrefix) {
+		return false
+	}
+
+	var why string
+	switch {
+	case sensitiveEnvPattern.MatchString(name):
+		why = "holds a literal credential"
+	case matchesSecretLiteral(value):
+		why = "holds a value that looks like a credential"
+	default:
+		if what := urlCredential(value); what != "" {
+			why = "holds a URL with " + what
+		}
+	}
+	if why == "" {
+		return false
+	}
+
+	key := x.store(namespace, secret, name, value)
+	delete(env, "value")
+	env["valueFrom"] = map[string]interface{}{
+		"secretKeyRef": map[string]interface{}{
+			"name": secret,
+			"key":  key,
+		},
+	}
+	x.report(resource, field+".value", fmt.Sprintf("environment variable %s %s; moved to Secret '%s' key '%s'", name, why, secret, key))
+	return true
+}
+
+// store adds a value to a Secret under key, or under a numbered key when
+// key already holds a different value, and returns the key used.
+func (x *secretExtraction) store(namespace, secret, key, value string) string {
+	ref := namespace + "/" + secret
+	data := x.secrets[ref]
+	if data == nil {
+		data = map[string]string{}
+		x.secrets[ref] = data
+	}
+	used := key
+	for n := 2; data[used] != "" && data[used] != value; n++ {
+		used = fmt.Sprintf("%s_%d", key, n)
+	}
+	if data[used] == "" {
+		x.moved++
+	}
+	data[used] = value
+	return used
+}
+
+func (x *secretExtraction) report(resource, field, message string) {
+	x.findings = append(x.findings, SecurityFinding{
+		Category: categorySecrets,
+		Severity: SecurityBlock,
+		Resource: resource,
+		Field:    field,
+		Message:  message,
+	})
+}
+
+// matchesSecretLiteral reports whether s contains a well-known credential
+// format.
+func matchesSecretLiteral(s string) bool {
+	for _, pattern := range secretLiteralPatterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
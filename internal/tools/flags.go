@@ -0,0 +1,344 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// flagsAnnotation names the ConfigMap holding an agent's feature flags when
+// it is not the default <agent>-flags.
+const flagsAnnotation = "kagent.dev/flags"
+
+// flagsLabel marks flag ConfigMaps with the agent they belong to.
+const flagsLabel = "kagent.dev/agent-flags"
+
+// Feature flags are exposed to the agent container either as FLAG_*
+// environment variables or as files in flagsMountPath.
+const (
+	flagsEnvPrefix = "FLAG_"
+	flagsVolume    = "feature-flags"
+	flagsMountPath = "/etc/kagent/flags"
+)
+
+// Flag exposure modes of set_agent_flag.
+const (
+	exposeEnv    = "env"
+	exposeVolume = "volume"
+)
+
+// flagNamePattern matches valid ConfigMap keys.
+var flagNamePattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// flagsConfigMapName returns the ConfigMap holding an agent's feature flags.
+func flagsConfigMapName(agentName string, annotations map[string]string) string {
+	if name := annotations[flagsAnnotation]; name != "" {
+		return name
+	}
+	return agentName + "-flags"
+}
+
+// flagEnvName returns the environment variable a flag is exposed as:
+// "verbose-mode" becomes FLAG_VERBOSE_MODE.
+func flagEnvName(flag string) string {
+	return flagsEnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(flag))
+}
+
+// AgentFlag is a feature flag of an agent and how the agent sees it.
+type AgentFlag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Env is the environment variable reading the flag, if any.
+	Env string `json:"env,omitempty"`
+	// File is the path the flag is mounted at, if any.
+	File string `json:"file,omitempty"`
+}
+
+// registerGetAgentFlags registers the get_agent_flags tool.
+func (ts *ToolServer) registerGetAgentFlags() {
+	tool := mcp.NewTool("get_agent_flags",
+		mcp.WithDescription("Show an agent's feature flags from its flag ConfigMap (see set_agent_flag), with the environment variable or file each flag reaches the agent through. Flags that are set but not wired into the agent are called out."),
+		mcp.WithString("agent_name",
+			mcp.Required(),
+			mcp.Description("Name of the agent"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleGetAgentFlags)
+}
+
+func (ts *ToolServer) handleGetAgentFlags(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	agentName := args.RequiredString("agent_name")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, agentName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	configMapName := flagsConfigMapName(agentName, agent.Annotations)
+	data, found, err := ts.kube(ctx).GetConfigMapData(ctx, configMapName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read flags: %v", err)), nil
+	}
+	if !found {
+		return mcp.NewToolResultText(fmt.Sprintf("Agent '%s' has no feature flags (ConfigMap '%s' does not exist). Add one with set_agent_flag.", agentName, configMapName)), nil
+	}
+
+	env, volumes, mounts := agentDeploymentSettings(agent)
+	mounted := flagsMountedAt(configMapName, volumes, mounts)
+
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var flags []AgentFlag
+	var unwired []string
+	for _, name := range names {
+		flag := AgentFlag{Name: name, Value: data[name]}
+		for _, e := range env {
+			if e.ValueFrom != nil && e.ValueFrom.ConfigMapKeyRef != nil &&
+				e.ValueFrom.ConfigMapKeyRef.Name == configMapName && e.ValueFrom.ConfigMapKeyRef.Key == name {
+				flag.Env = e.Name
+			}
+		}
+		if mounted != "" {
+			flag.File = mounted + "/" + name
+		}
+		if flag.Env == "" && flag.File == "" {
+			unwired = append(unwired, name)
+		}
+		flags = append(flags, flag)
+	}
+
+	output, _ := json.MarshalIndent(flags, "", "  ")
+	result := fmt.Sprintf("# Feature flags of agent '%s' (ConfigMap '%s', %d)\n\n%s", agentName, configMapName, len(flags), string(output))
+	if len(unwired) > 0 {
+		result += fmt.Sprintf("\n\n⚠️ Not exposed to the agent: %s. Run set_agent_flag on them to wire them in.", strings.Join(unwired, ", "))
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// registerSetAgentFlag registers the set_agent_flag tool.
+func (ts *ToolServer) registerSetAgentFlag() {
+	tool := mcp.NewTool("set_agent_flag",
+		mcp.WithDescription("Set or remove a feature flag of an agent. Generates the agent's flag ConfigMap and, when the flag is not yet wired in, the updated Agent exposing it as an environment variable or a mounted file, so behavior can be toggled without editing the prompt. The bundle is registered for review: apply it with apply_manifest and the returned diff_id."),
+		mcp.WithString("agent_name",
+			mcp.Required(),
+			mcp.Description("Name of the agent"),
+		),
+		mcp.WithString("flag",
+			mcp.Required(),
+			mcp.Description("Flag name, a valid ConfigMap key (e.g., 'verbose-mode')"),
+		),
+		mcp.WithString("value",
+			mcp.Description("Flag value (e.g., 'true', 'strict'). Required unless remove is set"),
+		),
+		mcp.WithBoolean("remove",
+			mcp.Description("Remove the flag instead of setting it (default: false)"),
+		),
+		mcp.WithString("expose",
+			mcp.Description("How the agent reads its flags: 'env' (a FLAG_<NAME> variable, read at pod start) or 'volume' (files under /etc/kagent/flags, updated in place). Default: 'env'"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleSetAgentFlag)
+}
+
+func (ts *ToolServer) handleSetAgentFlag(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	agentName := args.RequiredString("agent_name")
+	flag := args.RequiredString("flag")
+	value := args.String("value")
+	remove := args.Bool("remove", false)
+	expose := args.Enum("expose", exposeEnv, exposeEnv, exposeVolume)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if !flagNamePattern.MatchString(flag) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid flag name '%s': use letters, digits, '-', '_' and '.'", flag)), nil
+	}
+	if !remove && value == "" {
+		return mcp.NewToolResultError("value is required unless remove is set"), nil
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, agentName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	configMapName := flagsConfigMapName(agentName, agent.Annotations)
+	data, found, err := ts.kube(ctx).GetConfigMapData(ctx, configMapName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read flags: %v", err)), nil
+	}
+
+	previous, existed := data[flag]
+	var change string
+	switch {
+	case remove && !existed:
+		return mcp.NewToolResultText(fmt.Sprintf("Agent '%s' has no flag '%s'. Nothing to change.", agentName, flag)), nil
+	case remove:
+		delete(data, flag)
+		change = fmt.Sprintf("%s: %q -> (removed)", flag, previous)
+	case existed:
+		data[flag] = value
+		change = fmt.Sprintf("%s: %q -> %q", flag, previous, value)
+	default:
+		data[flag] = value
+		change = fmt.Sprintf("%s: (unset) -> %q", flag, value)
+	}
+
+	configMap := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      configMapName,
+			"namespace": ts.kube(ctx).Namespace(),
+			"labels": map[string]interface{}{
+				flagsLabel: agentName,
+			},
+		},
+		"data": data,
+	}
+	output, _ := yaml.Marshal(configMap)
+	docs := []string{string(output)}
+
+	rewired, err := wireAgentFlag(agent, configMapName, flag, expose, remove)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if rewired {
+		agent.APIVersion = "kagent.dev/v1alpha2"
+		agent.Kind = "Agent"
+		output, _ := yaml.Marshal(agent)
+		docs = append(docs, string(output))
+	}
+
+	bundle := strings.Join(docs, "---\n")
+	diffID, err := ts.reviews.Add(ctx, bundle, "ConfigMap", configMapName, sessionOwner(ctx))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to record the change for review: %v", err)), nil
+	}
+
+	header := fmt.Sprintf(`# Feature flag for agent '%s' (ConfigMap '%s')
+# Change: %s
+# Diff ID: %s (apply with apply_manifest diff_id=%s; ConfigMap must be listed
+# in KAGENT_APPLY_ALLOWED_KINDS)`, agentName, configMapName, change, diffID, diffID)
+	switch {
+	case rewired:
+		header += "\n# The Agent is updated to expose the flag, which restarts its pods."
+	case expose == exposeEnv && !remove:
+		header += "\n# Environment variables are read at pod start: restart the agent's pods to pick up the new value."
+	}
+	if !found {
+		header += "\n# The ConfigMap does not exist yet and will be created."
+	}
+
+	return out.render(header, bundle)
+}
+
+// wireAgentFlag updates an agent's deployment so it reads a flag from the
+// ConfigMap, or stops reading a removed one. It reports whether the agent
+// changed.
+func wireAgentFlag(agent *types.Agent, configMapName, flag, expose string, remove bool) (bool, error) {
+	env, volumes, mounts := agentDeploymentSettings(agent)
+	envName := flagEnvName(flag)
+
+	var nextEnv []types.EnvVar
+	wired := false
+	for _, e := range env {
+		if e.Name == envName {
+			wired = true
+			if remove {
+				continue
+			}
+		}
+		nextEnv = append(nextEnv, e)
+	}
+
+	switch {
+	case remove && !wired:
+		return false, nil
+	case remove:
+		return true, setAgentDeploymentSettings(agent, nextEnv, volumes, mounts)
+	case expose == exposeEnv && !wired:
+		env = append(env, types.EnvVar{
+			Name:      envName,
+			ValueFrom: &types.EnvVarSource{ConfigMapKeyRef: &types.KeySelector{Name: configMapName, Key: flag}},
+		})
+		return true, setAgentDeploymentSettings(agent, env, volumes, mounts)
+	case expose == exposeVolume && flagsMountedAt(configMapName, volumes, mounts) == "":
+		volumes = append(volumes, types.Volume{Name: flagsVolume, ConfigMap: &types.ConfigMapVolume{Name: configMapName}})
+		mounts = append(mounts, types.VolumeMount{Name: flagsVolume, MountPath: flagsMountPath, ReadOnly: true})
+		return true, setAgentDeploymentSettings(agent, env, volumes, mounts)
+	}
+	return false, nil
+}
+
+// flagsMountedAt returns the path the flag ConfigMap is mounted at, or ""
+// when it is not mounted.
+func flagsMountedAt(configMapName string, volumes []types.Volume, mounts []types.VolumeMount) string {
+	for _, v := range volumes {
+		if v.ConfigMap == nil || v.ConfigMap.Name != configMapName {
+			continue
+		}
+		for _, m := range mounts {
+			if m.Name == v.Name && m.SubPath == "" {
+				return m.MountPath
+			}
+		}
+	}
+	return ""
+}
+
+// agentDeploymentSettings returns the env, volumes and mounts of an agent's
+// container.
+func agentDeploymentSettings(agent *types.Agent) ([]types.EnvVar, []types.Volume, []types.VolumeMount) {
+	switch {
+	case agent.Spec.Declarative != nil && agent.Spec.Declarative.Deployment != nil:
+		d := agent.Spec.Declarative.Deployment
+		return d.Env, d.Volumes, d.VolumeMounts
+	case agent.Spec.BYO != nil && agent.Spec.BYO.Deployment != nil:
+		d := agent.Spec.BYO.Deployment
+		return d.Env, d.Volumes, d.VolumeMounts
+	}
+	return nil, nil, nil
+}
+
+// setAgentDeploymentSettings stores the env, volumes and mounts of an
+// agent's container.
+func setAgentDeploymentSettings(agent *types.Agent, env []types.EnvVar, volumes []types.Volume, mounts []types.VolumeMount) error {
+	switch {
+	case agent.Spec.Declarative != nil:
+		if agent.Spec.Declarative.Deployment == nil {
+			agent.Spec.Declarative.Deployment = &types.DeclarativeDeploymentSpec{}
+		}
+		d := agent.Spec.Declarative.Deployment
+		d.Env, d.Volumes, d.VolumeMounts = env, volumes, mounts
+	case agent.Spec.BYO != nil && agent.Spec.BYO.Deployment != nil:
+		d := agent.Spec.BYO.Deployment
+		d.Env, d.Volumes, d.VolumeMounts = env, volumes, mounts
+	default:
+		return fmt.Errorf("agent '%s' has no deployment to expose flags to", agent.Name)
+	}
+	return nil
+}
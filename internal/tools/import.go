@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// importKindOrder ranks kinds in the order import_resources applies them:
+// namespaces and identities first, then what agents reference, then agents.
+// Kinds not listed are applied after RBAC and before kagent kinds.
+var importKindOrder = []string{
+	"Namespace",
+	"ServiceAccount", "Secret", "ConfigMap",
+	"Role", "RoleBinding",
+	"ModelConfig", "MCPServer", "RemoteMCPServer",
+	"Agent",
+}
+
+// importStep is one resource of an import plan.
+type importStep struct {
+	doc       string
+	kind      string
+	name      string
+	namespace string
+	// action is what applying would do: "created", "updated" or
+	// "unchanged", or "failed" when the dry run was rejected.
+	action string
+	err    string
+}
+
+// registerImportResources registers the import_resources tool.
+func (ts *ToolServer) registerImportResources() {
+	tool := mcp.NewTool("import_resources",
+		mcp.WithDescription("Restore or bootstrap resources from a multi-document YAML bundle, such as one produced by export_resources. Every document is validated, then a server-side dry run plans each resource as create, update or no-op, and the bundle is applied in dependency order (Namespaces, ServiceAccounts and RBAC, then ModelConfigs and MCP servers, then Agents). Nothing is applied if validation fails. Use dry_run=true to see the plan only."),
+		mcp.WithString("manifest",
+			mcp.Required(),
+			mcp.Description("Multi-document YAML bundle to import"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only validate and show the plan (default: false)"),
+		),
+		mcp.WithBoolean("strict",
+			mcp.Description("Validate with best-practice checks; warnings never block the import (default: false)"),
+		),
+		mcp.WithBoolean("continue_on_error",
+			mcp.Description("Keep applying the remaining resources after a failure instead of stopping (default: false)"),
+		),
+		mcp.WithString("signature",
+			mcp.Description("Approver's signature of the bundle's digest, when the server requires signed manifests (see apply_manifest)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleImportResources)
+}
+
+func (ts *ToolServer) handleImportResources(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	manifest := args.RequiredString("manifest")
+	dryRun := args.Bool("dry_run", false)
+	strict := args.Bool("strict", false)
+	continueOnError := args.Bool("continue_on_error", false)
+	signature := args.String("signature")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := ts.checkSignature(manifest, signature, dryRun); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	docs := kubernetes.SplitManifests(manifest)
+	if len(docs) == 0 {
+		return mcp.NewToolResultError("manifest is empty"), nil
+	}
+	steps := make([]importStep, len(docs))
+	for i, doc := range docs {
+		obj, err := kubernetes.ParseManifest(doc)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("document %d: %v", i+1, err)), nil
+		}
+		steps[i] = importStep{doc: doc, kind: obj.GetKind(), name: obj.GetName(), namespace: obj.GetNamespace()}
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return importRank(steps[i].kind) < importRank(steps[j].kind) })
+
+	ordered := make([]string, len(steps))
+	for i, step := range steps {
+		ordered[i] = step.doc
+	}
+	issues, err := ts.validateBundle(ctx, ordered, strict, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	grouped := dedupeIssues(issues)
+	if errs, _ := issueMessages(grouped); len(errs) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Nothing was imported: the bundle has %d validation error(s).\n\n%s", len(errs), formatValidationReport(grouped))), nil
+	}
+
+	audit, err := ts.enforcePolicies(ctx, ordered)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Nothing was imported. %v", err)), nil
+	}
+
+	ts.planImport(ctx, steps)
+	if dryRun {
+		return mcp.NewToolResultText(formatImport(steps, grouped, true, 0, continueOnError) + audit), nil
+	}
+
+	failed := 0
+	for i := range steps {
+		step := &steps[i]
+		if failed > 0 && !continueOnError {
+			step.action, step.err = "skipped", ""
+			continue
+		}
+		if step.action == "unchanged" {
+			continue
+		}
+		result, err := ts.applyDocument(ctx, step.doc, false, kubernetes.Preconditions{})
+		if err != nil {
+			failed++
+			step.action, step.err = "failed", err.Error()
+			continue
+		}
+		step.action, step.err = result.Action, ""
+	}
+	return mcp.NewToolResultText(formatImport(steps, grouped, false, failed, continueOnError) + audit), nil
+}
+
+// planImport dry-runs each step to find what applying it would do.
+// Resources in a namespace the bundle creates cannot be dry-run before the
+// namespace exists, and are planned as created.
+func (ts *ToolServer) planImport(ctx context.Context, steps []importStep) {
+	created := map[string]bool{}
+	for i := range steps {
+		step := &steps[i]
+		namespace := step.namespace
+		if namespace == "" {
+			namespace = ts.kube(ctx).Namespace()
+		}
+		if step.kind != "Namespace" && created[namespace] {
+			step.action = "created"
+			continue
+		}
+		result, err := ts.applyDocument(ctx, step.doc, true, kubernetes.Preconditions{})
+		if err != nil {
+			step.action, step.err = "failed", err.Error()
+			continue
+		}
+		step.action = result.Action
+		if step.kind == "Namespace" && result.Action == "created" {
+			created[step.name] = true
+		}
+	}
+}
+
+// importRank returns the position of kind in importKindOrder.
+func importRank(kind string) int {
+	for i, k := range importKindOrder {
+		if k == kind {
+			return i
+		}
+	}
+	// Unlisted kinds go before the kagent kinds
+	for i, k := range importKindOrder {
+		if k == "ModelConfig" {
+			return i
+		}
+	}
+	return len(importKindOrder)
+}
+
+// formatImport renders an import plan or its result as a table.
+func formatImport(steps []importStep, issues []groupedIssue, dryRun bool, failed int, continueOnError bool) string {
+	var b strings.Builder
+	counts := map[string]int{}
+	for _, step := range steps {
+		counts[step.action]++
+	}
+
+	switch {
+	case dryRun:
+		b.WriteString("# Import Plan\n\n")
+	case failed == 0:
+		b.WriteString("# Import Complete\n\n")
+	default:
+		b.WriteString("# Import Partially Applied\n\n")
+	}
+
+	var summary []string
+	for _, action := range sortedKeys(counts) {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[action], action))
+	}
+	fmt.Fprintf(&b, "%d resource(s), in the order applied: %s.\n\n", len(steps), strings.Join(summary, ", "))
+
+	b.WriteString("| # | Kind | Name | Namespace | Result | Error |\n")
+	b.WriteString("|---|------|------|-----------|--------|-------|\n")
+	for i, step := range steps {
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s | %s |\n",
+			i+1, step.kind, step.name, step.namespace, step.action, strings.ReplaceAll(step.err, "|", "\\|"))
+	}
+
+	if len(issues) > 0 {
+		b.WriteString("\n" + formatValidationReport(issues))
+	}
+
+	switch {
+	case dryRun && counts["failed"] > 0:
+		b.WriteString("\nThe dry run rejected some resources; fix them before importing.")
+	case dryRun:
+		b.WriteString("\nTo import, run import_resources with dry_run=false.")
+	case failed > 0 && !continueOnError:
+		b.WriteString("\nStopped at the first failure. Resources before it were applied; fix the error and import again (applied resources will be unchanged), or pass continue_on_error=true.")
+	case failed > 0:
+		fmt.Fprintf(&b, "\n%d of %d resource(s) failed. Fix the errors and import again.", failed, len(steps))
+	}
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kagent-dev/meta-kagent/internal/jobs"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	mcpserver "github.com/kagent-dev/meta-kagent/internal/server"
+	"github.com/kagent-dev/meta-kagent/internal/tenancy"
+)
+
+// withAsyncOption adds the async argument accepted by tools wrapped with
+// withAsync.
+func withAsyncOption() mcp.ToolOption {
+	return mcp.WithBoolean("async",
+		mcp.Description("Run in the background and return a job ID immediately; poll with get_job_status and fetch the output with get_job_result (default: false)"),
+	)
+}
+
+// withAsync wraps a handler so that calls with async=true are queued as a
+// job. The handler runs with the job's context rather than the request's,
+// which ends when the call returns.
+func (ts *ToolServer) withAsync(operation string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := params.From(req)
+		async := args.Bool("async", false)
+		if err := args.Err(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if !async {
+			return handler(ctx, req)
+		}
+
+		tenant, hasTenant := tenancy.FromContext(ctx)
+		namespace, hasNamespace := mcpserver.NamespaceFromContext(ctx)
+		job := ts.jobs.Submit(operation, sessionOwner(ctx), func(jobCtx context.Context) (string, error) {
+			if hasTenant {
+				jobCtx = tenancy.WithTenant(jobCtx, tenant)
+			}
+			if hasNamespace {
+				jobCtx = mcpserver.WithNamespace(jobCtx, namespace)
+			}
+			result, err := handler(jobCtx, req)
+			if err != nil {
+				return "", err
+			}
+			text := resultText(result)
+			if result.IsError {
+				return "", errors.New(text)
+			}
+			return text, nil
+		})
+
+		return mcp.NewToolResultText(fmt.Sprintf("Queued %s as job '%s'. Poll with get_job_status and fetch the output with get_job_result.", operation, job.ID)), nil
+	}
+}
+
+// getJob returns a job if the session in ctx owns it. Jobs of other tenants
+// are reported as not found.
+func (ts *ToolServer) getJob(ctx context.Context, id string) (jobs.Job, bool) {
+	job, ok := ts.jobs.Get(id)
+	if !ok || job.Owner != sessionOwner(ctx) {
+		return jobs.Job{}, false
+	}
+	return job, true
+}
+
+// resultText joins the text content of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// registerGetJobStatus registers the get_job_status tool.
+func (ts *ToolServer) registerGetJobStatus() {
+	tool := mcp.NewTool("get_job_status",
+		mcp.WithDescription("Get the status of a background job (queued, running, succeeded, failed). Omit job_id to list all recent jobs."),
+		mcp.WithString("job_id",
+			mcp.Description("ID of the job returned by a tool called with async=true"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleGetJobStatus)
+}
+
+func (ts *ToolServer) handleGetJobStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	id := args.String("job_id")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if id == "" {
+		owner := sessionOwner(ctx)
+		var list []jobs.Job
+		for _, job := range ts.jobs.List() {
+			if job.Owner == owner {
+				job.Result = ""
+				list = append(list, job)
+			}
+		}
+		if len(list) == 0 {
+			return mcp.NewToolResultText("No jobs."), nil
+		}
+		output, _ := json.MarshalIndent(list, "", "  ")
+		return mcp.NewToolResultText(string(output)), nil
+	}
+
+	job, ok := ts.getJob(ctx, id)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("job '%s' not found or expired", id)), nil
+	}
+	job.Result = ""
+
+	output, _ := json.MarshalIndent(job, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// registerGetJobResult registers the get_job_result tool.
+func (ts *ToolServer) registerGetJobResult() {
+	tool := mcp.NewTool("get_job_result",
+		mcp.WithDescription("Get the output of a finished background job, exactly as the tool would have returned it when called synchronously."),
+		mcp.WithString("job_id",
+			mcp.Required(),
+			mcp.Description("ID of the job returned by a tool called with async=true"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleGetJobResult)
+}
+
+func (ts *ToolServer) handleGetJobResult(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	id := args.RequiredString("job_id")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	job, ok := ts.getJob(ctx, id)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("job '%s' not found or expired", id)), nil
+	}
+
+	switch job.Status {
+	case jobs.StatusSucceeded:
+		return mcp.NewToolResultText(job.Result), nil
+	case jobs.StatusFailed:
+		return mcp.NewToolResultError(fmt.Sprintf("Job '%s' failed: %s", id, job.Error)), nil
+	default:
+		return mcp.NewToolResultText(fmt.Sprintf("Job '%s' is still %s. Poll get_job_status until it finishes.", id, job.Status)), nil
+	}
+}
@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// toolConfig is one entry of a tools_json argument.
+type toolConfig struct {
+	MCPServer string   `json:"mcpServer"`
+	Kind      string   `json:"kind,omitempty"`
+	Tools     []string `json:"tools,omitempty"`
+}
+
+// Schema summaries included in tool descriptions so callers know exactly
+// what the JSON arguments accept.
+const (
+	toolsJSONSchema  = `JSON array of tool server references. Each item: {"mcpServer": string (required; 'name' or 'namespace/name'), "kind": "MCPServer" | "RemoteMCPServer" | "Service" (default "MCPServer"), "tools": [string] (tool names; omit for all)}. Unknown fields are rejected`
+	skillsJSONSchema = `JSON array of A2A skills. Each item: {"id": string (required), "name": string (required), "description": string (required), "tags": [string], "examples": [string], "inputModes": [string], "outputModes": [string]}. Unknown fields are rejected`
+)
+
+// withPartialOption adds the partial argument for tools taking JSON array
+// inputs.
+func withPartialOption() mcp.ToolOption {
+	return mcp.WithBoolean("partial",
+		mcp.Description("Keep the valid items of the JSON array arguments and skip invalid ones, listing what was skipped (default: false, any invalid item fails the call)"),
+	)
+}
+
+// parseToolConfigs strictly parses a tools_json argument into tool specs.
+func parseToolConfigs(name, raw string, partial bool) ([]types.ToolSpec, []string, error) {
+	configs, skipped, err := decodeJSONArray(name, raw, partial, func(tc toolConfig) error {
+		if tc.MCPServer == "" {
+			return errors.New("mcpServer is required")
+		}
+		if _, err := types.ParseObjectRef(tc.MCPServer, ""); err != nil {
+			return fmt.Errorf("mcpServer: %v", err)
+		}
+		if _, ok := toolServerGVRs[tc.Kind]; tc.Kind != "" && !ok {
+			return fmt.Errorf("kind must be MCPServer, RemoteMCPServer, or Service (got '%s')", tc.Kind)
+		}
+		for _, tool := range tc.Tools {
+			if strings.TrimSpace(tool) == "" {
+				return errors.New("tools must not contain empty names")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	specs := make([]types.ToolSpec, 0, len(configs))
+	for _, tc := range configs {
+		kind := tc.Kind
+		if kind == "" {
+			kind = "MCPServer"
+		}
+		specs = append(specs, types.ToolSpec{
+			Type: "McpServer",
+			McpServer: &types.McpServerRef{
+				Name:      tc.MCPServer,
+				Kind:      kind,
+				ToolNames: tc.Tools,
+			},
+		})
+	}
+	return specs, skipped, nil
+}
+
+// parseSkills strictly parses a skills_json argument.
+func parseSkills(name, raw string, partial bool) ([]types.Skill, []string, error) {
+	return decodeJSONArray(name, raw, partial, func(skill types.Skill) error {
+		var missing []string
+		if skill.ID == "" {
+			missing = append(missing, "id")
+		}
+		if skill.Name == "" {
+			missing = append(missing, "name")
+		}
+		if skill.Description == "" {
+			missing = append(missing, "description")
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing required field(s): %s", strings.Join(missing, ", "))
+		}
+		return nil
+	})
+}
+
+// decodeJSONArray decodes a JSON array argument item by item, rejecting
+// unknown fields and running check on each item. Errors name the argument,
+// the item index, and the line and column in raw. With partial set, invalid
+// items are skipped and described in the returned list instead; malformed
+// JSON always fails.
+func decodeJSONArray[T any](name, raw string, partial bool, check func(T) error) ([]T, []string, error) {
+	dec := json.NewDecoder(strings.NewReader(raw))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, jsonPositionError(name, raw, err, 0)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, nil, fmt.Errorf("%s must be a JSON array (line 1, column 1)", name)
+	}
+
+	var items []T
+	var problems []string
+	for i := 0; dec.More(); i++ {
+		start := skipSeparators(raw, int(dec.InputOffset()))
+
+		var item json.RawMessage
+		if err := dec.Decode(&item); err != nil {
+			return nil, nil, jsonPositionError(name, raw, err, 0)
+		}
+
+		var value T
+		strict := json.NewDecoder(bytes.NewReader(item))
+		strict.DisallowUnknownFields()
+		err := strict.Decode(&value)
+		if err != nil {
+			err = jsonPositionError(fmt.Sprintf("%s[%d]", name, i), raw, err, start)
+		} else if checkErr := check(value); checkErr != nil {
+			line, col := lineColumn(raw, start)
+			err = fmt.Errorf("%s[%d] (line %d, column %d): %v", name, i, line, col, checkErr)
+		}
+
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		items = append(items, value)
+	}
+
+	if _, err := dec.Token(); err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, jsonPositionError(name, raw, err, 0)
+	}
+
+	if len(problems) > 0 && !partial {
+		return nil, nil, fmt.Errorf("invalid %s: %s", name, strings.Join(problems, "; "))
+	}
+	return items, problems, nil
+}
+
+// jsonPositionError describes a JSON decoding error with its position in
+// raw. base is the offset in raw of the text that was decoded.
+func jsonPositionError(name, raw string, err error, base int) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := lineColumn(raw, base+int(syntaxErr.Offset))
+		return fmt.Errorf("%s: invalid JSON at line %d, column %d: %v", name, line, col, syntaxErr)
+	case errors.As(err, &typeErr):
+		line, col := lineColumn(raw, base+int(typeErr.Offset))
+		return fmt.Errorf("%s (line %d, column %d): field '%s' must be %s, got %s", name, line, col, typeErr.Field, typeErr.Type, typeErr.Value)
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		line, col := lineColumn(raw, len(raw))
+		return fmt.Errorf("%s: unexpected end of JSON at line %d, column %d", name, line, col)
+	default:
+		// Unknown field errors carry no offset
+		line, col := lineColumn(raw, base)
+		return fmt.Errorf("%s (line %d, column %d): %s", name, line, col, strings.TrimPrefix(err.Error(), "json: "))
+	}
+}
+
+// lineColumn converts a byte offset in s into a 1-based line and column.
+func lineColumn(s string, offset int) (int, int) {
+	if offset > len(s) {
+		offset = len(s)
+	}
+	line := 1 + strings.Count(s[:offset], "\n")
+	col := offset - strings.LastIndex(s[:offset], "\n")
+	return line, col
+}
+
+// skipSeparators returns the offset of the first byte at or after offset
+// that is not whitespace or a comma.
+func skipSeparators(s string, offset int) int {
+	for offset < len(s) && strings.ContainsRune(" \t\r\n,", rune(s[offset])) {
+		offset++
+	}
+	return offset
+}
+
+// skippedItemsComment lists items dropped by partial parsing as comment
+// lines for a manifest preamble.
+func skippedItemsComment(skipped []string) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n# WARNING: %d invalid item(s) were skipped:", len(skipped)))
+	for _, problem := range skipped {
+		b.WriteString("\n#   - " + problem)
+	}
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// withLabelsOption adds the labels argument to a tool that changes a
+// resource's labels.
+func withLabelsOption() mcp.ToolOption {
+	return mcp.WithString("labels",
+		mcp.Description("Labels to set as comma-separated key=value pairs, e.g. 'team=sre,environment=prod,cost-center=1234'. 'key-' removes a label"),
+	)
+}
+
+// withAnnotationsOption adds the annotations argument to a tool that changes
+// a resource's annotations.
+func withAnnotationsOption() mcp.ToolOption {
+	return mcp.WithString("annotations",
+		mcp.Description("Annotations to set as comma-separated key=value pairs, or an array of strings for values containing commas. 'key-' removes an annotation"),
+	)
+}
+
+// withLabelSelectorOption adds the label_selector argument to a list tool.
+func withLabelSelectorOption() mcp.ToolOption {
+	return mcp.WithString("label_selector",
+		mcp.Description("Only list resources matching this label selector (e.g., 'team=sre,environment in (prod,staging)')"),
+	)
+}
+
+// checkLabelSelector validates a label_selector argument.
+func checkLabelSelector(selector string) error {
+	if _, err := labels.Parse(selector); err != nil {
+		return fmt.Errorf("invalid label_selector '%s': %v", selector, err)
+	}
+	return nil
+}
+
+// withFieldSelectorOption adds the field_selector argument to a list tool.
+func withFieldSelectorOption() mcp.ToolOption {
+	return mcp.WithString("field_selector",
+		mcp.Description("Only list resources matching this field selector; kagent resources support metadata.name and metadata.namespace (e.g., 'metadata.name!=legacy-agent')"),
+	)
+}
+
+// checkFieldSelector validates a field_selector argument.
+func checkFieldSelector(selector string) error {
+	if _, err := fields.ParseSelector(selector); err != nil {
+		return fmt.Errorf("invalid field_selector '%s': %v", selector, err)
+	}
+	return nil
+}
+
+// listOptionsFrom reads the label_selector and field_selector arguments of
+// a list tool and validates the selectors; call it before args.Err.
+func listOptionsFrom(args *params.Args) (kubernetes.ListOptions, error) {
+	opts := kubernetes.ListOptions{
+		LabelSelector: args.String("label_selector"),
+		FieldSelector: args.String("field_selector"),
+	}
+	if err := checkLabelSelector(opts.LabelSelector); err != nil {
+		return opts, err
+	}
+	if err := checkFieldSelector(opts.FieldSelector); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// describeSelectors summarizes the selectors of a filtered list, e.g. " matching
+// 'team=sre'", or "" when nothing is filtered.
+func describeSelectors(opts kubernetes.ListOptions) string {
+	var parts []string
+	for _, s := range []string{opts.LabelSelector, opts.FieldSelector} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" matching '%s'", strings.Join(parts, ","))
+}
+
+// metadataChanges are label or annotation changes: values to set and keys
+// to remove.
+type metadataChanges struct {
+	set    map[string]string
+	remove []string
+}
+
+// parseMetadataChanges parses 'key=value' and 'key-' items of a labels or
+// annotations argument. Keys must be qualified names and label values valid
+// label values.
+func parseMetadataChanges(name string, items []string, labelValues bool) (metadataChanges, error) {
+	changes := metadataChanges{set: map[string]string{}}
+	for _, item := range items {
+		key, value, found := strings.Cut(item, "=")
+		if !found {
+			if !strings.HasSuffix(item, "-") {
+				return changes, fmt.Errorf("%s: '%s' must be key=value, or key- to remove it", name, item)
+			}
+			key = strings.TrimSuffix(item, "-")
+		}
+		key = strings.TrimSpace(key)
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return changes, fmt.Errorf("%s: invalid key '%s': %s", name, key, strings.Join(errs, "; "))
+		}
+		if !found {
+			changes.remove = append(changes.remove, key)
+			continue
+		}
+		if labelValues {
+			value = strings.TrimSpace(value)
+			if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+				return changes, fmt.Errorf("%s: invalid value '%s' for '%s': %s", name, value, key, strings.Join(errs, "; "))
+			}
+		}
+		changes.set[key] = value
+	}
+	return changes, nil
+}
+
+// isZero reports whether there is nothing to change.
+func (c metadataChanges) isZero() bool {
+	return len(c.set) == 0 && len(c.remove) == 0
+}
+
+// apply returns current with the changes made, or nil when nothing is left.
+func (c metadataChanges) apply(current map[string]string) map[string]string {
+	result := make(map[string]string, len(current)+len(c.set))
+	for k, v := range current {
+		result[k] = v
+	}
+	for _, k := range c.remove {
+		delete(result, k)
+	}
+	for k, v := range c.set {
+		result[k] = v
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// describe summarizes the changes, e.g. "team=sre, -stale".
+func (c metadataChanges) describe() string {
+	var parts []string
+	for _, k := range sortedKeys(c.set) {
+		parts = append(parts, k+"="+c.set[k])
+	}
+	removed := append([]string(nil), c.remove...)
+	sort.Strings(removed)
+	for _, k := range removed {
+		parts = append(parts, "-"+k)
+	}
+	return strings.Join(parts, ", ")
+}
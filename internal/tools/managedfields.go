@@ -0,0 +1,345 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kagent-dev/meta-kagent/internal/diff"
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// Field manager categories.
+const (
+	managerMetaAgent  = "meta-agent"
+	managerKubectl    = "kubectl"
+	managerGitOps     = "gitops"
+	managerHelm       = "helm"
+	managerController = "controller"
+	managerOther      = "other"
+)
+
+// FieldManagerInfo describes one field manager of a resource.
+type FieldManagerInfo struct {
+	Manager     string `json:"manager"`
+	Category    string `json:"category"`
+	Operation   string `json:"operation"`
+	Subresource string `json:"subresource,omitempty"`
+	Time        string `json:"time,omitempty"`
+	Fields      int    `json:"fields"`
+}
+
+// FieldOwnership lists the managers owning a field path.
+type FieldOwnership struct {
+	Path     string   `json:"path"`
+	Managers []string `json:"managers"`
+}
+
+// registerWhoManagesField registers the who_manages_field tool.
+func (ts *ToolServer) registerWhoManagesField() {
+	tool := mcp.NewTool("who_manages_field",
+		mcp.WithDescription("Report which field managers (the kagent controller, kubectl, this meta-agent, GitOps tools such as Argo CD or Flux) own each spec path of a resource, from its managedFields. Helps diagnose why applied changes keep getting reverted."),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Resource kind (e.g., 'Agent', 'ModelConfig', 'MCPServer')"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the resource"),
+		),
+		mcp.WithString("api_version",
+			mcp.Description("apiVersion of the kind (e.g., 'kagent.dev/v1alpha2'). If omitted, the kind is looked up by name"),
+		),
+		mcp.WithString("path",
+			mcp.Description("Only report fields at or below this dotted path, in full detail (e.g., 'spec.declarative.tools'). Default: each top-level spec path"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleWhoManagesField)
+}
+
+func (ts *ToolServer) handleWhoManagesField(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.RequiredString("kind")
+	name := args.RequiredString("name")
+	apiVersion := args.String("api_version")
+	path := strings.Trim(args.String("path"), ".")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	entries, err := ts.kube(ctx).GetManagedFields(ctx, apiVersion, kind, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get %s '%s': %v", kind, name, err)), nil
+	}
+	if len(entries) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("%s '%s' has no managedFields; the API server records them for every write, so it has not been written since field tracking was enabled.", kind, name)), nil
+	}
+
+	managers, ownership := fieldOwnership(entries, path)
+	if len(ownership) == 0 {
+		scope := "spec"
+		if path != "" {
+			scope = path
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("No manager owns fields under '%s' of %s '%s'.", scope, kind, name)), nil
+	}
+
+	categories := map[string]string{}
+	for _, m := range managers {
+		categories[m.Manager] = m.Category
+	}
+	findings := ownershipFindings(ownership, categories)
+
+	result := map[string]interface{}{
+		"resource": kind + "/" + name,
+		"managers": managers,
+		"fields":   ownership,
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+
+	summary := "✓ Every field has a single owner."
+	if len(findings) > 0 {
+		summary = "⚠️ " + strings.Join(findings, "\n⚠️ ")
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("# Field Managers of %s '%s'\n\n%s\n\n%s", kind, name, summary, string(output))), nil
+}
+
+// fieldOwnership returns the managers of a resource and the managers owning
+// each field path. Without a path, paths are reported at the first level
+// below spec; with one, every field at or below it is reported. Status
+// subresource entries are left out, since they never conflict with spec.
+func fieldOwnership(entries []metav1.ManagedFieldsEntry, path string) ([]FieldManagerInfo, []FieldOwnership) {
+	owners := map[string]map[string]bool{}
+	var managers []FieldManagerInfo
+	for _, entry := range entries {
+		if entry.Subresource == "status" || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+
+		info := FieldManagerInfo{
+			Manager:     entry.Manager,
+			Category:    managerCategory(entry.Manager),
+			Operation:   string(entry.Operation),
+			Subresource: entry.Subresource,
+		}
+		if entry.Time != nil {
+			info.Time = entry.Time.UTC().Format(time.RFC3339)
+		}
+
+		for _, leaf := range fieldPaths(fields, nil) {
+			reported, ok := reportedPath(leaf, path)
+			if !ok {
+				continue
+			}
+			info.Fields++
+			if owners[reported] == nil {
+				owners[reported] = map[string]bool{}
+			}
+			owners[reported][entry.Manager] = true
+		}
+		managers = append(managers, info)
+	}
+
+	ownership := make([]FieldOwnership, 0, len(owners))
+	for p, set := range owners {
+		o := FieldOwnership{Path: p}
+		for manager := range set {
+			o.Managers = append(o.Managers, manager)
+		}
+		sort.Strings(o.Managers)
+		ownership = append(ownership, o)
+	}
+	sort.Slice(ownership, func(i, j int) bool { return ownership[i].Path < ownership[j].Path })
+	return managers, ownership
+}
+
+// reportedPath maps a field path to the path it is reported under, or
+// returns false when it is outside the requested scope.
+func reportedPath(segments []string, path string) (string, bool) {
+	full := joinFieldPath(segments)
+	if path != "" {
+		if full != path && !strings.HasPrefix(full, path+".") && !strings.HasPrefix(full, path+"[") {
+			return "", false
+		}
+		return full, true
+	}
+	if len(segments) < 2 || segments[0] != "spec" {
+		return "", false
+	}
+	return joinFieldPath(segments[:2]), true
+}
+
+// fieldPaths flattens a FieldsV1 set into the paths of its leaves. Field
+// keys ("f:name") become names, and list item keys ("k:{...}", "v:...",
+// "i:n") become bracketed selectors.
+func fieldPaths(fields map[string]interface{}, prefix []string) [][]string {
+	var paths [][]string
+	for key, value := range fields {
+		if key == "." {
+			continue
+		}
+		segment := fieldSegment(key)
+		path := append(append([]string(nil), prefix...), segment)
+		children, _ := value.(map[string]interface{})
+		if sub := fieldPaths(children, path); len(sub) > 0 {
+			paths = append(paths, sub...)
+		} else {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// fieldSegment converts a FieldsV1 key to a path segment.
+func fieldSegment(key string) string {
+	switch {
+	case strings.HasPrefix(key, "f:"):
+		return strings.TrimPrefix(key, "f:")
+	case strings.HasPrefix(key, "k:"):
+		var item map[string]interface{}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "k:")), &item); err != nil {
+			return "[" + strings.TrimPrefix(key, "k:") + "]"
+		}
+		var parts []string
+		for k, v := range item {
+			parts = append(parts, fmt.Sprintf("%s=%v", k, v))
+		}
+		sort.Strings(parts)
+		return "[" + strings.Join(parts, ",") + "]"
+	case strings.HasPrefix(key, "v:"):
+		return "[=" + strings.TrimPrefix(key, "v:") + "]"
+	case strings.HasPrefix(key, "i:"):
+		return "[" + strings.TrimPrefix(key, "i:") + "]"
+	}
+	return key
+}
+
+// joinFieldPath joins segments with dots, attaching list selectors to the
+// preceding field.
+func joinFieldPath(segments []string) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		if i > 0 && !strings.HasPrefix(segment, "[") {
+			b.WriteString(".")
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+// managerCategory classifies a field manager by its well-known name.
+func managerCategory(manager string) string {
+	m := strings.ToLower(manager)
+	switch {
+	case manager == kubernetes.FieldManager:
+		return managerMetaAgent
+	case strings.HasPrefix(m, "kubectl"):
+		return managerKubectl
+	case strings.Contains(m, "argocd"), strings.Contains(m, "kustomize-controller"), strings.Contains(m, "flux"), strings.Contains(m, "gitops"):
+		return managerGitOps
+	case strings.Contains(m, "helm"):
+		return managerHelm
+	case strings.Contains(m, "kagent"), strings.Contains(m, "controller"), strings.Contains(m, "manager"), strings.Contains(m, "operator"):
+		return managerController
+	}
+	return managerOther
+}
+
+// ownershipFindings explains paths owned by more than one manager, calling
+// out the combinations that revert changes.
+func ownershipFindings(ownership []FieldOwnership, categories map[string]string) []string {
+	var findings []string
+	for _, o := range ownership {
+		if len(o.Managers) < 2 {
+			continue
+		}
+
+		var gitops, controllers []string
+		for _, manager := range o.Managers {
+			switch categories[manager] {
+			case managerGitOps, managerHelm:
+				gitops = append(gitops, manager)
+			case managerController:
+				controllers = append(controllers, manager)
+			}
+		}
+
+		switch {
+		case len(gitops) > 0:
+			findings = append(findings, fmt.Sprintf("%s is owned by %s and also by %s. Changes made outside Git will be reverted on the next sync; change the source in Git instead.",
+				o.Path, strings.Join(gitops, ", "), strings.Join(without(o.Managers, gitops), ", ")))
+		case len(controllers) > 0:
+			findings = append(findings, fmt.Sprintf("%s is also written by %s, which may overwrite changes to it when it reconciles.",
+				o.Path, strings.Join(controllers, ", ")))
+		default:
+			findings = append(findings, fmt.Sprintf("%s is shared by %s; the last writer wins.", o.Path, strings.Join(o.Managers, ", ")))
+		}
+	}
+	return findings
+}
+
+// without returns the items of all that are not in remove.
+func without(all, remove []string) []string {
+	var kept []string
+	for _, item := range all {
+		if !containsString(remove, item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// foreignRemovals lists the fields an update from before to after removes
+// that a manager other than the server owns, with their owners, e.g.
+// "metadata.annotations.owner (kubectl-client-side-apply)". Removals of
+// list items are left out, as managedFields key them by value rather than
+// position.
+func foreignRemovals(entries []metav1.ManagedFieldsEntry, before, after map[string]interface{}) []string {
+	owners := map[string][]string{}
+	for _, entry := range entries {
+		if entry.Subresource == "status" || entry.FieldsV1 == nil || entry.Manager == kubernetes.FieldManager {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		for _, leaf := range fieldPaths(fields, nil) {
+			for i := 1; i <= len(leaf); i++ {
+				p := joinFieldPath(leaf[:i])
+				if !containsString(owners[p], entry.Manager) {
+					owners[p] = append(owners[p], entry.Manager)
+				}
+			}
+		}
+	}
+
+	var removed []string
+	for _, op := range diff.Patch(before, after) {
+		if op.Op != "remove" {
+			continue
+		}
+		var segments []string
+		for _, s := range strings.Split(strings.TrimPrefix(op.Path, "/"), "/") {
+			segments = append(segments, strings.NewReplacer("~1", "/", "~0", "~").Replace(s))
+		}
+		path := joinFieldPath(segments)
+		if managers := owners[path]; len(managers) > 0 {
+			sort.Strings(managers)
+			removed = append(removed, fmt.Sprintf("%s (%s)", path, strings.Join(managers, ", ")))
+		}
+	}
+	return removed
+}
@@ -2,57 +2,139 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 
-	"github.com/google/go-cmp/cmp"
 	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/diff"
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/sampling"
+	"github.com/kagent-dev/meta-kagent/internal/signing"
+	ruleset "github.com/kagent-dev/meta-kagent/internal/validation"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
 )
 
 // registerValidateManifest registers the validate_manifest tool.
 func (ts *ToolServer) registerValidateManifest() {
 	tool := mcp.NewTool("validate_manifest",
-		mcp.WithDescription("Validate a kagent manifest for correctness and completeness. Checks required fields, references, and best practices."),
+		mcp.WithDescription("Validate a kagent manifest or multi-document bundle for correctness and completeness. Checks required fields, references, and best practices. Issues repeated across a bundle are reported once with a count, and include a suggested JSON patch fix where one can be derived."),
 		mcp.WithString("manifest",
 			mcp.Required(),
-			mcp.Description("YAML manifest to validate"),
+			mcp.Description("YAML manifest to validate (multiple documents separated by ---)"),
 		),
 		mcp.WithBoolean("strict",
 			mcp.Description("Enable strict validation including best practice checks (default: true)"),
 		),
+		mcp.WithBoolean("check_tool_names",
+			mcp.Description("Connect to each MCP server an agent references and check that its toolNames exist on it (default: false)"),
+		),
+		mcp.WithBoolean("server_dry_run",
+			mcp.Description("Also send each document to the API server as a dry-run create or update, so the CRD schema and admission webhooks check it, and report what they reject (default: false). Skipped outside apply mode, where the server has no write access"),
+		),
+		withRuleOptions(),
+		withStructuredOutputOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleValidateManifest)
+	ts.addTool(tool, ts.handleValidateManifest)
 }
 
 func (ts *ToolServer) handleValidateManifest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	manifest, _ := req.Params.Arguments["manifest"].(string)
-	if manifest == "" {
-		return mcp.NewToolResultError("manifest is required"), nil
+	args := params.From(req)
+	manifest := args.RequiredString("manifest")
+	strict := args.Bool("strict", true)
+	checkToolNames := args.Bool("check_tool_names", false)
+	serverDryRun := args.Bool("server_dry_run", false)
+	asStructured := structuredOutputFrom(args)
+	settings, err := ts.ruleSettingsFrom(args)
+	if err == nil {
+		err = args.Err()
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	ctx = withRuleSettings(ctx, settings)
 
-	strict := true
-	if v, ok := req.Params.Arguments["strict"].(bool); ok {
-		strict = v
+	docs := kubernetes.SplitManifests(manifest)
+	if len(docs) == 0 {
+		return mcp.NewToolResultError("manifest is empty"), nil
 	}
 
-	// Parse manifest
-	var obj unstructured.Unstructured
-	if err := yaml.Unmarshal([]byte(manifest), &obj.Object); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse manifest: %v", err)), nil
+	// Validate every document in the bundle, then collapse repeated issues
+	issues, err := ts.validateBundle(ctx, docs, strict, checkToolNames)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if serverDryRun {
+		issues = append(issues, ts.serverDryRunIssues(ctx, docs)...)
+	}
+
+	if asStructured {
+		grouped := dedupeIssues(issues)
+		errs, warnings := issueMessages(grouped)
+		valid := len(errs) == 0
+		summary := fmt.Sprintf("Validation passed for %d document(s).", len(docs))
+		switch {
+		case !valid:
+			summary = fmt.Sprintf("Validation found %d error(s) and %d warning(s); resolve the errors before applying.", len(errs), len(warnings))
+		case len(warnings) > 0:
+			summary = fmt.Sprintf("Validation found %d warning(s); the manifest can be applied.", len(warnings))
+		}
+		return structured(structuredResult{Summary: summary, Valid: &valid, Errors: errs, Warnings: warnings, Issues: grouped})
+	}
+
+	if len(issues) == 0 {
+		if len(docs) > 1 {
+			return mcp.NewToolResultText(fmt.Sprintf("✓ Validation passed. All %d manifests are valid and ready to apply.", len(docs))), nil
+		}
+		return mcp.NewToolResultText("✓ Validation passed. Manifest is valid and ready to apply."), nil
 	}
 
+	return mcp.NewToolResultText(formatValidationReport(dedupeIssues(issues))), nil
+}
+
+// validateObject runs the validation rules that apply to one manifest,
+// with the severities the server's and the call's rule settings give them.
+func (ts *ToolServer) validateObject(ctx context.Context, obj *unstructured.Unstructured, strict, checkToolNames bool) []ValidationIssue {
+	issues := ts.rules.Validate(ctx, obj, ruleset.Options{Strict: strict, CheckToolNames: checkToolNames}, ts.ruleSettings(ctx))
+
+	// Structure against the CRD schema, where the other rules say nothing
+	var reported, schema []ValidationIssue
+	for _, issue := range issues {
+		if issue.Rule == ruleSchema {
+			schema = append(schema, issue)
+		} else {
+			reported = append(reported, issue)
+		}
+	}
+	return append(reported, withoutReported(reported, schema)...)
+}
+
+// ValidationIssue represents a validation error or warning.
+type ValidationIssue = ruleset.Issue
+
+// checkRequiredFields checks the fields every manifest needs.
+func checkRequiredFields(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
 	var issues []ValidationIssue
 
-	// Basic validation
 	if obj.GetAPIVersion() == "" {
-		issues = append(issues, ValidationIssue{
+		issue := ValidationIssue{
 			Severity: "error",
 			Field:    "apiVersion",
 			Message:  "apiVersion is required",
-		})
+		}
+		if apiVersion, ok := kagentAPIVersions[obj.GetKind()]; ok {
+			issue.Fix = []PatchOperation{{Op: "add", Path: "/apiVersion", Value: apiVersion}}
+		}
+		issues = append(issues, issue)
 	}
 
 	if obj.GetKind() == "" {
@@ -71,132 +153,128 @@ func (ts *ToolServer) handleValidateManifest(ctx context.Context, req mcp.CallTo
 		})
 	}
 
-	// Kind-specific validation
-	switch obj.GetKind() {
-	case "Agent":
-		issues = append(issues, ts.validateAgent(ctx, &obj, strict)...)
-	case "ModelConfig":
-		issues = append(issues, ts.validateModelConfig(ctx, &obj, strict)...)
-	case "MCPServer":
-		issues = append(issues, ts.validateMCPServer(ctx, &obj, strict)...)
-	case "RemoteMCPServer":
-		issues = append(issues, ts.validateRemoteMCPServer(ctx, &obj, strict)...)
-	default:
-		issues = append(issues, ValidationIssue{
-			Severity: "warning",
-			Field:    "kind",
-			Message:  fmt.Sprintf("Unknown kind '%s'. Expected: Agent, ModelConfig, MCPServer, or RemoteMCPServer", obj.GetKind()),
-		})
-	}
+	return issues
+}
 
-	// Format result
-	if len(issues) == 0 {
-		return mcp.NewToolResultText("✓ Validation passed. Manifest is valid and ready to apply."), nil
+// checkKnownKind warns about kinds validate_manifest has no rules for.
+func checkKnownKind(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+	kind := obj.GetKind()
+	if _, ok := kagentAPIVersions[kind]; ok || kind == "Namespace" {
+		return nil
 	}
-
-	var result strings.Builder
-	result.WriteString("Validation Results:\n\n")
-
-	hasErrors := false
-	for _, issue := range issues {
-		prefix := "⚠️  WARNING"
-		if issue.Severity == "error" {
-			prefix = "❌ ERROR"
-			hasErrors = true
+	issue := ValidationIssue{
+		Severity: "warning",
+		Field:    "kind",
+		Message:  fmt.Sprintf("Unknown kind '%s'. Expected: Agent, ModelConfig, MCPServer, or RemoteMCPServer", kind),
+	}
+	for known := range kagentAPIVersions {
+		if strings.EqualFold(known, kind) {
+			issue.Fix = []PatchOperation{{Op: "replace", Path: "/kind", Value: known}}
 		}
-		result.WriteString(fmt.Sprintf("%s [%s]: %s\n", prefix, issue.Field, issue.Message))
 	}
+	return []ValidationIssue{issue}
+}
 
-	result.WriteString("\n")
-	if hasErrors {
-		result.WriteString("❌ Manifest has errors and should not be applied until they are resolved.")
-	} else {
-		result.WriteString("⚠️  Manifest has warnings but can be applied. Consider addressing warnings for best practices.")
+// checkAgentType checks that an agent says whether it is Declarative or
+// BYO.
+func checkAgentType(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+	specType, found, _ := unstructured.NestedString(obj.Object, "spec", "type")
+	if found && specType != "" {
+		return nil
 	}
-
-	return mcp.NewToolResultText(result.String()), nil
+	suggested := "BYO"
+	if _, ok, _ := unstructured.NestedMap(obj.Object, "spec", "declarative"); ok {
+		suggested = "Declarative"
+	}
+	return []ValidationIssue{{
+		Severity: "error",
+		Field:    "spec.type",
+		Message:  "spec.type is required (should be 'Declarative' or 'BYO')",
+		Fix:      []PatchOperation{{Op: "add", Path: "/spec/type", Value: suggested}},
+	}}
 }
 
-// ValidationIssue represents a validation error or warning.
-type ValidationIssue struct {
-	Severity string `json:"severity"` // "error" or "warning"
-	Field    string `json:"field"`
-	Message  string `json:"message"`
+// isDeclarative reports whether obj is a Declarative agent.
+func isDeclarative(obj *unstructured.Unstructured) bool {
+	specType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+	return specType == "Declarative"
 }
 
-func (ts *ToolServer) validateAgent(ctx context.Context, obj *unstructured.Unstructured, strict bool) []ValidationIssue {
-	var issues []ValidationIssue
-
-	// Check spec.type
-	specType, found, _ := unstructured.NestedString(obj.Object, "spec", "type")
-	if !found || specType == "" {
-		issues = append(issues, ValidationIssue{
+// checkAgentModelConfig checks that a Declarative agent references a
+// ModelConfig that exists, possibly in another namespace.
+func (ts *ToolServer) checkAgentModelConfig(ctx context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+	if !isDeclarative(obj) {
+		return nil
+	}
+	modelConfig, found, _ := unstructured.NestedString(obj.Object, "spec", "declarative", "modelConfig")
+	if !found || modelConfig == "" {
+		return []ValidationIssue{{
 			Severity: "error",
-			Field:    "spec.type",
-			Message:  "spec.type is required (should be 'Declarative' or 'BYO')",
-		})
+			Field:    "spec.declarative.modelConfig",
+			Message:  "spec.declarative.modelConfig is required for Declarative agents",
+		}}
 	}
+	return ts.checkReference(ctx, "spec.declarative.modelConfig", "ModelConfig", kubernetes.ModelConfigGVR, modelConfig, obj.GetNamespace())
+}
 
-	if specType == "Declarative" {
-		// Check modelConfig reference
-		modelConfig, found, _ := unstructured.NestedString(obj.Object, "spec", "declarative", "modelConfig")
-		if !found || modelConfig == "" {
-			issues = append(issues, ValidationIssue{
-				Severity: "error",
-				Field:    "spec.declarative.modelConfig",
-				Message:  "spec.declarative.modelConfig is required for Declarative agents",
-			})
-		} else {
-			// Verify ModelConfig exists
-			_, err := ts.k8sClient.GetModelConfig(ctx, modelConfig)
-			if err != nil {
-				issues = append(issues, ValidationIssue{
-					Severity: "warning",
-					Field:    "spec.declarative.modelConfig",
-					Message:  fmt.Sprintf("ModelConfig '%s' not found in namespace. Ensure it exists before applying.", modelConfig),
-				})
-			}
-		}
-
-		// Check systemMessage
-		systemMessage, found, _ := unstructured.NestedString(obj.Object, "spec", "declarative", "systemMessage")
-		if !found || systemMessage == "" {
-			issues = append(issues, ValidationIssue{
-				Severity: "error",
-				Field:    "spec.declarative.systemMessage",
-				Message:  "spec.declarative.systemMessage is required for Declarative agents",
-			})
-		} else if strict && len(systemMessage) < 100 {
-			issues = append(issues, ValidationIssue{
-				Severity: "warning",
-				Field:    "spec.declarative.systemMessage",
-				Message:  "System message seems short. Consider providing more detailed instructions for the agent.",
-			})
-		}
+// checkAgentTools checks the tool servers and tools of a Declarative
+// agent.
+func (ts *ToolServer) checkAgentTools(ctx context.Context, obj *unstructured.Unstructured, opts ruleset.Options) []ValidationIssue {
+	if !isDeclarative(obj) {
+		return nil
 	}
+	return ts.checkToolReferences(ctx, obj, opts.CheckToolNames)
+}
 
-	// Check description
-	if strict {
-		description, _, _ := unstructured.NestedString(obj.Object, "spec", "description")
-		if description == "" {
-			issues = append(issues, ValidationIssue{
-				Severity: "warning",
-				Field:    "spec.description",
-				Message:  "Consider adding a description to help users understand the agent's purpose",
-			})
-		}
+// checkSystemMessage checks that a Declarative agent has a system message
+// and, in strict mode, that it is not a one-liner.
+func checkSystemMessage(_ context.Context, obj *unstructured.Unstructured, opts ruleset.Options) []ValidationIssue {
+	if !isDeclarative(obj) {
+		return nil
+	}
+	systemMessage, found, _ := unstructured.NestedString(obj.Object, "spec", "declarative", "systemMessage")
+	if !found || systemMessage == "" {
+		return []ValidationIssue{{
+			Severity: "error",
+			Field:    "spec.declarative.systemMessage",
+			Message:  "spec.declarative.systemMessage is required for Declarative agents",
+		}}
+	}
+	if opts.Strict && len(systemMessage) < 100 {
+		return []ValidationIssue{{
+			Severity: "warning",
+			Field:    "spec.declarative.systemMessage",
+			Message:  "System message seems short. Consider providing more detailed instructions for the agent.",
+		}}
 	}
+	return nil
+}
 
-	// Validate A2A config if present
-	a2aConfig, found, _ := unstructured.NestedMap(obj.Object, "spec", "a2aConfig")
-	if found && a2aConfig != nil {
-		issues = append(issues, ts.validateA2AConfig(ctx, a2aConfig, strict)...)
+// checkAgentDescription recommends a description in strict mode.
+func checkAgentDescription(_ context.Context, obj *unstructured.Unstructured, opts ruleset.Options) []ValidationIssue {
+	if !opts.Strict {
+		return nil
+	}
+	if description, _, _ := unstructured.NestedString(obj.Object, "spec", "description"); description != "" {
+		return nil
 	}
+	return []ValidationIssue{{
+		Severity: "warning",
+		Field:    "spec.description",
+		Message:  "Consider adding a description to help users understand the agent's purpose",
+	}}
+}
 
-	return issues
+// checkA2ASkills checks the skills of an agent's A2A config, if any.
+func checkA2ASkills(_ context.Context, obj *unstructured.Unstructured, opts ruleset.Options) []ValidationIssue {
+	config, found, _ := unstructured.NestedMap(obj.Object, "spec", "a2aConfig")
+	if !found || config == nil {
+		return nil
+	}
+	return validateA2AConfig(config, opts.Strict)
 }
 
-func (ts *ToolServer) validateA2AConfig(ctx context.Context, config map[string]interface{}, strict bool) []ValidationIssue {
+func validateA2AConfig(config map[string]interface{}, strict bool) []ValidationIssue {
 	var issues []ValidationIssue
 
 	skills, found, _ := unstructured.NestedSlice(config, "skills")
@@ -228,6 +306,7 @@ func (ts *ToolServer) validateA2AConfig(ctx context.Context, config map[string]i
 					Severity: "error",
 					Field:    fmt.Sprintf("spec.a2aConfig.skills[%d].id", i),
 					Message:  fmt.Sprintf("duplicate skill id '%s'", id),
+					Fix:      []PatchOperation{{Op: "replace", Path: fmt.Sprintf("/spec/a2aConfig/skills/%d/id", i), Value: fmt.Sprintf("%s-%d", id, i)}},
 				})
 			}
 			seenIDs[id] = true
@@ -236,11 +315,15 @@ func (ts *ToolServer) validateA2AConfig(ctx context.Context, config map[string]i
 		// Validate skill name
 		name, _, _ := unstructured.NestedString(skillMap, "name")
 		if name == "" {
-			issues = append(issues, ValidationIssue{
+			issue := ValidationIssue{
 				Severity: "error",
 				Field:    fmt.Sprintf("spec.a2aConfig.skills[%d].name", i),
 				Message:  "skill name is required",
-			})
+			}
+			if id != "" {
+				issue.Fix = []PatchOperation{{Op: "add", Path: fmt.Sprintf("/spec/a2aConfig/skills/%d/name", i), Value: id}}
+			}
+			issues = append(issues, issue)
 		}
 
 		// Validate skill description
@@ -284,70 +367,81 @@ func (ts *ToolServer) validateA2AConfig(ctx context.Context, config map[string]i
 	return issues
 }
 
-func (ts *ToolServer) validateModelConfig(ctx context.Context, obj *unstructured.Unstructured, strict bool) []ValidationIssue {
-	var issues []ValidationIssue
+// validProviders are the ModelConfig providers kagent supports.
+var validProviders = map[string]bool{
+	"OpenAI": true, "AzureOpenAI": true, "Anthropic": true,
+	"Gemini": true, "Ollama": true, "Custom": true,
+}
 
-	// Check provider
+// checkModelProvider checks that a ModelConfig names a supported provider.
+func checkModelProvider(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
 	provider, found, _ := unstructured.NestedString(obj.Object, "spec", "provider")
 	if !found || provider == "" {
-		issues = append(issues, ValidationIssue{
+		return []ValidationIssue{{
 			Severity: "error",
 			Field:    "spec.provider",
 			Message:  "spec.provider is required",
-		})
-	} else {
-		validProviders := map[string]bool{
-			"OpenAI": true, "AzureOpenAI": true, "Anthropic": true,
-			"Gemini": true, "Ollama": true, "Custom": true,
-		}
-		if !validProviders[provider] {
-			issues = append(issues, ValidationIssue{
-				Severity: "error",
-				Field:    "spec.provider",
-				Message:  fmt.Sprintf("Invalid provider '%s'. Must be one of: OpenAI, AzureOpenAI, Anthropic, Gemini, Ollama, Custom", provider),
-			})
+		}}
+	}
+	if validProviders[provider] {
+		return nil
+	}
+	issue := ValidationIssue{
+		Severity: "error",
+		Field:    "spec.provider",
+		Message:  fmt.Sprintf("Invalid provider '%s'. Must be one of: OpenAI, AzureOpenAI, Anthropic, Gemini, Ollama, Custom", provider),
+	}
+	for valid := range validProviders {
+		if strings.EqualFold(valid, provider) {
+			issue.Fix = []PatchOperation{{Op: "replace", Path: "/spec/provider", Value: valid}}
 		}
 	}
+	return []ValidationIssue{issue}
+}
 
-	// Check model
-	model, found, _ := unstructured.NestedString(obj.Object, "spec", "model")
-	if !found || model == "" {
-		issues = append(issues, ValidationIssue{
-			Severity: "error",
-			Field:    "spec.model",
-			Message:  "spec.model is required",
-		})
+// checkModel checks that a ModelConfig names its model.
+func checkModel(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+	if model, found, _ := unstructured.NestedString(obj.Object, "spec", "model"); found && model != "" {
+		return nil
 	}
+	return []ValidationIssue{{
+		Severity: "error",
+		Field:    "spec.model",
+		Message:  "spec.model is required",
+	}}
+}
 
-	// Check apiKeySecret
+// checkAPIKeySecretSet checks that a ModelConfig names its API key Secret,
+// which every provider but Ollama needs.
+func checkAPIKeySecretSet(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+	provider, _, _ := unstructured.NestedString(obj.Object, "spec", "provider")
 	apiKeySecret, found, _ := unstructured.NestedString(obj.Object, "spec", "apiKeySecret")
-	if !found || apiKeySecret == "" {
-		// Only required for non-Ollama providers
-		if provider != "Ollama" {
-			issues = append(issues, ValidationIssue{
-				Severity: "error",
-				Field:    "spec.apiKeySecret",
-				Message:  "spec.apiKeySecret is required for non-Ollama providers",
-			})
-		}
+	if (found && apiKeySecret != "") || provider == "Ollama" {
+		return nil
 	}
+	return []ValidationIssue{{
+		Severity: "error",
+		Field:    "spec.apiKeySecret",
+		Message:  "spec.apiKeySecret is required for non-Ollama providers",
+	}}
+}
 
-	return issues
+// checkMCPServerImage checks that an MCPServer has an image to run.
+func checkMCPServerImage(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+	if image, found, _ := unstructured.NestedString(obj.Object, "spec", "deployment", "image"); found && image != "" {
+		return nil
+	}
+	return []ValidationIssue{{
+		Severity: "error",
+		Field:    "spec.deployment.image",
+		Message:  "spec.deployment.image is required for MCPServer",
+	}}
 }
 
-func (ts *ToolServer) validateMCPServer(ctx context.Context, obj *unstructured.Unstructured, strict bool) []ValidationIssue {
+// checkMCPServerTransport checks an MCPServer's stdio transport settings.
+func checkMCPServerTransport(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
 	var issues []ValidationIssue
 
-	// Check deployment
-	image, found, _ := unstructured.NestedString(obj.Object, "spec", "deployment", "image")
-	if !found || image == "" {
-		issues = append(issues, ValidationIssue{
-			Severity: "error",
-			Field:    "spec.deployment.image",
-			Message:  "spec.deployment.image is required for MCPServer",
-		})
-	}
-
 	// Check transportType
 	transportType, _, _ := unstructured.NestedString(obj.Object, "spec", "transportType")
 	if transportType != "" && transportType != "stdio" {
@@ -355,61 +449,113 @@ func (ts *ToolServer) validateMCPServer(ctx context.Context, obj *unstructured.U
 			Severity: "warning",
 			Field:    "spec.transportType",
 			Message:  fmt.Sprintf("Unexpected transportType '%s'. MCPServer typically uses 'stdio'", transportType),
+			Fix:      []PatchOperation{{Op: "replace", Path: "/spec/transportType", Value: "stdio"}},
+		})
+	}
+
+	// Check stdioTransport, which the CRD defines as an empty object
+	if raw, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "stdioTransport"); found {
+		if err := decodeStrict(raw, &types.StdioTransport{}); err != nil {
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    "spec.stdioTransport",
+				Message:  fmt.Sprintf("Invalid stdioTransport: %s; it takes no settings", strings.TrimPrefix(err.Error(), "json: ")),
+				Fix:      []PatchOperation{{Op: "replace", Path: "/spec/stdioTransport", Value: map[string]interface{}{}}},
+			})
+		}
+	} else if transportType == "stdio" {
+		issues = append(issues, ValidationIssue{
+			Severity: "warning",
+			Field:    "spec.stdioTransport",
+			Message:  "spec.stdioTransport should be set (to {}) when transportType is stdio",
+			Fix:      []PatchOperation{{Op: "add", Path: "/spec/stdioTransport", Value: map[string]interface{}{}}},
 		})
 	}
 
 	return issues
 }
 
-func (ts *ToolServer) validateRemoteMCPServer(ctx context.Context, obj *unstructured.Unstructured, strict bool) []ValidationIssue {
-	var issues []ValidationIssue
-
-	// Check URL
+// checkRemoteURL checks that a RemoteMCPServer has an http(s) URL.
+func checkRemoteURL(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
 	url, found, _ := unstructured.NestedString(obj.Object, "spec", "url")
 	if !found || url == "" {
-		issues = append(issues, ValidationIssue{
+		return []ValidationIssue{{
 			Severity: "error",
 			Field:    "spec.url",
 			Message:  "spec.url is required for RemoteMCPServer",
-		})
-	} else if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-		issues = append(issues, ValidationIssue{
+		}}
+	}
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return []ValidationIssue{{
 			Severity: "error",
 			Field:    "spec.url",
 			Message:  "spec.url must start with http:// or https://",
-		})
+			Fix:      []PatchOperation{{Op: "replace", Path: "/spec/url", Value: "https://" + strings.TrimPrefix(url, "//")}},
+		}}
 	}
+	return nil
+}
 
-	// Check protocol
+// checkRemoteProtocol checks a RemoteMCPServer's protocol.
+func checkRemoteProtocol(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
 	protocol, _, _ := unstructured.NestedString(obj.Object, "spec", "protocol")
-	if protocol != "" && protocol != "STREAMABLE_HTTP" && protocol != "SSE" {
-		issues = append(issues, ValidationIssue{
-			Severity: "error",
-			Field:    "spec.protocol",
-			Message:  "spec.protocol must be 'STREAMABLE_HTTP' or 'SSE'",
-		})
+	if protocol == "" || protocol == "STREAMABLE_HTTP" || protocol == "SSE" {
+		return nil
 	}
-
-	return issues
+	suggested := "STREAMABLE_HTTP"
+	if strings.EqualFold(protocol, "SSE") {
+		suggested = "SSE"
+	}
+	return []ValidationIssue{{
+		Severity: "error",
+		Field:    "spec.protocol",
+		Message:  "spec.protocol must be 'STREAMABLE_HTTP' or 'SSE'",
+		Fix:      []PatchOperation{{Op: "replace", Path: "/spec/protocol", Value: suggested}},
+	}}
 }
 
 // registerDiffManifest registers the diff_manifest tool.
 func (ts *ToolServer) registerDiffManifest() {
 	tool := mcp.NewTool("diff_manifest",
-		mcp.WithDescription("Show the differences between a manifest and the current cluster state. Helps review changes before applying."),
+		mcp.WithDescription("Show the differences between a manifest and the current cluster state. Helps review changes before applying. Returns a diff_id that apply_manifest accepts to apply exactly the reviewed manifest. Secret values are redacted on both sides."),
 		mcp.WithString("manifest",
 			mcp.Required(),
 			mcp.Description("YAML manifest to compare against current state"),
 		),
+		mcp.WithBoolean("summarize",
+			mcp.Description("Ask the client's LLM (via MCP sampling) for a plain-language summary of the change (default: false)"),
+		),
+		mcp.WithBoolean("three_way",
+			mcp.Description("Diff against the result of a server-side dry run of the apply, as kubectl diff does, so fields the API server defaults do not show as removals, and list fields other managers own that the apply removes (default: true). false compares the manifest to the live object as is, as is always done outside apply mode"),
+		),
+		mcp.WithString("ignore_fields",
+			mcp.Description("Comma-separated field paths left out of the diff, such as metadata.labels, metadata.annotations['kagent.dev/tests'] or spec.declarative.tools[*].mcpServer.toolNames. * or [*] matches any key or list item. Ignored fields are still applied"),
+		),
+		withDiffFormatOption(),
+		withStructuredOutputOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleDiffManifest)
+	ts.addTool(tool, ts.handleDiffManifest)
 }
 
 func (ts *ToolServer) handleDiffManifest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	manifest, _ := req.Params.Arguments["manifest"].(string)
-	if manifest == "" {
-		return mcp.NewToolResultError("manifest is required"), nil
+	args := params.From(req)
+	manifest := args.RequiredString("manifest")
+	summarize := args.Bool("summarize", false)
+	renderer := diffRendererFrom(args)
+	asStructured := structuredOutputFrom(args)
+	ignoreFields := args.StringList("ignore_fields")
+	threeWay := args.Bool("three_way", true)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var ignored [][]string
+	for _, field := range ignoreFields {
+		path, err := diff.ParsePath(field)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid ignore_fields: %v", err)), nil
+		}
+		ignored = append(ignored, path)
 	}
 
 	// Parse manifest
@@ -421,18 +567,52 @@ func (ts *ToolServer) handleDiffManifest(ctx context.Context, req mcp.CallToolRe
 	name := obj.GetName()
 	kind := obj.GetKind()
 
+	// Compare what apply_manifest would actually send
+	resolveSecretPlaceholders(&obj)
+
+	// Secret values are only shown redacted, on both sides of the diff
+	shown := obj.DeepCopy()
+	shownManifest := manifest
+	var notes []string
+	if kubernetes.RedactSecretValues(shown) {
+		if redacted, err := yaml.Marshal(shown.Object); err == nil {
+			shownManifest = string(redacted)
+		}
+		notes = append(notes, "Secret values are redacted, so changes to them are not shown")
+	}
+
 	// Try to get current state
-	currentYAML, err := ts.k8sClient.GetCurrentState(ctx, kind, name)
+	currentYAML, resourceVersion, err := ts.kube(ctx).GetCurrentStateVersion(ctx, obj.GetAPIVersion(), kind, name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get current state of %s '%s': %v", kind, name, err)), nil
+	}
 	if err != nil {
 		// Resource doesn't exist
+		diffID, err := ts.reviews.Add(ctx, manifest, kind, name, sessionOwner(ctx))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to record the diff: %v", err)), nil
+		}
+		if asStructured {
+			return structured(structuredResult{
+				Summary:         fmt.Sprintf("%s '%s' does not exist in the cluster; applying creates it. Apply with apply_manifest diff_id=%s expected_resource_version=%s.", kind, name, diffID, kubernetes.ResourceAbsent),
+				Manifest:        shownManifest,
+				DiffID:          diffID,
+				Digest:          signing.Digest(manifest),
+				ResourceVersion: kubernetes.ResourceAbsent,
+			})
+		}
 		return mcp.NewToolResultText(fmt.Sprintf(`# New Resource
+# Diff ID: %s
+# Digest: %s
 
 %s '%s' does not exist in the cluster.
 This will CREATE a new resource.
 
 Proposed manifest:
 ---
-%s`, kind, name, manifest)), nil
+%s
+
+To apply exactly this manifest after approval, call apply_manifest with diff_id=%s and expected_resource_version=%s, so the apply is aborted if the resource was created in the meantime.`, diffID, signing.Digest(manifest), kind, name, shownManifest, diffID, kubernetes.ResourceAbsent)), nil
 	}
 
 	// Parse current state for comparison
@@ -443,70 +623,509 @@ Proposed manifest:
 
 	// Clean the proposed manifest for comparison
 	proposedClean := make(map[string]interface{})
-	for k, v := range obj.Object {
+	for k, v := range shown.Object {
 		if k != "status" {
 			proposedClean[k] = v
 		}
 	}
 
-	// Generate diff
-	diff := cmp.Diff(currentObj, proposedClean)
+	// Compare what the update would leave, not the manifest as written:
+	// fields the API server defaults come back and are not removals
+	var foreign []string
+	if noDryRun := ts.dryRunUnavailable(); threeWay && noDryRun != nil {
+		notes = append(notes, fmt.Sprintf("Showing a two-way diff against the live object: %v", noDryRun))
+	} else if threeWay {
+		live, updated, err := ts.kube(ctx).PreviewUpdate(ctx, &obj)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("Showing a two-way diff against the live object: %v", err))
+		} else {
+			resourceVersion = live.GetResourceVersion()
+			entries := live.GetManagedFields()
+			kubernetes.CleanForDiff(live)
+			kubernetes.CleanForDiff(updated)
+			currentObj, proposedClean = live.Object, updated.Object
+			foreign = foreignRemovals(entries, currentObj, proposedClean)
+		}
+	}
+
+	// Ignored fields are only left out of what is shown
+	before, after := currentObj, proposedClean
+	if len(ignored) > 0 {
+		before, after = diff.Without(currentObj, ignored), diff.Without(proposedClean, ignored)
+	}
+	changes, err := renderer.Render(before, after)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to render the diff: %v", err)), nil
+	}
+	if changes == "" && len(ignored) > 0 && len(diff.Patch(currentObj, proposedClean)) > 0 {
+		changes = "(only ignored fields change)"
+	}
 
-	if diff == "" {
+	if changes == "" {
+		if asStructured {
+			return structured(structuredResult{Summary: fmt.Sprintf("No changes detected. %s '%s' is already up to date.", kind, name), ResourceVersion: resourceVersion})
+		}
 		return mcp.NewToolResultText(fmt.Sprintf("No changes detected. %s '%s' is already up to date.", kind, name)), nil
 	}
 
+	diffID, err := ts.reviews.Add(ctx, manifest, kind, name, sessionOwner(ctx))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to record the diff: %v", err)), nil
+	}
+
+	var sampled string
+	if summarize {
+		// The sampler reads the unified diff whatever the caller asked for
+		unified, _ := diff.For(diff.Unified)
+		text, err := unified.Render(before, after)
+		if err != nil {
+			text = changes
+		}
+		sampled = ts.summarizeDiff(ctx, kind, name, text)
+	}
+
+	var envSummary string
+	if kind == "Agent" {
+		envSummary = envChanges(currentObj, proposedClean)
+	}
+
+	if asStructured {
+		summary := fmt.Sprintf("%s '%s' changes. Apply with apply_manifest diff_id=%s expected_resource_version=%s.", kind, name, diffID, resourceVersion)
+		if envSummary != "" {
+			summary += " " + envSummary
+		}
+		if len(foreign) > 0 {
+			summary += " The apply removes fields other managers set: " + strings.Join(foreign, "; ") + "."
+		}
+		for _, note := range notes {
+			summary += " " + note + "."
+		}
+		if sampled != "" {
+			summary += "\n\n" + sampled
+		}
+		return structured(structuredResult{
+			Summary:         summary,
+			Manifest:        shownManifest,
+			Diff:            changes,
+			DiffID:          diffID,
+			Digest:          signing.Digest(manifest),
+			ResourceVersion: resourceVersion,
+		})
+	}
+
 	result := fmt.Sprintf(`# Diff: %s '%s'
+# Diff ID: %s
+# Digest: %s
+# Current resourceVersion: %s
 
 Changes that will be applied:
 
-%s
+%s`, kind, name, diffID, signing.Digest(manifest), resourceVersion, changes)
+	if len(foreign) > 0 {
+		result += "\n\n⚠️ The apply removes fields other managers set; add them to the manifest to keep them:\n- " + strings.Join(foreign, "\n- ")
+	}
+	if len(ignoreFields) > 0 {
+		result += "\n\nIgnored fields (not shown, still applied): " + strings.Join(ignoreFields, ", ")
+	}
+	for _, note := range notes {
+		result += "\n\nNote: " + note
+	}
+	if envSummary != "" {
+		result += "\n\n" + envSummary
+	}
+	if legend := renderer.Legend(); legend != "" {
+		result += "\n\n" + legend
+	}
+	result += fmt.Sprintf("\n\nTo apply exactly this reviewed change after approval, call apply_manifest with diff_id=%s and expected_resource_version=%s, so the apply is aborted if the resource changed since this review.", diffID, resourceVersion)
 
-Legend: - removed, + added`, kind, name, diff)
+	if sampled != "" {
+		result += "\n\n" + sampled
+	}
 
 	return mcp.NewToolResultText(result), nil
 }
 
+// summarizeDiff asks the sampler for a plain-language summary of a diff. The
+// diff itself is always returned, so failures are reported inline rather
+// than failing the tool call.
+func (ts *ToolServer) summarizeDiff(ctx context.Context, kind, name, changes string) string {
+	summary, err := ts.server.Sampler().Sample(ctx, sampling.Request{
+		SystemPrompt: "You review Kubernetes manifest changes for kagent resources. Be brief and concrete.",
+		Prompt: fmt.Sprintf("Summarize in a few bullet points what this change to %s '%s' does and call out anything risky. "+
+			"Lines starting with - are removed and + are added.\n\n%s", kind, name, changes),
+		MaxTokens: 512,
+	})
+	if errors.Is(err, sampling.ErrUnavailable) {
+		return "Summary: not available (" + err.Error() + ")."
+	}
+	if err != nil {
+		return fmt.Sprintf("Summary: failed to generate: %v", err)
+	}
+	return "Summary:\n" + strings.TrimSpace(summary)
+}
+
+// registerGetResource registers the get_resource tool.
+func (ts *ToolServer) registerGetResource() {
+	tool := mcp.NewTool("get_resource",
+		mcp.WithDescription("Get the current state of any resource kind served by the cluster (server-managed fields stripped). Kinds are resolved through API discovery, so new kagent kinds work without changes. Secret values are never returned, only their keys."),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Resource kind (e.g., 'Agent', 'ConfigMap', 'Deployment')"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the resource"),
+		),
+		mcp.WithString("api_version",
+			mcp.Description("apiVersion of the kind (e.g., 'kagent.dev/v1alpha2'). If omitted, the kind is looked up in kagent.dev first, then the core group, then all other groups"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleGetResource)
+}
+
+func (ts *ToolServer) handleGetResource(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.RequiredString("kind")
+	name := args.RequiredString("name")
+	apiVersion := args.String("api_version")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	current, err := ts.kube(ctx).GetCurrentState(ctx, apiVersion, kind, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get %s '%s': %v", kind, name, err)), nil
+	}
+
+	return mcp.NewToolResultText(current), nil
+}
+
 // registerApplyManifest registers the apply_manifest tool.
 func (ts *ToolServer) registerApplyManifest() {
 	tool := mcp.NewTool("apply_manifest",
-		mcp.WithDescription("Apply a validated manifest to the Kubernetes cluster. IMPORTANT: Always validate and show diff to user before applying. Use dry_run=true to preview without applying."),
+		mcp.WithDescription("Apply a validated manifest or multi-document bundle to the Kubernetes cluster, reporting a result per resource. Supports kagent.dev kinds; core kinds ("+strings.Join(coreApplyKindNames(), ", ")+") only when enabled on the server. Bundles breaking a rule the server enforces are refused with a policy violation. When the server runs in propose mode, the change is recorded as a pending change for an operator to apply instead of being applied. IMPORTANT: Always validate and show diff to user before applying. Use dry_run=true to preview without applying."),
 		mcp.WithString("manifest",
-			mcp.Required(),
-			mcp.Description("YAML manifest to apply"),
+			mcp.Description("YAML manifest to apply (required unless diff_id is given)"),
+		),
+		mcp.WithString("diff_id",
+			mcp.Description("Diff ID returned by diff_manifest. Applies exactly the manifest that was reviewed; preferred over re-sending the manifest"),
 		),
 		mcp.WithBoolean("dry_run",
-			mcp.Description("Perform a server-side dry-run without actually applying (default: false)"),
+			mcp.Description("Perform a server-side dry-run without actually applying (default: false). Outside apply mode only this server's checks run"),
+		),
+		mcp.WithBoolean("continue_on_error",
+			mcp.Description("For multi-document bundles, keep applying the remaining resources after a failure instead of stopping (default: false)"),
+		),
+		mcp.WithString("expected_resource_version",
+			mcp.Description("Abort unless the resource still has this resourceVersion, e.g. the one diff_manifest reported, so a change made since the review is not overwritten. 'absent' asserts the resource does not exist yet. Single-document manifests only"),
 		),
+		mcp.WithString("expected_fields_json",
+			mcp.Description(`Abort unless the resource's current fields have these values: a JSON object of field paths to values, e.g. {"spec.declarative.modelConfig": "gpt4o", "spec.declarative.tools[0].mcpServer.name": "k8s-tools"}; null asserts a field is unset. Single-document manifests only`),
+		),
+		mcp.WithString("signature",
+			mcp.Description("Approver's signature of the manifest's digest (hmac-sha256:<hex>), as printed by the server's --sign-manifest. Verified against the server's signing key; required when the server requires signed manifests"),
+		),
+		mcp.WithString("reason",
+			mcp.Description("Why the change is made, recorded with it for the operator when the server runs in propose mode"),
+		),
+		withAsyncOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleApplyManifest)
+	ts.addTool(tool, ts.withAsync("apply_manifest", ts.handleApplyManifest))
 }
 
 func (ts *ToolServer) handleApplyManifest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	manifest, _ := req.Params.Arguments["manifest"].(string)
+	args := params.From(req)
+	manifest := args.String("manifest")
+	diffID := args.String("diff_id")
+	dryRun := args.Bool("dry_run", false)
+	continueOnError := args.Bool("continue_on_error", false)
+	expectedVersion := args.String("expected_resource_version")
+	expectedFields := args.String("expected_fields_json")
+	signature := args.String("signature")
+	reason := args.String("reason")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pre := kubernetes.Preconditions{ResourceVersion: expectedVersion}
+	if expectedFields != "" {
+		if err := json.Unmarshal([]byte(expectedFields), &pre.Fields); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("expected_fields_json must be a JSON object of field paths to values: %v", err)), nil
+		}
+	}
+
+	// Resolve the reviewed manifest so the applied content matches the diff
+	if diffID != "" {
+		review, ok, err := ts.reviews.Get(ctx, diffID, sessionOwner(ctx))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to look up diff_id '%s': %v", diffID, err)), nil
+		}
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("diff_id '%s' not found or expired. Run diff_manifest again to review the change.", diffID)), nil
+		}
+		if manifest != "" && strings.TrimSpace(manifest) != strings.TrimSpace(review.Manifest) {
+			return mcp.NewToolResultError(fmt.Sprintf("manifest does not match the one reviewed under diff_id '%s'. Omit manifest to apply the reviewed version, or run diff_manifest on the new manifest.", diffID)), nil
+		}
+		manifest = review.Manifest
+	}
+
 	if manifest == "" {
-		return mcp.NewToolResultError("manifest is required"), nil
+		return mcp.NewToolResultError("manifest or diff_id is required"), nil
+	}
+	if err := ts.checkSignature(manifest, signature, dryRun); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	docs := kubernetes.SplitManifests(manifest)
+	if len(docs) == 0 {
+		return mcp.NewToolResultError("manifest is empty"), nil
+	}
+
+	if len(docs) > 1 && !pre.IsZero() {
+		return mcp.NewToolResultError("expected_resource_version and expected_fields_json apply to a single resource; apply the bundle's documents one at a time to assert preconditions"), nil
+	}
+
+	// Rules the server enforces gate every apply, dry runs included
+	audit, err := ts.enforcePolicies(ctx, docs)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Without write access, record the change for an operator instead
+	if noDryRun := ts.dryRunUnavailable(); noDryRun != nil && dryRun {
+		resources, err := ts.proposedResources(ctx, docs)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Not proposed: %v", err)), nil
+		}
+		return mcp.NewToolResultText(formatProposalDryRun(resources, noDryRun) + audit), nil
+	}
+	if ts.server.Config().Mode == ModePropose {
+		change, err := ts.proposeChange(ctx, manifest, docs, reason, pre)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Not proposed: %v", err)), nil
+		}
+		if diffID != "" {
+			ts.forgetReview(ctx, diffID)
+		}
+		return mcp.NewToolResultText(formatProposedChange(change) + audit), nil
+	}
+
+	if len(docs) == 1 {
+		result, err := ts.applyDocument(ctx, docs[0], dryRun, pre)
+		var failed *kubernetes.PreconditionError
+		if errors.As(err, &failed) {
+			return mcp.NewToolResultError(fmt.Sprintf("Not applied, %v. The resource changed since it was reviewed: run diff_manifest again and review the current changes.", err)), nil
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to apply manifest: %v", err)), nil
+		}
+
+		// A reviewed diff can only be applied once
+		if diffID != "" && !dryRun {
+			ts.forgetReview(ctx, diffID)
+		}
+
+		var status string
+		if dryRun {
+			status = fmt.Sprintf("# Dry Run Successful\n\n%s '%s' in namespace '%s' would be %s.\n\nTo actually apply, run apply_manifest with dry_run=false.",
+				result.Kind, result.Name, result.Namespace, result.Action)
+		} else {
+			status = fmt.Sprintf("# Successfully Applied\n\n%s '%s' in namespace '%s' has been %s.",
+				result.Kind, result.Name, result.Namespace, result.Action)
+		}
+
+		return mcp.NewToolResultText(status + audit), nil
+	}
+
+	// Apply the bundle in order, recording a result for every document
+	results := make([]kubernetes.ApplyResult, 0, len(docs))
+	failed := 0
+	for _, doc := range docs {
+		if failed > 0 && !continueOnError {
+			results = append(results, *skippedResult(doc, dryRun))
+			continue
+		}
+
+		result, err := ts.applyDocument(ctx, doc, dryRun, kubernetes.Preconditions{})
+		if err != nil {
+			failed++
+			result = skippedResult(doc, dryRun)
+			result.Action = "failed"
+			result.Error = err.Error()
+		}
+		results = append(results, *result)
+	}
+
+	if diffID != "" && !dryRun && failed == 0 {
+		ts.forgetReview(ctx, diffID)
+	}
+
+	return mcp.NewToolResultText(formatApplyResults(results, dryRun, failed, continueOnError) + audit), nil
+}
+
+// checkSignature verifies the approver's signature of a manifest about to be
+// applied. Without a signing key, signatures cannot be checked and are
+// rejected rather than ignored; with one, they are verified, and required
+// unless the server allows unsigned applies or this is a dry run.
+func (ts *ToolServer) checkSignature(manifest, signature string, dryRun bool) error {
+	cfg := ts.server.Config()
+	if cfg.SigningKey == "" {
+		if signature != "" {
+			return fmt.Errorf("a signature was given, but the server has no signing key (KAGENT_SIGNING_KEY) to verify it with")
+		}
+		return nil
+	}
+	if signature == "" {
+		if cfg.RequireSignature && !dryRun {
+			return fmt.Errorf("the server only applies signed manifests: an approver must sign digest %s and the signature be passed as signature", signing.Digest(manifest))
+		}
+		return nil
+	}
+	if err := signing.Verify(cfg.SigningKey, manifest, signature); err != nil {
+		return fmt.Errorf("not applied: %v. The manifest (digest %s) is not the one that was signed", err, signing.Digest(manifest))
+	}
+	return nil
+}
+
+// forgetReview removes an applied diff ID. The apply already succeeded, so
+// a failure is only logged; the ID then expires with its TTL.
+func (ts *ToolServer) forgetReview(ctx context.Context, diffID string) {
+	if err := ts.reviews.Delete(ctx, diffID); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove applied diff %s: %v\n", diffID, err)
+	}
+}
+
+// applyDocument checks and applies a single manifest document, if the
+// resource satisfies pre.
+func (ts *ToolServer) applyDocument(ctx context.Context, doc string, dryRun bool, pre kubernetes.Preconditions) (*kubernetes.ApplyResult, error) {
+	obj, err := kubernetes.ParseManifest(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.checkApplyAllowed(obj); err != nil {
+		return nil, err
+	}
+
+	// Apply secret references in place of placeholders, never secret values
+	if resolveSecretPlaceholders(obj) {
+		resolved, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode manifest: %w", err)
+		}
+		doc = string(resolved)
 	}
 
-	dryRun := false
-	if v, ok := req.Params.Arguments["dry_run"].(bool); ok {
-		dryRun = v
+	// Keep the spec an update replaces, so the change can be rolled back
+	var previous map[string]interface{}
+	gvr, versioned := kagentGVR(obj.GetKind())
+	if versioned && !dryRun {
+		if live, err := ts.kube(ctx).GetResourceIn(ctx, gvr, obj.GetNamespace(), obj.GetName()); err == nil {
+			previous, _, _ = unstructured.NestedMap(live.Object, "spec")
+		}
 	}
 
-	result, err := ts.k8sClient.Apply(ctx, manifest, dryRun)
+	result, err := ts.kube(ctx).Apply(ctx, doc, dryRun, pre)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to apply manifest: %v", err)), nil
+		if issues := ts.checkNamespace(ctx, obj.GetNamespace()); len(issues) > 0 {
+			return nil, fmt.Errorf("%s", issues[0].Message)
+		}
+		return nil, err
+	}
+
+	if versioned && !dryRun && (result.Action == "created" || result.Action == "updated") {
+		ts.recordRevision(ctx, obj, result.Action, previous)
 	}
+	return result, nil
+}
 
-	var status string
-	if dryRun {
-		status = fmt.Sprintf("# Dry Run Successful\n\n%s '%s' in namespace '%s' would be %s.\n\nTo actually apply, run apply_manifest with dry_run=false.",
-			result.Kind, result.Name, result.Namespace, result.Action)
-	} else {
-		status = fmt.Sprintf("# Successfully Applied\n\n%s '%s' in namespace '%s' has been %s.",
-			result.Kind, result.Name, result.Namespace, result.Action)
+// skippedResult returns a result for a document that was not applied,
+// identified by whatever can be parsed from it.
+func skippedResult(doc string, dryRun bool) *kubernetes.ApplyResult {
+	result := &kubernetes.ApplyResult{Action: "skipped", DryRun: dryRun}
+	if obj, err := kubernetes.ParseManifest(doc); err == nil {
+		result.Kind = obj.GetKind()
+		result.Name = obj.GetName()
+		result.Namespace = obj.GetNamespace()
 	}
+	return result
+}
 
-	return mcp.NewToolResultText(status), nil
+// formatApplyResults renders per-resource bundle results as a table.
+func formatApplyResults(results []kubernetes.ApplyResult, dryRun bool, failed int, continueOnError bool) string {
+	var b strings.Builder
+
+	switch {
+	case failed == 0 && dryRun:
+		b.WriteString("# Dry Run Successful\n\n")
+	case failed == 0:
+		b.WriteString("# Successfully Applied\n\n")
+	default:
+		b.WriteString("# Partially Applied\n\n")
+	}
+
+	b.WriteString("| # | Kind | Name | Namespace | Result | Error |\n")
+	b.WriteString("|---|------|------|-----------|--------|-------|\n")
+	for i, r := range results {
+		b.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s | %s |\n",
+			i+1, r.Kind, r.Name, r.Namespace, r.Action, strings.ReplaceAll(r.Error, "|", "\\|")))
+	}
+
+	switch {
+	case failed > 0 && !continueOnError:
+		b.WriteString("\nStopped at the first failure. Resources before it were applied; fix the error and re-apply the bundle (applied resources will report unchanged), or pass continue_on_error=true.")
+	case failed > 0:
+		b.WriteString(fmt.Sprintf("\n%d of %d resource(s) failed. Fix the errors and re-apply the bundle.", failed, len(results)))
+	case dryRun:
+		b.WriteString("\nTo actually apply, run apply_manifest with dry_run=false.")
+	}
+
+	return b.String()
+}
+
+// coreApplyKinds are the non-kagent kinds that may be enabled for
+// apply_manifest through the server allowlist, keyed by kind with the API
+// group they must belong to.
+var coreApplyKinds = map[string]string{
+	"Namespace":      "",
+	"ServiceAccount": "",
+	"Secret":         "",
+	"Service":        "",
+	"Role":           "rbac.authorization.k8s.io",
+	"RoleBinding":    "rbac.authorization.k8s.io",
+	"NetworkPolicy":  "networking.k8s.io",
+	"ConfigMap":      "",
+}
+
+// coreApplyKindNames returns the kinds of coreApplyKinds, sorted.
+func coreApplyKindNames() []string {
+	names := make([]string, 0, len(coreApplyKinds))
+	for kind := range coreApplyKinds {
+		names = append(names, kind)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkApplyAllowed returns an error if the object's kind may not be applied.
+// kagent kinds are always allowed; core kinds require an explicit opt-in via
+// KAGENT_APPLY_ALLOWED_KINDS.
+func (ts *ToolServer) checkApplyAllowed(obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+	if gvk.Group == kubernetes.AgentGVR.Group {
+		return nil
+	}
+
+	group, supported := coreApplyKinds[gvk.Kind]
+	if !supported || group != gvk.Group {
+		return fmt.Errorf("kind '%s' (%s) cannot be applied. apply_manifest supports kagent.dev kinds plus opt-in core kinds: %s", gvk.Kind, obj.GetAPIVersion(), strings.Join(coreApplyKindNames(), ", "))
+	}
+
+	for _, allowed := range ts.server.Config().ApplyAllowedKinds {
+		if allowed == gvk.Kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("kind '%s' is not enabled for apply_manifest. Add it to KAGENT_APPLY_ALLOWED_KINDS on the server to allow it", gvk.Kind)
 }
@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/tenancy"
+	"github.com/kagent-dev/meta-kagent/internal/upgrade"
+)
+
+// resourceScheme is the URI scheme of the kagent resources the server
+// exposes as MCP resources.
+const resourceScheme = "kagent://"
+
+// resourceURI returns the URI of a kagent resource. Resources in the
+// session's namespace get the short form.
+func resourceURI(gvr schema.GroupVersionResource, namespace, name, sessionNamespace string) string {
+	if namespace == sessionNamespace {
+		return fmt.Sprintf("%s%s/%s", resourceScheme, gvr.Resource, name)
+	}
+	return fmt.Sprintf("%snamespaces/%s/%s/%s", resourceScheme, namespace, gvr.Resource, name)
+}
+
+// registerResources exposes the kagent resources as MCP resources, so that
+// clients can read manifests by URI instead of calling a tool:
+//
+//	kagent://agents                               names and URIs of the agents
+//	kagent://agents/{name}                        an agent's manifest
+//	kagent://namespaces/{namespace}/agents/{name} an agent in another namespace
+//
+// and likewise for modelconfigs, mcpservers and remotemcpservers.
+func (ts *ToolServer) registerResources() {
+	for _, k := range staleKinds {
+		kind, gvr := k.Kind, k.GVR
+
+		ts.server.AddResource(mcp.NewResource(resourceScheme+gvr.Resource, kind+" list",
+			mcp.WithResourceDescription(fmt.Sprintf("Names and resource URIs of the %s resources in the server's namespace", kind)),
+			mcp.WithMIMEType("application/json"),
+		), ts.listResourceHandler(gvr))
+
+		ts.server.AddResourceTemplate(mcp.NewResourceTemplate(resourceScheme+gvr.Resource+"/{name}", kind,
+			mcp.WithTemplateDescription(fmt.Sprintf("Manifest of a %s in the server's namespace, without server-managed fields", kind)),
+			mcp.WithTemplateMIMEType("application/yaml"),
+		), ts.readResourceHandler(gvr))
+
+		ts.server.AddResourceTemplate(mcp.NewResourceTemplate(resourceScheme+"namespaces/{namespace}/"+gvr.Resource+"/{name}", kind+" in namespace",
+			mcp.WithTemplateDescription(fmt.Sprintf("Manifest of a %s in the given namespace, without server-managed fields", kind)),
+			mcp.WithTemplateMIMEType("application/yaml"),
+		), ts.readResourceHandler(gvr))
+	}
+}
+
+// listResourceHandler returns the handler of a kind's list resource.
+func (ts *ToolServer) listResourceHandler(gvr schema.GroupVersionResource) server.ResourceHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		client := ts.kube(ctx)
+		items, err := client.ListResourcesBySelector(ctx, gvr, "")
+		if err != nil {
+			return nil, err
+		}
+
+		type entry struct {
+			Name string `json:"name"`
+			URI  string `json:"uri"`
+		}
+		entries := make([]entry, 0, len(items))
+		for _, item := range items {
+			entries = append(entries, entry{
+				Name: item.GetName(),
+				URI:  resourceURI(gvr, item.GetNamespace(), item.GetName(), client.Namespace()),
+			})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+		output, _ := json.MarshalIndent(entries, "", "  ")
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(output),
+		}}, nil
+	}
+}
+
+// readResourceHandler returns the handler of a kind's resource templates.
+// Tenant sessions may only read their own namespace.
+func (ts *ToolServer) readResourceHandler(gvr schema.GroupVersionResource) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		name := templateArgument(req, "name")
+		namespace := templateArgument(req, "namespace")
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid name '%s' in %s", name, req.Params.URI)
+		}
+		if namespace != "" {
+			if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid namespace '%s' in %s", namespace, req.Params.URI)
+			}
+			if t, ok := tenancy.FromContext(ctx); ok && namespace != t.Namespace {
+				return nil, fmt.Errorf("tenant '%s' may only read namespace '%s'", t.Name, t.Namespace)
+			}
+		}
+
+		obj, err := ts.kube(ctx).GetResourceIn(ctx, gvr, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		output, err := yaml.Marshal(upgrade.Clean(obj).Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", req.Params.URI, err)
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: "application/yaml",
+			Text:     string(output),
+		}}, nil
+	}
+}
+
+// templateArgument returns a variable matched from a resource template.
+// Depending on the template, the matched value is a string or a list.
+func templateArgument(req mcp.ReadResourceRequest, name string) string {
+	switch v := req.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"sigs.k8s.io/yaml"
 
+	"github.com/kagent-dev/meta-kagent/internal/params"
 	"github.com/kagent-dev/meta-kagent/pkg/types"
 )
 
@@ -18,21 +20,40 @@ func (ts *ToolServer) registerListMCPServers() {
 		mcp.WithBoolean("include_remote",
 			mcp.Description("Include RemoteMCPServer resources (default: true)"),
 		),
+		withLabelSelectorOption(),
+		withFieldSelectorOption(),
+		withAllNamespacesOption(),
+		withRefreshOption(),
+		withLimitOption(),
+		withContinueOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleListMCPServers)
+	ts.addTool(tool, ts.handleListMCPServers)
 }
 
 func (ts *ToolServer) handleListMCPServers(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	includeRemote := true
-	if v, ok := req.Params.Arguments["include_remote"].(bool); ok {
-		includeRemote = v
+	args := params.From(req)
+	includeRemote := args.Bool("include_remote", true)
+	opts, selectorErr := listOptionsFrom(args)
+	allNamespaces := args.Bool("all_namespaces", false)
+	refresh := args.Bool("refresh", false)
+	p := ts.pageFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if selectorErr != nil {
+		return mcp.NewToolResultError(selectorErr.Error()), nil
+	}
+
+	client, err := ts.listClient(ctx, allNamespaces, refresh)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	var result []map[string]interface{}
 
 	// List MCPServers
-	mcpServers, err := ts.k8sClient.ListMCPServers(ctx)
+	mcpServers, _, err := client.ListMCPServersWithOptions(ctx, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to list MCP servers: %v", err)), nil
 	}
@@ -53,7 +74,7 @@ func (ts *ToolServer) handleListMCPServers(ctx context.Context, req mcp.CallTool
 
 	// List RemoteMCPServers
 	if includeRemote {
-		remoteServers, err := ts.k8sClient.ListRemoteMCPServers(ctx)
+		remoteServers, _, err := client.ListRemoteMCPServersWithOptions(ctx, opts)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to list remote MCP servers: %v", err)), nil
 		}
@@ -72,24 +93,33 @@ func (ts *ToolServer) handleListMCPServers(ctx context.Context, req mcp.CallTool
 	}
 
 	if len(result) == 0 {
+		if filter := describeSelectors(opts); filter != "" {
+			return mcp.NewToolResultText(fmt.Sprintf("No MCP servers%s found in the namespace.", filter)), nil
+		}
 		return mcp.NewToolResultText("No MCP servers found in the namespace. Use create_mcp_server_manifest to create one."), nil
 	}
 
+	// Both kinds are listed in one sequence, so the tool pages it
+	result, next, err := paginate(result, p)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	output, _ := json.MarshalIndent(result, "", "  ")
-	return mcp.NewToolResultText(string(output)), nil
+	return mcp.NewToolResultText(string(output) + continueNote(len(result), next)), nil
 }
 
 // registerCreateMCPServerManifest registers the create_mcp_server_manifest tool.
 func (ts *ToolServer) registerCreateMCPServerManifest() {
 	tool := mcp.NewTool("create_mcp_server_manifest",
-		mcp.WithDescription("Generate a new MCPServer or RemoteMCPServer manifest. MCPServer runs as a container with stdio transport; RemoteMCPServer connects to an external HTTP endpoint."),
+		mcp.WithDescription("Generate a new MCPServer, RemoteMCPServer or MCP Service manifest. MCPServer runs as a container with stdio transport; RemoteMCPServer connects to an external HTTP endpoint; Service fronts pods already serving MCP over HTTP in the cluster, annotated with the port and path agents connect to, for tool references with kind Service."),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name for the MCP server resource"),
 		),
 		mcp.WithString("server_type",
 			mcp.Required(),
-			mcp.Description("Type: 'MCPServer' (local container with stdio) or 'RemoteMCPServer' (external HTTP endpoint)"),
+			mcp.Description("Type: 'MCPServer' (local container with stdio), 'RemoteMCPServer' (external HTTP endpoint) or 'Service' (in-cluster pods serving MCP over HTTP)"),
 		),
 		mcp.WithString("description",
 			mcp.Description("Human-readable description of the server's purpose"),
@@ -105,106 +135,173 @@ func (ts *ToolServer) registerCreateMCPServerManifest() {
 			mcp.Description("JSON array of command arguments"),
 		),
 		mcp.WithNumber("port",
-			mcp.Description("Container port (default: 3000)"),
+			mcp.Description("Container port for MCPServer, or the port the Service exposes MCP on (default: 3000)"),
+		),
+		mcp.WithString("volumes_json",
+			mcp.Description(`JSON array of pod volumes, each with one source: [{"name": "config", "configMap": {"name": "my-config"}}, {"name": "creds", "secret": {"secretName": "my-secret"}}, {"name": "cache", "emptyDir": {}}]`),
+		),
+		mcp.WithString("volume_mounts_json",
+			mcp.Description(`JSON array of volume mounts for the server container: [{"name": "config", "mountPath": "/etc/config", "readOnly": true}]`),
+		),
+		mcp.WithString("init_containers_json",
+			mcp.Description(`JSON array of init containers run before the server starts, e.g. config fetchers: [{"name": "fetch-config", "image": "curlimages/curl", "args": ["-o", "/config/tools.json", "https://..."], "volumeMounts": [{"name": "config", "mountPath": "/config"}]}]`),
+		),
+		mcp.WithString("sidecars_json",
+			mcp.Description(`JSON array of sidecar containers run next to the server, e.g. credential helpers or proxies; same fields as init containers plus ports: [{"name": "proxy", "image": "envoyproxy/envoy:v1.30", "ports": [{"containerPort": 8080}]}]`),
 		),
 		// RemoteMCPServer specific
 		mcp.WithString("url",
 			mcp.Description("URL for RemoteMCPServer (required for RemoteMCPServer type)"),
 		),
 		mcp.WithString("protocol",
-			mcp.Description("Protocol for RemoteMCPServer: 'STREAMABLE_HTTP' (default) or 'SSE'"),
+			mcp.Description("Protocol for RemoteMCPServer and Service: 'STREAMABLE_HTTP' (default) or 'SSE'"),
 		),
 		mcp.WithString("timeout",
 			mcp.Description("Request timeout (e.g., '30s', '5m')"),
 		),
+		// Service specific
+		mcp.WithString("selector",
+			mcp.Description("Labels of the pods serving MCP, as comma-separated key=value pairs (required for Service type, e.g., 'app=my-mcp')"),
+		),
+		mcp.WithNumber("target_port",
+			mcp.Description("Port the pods listen on, for Service type (default: port)"),
+		),
+		mcp.WithString("path",
+			mcp.Description("HTTP path of the MCP endpoint, for Service type (default: '/mcp')"),
+		),
+		mcp.WithBoolean("include_namespace",
+			mcp.Description("Prepend a Namespace manifest so the bundle can be applied to a cluster where the namespace does not exist yet (default: false)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleCreateMCPServerManifest)
+	ts.addTool(tool, ts.handleCreateMCPServerManifest)
 }
 
 func (ts *ToolServer) handleCreateMCPServerManifest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name, _ := req.Params.Arguments["name"].(string)
-	serverType, _ := req.Params.Arguments["server_type"].(string)
-	description, _ := req.Params.Arguments["description"].(string)
-
-	if name == "" || serverType == "" {
-		return mcp.NewToolResultError("name and server_type are required"), nil
+	args := params.From(req)
+	name := args.RequiredString("name")
+	serverType := args.RequiredEnum("server_type", "MCPServer", "RemoteMCPServer", "Service")
+	description := args.String("description")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	if serverType == "MCPServer" {
-		return ts.createMCPServerManifest(req, name, description)
-	} else if serverType == "RemoteMCPServer" {
-		return ts.createRemoteMCPServerManifest(req, name, description)
+	switch serverType {
+	case "MCPServer":
+		return ts.createMCPServerManifest(ctx, args, name, description)
+	case "Service":
+		return ts.createServiceMCPManifest(ctx, args, name, description)
 	}
-
-	return mcp.NewToolResultError("server_type must be 'MCPServer' or 'RemoteMCPServer'"), nil
+	return ts.createRemoteMCPServerManifest(ctx, args, name, description)
 }
 
-func (ts *ToolServer) createMCPServerManifest(req mcp.CallToolRequest, name, description string) (*mcp.CallToolResult, error) {
-	image, _ := req.Params.Arguments["image"].(string)
-	command, _ := req.Params.Arguments["command"].(string)
-	argsJSON, _ := req.Params.Arguments["args_json"].(string)
-	portFloat, _ := req.Params.Arguments["port"].(float64)
+func (ts *ToolServer) createMCPServerManifest(ctx context.Context, args *params.Args, name, description string) (*mcp.CallToolResult, error) {
+	image := args.String("image")
+	command := args.String("command")
+	argsJSON := args.String("args_json")
+	port := args.IntRange("port", 3000, 1, 65535)
+	volumesJSON := args.String("volumes_json")
+	volumeMountsJSON := args.String("volume_mounts_json")
+	initContainersJSON := args.String("init_containers_json")
+	sidecarsJSON := args.String("sidecars_json")
+	includeNamespace := args.Bool("include_namespace", false)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	if image == "" {
 		return mcp.NewToolResultError("image is required for MCPServer type"), nil
 	}
 
-	port := int32(3000)
-	if portFloat > 0 {
-		port = int32(portFloat)
+	var volumes []types.Volume
+	var volumeMounts []types.VolumeMount
+	var initContainers, sidecars []types.Container
+	var err error
+	if volumesJSON != "" {
+		if volumes, _, err = decodeJSONArray("volumes_json", volumesJSON, false, checkVolume); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	if volumeMountsJSON != "" {
+		if volumeMounts, _, err = decodeJSONArray("volume_mounts_json", volumeMountsJSON, false, checkVolumeMount); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	if initContainersJSON != "" {
+		if initContainers, _, err = decodeJSONArray("init_containers_json", initContainersJSON, false, checkContainer); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	if sidecarsJSON != "" {
+		if sidecars, _, err = decodeJSONArray("sidecars_json", sidecarsJSON, false, checkContainer); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 	}
 
-	var args []string
+	var containerArgs []string
 	if argsJSON != "" {
-		_ = json.Unmarshal([]byte(argsJSON), &args)
+		_ = json.Unmarshal([]byte(argsJSON), &containerArgs)
 	}
 
 	server := types.MCPServer{
 		Spec: types.MCPServerSpec{
 			Description: description,
 			Deployment: &types.DeploymentSpec{
-				Image: image,
-				Cmd:   command,
-				Args:  args,
-				Port:  port,
+				Image:          image,
+				Cmd:            command,
+				Args:           containerArgs,
+				Port:           int32(port),
+				Volumes:        volumes,
+				VolumeMounts:   volumeMounts,
+				InitContainers: initContainers,
+				Sidecars:       sidecars,
 			},
 			TransportType:  "stdio",
-			StdioTransport: map[string]interface{}{},
+			StdioTransport: &types.StdioTransport{},
 		},
 	}
 	server.APIVersion = "kagent.dev/v1alpha1"
 	server.Kind = "MCPServer"
 	server.Name = name
-	server.Namespace = "kagent"
+	server.Namespace = ts.kube(ctx).Namespace()
+
+	if issues := checkDeploymentSpec(server.Spec.Deployment, "deployment"); len(issues) > 0 {
+		var problems []string
+		for _, issue := range issues {
+			problems = append(problems, fmt.Sprintf("%s: %s", issue.Field, issue.Message))
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid deployment:\n%s", strings.Join(problems, "\n"))), nil
+	}
 
 	output, _ := yaml.Marshal(server)
 
-	result := fmt.Sprintf(`# Generated MCPServer Manifest
+	header := `# Generated MCPServer Manifest
 # This creates a local MCP server running as a container with stdio transport.
-# Use validate_manifest to check, then apply_manifest to deploy.
-
-%s`, string(output))
+# Use validate_manifest to check, then apply_manifest to deploy.`
 
-	return mcp.NewToolResultText(result), nil
+	return out.render(header, withNamespaceDocument(includeNamespace, server.Namespace, string(output)))
 }
 
-func (ts *ToolServer) createRemoteMCPServerManifest(req mcp.CallToolRequest, name, description string) (*mcp.CallToolResult, error) {
-	url, _ := req.Params.Arguments["url"].(string)
-	protocol, _ := req.Params.Arguments["protocol"].(string)
-	timeout, _ := req.Params.Arguments["timeout"].(string)
+func (ts *ToolServer) createRemoteMCPServerManifest(ctx context.Context, args *params.Args, name, description string) (*mcp.CallToolResult, error) {
+	url := args.String("url")
+	protocol := args.Enum("protocol", "STREAMABLE_HTTP", "STREAMABLE_HTTP", "SSE")
+	timeout := args.StringDefault("timeout", "30s")
+	includeNamespace := args.Bool("include_namespace", false)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := ts.expandFacts(ctx, &description, &url); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	if url == "" {
 		return mcp.NewToolResultError("url is required for RemoteMCPServer type"), nil
 	}
 
-	if protocol == "" {
-		protocol = "STREAMABLE_HTTP"
-	}
-	if timeout == "" {
-		timeout = "30s"
-	}
-
 	server := types.RemoteMCPServer{
 		Spec: types.RemoteMCPServerSpec{
 			Description:      description,
@@ -218,15 +315,13 @@ func (ts *ToolServer) createRemoteMCPServerManifest(req mcp.CallToolRequest, nam
 	server.APIVersion = "kagent.dev/v1alpha2"
 	server.Kind = "RemoteMCPServer"
 	server.Name = name
-	server.Namespace = "kagent"
+	server.Namespace = ts.kube(ctx).Namespace()
 
 	output, _ := yaml.Marshal(server)
 
-	result := fmt.Sprintf(`# Generated RemoteMCPServer Manifest
+	header := fmt.Sprintf(`# Generated RemoteMCPServer Manifest
 # This connects to an external MCP server at %s using %s protocol.
-# Use validate_manifest to check, then apply_manifest to deploy.
-
-%s`, url, protocol, string(output))
+# Use validate_manifest to check, then apply_manifest to deploy.`, url, protocol)
 
-	return mcp.NewToolResultText(result), nil
+	return out.render(header, withNamespaceDocument(includeNamespace, server.Namespace, string(output)))
 }
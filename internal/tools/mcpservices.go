@@ -0,0 +1,216 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// Label and annotations kagent reads from a Service referenced as a tool
+// server.
+const (
+	serviceMCPLabel           = "kagent.dev/mcp-service"
+	serviceMCPPortAnnotation  = "kagent.dev/mcp-service-port"
+	serviceMCPPathAnnotation  = "kagent.dev/mcp-service-path"
+	serviceMCPProtoAnnotation = "kagent.dev/mcp-service-protocol"
+)
+
+// defaultServiceMCPPath is the path kagent assumes when a Service does not
+// declare one.
+const defaultServiceMCPPath = "/mcp"
+
+// serviceProtocols maps the protocol argument to the annotation value.
+var serviceProtocols = map[string]string{
+	"STREAMABLE_HTTP": "streamable-http",
+	"SSE":             "sse",
+}
+
+// createServiceMCPManifest generates a Service in front of pods already
+// serving MCP over HTTP, annotated so agents can reference it as a tool
+// server with kind Service.
+func (ts *ToolServer) createServiceMCPManifest(ctx context.Context, args *params.Args, name, description string) (*mcp.CallToolResult, error) {
+	selector := args.String("selector")
+	port := args.IntRange("port", 3000, 1, 65535)
+	targetPort := args.IntRange("target_port", port, 1, 65535)
+	path := args.StringDefault("path", defaultServiceMCPPath)
+	protocol := args.Enum("protocol", "STREAMABLE_HTTP", "STREAMABLE_HTTP", "SSE")
+	includeNamespace := args.Bool("include_namespace", false)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if selector == "" {
+		return mcp.NewToolResultError("selector is required for Service type: the labels of the pods serving MCP (e.g., 'app=my-mcp')"), nil
+	}
+	podLabels, err := labels.ConvertSelectorToLabelsMap(selector)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid selector '%s': expected comma-separated key=value pairs: %v", selector, err)), nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid path '%s': must start with '/'", path)), nil
+	}
+
+	selectorMap := map[string]interface{}{}
+	for k, v := range podLabels {
+		selectorMap[k] = v
+	}
+	annotations := map[string]interface{}{
+		serviceMCPPortAnnotation:  strconv.Itoa(port),
+		serviceMCPPathAnnotation:  path,
+		serviceMCPProtoAnnotation: serviceProtocols[protocol],
+	}
+	if description != "" {
+		annotations["kagent.dev/description"] = description
+	}
+
+	namespace := ts.kube(ctx).Namespace()
+	service := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata": map[string]interface{}{
+			"name":        name,
+			"namespace":   namespace,
+			"labels":      map[string]interface{}{serviceMCPLabel: "true"},
+			"annotations": annotations,
+		},
+		"spec": map[string]interface{}{
+			"selector": selectorMap,
+			"ports": []interface{}{
+				map[string]interface{}{
+					"name":       "mcp",
+					"port":       port,
+					"targetPort": targetPort,
+					"protocol":   "TCP",
+				},
+			},
+		},
+	}
+
+	output, _ := yaml.Marshal(service)
+
+	header := fmt.Sprintf(`# Generated MCP Service Manifest
+# Agents reach the pods matching '%s' at http://%s.%s.svc.cluster.local:%d%s using %s protocol.
+# Reference it from an agent with {"mcpServer": "%s", "kind": "Service"}.
+# Applying a Service requires KAGENT_APPLY_ALLOWED_KINDS to include Service.`, selector, name, namespace, port, path, protocol, name)
+
+	return out.render(header, withNamespaceDocument(includeNamespace, namespace, string(output)))
+}
+
+// checkServiceReference checks that a Service referenced by tools[i] declares
+// a port it exposes and a usable path.
+func (ts *ToolServer) checkServiceReference(ctx context.Context, field, ref, fromNamespace string) []ValidationIssue {
+	obj, _, err := ts.resolveReference(ctx, kubernetes.ServiceGVR, ref, fromNamespace)
+	if err != nil {
+		return nil
+	}
+	return serviceEndpointIssues(field, obj)
+}
+
+// serviceEndpointIssues checks the MCP endpoint a Service declares: the port
+// in its kagent.dev/mcp-service-port annotation must be one of its ports, or
+// the Service must have a single port, and the path must be absolute.
+func serviceEndpointIssues(field string, obj *unstructured.Unstructured) []ValidationIssue {
+	var issues []ValidationIssue
+	name := obj.GetName()
+	annotations := obj.GetAnnotations()
+
+	var ports []int64
+	raw, _, _ := unstructured.NestedSlice(obj.Object, "spec", "ports")
+	for _, r := range raw {
+		if p, ok := r.(map[string]interface{}); ok {
+			if port, ok, _ := unstructured.NestedInt64(p, "port"); ok {
+				ports = append(ports, port)
+			}
+		}
+	}
+
+	if declared, ok := annotations[serviceMCPPortAnnotation]; ok {
+		port, err := strconv.ParseInt(declared, 10, 64)
+		switch {
+		case err != nil:
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    field,
+				Message:  fmt.Sprintf("Service '%s' has %s=%q, which is not a port number", name, serviceMCPPortAnnotation, declared),
+			})
+		case !containsInt64(ports, port):
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    field,
+				Message:  fmt.Sprintf("Service '%s' declares MCP port %d but exposes %v. Fix %s or add the port to the Service", name, port, ports, serviceMCPPortAnnotation),
+			})
+		}
+	} else {
+		switch len(ports) {
+		case 0:
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    field,
+				Message:  fmt.Sprintf("Service '%s' exposes no ports", name),
+			})
+		case 1:
+		default:
+			issues = append(issues, ValidationIssue{
+				Severity: "warning",
+				Field:    field,
+				Message:  fmt.Sprintf("Service '%s' exposes ports %v but does not say which serves MCP. Set the %s annotation", name, ports, serviceMCPPortAnnotation),
+			})
+		}
+	}
+
+	if path, ok := annotations[serviceMCPPathAnnotation]; ok && !strings.HasPrefix(path, "/") {
+		issues = append(issues, ValidationIssue{
+			Severity: "error",
+			Field:    field,
+			Message:  fmt.Sprintf("Service '%s' has %s=%q, which must start with '/'", name, serviceMCPPathAnnotation, path),
+		})
+	}
+	if protocol, ok := annotations[serviceMCPProtoAnnotation]; ok && protocol != "streamable-http" && protocol != "sse" {
+		issues = append(issues, ValidationIssue{
+			Severity: "warning",
+			Field:    field,
+			Message:  fmt.Sprintf("Service '%s' has %s=%q. Expected 'streamable-http' or 'sse'", name, serviceMCPProtoAnnotation, protocol),
+		})
+	}
+	return issues
+}
+
+// serviceEndpoint describes the MCP endpoint of a Service, as "port N, path
+// P", for reports.
+func serviceEndpoint(obj *unstructured.Unstructured) string {
+	annotations := obj.GetAnnotations()
+	port := annotations[serviceMCPPortAnnotation]
+	if port == "" {
+		raw, _, _ := unstructured.NestedSlice(obj.Object, "spec", "ports")
+		if len(raw) == 1 {
+			if p, ok := raw[0].(map[string]interface{}); ok {
+				n, _, _ := unstructured.NestedInt64(p, "port")
+				port = strconv.FormatInt(n, 10)
+			}
+		}
+	}
+	path := annotations[serviceMCPPathAnnotation]
+	if path == "" {
+		path = defaultServiceMCPPath
+	}
+	return fmt.Sprintf("port %s, path %s", port, path)
+}
+
+func containsInt64(values []int64, v int64) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
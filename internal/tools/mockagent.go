@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/mockagent"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// mockAgentLabel marks agents deployed by deploy_mock_agent, so they can be
+// found and removed together with delete_matching.
+const mockAgentLabel = "kagent.dev/mock"
+
+// mockAgentCommand is the mock agent's entry point in the kmeta-agent image.
+const mockAgentCommand = "/kmeta-mock-agent"
+
+// registerDeployMockAgent registers the deploy_mock_agent tool.
+func (ts *ToolServer) registerDeployMockAgent() {
+	tool := mcp.NewTool("deploy_mock_agent",
+		mcp.WithDescription("Deploy a mock A2A agent that answers without a model: it echoes each message back, or replies with a fixed response. Its Agent Card advertises the given skills, and it implements message/send, tasks/get and tasks/cancel, so A2A wiring, cards, agents-as-tools and run_a2a_conformance can be tried without spending model tokens or building a real agent. The agent is a BYO Agent running the mock that ships in the kmeta-agent image."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the mock agent"),
+		),
+		mcp.WithString("description",
+			mcp.Description("Description shown in the Agent Card"),
+		),
+		mcp.WithString("skills_json",
+			mcp.Description(skillsJSONSchema+". Default: one 'echo' or 'fixed' skill"),
+		),
+		mcp.WithString("mode",
+			mcp.Description("How the agent replies: 'echo' (the text it received) or 'fixed' (the response argument). Default: 'echo'"),
+		),
+		mcp.WithString("response",
+			mcp.Description("Reply text in fixed mode"),
+		),
+		mcp.WithString("delay",
+			mcp.Description("Time to wait before each reply, to simulate a model call (e.g. '2s'; default: none)"),
+		),
+		mcp.WithString("image",
+			mcp.Description("Image to run (default: KAGENT_MOCK_AGENT_IMAGE, the kmeta-agent image)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("If true, validate the agent against the cluster without creating it"),
+		),
+		withPartialOption(),
+	)
+
+	ts.addTool(tool, ts.handleDeployMockAgent)
+}
+
+func (ts *ToolServer) handleDeployMockAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	description := args.StringDefault("description", "Mock A2A agent that answers without a model")
+	skillsJSON := args.String("skills_json")
+	mode := args.Enum("mode", mockagent.ModeEcho, mockagent.ModeEcho, mockagent.ModeFixed)
+	response := args.String("response")
+	delay := args.Duration("delay", 0)
+	image := args.StringDefault("image", ts.server.Config().MockAgentImage)
+	dryRun := args.Bool("dry_run", false)
+	partial := args.Bool("partial", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid name '%s': %v", name, errs)), nil
+	}
+	if image == "" {
+		return mcp.NewToolResultError("No mock agent image: set KAGENT_MOCK_AGENT_IMAGE to the kmeta-agent image, which ships the mock agent, or pass image"), nil
+	}
+	if mode == mockagent.ModeFixed && response == "" {
+		return mcp.NewToolResultError("response is required in fixed mode"), nil
+	}
+
+	var skills []types.Skill
+	var skipped []string
+	if skillsJSON != "" {
+		var err error
+		skills, skipped, err = parseSkills("skills_json", skillsJSON, partial)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	env := []types.EnvVar{
+		{Name: mockagent.EnvName, Value: name},
+		{Name: mockagent.EnvDescription, Value: description},
+		{Name: mockagent.EnvMode, Value: mode},
+	}
+	if len(skills) > 0 {
+		raw, _ := json.Marshal(skills)
+		env = append(env, types.EnvVar{Name: mockagent.EnvSkills, Value: string(raw)})
+	}
+	if response != "" {
+		env = append(env, types.EnvVar{Name: mockagent.EnvResponse, Value: response})
+	}
+	if delay > 0 {
+		env = append(env, types.EnvVar{Name: mockagent.EnvDelay, Value: delay.String()})
+	}
+
+	agent := types.Agent{
+		Spec: types.AgentSpec{
+			Type:        "BYO",
+			Description: description,
+			BYO: &types.BYOSpec{
+				Deployment: &types.BYODeploymentSpec{
+					Image: image,
+					Cmd:   mockAgentCommand,
+					Env:   env,
+					Resources: &types.ResourceRequirements{
+						Requests: map[string]string{"cpu": "10m", "memory": "16Mi"},
+						Limits:   map[string]string{"memory": "64Mi"},
+					},
+				},
+			},
+		},
+	}
+	agent.APIVersion = "kagent.dev/v1alpha2"
+	agent.Kind = "Agent"
+	agent.Name = name
+	agent.Namespace = ts.kube(ctx).Namespace()
+	agent.Labels = map[string]string{mockAgentLabel: "true"}
+	manifest, _ := yaml.Marshal(agent)
+
+	result, err := ts.applyDocument(ctx, string(manifest), dryRun, kubernetes.Preconditions{})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to deploy mock agent: %v", err)), nil
+	}
+
+	verb := fmt.Sprintf("Mock agent '%s' %s in namespace '%s'", name, result.Action, result.Namespace)
+	if dryRun {
+		verb = fmt.Sprintf("Dry run: mock agent '%s' would be %s in namespace '%s'", name, result.Action, result.Namespace)
+	}
+	reply := "echoes each message"
+	if mode == mockagent.ModeFixed {
+		reply = fmt.Sprintf("replies %q", response)
+	}
+	if delay > 0 {
+		reply += fmt.Sprintf(" after %s", delay.Round(time.Millisecond))
+	}
+
+	text := fmt.Sprintf(`# %s
+# It %s, without calling a model. Once Ready (get_agent_status name=%s):
+#   - get_agent_card name=%s shows its card
+#   - run_a2a_conformance name=%s exercises it
+#   - other agents can use it as a tool: {"agent": {"name": "%s"}}
+# Remove every mock agent with delete_matching kind=Agent selector=%s=true.
+%s
+%s`, verb, reply, name, name, name, name, mockAgentLabel, skippedItemsComment(skipped), string(manifest))
+	return mcp.NewToolResultText(text), nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"sigs.k8s.io/yaml"
 
+	"github.com/kagent-dev/meta-kagent/internal/params"
 	"github.com/kagent-dev/meta-kagent/pkg/types"
 )
 
@@ -15,18 +16,44 @@ import (
 func (ts *ToolServer) registerListModelConfigs() {
 	tool := mcp.NewTool("list_model_configs",
 		mcp.WithDescription("List all kagent ModelConfig resources in the namespace. Returns provider, model, and secret reference for each."),
+		withLabelSelectorOption(),
+		withFieldSelectorOption(),
+		withAllNamespacesOption(),
+		withRefreshOption(),
+		withLimitOption(),
+		withContinueOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleListModelConfigs)
+	ts.addTool(tool, ts.handleListModelConfigs)
 }
 
 func (ts *ToolServer) handleListModelConfigs(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	configs, err := ts.k8sClient.ListModelConfigs(ctx)
+	args := params.From(req)
+	opts, selectorErr := listOptionsFrom(args)
+	allNamespaces := args.Bool("all_namespaces", false)
+	refresh := args.Bool("refresh", false)
+	p := ts.pageFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if selectorErr != nil {
+		return mcp.NewToolResultError(selectorErr.Error()), nil
+	}
+	opts.Limit, opts.Continue = int64(p.limit), p.token
+
+	client, err := ts.listClient(ctx, allNamespaces, refresh)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	configs, next, err := client.ListModelConfigsWithOptions(ctx, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to list model configs: %v", err)), nil
 	}
 
 	if len(configs) == 0 {
+		if filter := describeSelectors(opts); filter != "" {
+			return mcp.NewToolResultText(fmt.Sprintf("No ModelConfigs%s found in the namespace.", filter)), nil
+		}
 		return mcp.NewToolResultText("No ModelConfigs found in the namespace. Use create_model_config_manifest to create one."), nil
 	}
 
@@ -43,7 +70,7 @@ func (ts *ToolServer) handleListModelConfigs(ctx context.Context, req mcp.CallTo
 	}
 
 	output, _ := json.MarshalIndent(result, "", "  ")
-	return mcp.NewToolResultText(string(output)), nil
+	return mcp.NewToolResultText(string(output) + continueNote(len(result), next)), nil
 }
 
 // registerCreateModelConfigManifest registers the create_model_config_manifest tool.
@@ -72,52 +99,102 @@ func (ts *ToolServer) registerCreateModelConfigManifest() {
 		mcp.WithString("base_url",
 			mcp.Description("Custom base URL for the API (for Custom provider or proxies)"),
 		),
+		mcp.WithString("temperature",
+			mcp.Description("Sampling temperature as a decimal string (OpenAI and AzureOpenAI: 0 to 2; Anthropic: 0 to 1)"),
+		),
+		mcp.WithString("top_p",
+			mcp.Description("Nucleus sampling probability as a decimal string, 0 to 1 (OpenAI, AzureOpenAI, Anthropic)"),
+		),
+		mcp.WithNumber("max_tokens",
+			mcp.Description("Maximum tokens to generate per response (OpenAI, AzureOpenAI, Anthropic)"),
+		),
+		mcp.WithNumber("top_k",
+			mcp.Description("Sample only from the top K tokens (Anthropic)"),
+		),
+		mcp.WithString("organization",
+			mcp.Description("OpenAI organization ID (OpenAI)"),
+		),
+		mcp.WithString("reasoning_effort",
+			mcp.Description("Reasoning effort of reasoning models: minimal, low, medium or high (OpenAI)"),
+			mcp.Enum(openAIReasoningEfforts...),
+		),
+		mcp.WithString("azure_endpoint",
+			mcp.Description("Azure OpenAI resource endpoint, e.g. https://<resource>.openai.azure.com/ (AzureOpenAI, required)"),
+		),
+		mcp.WithString("azure_api_version",
+			mcp.Description("Azure OpenAI API version, e.g. 2024-06-01 (AzureOpenAI, required)"),
+		),
+		mcp.WithString("azure_deployment",
+			mcp.Description("Azure OpenAI deployment name (AzureOpenAI)"),
+		),
+		mcp.WithString("ollama_host",
+			mcp.Description("Ollama server URL, e.g. http://ollama.ollama.svc:11434 (Ollama)"),
+		),
+		mcp.WithBoolean("include_namespace",
+			mcp.Description("Prepend a Namespace manifest so the bundle can be applied to a cluster where the namespace does not exist yet (default: false)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleCreateModelConfigManifest)
+	ts.addTool(tool, ts.handleCreateModelConfigManifest)
 }
 
 func (ts *ToolServer) handleCreateModelConfigManifest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name, _ := req.Params.Arguments["name"].(string)
-	provider, _ := req.Params.Arguments["provider"].(string)
-	model, _ := req.Params.Arguments["model"].(string)
-	apiKeySecret, _ := req.Params.Arguments["api_key_secret"].(string)
-	apiKeySecretKey, _ := req.Params.Arguments["api_key_secret_key"].(string)
-	baseURL, _ := req.Params.Arguments["base_url"].(string)
-
-	if name == "" || provider == "" || model == "" || apiKeySecret == "" {
-		return mcp.NewToolResultError("name, provider, model, and api_key_secret are required"), nil
+	args := params.From(req)
+	name := args.RequiredString("name")
+	provider := args.RequiredEnum("provider", "OpenAI", "AzureOpenAI", "Anthropic", "Gemini", "Ollama", "Custom")
+	model := args.RequiredString("model")
+	apiKeySecret := args.RequiredString("api_key_secret")
+	apiKeySecretKey := args.String("api_key_secret_key")
+	baseURL := args.String("base_url")
+	settings := providerSettingsFrom(args)
+	includeNamespace := args.Bool("include_namespace", false)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Validate provider
-	validProviders := map[string]bool{
-		"OpenAI":      true,
-		"AzureOpenAI": true,
-		"Anthropic":   true,
-		"Gemini":      true,
-		"Ollama":      true,
-		"Custom":      true,
+	if apiKeySecretKey == "" {
+		apiKeySecretKey = defaultAPIKeySecretKey(provider)
 	}
-	if !validProviders[provider] {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid provider '%s'. Must be one of: OpenAI, AzureOpenAI, Anthropic, Gemini, Ollama, Custom", provider)), nil
+	config := newModelConfig(name, ts.kube(ctx).Namespace(), provider, model, apiKeySecret, apiKeySecretKey, baseURL)
+	if err := settings.apply(&config); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Set default secret key based on provider
-	if apiKeySecretKey == "" {
-		switch provider {
-		case "OpenAI":
-			apiKeySecretKey = "OPENAI_API_KEY"
-		case "Anthropic":
-			apiKeySecretKey = "ANTHROPIC_API_KEY"
-		case "Gemini":
-			apiKeySecretKey = "GOOGLE_API_KEY"
-		case "AzureOpenAI":
-			apiKeySecretKey = "AZURE_OPENAI_API_KEY"
-		default:
-			apiKeySecretKey = "API_KEY"
-		}
+	output, _ := yaml.Marshal(config)
+
+	header := fmt.Sprintf(`# Generated ModelConfig Manifest
+# IMPORTANT: Ensure the Kubernetes Secret '%s' exists with key '%s' containing the API key.
+# Use validate_manifest to check, then apply_manifest to deploy.`, apiKeySecret, apiKeySecretKey)
+	if provider == "AzureOpenAI" && (settings.AzureEndpoint == "" || settings.AzureAPIVersion == "") {
+		header += "\n# WARNING: AzureOpenAI requires azure_endpoint and azure_api_version; fill in spec.azure before applying."
 	}
 
+	return out.render(header, withNamespaceDocument(includeNamespace, config.Namespace, string(output)))
+}
+
+// defaultAPIKeySecretKey is the Secret key conventionally holding a
+// provider's API key.
+func defaultAPIKeySecretKey(provider string) string {
+	switch provider {
+	case "OpenAI":
+		return "OPENAI_API_KEY"
+	case "Anthropic":
+		return "ANTHROPIC_API_KEY"
+	case "Gemini":
+		return "GOOGLE_API_KEY"
+	case "AzureOpenAI":
+		return "AZURE_OPENAI_API_KEY"
+	default:
+		return "API_KEY"
+	}
+}
+
+// newModelConfig builds a ModelConfig with the provider's empty settings
+// block.
+func newModelConfig(name, namespace, provider, model, apiKeySecret, apiKeySecretKey, baseURL string) types.ModelConfig {
 	config := types.ModelConfig{
 		Spec: types.ModelConfigSpec{
 			Provider:        provider,
@@ -130,29 +207,20 @@ func (ts *ToolServer) handleCreateModelConfigManifest(ctx context.Context, req m
 	config.APIVersion = "kagent.dev/v1alpha2"
 	config.Kind = "ModelConfig"
 	config.Name = name
-	config.Namespace = "kagent"
+	config.Namespace = namespace
 
 	// Add provider-specific empty config
 	switch provider {
 	case "OpenAI":
-		config.Spec.OpenAI = map[string]interface{}{}
+		config.Spec.OpenAI = &types.OpenAIConfig{}
 	case "Anthropic":
-		config.Spec.Anthropic = map[string]interface{}{}
+		config.Spec.Anthropic = &types.AnthropicConfig{}
 	case "Gemini":
-		config.Spec.Gemini = map[string]interface{}{}
+		config.Spec.Gemini = &types.GeminiConfig{}
 	case "AzureOpenAI":
-		config.Spec.Azure = map[string]interface{}{}
+		config.Spec.Azure = &types.AzureOpenAIConfig{}
 	case "Ollama":
-		config.Spec.Ollama = map[string]interface{}{}
+		config.Spec.Ollama = &types.OllamaConfig{}
 	}
-
-	output, _ := yaml.Marshal(config)
-
-	result := fmt.Sprintf(`# Generated ModelConfig Manifest
-# IMPORTANT: Ensure the Kubernetes Secret '%s' exists with key '%s' containing the API key.
-# Use validate_manifest to check, then apply_manifest to deploy.
-
-%s`, apiKeySecret, apiKeySecretKey, string(output))
-
-	return mcp.NewToolResultText(result), nil
+	return config
 }
@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/signing"
+	"github.com/kagent-dev/meta-kagent/internal/state"
+)
+
+// Operating modes selectable with KAGENT_MCP_MODE.
+const (
+	ModeApply    = "apply"
+	ModePropose  = "propose"
+	ModeReadOnly = "readonly"
+)
+
+// pendingChangeTTL is how long a proposed change waits for an operator.
+const pendingChangeTTL = 7 * 24 * time.Hour
+
+// offeredInMode reports whether a tool is registered in the server's mode.
+// Read-only servers offer no mutating tool; proposing servers only
+// apply_manifest, which records the change instead of making it.
+func (ts *ToolServer) offeredInMode(name string) bool {
+	switch ts.server.Config().Mode {
+	case ModeReadOnly:
+		return !mutatingTools[name]
+	case ModePropose:
+		return !mutatingTools[name] || name == "apply_manifest"
+	}
+	return true
+}
+
+// dryRunUnavailable returns why server-side dry runs are skipped, or nil
+// where they can run. The API server authorizes a dry run like the write
+// itself, and outside apply mode the server has no write access.
+func (ts *ToolServer) dryRunUnavailable() error {
+	if mode := ts.server.Config().Mode; mode != ModeApply {
+		return fmt.Errorf("server-side dry runs need write access, which the server does not have in %s mode", mode)
+	}
+	return nil
+}
+
+// pendingChange is a change apply_manifest recorded in propose mode, for an
+// operator to review and apply with their own credentials.
+type pendingChange struct {
+	ID        string   `json:"id"`
+	Resources []string `json:"resources"`
+	Reason    string   `json:"reason,omitempty"`
+	// Digest identifies the manifest, as --sign-manifest prints it.
+	Digest string `json:"digest"`
+	// ExpectedResourceVersion and ExpectedFields are the preconditions the
+	// change was proposed with, for the operator to check before applying.
+	ExpectedResourceVersion string                 `json:"expectedResourceVersion,omitempty"`
+	ExpectedFields          map[string]interface{} `json:"expectedFields,omitempty"`
+	Owner                   string                 `json:"owner,omitempty"`
+	CreatedAt               time.Time              `json:"createdAt"`
+	ExpiresAt               time.Time              `json:"expiresAt"`
+	Manifest                string                 `json:"manifest"`
+}
+
+func pendingChangeKey(id string) string {
+	return "pending/" + id
+}
+
+// proposedResources checks docs as an apply would check them, so operators
+// are not handed changes the server would refuse, and names the resources
+// they change.
+func (ts *ToolServer) proposedResources(ctx context.Context, docs []string) ([]string, error) {
+	var resources []string
+	for i, doc := range docs {
+		obj, err := kubernetes.ParseManifest(doc)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+		if err := ts.checkApplyAllowed(obj); err != nil {
+			return nil, err
+		}
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = ts.kube(ctx).Namespace()
+		}
+		resources = append(resources, fmt.Sprintf("%s/%s in namespace '%s'", obj.GetKind(), obj.GetName(), namespace))
+	}
+	return resources, nil
+}
+
+// proposeChange records docs as a pending change instead of applying them.
+// The change is kept in the state store, which with the ConfigMap backend
+// is the one write to the cluster propose mode makes.
+func (ts *ToolServer) proposeChange(ctx context.Context, manifest string, docs []string, reason string, pre kubernetes.Preconditions) (*pendingChange, error) {
+	resources, err := ts.proposedResources(ctx, docs)
+	if err != nil {
+		return nil, err
+	}
+	change := &pendingChange{
+		ID:                      "change-" + randomHex(8),
+		Resources:               resources,
+		Reason:                  reason,
+		Digest:                  signing.Digest(manifest),
+		ExpectedResourceVersion: pre.ResourceVersion,
+		ExpectedFields:          pre.Fields,
+		Owner:                   sessionOwner(ctx),
+		CreatedAt:               time.Now(),
+		Manifest:                manifest,
+	}
+	change.ExpiresAt = change.CreatedAt.Add(pendingChangeTTL)
+
+	if err := state.PutJSON(ctx, ts.state, pendingChangeKey(change.ID), change, pendingChangeTTL); err != nil {
+		return nil, fmt.Errorf("failed to record the pending change: %w", err)
+	}
+	return change, nil
+}
+
+// formatProposedChange tells the caller what was recorded instead of
+// applied.
+func formatProposedChange(change *pendingChange) string {
+	var b strings.Builder
+	b.WriteString("# Change Proposed\n\n")
+	b.WriteString("Nothing was applied: this server runs in propose mode and does not change the cluster's resources. The change was recorded for an operator to review and apply with their own credentials.\n\n")
+	fmt.Fprintf(&b, "Pending change: %s (digest %s)\n", change.ID, change.Digest)
+	for _, resource := range change.Resources {
+		fmt.Fprintf(&b, "- %s\n", resource)
+	}
+	fmt.Fprintf(&b, "\nlist_pending_changes shows the manifest to apply. The change expires on %s.", change.ExpiresAt.UTC().Format(time.RFC3339))
+	return b.String()
+}
+
+// formatProposalDryRun reports what a proposal would record, checked by
+// the server but not by the API server.
+func formatProposalDryRun(resources []string, reason error) string {
+	var b strings.Builder
+	b.WriteString("# Dry Run (checked by this server only)\n\napply_manifest would record a pending change for:\n")
+	for _, resource := range resources {
+		fmt.Fprintf(&b, "- %s\n", resource)
+	}
+	fmt.Fprintf(&b, "\nThe API server was not asked: %v. Use validate_manifest and diff_manifest to review the change, then call apply_manifest with dry_run=false to propose it.", reason)
+	return b.String()
+}
+
+// pendingChanges returns the unexpired changes owner proposed, oldest first.
+func (ts *ToolServer) pendingChanges(ctx context.Context, owner string) ([]pendingChange, error) {
+	values, err := ts.state.List(ctx, pendingChangeKey(""))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []pendingChange{}
+	for _, value := range values {
+		var c pendingChange
+		if json.Unmarshal(value, &c) != nil || c.Owner != owner {
+			continue
+		}
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].CreatedAt.Before(changes[j].CreatedAt) })
+	return changes, nil
+}
+
+// registerListPendingChanges registers the list_pending_changes tool.
+func (ts *ToolServer) registerListPendingChanges() {
+	tool := mcp.NewTool("list_pending_changes",
+		mcp.WithDescription("List the changes apply_manifest proposed while the server runs in propose mode, which an operator has yet to apply: the resources, reason, digest and full manifest of each. Apply a change with your own credentials (e.g., kubectl apply) and then discard it with discard_pending_change."),
+		mcp.WithString("change_id",
+			mcp.Description("Only show this pending change"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleListPendingChanges)
+}
+
+func (ts *ToolServer) handleListPendingChanges(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	changeID := args.String("change_id")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	changes, err := ts.pendingChanges(ctx, sessionOwner(ctx))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list pending changes: %v", err)), nil
+	}
+	if changeID != "" {
+		var found []pendingChange
+		for _, c := range changes {
+			if c.ID == changeID {
+				found = append(found, c)
+			}
+		}
+		if len(found) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Pending change '%s' not found or expired", changeID)), nil
+		}
+		changes = found
+	}
+
+	output, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode pending changes: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// registerDiscardPendingChange registers the discard_pending_change tool.
+func (ts *ToolServer) registerDiscardPendingChange() {
+	tool := mcp.NewTool("discard_pending_change",
+		mcp.WithDescription("Remove a change proposed in propose mode, once an operator has applied it or decided against it. Discarding never changes the cluster."),
+		mcp.WithString("change_id",
+			mcp.Required(),
+			mcp.Description("ID of the pending change, as returned by apply_manifest"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleDiscardPendingChange)
+}
+
+func (ts *ToolServer) handleDiscardPendingChange(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	changeID := args.RequiredString("change_id")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var c pendingChange
+	ok, err := state.GetJSON(ctx, ts.state, pendingChangeKey(changeID), &c)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to look up pending change '%s': %v", changeID, err)), nil
+	}
+	if !ok || c.Owner != sessionOwner(ctx) {
+		return mcp.NewToolResultError(fmt.Sprintf("Pending change '%s' not found or expired", changeID)), nil
+	}
+	if _, err := ts.state.Delete(ctx, pendingChangeKey(changeID)); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to discard pending change '%s': %v", changeID, err)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Pending change '%s' discarded.", changeID)), nil
+}
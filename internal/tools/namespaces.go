@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// registerBootstrapNamespace registers the bootstrap_namespace tool.
+func (ts *ToolServer) registerBootstrapNamespace() {
+	tool := mcp.NewTool("bootstrap_namespace",
+		mcp.WithDescription("Generate a Namespace manifest for kagent resources and report whether the namespace already exists. Use this when apply fails because the target namespace is missing."),
+		mcp.WithString("name",
+			mcp.Description("Namespace name (default: the server's namespace)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.server.AddTool(tool, ts.handleBootstrapNamespace)
+}
+
+func (ts *ToolServer) handleBootstrapNamespace(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.StringDefault("name", ts.kube(ctx).Namespace())
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	exists, known, err := ts.kube(ctx).NamespaceExists(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to check namespace: %v", err)), nil
+	}
+
+	var status string
+	switch {
+	case !known:
+		status = fmt.Sprintf("# Could not verify whether namespace '%s' exists (not allowed to read namespaces).", name)
+	case exists:
+		status = fmt.Sprintf("# Namespace '%s' already exists. No action is needed.", name)
+	default:
+		status = fmt.Sprintf("# Namespace '%s' does not exist. Apply this manifest before applying resources into it.", name)
+	}
+
+	header := fmt.Sprintf(`# Generated Namespace Manifest
+%s
+# Applying it with apply_manifest requires Namespace in KAGENT_APPLY_ALLOWED_KINDS;
+# otherwise apply it with kubectl.`, status)
+
+	return out.render(header, namespaceManifest(name))
+}
+
+// namespaceManifest returns a Namespace manifest labeled for kagent.
+func namespaceManifest(name string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s
+  labels:
+    app.kubernetes.io/part-of: kagent
+`, name)
+}
+
+// withNamespaceDocument prepends a Namespace document to a generated manifest
+// when include is set, so the bundle can be applied to a fresh cluster.
+func withNamespaceDocument(include bool, namespace, manifest string) string {
+	if !include {
+		return manifest
+	}
+	return namespaceManifest(namespace) + "---\n" + manifest
+}
+
+// checkNamespace returns a validation issue if the manifest's namespace does
+// not exist and the bundle does not create it. Unverifiable namespaces (no
+// read access) are not reported.
+func (ts *ToolServer) checkNamespace(ctx context.Context, namespace string) []ValidationIssue {
+	if namespace == "" {
+		namespace = ts.kube(ctx).Namespace()
+	}
+	if bundleDefines(ctx, "Namespace", namespace, "") {
+		return nil
+	}
+
+	exists, known, err := ts.kube(ctx).NamespaceExists(ctx, namespace)
+	if err != nil || !known || exists {
+		return nil
+	}
+
+	return []ValidationIssue{{
+		Severity: "error",
+		Field:    "metadata.namespace",
+		Message:  fmt.Sprintf("Namespace '%s' does not exist. Use bootstrap_namespace to generate it before applying.", namespace),
+	}}
+}
@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/diff"
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// Output formats accepted by generator tools, besides OutputStructured,
+// which they share with validation and diff tools.
+const (
+	// OutputAnnotated is YAML preceded by a comment preamble with review hints.
+	OutputAnnotated = "annotated"
+	// OutputYAML is plain YAML that can be piped to kubectl or committed to Git.
+	OutputYAML = "yaml"
+	// OutputJSON is a JSON object, or a v1 List when there are several resources.
+	OutputJSON = "json"
+)
+
+// serverDefaults are fields that generators set to the value the API server
+// would default anyway, keyed by kind and dotted field path.
+var serverDefaults = map[string]map[string]interface{}{
+	"Agent": {
+		"spec.type": "Declarative",
+	},
+	"RemoteMCPServer": {
+		"spec.protocol":         "STREAMABLE_HTTP",
+		"spec.timeout":          "30s",
+		"spec.sseReadTimeout":   "5m0s",
+		"spec.terminateOnClose": true,
+	},
+}
+
+// outputOptions controls how a generator renders its manifests.
+type outputOptions struct {
+	format        string
+	stripDefaults bool
+}
+
+// withOutputFormatOption adds the output_format argument to a generator.
+func withOutputFormatOption() mcp.ToolOption {
+	return mcp.WithString("output_format",
+		mcp.Description("Output format: 'annotated' (YAML with a review comment preamble), 'yaml' (plain YAML, ready for kubectl or GitOps), or 'json' (a JSON object, or a v1 List for several resources), or 'structured' (a JSON object with separate summary, manifest, objects, warnings and diffId fields, for agents and scripts). Default: 'annotated'"),
+	)
+}
+
+// withStripDefaultsOption adds the strip_defaults argument to a generator.
+func withStripDefaultsOption() mcp.ToolOption {
+	return mcp.WithBoolean("strip_defaults",
+		mcp.Description("Omit empty fields and fields set to the value the API server defaults anyway (default: false)"),
+	)
+}
+
+// withDiffFormatOption adds the diff_format argument to a tool showing a
+// diff.
+func withDiffFormatOption() mcp.ToolOption {
+	return mcp.WithString("diff_format",
+		mcp.Description("How to show the changes: 'unified' (unified diff of the YAML, for terminals), 'semantic' (one line per added, removed or changed field path, e.g. '~ spec.declarative.modelConfig: \"a\" → \"b\"'), 'side-by-side' (markdown table of each changed field before and after, for chat UIs) or 'json-patch' (RFC 6902 JSON Patch, for automation). Default: 'unified'"),
+	)
+}
+
+// diffRendererFrom reads the diff_format argument of a call.
+func diffRendererFrom(args *params.Args) diff.Renderer {
+	r, _ := diff.For(args.Enum("diff_format", diff.Unified, diff.Formats()...))
+	return r
+}
+
+// outputOptionsFrom reads the output arguments of a generator call.
+func outputOptionsFrom(args *params.Args) outputOptions {
+	return outputOptions{
+		format:        args.Enum("output_format", OutputAnnotated, OutputAnnotated, OutputYAML, OutputJSON, OutputStructured),
+		stripDefaults: args.Bool("strip_defaults", false),
+	}
+}
+
+// render returns generated manifests in the requested format. header is the
+// comment preamble shown in the annotated format only.
+func (o outputOptions) render(header, manifests string) (*mcp.CallToolResult, error) {
+	// Keep the generated text, including inline comments, when nothing changes
+	if !o.stripDefaults {
+		switch o.format {
+		case OutputAnnotated:
+			return mcp.NewToolResultText(header + "\n\n" + manifests), nil
+		case OutputYAML:
+			return mcp.NewToolResultText(strings.Join(kubernetes.SplitManifests(manifests), "---\n")), nil
+		}
+	}
+
+	var objects []map[string]interface{}
+	for _, doc := range kubernetes.SplitManifests(manifests) {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to render manifest: %v", err)), nil
+		}
+		if o.stripDefaults {
+			stripDefaults(obj)
+		}
+		objects = append(objects, obj)
+	}
+
+	if o.format == OutputJSON {
+		var value interface{} = objects
+		if len(objects) == 1 {
+			value = objects[0]
+		} else {
+			value = map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "List",
+				"items":      objects,
+			}
+		}
+		output, _ := json.MarshalIndent(value, "", "  ")
+		return mcp.NewToolResultText(string(output)), nil
+	}
+
+	docs := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		output, _ := yaml.Marshal(obj)
+		docs = append(docs, string(output))
+	}
+	body := strings.Join(docs, "---\n")
+	if o.format == OutputStructured {
+		r := structuredFromHeader(header)
+		r.Manifest = body
+		r.Objects = objects
+		return structured(r)
+	}
+	if o.format == OutputAnnotated {
+		body = header + "\n\n" + body
+	}
+	return mcp.NewToolResultText(body), nil
+}
+
+// stripDefaults removes server-defaulted fields, then empty values, from a
+// manifest.
+func stripDefaults(obj map[string]interface{}) {
+	kind, _ := obj["kind"].(string)
+	for field, def := range serverDefaults[kind] {
+		path := strings.Split(field, ".")
+		value, found, _ := unstructured.NestedFieldNoCopy(obj, path...)
+		if found && value == def {
+			unstructured.RemoveNestedField(obj, path...)
+		}
+	}
+	pruneEmpty(obj)
+}
+
+// pruneEmpty removes nil values and empty maps and lists, recursively.
+func pruneEmpty(obj map[string]interface{}) {
+	for key, value := range obj {
+		switch v := value.(type) {
+		case nil:
+			delete(obj, key)
+		case map[string]interface{}:
+			pruneEmpty(v)
+			if len(v) == 0 {
+				delete(obj, key)
+			}
+		case []interface{}:
+			for _, item := range v {
+				if m, ok := item.(map[string]interface{}); ok {
+					pruneEmpty(m)
+				}
+			}
+			if len(v) == 0 {
+				delete(obj, key)
+			}
+		}
+	}
+}
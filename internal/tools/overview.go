@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// ClusterOverview is an aggregated snapshot of the kagent resources in a
+// namespace.
+type ClusterOverview struct {
+	Namespace string `json:"namespace"`
+	// ResourceVersion is the version the resources were read at.
+	ResourceVersion string `json:"resourceVersion"`
+	// Consistent is false when the kinds could not be read at one version,
+	// so a change made during the read may be only partly reflected.
+	Consistent bool `json:"consistent"`
+	Agents     struct {
+		Total  int            `json:"total"`
+		Ready  int            `json:"ready"`
+		ByType map[string]int `json:"byType"`
+		// NotReady lists the agents the controller does not report Ready.
+		NotReady []string `json:"notReady,omitempty"`
+	} `json:"agents"`
+	ModelConfigs struct {
+		Total      int            `json:"total"`
+		ByProvider map[string]int `json:"byProvider"`
+	} `json:"modelConfigs"`
+	MCPServers struct {
+		Total       int            `json:"total"`
+		ByTransport map[string]int `json:"byTransport"`
+	} `json:"mcpServers"`
+	// Orphaned lists the ModelConfigs and MCP servers no agent uses, as
+	// "Kind/name".
+	Orphaned   []string            `json:"orphaned"`
+	Validation *OverviewValidation `json:"validation,omitempty"`
+}
+
+// OverviewValidation counts the validation issues of every resource in the
+// namespace.
+type OverviewValidation struct {
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Issues   []groupedIssue `json:"issues,omitempty"`
+}
+
+// registerClusterOverview registers the cluster_overview tool.
+func (ts *ToolServer) registerClusterOverview() {
+	tool := mcp.NewTool("cluster_overview",
+		mcp.WithDescription("Summarize the kagent resources of the namespace in one call: agents by type and readiness, ModelConfigs by provider, MCP servers by transport, ModelConfigs and MCP servers no agent uses, and the validation errors and warnings of every resource. Start here to get the lay of the land before drilling down with list_agents, get_agent_status or validate_manifest."),
+		mcp.WithBoolean("validate",
+			mcp.Description("Run validate_manifest's checks on every resource and summarize the issues (default: true). Turn off for a faster answer in large namespaces"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleClusterOverview)
+}
+
+func (ts *ToolServer) handleClusterOverview(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	validate := args.Bool("validate", true)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client := ts.kube(ctx)
+	overview := &ClusterOverview{Namespace: client.Namespace(), Orphaned: []string{}}
+	overview.Agents.ByType = map[string]int{}
+	overview.ModelConfigs.ByProvider = map[string]int{}
+	overview.MCPServers.ByTransport = map[string]int{}
+
+	// Read every kind at one version, so that no agent is counted as using
+	// a server the same overview reports as missing or orphaned
+	snapshot, err := client.ListSnapshot(ctx, kagentGVRs()...)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list resources: %v", err)), nil
+	}
+	overview.ResourceVersion = snapshot.ResourceVersion
+	overview.Consistent = snapshot.Consistent
+	index := kubernetes.IndexReferences(snapshot)
+
+	var issues []resourceIssue
+	for _, k := range staleKinds {
+		items := snapshot.Items(k.GVR)
+		for i := range items {
+			obj := &items[i]
+			switch k.Kind {
+			case "Agent":
+				overview.Agents.Total++
+				overview.Agents.ByType[stringOr(obj, "Declarative", "spec", "type")]++
+				if status, _ := conditionStatus(obj, "Ready"); status == "True" {
+					overview.Agents.Ready++
+				} else {
+					overview.Agents.NotReady = append(overview.Agents.NotReady, obj.GetName())
+				}
+			case "ModelConfig":
+				overview.ModelConfigs.Total++
+				overview.ModelConfigs.ByProvider[stringOr(obj, "unknown", "spec", "provider")]++
+			case "MCPServer":
+				overview.MCPServers.Total++
+				overview.MCPServers.ByTransport[stringOr(obj, "stdio", "spec", "transportType")]++
+			case "RemoteMCPServer":
+				overview.MCPServers.Total++
+				overview.MCPServers.ByTransport[stringOr(obj, "STREAMABLE_HTTP", "spec", "protocol")]++
+			}
+
+			if k.Kind != "Agent" && len(index.ReferencesTo(k.Kind, obj.GetNamespace(), obj.GetName())) == 0 {
+				overview.Orphaned = append(overview.Orphaned, k.Kind+"/"+obj.GetName())
+			}
+			if validate {
+				for _, issue := range ts.validateObject(ctx, obj, true, false) {
+					issues = append(issues, resourceIssue{Resource: k.Kind + "/" + obj.GetName(), Issue: issue})
+				}
+			}
+		}
+	}
+
+	if validate {
+		overview.Validation = &OverviewValidation{Issues: dedupeIssues(issues)}
+		for _, ri := range issues {
+			if ri.Issue.Severity == "error" {
+				overview.Validation.Errors++
+			} else {
+				overview.Validation.Warnings++
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Cluster Overview: %s\n\n", overview.Namespace)
+	fmt.Fprintf(&b, "Agents: %d (%d ready, %d not ready%s)\n", overview.Agents.Total, overview.Agents.Ready, len(overview.Agents.NotReady), formatCounts(overview.Agents.ByType))
+	fmt.Fprintf(&b, "ModelConfigs: %d%s\n", overview.ModelConfigs.Total, formatCounts(overview.ModelConfigs.ByProvider))
+	fmt.Fprintf(&b, "MCP servers: %d%s\n", overview.MCPServers.Total, formatCounts(overview.MCPServers.ByTransport))
+	fmt.Fprintf(&b, "Orphaned: %d", len(overview.Orphaned))
+	if len(overview.Orphaned) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(overview.Orphaned, ", "))
+	}
+	b.WriteString("\n")
+	if overview.Validation != nil {
+		fmt.Fprintf(&b, "Validation: %d error(s), %d warning(s)\n", overview.Validation.Errors, overview.Validation.Warnings)
+	}
+	if !overview.Consistent {
+		b.WriteString("Note: the API server could not serve a consistent snapshot; resources changed during the read may be partly reflected.\n")
+	}
+
+	output, _ := json.MarshalIndent(overview, "", "  ")
+	return mcp.NewToolResultText(b.String() + "\n" + string(output)), nil
+}
+
+// stringOr returns the string at fields of obj, or def when it is unset.
+func stringOr(obj *unstructured.Unstructured, def string, fields ...string) string {
+	if s, _, _ := unstructured.NestedString(obj.Object, fields...); s != "" {
+		return s
+	}
+	return def
+}
+
+// formatCounts renders counts as "; a 2, b 1", ordered by key, or nothing
+// when there are none.
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(counts))
+	for _, k := range sortedKeys(counts) {
+		parts = append(parts, fmt.Sprintf("%s %d", k, counts[k]))
+	}
+	return "; " + strings.Join(parts, ", ")
+}
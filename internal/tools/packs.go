@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	mcpserver "github.com/kagent-dev/meta-kagent/internal/server"
+	"github.com/kagent-dev/meta-kagent/pkg/toolpack"
+)
+
+// RegisterPacks registers the tools of external tool packs, prefixing each
+// tool name with its pack name. It must run after RegisterAll: a pack tool
+// whose name is taken by a built-in tool or another pack is refused. Packs
+// run arbitrary code the server cannot vet, so they are only offered in
+// apply mode.
+func RegisterPacks(ctx context.Context, s *mcpserver.Server, packs []toolpack.Pack) error {
+	if mode := s.Config().Mode; mode != ModeApply && len(packs) > 0 {
+		fmt.Fprintf(os.Stderr, "Tool packs are not offered in %s mode\n", mode)
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, pack := range packs {
+		name := pack.Name()
+		if err := toolpack.ValidateName(name); err != nil {
+			return err
+		}
+		if seen[name] {
+			return fmt.Errorf("tool pack %q is registered more than once", name)
+		}
+		seen[name] = true
+
+		packTools, err := pack.Tools(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load tool pack %q: %w", name, err)
+		}
+
+		for _, t := range packTools {
+			tool := t.Tool
+			tool.Name = toolpack.ToolName(name, tool.Name)
+			if s.ToolNameTaken(tool.Name) {
+				return fmt.Errorf("tool pack %q: tool %q collides with an already registered tool", name, tool.Name)
+			}
+			tool.Description = fmt.Sprintf("[%s] %s", name, tool.Description)
+			s.AddTool(tool, t.Handler)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// defaultPageSize is the page size of list tools unless KAGENT_LIST_PAGE_SIZE
+// sets another: small enough that a page fits comfortably in a client's
+// context window.
+const defaultPageSize = 50
+
+// maxPageSize bounds the limit argument of list tools.
+const maxPageSize = 500
+
+// offsetContinuePrefix marks continue tokens of lists paged by the tool
+// rather than the API server.
+const offsetContinuePrefix = "offset:"
+
+// withLimitOption adds the limit argument to a paginated list tool.
+func withLimitOption() mcp.ToolOption {
+	return mcp.WithNumber("limit",
+		mcp.Description(fmt.Sprintf("Maximum number of items to return (default: %d, or KAGENT_LIST_PAGE_SIZE; at most %d). When more remain, the result ends with a continue token", defaultPageSize, maxPageSize)),
+	)
+}
+
+// withContinueOption adds the continue argument to a paginated list tool.
+func withContinueOption() mcp.ToolOption {
+	return mcp.WithString("continue",
+		mcp.Description("Continue token from the previous page, to fetch the next one. Pass the same filters as for the first page"),
+	)
+}
+
+// page is the limit and continue token of a list tool call.
+type page struct {
+	limit int
+	token string
+}
+
+// pageFrom reads the limit and continue arguments.
+func (ts *ToolServer) pageFrom(args *params.Args) page {
+	size := ts.server.Config().ListPageSize
+	if size < 1 || size > maxPageSize {
+		size = defaultPageSize
+	}
+	return page{
+		limit: args.IntRange("limit", size, 1, maxPageSize),
+		token: args.String("continue"),
+	}
+}
+
+// paginate returns the page of items p asks for and the token to continue
+// from, or "" on the last page. It pages by position, for lists the tool
+// assembles itself; lists read from the API server page there instead.
+func paginate[T any](items []T, p page) ([]T, string, error) {
+	offset := 0
+	if p.token != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(p.token)
+		if err != nil || !strings.HasPrefix(string(raw), offsetContinuePrefix) {
+			return nil, "", fmt.Errorf("invalid continue token: pass the token returned by the previous page of this tool")
+		}
+		offset, err = strconv.Atoi(strings.TrimPrefix(string(raw), offsetContinuePrefix))
+		if err != nil || offset < 0 {
+			return nil, "", fmt.Errorf("invalid continue token: pass the token returned by the previous page of this tool")
+		}
+	}
+	if offset >= len(items) {
+		return nil, "", nil
+	}
+	items = items[offset:]
+	if len(items) <= p.limit {
+		return items, "", nil
+	}
+	next := offsetContinuePrefix + strconv.Itoa(offset+p.limit)
+	return items[:p.limit], base64.RawURLEncoding.EncodeToString([]byte(next)), nil
+}
+
+// continueNote is appended to a page of results when more remain.
+func continueNote(count int, next string) string {
+	if next == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n# Showing %d items; more remain. Call again with continue='%s' (and the same filters) for the next page.", count, next)
+}
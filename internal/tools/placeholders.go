@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// secretPlaceholderPattern matches ${SECRET:<secret-name>:<key>} placeholders.
+// Bundles use them to point at credentials without embedding them.
+var secretPlaceholderPattern = regexp.MustCompile(`\$\{SECRET:([a-z0-9]([-a-z0-9.]*[a-z0-9])?):([-._a-zA-Z0-9]+)\}`)
+
+// secretPlaceholderPrefix starts every placeholder, well-formed or not.
+const secretPlaceholderPrefix = "${SECRET:"
+
+// secretPlaceholder is a secret reference found in a manifest.
+type secretPlaceholder struct {
+	Secret string
+	Key    string
+	Field  string // dotted path of the field holding the placeholder
+	Raw    string // the placeholder text as written
+}
+
+// findSecretPlaceholders returns the placeholders in obj, and the fields that
+// contain a malformed placeholder.
+func findSecretPlaceholders(obj map[string]interface{}) (found []secretPlaceholder, malformed []string) {
+	var walk func(value interface{}, field string)
+	walk = func(value interface{}, field string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for k, child := range v {
+				walk(child, joinField(field, k))
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(child, fmt.Sprintf("%s[%d]", field, i))
+			}
+		case string:
+			matches := secretPlaceholderPattern.FindAllStringSubmatch(v, -1)
+			for _, m := range matches {
+				found = append(found, secretPlaceholder{Secret: m[1], Key: m[3], Field: field, Raw: m[0]})
+			}
+			if strings.Count(v, secretPlaceholderPrefix) > len(matches) {
+				malformed = append(malformed, field)
+			}
+		}
+	}
+	walk(obj, "")
+	return found, malformed
+}
+
+func joinField(parent, child string) string {
+	if parent == "" {
+		return child
+	}
+	return parent + "." + child
+}
+
+// checkSecretPlaceholders verifies that every secret and key referenced by a
+// placeholder exists. Only key names are read, never secret values.
+func (ts *ToolServer) checkSecretPlaceholders(ctx context.Context, obj *unstructured.Unstructured) []ValidationIssue {
+	var issues []ValidationIssue
+
+	found, malformed := findSecretPlaceholders(obj.Object)
+	for _, field := range malformed {
+		issues = append(issues, ValidationIssue{
+			Severity: "error",
+			Field:    field,
+			Message:  "malformed secret placeholder. Expected ${SECRET:<secret-name>:<key>}",
+		})
+	}
+
+	for _, p := range found {
+		if !isReferenceField(obj, p) {
+			issues = append(issues, ValidationIssue{
+				Severity: "warning",
+				Field:    p.Field,
+				Message:  fmt.Sprintf("%s cannot be turned into a secret reference here and will be applied as literal text. Placeholders are supported in ModelConfig spec.apiKeySecret and env[].value", p.Raw),
+			})
+		}
+
+		keys, exists, known, err := ts.kube(ctx).SecretKeys(ctx, p.Secret)
+		switch {
+		case err != nil:
+			issues = append(issues, ValidationIssue{
+				Severity: "warning",
+				Field:    p.Field,
+				Message:  fmt.Sprintf("Could not verify secret '%s': %v", p.Secret, err),
+			})
+		case !known:
+			issues = append(issues, ValidationIssue{
+				Severity: "warning",
+				Field:    p.Field,
+				Message:  fmt.Sprintf("Could not verify secret '%s' (not allowed to read secrets)", p.Secret),
+			})
+		case !exists:
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    p.Field,
+				Message:  fmt.Sprintf("Secret '%s' referenced by %s does not exist", p.Secret, p.Raw),
+			})
+		case !containsString(keys, p.Key):
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    p.Field,
+				Message:  fmt.Sprintf("Secret '%s' has no key '%s'.%s", p.Secret, p.Key, keyHint(keys, p.Key)),
+			})
+		}
+	}
+
+	return issues
+}
+
+// isReferenceField reports whether the placeholder fills a whole field that
+// resolveSecretPlaceholders can rewrite into a native secret reference.
+func isReferenceField(obj *unstructured.Unstructured, p secretPlaceholder) bool {
+	if obj.GetKind() == "ModelConfig" && p.Field == "spec.apiKeySecret" {
+		value, _, _ := unstructured.NestedString(obj.Object, "spec", "apiKeySecret")
+		return value == p.Raw
+	}
+	return envValuePlaceholder(obj.Object, p) != nil
+}
+
+// resolveSecretPlaceholders rewrites placeholders into secret references the
+// cluster understands, so credentials stay in the Secret:
+//
+//   - ModelConfig spec.apiKeySecret becomes apiKeySecret/apiKeySecretKey
+//   - env[].value becomes env[].valueFrom.secretKeyRef
+//
+// Placeholders elsewhere are left as they are. It reports whether obj changed.
+func resolveSecretPlaceholders(obj *unstructured.Unstructured) bool {
+	found, _ := findSecretPlaceholders(obj.Object)
+	changed := false
+
+	for _, p := range found {
+		if p.Field == "spec.apiKeySecret" && isReferenceField(obj, p) {
+			_ = unstructured.SetNestedField(obj.Object, p.Secret, "spec", "apiKeySecret")
+			_ = unstructured.SetNestedField(obj.Object, p.Key, "spec", "apiKeySecretKey")
+			changed = true
+			continue
+		}
+
+		if env := envValuePlaceholder(obj.Object, p); env != nil {
+			delete(env, "value")
+			env["valueFrom"] = map[string]interface{}{
+				"secretKeyRef": map[string]interface{}{
+					"name": p.Secret,
+					"key":  p.Key,
+				},
+			}
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// envValuePlaceholder returns the env var entry whose value is exactly the
+// placeholder, or nil if the placeholder is not an env var value.
+func envValuePlaceholder(obj map[string]interface{}, p secretPlaceholder) map[string]interface{} {
+	if !strings.HasSuffix(p.Field, ".value") || !strings.Contains(p.Field, "env[") {
+		return nil
+	}
+
+	// Walk the dotted path down to the env entry
+	var current interface{} = obj
+	segments := strings.Split(strings.TrimSuffix(p.Field, ".value"), ".")
+	for _, segment := range segments {
+		name, index := segment, -1
+		if i := strings.Index(segment, "["); i >= 0 {
+			name = segment[:i]
+			fmt.Sscanf(segment[i:], "[%d]", &index)
+		}
+
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[name]
+		if index >= 0 {
+			list, ok := current.([]interface{})
+			if !ok || index >= len(list) {
+				return nil
+			}
+			current = list[index]
+		}
+	}
+
+	env, ok := current.(map[string]interface{})
+	if !ok || env["value"] != p.Raw {
+		return nil
+	}
+	return env
+}
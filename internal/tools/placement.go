@@ -0,0 +1,339 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// Annotations recording model provider capacity and expected agent load.
+const (
+	rateLimitAnnotation   = "kagent.dev/rate-limit-rpm"
+	expectedRPMAnnotation = "kagent.dev/expected-rpm"
+)
+
+// defaultAgentRPM is the load assumed for agents without an expected-rpm
+// annotation.
+const defaultAgentRPM = 10
+
+// sizing is a container resource profile.
+type sizing struct {
+	RequestsCPU    string `json:"requestsCpu"`
+	RequestsMemory string `json:"requestsMemory"`
+	LimitsCPU      string `json:"limitsCpu"`
+	LimitsMemory   string `json:"limitsMemory"`
+}
+
+// sizingPresets are the recommended resource profiles per kind and size.
+var sizingPresets = map[string]map[string]sizing{
+	"Agent": {
+		"small":  {"100m", "256Mi", "500m", "512Mi"},
+		"medium": {"250m", "512Mi", "1", "1Gi"},
+		"large":  {"500m", "1Gi", "2", "2Gi"},
+	},
+	"MCPServer": {
+		"small":  {"50m", "128Mi", "250m", "256Mi"},
+		"medium": {"100m", "256Mi", "500m", "512Mi"},
+		"large":  {"250m", "512Mi", "1", "1Gi"},
+	},
+}
+
+// quantities maps the profile onto quota resource names.
+func (s sizing) quantities() map[string]resource.Quantity {
+	return map[string]resource.Quantity{
+		"requests.cpu":    resource.MustParse(s.RequestsCPU),
+		"cpu":             resource.MustParse(s.RequestsCPU),
+		"requests.memory": resource.MustParse(s.RequestsMemory),
+		"memory":          resource.MustParse(s.RequestsMemory),
+		"limits.cpu":      resource.MustParse(s.LimitsCPU),
+		"limits.memory":   resource.MustParse(s.LimitsMemory),
+		"pods":            resource.MustParse("1"),
+	}
+}
+
+// NamespacePlacement is the capacity assessment of a candidate namespace.
+type NamespacePlacement struct {
+	Namespace string   `json:"namespace"`
+	Fits      bool     `json:"fits"`
+	Issues    []string `json:"issues,omitempty"`
+	Notes     []string `json:"notes,omitempty"`
+}
+
+// ModelConfigCapacity is the rate-limit headroom of a ModelConfig.
+type ModelConfigCapacity struct {
+	Name        string `json:"name"`
+	Provider    string `json:"provider"`
+	Agents      int    `json:"agents"`
+	LimitRPM    int    `json:"limitRpm,omitempty"`
+	ExpectedRPM int    `json:"expectedRpm"`
+	HeadroomRPM *int   `json:"headroomRpm,omitempty"`
+}
+
+// PlacementAdvice is the result of advise_agent_placement.
+type PlacementAdvice struct {
+	Kind                 string                `json:"kind"`
+	Size                 string                `json:"size"`
+	Sizing               sizing                `json:"sizing"`
+	RecommendedNamespace string                `json:"recommendedNamespace,omitempty"`
+	RecommendedModel     string                `json:"recommendedModelConfig,omitempty"`
+	Namespaces           []NamespacePlacement  `json:"namespaces"`
+	ModelConfigs         []ModelConfigCapacity `json:"modelConfigs,omitempty"`
+	Nodes                []string              `json:"nodes,omitempty"`
+	Recommendations      []string              `json:"recommendations"`
+}
+
+// registerAdvisePlacement registers the advise_agent_placement tool.
+func (ts *ToolServer) registerAdvisePlacement() {
+	tool := mcp.NewTool("advise_agent_placement",
+		mcp.WithDescription("Before generating a new agent or MCP server, check namespace ResourceQuotas, ModelConfig rate-limit headroom and node capacity, and recommend a namespace, resource sizing and ModelConfig. ModelConfig capacity comes from the 'kagent.dev/rate-limit-rpm' annotation; agent load from 'kagent.dev/expected-rpm'."),
+		mcp.WithString("kind",
+			mcp.Description("Resource to place: 'Agent' or 'MCPServer'. Default: 'Agent'"),
+		),
+		mcp.WithString("size",
+			mcp.Description("Sizing profile: 'small', 'medium' or 'large'. Default: 'small'"),
+		),
+		mcp.WithString("namespaces",
+			mcp.Description("Comma-separated candidate namespaces (default: the server's namespace)"),
+		),
+		mcp.WithNumber("expected_rpm",
+			mcp.Description("Expected model requests per minute of the new agent (default: 10)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleAdvisePlacement)
+}
+
+func (ts *ToolServer) handleAdvisePlacement(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.Enum("kind", "Agent", "Agent", "MCPServer")
+	size := args.Enum("size", "small", "small", "medium", "large")
+	namespaceList := args.StringDefault("namespaces", ts.kube(ctx).Namespace())
+	expectedRPM := args.IntRange("expected_rpm", defaultAgentRPM, 0, 1000000)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	profile := sizingPresets[kind][size]
+	advice := PlacementAdvice{Kind: kind, Size: size, Sizing: profile}
+
+	for _, namespace := range strings.Split(namespaceList, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace == "" {
+			continue
+		}
+		placement, err := ts.assessNamespace(ctx, namespace, profile)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to assess namespace '%s': %v", namespace, err)), nil
+		}
+		advice.Namespaces = append(advice.Namespaces, placement)
+		if placement.Fits && advice.RecommendedNamespace == "" {
+			advice.RecommendedNamespace = namespace
+		}
+	}
+	if advice.RecommendedNamespace != "" {
+		advice.Recommendations = append(advice.Recommendations, fmt.Sprintf("Create the %s in namespace '%s' with requests %s CPU / %s memory and limits %s CPU / %s memory.",
+			kind, advice.RecommendedNamespace, profile.RequestsCPU, profile.RequestsMemory, profile.LimitsCPU, profile.LimitsMemory))
+	} else {
+		advice.Recommendations = append(advice.Recommendations, "No candidate namespace has room for this sizing. Choose a smaller size, raise the ResourceQuota, or pick another namespace.")
+	}
+
+	nodes, known, err := ts.kube(ctx).Nodes(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read node capacity: %v", err)), nil
+	}
+	if known {
+		advice.Nodes, advice.Recommendations = assessNodes(nodes, profile, advice.Recommendations)
+	} else {
+		advice.Recommendations = append(advice.Recommendations, "Node capacity was not checked (not allowed to list nodes).")
+	}
+
+	if kind == "Agent" {
+		capacities, err := ts.modelConfigCapacity(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to assess model configs: %v", err)), nil
+		}
+		advice.ModelConfigs = capacities
+		advice.RecommendedModel, advice.Recommendations = recommendModelConfig(capacities, expectedRPM, advice.Recommendations)
+	}
+
+	output, _ := json.MarshalIndent(advice, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// assessNamespace checks that the namespace exists and that its quotas have
+// room for one more workload of the given sizing.
+func (ts *ToolServer) assessNamespace(ctx context.Context, namespace string, profile sizing) (NamespacePlacement, error) {
+	placement := NamespacePlacement{Namespace: namespace, Fits: true}
+
+	exists, known, err := ts.kube(ctx).NamespaceExists(ctx, namespace)
+	if err != nil {
+		return placement, err
+	}
+	if known && !exists {
+		placement.Fits = false
+		placement.Issues = append(placement.Issues, "Namespace does not exist. Use bootstrap_namespace to create it.")
+		return placement, nil
+	}
+
+	quotas, known, err := ts.kube(ctx).ResourceQuotas(ctx, namespace)
+	if err != nil {
+		return placement, err
+	}
+	if !known {
+		placement.Notes = append(placement.Notes, "ResourceQuotas were not checked (not allowed to list them).")
+		return placement, nil
+	}
+	if len(quotas) == 0 {
+		placement.Notes = append(placement.Notes, "No ResourceQuota limits this namespace.")
+		return placement, nil
+	}
+
+	needed := profile.quantities()
+	for _, quota := range quotas {
+		names := make([]string, 0, len(quota.Hard))
+		for name := range quota.Hard {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			need, ok := needed[name]
+			if !ok {
+				continue
+			}
+			remaining := quota.Hard[name].DeepCopy()
+			used := quota.Used[name]
+			remaining.Sub(used)
+			if remaining.Cmp(need) < 0 {
+				placement.Fits = false
+				placement.Issues = append(placement.Issues, fmt.Sprintf("ResourceQuota '%s': %s has %s left, %s needed.", quota.Name, name, remaining.String(), need.String()))
+			}
+		}
+	}
+	return placement, nil
+}
+
+// assessNodes reports whether any schedulable node can fit the sizing limits.
+func assessNodes(nodes []kubernetes.NodeCapacity, profile sizing, recommendations []string) ([]string, []string) {
+	limitCPU := resource.MustParse(profile.LimitsCPU)
+	limitMemory := resource.MustParse(profile.LimitsMemory)
+
+	var summary []string
+	fits := 0
+	for _, node := range nodes {
+		state := ""
+		if node.Unschedulable {
+			state = " (unschedulable)"
+		}
+		summary = append(summary, fmt.Sprintf("%s: %s CPU, %s memory allocatable%s", node.Name, node.CPU.String(), node.Memory.String(), state))
+		if !node.Unschedulable && node.CPU.Cmp(limitCPU) >= 0 && node.Memory.Cmp(limitMemory) >= 0 {
+			fits++
+		}
+	}
+
+	if fits == 0 {
+		recommendations = append(recommendations, fmt.Sprintf("No schedulable node can fit the limits (%s CPU, %s memory). Choose a smaller size.", profile.LimitsCPU, profile.LimitsMemory))
+	}
+	return summary, recommendations
+}
+
+// modelConfigCapacity returns the rate-limit headroom of each ModelConfig,
+// counting the expected load of the agents that reference it.
+func (ts *ToolServer) modelConfigCapacity(ctx context.Context) ([]ModelConfigCapacity, error) {
+	configs, err := ts.kube(ctx).ListModelConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	agents, err := ts.kube(ctx).ListAgents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	load := map[string]int{}
+	count := map[string]int{}
+	for _, agent := range agents {
+		if agent.Spec.Declarative == nil || agent.Spec.Declarative.ModelConfig == "" {
+			continue
+		}
+		ref, err := types.ParseObjectRef(agent.Spec.Declarative.ModelConfig, agent.Namespace)
+		if err != nil {
+			continue
+		}
+		load[ref.String()] += annotationInt(agent.Annotations, expectedRPMAnnotation, defaultAgentRPM)
+		count[ref.String()]++
+	}
+
+	capacities := make([]ModelConfigCapacity, 0, len(configs))
+	for _, config := range configs {
+		key := types.ObjectRef{Namespace: config.Namespace, Name: config.Name}.String()
+		capacity := ModelConfigCapacity{
+			Name:        config.Name,
+			Provider:    config.Spec.Provider,
+			Agents:      count[key],
+			LimitRPM:    annotationInt(config.Annotations, rateLimitAnnotation, 0),
+			ExpectedRPM: load[key],
+		}
+		if capacity.LimitRPM > 0 {
+			headroom := capacity.LimitRPM - capacity.ExpectedRPM
+			capacity.HeadroomRPM = &headroom
+		}
+		capacities = append(capacities, capacity)
+	}
+	return capacities, nil
+}
+
+// recommendModelConfig picks the ModelConfig with the most headroom that can
+// absorb expectedRPM, preferring configs with a recorded rate limit.
+func recommendModelConfig(capacities []ModelConfigCapacity, expectedRPM int, recommendations []string) (string, []string) {
+	if len(capacities) == 0 {
+		return "", append(recommendations, "No ModelConfigs exist. Create one with create_model_config_manifest first.")
+	}
+
+	best := -1
+	for i, c := range capacities {
+		if c.HeadroomRPM == nil || *c.HeadroomRPM < expectedRPM {
+			continue
+		}
+		if best < 0 || *c.HeadroomRPM > *capacities[best].HeadroomRPM {
+			best = i
+		}
+	}
+	if best >= 0 {
+		c := capacities[best]
+		return c.Name, append(recommendations, fmt.Sprintf("Use ModelConfig '%s' (%d of %d requests/minute left).", c.Name, *c.HeadroomRPM, c.LimitRPM))
+	}
+
+	// Fall back to the least-loaded config without a recorded limit
+	for i, c := range capacities {
+		if c.HeadroomRPM != nil {
+			continue
+		}
+		if best < 0 || c.ExpectedRPM < capacities[best].ExpectedRPM {
+			best = i
+		}
+	}
+	if best >= 0 {
+		c := capacities[best]
+		return c.Name, append(recommendations, fmt.Sprintf("Use ModelConfig '%s' (least loaded; no rate limit recorded, set the %s annotation to check headroom).", c.Name, rateLimitAnnotation))
+	}
+	return "", append(recommendations, fmt.Sprintf("Every ModelConfig is at its rate limit for %d more requests/minute. Raise a provider limit or add a ModelConfig.", expectedRPM))
+}
+
+// annotationInt returns an integer annotation, or defaultValue when it is
+// missing or malformed.
+func annotationInt(annotations map[string]string, key string, defaultValue int) int {
+	v, err := strconv.Atoi(annotations[key])
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// checkContainer reports an init container or sidecar that cannot run.
+func checkContainer(c types.Container) error {
+	if errs := validation.IsDNS1123Label(c.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid container name '%s': %s", c.Name, errs[0])
+	}
+	if c.Image == "" {
+		return fmt.Errorf("container '%s': image is required", c.Name)
+	}
+	return nil
+}
+
+// checkVolume reports a volume without exactly one source.
+func checkVolume(v types.Volume) error {
+	if errs := validation.IsDNS1123Label(v.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid volume name '%s': %s", v.Name, errs[0])
+	}
+	sources := 0
+	for _, set := range []bool{v.ConfigMap != nil, v.Secret != nil, v.EmptyDir != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("volume '%s' must set exactly one of configMap, secret or emptyDir", v.Name)
+	}
+	return nil
+}
+
+// checkVolumeMount reports a mount without a name or path.
+func checkVolumeMount(m types.VolumeMount) error {
+	if m.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if m.MountPath == "" {
+		return fmt.Errorf("volume mount '%s': mountPath is required", m.Name)
+	}
+	return nil
+}
+
+// checkDeploymentSpec validates the init containers, sidecars and volumes of
+// an MCPServer deployment: containers must be valid and uniquely named,
+// volumes valid and uniquely named, and every mount must name a declared
+// volume.
+func checkDeploymentSpec(d *types.DeploymentSpec, path string) []ValidationIssue {
+	var issues []ValidationIssue
+	add := func(field, format string, a ...interface{}) {
+		issues = append(issues, ValidationIssue{
+			Severity: "error",
+			Field:    path + "." + field,
+			Message:  fmt.Sprintf(format, a...),
+		})
+	}
+
+	volumes := map[string]bool{}
+	for i, v := range d.Volumes {
+		field := fmt.Sprintf("volumes[%d]", i)
+		if err := checkVolume(v); err != nil {
+			add(field, "%v", err)
+		}
+		if volumes[v.Name] {
+			add(field, "Duplicate volume name '%s'", v.Name)
+		}
+		volumes[v.Name] = true
+	}
+
+	checkMounts := func(field string, mounts []types.VolumeMount) {
+		for i, m := range mounts {
+			mountField := fmt.Sprintf("%s[%d]", field, i)
+			if err := checkVolumeMount(m); err != nil {
+				add(mountField, "%v", err)
+			} else if !volumes[m.Name] {
+				add(mountField, "Volume '%s' is not declared in %s.volumes", m.Name, path)
+			}
+		}
+	}
+	checkMounts("volumeMounts", d.VolumeMounts)
+
+	names := map[string]bool{}
+	for _, group := range []struct {
+		field      string
+		containers []types.Container
+	}{
+		{"initContainers", d.InitContainers},
+		{"sidecars", d.Sidecars},
+	} {
+		for i, c := range group.containers {
+			field := fmt.Sprintf("%s[%d]", group.field, i)
+			if err := checkContainer(c); err != nil {
+				add(field, "%v", err)
+			}
+			if names[c.Name] {
+				add(field+".name", "Duplicate container name '%s'", c.Name)
+			}
+			names[c.Name] = true
+			checkMounts(field+".volumeMounts", c.VolumeMounts)
+		}
+	}
+	return issues
+}
+
+// checkMCPServerDeployment runs checkDeploymentSpec on a manifest's
+// spec.deployment.
+func checkMCPServerDeployment(obj *unstructured.Unstructured) []ValidationIssue {
+	raw, found, _ := unstructured.NestedMap(obj.Object, "spec", "deployment")
+	if !found {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var deployment types.DeploymentSpec
+	if err := json.Unmarshal(data, &deployment); err != nil {
+		return []ValidationIssue{{
+			Severity: "error",
+			Field:    "spec.deployment",
+			Message:  fmt.Sprintf("Invalid deployment: %v", err),
+		}}
+	}
+	return checkDeploymentSpec(&deployment, "spec.deployment")
+}
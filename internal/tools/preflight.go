@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/config"
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// RequiredAccess returns the permissions needed by the tool set registered
+// in the given mode.
+func RequiredAccess(mode string) []kubernetes.AccessRequirement {
+	readWrite := []string{"get", "list", "create", "update"}
+	if mode != ModeApply {
+		// Only apply mode changes the cluster
+		readOnly := []string{"get", "list"}
+		return []kubernetes.AccessRequirement{
+			{GVR: kubernetes.AgentGVR, Verbs: readOnly},
+			{GVR: kubernetes.ModelConfigGVR, Verbs: readOnly},
+			{GVR: kubernetes.MCPServerGVR, Verbs: readOnly},
+			{GVR: kubernetes.RemoteMCPServerGVR, Verbs: readOnly},
+		}
+	}
+	return []kubernetes.AccessRequirement{
+		{GVR: kubernetes.AgentGVR, Verbs: append(readWrite, "delete")},
+		{GVR: kubernetes.ModelConfigGVR, Verbs: readWrite},
+		{GVR: kubernetes.MCPServerGVR, Verbs: readWrite},
+		{GVR: kubernetes.RemoteMCPServerGVR, Verbs: readWrite},
+	}
+}
+
+// PreflightOptions builds the preflight options for the given configuration.
+func PreflightOptions(cfg *config.Config) kubernetes.PreflightOptions {
+	return kubernetes.PreflightOptions{
+		ControllerName:      cfg.ControllerName,
+		ControllerNamespace: cfg.ControllerNamespace,
+		Requirements:        RequiredAccess(cfg.Mode),
+	}
+}
+
+// registerPreflightReport registers the preflight_report tool.
+func (ts *ToolServer) registerPreflightReport() {
+	tool := mcp.NewTool("preflight_report",
+		mcp.WithDescription("Run the startup preflight checks on demand: namespace exists, kagent CRDs are installed, RBAC is sufficient for the tool set, and the kagent controller is running."),
+	)
+
+	ts.server.AddTool(tool, ts.handlePreflightReport)
+}
+
+func (ts *ToolServer) handlePreflightReport(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	report := ts.kube(ctx).Preflight(ctx, PreflightOptions(ts.server.Config()))
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+
+	summary := "✓ All preflight checks passed."
+	if !report.Passed() {
+		summary = fmt.Sprintf("❌ %d preflight check(s) failed. Tool calls depending on them will fail until the issues are resolved.", len(report.Failures()))
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("# Preflight Report\n\n%s\n\n%s", summary, string(output))), nil
+}
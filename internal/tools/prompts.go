@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// safeApplySteps is the review sequence every prompt that changes the
+// cluster ends with, so that nothing is applied unseen.
+const safeApplySteps = `Before changing the cluster:
+- Validate the manifest with validate_manifest and fix every error it reports (its fixes can be applied as suggested).
+- Show the change with diff_manifest and present the diff to me. Note its diff_id and resourceVersion.
+- Ask me to confirm. Do not apply anything without my explicit approval.
+- Apply with apply_manifest diff_id=<diff_id>, passing expected_resource_version=<resourceVersion> when the resource already exists, so a concurrent change is not overwritten.
+- Never use delete tools or apply manifests you have not shown me.`
+
+// workflowPrompt is an MCP prompt encoding a recommended tool sequence.
+type workflowPrompt struct {
+	name        string
+	description string
+	arguments   []promptArgument
+	// render returns the instructions given the prompt's arguments, which
+	// include every required argument.
+	render func(args map[string]string) string
+}
+
+type promptArgument struct {
+	name        string
+	description string
+	required    bool
+}
+
+// workflowPrompts are the prompts the server offers.
+var workflowPrompts = []workflowPrompt{
+	{
+		name:        "create_agent",
+		description: "Create a new agent: discover models and tools, generate the manifest, then validate, diff and apply it after review",
+		arguments: []promptArgument{
+			{name: "name", description: "Name of the new agent", required: true},
+			{name: "purpose", description: "What the agent should do", required: true},
+			{name: "model_config", description: "ModelConfig to use (default: pick one with list_model_configs)"},
+		},
+		render: func(args map[string]string) string {
+			model := "Pick a ModelConfig with list_model_configs; if none fits, generate one with create_model_config_manifest and check its Secret with verify_model_secret."
+			if args["model_config"] != "" {
+				model = fmt.Sprintf("Use the ModelConfig '%s'; check it exists with list_model_configs and its API key with verify_model_secret.", args["model_config"])
+			}
+			return fmt.Sprintf(`Create a kagent agent named '%s'. Its purpose: %s
+
+Follow these steps in order:
+1. %s
+2. Find the tools it needs: list_mcp_servers, then get_tool_list on the relevant servers. Only use tool names that get_tool_list returns.
+3. Draft a focused system message for the purpose, and generate the manifest with create_agent_manifest (tools_json with the servers and toolNames from step 2). Use create_agent_stack instead if a new ModelConfig or RBAC is needed too.
+4. Run security_review on the manifest and address anything that blocks.
+
+%s
+
+After applying, confirm the agent becomes Ready with get_agent_status.`, args["name"], args["purpose"], model, safeApplySteps)
+		},
+	},
+	{
+		name:        "debug_unready_agent",
+		description: "Find out why an agent is not Ready and propose a reviewed fix",
+		arguments: []promptArgument{
+			{name: "name", description: "Name of the agent", required: true},
+		},
+		render: func(args map[string]string) string {
+			return fmt.Sprintf(`The agent '%s' is not Ready. Find out why and fix it.
+
+Follow these steps in order:
+1. Run get_agent_status to read its conditions, Deployment, Pods and recent Events.
+2. Run diagnose_agent for pass/fail checks of its dependencies, and readiness_gate_report for its ModelConfig and MCP servers.
+3. If a ModelConfig is involved, check its credentials with verify_model_secret. If a tool server is involved, confirm the referenced toolNames exist with get_tool_list.
+4. If it broke after a change, compare with diff_revisions and look at resource_timeline.
+5. Explain the root cause to me in a few sentences, citing the evidence, before proposing anything.
+6. Propose the smallest fix, as update_agent_manifest or patch_agent output (or a manifest for the failing dependency).
+
+%s
+
+After applying, check again with get_agent_status until the agent is Ready or a new cause appears.`, args["name"], safeApplySteps)
+		},
+	},
+	{
+		name:        "wire_mcp_server",
+		description: "Give an agent tools from an MCP server, using only tool names the server exposes",
+		arguments: []promptArgument{
+			{name: "agent", description: "Name of the agent", required: true},
+			{name: "mcp_server", description: "Name of the MCPServer or RemoteMCPServer", required: true},
+			{name: "tools", description: "Comma-separated tools to add (default: choose from the agent's purpose)"},
+		},
+		render: func(args map[string]string) string {
+			selection := "Choose the tools that serve the agent's purpose (read it with get_agent), and tell me which ones and why."
+			if args["tools"] != "" {
+				selection = fmt.Sprintf("Add these tools: %s. Report any that the server does not expose, with the closest existing names, instead of adding them.", args["tools"])
+			}
+			return fmt.Sprintf(`Wire the MCP server '%s' into the agent '%s'.
+
+Follow these steps in order:
+1. Confirm the server exists and is Ready with list_mcp_servers.
+2. List the tools it exposes with get_tool_list; use get_tool_schema for any whose parameters matter.
+3. %s
+4. Generate the change with update_agent_manifest, passing add_tools_json with the server and the exact toolNames.
+5. Run validate_manifest with check_tool_names=true so every tool name is verified against the server.
+
+%s
+
+After applying, confirm the agent is still Ready with get_agent_status.`, args["mcp_server"], args["agent"], selection, safeApplySteps)
+		},
+	},
+}
+
+// registerPrompts registers the workflow prompts.
+func (ts *ToolServer) registerPrompts() {
+	for _, p := range workflowPrompts {
+		opts := []mcp.PromptOption{mcp.WithPromptDescription(p.description)}
+		for _, a := range p.arguments {
+			argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(a.description)}
+			if a.required {
+				argOpts = append(argOpts, mcp.RequiredArgument())
+			}
+			opts = append(opts, mcp.WithArgument(a.name, argOpts...))
+		}
+		ts.server.AddPrompt(mcp.NewPrompt(p.name, opts...), ts.promptHandler(p))
+	}
+}
+
+// promptHandler returns the handler of a workflow prompt. Cluster facts
+// used in its arguments are substituted.
+func (ts *ToolServer) promptHandler(p workflowPrompt) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		for _, a := range p.arguments {
+			if a.required && strings.TrimSpace(req.Params.Arguments[a.name]) == "" {
+				return nil, fmt.Errorf("prompt '%s' requires the argument '%s'", p.name, a.name)
+			}
+		}
+		text := p.render(req.Params.Arguments)
+		if err := ts.expandFacts(ctx, &text); err != nil {
+			return nil, fmt.Errorf("prompt '%s': %w", p.name, err)
+		}
+		return mcp.NewGetPromptResult(p.description, []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+		}), nil
+	}
+}
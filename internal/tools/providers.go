@@ -0,0 +1,262 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// providerBlocks maps the provider settings fields of a ModelConfig spec to
+// the provider each belongs to.
+var providerBlocks = []struct {
+	Field    string
+	Provider string
+	Type     func() interface{}
+}{
+	{"openai", "OpenAI", func() interface{} { return &types.OpenAIConfig{} }},
+	{"anthropic", "Anthropic", func() interface{} { return &types.AnthropicConfig{} }},
+	{"gemini", "Gemini", func() interface{} { return &types.GeminiConfig{} }},
+	{"azure", "AzureOpenAI", func() interface{} { return &types.AzureOpenAIConfig{} }},
+	{"ollama", "Ollama", func() interface{} { return &types.OllamaConfig{} }},
+}
+
+// openAIReasoningEfforts are the accepted values of openai.reasoningEffort.
+var openAIReasoningEfforts = []string{"minimal", "low", "medium", "high"}
+
+// providerSettings are the generation parameters of
+// create_model_config_manifest. Empty fields are left unset.
+type providerSettings struct {
+	Temperature     string
+	TopP            string
+	MaxTokens       int
+	TopK            int
+	Organization    string
+	ReasoningEffort string
+	AzureEndpoint   string
+	AzureAPIVersion string
+	AzureDeployment string
+	OllamaHost      string
+}
+
+// providerSettingsFrom reads the generation parameters of a call.
+func providerSettingsFrom(args *params.Args) providerSettings {
+	return providerSettings{
+		Temperature:     args.String("temperature"),
+		TopP:            args.String("top_p"),
+		MaxTokens:       args.Int("max_tokens", 0),
+		TopK:            args.Int("top_k", 0),
+		Organization:    args.String("organization"),
+		ReasoningEffort: args.String("reasoning_effort"),
+		AzureEndpoint:   args.String("azure_endpoint"),
+		AzureAPIVersion: args.String("azure_api_version"),
+		AzureDeployment: args.String("azure_deployment"),
+		OllamaHost:      args.String("ollama_host"),
+	}
+}
+
+// apply sets the parameters in the provider's settings block of config,
+// which newModelConfig created. Parameters the provider does not support
+// are an error rather than being dropped.
+func (s providerSettings) apply(config *types.ModelConfig) error {
+	provider := config.Spec.Provider
+	var unsupported []string
+	unless := func(ok bool, arg string, set bool) {
+		if set && !ok {
+			unsupported = append(unsupported, arg)
+		}
+	}
+
+	switch provider {
+	case "OpenAI":
+		c := config.Spec.OpenAI
+		c.Temperature, c.TopP, c.MaxTokens = s.Temperature, s.TopP, s.MaxTokens
+		c.Organization, c.ReasoningEffort = s.Organization, s.ReasoningEffort
+	case "Anthropic":
+		c := config.Spec.Anthropic
+		c.Temperature, c.TopP, c.MaxTokens, c.TopK = s.Temperature, s.TopP, s.MaxTokens, s.TopK
+	case "AzureOpenAI":
+		c := config.Spec.Azure
+		c.Temperature, c.TopP, c.MaxTokens = s.Temperature, s.TopP, s.MaxTokens
+		c.Endpoint, c.APIVersion, c.DeploymentName = s.AzureEndpoint, s.AzureAPIVersion, s.AzureDeployment
+	case "Ollama":
+		config.Spec.Ollama.Host = s.OllamaHost
+	}
+
+	sampling := provider == "OpenAI" || provider == "Anthropic" || provider == "AzureOpenAI"
+	unless(sampling, "temperature", s.Temperature != "")
+	unless(sampling, "top_p", s.TopP != "")
+	unless(sampling, "max_tokens", s.MaxTokens != 0)
+	unless(provider == "Anthropic", "top_k", s.TopK != 0)
+	unless(provider == "OpenAI", "organization", s.Organization != "")
+	unless(provider == "OpenAI", "reasoning_effort", s.ReasoningEffort != "")
+	unless(provider == "AzureOpenAI", "azure_endpoint", s.AzureEndpoint != "")
+	unless(provider == "AzureOpenAI", "azure_api_version", s.AzureAPIVersion != "")
+	unless(provider == "AzureOpenAI", "azure_deployment", s.AzureDeployment != "")
+	unless(provider == "Ollama", "ollama_host", s.OllamaHost != "")
+	if len(unsupported) > 0 {
+		return fmt.Errorf("provider %s does not support: %s", provider, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// checkProviderSettings validates the provider settings blocks of a
+// ModelConfig: only the block of the selected provider may be set (any for
+// Custom), it may only contain the fields the CRD defines, and their values
+// must be in range.
+func checkProviderSettings(obj *unstructured.Unstructured, provider string) []ValidationIssue {
+	known := false
+	for _, block := range providerBlocks {
+		known = known || block.Provider == provider
+	}
+
+	var issues []ValidationIssue
+	for _, block := range providerBlocks {
+		raw, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", block.Field)
+		if !found {
+			continue
+		}
+		field := "spec." + block.Field
+		if known && provider != block.Provider {
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    field,
+				Message:  fmt.Sprintf("%s holds %s settings, but the provider is %s; set the provider to %s or remove the block", field, block.Provider, provider, block.Provider),
+				Fix:      []PatchOperation{{Op: "remove", Path: "/spec/" + block.Field}},
+			})
+			continue
+		}
+
+		settings := block.Type()
+		if err := decodeStrict(raw, settings); err != nil {
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    field,
+				Message:  fmt.Sprintf("Invalid %s settings: %s", block.Provider, strings.TrimPrefix(err.Error(), "json: ")),
+			})
+			continue
+		}
+		issues = append(issues, checkProviderValues(field, settings)...)
+	}
+	return issues
+}
+
+// checkProviderValues checks the values of a decoded settings block.
+func checkProviderValues(field string, settings interface{}) []ValidationIssue {
+	var issues []ValidationIssue
+	check := func(issue *ValidationIssue) {
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+
+	switch s := settings.(type) {
+	case *types.OpenAIConfig:
+		check(checkDecimal(field+".temperature", s.Temperature, 0, 2))
+		check(checkDecimal(field+".topP", s.TopP, 0, 1))
+		check(checkDecimal(field+".frequencyPenalty", s.FrequencyPenalty, -2, 2))
+		check(checkDecimal(field+".presencePenalty", s.PresencePenalty, -2, 2))
+		check(checkPositive(field+".maxTokens", s.MaxTokens))
+		if s.N != nil {
+			check(checkPositive(field+".n", *s.N))
+		}
+		if s.Timeout != nil {
+			check(checkPositive(field+".timeout", *s.Timeout))
+		}
+		if s.ReasoningEffort != "" && !containsString(openAIReasoningEfforts, s.ReasoningEffort) {
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    field + ".reasoningEffort",
+				Message:  fmt.Sprintf("Invalid reasoningEffort '%s'. Must be one of: %s", s.ReasoningEffort, strings.Join(openAIReasoningEfforts, ", ")),
+			})
+		}
+	case *types.AnthropicConfig:
+		check(checkDecimal(field+".temperature", s.Temperature, 0, 1))
+		check(checkDecimal(field+".topP", s.TopP, 0, 1))
+		check(checkPositive(field+".maxTokens", s.MaxTokens))
+		check(checkPositive(field+".topK", s.TopK))
+	case *types.AzureOpenAIConfig:
+		check(checkDecimal(field+".temperature", s.Temperature, 0, 2))
+		check(checkDecimal(field+".topP", s.TopP, 0, 1))
+		check(checkPositive(field+".maxTokens", s.MaxTokens))
+		if s.Endpoint == "" {
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    field + ".azureEndpoint",
+				Message:  "azureEndpoint is required for AzureOpenAI, e.g. https://<resource>.openai.azure.com/",
+			})
+		} else if u, err := url.Parse(s.Endpoint); err != nil || u.Scheme != "https" || u.Host == "" {
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    field + ".azureEndpoint",
+				Message:  fmt.Sprintf("azureEndpoint '%s' must be an https URL", s.Endpoint),
+			})
+		}
+		if s.APIVersion == "" {
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    field + ".apiVersion",
+				Message:  "apiVersion is required for AzureOpenAI, e.g. 2024-06-01",
+			})
+		}
+	case *types.OllamaConfig:
+		if s.Host != "" {
+			if u, err := url.Parse(s.Host); err != nil || u.Scheme == "" || u.Host == "" {
+				issues = append(issues, ValidationIssue{
+					Severity: "error",
+					Field:    field + ".host",
+					Message:  fmt.Sprintf("host '%s' must be a URL, e.g. http://ollama.ollama.svc:11434", s.Host),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// checkDecimal checks that a decimal string parameter, if set, is a number
+// between lo and hi.
+func checkDecimal(field, value string, lo, hi float64) *ValidationIssue {
+	if value == "" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v < lo || v > hi {
+		return &ValidationIssue{
+			Severity: "error",
+			Field:    field,
+			Message:  fmt.Sprintf("%s must be a number from %g to %g, given as a string (e.g. \"0.7\"); got '%s'", field, lo, hi, value),
+		}
+	}
+	return nil
+}
+
+// checkPositive checks that an integer parameter, if set, is positive.
+func checkPositive(field string, value int) *ValidationIssue {
+	if value < 0 {
+		return &ValidationIssue{
+			Severity: "error",
+			Field:    field,
+			Message:  fmt.Sprintf("%s must be positive; got %d", field, value),
+		}
+	}
+	return nil
+}
+
+// decodeStrict decodes a value of an unstructured object into a typed
+// struct, failing on fields the struct does not define.
+func decodeStrict(raw interface{}, into interface{}) error {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(into)
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/query"
+)
+
+// registerQuery registers the query tool.
+func (ts *ToolServer) registerQuery() {
+	tool := mcp.NewTool("query",
+		mcp.WithDescription("Answer ad-hoc questions about kagent resources with a JMESPath expression (https://jmespath.org) evaluated over all of them at once. The document queried has one list per kind: agents, modelconfigs, mcpservers and remotemcpservers, each holding the full objects (metadata, spec, status). Examples: agents[?spec.declarative.modelConfig=='gpt4o-config'].metadata.name; modelconfigs[].{name: metadata.name, provider: spec.provider}; agents[?status.conditions[?type=='Ready' && status!='True']].metadata.name; length(agents[?spec.type=='BYO']). Returns the result as JSON."),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("JMESPath expression over {agents, modelconfigs, mcpservers, remotemcpservers}"),
+		),
+		withAllNamespacesOption(),
+	)
+
+	ts.addTool(tool, ts.handleQuery)
+}
+
+func (ts *ToolServer) handleQuery(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	expression := args.RequiredString("expression")
+	allNamespaces := args.Bool("all_namespaces", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	expr, err := query.Compile(expression)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client, err := ts.listClient(ctx, allNamespaces, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	snapshot, err := client.ListSnapshot(ctx, kagentGVRs()...)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list resources: %v", err)), nil
+	}
+
+	document := map[string]interface{}{}
+	for _, k := range staleKinds {
+		items := []interface{}{}
+		for _, item := range snapshot.Items(k.GVR) {
+			item.SetManagedFields(nil)
+			item.SetKind(k.Kind)
+			items = append(items, item.Object)
+		}
+		document[k.GVR.Resource] = items
+	}
+
+	result, err := expr.Search(document)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to evaluate '%s': %v", expression, err)), nil
+	}
+	if result == nil {
+		return mcp.NewToolResultText(fmt.Sprintf("null\n\nNothing matched. The queried document has the keys %s, e.g. agents[].metadata.name.", strings.Join(sortedKeys(document), ", "))), nil
+	}
+
+	output, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
@@ -3,9 +3,10 @@ package tools
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
 )
 
 // registerGenerateRBACManifest registers the generate_rbac_manifest tool.
@@ -22,37 +23,64 @@ func (ts *ToolServer) registerGenerateRBACManifest() {
 		mcp.WithString("additional_rules_json",
 			mcp.Description("JSON array of additional RBAC rules. Format: [{\"apiGroups\": [\"...\"], \"resources\": [\"...\"], \"verbs\": [\"...\"]}]"),
 		),
+		mcp.WithBoolean("include_namespace",
+			mcp.Description("Prepend a Namespace manifest so the bundle can be applied to a cluster where the namespace does not exist yet (default: false)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
 	)
 
-	ts.server.AddTool(tool, ts.handleGenerateRBACManifest)
+	ts.addTool(tool, ts.handleGenerateRBACManifest)
 }
 
 func (ts *ToolServer) handleGenerateRBACManifest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name, _ := req.Params.Arguments["name"].(string)
-	permissions, _ := req.Params.Arguments["permissions"].(string)
-
-	if name == "" {
-		return mcp.NewToolResultError("name is required"), nil
+	args := params.From(req)
+	name := args.RequiredString("name")
+	permissions := args.Enum("permissions", "readonly", "readonly", "standard", "admin")
+	includeNamespace := args.Bool("include_namespace", false)
+	out := outputOptionsFrom(args)
+	namespace := ts.kube(ctx).Namespace()
+
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	if permissions == "" {
-		permissions = "readonly"
-	}
+	serviceAccount, role, roleBinding := rbacManifests(name, namespace, permissions)
+	manifests := fmt.Sprintf(`---
+%s
+---
+%s
+---
+%s
+`, withNamespaceDocument(includeNamespace, namespace, serviceAccount), role, roleBinding)
 
-	validPermissions := map[string]bool{"readonly": true, "standard": true, "admin": true}
-	if !validPermissions[permissions] {
-		return mcp.NewToolResultError("permissions must be 'readonly', 'standard', or 'admin'"), nil
-	}
+	header := fmt.Sprintf(`# Generated RBAC Manifests for '%s'
+# Permission level: %s
+# %s
+# Review these manifests before applying.`, name, permissions, rbacPermissionDescriptions[permissions])
+
+	return out.render(header, manifests)
+}
+
+// rbacPermissionDescriptions explains what each permission preset grants.
+var rbacPermissionDescriptions = map[string]string{
+	"readonly": "This grants read-only access to kagent resources (agents, model configs, MCP servers).",
+	"standard": "This grants read/write access to kagent resources and read access to secrets for validation.",
+	"admin":    "This grants full access to kagent resources plus the ability to manage RBAC and ServiceAccounts.",
+}
 
+// rbacManifests returns the ServiceAccount, Role and RoleBinding granting a
+// permission preset.
+func rbacManifests(name, namespace, permissions string) (serviceAccount, role, roleBinding string) {
 	// Generate ServiceAccount
-	serviceAccount := fmt.Sprintf(`apiVersion: v1
+	serviceAccount = fmt.Sprintf(`apiVersion: v1
 kind: ServiceAccount
 metadata:
   name: %s
-  namespace: kagent
+  namespace: %s
   labels:
     app.kubernetes.io/name: %s
-    app.kubernetes.io/component: rbac`, name, name)
+    app.kubernetes.io/component: rbac`, name, namespace, name)
 
 	// Generate Role based on permission level
 	var rules string
@@ -101,59 +129,34 @@ metadata:
     verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]`
 	}
 
-	role := fmt.Sprintf(`apiVersion: rbac.authorization.k8s.io/v1
+	role = fmt.Sprintf(`apiVersion: rbac.authorization.k8s.io/v1
 kind: Role
 metadata:
   name: %s-role
-  namespace: kagent
+  namespace: %s
   labels:
     app.kubernetes.io/name: %s
     app.kubernetes.io/component: rbac
 rules:
-%s`, name, name, rules)
+%s`, name, namespace, name, rules)
 
 	// Generate RoleBinding
-	roleBinding := fmt.Sprintf(`apiVersion: rbac.authorization.k8s.io/v1
+	roleBinding = fmt.Sprintf(`apiVersion: rbac.authorization.k8s.io/v1
 kind: RoleBinding
 metadata:
   name: %s-rolebinding
-  namespace: kagent
+  namespace: %s
   labels:
     app.kubernetes.io/name: %s
     app.kubernetes.io/component: rbac
 subjects:
   - kind: ServiceAccount
     name: %s
-    namespace: kagent
+    namespace: %s
 roleRef:
   kind: Role
   name: %s-role
-  apiGroup: rbac.authorization.k8s.io`, name, name, name, name)
-
-	result := fmt.Sprintf(`# Generated RBAC Manifests for '%s'
-# Permission level: %s
-# Review these manifests before applying.
-
----
-%s
----
-%s
----
-%s
-`, name, permissions, serviceAccount, role, roleBinding)
-
-	// Add description of what each permission level provides
-	var permissionDesc string
-	switch permissions {
-	case "readonly":
-		permissionDesc = "This grants read-only access to kagent resources (agents, model configs, MCP servers)."
-	case "standard":
-		permissionDesc = "This grants read/write access to kagent resources and read access to secrets for validation."
-	case "admin":
-		permissionDesc = "This grants full access to kagent resources plus the ability to manage RBAC and ServiceAccounts."
-	}
-
-	result = strings.Replace(result, "# Review", fmt.Sprintf("# %s\n# Review", permissionDesc), 1)
+  apiGroup: rbac.authorization.k8s.io`, name, namespace, name, name, namespace, name)
 
-	return mcp.NewToolResultText(result), nil
+	return serviceAccount, role, roleBinding
 }
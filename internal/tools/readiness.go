@@ -0,0 +1,328 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	ruleset "github.com/kagent-dev/meta-kagent/internal/validation"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// a2aProbeTimeout bounds how long the A2A endpoint probe may take.
+const a2aProbeTimeout = 5 * time.Second
+
+// Readiness verdicts.
+const (
+	VerdictReady    = "ready"
+	VerdictDegraded = "degraded"
+	VerdictNotReady = "not_ready"
+)
+
+// ReadinessCheck is the health of a single agent dependency.
+type ReadinessCheck struct {
+	Dependency string `json:"dependency"` // "Agent", "ModelConfig", "Secret", "MCPServer", "RemoteMCPServer", "Service", "A2A", or for diagnose_agent "Deployment", "Pods", "Pod", "Skill"
+	Name       string `json:"name"`
+	Status     string `json:"status"` // "pass", "warn", or "fail"
+	Message    string `json:"message"`
+	// Remediation suggests how to fix a failing or unverified check.
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// ReadinessReport is the composite readiness verdict for an agent.
+type ReadinessReport struct {
+	Agent   string           `json:"agent"`
+	Verdict string           `json:"verdict"`
+	Checks  []ReadinessCheck `json:"checks"`
+}
+
+func (r *ReadinessReport) add(dependency, name, status, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, ReadinessCheck{
+		Dependency: dependency,
+		Name:       name,
+		Status:     status,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// hint sets the remediation of the check added last.
+func (r *ReadinessReport) hint(format string, args ...interface{}) {
+	r.Checks[len(r.Checks)-1].Remediation = fmt.Sprintf(format, args...)
+}
+
+// decide sets the verdict from the checks: not ready if any failed,
+// degraded if any could only be verified in part.
+func (r *ReadinessReport) decide() {
+	r.Verdict = VerdictReady
+	for _, c := range r.Checks {
+		if c.Status == kubernetes.PreflightFail {
+			r.Verdict = VerdictNotReady
+			return
+		}
+		if c.Status == kubernetes.PreflightWarn {
+			r.Verdict = VerdictDegraded
+		}
+	}
+}
+
+// toolServerGVRs maps the kinds an agent tool may reference to their resources.
+var toolServerGVRs = map[string]schema.GroupVersionResource{
+	"MCPServer":       kubernetes.MCPServerGVR,
+	"RemoteMCPServer": kubernetes.RemoteMCPServerGVR,
+	"Service":         kubernetes.ServiceGVR,
+}
+
+// registerReadinessGateReport registers the readiness_gate_report tool.
+func (ts *ToolServer) registerReadinessGateReport() {
+	tool := mcp.NewTool("readiness_gate_report",
+		mcp.WithDescription("Evaluate whether an agent is actually usable: its ModelConfig is valid and its API key Secret exists, every referenced MCP server is ready, and its A2A endpoint responds. Returns a composite verdict (ready, degraded, not_ready) with per-dependency detail."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the agent to evaluate"),
+		),
+		mcp.WithString("endpoint_url",
+			mcp.Description("A2A endpoint URL to probe (defaults to the Kubernetes service URL: http://<name>.<namespace>.svc.cluster.local)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleReadinessGateReport)
+}
+
+func (ts *ToolServer) handleReadinessGateReport(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	endpointURL := args.String("endpoint_url")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	report := &ReadinessReport{Agent: name}
+
+	if agent.Status.IsReady() {
+		report.add("Agent", name, kubernetes.PreflightPass, "controller reports Ready")
+	} else {
+		report.add("Agent", name, kubernetes.PreflightFail, "controller does not report Ready")
+		report.hint("Run get_agent_status for the agent's conditions, pods and events")
+	}
+
+	if agent.Spec.Declarative != nil {
+		ts.checkModelConfigReadiness(ctx, report, agent.Spec.Declarative.ModelConfig, agent.Namespace)
+		for _, tool := range agent.Spec.Declarative.Tools {
+			if tool.McpServer != nil {
+				ts.checkToolServerReadiness(ctx, report, tool.McpServer, agent.Namespace)
+			}
+		}
+	}
+
+	if a2a := getA2AConfig(agent); a2a != nil && len(a2a.Skills) > 0 {
+		if endpointURL == "" {
+			namespace := agent.Namespace
+			if namespace == "" {
+				namespace = ts.kube(ctx).Namespace()
+			}
+			endpointURL = fmt.Sprintf("http://%s.%s.svc.cluster.local", name, namespace)
+		}
+		checkA2AReadiness(ctx, report, endpointURL)
+	}
+
+	report.decide()
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	return mcp.NewToolResultText(fmt.Sprintf("# Readiness Gate: %s\n\nVerdict: %s\n\n%s", name, report.Verdict, string(output))), nil
+}
+
+// checkModelConfigReadiness checks that the ModelConfig exists, passes
+// validation, and that its API key Secret holds the expected key. The
+// reference may name a ModelConfig in another namespace.
+func (ts *ToolServer) checkModelConfigReadiness(ctx context.Context, report *ReadinessReport, name, namespace string) {
+	if name == "" {
+		report.add("ModelConfig", "", kubernetes.PreflightFail, "agent does not reference a ModelConfig")
+		report.hint("Set spec.declarative.modelConfig to one of the ModelConfigs from list_model_configs")
+		return
+	}
+
+	obj, ref, err := ts.resolveReference(ctx, kubernetes.ModelConfigGVR, name, namespace)
+	if err != nil {
+		if ref.Name == "" {
+			report.add("ModelConfig", name, kubernetes.PreflightFail, "%v", err)
+			return
+		}
+		status := kubernetes.PreflightFail
+		if apierrors.IsForbidden(err) {
+			status = kubernetes.PreflightWarn
+		}
+		report.add("ModelConfig", name, status, "%s", referenceError("ModelConfig", kubernetes.ModelConfigGVR, ref, err))
+		if apierrors.IsNotFound(err) {
+			report.hint("Create it with create_model_config_manifest, or point the agent at an existing ModelConfig")
+		}
+		return
+	}
+
+	var problems []string
+	// The ModelConfig's own fields; the API key Secret is checked below
+	rules := ts.rules.Only(ruleModelProvider, ruleModel, ruleAPIKeySecret, ruleProviderSettings)
+	for _, issue := range rules.Validate(ctx, obj, ruleset.Options{}, ts.ruleSettings(ctx)) {
+		if issue.Severity == "error" {
+			problems = append(problems, issue.Message)
+		}
+	}
+	if len(problems) > 0 {
+		report.add("ModelConfig", name, kubernetes.PreflightFail, "invalid: %s", strings.Join(problems, "; "))
+		report.hint("Fix the ModelConfig; validate_manifest on its YAML shows each issue")
+		return
+	}
+	report.add("ModelConfig", name, kubernetes.PreflightPass, "exists and is valid")
+
+	secret, _, _ := unstructured.NestedString(obj.Object, "spec", "apiKeySecret")
+	secretKey, _, _ := unstructured.NestedString(obj.Object, "spec", "apiKeySecretKey")
+	if secret == "" {
+		return
+	}
+
+	// The API key Secret lives next to the ModelConfig
+	keys, found, known, err := ts.kube(ctx).SecretKeysIn(ctx, ref.Namespace, secret)
+	switch {
+	case err != nil:
+		report.add("Secret", secret, kubernetes.PreflightWarn, "%v", err)
+	case !known:
+		report.add("Secret", secret, kubernetes.PreflightWarn, "could not verify the secret (not allowed to read secrets)")
+		report.hint("Grant the server's ServiceAccount 'get' on secrets to verify it")
+	case !found:
+		key := secretKey
+		if key == "" {
+			key = "<key>"
+		}
+		report.add("Secret", secret, kubernetes.PreflightFail, "API key secret does not exist")
+		report.hint("kubectl create secret generic %s -n %s --from-literal=%s=<api key>", secret, ref.Namespace, key)
+	case secretKey != "" && !containsString(keys, secretKey):
+		report.add("Secret", secret, kubernetes.PreflightFail, "secret has no key '%s'.%s", secretKey, keyHint(keys, secretKey))
+		report.hint("Add the key '%s' to the secret, or set spec.apiKeySecretKey of ModelConfig '%s' to an existing key", secretKey, name)
+	default:
+		report.add("Secret", secret, kubernetes.PreflightPass, "API key secret exists")
+	}
+}
+
+// checkToolServerReadiness checks that a referenced MCP server exists and,
+// for kagent kinds, that the controller reports it Ready or, for Services,
+// that it declares a port it exposes.
+func (ts *ToolServer) checkToolServerReadiness(ctx context.Context, report *ReadinessReport, ref *types.McpServerRef, namespace string) {
+	kind := ref.Kind
+	if kind == "" {
+		kind = "MCPServer"
+	}
+
+	gvr, ok := toolServerGVRs[kind]
+	if !ok {
+		report.add(kind, ref.Name, kubernetes.PreflightWarn, "unsupported tool server kind '%s'", kind)
+		return
+	}
+
+	obj, parsed, err := ts.resolveReference(ctx, gvr, ref.Name, namespace)
+	switch {
+	case err != nil && parsed.Name == "":
+		report.add(kind, ref.Name, kubernetes.PreflightFail, "%v", err)
+		return
+	case apierrors.IsNotFound(err):
+		report.add(kind, ref.Name, kubernetes.PreflightFail, "%s does not exist", kind)
+		report.hint("Create it (create_mcp_server_manifest for an MCPServer), or remove the tool from the agent")
+		return
+	case err != nil:
+		report.add(kind, ref.Name, kubernetes.PreflightWarn, "%s", referenceError(kind, gvr, parsed, err))
+		return
+	}
+
+	if kind == "Service" {
+		issues := serviceEndpointIssues("", obj)
+		for _, issue := range issues {
+			status := kubernetes.PreflightWarn
+			if issue.Severity == "error" {
+				status = kubernetes.PreflightFail
+			}
+			report.add(kind, ref.Name, status, "%s", issue.Message)
+			report.hint("Fix the Service's ports or its kagent.dev/mcp-service-* annotations (create_mcp_server_manifest with server_type=Service shows the expected form)")
+		}
+		if len(issues) == 0 {
+			report.add(kind, ref.Name, kubernetes.PreflightPass, "service exists, serving MCP on %s", serviceEndpoint(obj))
+		}
+		return
+	}
+
+	status, message := conditionStatus(obj, "Ready")
+	switch status {
+	case "True":
+		report.add(kind, ref.Name, kubernetes.PreflightPass, "controller reports Ready")
+	case "":
+		report.add(kind, ref.Name, kubernetes.PreflightWarn, "no Ready condition reported")
+	default:
+		report.add(kind, ref.Name, kubernetes.PreflightFail, "not Ready: %s", message)
+		report.hint("summarize_recent_events with name=%s shows what the controller and its pods report", ref.Name)
+	}
+}
+
+// checkA2AReadiness probes the agent's A2A card endpoint. Any response below
+// 500 means the endpoint is serving.
+func checkA2AReadiness(ctx context.Context, report *ReadinessReport, endpointURL string) {
+	ctx, cancel := context.WithTimeout(ctx, a2aProbeTimeout)
+	defer cancel()
+
+	cardURL := strings.TrimSuffix(endpointURL, "/") + "/.well-known/agent.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cardURL, nil)
+	if err != nil {
+		report.add("A2A", endpointURL, kubernetes.PreflightFail, "invalid endpoint URL: %v", err)
+		return
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		report.add("A2A", endpointURL, kubernetes.PreflightFail, "endpoint not responding: %v", err)
+		report.hint("Check that the agent's pods are running (get_agent_status)")
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		report.add("A2A", endpointURL, kubernetes.PreflightFail, "endpoint returned %s", resp.Status)
+		return
+	}
+	report.add("A2A", endpointURL, kubernetes.PreflightPass, "endpoint responded with %s", resp.Status)
+}
+
+// conditionStatus returns the status and message of the named condition, or
+// an empty status if the object does not report it.
+func conditionStatus(obj *unstructured.Unstructured, conditionType string) (string, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != conditionType {
+			continue
+		}
+		status, _ := cond["status"].(string)
+		message, _ := cond["message"].(string)
+		return status, message
+	}
+	return "", ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	mcpserver "github.com/kagent-dev/meta-kagent/internal/server"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// resolveReference fetches the resource a "name" or "namespace/name"
+// reference points to. Unqualified references resolve in fromNamespace, or
+// the server's namespace if that is empty.
+func (ts *ToolServer) resolveReference(ctx context.Context, gvr schema.GroupVersionResource, ref, fromNamespace string) (*unstructured.Unstructured, types.ObjectRef, error) {
+	if fromNamespace == "" {
+		fromNamespace = ts.kube(ctx).Namespace()
+	}
+	parsed, err := types.ParseObjectRef(ref, fromNamespace)
+	if err != nil {
+		return nil, types.ObjectRef{}, err
+	}
+
+	obj, err := ts.kube(ctx).GetResourceIn(ctx, gvr, parsed.Namespace, parsed.Name)
+	return obj, parsed, err
+}
+
+// referenceError explains why a reference could not be resolved. Access
+// denials name the missing permission, since cross-namespace references
+// often fail only because the server's Role does not reach that namespace.
+func referenceError(kind string, gvr schema.GroupVersionResource, ref types.ObjectRef, err error) string {
+	switch {
+	case apierrors.IsNotFound(err):
+		return fmt.Sprintf("%s '%s' not found", kind, ref)
+	case apierrors.IsForbidden(err):
+		return fmt.Sprintf("%s '%s' could not be verified: the server is not allowed to get %s in namespace '%s'. Grant its ServiceAccount read access in that namespace, or reference a %s in the agent's namespace", kind, ref, gvr.Resource, ref.Namespace, kind)
+	default:
+		return fmt.Sprintf("%s '%s' could not be resolved: %v", kind, ref, err)
+	}
+}
+
+// checkReference validates a reference field and reports whether its target
+// exists, in the cluster or in the bundle being validated.
+func (ts *ToolServer) checkReference(ctx context.Context, field, kind string, gvr schema.GroupVersionResource, ref, fromNamespace string) []ValidationIssue {
+	_, parsed, err := ts.resolveReference(ctx, gvr, ref, fromNamespace)
+	if err == nil {
+		return nil
+	}
+	if apierrors.IsNotFound(err) && bundleDefines(ctx, kind, ref, parsed.Namespace) {
+		return nil
+	}
+	if parsed.Name == "" {
+		return []ValidationIssue{{Severity: "error", Field: field, Message: err.Error()}}
+	}
+	return []ValidationIssue{{
+		Severity: "warning",
+		Field:    field,
+		Message:  referenceError(kind, gvr, parsed, err) + ". Ensure it exists before applying.",
+	}}
+}
+
+// checkToolReferences checks the tool servers and agents an agent uses.
+// Referenced Services must declare a port they expose. With
+// checkToolNames, each referenced MCPServer and RemoteMCPServer that exists
+// is also asked for its tools, and toolNames it does not expose are errors.
+func (ts *ToolServer) checkToolReferences(ctx context.Context, obj *unstructured.Unstructured, checkToolNames bool) []ValidationIssue {
+	var issues []ValidationIssue
+
+	tools, _, _ := unstructured.NestedSlice(obj.Object, "spec", "declarative", "tools")
+	for i, t := range tools {
+		tool, _ := t.(map[string]interface{})
+
+		if name, _, _ := unstructured.NestedString(tool, "mcpServer", "name"); name != "" {
+			field := fmt.Sprintf("spec.declarative.tools[%d].mcpServer", i)
+			kind, _, _ := unstructured.NestedString(tool, "mcpServer", "kind")
+			if kind == "" {
+				kind = "MCPServer"
+			}
+			toolNames, _, _ := unstructured.NestedStringSlice(tool, "mcpServer", "toolNames")
+
+			gvr, ok := toolServerGVRs[kind]
+			if !ok {
+				issue := ValidationIssue{
+					Severity: "error",
+					Field:    field + ".kind",
+					Message:  fmt.Sprintf("Unknown tool server kind '%s'. Expected: MCPServer, RemoteMCPServer, or Service", kind),
+				}
+				for known := range toolServerGVRs {
+					if strings.EqualFold(known, kind) {
+						issue.Fix = []PatchOperation{{Op: "replace", Path: fmt.Sprintf("/spec/declarative/tools/%d/mcpServer/kind", i), Value: known}}
+					}
+				}
+				issues = append(issues, issue)
+				continue
+			}
+
+			refIssues := ts.checkReference(ctx, field+".name", kind, gvr, name, obj.GetNamespace())
+			issues = append(issues, refIssues...)
+			issues = append(issues, checkToolNameList(field+".toolNames", toolNames)...)
+			if bundleDefines(ctx, kind, name, obj.GetNamespace()) {
+				// Not deployed yet, so there is nothing to connect to
+				continue
+			}
+			if kind == "Service" && len(refIssues) == 0 {
+				issues = append(issues, ts.checkServiceReference(ctx, field+".name", name, obj.GetNamespace())...)
+			}
+			if checkToolNames && len(refIssues) == 0 && kind != "Service" {
+				issues = append(issues, ts.checkToolNamesExposed(ctx, i, kind, name, obj.GetNamespace(), toolNames)...)
+			}
+		}
+
+		if name, _, _ := unstructured.NestedString(tool, "agent", "name"); name != "" {
+			issues = append(issues, ts.checkReference(ctx, fmt.Sprintf("spec.declarative.tools[%d].agent.name", i), "Agent", kubernetes.AgentGVR, name, obj.GetNamespace())...)
+		}
+	}
+
+	return issues
+}
+
+// checkToolNameList reports tools listed more than once.
+func checkToolNameList(field string, toolNames []string) []ValidationIssue {
+	var issues []ValidationIssue
+	seen := map[string]bool{}
+	for j, toolName := range toolNames {
+		if seen[toolName] {
+			issues = append(issues, ValidationIssue{
+				Severity: "warning",
+				Field:    fmt.Sprintf("%s[%d]", field, j),
+				Message:  fmt.Sprintf("Tool '%s' is listed more than once", toolName),
+			})
+		}
+		seen[toolName] = true
+	}
+	return issues
+}
+
+// checkToolNamesExposed asks the MCP server behind tools[i] for its tool list
+// and reports toolNames it does not expose, suggesting the closest name. A
+// server that cannot be reached is a warning, since it may not be deployed
+// yet.
+func (ts *ToolServer) checkToolNamesExposed(ctx context.Context, i int, kind, ref, fromNamespace string, toolNames []string) []ValidationIssue {
+	field := fmt.Sprintf("spec.declarative.tools[%d].mcpServer.toolNames", i)
+	if fromNamespace == "" {
+		fromNamespace = ts.kube(ctx).Namespace()
+	}
+	parsed, err := types.ParseObjectRef(ref, fromNamespace)
+	if err != nil {
+		return nil
+	}
+
+	listing, _, err := ts.mcpToolListing(mcpserver.WithNamespace(ctx, parsed.Namespace), kind, parsed.Name, "", false)
+	if err != nil {
+		return []ValidationIssue{{
+			Severity: "warning",
+			Field:    field,
+			Message:  fmt.Sprintf("toolNames could not be checked against %s '%s': %v", kind, parsed, err),
+		}}
+	}
+
+	var issues []ValidationIssue
+	names := listing.names()
+	for j, toolName := range toolNames {
+		if _, ok := listing.find(toolName); ok {
+			continue
+		}
+		issue := ValidationIssue{
+			Severity: "error",
+			Field:    fmt.Sprintf("%s[%d]", field, j),
+			Message:  fmt.Sprintf("%s '%s' has no tool '%s'.%s", kind, parsed, toolName, toolNameHint(names, toolName)),
+		}
+		if closest, ok := closestKey(names, toolName); ok {
+			issue.Fix = []PatchOperation{{Op: "replace", Path: fmt.Sprintf("/spec/declarative/tools/%d/mcpServer/toolNames/%d", i, j), Value: closest}}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
@@ -0,0 +1,330 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/upgrade"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// registerRenameAgent registers the rename_agent tool.
+func (ts *ToolServer) registerRenameAgent() {
+	tool := mcp.NewTool("rename_agent",
+		mcp.WithDescription("Plan the rename of an Agent, which Kubernetes cannot do in place. Generates the Agent under its new name and updated manifests for everything pointing at the old one: agents calling it as a tool (in any namespace the server can read), and RemoteMCPServer URLs and environment variables using its in-cluster address, plus kubectl patches for Ingresses exposing it. Lists system messages and skills that still mention the old name, and outlines the order to apply the bundle and delete the old agent. Nothing is applied."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Current name of the agent"),
+		),
+		mcp.WithString("new_name",
+			mcp.Required(),
+			mcp.Description("New name for the agent (DNS-1123 label)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleRenameAgent)
+}
+
+// agentRename rewrites references to a renamed agent.
+type agentRename struct {
+	namespace string
+	oldName   string
+	newName   string
+	// oldHost and newHost are the agent's in-cluster Service hosts.
+	oldHost string
+	newHost string
+	// mention matches the old name as a word in free text.
+	mention *regexp.Regexp
+}
+
+func newAgentRename(namespace, oldName, newName string) *agentRename {
+	return &agentRename{
+		namespace: namespace,
+		oldName:   oldName,
+		newName:   newName,
+		oldHost:   fmt.Sprintf("%s.%s.svc", oldName, namespace),
+		newHost:   fmt.Sprintf("%s.%s.svc", newName, namespace),
+		mention:   regexp.MustCompile(`(^|[^-\w])` + regexp.QuoteMeta(oldName) + `($|[^-\w])`),
+	}
+}
+
+func (ts *ToolServer) handleRenameAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	newName := args.RequiredString("new_name")
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if errs := validation.IsDNS1123Label(newName); len(errs) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid new_name '%s': %s", newName, strings.Join(errs, "; "))), nil
+	}
+	if newName == name {
+		return mcp.NewToolResultError("new_name is the agent's current name"), nil
+	}
+
+	client := ts.kube(ctx)
+	agent, err := client.GetResource(ctx, kubernetes.AgentGVR, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Agent not found: %v", err)), nil
+	}
+	if _, err := client.GetResource(ctx, kubernetes.AgentGVR, newName); err == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' already exists in namespace '%s'", newName, client.Namespace())), nil
+	} else if !apierrors.IsNotFound(err) {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to check for agent '%s': %v", newName, err)), nil
+	}
+
+	r := newAgentRename(agent.GetNamespace(), name, newName)
+	var manifests, changes, mentions, notes []string
+	addManifest := func(obj *unstructured.Unstructured) {
+		output, _ := yaml.Marshal(obj.Object)
+		manifests = append(manifests, string(output))
+	}
+
+	// The agent under its new name keeps its test and flag ConfigMaps,
+	// which are found by the agent's name unless annotations point at them
+	renamed := upgrade.Clean(agent)
+	renamed.SetName(newName)
+	labels := renamed.GetLabels()
+	for key, value := range labels {
+		if value == name {
+			labels[key] = newName
+		}
+	}
+	renamed.SetLabels(labels)
+	annotations := renamed.GetAnnotations()
+	for annotation, configMap := range map[string]string{
+		testsAnnotation: testsConfigMapName(name, annotations),
+		flagsAnnotation: flagsConfigMapName(name, annotations),
+	} {
+		if annotations[annotation] != "" {
+			continue
+		}
+		if _, found, err := client.GetConfigMapData(ctx, configMap); err == nil && found {
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[annotation] = configMap
+			changes = append(changes, fmt.Sprintf("Agent '%s': annotation %s pins ConfigMap '%s'", newName, annotation, configMap))
+		}
+	}
+	renamed.SetAnnotations(annotations)
+	r.rewriteEnv(renamed, &changes)
+	addManifest(renamed)
+	mentions = append(mentions, r.mentions(renamed)...)
+
+	// Agents calling the agent as a tool, in every namespace the session
+	// may read
+	agentClient, err := ts.listClient(ctx, true, false)
+	if err != nil {
+		agentClient = client
+		notes = append(notes, fmt.Sprintf("Only agents in namespace '%s' were searched for references: %v", client.Namespace(), err))
+	}
+	agents, err := agentClient.ListResources(ctx, kubernetes.AgentGVR)
+	if apierrors.IsForbidden(err) && agentClient != client {
+		notes = append(notes, fmt.Sprintf("Only agents in namespace '%s' were searched for references: the server may not list agents in every namespace", client.Namespace()))
+		agents, err = client.ListResources(ctx, kubernetes.AgentGVR)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list agents: %v", err)), nil
+	}
+	for i := range agents {
+		other := &agents[i]
+		if other.GetNamespace() == r.namespace && other.GetName() == name {
+			continue
+		}
+		updated := upgrade.Clean(other)
+		changed := r.rewriteToolRefs(updated, &changes)
+		changed = r.rewriteEnv(updated, &changes) || changed
+		if changed {
+			addManifest(updated)
+		}
+		mentions = append(mentions, r.mentions(other)...)
+	}
+
+	// RemoteMCPServers reaching the agent through its Service
+	if servers, err := client.ListResources(ctx, kubernetes.RemoteMCPServerGVR); err == nil {
+		for i := range servers {
+			url, _, _ := unstructured.NestedString(servers[i].Object, "spec", "url")
+			if !strings.Contains(url, r.oldHost) {
+				continue
+			}
+			updated := upgrade.Clean(&servers[i])
+			_ = unstructured.SetNestedField(updated.Object, strings.ReplaceAll(url, r.oldHost, r.newHost), "spec", "url")
+			changes = append(changes, fmt.Sprintf("RemoteMCPServer '%s': spec.url", updated.GetName()))
+			addManifest(updated)
+		}
+	} else {
+		notes = append(notes, fmt.Sprintf("RemoteMCPServers were not searched: %v", err))
+	}
+
+	// Ingresses routing to the agent's Service, as expose_agent generates.
+	// apply_manifest does not take Ingresses, so they are patched with
+	// kubectl once the new agent's Service exists
+	var ingressPatches []string
+	if ingresses, err := client.ListResources(ctx, kubernetes.IngressGVR); err == nil {
+		for i := range ingresses {
+			if patch := r.ingressPatch(&ingresses[i]); len(patch) > 0 {
+				ops, _ := json.Marshal(patch)
+				ingressPatches = append(ingressPatches, fmt.Sprintf("kubectl -n %s patch ingress %s --type=json -p '%s'", ingresses[i].GetNamespace(), ingresses[i].GetName(), ops))
+			}
+		}
+	} else {
+		notes = append(notes, fmt.Sprintf("Ingresses were not searched: %v", err))
+	}
+
+	header := fmt.Sprintf(`# Rename Agent '%s' to '%s' (namespace %s)
+# Kubernetes cannot rename resources: the agent is re-created under the new
+# name and its references are moved over before the old agent is deleted.
+#
+# Steps:
+#   1. validate_manifest and diff_manifest the bundle below, then apply_manifest it.
+#      The first document creates Agent '%s'; the others point references at it.
+#   2. Wait until the new agent is Ready (get_agent_status name=%s).
+#   3. delete_agent name=%s. It refuses while agents still call the old one,
+#      so it confirms that every reference was moved.
+#
+# The A2A endpoint moves from http://%s.cluster.local to http://%s.cluster.local;
+# callers outside the cluster's kagent resources must be updated too.
+# Revisions and archives of the agent stay under its old name.`, name, newName, r.namespace, newName, newName, name, r.oldHost, r.newHost)
+	header += commentList("Changes", changes)
+	header += commentList("After step 2, point Ingresses at the new agent's Service", ingressPatches)
+	header += commentList("Review by hand (free text mentioning the old name)", mentions)
+	header += commentList("Notes", notes)
+
+	return out.render(header, strings.Join(manifests, "---\n"))
+}
+
+// commentList renders a titled list as manifest comment lines, or nothing
+// when the list is empty.
+func commentList(title string, items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	s := "\n#\n# " + title + ":"
+	for _, item := range items {
+		s += "\n#   - " + item
+	}
+	return s
+}
+
+// rewriteToolRefs points an agent's tool references to the renamed agent,
+// keeping their form: qualified references stay qualified.
+func (r *agentRename) rewriteToolRefs(agent *unstructured.Unstructured, changes *[]string) bool {
+	tools, _, _ := unstructured.NestedSlice(agent.Object, "spec", "declarative", "tools")
+	changed := false
+	for i, t := range tools {
+		tool, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ref, _, _ := unstructured.NestedString(tool, "agent", "name")
+		parsed, err := types.ParseObjectRef(ref, agent.GetNamespace())
+		if ref == "" || err != nil || parsed.Namespace != r.namespace || parsed.Name != r.oldName {
+			continue
+		}
+		newRef := r.newName
+		if strings.Contains(ref, "/") {
+			newRef = r.namespace + "/" + r.newName
+		}
+		_ = unstructured.SetNestedField(tool, newRef, "agent", "name")
+		*changes = append(*changes, fmt.Sprintf("Agent '%s/%s': spec.declarative.tools[%d].agent.name", agent.GetNamespace(), agent.GetName(), i))
+		changed = true
+	}
+	if changed {
+		_ = unstructured.SetNestedSlice(agent.Object, tools, "spec", "declarative", "tools")
+	}
+	return changed
+}
+
+// rewriteEnv moves environment values using the agent's in-cluster address
+// to its new one.
+func (r *agentRename) rewriteEnv(agent *unstructured.Unstructured, changes *[]string) bool {
+	changed := false
+	for _, path := range agentEnvPaths {
+		env, _, _ := unstructured.NestedSlice(agent.Object, path...)
+		pathChanged := false
+		for i, item := range env {
+			e, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			value, _, _ := unstructured.NestedString(e, "value")
+			if !strings.Contains(value, r.oldHost) {
+				continue
+			}
+			e["value"] = strings.ReplaceAll(value, r.oldHost, r.newHost)
+			*changes = append(*changes, fmt.Sprintf("Agent '%s/%s': %s[%d].value", agent.GetNamespace(), agent.GetName(), strings.Join(path, "."), i))
+			pathChanged = true
+		}
+		if pathChanged {
+			_ = unstructured.SetNestedSlice(agent.Object, env, path...)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// ingressPatch returns the JSON Patch pointing the backends of an Ingress
+// that route to the agent's Service at the renamed agent's.
+func (r *agentRename) ingressPatch(ingress *unstructured.Unstructured) []PatchOperation {
+	var patch []PatchOperation
+	rules, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "rules")
+	for i, item := range rules {
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+		for j, p := range paths {
+			path, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if service, _, _ := unstructured.NestedString(path, "backend", "service", "name"); service == r.oldName {
+				patch = append(patch, PatchOperation{Op: "replace", Path: fmt.Sprintf("/spec/rules/%d/http/paths/%d/backend/service/name", i, j), Value: r.newName})
+			}
+		}
+	}
+	return patch
+}
+
+// mentions lists the free-text fields of an agent that mention the old
+// name: system messages and A2A skills, which may tell users or other
+// agents to call it.
+func (r *agentRename) mentions(agent *unstructured.Unstructured) []string {
+	var found []string
+	where := fmt.Sprintf("Agent '%s/%s'", agent.GetNamespace(), agent.GetName())
+	if message, _, _ := unstructured.NestedString(agent.Object, "spec", "declarative", "systemMessage"); r.mention.MatchString(message) {
+		found = append(found, where+": spec.declarative.systemMessage")
+	}
+	for _, path := range [][]string{{"spec", "a2aConfig", "skills"}, {"spec", "declarative", "a2aConfig", "skills"}} {
+		skills, _, _ := unstructured.NestedSlice(agent.Object, path...)
+		for i, s := range skills {
+			skill, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			text, _ := yaml.Marshal(skill)
+			if r.mention.Match(text) {
+				id, _, _ := unstructured.NestedString(skill, "id")
+				found = append(found, fmt.Sprintf("%s: %s[%d] (skill '%s')", where, strings.Join(path, "."), i, id))
+			}
+		}
+	}
+	return found
+}
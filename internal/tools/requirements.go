@@ -0,0 +1,363 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	mcpserver "github.com/kagent-dev/meta-kagent/internal/server"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// Requirement coverage.
+const (
+	requirementCovered = "covered"
+	requirementPartial = "partial"
+	requirementMissing = "missing"
+)
+
+// requirementSynonyms widens the words of a requirement to the verbs and
+// names tools commonly use for them.
+var requirementSynonyms = map[string][]string{
+	"read":       {"get", "list", "describe", "watch", "fetch", "view"},
+	"write":      {"create", "update", "apply", "patch", "delete", "set"},
+	"notify":     {"send", "post", "message", "alert", "notification"},
+	"query":      {"search", "fetch", "get", "promql"},
+	"kubernetes": {"k8s", "kube", "kubectl"},
+	"k8s":        {"kubernetes", "kube", "kubectl"},
+	"metrics":    {"prometheus", "promql"},
+	"logs":       {"log", "loki"},
+}
+
+// requirementStopWords are ignored when matching.
+var requirementStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "to": true, "of": true,
+	"in": true, "on": true, "for": true, "with": true, "from": true, "can": true,
+}
+
+// capability is something an agent can do, described by words matched
+// against requirements.
+type capability struct {
+	Source string
+	words  map[string]bool
+}
+
+// RequirementCoverage is how an agent covers one requirement.
+type RequirementCoverage struct {
+	Requirement string   `json:"requirement"`
+	Status      string   `json:"status"`
+	CoveredBy   []string `json:"coveredBy,omitempty"`
+	// Unmatched lists the words of a partly covered requirement that no
+	// capability matches.
+	Unmatched []string `json:"unmatched,omitempty"`
+}
+
+// AgentRequirements is how an agent measures up against a requirements
+// spec.
+type AgentRequirements struct {
+	Agent        string                `json:"agent"`
+	Satisfied    bool                  `json:"satisfied"`
+	Covered      int                   `json:"covered"`
+	Requirements []RequirementCoverage `json:"requirements"`
+	Missing      []string              `json:"missing,omitempty"`
+}
+
+// registerCheckRequirements registers the check_requirements tool.
+func (ts *ToolServer) registerCheckRequirements() {
+	tool := mcp.NewTool("check_requirements",
+		mcp.WithDescription("Check whether an existing agent satisfies a requirements spec (e.g., 'kubernetes read, prometheus query, slack notify'): for each requirement, which of the agent's MCP tools, agents it calls and A2A skills cover it, and what is missing. Without an agent, ranks every agent in the namespace by coverage, to decide whether to reuse or extend an agent or build a new one. Matching is by keyword, with common verb synonyms (read matches get and list)."),
+		mcp.WithString("requirements",
+			mcp.Required(),
+			mcp.Description("Comma-separated list of requirements, each a few words (e.g., 'kubernetes read, prometheus query, slack notify')"),
+		),
+		mcp.WithString("agent",
+			mcp.Description("Name of the agent to check. Omit to rank every agent"),
+		),
+		mcp.WithBoolean("inspect_servers",
+			mcp.Description("Connect to the referenced MCP servers to match their tool names and descriptions, including servers whose tools are not listed on the agent (default: false, match only names and descriptions on the resources)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("How many agents to show when ranking (default: 5)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleCheckRequirements)
+}
+
+func (ts *ToolServer) handleCheckRequirements(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	requirements := args.StringList("requirements")
+	agentName := args.String("agent")
+	inspect := args.Bool("inspect_servers", false)
+	limit := args.IntRange("limit", 5, 1, 100)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(requirements) == 0 {
+		return mcp.NewToolResultError("requirements must list at least one requirement"), nil
+	}
+
+	var agents []types.Agent
+	if agentName != "" {
+		agent, err := ts.kube(ctx).GetAgent(ctx, agentName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+		}
+		agents = []types.Agent{*agent}
+	} else {
+		var err error
+		agents, err = ts.kube(ctx).ListAgents(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list agents: %v", err)), nil
+		}
+	}
+
+	descriptions, err := ts.toolServerDescriptions(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var results []AgentRequirements
+	for i := range agents {
+		caps := ts.agentCapabilities(ctx, &agents[i], descriptions, inspect)
+		results = append(results, matchRequirements(agents[i].Name, requirements, caps))
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Covered > results[j].Covered })
+
+	var b strings.Builder
+	switch {
+	case len(results) == 0:
+		return mcp.NewToolResultText("No agents in the namespace. Nothing can be reused: build a new agent with create_agent_manifest."), nil
+	case agentName != "" && results[0].Satisfied:
+		fmt.Fprintf(&b, "# Requirements: ✓ agent '%s' satisfies all %d requirement(s)\n", agentName, len(requirements))
+	case agentName != "":
+		fmt.Fprintf(&b, "# Requirements: ❌ agent '%s' covers %d of %d requirement(s)\n\nMissing: %s\n",
+			agentName, results[0].Covered, len(requirements), strings.Join(results[0].Missing, ", "))
+	case results[0].Satisfied:
+		fmt.Fprintf(&b, "# Requirements: reuse agent '%s', which satisfies all %d requirement(s)\n", results[0].Agent, len(requirements))
+	default:
+		fmt.Fprintf(&b, "# Requirements: no agent satisfies all %d requirement(s)\n\nClosest: '%s' covers %d; missing %s. Extend it with the missing tools (update_agent_manifest) or build a new agent.\n",
+			len(requirements), results[0].Agent, results[0].Covered, strings.Join(results[0].Missing, ", "))
+	}
+	if len(results) > limit {
+		fmt.Fprintf(&b, "\nShowing the top %d of %d agents.\n", limit, len(results))
+		results = results[:limit]
+	}
+
+	output, _ := json.MarshalIndent(results, "", "  ")
+	return mcp.NewToolResultText(b.String() + "\n" + string(output)), nil
+}
+
+// toolServerDescriptions returns the descriptions of the MCPServers and
+// RemoteMCPServers in the namespace, keyed by "Kind/name".
+func (ts *ToolServer) toolServerDescriptions(ctx context.Context) (map[string]string, error) {
+	descriptions := map[string]string{}
+	servers, err := ts.kube(ctx).ListMCPServers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MCP servers: %w", err)
+	}
+	for _, s := range servers {
+		descriptions["MCPServer/"+s.Name] = s.Spec.Description
+	}
+	remotes, err := ts.kube(ctx).ListRemoteMCPServers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote MCP servers: %w", err)
+	}
+	for _, s := range remotes {
+		descriptions["RemoteMCPServer/"+s.Name] = s.Spec.Description
+	}
+	return descriptions, nil
+}
+
+// agentCapabilities collects what an agent can do: each MCP tool it may
+// call (or each server, when it may call all of a server's tools), each
+// agent it calls and each A2A skill it declares.
+func (ts *ToolServer) agentCapabilities(ctx context.Context, agent *types.Agent, descriptions map[string]string, inspect bool) []capability {
+	var caps []capability
+
+	if agent.Spec.Declarative != nil {
+		for _, tool := range agent.Spec.Declarative.Tools {
+			switch {
+			case tool.McpServer != nil:
+				caps = append(caps, ts.serverCapabilities(ctx, agent.Namespace, tool.McpServer, descriptions, inspect)...)
+			case tool.Agent != nil:
+				caps = append(caps, newCapability("agent "+tool.Agent.Name, tool.Agent.Name))
+			}
+		}
+	}
+
+	if a2a := getA2AConfig(agent); a2a != nil {
+		for _, skill := range a2a.Skills {
+			caps = append(caps, newCapability("skill "+skill.ID, skill.ID, skill.Name, skill.Description, strings.Join(skill.Tags, " ")))
+		}
+	}
+	return caps
+}
+
+// serverCapabilities describes the tools an agent may call on one server.
+func (ts *ToolServer) serverCapabilities(ctx context.Context, namespace string, ref *types.McpServerRef, descriptions map[string]string, inspect bool) []capability {
+	kind := ref.Kind
+	if kind == "" {
+		kind = "MCPServer"
+	}
+	server := ref.Name
+	description := descriptions[kind+"/"+ref.Name]
+
+	var listed map[string]string
+	if inspect && kind != "Service" {
+		parsed, err := types.ParseObjectRef(ref.Name, namespace)
+		if err == nil {
+			listing, _, err := ts.mcpToolListing(mcpserver.WithNamespace(ctx, parsed.Namespace), kind, parsed.Name, "", false)
+			if err == nil {
+				listed = map[string]string{}
+				for _, t := range listing.Tools {
+					listed[t.Name] = t.Description
+				}
+			}
+		}
+	}
+
+	names := ref.ToolNames
+	if len(names) == 0 {
+		if listed == nil {
+			// Every tool of the server, known only by the server itself
+			return []capability{newCapability(fmt.Sprintf("%s %s (all tools)", kind, server), server, description)}
+		}
+		names = sortedKeys(listed)
+	}
+
+	caps := make([]capability, 0, len(names))
+	for _, name := range names {
+		caps = append(caps, newCapability(fmt.Sprintf("tool %s/%s", server, name), server, description, name, listed[name]))
+	}
+	return caps
+}
+
+// matchRequirements checks each requirement against an agent's
+// capabilities. A requirement is covered when one capability matches all of
+// its words, and partly covered when capabilities together match some.
+func matchRequirements(agent string, requirements []string, caps []capability) AgentRequirements {
+	result := AgentRequirements{Agent: agent}
+	for _, requirement := range requirements {
+		words := requirementWords(requirement)
+		coverage := RequirementCoverage{Requirement: requirement, Status: requirementMissing}
+
+		matched := map[string]bool{}
+		for _, c := range caps {
+			all := true
+			for _, w := range words {
+				if c.matches(w) {
+					matched[w] = true
+				} else {
+					all = false
+				}
+			}
+			if all && len(words) > 0 {
+				coverage.Status = requirementCovered
+				coverage.CoveredBy = append(coverage.CoveredBy, c.Source)
+			}
+		}
+
+		if coverage.Status != requirementCovered && len(matched) > 0 {
+			coverage.Status = requirementPartial
+			for _, w := range words {
+				if !matched[w] {
+					coverage.Unmatched = append(coverage.Unmatched, w)
+				}
+			}
+		}
+		if coverage.Status == requirementCovered {
+			result.Covered++
+		} else {
+			result.Missing = append(result.Missing, requirement)
+		}
+		result.Requirements = append(result.Requirements, coverage)
+	}
+	result.Satisfied = result.Covered == len(requirements)
+	return result
+}
+
+func newCapability(source string, texts ...string) capability {
+	c := capability{Source: source, words: map[string]bool{}}
+	for _, text := range texts {
+		for _, w := range splitWords(text) {
+			c.words[stem(w)] = true
+		}
+	}
+	return c
+}
+
+// matches reports whether a requirement word, or one of its synonyms,
+// appears in the capability.
+func (c capability) matches(word string) bool {
+	if c.words[stem(word)] {
+		return true
+	}
+	for _, synonym := range requirementSynonyms[word] {
+		if c.words[stem(synonym)] {
+			return true
+		}
+	}
+	return false
+}
+
+// requirementWords returns the significant words of a requirement.
+func requirementWords(requirement string) []string {
+	var words []string
+	for _, w := range splitWords(requirement) {
+		if !requirementStopWords[w] {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// splitWords lower-cases text and splits it into words at anything but
+// letters and digits and at camelCase boundaries, so "k8s_get_pods" and
+// "getPods" yield "get" and "pods".
+func splitWords(text string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+	var prev rune
+	for _, r := range text {
+		switch {
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush()
+			current = append(current, unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			current = append(current, unicode.ToLower(r))
+		default:
+			flush()
+		}
+		prev = r
+	}
+	flush()
+	return words
+}
+
+// stem reduces a word to a crude stem so that plurals and common verb and
+// noun forms compare equal (queries/query, notify/notification).
+func stem(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && len(word) > 3:
+		word = word[:len(word)-1]
+	}
+	if len(word) > 5 {
+		return word[:5]
+	}
+	return word
+}
@@ -0,0 +1,290 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// Annotations recording the resource usage observed for a workload.
+const (
+	observedCPUAnnotation    = "kagent.dev/observed-cpu"
+	observedMemoryAnnotation = "kagent.dev/observed-memory"
+	observedSourceAnnotation = "kagent.dev/observed-source"
+)
+
+// overprovisionFactor is how many times the observed usage a request may be
+// before validation warns about it.
+const overprovisionFactor = 4
+
+// Floors for recommended requests, so that idle workloads keep room to start.
+var (
+	minRecommendedCPU    = resource.MustParse("10m")
+	minRecommendedMemory = resource.MustParse("64Mi")
+)
+
+// registerRecommendResources registers the recommend_resources tool.
+func (ts *ToolServer) registerRecommendResources() {
+	tool := mcp.NewTool("recommend_resources",
+		mcp.WithDescription("Recommend CPU and memory requests and limits for an agent or MCPServer from its VerticalPodAutoscaler recommendation, or from current metrics-server usage plus headroom. Generates the updated manifest with the new resources block and the observed usage recorded in kagent.dev/observed-* annotations, which validate_manifest uses to flag over-provisioned requests."),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Kind of the workload: Agent or MCPServer"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the agent or MCPServer"),
+		),
+		mcp.WithNumber("headroom",
+			mcp.Description("Percentage added to observed metrics-server usage; VPA targets are used as-is (default: 30)"),
+		),
+		mcp.WithNumber("limit_ratio",
+			mcp.Description("Limits as a multiple of the recommended requests (default: 2)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleRecommendResources)
+}
+
+func (ts *ToolServer) handleRecommendResources(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.RequiredEnum("kind", "Agent", "MCPServer")
+	name := args.RequiredString("name")
+	headroom := args.IntRange("headroom", 30, 0, 500)
+	limitRatio := args.Float("limit_ratio", 2)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if limitRatio < 1 {
+		return mcp.NewToolResultError(fmt.Sprintf("limit_ratio must be at least 1, got %g", limitRatio)), nil
+	}
+
+	// kagent names the Deployment of an agent or MCPServer after it
+	deployment, err := ts.kube(ctx).GetResource(ctx, kubernetes.DeploymentGVR, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get the Deployment of %s '%s' (is it deployed?): %v", kind, name, err)), nil
+	}
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	if len(containers) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Deployment '%s' has no containers", name)), nil
+	}
+	first, _ := containers[0].(map[string]interface{})
+	containerName, _, _ := unstructured.NestedString(first, "name")
+
+	observed, source, err := ts.observedUsage(ctx, deployment, containerName, headroom)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	recommended := &types.ResourceRequirements{
+		Requests: map[string]string{
+			"cpu":    roundCPU(atLeast(observed.CPU, minRecommendedCPU)),
+			"memory": roundMemory(atLeast(observed.Memory, minRecommendedMemory)),
+		},
+	}
+	recommended.Limits = map[string]string{
+		"cpu":    roundCPU(scaleQuantity(resource.MustParse(recommended.Requests["cpu"]), limitRatio)),
+		"memory": roundMemory(scaleQuantity(resource.MustParse(recommended.Requests["memory"]), limitRatio)),
+	}
+
+	observedAnnotations := map[string]string{
+		observedCPUAnnotation:    observed.CPU.String(),
+		observedMemoryAnnotation: observed.Memory.String(),
+		observedSourceAnnotation: source,
+	}
+
+	var current *types.ResourceRequirements
+	var manifest []byte
+	switch kind {
+	case "Agent":
+		agent, err := ts.kube(ctx).GetAgent(ctx, name)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+		}
+		switch {
+		case agent.Spec.Declarative != nil:
+			if agent.Spec.Declarative.Deployment == nil {
+				agent.Spec.Declarative.Deployment = &types.DeclarativeDeploymentSpec{}
+			}
+			current = agent.Spec.Declarative.Deployment.Resources
+			agent.Spec.Declarative.Deployment.Resources = recommended
+		case agent.Spec.BYO != nil && agent.Spec.BYO.Deployment != nil:
+			current = agent.Spec.BYO.Deployment.Resources
+			agent.Spec.BYO.Deployment.Resources = recommended
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' has no deployment to configure", name)), nil
+		}
+		agent.Annotations = mergeAnnotations(agent.Annotations, observedAnnotations)
+		agent.APIVersion = "kagent.dev/v1alpha2"
+		agent.Kind = "Agent"
+		manifest, _ = yaml.Marshal(agent)
+	case "MCPServer":
+		server, err := ts.kube(ctx).GetMCPServer(ctx, name)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get MCP server: %v", err)), nil
+		}
+		if server.Spec.Deployment == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("MCPServer '%s' has no deployment to configure", name)), nil
+		}
+		current = server.Spec.Deployment.Resources
+		server.Spec.Deployment.Resources = recommended
+		server.Annotations = mergeAnnotations(server.Annotations, observedAnnotations)
+		server.APIVersion = "kagent.dev/v1alpha1"
+		server.Kind = "MCPServer"
+		manifest, _ = yaml.Marshal(server)
+	}
+
+	header := fmt.Sprintf(`# Resource Recommendation for %s '%s' (container '%s')
+# Source: %s
+# Observed: cpu=%s memory=%s
+# Current:     %s
+# Recommended: %s
+# IMPORTANT: Review the changes before applying.
+# Use diff_manifest to see changes, then apply_manifest to deploy.`,
+		kind, name, containerName, source, observed.CPU.String(), observed.Memory.String(),
+		formatResources(current), formatResources(recommended))
+
+	return out.render(header, string(manifest))
+}
+
+// observedUsage returns the usage to size a container for: the VPA target
+// when a VPA has a recommendation, otherwise the peak metrics-server usage
+// across the Deployment's pods plus headroom percent.
+func (ts *ToolServer) observedUsage(ctx context.Context, deployment *unstructured.Unstructured, container string, headroom int) (kubernetes.ContainerUsage, string, error) {
+	recs, vpaKnown, err := ts.kube(ctx).VPARecommendations(ctx, deployment.GetName())
+	if err != nil {
+		return kubernetes.ContainerUsage{}, "", err
+	}
+	if rec, ok := recs[container]; ok {
+		return rec, "vpa", nil
+	}
+
+	matchLabels, _, _ := unstructured.NestedStringMap(deployment.Object, "spec", "selector", "matchLabels")
+	if len(matchLabels) == 0 {
+		return kubernetes.ContainerUsage{}, "", fmt.Errorf("Deployment '%s' has no label selector to find its pods", deployment.GetName())
+	}
+	usage, metricsKnown, err := ts.kube(ctx).PodUsage(ctx, labels.SelectorFromSet(matchLabels).String())
+	if err != nil {
+		return kubernetes.ContainerUsage{}, "", err
+	}
+	peak, ok := usage[container]
+	if !ok {
+		var missing []string
+		if !vpaKnown {
+			missing = append(missing, "the VerticalPodAutoscaler API is not available")
+		} else {
+			missing = append(missing, "no VerticalPodAutoscaler recommendation targets it")
+		}
+		if !metricsKnown {
+			missing = append(missing, "the metrics API (metrics-server) is not available")
+		} else {
+			missing = append(missing, "no pod metrics were found (are its pods running?)")
+		}
+		return kubernetes.ContainerUsage{}, "", fmt.Errorf("No usage data for Deployment '%s': %s", deployment.GetName(), strings.Join(missing, "; "))
+	}
+
+	factor := 1 + float64(headroom)/100
+	return kubernetes.ContainerUsage{
+		CPU:    scaleQuantity(peak.CPU, factor),
+		Memory: scaleQuantity(peak.Memory, factor),
+	}, fmt.Sprintf("metrics-server (peak usage + %d%%)", headroom), nil
+}
+
+// checkOverprovisioned warns when resource requests are far above the usage
+// recorded in the kagent.dev/observed-* annotations.
+func checkOverprovisioned(obj *unstructured.Unstructured, path ...string) []ValidationIssue {
+	annotations := obj.GetAnnotations()
+	requests, _, _ := unstructured.NestedStringMap(obj.Object, append(path, "requests")...)
+	if len(requests) == 0 {
+		return nil
+	}
+
+	var issues []ValidationIssue
+	for _, r := range []struct{ name, annotation string }{
+		{"cpu", observedCPUAnnotation},
+		{"memory", observedMemoryAnnotation},
+	} {
+		observed, err := resource.ParseQuantity(annotations[r.annotation])
+		if err != nil || observed.IsZero() {
+			continue
+		}
+		requested, err := resource.ParseQuantity(requests[r.name])
+		if err != nil {
+			continue
+		}
+		if requested.Cmp(scaleQuantity(observed, overprovisionFactor)) > 0 {
+			issues = append(issues, ValidationIssue{
+				Severity: "warning",
+				Field:    strings.Join(append(path, "requests", r.name), "."),
+				Message: fmt.Sprintf("Requested %s %s is more than %dx the observed usage of %s. Consider recommend_resources to right-size it.",
+					r.name, requested.String(), overprovisionFactor, observed.String()),
+			})
+		}
+	}
+	return issues
+}
+
+// scaleQuantity multiplies a quantity by factor.
+func scaleQuantity(q resource.Quantity, factor float64) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(math.Ceil(float64(q.MilliValue())*factor)), q.Format)
+}
+
+// atLeast returns q, or min when q is smaller.
+func atLeast(q, min resource.Quantity) resource.Quantity {
+	if q.Cmp(min) < 0 {
+		return min
+	}
+	return q
+}
+
+// roundCPU rounds a CPU quantity up to whole millicores.
+func roundCPU(q resource.Quantity) string {
+	return fmt.Sprintf("%dm", q.MilliValue())
+}
+
+// roundMemory rounds a memory quantity up to whole mebibytes.
+func roundMemory(q resource.Quantity) string {
+	const mi = 1 << 20
+	return fmt.Sprintf("%dMi", (q.Value()+mi-1)/mi)
+}
+
+// formatResources renders a resources block on one line.
+func formatResources(r *types.ResourceRequirements) string {
+	if r == nil {
+		return "(none)"
+	}
+	return fmt.Sprintf("requests cpu=%s memory=%s, limits cpu=%s memory=%s",
+		valueOr(r.Requests["cpu"]), valueOr(r.Requests["memory"]), valueOr(r.Limits["cpu"]), valueOr(r.Limits["memory"]))
+}
+
+func valueOr(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
+
+// mergeAnnotations sets add in annotations, allocating it if needed.
+func mergeAnnotations(annotations, add map[string]string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for k, v := range add {
+		annotations[k] = v
+	}
+	return annotations
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/kagent-dev/meta-kagent/internal/state"
+)
+
+// reviewTTL is how long a reviewed diff can be applied by ID.
+const reviewTTL = time.Hour
+
+// reviewedManifest is a manifest whose diff was shown to the user.
+type reviewedManifest struct {
+	Manifest  string    `json:"manifest"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Owner     string    `json:"owner,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// reviewStore keeps manifests returned by diff_manifest so apply_manifest can
+// apply exactly what was reviewed, referenced by diff ID. Reviews live in the
+// server's state store, so with persistence configured a diff ID stays valid
+// across restarts.
+type reviewStore struct {
+	state state.Store
+}
+
+func newReviewStore(s state.Store) *reviewStore {
+	return &reviewStore{state: s}
+}
+
+// Add records a manifest reviewed by owner and returns its diff ID.
+func (s *reviewStore) Add(ctx context.Context, manifest, kind, name, owner string) (string, error) {
+	id := "diff-" + randomHex(8)
+	err := state.PutJSON(ctx, s.state, reviewKey(id), reviewedManifest{
+		Manifest:  manifest,
+		Kind:      kind,
+		Name:      name,
+		Owner:     owner,
+		CreatedAt: time.Now(),
+	}, reviewTTL)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Get returns the manifest owner reviewed under a diff ID, if it exists and
+// has not expired.
+func (s *reviewStore) Get(ctx context.Context, id, owner string) (reviewedManifest, bool, error) {
+	var r reviewedManifest
+	ok, err := state.GetJSON(ctx, s.state, reviewKey(id), &r)
+	if err != nil || !ok || r.Owner != owner {
+		return reviewedManifest{}, false, err
+	}
+	return r, true, nil
+}
+
+// Delete removes a diff ID once it has been applied.
+func (s *reviewStore) Delete(ctx context.Context, id string) error {
+	_, err := s.state.Delete(ctx, reviewKey(id))
+	return err
+}
+
+func reviewKey(id string) string {
+	return "review/" + id
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,337 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/revisions"
+	"github.com/kagent-dev/meta-kagent/internal/upgrade"
+)
+
+// registerDiffRevisions registers the diff_revisions tool.
+func (ts *ToolServer) registerDiffRevisions() {
+	tool := mcp.NewTool("diff_revisions",
+		mcp.WithDescription("Diff two historical revisions of an agent's spec, or a revision against the live agent. Revisions are recorded whenever an agent is applied through apply_manifest. Answers questions like 'what changed in this agent since Tuesday?'."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the agent"),
+		),
+		mcp.WithString("from",
+			mcp.Description("Older side of the diff: a revision number, an RFC 3339 timestamp, or a date (YYYY-MM-DD). Timestamps and dates select the latest revision at or before that time. Default: the revision before 'to'"),
+		),
+		mcp.WithString("to",
+			mcp.Description("Newer side of the diff, in the same formats as 'from', or 'live' for the current cluster state (default: 'live')"),
+		),
+		withDiffFormatOption(),
+		withStructuredOutputOption(),
+	)
+
+	ts.addTool(tool, ts.handleDiffRevisions)
+}
+
+func (ts *ToolServer) handleDiffRevisions(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	fromRef := args.String("from")
+	toRef := args.StringDefault("to", revisions.Live)
+	renderer := diffRendererFrom(args)
+	asStructured := structuredOutputFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	history, err := ts.revisionStore(ctx).List(ctx, "Agent", name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read revisions: %v", err)), nil
+	}
+	if len(history) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No revisions recorded for agent '%s'. Revisions are recorded when the agent is applied with apply_manifest.", name)), nil
+	}
+
+	// Resolve the newer side first so 'from' can default relative to it
+	var toSpec map[string]interface{}
+	var toLabel string
+	toIndex := len(history)
+	if toRef == revisions.Live {
+		live, err := ts.kube(ctx).GetResource(ctx, kubernetes.AgentGVR, name)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+		}
+		toSpec, _, _ = unstructured.NestedMap(live.Object, "spec")
+		toLabel = "live"
+	} else {
+		to, err := revisions.Resolve(history, toRef)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		toSpec = to.Spec
+		toLabel = revisionLabel(to)
+		toIndex = revisionIndex(history, to.Number)
+	}
+
+	var from *revisions.Revision
+	if fromRef != "" {
+		from, err = revisions.Resolve(history, fromRef)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	} else {
+		if toIndex == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is the oldest recorded revision; specify 'from'", toLabel)), nil
+		}
+		from = &history[toIndex-1]
+	}
+
+	// Diff under spec so field paths and patches address the agent itself
+	changes, err := renderer.Render(map[string]interface{}{"spec": from.Spec}, map[string]interface{}{"spec": toSpec})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to render the diff: %v", err)), nil
+	}
+	if changes == "" {
+		if asStructured {
+			return structured(structuredResult{Summary: fmt.Sprintf("No changes in agent '%s' between %s and %s.", name, revisionLabel(from), toLabel)})
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("No changes in agent '%s' between %s and %s.", name, revisionLabel(from), toLabel)), nil
+	}
+	if asStructured {
+		return structured(structuredResult{
+			Summary: fmt.Sprintf("Changes to the spec of agent '%s' from %s to %s.", name, revisionLabel(from), toLabel),
+			Diff:    changes,
+		})
+	}
+
+	result := fmt.Sprintf(`# Revision Diff: Agent '%s'
+# From: %s
+# To:   %s
+
+Changes to spec:
+
+%s`, name, revisionLabel(from), toLabel, changes)
+	if legend := renderer.Legend(); legend != "" {
+		result += "\n\n" + legend
+	}
+
+	return mcp.NewToolResultText(result), nil
+}
+
+// recordRevision records the spec of an applied resource, after the spec it
+// replaced if that is not already the latest revision. Failures are logged
+// rather than failing the apply, since the change is already live.
+func (ts *ToolServer) recordRevision(ctx context.Context, obj *unstructured.Unstructured, action string, previous map[string]interface{}) {
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	if _, err := ts.revisionStore(ctx).Record(ctx, obj.GetKind(), obj.GetName(), action, previous, spec); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to record revision of %s %s: %v\n", obj.GetKind(), obj.GetName(), err)
+	}
+}
+
+func revisionLabel(r *revisions.Revision) string {
+	return fmt.Sprintf("revision %d (%s, %s)", r.Number, r.Action, r.Timestamp.Format("2006-01-02 15:04:05 UTC"))
+}
+
+func revisionIndex(history []revisions.Revision, number int) int {
+	for i, r := range history {
+		if r.Number == number {
+			return i
+		}
+	}
+	return len(history)
+}
+
+// registerListRevisions registers the list_revisions tool.
+func (ts *ToolServer) registerListRevisions() {
+	tool := mcp.NewTool("list_revisions",
+		mcp.WithDescription("List the recorded revisions of an agent, ModelConfig, MCPServer or RemoteMCPServer, newest first, with the spec fields each changed. A revision is recorded whenever apply_manifest creates or updates the resource; when an update replaces a spec that was changed elsewhere, that spec is recorded first as an 'observed' revision. Use it to pick a revision for rollback_resource or diff_revisions."),
+		mcp.WithString("kind",
+			mcp.Description("Kind of the resource: Agent, ModelConfig, MCPServer or RemoteMCPServer (default: Agent)"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the resource"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleListRevisions)
+}
+
+func (ts *ToolServer) handleListRevisions(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.Enum("kind", "Agent", "Agent", "ModelConfig", "MCPServer", "RemoteMCPServer")
+	name := args.RequiredString("name")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	history, err := ts.revisionStore(ctx).List(ctx, kind, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read revisions: %v", err)), nil
+	}
+	if len(history) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No revisions recorded for %s '%s'. Revisions are recorded when the resource is applied with apply_manifest.", kind, name)), nil
+	}
+
+	// Mark the revision the live resource is at, if any
+	gvr, _ := kagentGVR(kind)
+	var liveSpec map[string]interface{}
+	liveNote := ""
+	if live, err := ts.kube(ctx).GetResource(ctx, gvr, name); err == nil {
+		liveSpec, _, _ = unstructured.NestedMap(live.Object, "spec")
+	} else if apierrors.IsNotFound(err) {
+		liveNote = fmt.Sprintf("\n%s '%s' no longer exists; rollback_resource recreates it from a revision.", kind, name)
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Revisions of %s '%s' (newest first)\n\n", kind, name))
+	b.WriteString("| Revision | Recorded | Action | Changed | Live |\n")
+	b.WriteString("|----------|----------|--------|---------|------|\n")
+	for i := len(history) - 1; i >= 0; i-- {
+		r := history[i]
+		changed := "-"
+		if i > 0 {
+			if fields := changedFields(history[i-1].Spec, r.Spec); len(fields) > 0 {
+				changed = strings.Join(fields, ", ")
+			}
+		}
+		live := ""
+		if liveSpec != nil && reflect.DeepEqual(liveSpec, r.Spec) {
+			live = "yes"
+		}
+		b.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s |\n", r.Number, r.Timestamp.Format("2006-01-02 15:04:05 UTC"), r.Action, changed, live))
+	}
+	b.WriteString(liveNote)
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// registerRollbackResource registers the rollback_resource tool.
+func (ts *ToolServer) registerRollbackResource() {
+	tool := mcp.NewTool("rollback_resource",
+		mcp.WithDescription("Restore the spec of an agent, ModelConfig, MCPServer or RemoteMCPServer from a recorded revision, to undo a bad change. Shows the change as a diff against the live resource; the live resource's labels, annotations and status are kept. A deleted resource is recreated. The rollback is applied only if nobody changed the resource since it was read, and is itself recorded as a new revision, so it can be undone too. Use list_revisions to pick a revision."),
+		mcp.WithString("kind",
+			mcp.Description("Kind of the resource: Agent, ModelConfig, MCPServer or RemoteMCPServer (default: Agent)"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the resource"),
+		),
+		mcp.WithString("revision",
+			mcp.Description("Revision to restore: a revision number, an RFC 3339 timestamp or a date (YYYY-MM-DD), as in diff_revisions. Default: the latest revision that differs from the live spec"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("If true, show the rollback and validate it against the cluster without applying it"),
+		),
+		withDiffFormatOption(),
+	)
+
+	ts.addTool(tool, ts.handleRollbackResource)
+}
+
+func (ts *ToolServer) handleRollbackResource(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.Enum("kind", "Agent", "Agent", "ModelConfig", "MCPServer", "RemoteMCPServer")
+	name := args.RequiredString("name")
+	ref := args.String("revision")
+	dryRun := args.Bool("dry_run", false)
+	renderer := diffRendererFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	history, err := ts.revisionStore(ctx).List(ctx, kind, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read revisions: %v", err)), nil
+	}
+	if len(history) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("No revisions recorded for %s '%s', so there is nothing to roll back to. Revisions are recorded when the resource is applied with apply_manifest.", kind, name)), nil
+	}
+
+	client := ts.kube(ctx)
+	gvr, _ := kagentGVR(kind)
+	live, err := client.GetResource(ctx, gvr, name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get %s '%s': %v", kind, name, err)), nil
+	}
+	var liveSpec map[string]interface{}
+	if live != nil {
+		liveSpec, _, _ = unstructured.NestedMap(live.Object, "spec")
+	}
+
+	var target *revisions.Revision
+	if ref != "" {
+		if target, err = revisions.Resolve(history, ref); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	} else {
+		for i := len(history) - 1; i >= 0; i-- {
+			if !reflect.DeepEqual(history[i].Spec, liveSpec) {
+				target = &history[i]
+				break
+			}
+		}
+		if target == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Every recorded revision of %s '%s' matches the live spec; there is no earlier version to restore.", kind, name)), nil
+		}
+	}
+	if live != nil && reflect.DeepEqual(target.Spec, liveSpec) {
+		return mcp.NewToolResultText(fmt.Sprintf("%s '%s' is already at %s. Nothing to roll back.", kind, name, revisionLabel(target))), nil
+	}
+
+	// Restore the spec onto the live object, or recreate the resource
+	var obj *unstructured.Unstructured
+	pre := kubernetes.Preconditions{ResourceVersion: kubernetes.ResourceAbsent}
+	if live != nil {
+		obj = upgrade.Clean(live)
+		pre.ResourceVersion = live.GetResourceVersion()
+	} else {
+		obj = &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetAPIVersion(gvr.GroupVersion().String())
+		obj.SetKind(kind)
+		obj.SetName(name)
+		obj.SetNamespace(client.Namespace())
+	}
+	obj.Object["spec"] = target.Spec
+	manifest, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode %s '%s': %v", kind, name, err)), nil
+	}
+
+	changes, err := renderer.Render(map[string]interface{}{"spec": liveSpec}, map[string]interface{}{"spec": target.Spec})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to render the diff: %v", err)), nil
+	}
+
+	result, err := ts.applyDocument(ctx, string(manifest), dryRun, pre)
+	if err != nil {
+		var preErr *kubernetes.PreconditionError
+		if errors.As(err, &preErr) {
+			return mcp.NewToolResultError(fmt.Sprintf("%s '%s' changed while the rollback was prepared: %v. Run rollback_resource again to roll back from its current state.", kind, name, err)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to roll back %s '%s': %v", kind, name, err)), nil
+	}
+
+	verb := fmt.Sprintf("Rolled back %s '%s' to %s (%s)", kind, name, revisionLabel(target), result.Action)
+	if dryRun {
+		verb = fmt.Sprintf("Dry run: %s '%s' would be rolled back to %s (%s)", kind, name, revisionLabel(target), result.Action)
+	}
+	text := fmt.Sprintf(`# %s
+
+Changes to spec:
+
+%s`, verb, changes)
+	if legend := renderer.Legend(); legend != "" {
+		text += "\n\n" + legend
+	}
+	if dryRun {
+		text += "\n\nTo roll back, run rollback_resource again with dry_run=false."
+	}
+	return mcp.NewToolResultText(text), nil
+}
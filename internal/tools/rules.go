@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	ruleset "github.com/kagent-dev/meta-kagent/internal/validation"
+)
+
+// Names of the built-in rules other checks refer to.
+const (
+	ruleSchema           = "resource.schema"
+	ruleModelProvider    = "modelconfig.provider"
+	ruleModel            = "modelconfig.model"
+	ruleAPIKeySecret     = "modelconfig.api-key-secret"
+	ruleProviderSettings = "modelconfig.provider-settings"
+)
+
+// builtinRules returns the rules validate_manifest checks resources against
+// out of the box, in the order they run.
+func (ts *ToolServer) builtinRules() []ruleset.Validator {
+	return []ruleset.Validator{
+		ruleset.Rule{ID: "resource.required", Summary: "apiVersion, kind and metadata.name are set", Check: checkRequiredFields},
+		ruleset.Rule{ID: "resource.namespace", Summary: "The namespace exists or is created by the bundle", Check: func(ctx context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+			if obj.GetKind() == "Namespace" {
+				return nil
+			}
+			return ts.checkNamespace(ctx, obj.GetNamespace())
+		}},
+		ruleset.Rule{ID: "resource.secret-placeholders", Summary: "Secret placeholders point at existing Secrets and keys", Check: func(ctx context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+			return ts.checkSecretPlaceholders(ctx, obj)
+		}},
+
+		ruleset.Rule{ID: "agent.type", Summary: "spec.type is Declarative or BYO", ForKind: []string{"Agent"}, Check: checkAgentType},
+		ruleset.Rule{ID: "agent.model-config", Summary: "Declarative agents reference a ModelConfig that exists", ForKind: []string{"Agent"}, Check: ts.checkAgentModelConfig},
+		ruleset.Rule{ID: "agent.tools", Summary: "Tool servers and, with check_tool_names, tools exist", ForKind: []string{"Agent"}, Check: ts.checkAgentTools},
+		ruleset.Rule{ID: "agent.system-message", Summary: "Declarative agents have a system message; strict: a detailed one", ForKind: []string{"Agent"}, Check: checkSystemMessage},
+		ruleset.Rule{ID: "agent.description", Summary: "Strict: agents have a description", ForKind: []string{"Agent"}, Check: checkAgentDescription},
+		ruleset.Rule{ID: "agent.tests", Summary: "Strict: agents have regression tests", ForKind: []string{"Agent"}, Check: func(ctx context.Context, obj *unstructured.Unstructured, opts ruleset.Options) []ValidationIssue {
+			if !opts.Strict {
+				return nil
+			}
+			return ts.checkAgentTests(ctx, obj)
+		}},
+		ruleset.Rule{ID: "agent.a2a-skills", Summary: "A2A skills have unique ids, names and descriptions", ForKind: []string{"Agent"}, Check: checkA2ASkills},
+		ruleset.Rule{ID: "agent.a2a-security", Summary: "Agents exposed over A2A are secured", ForKind: []string{"Agent"}, Check: func(ctx context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+			return ts.validateA2ASecurity(ctx, obj)
+		}},
+		ruleset.Rule{ID: "agent.env", Summary: "Secrets and ConfigMaps the environment reads exist", ForKind: []string{"Agent"}, Check: func(ctx context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+			return ts.checkAgentEnv(ctx, obj)
+		}},
+
+		ruleset.Rule{ID: ruleModelProvider, Summary: "spec.provider is a supported provider", ForKind: []string{"ModelConfig"}, Check: checkModelProvider},
+		ruleset.Rule{ID: ruleModel, Summary: "spec.model is set", ForKind: []string{"ModelConfig"}, Check: checkModel},
+		ruleset.Rule{ID: ruleAPIKeySecret, Summary: "spec.apiKeySecret is set for providers other than Ollama", ForKind: []string{"ModelConfig"}, Check: checkAPIKeySecretSet},
+		ruleset.Rule{ID: ruleProviderSettings, Summary: "Provider settings match spec.provider", ForKind: []string{"ModelConfig"}, Check: func(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+			provider, _, _ := unstructured.NestedString(obj.Object, "spec", "provider")
+			return checkProviderSettings(obj, provider)
+		}},
+		ruleset.Rule{ID: "modelconfig.api-key-secret-key", Summary: "The API key Secret has the key the provider reads", ForKind: []string{"ModelConfig"}, Check: func(ctx context.Context, obj *unstructured.Unstructured, opts ruleset.Options) []ValidationIssue {
+			return ts.checkAPIKeySecretKey(ctx, obj, opts.Strict)
+		}},
+
+		ruleset.Rule{ID: "mcpserver.image", Summary: "spec.deployment.image is set", ForKind: []string{"MCPServer"}, Check: checkMCPServerImage},
+		ruleset.Rule{ID: "mcpserver.transport", Summary: "The transport is stdio with an empty stdioTransport", ForKind: []string{"MCPServer"}, Check: checkMCPServerTransport},
+		ruleset.Rule{ID: "mcpserver.deployment", Summary: "The deployment's ports, env and volumes are consistent", ForKind: []string{"MCPServer"}, Check: func(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+			return checkMCPServerDeployment(obj)
+		}},
+		ruleset.Rule{ID: "resources.overprovisioned", Summary: "Requests are not far above the usage recorded by recommend_resources", ForKind: []string{"Agent", "MCPServer"}, Check: func(_ context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+			if obj.GetKind() == "MCPServer" {
+				return checkOverprovisioned(obj, "spec", "deployment", "resources")
+			}
+			issues := checkOverprovisioned(obj, "spec", "declarative", "deployment", "resources")
+			return append(issues, checkOverprovisioned(obj, "spec", "byo", "deployment", "resources")...)
+		}},
+
+		ruleset.Rule{ID: "remotemcpserver.url", Summary: "spec.url is an http or https URL", ForKind: []string{"RemoteMCPServer"}, Check: checkRemoteURL},
+		ruleset.Rule{ID: "remotemcpserver.protocol", Summary: "spec.protocol is STREAMABLE_HTTP or SSE", ForKind: []string{"RemoteMCPServer"}, Check: checkRemoteProtocol},
+
+		ruleset.Rule{ID: "resource.known-kind", Summary: "The kind is one validate_manifest knows", Check: checkKnownKind},
+		ruleset.Rule{ID: ruleSchema, Summary: "kagent resources match the OpenAPI schema of their CRD", Check: func(ctx context.Context, obj *unstructured.Unstructured, _ ruleset.Options) []ValidationIssue {
+			return ts.checkSchema(ctx, obj)
+		}},
+	}
+}
+
+// loadValidationPolicies registers the policies declared in
+// KAGENT_VALIDATION_POLICIES_FILE, and reports KAGENT_VALIDATION_RULES and
+// KAGENT_ENFORCED_RULES entries that name no rule.
+func (ts *ToolServer) loadValidationPolicies() {
+	cfg := ts.server.Config()
+	if cfg.ValidationPoliciesFile != "" {
+		policies, err := ruleset.LoadPolicies(cfg.ValidationPoliciesFile)
+		if err == nil {
+			err = ts.rules.Register(policies...)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Validation policies not loaded: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Loaded %d validation polic(ies) from %s\n", len(policies), cfg.ValidationPoliciesFile)
+		}
+	}
+	if _, err := ts.rules.ParseSettings(cfg.ValidationRules); err != nil {
+		fmt.Fprintf(os.Stderr, "KAGENT_VALIDATION_RULES: %v\n", err)
+	}
+	if _, err := ts.rules.Select(cfg.EnforcedRules); err != nil {
+		fmt.Fprintf(os.Stderr, "KAGENT_ENFORCED_RULES: %v\n", err)
+	}
+}
+
+type ruleSettingsKey struct{}
+
+// withRuleSettings overrides the server's rule settings for one call.
+func withRuleSettings(ctx context.Context, settings ruleset.Settings) context.Context {
+	return context.WithValue(ctx, ruleSettingsKey{}, settings)
+}
+
+// ruleSettings returns the rule settings in effect: KAGENT_VALIDATION_RULES
+// overridden by those of the call. Invalid server settings were reported
+// at startup and are left out.
+func (ts *ToolServer) ruleSettings(ctx context.Context) ruleset.Settings {
+	settings, _ := ts.rules.ParseSettings(ts.server.Config().ValidationRules)
+	if call, ok := ctx.Value(ruleSettingsKey{}).(ruleset.Settings); ok {
+		settings = settings.Merge(call)
+	}
+	return settings
+}
+
+// ruleSettingsFrom reads the disable_rules and rule_severity arguments of
+// a validating tool.
+func (ts *ToolServer) ruleSettingsFrom(args *params.Args) (ruleset.Settings, error) {
+	entries := args.StringList("rule_severity")
+	for _, name := range args.StringList("disable_rules") {
+		entries = append(entries, name+"="+ruleset.SeverityOff)
+	}
+	settings, err := ts.rules.ParseSettings(entries)
+	if err != nil {
+		return nil, fmt.Errorf("%v (list_validation_rules shows the rules)", err)
+	}
+	return settings, nil
+}
+
+// withRuleOptions adds the disable_rules and rule_severity arguments to a
+// tool.
+func withRuleOptions() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithString("disable_rules",
+			mcp.Description("Comma-separated validation rules to skip for this call, by name or prefix such as 'agent.*' (see list_validation_rules)"),
+		)(t)
+		mcp.WithString("rule_severity",
+			mcp.Description("Comma-separated severity overrides for this call as '<rule>=error|warning|off', e.g. 'agent.description=error'"),
+		)(t)
+	}
+}
+
+// registerListValidationRules registers the list_validation_rules tool.
+func (ts *ToolServer) registerListValidationRules() {
+	tool := mcp.NewTool("list_validation_rules",
+		mcp.WithDescription("List the rules validate_manifest checks resources against: the built-in rules and the policies of KAGENT_VALIDATION_POLICIES_FILE, with the kinds they apply to and the severity KAGENT_VALIDATION_RULES gives them, and whether KAGENT_ENFORCED_RULES makes them block apply_manifest. Pass rule names to disable_rules or rule_severity to change them for one call."),
+		mcp.WithString("kind",
+			mcp.Description("Only list the rules that apply to this kind"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleListValidationRules)
+}
+
+// validationRuleInfo describes a rule in list_validation_rules.
+type validationRuleInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Kinds       []string `json:"kinds,omitempty"`
+	// Severity is the severity the server's settings give the rule, or
+	// "default" when its checks decide.
+	Severity string `json:"severity"`
+	// Enforced is set when the rule's issues block apply_manifest.
+	Enforced bool `json:"enforced,omitempty"`
+}
+
+func (ts *ToolServer) handleListValidationRules(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.String("kind")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	settings := ts.ruleSettings(ctx)
+	enforced, _ := ts.rules.Select(ts.server.Config().EnforcedRules)
+	var rules []validationRuleInfo
+	for _, rule := range ts.rules.Rules() {
+		kinds := rule.Kinds()
+		if kind != "" && len(kinds) > 0 && !containsFold(kinds, kind) {
+			continue
+		}
+		severity := settings.Severity(rule.Name())
+		if severity == "" {
+			severity = "default"
+		}
+		_, isEnforced := enforced.Lookup(rule.Name())
+		rules = append(rules, validationRuleInfo{Name: rule.Name(), Description: rule.Description(), Kinds: kinds, Severity: severity, Enforced: isEnforced})
+	}
+
+	output, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode validation rules: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// containsFold reports whether values contains s, ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
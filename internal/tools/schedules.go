@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/schedule"
+	"github.com/kagent-dev/meta-kagent/internal/tenancy"
+)
+
+// unschedulableTools change state even though they do not change the
+// cluster, so they are not run on schedules either.
+var unschedulableTools = map[string]bool{
+	"request_elevation": true,
+	"approve_elevation": true,
+	"release_elevation": true,
+}
+
+// errTenantSchedules is returned to tenant sessions, since schedules run
+// with the server's identity.
+const errTenantSchedules = "Schedules run with the server's identity and are not available to tenant sessions"
+
+// schedulable reports why a tool may not run on a schedule, or nil.
+func schedulable(tool string) error {
+	if mutatingTools[tool] || unschedulableTools[tool] {
+		return fmt.Errorf("%s changes state; only read-only tools can be scheduled", tool)
+	}
+	return nil
+}
+
+// startScheduler loads KAGENT_SCHEDULES_FILE and runs its schedules in the
+// background with the server's own identity. Tools are looked up when they
+// run, so schedules may name tools from tool packs registered later.
+func (ts *ToolServer) startScheduler() {
+	cfg := ts.server.Config()
+	if cfg.SchedulesFile == "" {
+		return
+	}
+	entries, err := schedule.Load(cfg.SchedulesFile, schedulable)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Scheduler disabled: %v\n", err)
+		return
+	}
+
+	call := func(ctx context.Context, tool string, arguments map[string]interface{}) (string, bool, error) {
+		// Scheduled runs wait for the result rather than queueing a job
+		args := map[string]interface{}{}
+		for k, v := range arguments {
+			args[k] = v
+		}
+		delete(args, "async")
+
+		result, err := ts.server.CallTool(ctx, tool, args)
+		if err != nil {
+			return "", false, err
+		}
+		return resultText(result), result.IsError, nil
+	}
+	// A read-only server keeps the results in memory rather than write them
+	// to the cluster
+	configMap := cfg.ScheduleConfigMap
+	if cfg.Mode == ModeReadOnly {
+		configMap = ""
+	}
+	ts.schedules = schedule.NewRunner(entries, call, ts.server.K8sClient(), configMap, cfg.ScheduleWebhook, cfg.JobTimeout)
+	go ts.schedules.Run(context.Background())
+	fmt.Fprintf(os.Stderr, "Scheduler started with %d schedule(s) from %s\n", len(entries), cfg.SchedulesFile)
+}
+
+// registerListSchedules registers the list_schedules tool.
+func (ts *ToolServer) registerListSchedules() {
+	tool := mcp.NewTool("list_schedules",
+		mcp.WithDescription("List the read-only tools the server runs on cron schedules (configured in KAGENT_SCHEDULES_FILE), with each schedule's next run and the status of its last run. Pass name to get the full output of the last run of one schedule."),
+		mcp.WithString("name",
+			mcp.Description("Schedule whose last result to return in full"),
+		),
+		withLimitOption(),
+		withContinueOption(),
+	)
+
+	ts.server.AddTool(tool, ts.handleListSchedules)
+}
+
+func (ts *ToolServer) handleListSchedules(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.String("name")
+	p := ts.pageFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if _, ok := tenancy.FromContext(ctx); ok {
+		return mcp.NewToolResultError(errTenantSchedules), nil
+	}
+	if ts.schedules == nil {
+		return mcp.NewToolResultText("No schedules are configured. Set KAGENT_SCHEDULES_FILE to a schedules file to run read-only tools periodically."), nil
+	}
+
+	results, err := ts.schedules.Results(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read schedule results: %v", err)), nil
+	}
+
+	if name != "" {
+		result, ok := results[name]
+		if !ok {
+			for _, e := range ts.schedules.Entries() {
+				if e.Name == name {
+					return mcp.NewToolResultText(fmt.Sprintf("Schedule '%s' has not run yet; next run at %s.", name, e.Next(time.Now()).Format(time.RFC3339))), nil
+				}
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Schedule '%s' not found", name)), nil
+		}
+		output, _ := json.MarshalIndent(result, "", "  ")
+		return mcp.NewToolResultText(string(output)), nil
+	}
+
+	var items []map[string]interface{}
+	now := time.Now()
+	for _, e := range ts.schedules.Entries() {
+		item := map[string]interface{}{
+			"name":     e.Name,
+			"tool":     e.Tool,
+			"schedule": e.Schedule,
+			"notify":   e.Notify,
+			"nextRun":  e.Next(now).Format(time.RFC3339),
+		}
+		if len(e.Arguments) > 0 {
+			item["arguments"] = e.Arguments
+		}
+		if result, ok := results[e.Name]; ok {
+			item["lastRun"] = result.StartedAt.Format(time.RFC3339)
+			item["lastStatus"] = result.Status
+			item["lastChanged"] = result.Changed
+		}
+		items = append(items, item)
+	}
+
+	items, next, err := paginate(items, p)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	output, _ := json.MarshalIndent(items, "", "  ")
+	return mcp.NewToolResultText(string(output) + continueNote(len(items), next)), nil
+}
+
+// registerRunSchedule registers the run_schedule tool.
+func (ts *ToolServer) registerRunSchedule() {
+	tool := mcp.NewTool("run_schedule",
+		mcp.WithDescription("Run a configured schedule now instead of waiting for its next run, e.g. to check a fix. The result is recorded and notified like a scheduled run."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the schedule to run"),
+		),
+	)
+
+	ts.server.AddTool(tool, ts.handleRunSchedule)
+}
+
+func (ts *ToolServer) handleRunSchedule(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if _, ok := tenancy.FromContext(ctx); ok {
+		return mcp.NewToolResultError(errTenantSchedules), nil
+	}
+	if ts.schedules == nil {
+		return mcp.NewToolResultError("No schedules are configured (KAGENT_SCHEDULES_FILE is not set)"), nil
+	}
+
+	result, err := ts.schedules.RunNow(ctx, name)
+	if result == nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+	text := string(output)
+	if err != nil {
+		text = fmt.Sprintf("Warning: %v\n\n%s", err, text)
+	}
+	return mcp.NewToolResultText(text), nil
+}
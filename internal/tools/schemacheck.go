@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kagent-dev/meta-kagent/internal/crdschema"
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+)
+
+// loadCRDSchemas loads the CRD schemas configured in KAGENT_CRD_SCHEMAS.
+// Without them, resources are checked against the schemas the cluster
+// publishes.
+func (ts *ToolServer) loadCRDSchemas() {
+	cfg := ts.server.Config()
+	if len(cfg.CRDSchemas) == 0 {
+		return
+	}
+	schemas, err := crdschema.LoadCRDs(cfg.CRDSchemas)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Using the cluster's CRD schemas: %v\n", err)
+		return
+	}
+	ts.crdSchemas = schemas
+	fmt.Fprintf(os.Stderr, "Loaded %d CRD schema(s) from %s\n", len(schemas), strings.Join(cfg.CRDSchemas, ", "))
+}
+
+// checkSchema checks a kagent resource against the OpenAPI schema of its
+// CRD: from KAGENT_CRD_SCHEMAS when it has the kind's version, otherwise
+// as the cluster publishes it. Misspelled fields, which the API server
+// drops without an error, get a fix moving them to the field meant.
+func (ts *ToolServer) checkSchema(ctx context.Context, obj *unstructured.Unstructured) []ValidationIssue {
+	gvk := obj.GroupVersionKind()
+	if gvk.Group != kubernetes.AgentGVR.Group || gvk.Kind == "" {
+		return nil
+	}
+
+	s, ok := ts.crdSchemas[gvk]
+	if !ok {
+		var err error
+		if s, err = ts.kube(ctx).Schema(ctx, gvk); err != nil {
+			return []ValidationIssue{{
+				Severity: "warning",
+				Field:    "kind",
+				Message:  fmt.Sprintf("Schema not checked: failed to read the cluster's OpenAPI schemas: %v", err),
+			}}
+		}
+	}
+	if s == nil {
+		return nil
+	}
+
+	var issues []ValidationIssue
+	for _, v := range crdschema.Validate(obj.Object, s) {
+		issue := ValidationIssue{
+			Severity: "error",
+			Field:    v.Field,
+			Message:  fmt.Sprintf("Schema: %s", v.Message),
+		}
+		if v.Suggestion != "" {
+			parent := v.Pointer[:strings.LastIndex(v.Pointer, "/")]
+			issue.Fix = []PatchOperation{{Op: "move", From: v.Pointer, Path: parent + "/" + v.Suggestion}}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// withoutReported drops the issues on fields the other checks already
+// reported, which explain them better.
+func withoutReported(reported, issues []ValidationIssue) []ValidationIssue {
+	fields := map[string]bool{}
+	for _, issue := range reported {
+		fields[issue.Field] = true
+	}
+	var kept []ValidationIssue
+	for _, issue := range issues {
+		if !fields[issue.Field] {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
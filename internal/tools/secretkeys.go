@@ -0,0 +1,236 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// Outcomes of verifying an API key Secret.
+const (
+	secretOK           = "ok"
+	secretMissing      = "missing-secret"
+	secretKeyMissing   = "missing-key"
+	secretUnverifiable = "unverifiable"
+)
+
+// SecretVerification is the outcome of checking that an API key Secret
+// exists and holds a key. It never carries secret values.
+type SecretVerification struct {
+	Namespace string `json:"namespace"`
+	Secret    string `json:"secret"`
+	Key       string `json:"key,omitempty"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	// AvailableKeys and Suggestion, the existing key most likely meant, are
+	// set when Key is missing.
+	AvailableKeys []string `json:"availableKeys,omitempty"`
+	Suggestion    string   `json:"suggestion,omitempty"`
+}
+
+// verifySecretKey checks that a Secret exists in namespace and, when key is
+// set, that it has that data key.
+func (ts *ToolServer) verifySecretKey(ctx context.Context, namespace, secret, key string) SecretVerification {
+	v := SecretVerification{Namespace: namespace, Secret: secret, Key: key}
+	keys, found, known, err := ts.kube(ctx).SecretKeysIn(ctx, namespace, secret)
+	switch {
+	case err != nil:
+		v.Status, v.Message = secretUnverifiable, err.Error()
+	case !known:
+		v.Status = secretUnverifiable
+		v.Message = fmt.Sprintf("the server is not allowed to get secrets in namespace '%s'", namespace)
+	case !found:
+		v.Status = secretMissing
+		v.Message = fmt.Sprintf("Secret '%s' does not exist in namespace '%s'", secret, namespace)
+	case key != "" && !containsString(keys, key):
+		v.Status = secretKeyMissing
+		v.Message = fmt.Sprintf("Secret '%s' has no key '%s'.%s", secret, key, keyHint(keys, key))
+		v.AvailableKeys = append([]string(nil), keys...)
+		sort.Strings(v.AvailableKeys)
+		v.Suggestion, _ = closestKey(keys, key)
+	default:
+		v.Status = secretOK
+		v.Message = fmt.Sprintf("Secret '%s' exists", secret)
+		if key != "" {
+			v.Message += fmt.Sprintf(" and has key '%s'", key)
+		}
+	}
+	return v
+}
+
+// checkAPIKeySecretKey verifies that the key a ModelConfig reads its API key
+// from exists in the referenced Secret, and suggests the closest existing key
+// when it does not. A missing Secret is an error in strict mode and a warning
+// otherwise. Secrets that cannot be read are not judged; placeholders are
+// checked by checkSecretPlaceholders.
+func (ts *ToolServer) checkAPIKeySecretKey(ctx context.Context, obj *unstructured.Unstructured, strict bool) []ValidationIssue {
+	secret, _, _ := unstructured.NestedString(obj.Object, "spec", "apiKeySecret")
+	key, _, _ := unstructured.NestedString(obj.Object, "spec", "apiKeySecretKey")
+	if secret == "" || strings.Contains(secret, "${") || (key == "" && !strict) {
+		return nil
+	}
+
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = ts.kube(ctx).Namespace()
+	}
+	v := ts.verifySecretKey(ctx, namespace, secret, key)
+	switch v.Status {
+	case secretMissing:
+		severity := "warning"
+		if strict {
+			severity = "error"
+		}
+		return []ValidationIssue{{
+			Severity: severity,
+			Field:    "spec.apiKeySecret",
+			Message:  v.Message + ". Ensure it exists before applying.",
+		}}
+	case secretKeyMissing:
+		issue := ValidationIssue{
+			Severity: "error",
+			Field:    "spec.apiKeySecretKey",
+			Message:  fmt.Sprintf("Secret '%s' has no key '%s'; the model would fail to authenticate at runtime.%s", secret, key, keyHint(v.AvailableKeys, key)),
+		}
+		if v.Suggestion != "" {
+			issue.Fix = []PatchOperation{{Op: "replace", Path: "/spec/apiKeySecretKey", Value: v.Suggestion}}
+		}
+		return []ValidationIssue{issue}
+	}
+	return nil
+}
+
+// registerVerifyModelSecret registers the verify_model_secret tool.
+func (ts *ToolServer) registerVerifyModelSecret() {
+	tool := mcp.NewTool("verify_model_secret",
+		mcp.WithDescription("Check that the API key Secret of a ModelConfig exists and contains the key it reads (spec.apiKeySecret and spec.apiKeySecretKey), or check a Secret and key given directly. Only key names are read and reported; secret values are never returned. Suggests the closest existing key when the key is misspelled."),
+		mcp.WithString("model_config",
+			mcp.Description("Name of the ModelConfig whose Secret to verify ('name' or 'namespace/name'). Either this or secret is required"),
+		),
+		mcp.WithString("secret",
+			mcp.Description("Name of a Secret to verify instead of a ModelConfig's"),
+		),
+		mcp.WithString("key",
+			mcp.Description("Data key the Secret must contain, with secret. Omit to check only that the Secret exists"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleVerifyModelSecret)
+}
+
+func (ts *ToolServer) handleVerifyModelSecret(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	modelConfig := args.String("model_config")
+	secret := args.String("secret")
+	key := args.String("key")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if (modelConfig == "") == (secret == "") {
+		return mcp.NewToolResultError("exactly one of model_config and secret is required"), nil
+	}
+
+	namespace := ts.kube(ctx).Namespace()
+	if modelConfig != "" {
+		obj, ref, err := ts.resolveReference(ctx, kubernetes.ModelConfigGVR, modelConfig, "")
+		if err != nil {
+			if ref.Name == "" {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultError(referenceError("ModelConfig", kubernetes.ModelConfigGVR, ref, err)), nil
+		}
+		secret, _, _ = unstructured.NestedString(obj.Object, "spec", "apiKeySecret")
+		key, _, _ = unstructured.NestedString(obj.Object, "spec", "apiKeySecretKey")
+		namespace = ref.Namespace
+		if secret == "" {
+			return mcp.NewToolResultText(fmt.Sprintf("ModelConfig '%s' does not reference an API key Secret (spec.apiKeySecret is empty). Nothing to verify.", ref)), nil
+		}
+	}
+
+	v := ts.verifySecretKey(ctx, namespace, secret, key)
+	marker := "✓"
+	switch v.Status {
+	case secretUnverifiable:
+		marker = "⚠️"
+	case secretMissing, secretKeyMissing:
+		marker = "❌"
+	}
+
+	output, _ := json.MarshalIndent(v, "", "  ")
+	return mcp.NewToolResultText(fmt.Sprintf("%s %s\n\n%s", marker, v.Message, string(output))), nil
+}
+
+// keyHint suggests the existing key closest to want, or lists the keys when
+// none is close.
+func keyHint(keys []string, want string) string {
+	if closest, ok := closestKey(keys, want); ok {
+		return fmt.Sprintf(" Did you mean '%s'?", closest)
+	}
+	if len(keys) == 0 {
+		return " The secret has no keys."
+	}
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	return fmt.Sprintf(" Available keys: %s.", strings.Join(sorted, ", "))
+}
+
+// closestKey returns the key most likely meant by want: one that differs only
+// in case and separators, or otherwise the nearest by edit distance within a
+// few characters.
+func closestKey(keys []string, want string) (string, bool) {
+	normalized := normalizeKey(want)
+	best, bestDistance := "", -1
+	for _, key := range keys {
+		if normalizeKey(key) == normalized {
+			return key, true
+		}
+		if d := editDistance(strings.ToUpper(key), strings.ToUpper(want)); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = key, d
+		}
+	}
+	if bestDistance < 0 || bestDistance > max(2, len(want)/4) {
+		return "", false
+	}
+	return best, true
+}
+
+// normalizeKey upper-cases a key and drops separators, so OPENAI_APIKEY and
+// openai-api-key compare equal.
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, key)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
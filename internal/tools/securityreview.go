@@ -0,0 +1,474 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// Security review verdicts, from least to most severe.
+const (
+	SecurityPass  = "pass"
+	SecurityWarn  = "warn"
+	SecurityBlock = "block"
+)
+
+// Security review categories.
+const (
+	categoryRBAC     = "rbac"
+	categoryImage    = "image"
+	categorySecrets  = "secrets"
+	categoryNetwork  = "network"
+	categoryPrompt   = "prompt"
+	categoryManifest = "manifest"
+)
+
+// SecurityFinding is one security-relevant problem in a bundle.
+type SecurityFinding struct {
+	Category string `json:"category"`
+	Severity string `json:"severity"` // SecurityWarn or SecurityBlock
+	Resource string `json:"resource"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// SecurityReview is the gated result of a security review.
+type SecurityReview struct {
+	Verdict  string            `json:"verdict"`
+	Blocking int               `json:"blocking"`
+	Warnings int               `json:"warnings"`
+	Findings []SecurityFinding `json:"findings"`
+}
+
+// secretLiteralPatterns match credentials pasted into manifests.
+var secretLiteralPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),            // OpenAI and Anthropic style API keys
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),               // AWS access key IDs
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),          // Google API keys
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),     // GitHub tokens
+	regexp.MustCompile(`\bxox[abpr]-[A-Za-z0-9-]{10,}`),      // Slack tokens
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`), // PEM private keys
+}
+
+// sensitiveEnvPattern matches environment variable names that hold
+// credentials.
+var sensitiveEnvPattern = regexp.MustCompile(`(?i)(KEY|TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIAL)`)
+
+// promptInjectionPhrases in a system message undo the guardrails of the
+// agent or of the platform.
+var promptInjectionPhrases = []string{
+	"ignore previous instructions",
+	"ignore all previous instructions",
+	"disregard previous instructions",
+	"ignore your instructions",
+	"you have no restrictions",
+	"bypass safety",
+	"without asking for confirmation",
+	"never ask for confirmation",
+}
+
+// destructiveToolWords mark tool names that change or remove resources.
+var destructiveToolWords = []string{"delete", "apply", "create", "patch", "update", "exec", "scale", "restart", "drain"}
+
+// registerSecurityReview registers the security_review tool.
+func (ts *ToolServer) registerSecurityReview() {
+	tool := mcp.NewTool("security_review",
+		mcp.WithDescription("Run every security-relevant check against a manifest or bundle before it is applied and return one gated verdict: pass, warn or block. Covers RBAC breadth (wildcards, escalation verbs, cluster-wide grants, secret access), image policy (unpinned tags, registries outside KAGENT_ALLOWED_IMAGE_REGISTRIES), secret handling (inline Secrets, credentials in literals or environment variables, missing Secrets), network exposure (LoadBalancer and NodePort Services, Ingresses, unauthenticated or plain-HTTP A2A and MCP endpoints) and prompt safety (credentials or guardrail overrides in system messages, destructive tools without confirmation guidance). The first line is machine-readable for approval workflows."),
+		mcp.WithString("manifest",
+			mcp.Required(),
+			mcp.Description("YAML manifest or multi-document bundle to review"),
+		),
+		mcp.WithString("fail_on",
+			mcp.Description("Lowest verdict that marks the tool result as an error, for workflows that gate on it: 'block' (default), 'warn', or 'never'"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleSecurityReview)
+}
+
+func (ts *ToolServer) handleSecurityReview(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	manifest := args.RequiredString("manifest")
+	failOn := args.Enum("fail_on", SecurityBlock, SecurityBlock, SecurityWarn, "never")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	docs := kubernetes.SplitManifests(manifest)
+	if len(docs) == 0 {
+		return mcp.NewToolResultError("manifest is empty"), nil
+	}
+	objs := make([]*unstructured.Unstructured, len(docs))
+	for i, doc := range docs {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(doc), &obj.Object); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to parse document %d: %v", i+1, err)), nil
+		}
+		objs[i] = obj
+	}
+
+	review := ts.reviewSecurity(withBundle(ctx, objs, ts.kube(ctx).Namespace()), objs)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SECURITY_VERDICT=%s blocking=%d warnings=%d\n\n", review.Verdict, review.Blocking, review.Warnings)
+	switch review.Verdict {
+	case SecurityPass:
+		fmt.Fprintf(&b, "✓ No security findings in %d resource(s).\n", len(objs))
+	default:
+		for _, f := range review.Findings {
+			prefix := "⚠️  WARN "
+			if f.Severity == SecurityBlock {
+				prefix = "⛔ BLOCK"
+			}
+			where := f.Resource
+			if f.Field != "" {
+				where += " " + f.Field
+			}
+			fmt.Fprintf(&b, "%s [%s] (%s): %s\n", prefix, f.Category, where, f.Message)
+		}
+	}
+	output, _ := json.MarshalIndent(review, "", "  ")
+	b.WriteString("\n" + string(output))
+
+	if failOn == SecurityBlock && review.Verdict == SecurityBlock ||
+		failOn == SecurityWarn && review.Verdict != SecurityPass {
+		return mcp.NewToolResultError(b.String()), nil
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// reviewSecurity runs the security checks on every object of a bundle.
+func (ts *ToolServer) reviewSecurity(ctx context.Context, objs []*unstructured.Unstructured) SecurityReview {
+	review := SecurityReview{Verdict: SecurityPass, Findings: []SecurityFinding{}}
+	add := func(category, severity, resource, field, format string, args ...interface{}) {
+		review.Findings = append(review.Findings, SecurityFinding{
+			Category: category,
+			Severity: severity,
+			Resource: resource,
+			Field:    field,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Ingresses route to Services named after the agents they expose
+	exposed := map[string]bool{}
+	for _, obj := range objs {
+		if obj.GetKind() == "Ingress" {
+			for _, service := range ingressBackends(obj) {
+				exposed[service] = true
+			}
+		}
+	}
+
+	for i, obj := range objs {
+		resource := bundleResource(objs, i)
+
+		switch obj.GetKind() {
+		case "Role", "ClusterRole":
+			reviewRules(obj, resource, add)
+		case "RoleBinding", "ClusterRoleBinding":
+			reviewBinding(obj, resource, add)
+		case "Secret":
+			if hasData(obj) && !hasOnlyPlaceholders(obj) {
+				add(categorySecrets, SecurityBlock, resource, "data", "Secret values are embedded in the manifest. Create the Secret out of band (or with extract_secrets) and reference it, or use ${SECRET:<name>:<key>} placeholders")
+			}
+		case "Service":
+			switch serviceType, _, _ := unstructured.NestedString(obj.Object, "spec", "type"); serviceType {
+			case "LoadBalancer":
+				add(categoryNetwork, SecurityWarn, resource, "spec.type", "LoadBalancer Service exposes the workload outside the cluster; prefer an Ingress with TLS (expose_agent)")
+			case "NodePort":
+				add(categoryNetwork, SecurityWarn, resource, "spec.type", "NodePort Service exposes the workload on every node")
+			}
+		case "Ingress":
+			if tls, _, _ := unstructured.NestedSlice(obj.Object, "spec", "tls"); len(tls) == 0 {
+				add(categoryNetwork, SecurityBlock, resource, "spec.tls", "Ingress has no TLS; A2A traffic would cross the network in plain text")
+			}
+		case "Agent":
+			ts.reviewAgentSecurity(ctx, obj, resource, exposed[obj.GetName()], add)
+		case "RemoteMCPServer":
+			if raw, _, _ := unstructured.NestedString(obj.Object, "spec", "url"); raw != "" {
+				if u, err := url.Parse(raw); err == nil && u.Scheme == "http" && !isClusterHost(u.Hostname()) {
+					add(categoryNetwork, SecurityWarn, resource, "spec.url", "remote MCP server is reached over plain HTTP outside the cluster; use https")
+				}
+			}
+		}
+
+		ts.reviewImages(obj, resource, add)
+		reviewLiterals(obj, resource, add)
+
+		// Secret references and placeholders that do not resolve
+		for _, issue := range ts.checkSecretPlaceholders(ctx, obj) {
+			add(categorySecrets, severityOf(issue), resource, issue.Field, "%s", issue.Message)
+		}
+		if obj.GetKind() == "ModelConfig" {
+			for _, issue := range ts.checkAPIKeySecretKey(ctx, obj, true) {
+				add(categorySecrets, severityOf(issue), resource, issue.Field, "%s", issue.Message)
+			}
+		}
+	}
+
+	for _, f := range review.Findings {
+		if f.Severity == SecurityBlock {
+			review.Blocking++
+			review.Verdict = SecurityBlock
+		} else {
+			review.Warnings++
+			if review.Verdict == SecurityPass {
+				review.Verdict = SecurityWarn
+			}
+		}
+	}
+	return review
+}
+
+type findingFunc func(category, severity, resource, field, format string, args ...interface{})
+
+// reviewRules flags broad or escalating RBAC rules.
+func reviewRules(obj *unstructured.Unstructured, resource string, add findingFunc) {
+	if obj.GetKind() == "ClusterRole" {
+		add(categoryRBAC, SecurityWarn, resource, "kind", "ClusterRole grants access in every namespace; prefer a namespaced Role")
+	}
+
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "rules")
+	for i, r := range rules {
+		rule, _ := r.(map[string]interface{})
+		field := fmt.Sprintf("rules[%d]", i)
+		groups, _, _ := unstructured.NestedStringSlice(rule, "apiGroups")
+		resources, _, _ := unstructured.NestedStringSlice(rule, "resources")
+		verbs, _, _ := unstructured.NestedStringSlice(rule, "verbs")
+
+		switch {
+		case containsString(verbs, "*") && containsString(resources, "*"):
+			add(categoryRBAC, SecurityBlock, resource, field, "rule grants every verb on every resource")
+		case containsString(verbs, "*"):
+			add(categoryRBAC, SecurityBlock, resource, field+".verbs", "rule grants every verb on %s; list the verbs needed", strings.Join(resources, ", "))
+		case containsString(resources, "*"):
+			add(categoryRBAC, SecurityBlock, resource, field+".resources", "rule grants %s on every resource; list the resources needed", strings.Join(verbs, ", "))
+		}
+		if containsString(groups, "*") {
+			add(categoryRBAC, SecurityWarn, resource, field+".apiGroups", "rule applies to every API group")
+		}
+		for _, verb := range []string{"escalate", "bind", "impersonate"} {
+			if containsString(verbs, verb) {
+				add(categoryRBAC, SecurityBlock, resource, field+".verbs", "'%s' lets the holder gain permissions beyond this role", verb)
+			}
+		}
+		if containsString(resources, "secrets") && (containsString(verbs, "get") || containsString(verbs, "list") || containsString(verbs, "watch")) {
+			add(categoryRBAC, SecurityWarn, resource, field, "rule can read Secret values; grant it only when the workload must read credentials")
+		}
+		if (containsString(resources, "roles") || containsString(resources, "rolebindings") || containsString(resources, "clusterroles") || containsString(resources, "clusterrolebindings")) &&
+			(containsString(verbs, "create") || containsString(verbs, "update") || containsString(verbs, "patch")) {
+			add(categoryRBAC, SecurityWarn, resource, field, "rule can change RBAC, which allows granting further permissions")
+		}
+		if containsString(resources, "pods/exec") {
+			add(categoryRBAC, SecurityWarn, resource, field, "rule allows executing commands in pods")
+		}
+	}
+}
+
+// reviewBinding flags bindings to built-in administrator roles.
+func reviewBinding(obj *unstructured.Unstructured, resource string, add findingFunc) {
+	role, _, _ := unstructured.NestedString(obj.Object, "roleRef", "name")
+	switch role {
+	case "cluster-admin":
+		add(categoryRBAC, SecurityBlock, resource, "roleRef.name", "binding grants cluster-admin")
+	case "admin", "edit":
+		add(categoryRBAC, SecurityWarn, resource, "roleRef.name", "binding grants the built-in '%s' role, which is broader than a workload needs", role)
+	}
+	if obj.GetKind() == "ClusterRoleBinding" {
+		add(categoryRBAC, SecurityWarn, resource, "kind", "ClusterRoleBinding grants its role in every namespace")
+	}
+}
+
+// reviewAgentSecurity checks an agent's endpoint security, A2A credentials
+// and system message.
+func (ts *ToolServer) reviewAgentSecurity(ctx context.Context, obj *unstructured.Unstructured, resource string, exposed bool, add findingFunc) {
+	for _, issue := range ts.validateA2ASecurity(ctx, obj) {
+		add(categoryNetwork, severityOf(issue), resource, issue.Field, "%s", issue.Message)
+	}
+	scheme := a2aSecurityFrom(obj.GetAnnotations()).Scheme
+	if exposed && (scheme == "" || scheme == SecurityNone) {
+		add(categoryNetwork, SecurityBlock, resource, "metadata.annotations."+annotationA2AScheme, "agent is exposed by an Ingress in this bundle but accepts unauthenticated A2A calls; configure_a2a_security first")
+	}
+
+	systemMessage, _, _ := unstructured.NestedString(obj.Object, "spec", "declarative", "systemMessage")
+	lower := strings.ToLower(systemMessage)
+	for _, phrase := range promptInjectionPhrases {
+		if strings.Contains(lower, phrase) {
+			add(categoryPrompt, SecurityBlock, resource, "spec.declarative.systemMessage", "system message contains '%s', which overrides safety guardrails", phrase)
+		}
+	}
+
+	// Tools that change things call for confirmation guidance in the prompt
+	var destructive []string
+	tools, _, _ := unstructured.NestedSlice(obj.Object, "spec", "declarative", "tools")
+	for _, t := range tools {
+		tool, _ := t.(map[string]interface{})
+		names, _, _ := unstructured.NestedStringSlice(tool, "mcpServer", "toolNames")
+		for _, name := range names {
+			for _, word := range splitWords(name) {
+				if containsString(destructiveToolWords, word) {
+					destructive = append(destructive, name)
+					break
+				}
+			}
+		}
+	}
+	if len(destructive) > 0 && !strings.Contains(lower, "confirm") && !strings.Contains(lower, "approv") {
+		add(categoryPrompt, SecurityWarn, resource, "spec.declarative.systemMessage", "agent can call %s, but its system message never asks it to confirm or seek approval before changing anything", strings.Join(destructive, ", "))
+	}
+}
+
+// reviewImages checks every container image of a resource: tags must be
+// pinned and registries allowed.
+func (ts *ToolServer) reviewImages(obj *unstructured.Unstructured, resource string, add findingFunc) {
+	allowed := ts.server.Config().AllowedImageRegistries
+
+	var walk func(value interface{}, field string)
+	walk = func(value interface{}, field string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for k, child := range v {
+				if image, ok := child.(string); ok && k == "image" {
+					checkImage(image, joinField(field, k), resource, allowed, add)
+					continue
+				}
+				walk(child, joinField(field, k))
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(child, fmt.Sprintf("%s[%d]", field, i))
+			}
+		}
+	}
+	walk(obj.Object["spec"], "spec")
+}
+
+func checkImage(image, field, resource string, allowed []string, add findingFunc) {
+	if image == "" {
+		return
+	}
+	name, digest, pinned := strings.Cut(image, "@")
+	tag := ""
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		tag = name[i+1:]
+	}
+	switch {
+	case pinned && digest != "":
+	case tag == "" || tag == "latest":
+		add(categoryImage, SecurityWarn, resource, field, "image '%s' is not pinned: use a version tag or, better, a digest", image)
+	}
+
+	if len(allowed) == 0 {
+		return
+	}
+	registry := "docker.io"
+	if first, _, found := strings.Cut(name, "/"); found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry = first
+	}
+	for _, r := range allowed {
+		if registry == r || strings.HasPrefix(name, strings.TrimSuffix(r, "/")+"/") {
+			return
+		}
+	}
+	add(categoryImage, SecurityBlock, resource, field, "image '%s' comes from registry '%s', which is not in KAGENT_ALLOWED_IMAGE_REGISTRIES", image, registry)
+}
+
+// reviewLiterals looks for credentials pasted into any string, and for
+// sensitive environment variables given literal values.
+func reviewLiterals(obj *unstructured.Unstructured, resource string, add findingFunc) {
+	var walk func(value interface{}, field string)
+	walk = func(value interface{}, field string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			name, _ := v["name"].(string)
+			literal, _ := v["value"].(string)
+			if name != "" && literal != "" && strings.HasSuffix(field, "]") && strings.Contains(field, "env[") &&
+				sensitiveEnvPattern.MatchString(name) && !strings.HasPrefix(literal, secretPlaceholderPrefix) {
+				add(categorySecrets, SecurityBlock, resource, field+".value", "environment variable %s holds a literal credential; use valueFrom.secretKeyRef (extract_secrets moves it)", name)
+			}
+			for k, child := range v {
+				walk(child, joinField(field, k))
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(child, fmt.Sprintf("%s[%d]", field, i))
+			}
+		case string:
+			if matchesSecretLiteral(v) {
+				add(categorySecrets, SecurityBlock, resource, field, "value looks like a credential; move it to a Secret")
+			} else if what := urlCredential(v); what != "" {
+				add(categorySecrets, SecurityBlock, resource, field, "URL embeds %s; move it to a Secret", what)
+			}
+		}
+	}
+	// Secret data is reported by its own check
+	if obj.GetKind() != "Secret" {
+		walk(obj.Object, "")
+	}
+}
+
+// ingressBackends returns the Services an Ingress routes to.
+func ingressBackends(obj *unstructured.Unstructured) []string {
+	var services []string
+	if name, _, _ := unstructured.NestedString(obj.Object, "spec", "defaultBackend", "service", "name"); name != "" {
+		services = append(services, name)
+	}
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rules")
+	for _, r := range rules {
+		rule, _ := r.(map[string]interface{})
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+		for _, p := range paths {
+			path, _ := p.(map[string]interface{})
+			if name, _, _ := unstructured.NestedString(path, "backend", "service", "name"); name != "" {
+				services = append(services, name)
+			}
+		}
+	}
+	return services
+}
+
+// hasData reports whether a Secret carries values.
+func hasData(obj *unstructured.Unstructured) bool {
+	data, _, _ := unstructured.NestedMap(obj.Object, "data")
+	stringData, _, _ := unstructured.NestedMap(obj.Object, "stringData")
+	return len(data) > 0 || len(stringData) > 0
+}
+
+// hasOnlyPlaceholders reports whether every stringData value of a Secret is
+// a secret placeholder, i.e. the manifest carries no credential itself.
+func hasOnlyPlaceholders(obj *unstructured.Unstructured) bool {
+	if data, _, _ := unstructured.NestedMap(obj.Object, "data"); len(data) > 0 {
+		return false
+	}
+	stringData, _, _ := unstructured.NestedStringMap(obj.Object, "stringData")
+	for _, v := range stringData {
+		if !secretPlaceholderPattern.MatchString(v) || strings.TrimSpace(secretPlaceholderPattern.ReplaceAllString(v, "")) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isClusterHost reports whether a host is reached inside the cluster.
+func isClusterHost(host string) bool {
+	return host == "localhost" || strings.HasSuffix(host, ".svc") || strings.HasSuffix(host, ".svc.cluster.local") || !strings.Contains(host, ".")
+}
+
+// severityOf maps a validation issue to a finding severity.
+func severityOf(issue ValidationIssue) string {
+	if issue.Severity == "error" {
+		return SecurityBlock
+	}
+	return SecurityWarn
+}
@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// SkillSyncResult describes how one agent's copy of a skill compares to the
+// canonical definition.
+type SkillSyncResult struct {
+	Agent       string   `json:"agent"`
+	Action      string   `json:"action"` // "in_sync", "updated", "added", or "missing"
+	Divergences []string `json:"divergences,omitempty"`
+}
+
+// registerSyncSkills registers the sync_skills tool.
+func (ts *ToolServer) registerSyncSkills() {
+	tool := mcp.NewTool("sync_skills",
+		mcp.WithDescription("Ensure a canonical A2A skill definition exists with identical content on every agent matching a label selector. Reports which agents diverge and how, and returns updated agent manifests as one bundle for review before applying."),
+		mcp.WithString("skill_json",
+			mcp.Required(),
+			mcp.Description("JSON representation of the canonical skill; matched on agents by id"),
+		),
+		mcp.WithString("label_selector",
+			mcp.Required(),
+			mcp.Description("Label selector for the agents to sync (e.g., 'team=platform')"),
+		),
+		mcp.WithBoolean("add_missing",
+			mcp.Description("Add the skill to matching agents that do not have it yet (default: true). When false, only existing copies are updated"),
+		),
+		withAsyncOption(),
+	)
+
+	ts.addTool(tool, ts.withAsync("sync_skills", ts.handleSyncSkills))
+}
+
+func (ts *ToolServer) handleSyncSkills(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	skillJSON := args.RequiredString("skill_json")
+	selector := args.RequiredString("label_selector")
+	addMissing := args.Bool("add_missing", true)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var skill types.Skill
+	if err := json.Unmarshal([]byte(skillJSON), &skill); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid skill JSON: %v", err)), nil
+	}
+	if skill.ID == "" || skill.Name == "" || skill.Description == "" {
+		return mcp.NewToolResultError("skill must have id, name, and description"), nil
+	}
+
+	agents, err := ts.kube(ctx).ListAgentsBySelector(ctx, selector)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list agents: %v", err)), nil
+	}
+	if len(agents) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No agents match selector '%s'.", selector)), nil
+	}
+
+	var results []SkillSyncResult
+	var manifests []string
+	for i := range agents {
+		agent := &agents[i]
+		result := syncSkill(agent, skill, addMissing)
+		results = append(results, result)
+
+		if result.Action != "updated" && result.Action != "added" {
+			continue
+		}
+
+		agent.APIVersion = "kagent.dev/v1alpha2"
+		agent.Kind = "Agent"
+		output, _ := yaml.Marshal(agent)
+		manifests = append(manifests, string(output))
+	}
+
+	report, _ := json.MarshalIndent(results, "", "  ")
+
+	if len(manifests) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("# Skill Sync: %s\n\nNo changes needed.\n\n%s", skill.ID, string(report))), nil
+	}
+
+	result := fmt.Sprintf(`# Skill Sync: %s
+# %d of %d matching agent(s) need changes.
+# IMPORTANT: Review the changes before applying.
+# Use validate_manifest to check the bundle below, then apply_manifest to deploy it.
+
+%s
+
+# Updated Agent Manifests
+%s`, skill.ID, len(manifests), len(agents), string(report), strings.Join(manifests, "---\n"))
+
+	return mcp.NewToolResultText(result), nil
+}
+
+// syncSkill brings the agent's copy of skill in line with the canonical
+// definition, modifying the agent in place.
+func syncSkill(agent *types.Agent, skill types.Skill, addMissing bool) SkillSyncResult {
+	result := SkillSyncResult{Agent: agent.Name}
+
+	a2aConfig := getA2AConfig(agent)
+	if a2aConfig != nil {
+		for i, existing := range a2aConfig.Skills {
+			if existing.ID != skill.ID {
+				continue
+			}
+			result.Divergences = skillDivergences(existing, skill)
+			if len(result.Divergences) == 0 {
+				result.Action = "in_sync"
+				return result
+			}
+			a2aConfig.Skills[i] = skill
+			result.Action = "updated"
+			return result
+		}
+	}
+
+	if !addMissing {
+		result.Action = "missing"
+		return result
+	}
+
+	if a2aConfig == nil {
+		a2aConfig = &types.A2AConfig{}
+		setA2AConfig(agent, a2aConfig)
+	}
+	a2aConfig.Skills = append(a2aConfig.Skills, skill)
+	result.Action = "added"
+	return result
+}
+
+// skillDivergences lists the fields in which an agent's skill differs from
+// the canonical one.
+func skillDivergences(existing, canonical types.Skill) []string {
+	var fields []string
+	if existing.Name != canonical.Name {
+		fields = append(fields, fmt.Sprintf("name: '%s' -> '%s'", existing.Name, canonical.Name))
+	}
+	if existing.Description != canonical.Description {
+		fields = append(fields, "description")
+	}
+	if !reflect.DeepEqual(existing.InputModes, canonical.InputModes) {
+		fields = append(fields, fmt.Sprintf("inputModes: %v -> %v", existing.InputModes, canonical.InputModes))
+	}
+	if !reflect.DeepEqual(existing.OutputModes, canonical.OutputModes) {
+		fields = append(fields, fmt.Sprintf("outputModes: %v -> %v", existing.OutputModes, canonical.OutputModes))
+	}
+	if !reflect.DeepEqual(existing.Tags, canonical.Tags) {
+		fields = append(fields, fmt.Sprintf("tags: %v -> %v", existing.Tags, canonical.Tags))
+	}
+	if !reflect.DeepEqual(existing.Examples, canonical.Examples) {
+		fields = append(fields, "examples")
+	}
+	return fields
+}
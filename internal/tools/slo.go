@@ -0,0 +1,255 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/slo"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// sloQueryTimeout bounds each Prometheus query.
+const sloQueryTimeout = 30 * time.Second
+
+// registerDefineAgentSLO registers the define_agent_slo tool.
+func (ts *ToolServer) registerDefineAgentSLO() {
+	tool := mcp.NewTool("define_agent_slo",
+		mcp.WithDescription("Define service level objectives for an agent: a target availability and/or response latency at a percentile over an evaluation window. Generates the agent manifest with the objectives recorded as kagent.dev/slo-* annotations, for review and apply_manifest."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the agent"),
+		),
+		mcp.WithNumber("availability",
+			mcp.Description("Target percentage of successful requests, e.g. 99.5"),
+		),
+		mcp.WithString("latency",
+			mcp.Description("Target response latency as a duration, e.g. '10s'"),
+		),
+		mcp.WithNumber("latency_percentile",
+			mcp.Description("Percentile the latency target applies to (default: 95)"),
+		),
+		mcp.WithString("window",
+			mcp.Description("Evaluation window as a Prometheus duration (default: '30d')"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleDefineAgentSLO)
+}
+
+func (ts *ToolServer) handleDefineAgentSLO(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	availability := args.Float("availability", 0)
+	latency := args.Duration("latency", 0)
+	percentile := args.Float("latency_percentile", slo.DefaultLatencyPercentile)
+	window := args.StringDefault("window", slo.DefaultWindow)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	objectives := slo.SLO{Availability: availability, Latency: latency, LatencyPercentile: percentile, Window: window}
+	if err := objectives.Validate(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid SLO: %v", err)), nil
+	}
+
+	agent, err := ts.kube(ctx).GetAgent(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+	}
+
+	// Replace any previous objectives rather than merging with them
+	if agent.Annotations == nil {
+		agent.Annotations = map[string]string{}
+	}
+	for _, key := range []string{slo.AnnotationAvailability, slo.AnnotationLatency, slo.AnnotationLatencyPercentile, slo.AnnotationWindow} {
+		delete(agent.Annotations, key)
+	}
+	for key, value := range objectives.Annotations() {
+		agent.Annotations[key] = value
+	}
+
+	agent.APIVersion = "kagent.dev/v1alpha2"
+	agent.Kind = "Agent"
+	output, _ := yaml.Marshal(agent)
+
+	header := `# Agent Manifest with SLO annotations
+# IMPORTANT: Review the changes before applying.
+# Use apply_manifest to deploy, check_slo_compliance to evaluate the objectives,
+# and generate_slo_alert_rules to alert on them.`
+
+	return out.render(header, string(output))
+}
+
+// registerCheckSLOCompliance registers the check_slo_compliance tool.
+func (ts *ToolServer) registerCheckSLOCompliance() {
+	tool := mcp.NewTool("check_slo_compliance",
+		mcp.WithDescription("Evaluate agents' SLOs (kagent.dev/slo-* annotations) against Prometheus metrics: measured availability and remaining error budget, and measured latency at the objective's percentile, over each objective's window. Requires KAGENT_PROMETHEUS_URL."),
+		mcp.WithString("name",
+			mcp.Description("Agent to check (default: every agent with an SLO)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleCheckSLOCompliance)
+}
+
+func (ts *ToolServer) handleCheckSLOCompliance(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.String("name")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cfg := ts.server.Config()
+	if cfg.PrometheusURL == "" {
+		return mcp.NewToolResultError("SLO compliance requires a Prometheus server; set KAGENT_PROMETHEUS_URL"), nil
+	}
+
+	targets, invalid, err := ts.sloTargets(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(targets) == 0 && len(invalid) == 0 {
+		return mcp.NewToolResultText("No agents have SLOs defined. Use define_agent_slo to add them."), nil
+	}
+
+	prom := slo.NewPrometheus(cfg.PrometheusURL, &http.Client{Timeout: sloQueryTimeout})
+	queries := ts.sloQueries()
+	namespace := ts.kube(ctx).Namespace()
+
+	var report []slo.Compliance
+	violated := 0
+	for _, t := range targets {
+		c := slo.Evaluate(ctx, prom, queries, namespace, t.Agent, t.SLO)
+		if c.Status == slo.StatusViolated {
+			violated++
+		}
+		report = append(report, c)
+	}
+
+	result := map[string]interface{}{
+		"prometheus": cfg.PrometheusURL,
+		"agents":     report,
+	}
+	if len(invalid) > 0 {
+		result["invalid"] = invalid
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+
+	return mcp.NewToolResultText(fmt.Sprintf(`# SLO Compliance: %d agent(s) checked, %d violating
+
+%s`, len(report), violated, string(output))), nil
+}
+
+// registerGenerateSLOAlertRules registers the generate_slo_alert_rules tool.
+func (ts *ToolServer) registerGenerateSLOAlertRules() {
+	tool := mcp.NewTool("generate_slo_alert_rules",
+		mcp.WithDescription("Generate a Prometheus Operator PrometheusRule alerting on agents' SLOs (kagent.dev/slo-* annotations): multi-window burn-rate alerts on the availability error budget and an alert on sustained latency above the objective."),
+		mcp.WithString("name",
+			mcp.Description("Agent to generate rules for (default: every agent with an SLO)"),
+		),
+		mcp.WithString("rule_name",
+			mcp.Description("Name of the PrometheusRule (default: 'kagent-agent-slos', or '<agent>-slo' with name)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleGenerateSLOAlertRules)
+}
+
+func (ts *ToolServer) handleGenerateSLOAlertRules(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.String("name")
+	ruleName := args.String("rule_name")
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	targets, invalid, err := ts.sloTargets(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(targets) == 0 {
+		return mcp.NewToolResultError("No agents have valid SLOs defined. Use define_agent_slo to add them."), nil
+	}
+
+	if ruleName == "" {
+		ruleName = "kagent-agent-slos"
+		if name != "" {
+			ruleName = name + "-slo"
+		}
+	}
+
+	rule := slo.PrometheusRule(ruleName, ts.kube(ctx).Namespace(), ts.sloQueries(), targets)
+	output, _ := yaml.Marshal(rule)
+
+	header := fmt.Sprintf(`# PrometheusRule for %d agent SLO(s)
+# Requires the Prometheus Operator (monitoring.coreos.com/v1).
+# Apply it with kubectl; apply_manifest only applies kagent kinds by default.`, len(targets))
+	for _, problem := range invalid {
+		header += "\n# Skipped: " + problem
+	}
+
+	return out.render(header, string(output))
+}
+
+// sloTargets returns the agents with valid objectives, or only the named
+// agent. Agents whose annotations cannot be parsed are reported in invalid.
+func (ts *ToolServer) sloTargets(ctx context.Context, name string) (targets []slo.Target, invalid []string, err error) {
+	var agents []types.Agent
+	if name != "" {
+		agent, err := ts.kube(ctx).GetAgent(ctx, name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to get agent: %v", err)
+		}
+		agents = []types.Agent{*agent}
+	} else {
+		agents, err = ts.kube(ctx).ListAgents(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to list agents: %v", err)
+		}
+	}
+
+	for _, agent := range agents {
+		objectives, ok, err := slo.FromAnnotations(agent.Annotations)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", agent.Name, err))
+			continue
+		}
+		targets = append(targets, slo.Target{Agent: agent.Name, SLO: objectives})
+	}
+	if name != "" && len(targets) == 0 && len(invalid) == 0 {
+		return nil, nil, fmt.Errorf("Agent '%s' has no SLO defined. Use define_agent_slo to add one.", name)
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Agent < targets[j].Agent })
+	return targets, invalid, nil
+}
+
+// sloQueries returns the configured PromQL templates.
+func (ts *ToolServer) sloQueries() slo.Queries {
+	cfg := ts.server.Config()
+	queries := slo.DefaultQueries
+	if cfg.SLOAvailabilityQuery != "" {
+		queries.Availability = cfg.SLOAvailabilityQuery
+	}
+	if cfg.SLOLatencyQuery != "" {
+		queries.Latency = cfg.SLOLatencyQuery
+	}
+	return queries
+}
@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// registerCreateAgentStack registers the create_agent_stack tool.
+func (ts *ToolServer) registerCreateAgentStack() {
+	tool := mcp.NewTool("create_agent_stack",
+		mcp.WithDescription("Generate everything a new agent needs in one step: a ModelConfig for the provider and model, the Agent using it with the selected tools and skills, and optionally RBAC and the Namespace. The manifests are validated together, so the Agent's reference to its new ModelConfig resolves, and returned as one multi-document YAML bundle in application order. Replaces chaining create_model_config_manifest, create_agent_manifest, generate_rbac_manifest and validate_manifest."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name for the new agent"),
+		),
+		mcp.WithString("description",
+			mcp.Required(),
+			mcp.Description("Human-readable description of what the agent does"),
+		),
+		mcp.WithString("system_message",
+			mcp.Required(),
+			mcp.Description("The system prompt that defines the agent's behavior, capabilities, and constraints"),
+		),
+		mcp.WithString("provider",
+			mcp.Required(),
+			mcp.Description("LLM provider: OpenAI, AzureOpenAI, Anthropic, Gemini, Ollama, or Custom"),
+		),
+		mcp.WithString("model",
+			mcp.Required(),
+			mcp.Description("Model identifier (e.g., gpt-4o, claude-sonnet-4-20250514, gemini-2.5-pro)"),
+		),
+		mcp.WithString("api_key_secret",
+			mcp.Description("Name of Kubernetes Secret containing the API key (required except for Ollama)"),
+		),
+		mcp.WithString("api_key_secret_key",
+			mcp.Description("Key within the secret that holds the API key (default varies by provider)"),
+		),
+		mcp.WithString("base_url",
+			mcp.Description("Custom base URL for the API (for Custom provider or proxies)"),
+		),
+		mcp.WithString("model_config_name",
+			mcp.Description("Name for the ModelConfig (default: '<name>-model')"),
+		),
+		mcp.WithString("tools_json",
+			mcp.Description(toolsJSONSchema+`. Example: [{"mcpServer": "server-name", "kind": "MCPServer", "tools": ["tool1", "tool2"]}]`),
+		),
+		mcp.WithString("skills_json",
+			mcp.Description(skillsJSONSchema+`. Example: [{"id": "skill-id", "name": "Skill Name", "description": "..."}]`),
+		),
+		withPartialOption(),
+		mcp.WithString("rbac",
+			mcp.Description("Also generate a ServiceAccount, Role and RoleBinding named after the agent with this permission preset: 'none' (default), 'readonly', 'standard', or 'admin' (see generate_rbac_manifest)"),
+		),
+		mcp.WithBoolean("include_namespace",
+			mcp.Description("Prepend a Namespace manifest so the bundle can be applied to a cluster where the namespace does not exist yet (default: false)"),
+		),
+		mcp.WithBoolean("strict",
+			mcp.Description("Validate with strict checks, including that the API key Secret exists (default: true)"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleCreateAgentStack)
+}
+
+func (ts *ToolServer) handleCreateAgentStack(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	name := args.RequiredString("name")
+	description := args.RequiredString("description")
+	systemMessage := args.RequiredString("system_message")
+	provider := args.RequiredEnum("provider", "OpenAI", "AzureOpenAI", "Anthropic", "Gemini", "Ollama", "Custom")
+	model := args.RequiredString("model")
+	apiKeySecret := args.String("api_key_secret")
+	apiKeySecretKey := args.String("api_key_secret_key")
+	baseURL := args.String("base_url")
+	modelConfigName := args.String("model_config_name")
+	toolsJSON := args.String("tools_json")
+	skillsJSON := args.String("skills_json")
+	partial := args.Bool("partial", false)
+	rbac := args.Enum("rbac", "none", "none", "readonly", "standard", "admin")
+	includeNamespace := args.Bool("include_namespace", false)
+	strict := args.Bool("strict", true)
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := ts.expandFacts(ctx, &description, &systemMessage, &baseURL); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if apiKeySecret == "" && provider != "Ollama" {
+		return mcp.NewToolResultError(fmt.Sprintf("api_key_secret is required for provider %s", provider)), nil
+	}
+	if modelConfigName == "" {
+		modelConfigName = name + "-model"
+	}
+	if apiKeySecretKey == "" && apiKeySecret != "" {
+		apiKeySecretKey = defaultAPIKeySecretKey(provider)
+	}
+
+	var skipped []string
+	var tools []types.ToolSpec
+	if toolsJSON != "" {
+		parsed, problems, err := parseToolConfigs("tools_json", toolsJSON, partial)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		tools = parsed
+		skipped = append(skipped, problems...)
+	}
+
+	var skills []types.Skill
+	if skillsJSON != "" {
+		parsed, problems, err := parseSkills("skills_json", skillsJSON, partial)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		skills = parsed
+		skipped = append(skipped, problems...)
+	}
+
+	namespace := ts.kube(ctx).Namespace()
+
+	// Documents in the order they must be applied: the namespace first, then
+	// what the agent runs as, then what it references, then the agent
+	var docs, order []string
+	if includeNamespace {
+		docs = append(docs, namespaceManifest(namespace))
+		order = append(order, "Namespace/"+namespace)
+	}
+	if rbac != "none" {
+		serviceAccount, role, roleBinding := rbacManifests(name, namespace, rbac)
+		docs = append(docs, serviceAccount+"\n", role+"\n", roleBinding+"\n")
+		order = append(order, "ServiceAccount/"+name, "Role/"+name+"-role", "RoleBinding/"+name+"-rolebinding")
+	}
+
+	config := newModelConfig(modelConfigName, namespace, provider, model, apiKeySecret, apiKeySecretKey, baseURL)
+	output, _ := yaml.Marshal(config)
+	docs = append(docs, string(output))
+	order = append(order, "ModelConfig/"+modelConfigName)
+
+	agent := newDeclarativeAgent(name, namespace, description, systemMessage, modelConfigName, tools, skills)
+	output, _ = yaml.Marshal(agent)
+	docs = append(docs, string(output))
+	order = append(order, "Agent/"+name)
+
+	issues, err := ts.validateBundle(ctx, docs, strict, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var header strings.Builder
+	fmt.Fprintf(&header, "# Generated Agent Stack for '%s'\n# Application order:", name)
+	for i, resource := range order {
+		fmt.Fprintf(&header, "\n#   %d. %s", i+1, resource)
+	}
+	if apiKeySecret != "" {
+		fmt.Fprintf(&header, "\n# IMPORTANT: Ensure the Kubernetes Secret '%s' exists with key '%s' containing the API key.", apiKeySecret, apiKeySecretKey)
+	}
+	if rbac != "none" || includeNamespace {
+		header.WriteString("\n# Core kinds are applied only when listed in KAGENT_APPLY_ALLOWED_KINDS.")
+	}
+	header.WriteString("\n" + validationComment(dedupeIssues(issues)))
+	header.WriteString("\n# Use diff_manifest to review, then apply_manifest to deploy the bundle in order.")
+	header.WriteString(skippedItemsComment(skipped))
+
+	return out.render(header.String(), strings.Join(docs, "---\n"))
+}
@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// StaleResource is a kagent resource the controller has not reconciled.
+type StaleResource struct {
+	Kind               string `json:"kind"`
+	Name               string `json:"name"`
+	Generation         int64  `json:"generation"`
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+	LastTransition     string `json:"lastTransition,omitempty"`
+	Age                string `json:"age"`
+	Reason             string `json:"reason"`
+}
+
+// staleKinds are the kagent kinds reconciled by the controller.
+var staleKinds = []struct {
+	Kind string
+	GVR  schema.GroupVersionResource
+}{
+	{"Agent", kubernetes.AgentGVR},
+	{"ModelConfig", kubernetes.ModelConfigGVR},
+	{"MCPServer", kubernetes.MCPServerGVR},
+	{"RemoteMCPServer", kubernetes.RemoteMCPServerGVR},
+}
+
+// kagentGVR returns the resource of a kagent kind reconciled by the
+// controller.
+func kagentGVR(kind string) (schema.GroupVersionResource, bool) {
+	for _, k := range staleKinds {
+		if k.Kind == kind {
+			return k.GVR, true
+		}
+	}
+	return schema.GroupVersionResource{}, false
+}
+
+// kagentGVRs returns the resources of the kagent kinds, to read them in one
+// snapshot.
+func kagentGVRs() []schema.GroupVersionResource {
+	gvrs := make([]schema.GroupVersionResource, 0, len(staleKinds))
+	for _, k := range staleKinds {
+		gvrs = append(gvrs, k.GVR)
+	}
+	return gvrs
+}
+
+// registerFindStaleResources registers the find_stale_resources tool.
+func (ts *ToolServer) registerFindStaleResources() {
+	tool := mcp.NewTool("find_stale_resources",
+		mcp.WithDescription("Find kagent resources the controller has not reconciled: status.observedGeneration behind metadata.generation, or no status at all, for longer than a threshold. Many stale resources usually means the controller is unhealthy."),
+		mcp.WithString("threshold",
+			mcp.Description("How long a resource may stay unreconciled before it is flagged, as a duration (e.g., '5m', '1h'). Default: '10m'"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Only check this kind: Agent, ModelConfig, MCPServer, or RemoteMCPServer"),
+		),
+		withAsyncOption(),
+	)
+
+	ts.addTool(tool, ts.withAsync("find_stale_resources", ts.handleFindStaleResources))
+}
+
+func (ts *ToolServer) handleFindStaleResources(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	threshold := args.Duration("threshold", 10*time.Minute)
+	kind := args.Enum("kind", "", "Agent", "ModelConfig", "MCPServer", "RemoteMCPServer")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	gvrs := kagentGVRs()
+	if kind != "" {
+		gvr, _ := kagentGVR(kind)
+		gvrs = []schema.GroupVersionResource{gvr}
+	}
+	snapshot, err := ts.kube(ctx).ListSnapshot(ctx, gvrs...)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list resources: %v", err)), nil
+	}
+
+	now := time.Now()
+	var stale []StaleResource
+	checked := 0
+	for _, k := range staleKinds {
+		if kind != "" && k.Kind != kind {
+			continue
+		}
+
+		items := snapshot.Items(k.GVR)
+		for i := range items {
+			checked++
+			if s, ok := checkStale(&items[i], k.Kind, threshold, now); ok {
+				stale = append(stale, s)
+			}
+		}
+	}
+
+	if len(stale) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("✓ All %d resource(s) have been reconciled (threshold %s).", checked, threshold)), nil
+	}
+
+	output, _ := json.MarshalIndent(stale, "", "  ")
+
+	summary := fmt.Sprintf("⚠️ %d of %d resource(s) have not been reconciled for more than %s.", len(stale), checked, threshold)
+	if len(stale) > 1 && len(stale)*2 >= checked {
+		summary += " Most resources are affected, which points at the kagent controller; run preflight_report to check it."
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("# Stale Resources\n\n%s\n\n%s", summary, string(output))), nil
+}
+
+// checkStale reports whether obj has gone unreconciled for longer than
+// threshold. The time a resource has been waiting is measured from its most
+// recent condition transition, or from its creation if it has none.
+func checkStale(obj *unstructured.Unstructured, kind string, threshold time.Duration, now time.Time) (StaleResource, bool) {
+	generation := obj.GetGeneration()
+	observed, hasObserved, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+
+	since := obj.GetCreationTimestamp().Time
+	var lastTransition string
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ts, _ := cond["lastTransitionTime"].(string)
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			continue
+		}
+		if t.After(since) {
+			since = t
+			lastTransition = ts
+		}
+	}
+
+	var reason string
+	switch {
+	case !hasObserved && len(conditions) == 0:
+		reason = "no status reported by the controller"
+	case hasObserved && observed < generation:
+		reason = fmt.Sprintf("observedGeneration %d is behind generation %d", observed, generation)
+	default:
+		return StaleResource{}, false
+	}
+
+	age := now.Sub(since)
+	if age < threshold {
+		return StaleResource{}, false
+	}
+
+	return StaleResource{
+		Kind:               kind,
+		Name:               obj.GetName(),
+		Generation:         generation,
+		ObservedGeneration: observed,
+		LastTransition:     lastTransition,
+		Age:                age.Round(time.Second).String(),
+		Reason:             reason,
+	}, true
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/stats"
+)
+
+// registerResourceTrends registers the resource_trends tool.
+func (ts *ToolServer) registerResourceTrends() {
+	tool := mcp.NewTool("resource_trends",
+		mcp.WithDescription("Report growth of kagent resources (agents, model configs, MCP servers) over time, based on periodic count snapshots. Helps spot runaway agent creation."),
+		mcp.WithString("window",
+			mcp.Description("Time window to report on as a duration (e.g., '24h', '168h'). Default: '168h' (7 days)"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Only report on this kind: Agent, ModelConfig, MCPServer, or RemoteMCPServer"),
+		),
+		mcp.WithBoolean("include_series",
+			mcp.Description("Include the raw snapshots in the window (default: false)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleResourceTrends)
+}
+
+func (ts *ToolServer) handleResourceTrends(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	window := args.Duration("window", 7*24*time.Hour)
+	kind := args.Enum("kind", "", stats.Kinds()...)
+	includeSeries := args.Bool("include_series", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cfg := ts.server.Config()
+	store := stats.NewStore(ts.kube(ctx), cfg.StatsConfigMap, cfg.StatsRetention)
+	snapshots, err := store.Snapshots(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read resource stats: %v", err)), nil
+	}
+
+	if len(snapshots) == 0 && cfg.Mode == ModeReadOnly {
+		return mcp.NewToolResultText(fmt.Sprintf("No resource stats recorded in ConfigMap '%s'. A server in readonly mode records none; servers in another mode sharing the ConfigMap do.", cfg.StatsConfigMap)), nil
+	}
+	if len(snapshots) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No resource stats recorded yet in ConfigMap '%s'. Snapshots are recorded every %s while the server runs.", cfg.StatsConfigMap, cfg.StatsInterval)), nil
+	}
+
+	since := time.Now().Add(-window)
+	trends := stats.Trends(snapshots, since)
+	if len(trends) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No snapshots recorded in the last %s.", window)), nil
+	}
+
+	if kind != "" {
+		var filtered []stats.Trend
+		for _, t := range trends {
+			if t.Kind == kind {
+				filtered = append(filtered, t)
+			}
+		}
+		if len(filtered) == 0 {
+			return mcp.NewToolResultText(fmt.Sprintf("No %s counts recorded in the last %s.", kind, window)), nil
+		}
+		trends = filtered
+	}
+
+	result := map[string]interface{}{
+		"window": window.String(),
+		"trends": trends,
+	}
+	if includeSeries {
+		var series []stats.Snapshot
+		for _, snap := range snapshots {
+			if !snap.Timestamp.Before(since) {
+				series = append(series, snap)
+			}
+		}
+		result["series"] = series
+	}
+
+	output, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
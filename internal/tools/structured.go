@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// Output formats of validation and diff tools, which report findings
+// rather than manifests.
+const (
+	// OutputText is the human-readable report.
+	OutputText = "text"
+	// OutputStructured is a JSON object with the summary, manifest, diff
+	// and findings in separate fields. Generators accept it too.
+	OutputStructured = "structured"
+)
+
+// diffIDPattern finds the review ID in a generator's comment preamble.
+var diffIDPattern = regexp.MustCompile(`Diff ID: ([A-Za-z0-9-]+)`)
+
+// structuredResult is the result of a tool called with
+// output_format=structured. Fields a tool has nothing for are omitted,
+// except warnings, which is always a list so callers can test it directly.
+type structuredResult struct {
+	// Summary is what the tool found or did, in plain text.
+	Summary string `json:"summary"`
+	// Manifest is the generated or proposed YAML, ready to apply.
+	Manifest string `json:"manifest,omitempty"`
+	// Objects are the manifest's documents, parsed.
+	Objects []map[string]interface{} `json:"objects,omitempty"`
+	// Diff is the change in the requested diff format.
+	Diff string `json:"diff,omitempty"`
+	// DiffID is the review ID that apply_manifest accepts.
+	DiffID string `json:"diffId,omitempty"`
+	// Digest identifies the manifest for approvers who sign it.
+	Digest string `json:"digest,omitempty"`
+	// ResourceVersion is the version a diff was computed against.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// Valid is set by validation tools: false when there are errors.
+	Valid *bool `json:"valid,omitempty"`
+	// Errors block applying; warnings do not.
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings"`
+	// Issues are a validation tool's findings with their fields and fixes.
+	Issues interface{} `json:"issues,omitempty"`
+}
+
+// withStructuredOutputOption adds the output_format argument to a
+// validation or diff tool.
+func withStructuredOutputOption() mcp.ToolOption {
+	return mcp.WithString("output_format",
+		mcp.Description("Output format: 'text' (a report for people) or 'structured' (a JSON object with separate summary, manifest, diff, errors and warnings fields, for agents and scripts). Default: 'text'"),
+	)
+}
+
+// structuredOutputFrom reports whether a validation or diff tool was called
+// with output_format=structured.
+func structuredOutputFrom(args *params.Args) bool {
+	return args.Enum("output_format", OutputText, OutputText, OutputStructured) == OutputStructured
+}
+
+// structured returns r as the JSON result of a tool.
+func structured(r structuredResult) (*mcp.CallToolResult, error) {
+	if r.Warnings == nil {
+		r.Warnings = []string{}
+	}
+	output, _ := json.MarshalIndent(r, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// structuredFromHeader splits a generator's comment preamble into the
+// summary, the warnings and errors it reports, and the review ID.
+func structuredFromHeader(header string) structuredResult {
+	var r structuredResult
+	var summary []string
+	for _, line := range strings.Split(header, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+		if line == "" {
+			continue
+		}
+		switch severity, finding := splitFinding(line); severity {
+		case "error":
+			r.Errors = append(r.Errors, finding)
+		case "warning":
+			r.Warnings = append(r.Warnings, finding)
+		default:
+			summary = append(summary, line)
+		}
+		if m := diffIDPattern.FindStringSubmatch(line); m != nil {
+			r.DiffID = m[1]
+		}
+	}
+	r.Summary = strings.Join(summary, "\n")
+	return r
+}
+
+// splitFinding recognizes a line reporting a finding, such as
+// "⚠️  WARNING [spec.x]: ..." or "WARNING: ...", and returns its severity
+// and the finding without the marker. Other lines have no severity.
+func splitFinding(line string) (severity, finding string) {
+	rest := strings.TrimLeftFunc(line, func(r rune) bool { return !unicode.IsLetter(r) && r != '[' })
+	for _, marker := range []struct{ prefix, severity string }{{"ERROR", "error"}, {"WARNING", "warning"}} {
+		if strings.HasPrefix(strings.ToUpper(rest), marker.prefix) {
+			return marker.severity, strings.TrimSpace(strings.TrimPrefix(rest[len(marker.prefix):], ":"))
+		}
+	}
+	return "", line
+}
+
+// issueMessages splits validation issues into error and warning messages.
+func issueMessages(issues []groupedIssue) (errors, warnings []string) {
+	for _, issue := range issues {
+		message := fmt.Sprintf("[%s] (%s): %s", issue.Field, strings.Join(issue.Resources, ", "), issue.Message)
+		if issue.Severity == "error" {
+			errors = append(errors, message)
+		} else {
+			warnings = append(warnings, message)
+		}
+	}
+	return errors, warnings
+}
@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// Timeline entry sources.
+const (
+	timelineLifecycle = "lifecycle"
+	timelineRevision  = "revision"
+	timelineWrite     = "write"
+	timelineStatus    = "status"
+	timelineEvent     = "event"
+)
+
+// TimelineEntry is one thing that happened to a resource.
+type TimelineEntry struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Summary string    `json:"summary"`
+	// Warning marks entries that point at a problem (Warning events,
+	// conditions turning False).
+	Warning bool `json:"warning,omitempty"`
+}
+
+// registerResourceTimeline registers the resource_timeline tool.
+func (ts *ToolServer) registerResourceTimeline() {
+	tool := mcp.NewTool("resource_timeline",
+		mcp.WithDescription("Walk through everything that happened to one kagent resource, oldest first: its creation, the revisions recorded when it was applied through this server (with the spec fields each changed), the last write of each field manager (kubectl, GitOps tools, controllers, this server), status condition transitions, and the Kubernetes Events on it and on the Deployment and Pods running it. Answers 'what happened to agent X this week?'."),
+		mcp.WithString("kind",
+			mcp.Description("Kind of the resource: Agent, ModelConfig, MCPServer or RemoteMCPServer (default: Agent)"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the resource"),
+		),
+		mcp.WithString("since",
+			mcp.Description("How far back to go, as a duration (e.g., '24h', '168h'). Default: '168h' (a week)"),
+		),
+		mcp.WithString("output_format",
+			mcp.Description("Output format: 'text' (default), one line per entry, or 'json'"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleResourceTimeline)
+}
+
+func (ts *ToolServer) handleResourceTimeline(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.Enum("kind", "Agent", "Agent", "ModelConfig", "MCPServer", "RemoteMCPServer")
+	name := args.RequiredString("name")
+	since := args.Duration("since", 7*24*time.Hour)
+	format := args.Enum("output_format", "text", "text", "json")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client := ts.kube(ctx)
+	gvr, _ := kagentGVR(kind)
+	obj, err := client.GetResource(ctx, gvr, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get %s '%s': %v", kind, name, err)), nil
+	}
+
+	var entries []TimelineEntry
+	var notes []string
+
+	entries = append(entries, TimelineEntry{
+		Time:    obj.GetCreationTimestamp().Time,
+		Source:  timelineLifecycle,
+		Summary: fmt.Sprintf("%s created", kind),
+	})
+	if deleted := obj.GetDeletionTimestamp(); deleted != nil {
+		entries = append(entries, TimelineEntry{
+			Time:    deleted.Time,
+			Source:  timelineLifecycle,
+			Summary: fmt.Sprintf("deletion requested, waiting on finalizers %v", obj.GetFinalizers()),
+			Warning: true,
+		})
+	}
+
+	history, err := ts.revisionStore(ctx).List(ctx, kind, name)
+	if err != nil {
+		notes = append(notes, fmt.Sprintf("revisions unavailable: %v", err))
+	}
+	var previous map[string]interface{}
+	for _, r := range history {
+		summary := fmt.Sprintf("revision %d recorded (%s)", r.Number, r.Action)
+		if previous != nil {
+			if changed := changedFields(previous, r.Spec); len(changed) > 0 {
+				summary += ", changed " + strings.Join(changed, ", ")
+			}
+		}
+		entries = append(entries, TimelineEntry{Time: r.Timestamp, Source: timelineRevision, Summary: summary})
+		previous = r.Spec
+	}
+
+	for _, m := range obj.GetManagedFields() {
+		if m.Time == nil {
+			continue
+		}
+		summary := fmt.Sprintf("%s by %s", strings.ToLower(string(m.Operation)), m.Manager)
+		if m.Subresource != "" {
+			summary += " (" + m.Subresource + ")"
+		}
+		entries = append(entries, TimelineEntry{Time: m.Time.Time, Source: timelineWrite, Summary: summary + ", its latest write"})
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(cond, "type")
+		status, _, _ := unstructured.NestedString(cond, "status")
+		reason, _, _ := unstructured.NestedString(cond, "reason")
+		message, _, _ := unstructured.NestedString(cond, "message")
+		raw, _, _ := unstructured.NestedString(cond, "lastTransitionTime")
+		at, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			continue
+		}
+		summary := fmt.Sprintf("%s became %s", conditionType, status)
+		if reason != "" {
+			summary += " (" + reason + ")"
+		}
+		if message != "" {
+			summary += ": " + message
+		}
+		entries = append(entries, TimelineEntry{Time: at, Source: timelineStatus, Summary: summary, Warning: status == "False"})
+	}
+
+	events, known, err := client.ListEvents(ctx)
+	switch {
+	case err != nil:
+		notes = append(notes, fmt.Sprintf("events unavailable: %v", err))
+	case !known:
+		notes = append(notes, "events unavailable: the server may not list events")
+	default:
+		owners, err := eventOwners(ctx, client)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, e := range events {
+			ownerKind, owner, ok := eventOwner(e, owners)
+			if !ok || ownerKind != kind || owner != name {
+				continue
+			}
+			summary := fmt.Sprintf("%s on %s/%s: %s", e.Reason, e.Kind, e.Name, e.Message)
+			if e.Count > 1 {
+				summary = fmt.Sprintf("%s (%dx since %s)", summary, e.Count, e.FirstSeen.UTC().Format(time.RFC3339))
+			}
+			entries = append(entries, TimelineEntry{Time: e.LastSeen, Source: timelineEvent, Summary: summary, Warning: e.Type == "Warning"})
+		}
+		notes = append(notes, "Kubernetes keeps events for about an hour by default; older ones are gone")
+	}
+
+	cutoff := time.Now().Add(-since)
+	kept := entries[:0]
+	for _, e := range entries {
+		if !e.Time.Before(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	entries = kept
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+
+	header := fmt.Sprintf("# Timeline of %s '%s' over the last %s (%d entries)", kind, name, since, len(entries))
+	for _, note := range notes {
+		header += "\n# Note: " + note
+	}
+
+	if format == "json" {
+		output, _ := json.MarshalIndent(entries, "", "  ")
+		return mcp.NewToolResultText(header + "\n\n" + string(output)), nil
+	}
+	if len(entries) == 0 {
+		return mcp.NewToolResultText(header + "\n\nNothing happened in this window."), nil
+	}
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		marker := ""
+		if e.Warning {
+			marker = "⚠️ "
+		}
+		lines = append(lines, fmt.Sprintf("%s [%s] %s%s", e.Time.UTC().Format(time.RFC3339), e.Source, marker, e.Summary))
+	}
+	return mcp.NewToolResultText(header + "\n\n" + strings.Join(lines, "\n")), nil
+}
+
+// changedFields lists the spec fields that differ between two revisions,
+// as "spec.<field>" or, within objects such as declarative,
+// "spec.<field>.<field>", in order.
+func changedFields(previous, current map[string]interface{}) []string {
+	return diffFields("spec", previous, current, 2)
+}
+
+func diffFields(prefix string, previous, current map[string]interface{}, depth int) []string {
+	keys := map[string]bool{}
+	for k := range previous {
+		keys[k] = true
+	}
+	for k := range current {
+		keys[k] = true
+	}
+
+	var changed []string
+	for _, k := range sortedKeys(keys) {
+		if reflect.DeepEqual(previous[k], current[k]) {
+			continue
+		}
+		prev, prevOK := previous[k].(map[string]interface{})
+		cur, curOK := current[k].(map[string]interface{})
+		if depth > 1 && prevOK && curOK {
+			changed = append(changed, diffFields(prefix+"."+k, prev, cur, depth-1)...)
+			continue
+		}
+		changed = append(changed, prefix+"."+k)
+	}
+	return changed
+}
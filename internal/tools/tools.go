@@ -2,48 +2,340 @@
 package tools
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kagent-dev/meta-kagent/internal/archive"
+	"github.com/kagent-dev/meta-kagent/internal/cache"
+	"github.com/kagent-dev/meta-kagent/internal/capabilities"
+	"github.com/kagent-dev/meta-kagent/internal/clusterfacts"
+	"github.com/kagent-dev/meta-kagent/internal/crdschema"
+	"github.com/kagent-dev/meta-kagent/internal/elevation"
+	"github.com/kagent-dev/meta-kagent/internal/endpoints"
+	"github.com/kagent-dev/meta-kagent/internal/jobs"
 	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/revisions"
+	"github.com/kagent-dev/meta-kagent/internal/schedule"
 	mcpserver "github.com/kagent-dev/meta-kagent/internal/server"
+	"github.com/kagent-dev/meta-kagent/internal/state"
+	"github.com/kagent-dev/meta-kagent/internal/tenancy"
+	"github.com/kagent-dev/meta-kagent/internal/topology"
+	ruleset "github.com/kagent-dev/meta-kagent/internal/validation"
 )
 
 // ToolServer holds the dependencies for tool handlers.
 type ToolServer struct {
-	server    *mcpserver.Server
-	k8sClient *kubernetes.Client
+	server       *mcpserver.Server
+	state        state.Store
+	reviews      *reviewStore
+	jobs         *jobs.Manager
+	results      *cache.Cache
+	topology     *topology.Index
+	toolListings *cache.Cache
+	elevations   *elevation.Store
+	schedules    *schedule.Runner
+	environments *endpoints.Environments
+	crdSchemas   crdschema.Schemas
+	clusterFacts *clusterfacts.Provider
+	rules        *ruleset.Registry
+	gates        toolGates
 }
 
 // RegisterAll registers all tools with the MCP server.
 func RegisterAll(s *mcpserver.Server) {
-	ts := &ToolServer{
-		server:    s,
-		k8sClient: s.K8sClient(),
+	cfg := s.Config()
+	var st state.Store
+	if cfg.Mode == ModeReadOnly {
+		// A read-only server writes nothing, not even its own state
+		if cfg.StateStore != "" && cfg.StateStore != state.BackendMemory {
+			fmt.Fprintf(os.Stderr, "Readonly mode keeps state in memory: KAGENT_STATE_STORE=%s is ignored\n", cfg.StateStore)
+		}
+		st = state.NewMemoryStore()
+	} else {
+		var err error
+		st, err = state.Open(cfg.StateStore, s.K8sClient(), cfg.StateConfigMap, cfg.StateDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open state store, keeping state in memory: %v\n", err)
+			st = state.NewMemoryStore()
+		}
+	}
+
+	if cfg.Mode == ModePropose && (cfg.StateStore == "" || cfg.StateStore == state.BackendMemory) {
+		fmt.Fprintf(os.Stderr, "Propose mode keeps pending changes in memory: they are lost on restart unless KAGENT_STATE_STORE is file or configmap\n")
+	}
+
+	ts := newToolServer(s, st)
+	ts.loadEnvironments()
+	ts.loadCRDSchemas()
+	ts.loadValidationPolicies()
+	if err := capabilities.CheckNames(cfg.AssumeCapabilities); err != nil {
+		fmt.Fprintf(os.Stderr, "KAGENT_ASSUME_CAPABILITIES: %v\n", err)
 	}
+	ts.detectCapabilities(context.Background())
+
+	// Maintain the dependency topology from watch events
+	go func() {
+		if err := ts.topology.Run(context.Background(), s.K8sClient()); err != nil {
+			fmt.Fprintf(os.Stderr, "Topology index stopped: %v\n", err)
+		}
+	}()
 
 	// Discovery tools
+	ts.registerClusterOverview()
+	ts.registerQuery()
 	ts.registerListAgents()
 	ts.registerGetAgent()
+	ts.registerGetAgentStatus()
 	ts.registerListModelConfigs()
+	ts.registerVerifyModelSecret()
 	ts.registerListMCPServers()
+	ts.registerGetToolList()
+	ts.registerGetToolSchema()
+	ts.registerPreflightReport()
+	ts.registerControllerHealth()
+	ts.registerGetClusterFacts()
+	ts.registerListUnavailableTools()
+	ts.registerResourceTrends()
+	ts.registerFindStaleResources()
+	ts.registerSummarizeRecentEvents()
+	ts.registerResourceTimeline()
+	ts.registerReadinessGateReport()
+	ts.registerDiagnoseAgent()
+	ts.registerAdvisePlacement()
+	ts.registerCheckRequirements()
+	ts.registerWhoCallsWhom()
+	ts.registerReverseDependencies()
+	ts.registerAgentDependencyGraph()
+	ts.registerDefineAgentSLO()
+	ts.registerCheckSLOCompliance()
+	ts.registerWhenAvailable("generate_slo_alert_rules", ts.registerGenerateSLOAlertRules, capabilities.PrometheusOperator)
+	ts.registerWhenAvailable("generate_tracing_config", ts.registerGenerateTracingConfig, capabilities.OpenTelemetryOperator)
+	ts.registerCreateAgentTests()
+	ts.registerRunAgentTests()
+	ts.registerGetAgentFlags()
+	ts.registerSetAgentFlag()
+	ts.registerRecommendResources()
+	ts.registerUpgradeAssistant()
 
 	// Generation tools
 	ts.registerCreateAgentManifest()
 	ts.registerUpdateAgentManifest()
+	ts.registerPatchAgent()
 	ts.registerCreateModelConfigManifest()
 	ts.registerCreateMCPServerManifest()
 	ts.registerGenerateRBACManifest()
+	ts.registerCompareRBAC()
+	ts.registerCreateAgentStack()
+	ts.registerBootstrapNamespace()
+	ts.registerAdoptWorkload()
+	ts.registerCopyNamespace()
+	ts.registerExportResources()
+	ts.registerImportResources()
 
 	// Validation and mutation tools
 	ts.registerValidateManifest()
+	ts.registerListValidationRules()
+	ts.registerSecurityReview()
+	ts.registerExtractSecrets()
 	ts.registerDiffManifest()
+	ts.registerDiffRevisions()
+	ts.registerListRevisions()
+	ts.registerRollbackResource()
+	ts.registerGetResource()
+	ts.registerWhoManagesField()
 	ts.registerApplyManifest()
+	ts.registerRenameAgent()
 	ts.registerDeleteAgent()
+	ts.registerDeleteResource()
+	ts.registerDeleteMatching()
+	ts.registerArchiveAgent()
+	ts.registerListArchivedAgents()
+	ts.registerRestoreArchivedAgent()
+
+	// Elevation tools, only useful where the server may change the cluster
+	if cfg.Mode == ModeApply {
+		ts.registerRequestElevation()
+		ts.registerApproveElevation()
+		ts.registerElevationStatus()
+		ts.registerReleaseElevation()
+	}
+
+	// Pending change tools
+	if cfg.Mode == ModePropose {
+		ts.registerListPendingChanges()
+		ts.registerDiscardPendingChange()
+	}
+
+	// Background job tools
+	ts.registerGetJobStatus()
+	ts.registerGetJobResult()
+
+	// Scheduled run tools
+	ts.registerListSchedules()
+	ts.registerRunSchedule()
 
 	// A2A (Agent-to-Agent) tools
 	ts.registerListAgentSkills()
 	ts.registerDiscoverA2AAgents()
 	ts.registerGetAgentCard()
+	ts.registerExportAgentCards()
 	ts.registerCreateSkillManifest()
 	ts.registerValidateSkill()
 	ts.registerAddSkillToAgent()
 	ts.registerRemoveSkillFromAgent()
+	ts.registerSyncSkills()
+	ts.registerConfigureA2ASecurity()
+	ts.registerWhenAvailable("expose_agent", ts.registerExposeAgent, capabilities.CertManager)
+	ts.registerRunA2AConformance()
+	ts.registerDeployMockAgent()
+
+	// Resources readable by URI
+	ts.registerResources()
+
+	// Prompts encoding the recommended workflows
+	ts.registerPrompts()
+
+	// Run read-only tools on schedules once they are all registered
+	ts.startScheduler()
+}
+
+// newToolServer creates the dependencies of the tool handlers, keeping
+// state in st.
+func newToolServer(s *mcpserver.Server, st state.Store) *ToolServer {
+	ts := &ToolServer{
+		server:       s,
+		state:        st,
+		reviews:      newReviewStore(st),
+		jobs:         jobs.NewManager(context.Background(), s.Config().JobWorkers, s.Config().JobTimeout),
+		results:      cache.New(s.Config().CacheEntries),
+		topology:     topology.NewIndex(s.K8sClient().Namespace()),
+		toolListings: cache.New(s.Config().CacheEntries),
+		elevations:   elevation.NewStore(s.Config().ElevationSecret, os.Stderr, st),
+		clusterFacts: clusterfacts.NewProvider(s.K8sClient(), clusterfacts.Facts{
+			ClusterName:   s.Config().ClusterName,
+			CloudProvider: s.Config().CloudProvider,
+			Region:        s.Config().ClusterRegion,
+			IngressDomain: s.Config().IngressDomain,
+		}),
+		rules: ruleset.NewRegistry(),
+	}
+	_ = ts.rules.Register(ts.builtinRules()...)
+	return ts
+}
+
+// kube returns the Kubernetes client for the calling session. Handlers must
+// use it for every call so tenant sessions stay within their own identity.
+func (ts *ToolServer) kube(ctx context.Context) *kubernetes.Client {
+	return ts.server.K8sClientFor(ctx)
+}
+
+// addTool registers a tool that operates in the namespace given by its
+// optional namespace argument, defaulting to the session's namespace.
+// Mutating tools are left out outside apply mode, and in apply mode gated
+// behind elevation when KAGENT_ELEVATION_SECRET is set.
+// Tools that do not touch namespaced resources register with the server
+// directly.
+func (ts *ToolServer) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if !ts.offeredInMode(tool.Name) {
+		fmt.Fprintf(os.Stderr, "Tool %s is not offered in %s mode\n", tool.Name, ts.server.Config().Mode)
+		ts.server.ReserveToolName(tool.Name)
+		return
+	}
+	withNamespaceOption()(&tool)
+	ts.server.AddTool(tool, ts.withNamespace(ts.withElevation(tool.Name, handler)))
+}
+
+// withNamespaceOption adds the namespace argument to a tool.
+func withNamespaceOption() mcp.ToolOption {
+	return mcp.WithString("namespace",
+		mcp.Description("Namespace to operate in (default: the server's namespace)"),
+	)
+}
+
+// withNamespace scopes a handler to the namespace argument of the call.
+// Tenant sessions may only use their own namespace.
+func (ts *ToolServer) withNamespace(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := params.From(req)
+		namespace := strings.TrimSpace(args.String("namespace"))
+		if err := args.Err(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if namespace == "" {
+			return handler(ctx, req)
+		}
+
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid namespace '%s': %s", namespace, strings.Join(errs, "; "))), nil
+		}
+		if t, ok := tenancy.FromContext(ctx); ok && namespace != t.Namespace {
+			return mcp.NewToolResultError(fmt.Sprintf("Tenant '%s' may only operate in namespace '%s'", t.Name, t.Namespace)), nil
+		}
+		return handler(mcpserver.WithNamespace(ctx, namespace), req)
+	}
+}
+
+// listClient returns the client a list tool reads with: the session's
+// client, or one spanning every namespace when allNamespaces is set.
+func (ts *ToolServer) listClient(ctx context.Context, allNamespaces, refresh bool) (*kubernetes.Client, error) {
+	if !allNamespaces {
+		return ts.readClient(ctx, refresh), nil
+	}
+	if _, ok := tenancy.FromContext(ctx); ok {
+		return nil, fmt.Errorf("all_namespaces is not available to tenant sessions")
+	}
+	return ts.kube(ctx).AllNamespaces(), nil
+}
+
+// readClient returns the session's client, bypassing the informer cache
+// when refresh is set.
+func (ts *ToolServer) readClient(ctx context.Context, refresh bool) *kubernetes.Client {
+	if refresh {
+		return ts.kube(ctx).Uncached()
+	}
+	return ts.kube(ctx)
+}
+
+// withRefreshOption adds the refresh argument to a tool that reads kagent
+// resources through the informer cache.
+func withRefreshOption() mcp.ToolOption {
+	return mcp.WithBoolean("refresh",
+		mcp.Description("Read from the API server instead of the watch cache, for changes made moments ago outside this server (default: false)"),
+	)
+}
+
+// withAllNamespacesOption adds the all_namespaces argument to a list tool.
+func withAllNamespacesOption() mcp.ToolOption {
+	return mcp.WithBoolean("all_namespaces",
+		mcp.Description("List across all namespaces the server can read (default: false)"),
+	)
+}
+
+// revisionStore returns the revision history for the calling session. It is
+// kept in the session's namespace, with the session's identity.
+func (ts *ToolServer) revisionStore(ctx context.Context) *revisions.Store {
+	cfg := ts.server.Config()
+	return revisions.NewStore(ts.kube(ctx), cfg.RevisionsConfigMap, cfg.RevisionsRetention)
+}
+
+// archiveStore returns the agent archive for the calling session. It is kept
+// in the session's namespace, with the session's identity.
+func (ts *ToolServer) archiveStore(ctx context.Context) *archive.Store {
+	return archive.NewStore(ts.kube(ctx), ts.server.Config().ArchiveConfigMap)
+}
+
+// sessionOwner identifies the calling session for server-side state such as
+// jobs and reviewed diffs: the tenant name, or empty for untenanted sessions.
+func sessionOwner(ctx context.Context) string {
+	if t, ok := tenancy.FromContext(ctx); ok {
+		return t.Name
+	}
+	return ""
 }
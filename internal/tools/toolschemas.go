@@ -0,0 +1,353 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+)
+
+// toolListingTimeout bounds the handshake and tools/list exchange with a
+// deployed MCP server.
+const toolListingTimeout = 30 * time.Second
+
+// mcpServerPath is the path kagent serves an MCPServer's Streamable HTTP
+// endpoint on.
+const mcpServerPath = "/mcp"
+
+// mcpProtocolVersion is the MCP protocol version requested on initialize,
+// the first to define the Streamable HTTP transport.
+const mcpProtocolVersion = "2025-03-26"
+
+// Transport protocols, as named by RemoteMCPServer.spec.protocol.
+const (
+	protocolStreamableHTTP = "STREAMABLE_HTTP"
+	protocolSSE            = "SSE"
+)
+
+// listedTool is a tool advertised by a deployed MCP server.
+type listedTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema,omitempty"`
+}
+
+// listedServer identifies the server that answered initialize.
+type listedServer struct {
+	Name            string `json:"name,omitempty"`
+	Version         string `json:"version,omitempty"`
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+}
+
+// toolListing is the result of listing a deployed server's tools, as it is
+// cached.
+type toolListing struct {
+	Endpoint  string       `json:"endpoint"`
+	Protocol  string       `json:"protocol"`
+	Server    listedServer `json:"server"`
+	Tools     []listedTool `json:"tools"`
+	FetchedAt time.Time    `json:"fetchedAt"`
+}
+
+// find returns the tool with the given name.
+func (l *toolListing) find(name string) (listedTool, bool) {
+	for _, tool := range l.Tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return listedTool{}, false
+}
+
+// names returns the names of the listed tools.
+func (l *toolListing) names() []string {
+	names := make([]string, 0, len(l.Tools))
+	for _, tool := range l.Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+// listMCPTools connects to the MCP server at endpoint, initializes a
+// session and returns every tool it advertises. The context bounds the
+// whole exchange.
+func listMCPTools(ctx context.Context, endpoint, protocol string) (*toolListing, error) {
+	var (
+		client *mcpclient.Client
+		err    error
+	)
+	switch protocol {
+	case "", protocolStreamableHTTP:
+		protocol = protocolStreamableHTTP
+		client, err = mcpclient.NewStreamableHttpClient(endpoint, transport.WithHTTPTimeout(toolListingTimeout))
+	case protocolSSE:
+		client, err = mcpclient.NewSSEMCPClient(endpoint)
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q: must be %s or %s", protocol, protocolStreamableHTTP, protocolSSE)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Start(ctx); err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	init := mcp.InitializeRequest{}
+	init.Params.ProtocolVersion = mcpProtocolVersion
+	init.Params.ClientInfo = mcp.Implementation{Name: "kmeta-agent", Version: "1.0.0"}
+	initialized, err := client.Initialize(ctx, init)
+	if err != nil {
+		return nil, fmt.Errorf("initialize: %w", err)
+	}
+
+	result, err := client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("tools/list: %w", err)
+	}
+
+	listing := &toolListing{
+		Endpoint: endpoint,
+		Protocol: protocol,
+		Server: listedServer{
+			Name:            initialized.ServerInfo.Name,
+			Version:         initialized.ServerInfo.Version,
+			ProtocolVersion: initialized.ProtocolVersion,
+		},
+		Tools:     make([]listedTool, 0, len(result.Tools)),
+		FetchedAt: time.Now().UTC(),
+	}
+	for _, tool := range result.Tools {
+		schema, _ := json.Marshal(tool.InputSchema)
+		listing.Tools = append(listing.Tools, listedTool{Name: tool.Name, Description: tool.Description, InputSchema: schema})
+	}
+	return listing, nil
+}
+
+// registerGetToolList registers the get_tool_list tool.
+func (ts *ToolServer) registerGetToolList() {
+	tool := mcp.NewTool("get_tool_list",
+		mcp.WithDescription("List the tools a deployed MCPServer or RemoteMCPServer exposes, with their descriptions and input schemas, by connecting to the running server. Use the names as toolNames when wiring the server into an agent."),
+		mcp.WithString("server_name",
+			mcp.Required(),
+			mcp.Description("Name of the MCPServer or RemoteMCPServer"),
+		),
+		mcp.WithString("server_kind",
+			mcp.Description("'MCPServer' or 'RemoteMCPServer' (default: whichever exists, MCPServer first)"),
+		),
+		mcp.WithString("endpoint_url",
+			mcp.Description("MCP endpoint URL (defaults to the RemoteMCPServer's url, or http://<name>.<namespace>.svc.cluster.local:<port>/mcp for an MCPServer)"),
+		),
+		mcp.WithBoolean("include_schemas",
+			mcp.Description("Include each tool's input schema (default: true)"),
+		),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Ask the server again instead of using a cached tool listing (default: false)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleGetToolList)
+}
+
+func (ts *ToolServer) handleGetToolList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	serverName := args.RequiredString("server_name")
+	serverKind := args.Enum("server_kind", "", "MCPServer", "RemoteMCPServer")
+	endpointURL := args.String("endpoint_url")
+	includeSchemas := args.Bool("include_schemas", true)
+	refresh := args.Bool("refresh", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	listing, cached, err := ts.mcpToolListing(ctx, serverKind, serverName, endpointURL, refresh)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if len(listing.Tools) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("Server '%s' at %s exposes no tools.", serverName, listing.Endpoint)), nil
+	}
+
+	tools := listing.Tools
+	if !includeSchemas {
+		tools = make([]listedTool, 0, len(listing.Tools))
+		for _, tool := range listing.Tools {
+			tools = append(tools, listedTool{Name: tool.Name, Description: tool.Description})
+		}
+	}
+	output, _ := json.MarshalIndent(tools, "", "  ")
+
+	source := "live"
+	if cached {
+		source = "cached"
+	}
+	server := listing.Server.Name
+	if listing.Server.Version != "" {
+		server += " " + listing.Server.Version
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf(`# Tools of '%s' (%s)
+# Endpoint: %s (%s)
+# %d tool(s), fetched %s (%s)
+# Wire them into an agent with toolNames: [%s]
+
+%s`, serverName, strings.TrimSpace(server), listing.Endpoint, listing.Protocol, len(listing.Tools),
+		listing.FetchedAt.Format(time.RFC3339), source, strings.Join(listing.names(), ", "), string(output))), nil
+}
+
+// registerGetToolSchema registers the get_tool_schema tool.
+func (ts *ToolServer) registerGetToolSchema() {
+	tool := mcp.NewTool("get_tool_schema",
+		mcp.WithDescription("Get the description and input schema of a tool exposed by a deployed MCPServer or RemoteMCPServer, to show its exact parameter contract before wiring it into an agent. Tool listings are cached per server until its resourceVersion changes or the cache entry expires; use get_tool_list to see every tool."),
+		mcp.WithString("server_name",
+			mcp.Required(),
+			mcp.Description("Name of the MCPServer or RemoteMCPServer"),
+		),
+		mcp.WithString("tool_name",
+			mcp.Required(),
+			mcp.Description("Name of the tool as exposed by the server"),
+		),
+		mcp.WithString("server_kind",
+			mcp.Description("'MCPServer' or 'RemoteMCPServer' (default: whichever exists, MCPServer first)"),
+		),
+		mcp.WithString("endpoint_url",
+			mcp.Description("MCP endpoint URL (defaults to the RemoteMCPServer's url, or http://<name>.<namespace>.svc.cluster.local:<port>/mcp for an MCPServer)"),
+		),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Ask the server again instead of using a cached tool listing (default: false)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleGetToolSchema)
+}
+
+func (ts *ToolServer) handleGetToolSchema(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	serverName := args.RequiredString("server_name")
+	toolName := args.RequiredString("tool_name")
+	serverKind := args.Enum("server_kind", "", "MCPServer", "RemoteMCPServer")
+	endpointURL := args.String("endpoint_url")
+	refresh := args.Bool("refresh", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	listing, cached, err := ts.mcpToolListing(ctx, serverKind, serverName, endpointURL, refresh)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	tool, ok := listing.find(toolName)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("Server '%s' has no tool '%s'.%s", serverName, toolName, toolNameHint(listing.names(), toolName))), nil
+	}
+
+	result := map[string]interface{}{
+		"server":      serverName,
+		"endpoint":    listing.Endpoint,
+		"name":        tool.Name,
+		"description": tool.Description,
+		"inputSchema": tool.InputSchema,
+		"fetchedAt":   listing.FetchedAt,
+		"cached":      cached,
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// mcpToolListing returns the tools advertised by an MCPServer or
+// RemoteMCPServer, and whether the listing came from the cache. Listings are
+// cached per server and endpoint while the server's resourceVersion is
+// unchanged and for at most the configured TTL. An empty kind looks for an
+// MCPServer, then a RemoteMCPServer.
+func (ts *ToolServer) mcpToolListing(ctx context.Context, kind, name, endpointURL string, refresh bool) (*toolListing, bool, error) {
+	kind, endpoint, protocol, resourceVersion, err := ts.resolveMCPEndpoint(ctx, kind, name, endpointURL, refresh)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := "tool-listing\x00" + sessionOwner(ctx) + "\x00" + ts.kube(ctx).Namespace() + "\x00" + kind + "/" + name
+	fingerprint := resourceVersion + "\x00" + endpoint + "\x00" + protocol
+
+	if !refresh {
+		if text, ok := ts.toolListings.Get(key, fingerprint); ok {
+			var listing toolListing
+			if json.Unmarshal([]byte(text), &listing) == nil && time.Since(listing.FetchedAt) < ts.server.Config().ToolSchemaTTL {
+				return &listing, true, nil
+			}
+		}
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, toolListingTimeout)
+	defer cancel()
+	listing, err := listMCPTools(fetchCtx, endpoint, protocol)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list tools of %s '%s' at %s: %v", kind, name, endpoint, err)
+	}
+
+	data, _ := json.Marshal(listing)
+	ts.toolListings.Put(key, fingerprint, string(data))
+	return listing, false, nil
+}
+
+// resolveMCPEndpoint finds an MCP server resource and returns its kind, the
+// endpoint and protocol to reach it with, and its resourceVersion.
+func (ts *ToolServer) resolveMCPEndpoint(ctx context.Context, kind, name, endpointURL string, refresh bool) (string, string, string, string, error) {
+	client := ts.readClient(ctx, refresh)
+
+	if kind == "" || kind == "MCPServer" {
+		server, err := client.GetMCPServer(ctx, name)
+		switch {
+		case err == nil:
+			if endpointURL == "" {
+				port := int32(3000)
+				if server.Spec.Deployment != nil && server.Spec.Deployment.Port != 0 {
+					port = server.Spec.Deployment.Port
+				}
+				endpointURL = fmt.Sprintf("http://%s.%s.svc.cluster.local:%d%s", name, client.Namespace(), port, mcpServerPath)
+			}
+			return "MCPServer", endpointURL, protocolStreamableHTTP, server.ResourceVersion, nil
+		case kind != "" || !apierrors.IsNotFound(err):
+			return "", "", "", "", fmt.Errorf("failed to get MCPServer: %v", err)
+		}
+	}
+
+	server, err := client.GetRemoteMCPServer(ctx, name)
+	if err != nil {
+		if kind == "" && apierrors.IsNotFound(err) {
+			return "", "", "", "", fmt.Errorf("no MCPServer or RemoteMCPServer named '%s' in namespace '%s'", name, client.Namespace())
+		}
+		return "", "", "", "", fmt.Errorf("failed to get RemoteMCPServer: %v", err)
+	}
+	if endpointURL == "" {
+		endpointURL = server.Spec.URL
+	}
+	if endpointURL == "" {
+		return "", "", "", "", fmt.Errorf("RemoteMCPServer '%s' has no url; pass endpoint_url", name)
+	}
+	return "RemoteMCPServer", endpointURL, strings.ToUpper(server.Spec.Protocol), server.ResourceVersion, nil
+}
+
+// toolNameHint suggests the tool name closest to want, or lists the names
+// when none is close.
+func toolNameHint(names []string, want string) string {
+	if closest, ok := closestKey(names, want); ok {
+		return fmt.Sprintf(" Did you mean '%s'?", closest)
+	}
+	if len(names) == 0 {
+		return " The server exposes no tools."
+	}
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	return fmt.Sprintf(" Available tools: %s.", strings.Join(sorted, ", "))
+}
@@ -0,0 +1,308 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/topology"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// registerWhoCallsWhom registers the who_calls_whom tool.
+func (ts *ToolServer) registerWhoCallsWhom() {
+	tool := mcp.NewTool("who_calls_whom",
+		mcp.WithDescription("Show which agents call which other agents over A2A (agents used as tools). With agent_name, shows the agents it calls and the agents that call it. Answered from a watch-maintained index, without rescanning the cluster."),
+		mcp.WithString("agent_name",
+			mcp.Description("Agent to focus on (default: every agent-to-agent call in the namespace)"),
+		),
+		mcp.WithBoolean("transitive",
+			mcp.Description("With agent_name, follow calls through intermediate agents (default: false)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleWhoCallsWhom)
+}
+
+func (ts *ToolServer) handleWhoCallsWhom(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	agentName := args.String("agent_name")
+	transitive := args.Bool("transitive", false)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := ts.checkTopology(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if agentName == "" {
+		output, _ := json.MarshalIndent(ts.topology.Calls(), "", "  ")
+		return mcp.NewToolResultText(string(output)), nil
+	}
+
+	node := topology.Node{Kind: "Agent", Namespace: ts.topology.Namespace(), Name: agentName}
+	if !ts.topology.Exists(node) {
+		return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' not found", agentName)), nil
+	}
+
+	result := map[string][]topology.Edge{
+		"calls":    callEdges(ts.topology.Dependencies(node, transitive)),
+		"calledBy": callEdges(ts.topology.Dependents(node, transitive)),
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// registerReverseDependencies registers the reverse_dependencies tool.
+func (ts *ToolServer) registerReverseDependencies() {
+	tool := mcp.NewTool("reverse_dependencies",
+		mcp.WithDescription("List the agents that depend on a ModelConfig, MCP server, Service, Secret or agent, e.g. to assess the impact of changing or deleting it. Answered from a watch-maintained index, without rescanning the cluster."),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Kind of the resource: Agent, ModelConfig, MCPServer, RemoteMCPServer, Service or Secret"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the resource, optionally qualified as namespace/name"),
+		),
+		mcp.WithBoolean("transitive",
+			mcp.Description("Also include agents that depend on it indirectly, through agents they call (default: true)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleReverseDependencies)
+}
+
+func (ts *ToolServer) handleReverseDependencies(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	kind := args.RequiredEnum("kind", "Agent", "ModelConfig", "MCPServer", "RemoteMCPServer", "Service", "Secret")
+	name := args.RequiredString("name")
+	transitive := args.Bool("transitive", true)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := ts.checkTopology(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	ref, err := types.ParseObjectRef(name, ts.topology.Namespace())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	node := topology.Node{Kind: kind, Namespace: ref.Namespace, Name: ref.Name}
+
+	edges := ts.topology.Dependents(node, transitive)
+	if len(edges) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No agents depend on %s '%s'.", kind, ref.String())), nil
+	}
+
+	seen := map[string]bool{}
+	var agents []string
+	for _, e := range edges {
+		if e.From.Kind == "Agent" && !seen[e.From.Name] {
+			seen[e.From.Name] = true
+			agents = append(agents, e.From.Name)
+		}
+	}
+
+	result := map[string]interface{}{
+		"resource": node,
+		"agents":   agents,
+		"edges":    edges,
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// GraphEdge is an outgoing edge in a dependency adjacency list.
+type GraphEdge struct {
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+	Missing  bool   `json:"missing,omitempty"`
+}
+
+// registerAgentDependencyGraph registers the agent_dependency_graph tool.
+func (ts *ToolServer) registerAgentDependencyGraph() {
+	tool := mcp.NewTool("agent_dependency_graph",
+		mcp.WithDescription("Build the dependency graph of one agent or of every agent in the namespace: Agent -> ModelConfig -> Secret, Agent -> MCPServer/RemoteMCPServer/Service, and Agent -> Agent for A2A calls, including the agents calling the focused agent. Returns a JSON adjacency list, optionally with a Mermaid or DOT rendering, to see what breaks if a resource is deleted. Missing targets are flagged."),
+		mcp.WithString("agent_name",
+			mcp.Description("Agent to focus on (default: the whole namespace)"),
+		),
+		mcp.WithString("diagram",
+			mcp.Description("Also render the graph as 'mermaid' or 'dot' text (default: none)"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleAgentDependencyGraph)
+}
+
+func (ts *ToolServer) handleAgentDependencyGraph(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	agentName := args.String("agent_name")
+	diagram := args.Enum("diagram", "", "", "mermaid", "dot")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := ts.checkTopology(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var edges []topology.Edge
+	if agentName == "" {
+		edges = ts.topology.Edges()
+	} else {
+		node := topology.Node{Kind: "Agent", Namespace: ts.topology.Namespace(), Name: agentName}
+		if !ts.topology.Exists(node) {
+			return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' not found", agentName)), nil
+		}
+		edges = append(ts.topology.Dependencies(node, true), callEdges(ts.topology.Dependents(node, true))...)
+	}
+	if len(edges) == 0 {
+		return mcp.NewToolResultText("No dependencies found."), nil
+	}
+
+	adjacency := dependencyAdjacency(edges, ts.topology.Namespace())
+	result := map[string]interface{}{
+		"namespace": ts.topology.Namespace(),
+		"adjacency": adjacency,
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+
+	text := string(output)
+	switch diagram {
+	case "mermaid":
+		text += "\n\n```mermaid\n" + mermaidGraph(adjacency) + "```"
+	case "dot":
+		text += "\n\n```dot\n" + dotGraph(adjacency) + "```"
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// dependencyAdjacency turns edges into an adjacency list keyed by
+// "Kind/name", qualified with the namespace when it is not namespace.
+// Every node appears as a key, with no edges when nothing depends on it.
+func dependencyAdjacency(edges []topology.Edge, namespace string) map[string][]GraphEdge {
+	key := func(n topology.Node) string {
+		if n.Namespace != namespace {
+			return n.Kind + "/" + n.Namespace + "/" + n.Name
+		}
+		return n.Kind + "/" + n.Name
+	}
+
+	adjacency := map[string][]GraphEdge{}
+	seen := map[topology.Edge]bool{}
+	for _, e := range edges {
+		if seen[e] {
+			continue
+		}
+		seen[e] = true
+		from, to := key(e.From), key(e.To)
+		adjacency[from] = append(adjacency[from], GraphEdge{To: to, Relation: e.Relation, Missing: e.Missing})
+		if _, ok := adjacency[to]; !ok {
+			adjacency[to] = []GraphEdge{}
+		}
+	}
+	return adjacency
+}
+
+// sortedKeys returns the keys of a map, such as the nodes of an adjacency
+// list, in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mermaidGraph renders an adjacency list as a Mermaid flowchart. Missing
+// targets are drawn with a dashed outline.
+func mermaidGraph(adjacency map[string][]GraphEdge) string {
+	keys := sortedKeys(adjacency)
+	ids := make(map[string]string, len(keys))
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for i, k := range keys {
+		ids[k] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[k], k)
+	}
+
+	missing := map[string]bool{}
+	for _, k := range keys {
+		for _, e := range adjacency[k] {
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", ids[k], e.Relation, ids[e.To])
+			if e.Missing {
+				missing[e.To] = true
+			}
+		}
+	}
+	if len(missing) > 0 {
+		b.WriteString("  classDef missing stroke-dasharray: 5 5,stroke:#d33\n")
+		for _, k := range keys {
+			if missing[k] {
+				fmt.Fprintf(&b, "  class %s missing\n", ids[k])
+			}
+		}
+	}
+	return b.String()
+}
+
+// dotGraph renders an adjacency list as a Graphviz digraph. Missing targets
+// are drawn dashed.
+func dotGraph(adjacency map[string][]GraphEdge) string {
+	keys := sortedKeys(adjacency)
+	missing := map[string]bool{}
+	for _, k := range keys {
+		for _, e := range adjacency[k] {
+			if e.Missing {
+				missing[e.To] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n  rankdir=LR;\n")
+	for _, k := range keys {
+		style := ""
+		if missing[k] {
+			style = ", style=dashed, color=red"
+		}
+		fmt.Fprintf(&b, "  %q [shape=box%s];\n", k, style)
+	}
+	for _, k := range keys {
+		for _, e := range adjacency[k] {
+			fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", k, e.To, e.Relation)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// checkTopology reports whether the topology index can answer for the
+// calling session.
+func (ts *ToolServer) checkTopology(ctx context.Context) error {
+	if ts.kube(ctx).Namespace() != ts.topology.Namespace() {
+		return fmt.Errorf("the topology index covers namespace '%s' only", ts.topology.Namespace())
+	}
+	if !ts.topology.Synced() {
+		return fmt.Errorf("the topology index is still loading; retry shortly")
+	}
+	return nil
+}
+
+// callEdges filters edges to agent-to-agent calls.
+func callEdges(edges []topology.Edge) []topology.Edge {
+	calls := []topology.Edge{}
+	for _, e := range edges {
+		if e.Relation == topology.RelationCalls {
+			calls = append(calls, e)
+		}
+	}
+	return calls
+}
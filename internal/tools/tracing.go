@@ -0,0 +1,277 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// OTLP receiver ports of the generated collector.
+const (
+	otlpGRPCPort = 4317
+	otlpHTTPPort = 4318
+)
+
+// tracingAuthEnv is the collector environment variable holding the backend
+// credentials when auth_secret is set.
+const tracingAuthEnv = "OTLP_AUTH_TOKEN"
+
+// registerGenerateTracingConfig registers the generate_tracing_config tool.
+func (ts *ToolServer) registerGenerateTracingConfig() {
+	tool := mcp.NewTool("generate_tracing_config",
+		mcp.WithDescription("Generate the OpenTelemetry manifests needed to export traces from kagent agents and MCP servers to a tracing backend: an OpenTelemetryCollector forwarding OTLP to the backend, an Instrumentation resource with the sampling settings, and the listed agents and MCP servers updated with the OTEL_* environment pointing at the collector. Requires the OpenTelemetry Operator."),
+		mcp.WithString("endpoint",
+			mcp.Required(),
+			mcp.Description("OTLP endpoint of the tracing backend, e.g. 'tempo-distributor.observability:4317' or 'https://otlp.example.com'"),
+		),
+		mcp.WithString("protocol",
+			mcp.Description("OTLP protocol of the backend endpoint: 'grpc' or 'http' (default: 'grpc')"),
+		),
+		mcp.WithNumber("sampling_rate",
+			mcp.Description("Fraction of traces to sample, from 0 to 1 (default: 0.1)"),
+		),
+		mcp.WithBoolean("insecure",
+			mcp.Description("Connect to the backend without TLS (default: false)"),
+		),
+		mcp.WithString("auth_secret",
+			mcp.Description("Secret holding the backend bearer token, as 'secret-name/key' (default: no authentication)"),
+		),
+		mcp.WithString("collector_name",
+			mcp.Description("Name of the OpenTelemetryCollector and Instrumentation (default: 'kagent-otel')"),
+		),
+		mcp.WithString("agents",
+			mcp.Description("Comma-separated list of agents to configure for tracing"),
+		),
+		mcp.WithString("mcp_servers",
+			mcp.Description("Comma-separated list of MCPServers to configure for tracing"),
+		),
+		withOutputFormatOption(),
+		withStripDefaultsOption(),
+	)
+
+	ts.addTool(tool, ts.handleGenerateTracingConfig)
+}
+
+func (ts *ToolServer) handleGenerateTracingConfig(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	endpoint := args.RequiredString("endpoint")
+	protocol := args.Enum("protocol", "grpc", "grpc", "http")
+	samplingRate := args.Float("sampling_rate", 0.1)
+	insecure := args.Bool("insecure", false)
+	authSecret := args.String("auth_secret")
+	name := args.StringDefault("collector_name", "kagent-otel")
+	agentNames := args.StringList("agents")
+	serverNames := args.StringList("mcp_servers")
+	out := outputOptionsFrom(args)
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if samplingRate < 0 || samplingRate > 1 {
+		return mcp.NewToolResultError(fmt.Sprintf("sampling_rate must be between 0 and 1, got %g", samplingRate)), nil
+	}
+	var secretName, secretKey string
+	if authSecret != "" {
+		parts := strings.SplitN(authSecret, "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("auth_secret must be 'secret-name/key', got '%s'", authSecret)), nil
+		}
+		secretName, secretKey = parts[0], parts[1]
+	}
+
+	namespace := ts.kube(ctx).Namespace()
+	rate := strconv.FormatFloat(samplingRate, 'f', -1, 64)
+	// The OpenTelemetry Operator exposes the collector as <name>-collector
+	collectorURL := fmt.Sprintf("http://%s-collector.%s.svc.cluster.local:%d", name, namespace, otlpGRPCPort)
+
+	collector := tracingCollector(name, namespace, endpoint, protocol, insecure, secretName, secretKey)
+	instrumentation := map[string]interface{}{
+		"apiVersion": "opentelemetry.io/v1alpha1",
+		"kind":       "Instrumentation",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"exporter":    map[string]interface{}{"endpoint": collectorURL},
+			"propagators": []interface{}{"tracecontext", "baggage"},
+			"sampler": map[string]interface{}{
+				"type":     "parentbased_traceidratio",
+				"argument": rate,
+			},
+		},
+	}
+
+	var docs []string
+	for _, obj := range []interface{}{collector, instrumentation} {
+		output, _ := yaml.Marshal(obj)
+		docs = append(docs, string(output))
+	}
+
+	for _, agentName := range agentNames {
+		agent, err := ts.kube(ctx).GetAgent(ctx, agentName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get agent: %v", err)), nil
+		}
+		env := tracingEnv(agentName, namespace, collectorURL, rate)
+		switch {
+		case agent.Spec.Declarative != nil:
+			if agent.Spec.Declarative.Deployment == nil {
+				agent.Spec.Declarative.Deployment = &types.DeclarativeDeploymentSpec{}
+			}
+			agent.Spec.Declarative.Deployment.Env = mergeEnv(agent.Spec.Declarative.Deployment.Env, env)
+		case agent.Spec.BYO != nil && agent.Spec.BYO.Deployment != nil:
+			agent.Spec.BYO.Deployment.Env = mergeEnv(agent.Spec.BYO.Deployment.Env, env)
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("Agent '%s' has no deployment to configure", agentName)), nil
+		}
+		agent.APIVersion = "kagent.dev/v1alpha2"
+		agent.Kind = "Agent"
+		output, _ := yaml.Marshal(agent)
+		docs = append(docs, string(output))
+	}
+
+	for _, serverName := range serverNames {
+		server, err := ts.kube(ctx).GetMCPServer(ctx, serverName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get MCP server: %v", err)), nil
+		}
+		if server.Spec.Deployment == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("MCPServer '%s' has no deployment to configure", serverName)), nil
+		}
+		server.Spec.Deployment.Env = mergeEnv(server.Spec.Deployment.Env, tracingEnv(serverName, namespace, collectorURL, rate))
+		server.APIVersion = "kagent.dev/v1alpha1"
+		server.Kind = "MCPServer"
+		output, _ := yaml.Marshal(server)
+		docs = append(docs, string(output))
+	}
+
+	header := fmt.Sprintf(`# OpenTelemetry Tracing Configuration
+# Collector: %s (OTLP gRPC :%d, HTTP :%d) -> %s (%s)
+# Sampling: parent-based, %s of new traces
+# Requires the OpenTelemetry Operator (opentelemetry.io CRDs). Apply the
+# OpenTelemetryCollector and Instrumentation with kubectl; the updated
+# agents and MCP servers can be applied with apply_manifest.`,
+		collectorURL, otlpGRPCPort, otlpHTTPPort, endpoint, protocol, rate)
+	if authSecret != "" {
+		header += fmt.Sprintf("\n# The collector reads the backend token from Secret '%s' (key '%s').", secretName, secretKey)
+	}
+	if len(agentNames) == 0 && len(serverNames) == 0 {
+		header += "\n# Pass agents or mcp_servers to generate their OTEL_* environment."
+	}
+
+	return out.render(header, strings.Join(docs, "---\n"))
+}
+
+// tracingCollector returns an OpenTelemetryCollector that receives OTLP from
+// agents and forwards traces to the backend.
+func tracingCollector(name, namespace, endpoint, protocol string, insecure bool, secretName, secretKey string) map[string]interface{} {
+	exporterName := "otlp"
+	if protocol == "http" {
+		exporterName = "otlphttp"
+	}
+	exporter := map[string]interface{}{
+		"endpoint": endpoint,
+	}
+	if insecure {
+		exporter["tls"] = map[string]interface{}{"insecure": true}
+	}
+
+	spec := map[string]interface{}{
+		"mode": "deployment",
+		"config": map[string]interface{}{
+			"receivers": map[string]interface{}{
+				"otlp": map[string]interface{}{
+					"protocols": map[string]interface{}{
+						"grpc": map[string]interface{}{"endpoint": fmt.Sprintf("0.0.0.0:%d", otlpGRPCPort)},
+						"http": map[string]interface{}{"endpoint": fmt.Sprintf("0.0.0.0:%d", otlpHTTPPort)},
+					},
+				},
+			},
+			"processors": map[string]interface{}{
+				"memory_limiter": map[string]interface{}{
+					"check_interval":         "1s",
+					"limit_percentage":       80,
+					"spike_limit_percentage": 25,
+				},
+				"batch": map[string]interface{}{},
+			},
+			"exporters": map[string]interface{}{
+				exporterName: exporter,
+			},
+			"service": map[string]interface{}{
+				"pipelines": map[string]interface{}{
+					"traces": map[string]interface{}{
+						"receivers":  []interface{}{"otlp"},
+						"processors": []interface{}{"memory_limiter", "batch"},
+						"exporters":  []interface{}{exporterName},
+					},
+				},
+			},
+		},
+	}
+
+	if secretName != "" {
+		exporter["headers"] = map[string]interface{}{
+			"Authorization": "Bearer ${env:" + tracingAuthEnv + "}",
+		}
+		spec["env"] = []interface{}{
+			map[string]interface{}{
+				"name": tracingAuthEnv,
+				"valueFrom": map[string]interface{}{
+					"secretKeyRef": map[string]interface{}{"name": secretName, "key": secretKey},
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "opentelemetry.io/v1beta1",
+		"kind":       "OpenTelemetryCollector",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": spec,
+	}
+}
+
+// tracingEnv returns the OpenTelemetry SDK settings for a workload sending
+// traces to the collector.
+func tracingEnv(service, namespace, collectorURL, rate string) []types.EnvVar {
+	return []types.EnvVar{
+		{Name: "OTEL_TRACING_ENABLED", Value: "true"},
+		{Name: "OTEL_TRACES_EXPORTER", Value: "otlp"},
+		{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: collectorURL},
+		{Name: "OTEL_EXPORTER_OTLP_PROTOCOL", Value: "grpc"},
+		{Name: "OTEL_TRACES_SAMPLER", Value: "parentbased_traceidratio"},
+		{Name: "OTEL_TRACES_SAMPLER_ARG", Value: rate},
+		{Name: "OTEL_SERVICE_NAME", Value: service},
+		{Name: "OTEL_RESOURCE_ATTRIBUTES", Value: "k8s.namespace.name=" + namespace},
+	}
+}
+
+// mergeEnv sets the variables of add in env, replacing existing values of
+// the same name and keeping the order of env.
+func mergeEnv(env, add []types.EnvVar) []types.EnvVar {
+	index := make(map[string]int, len(env))
+	for i, e := range env {
+		index[e.Name] = i
+	}
+	for _, e := range add {
+		if i, ok := index[e.Name]; ok {
+			env[i] = e
+			continue
+		}
+		index[e.Name] = len(env)
+		env = append(env, e)
+	}
+	return env
+}
@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/internal/params"
+	"github.com/kagent-dev/meta-kagent/internal/upgrade"
+)
+
+// registerUpgradeAssistant registers the upgrade_assistant tool.
+func (ts *ToolServer) registerUpgradeAssistant() {
+	tool := mcp.NewTool("upgrade_assistant",
+		mcp.WithDescription("Plan an upgrade of kagent to a target version: find resources using apiVersions that version deprecates or removes, convert them to their replacements, order the migration steps so referenced ModelConfigs and tool servers move before the agents using them, and produce a rollback bundle of the originals. Inspects the namespace, or a manifest bundle from Git. Nothing is applied."),
+		mcp.WithString("target_version",
+			mcp.Required(),
+			mcp.Description("kagent version to upgrade to (e.g., v0.7.0)"),
+		),
+		mcp.WithString("manifest",
+			mcp.Description("YAML bundle to inspect instead of the cluster, e.g. the manifests kept in a GitOps repository"),
+		),
+	)
+
+	ts.addTool(tool, ts.handleUpgradeAssistant)
+}
+
+func (ts *ToolServer) handleUpgradeAssistant(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := params.From(req)
+	targetVersion := args.RequiredString("target_version")
+	manifest := args.String("manifest")
+	if err := args.Err(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	target, err := upgrade.ParseVersion(targetVersion)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var objs []*unstructured.Unstructured
+	var unreadable []string
+	source := "manifest bundle"
+	if manifest != "" {
+		for i, doc := range kubernetes.SplitManifests(manifest) {
+			obj, err := kubernetes.ParseManifest(doc)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Document %d: %v", i+1, err)), nil
+			}
+			objs = append(objs, obj)
+		}
+	} else {
+		source = fmt.Sprintf("namespace '%s'", ts.kube(ctx).Namespace())
+		objs, unreadable, err = ts.deprecatedResources(ctx, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	plan := upgrade.Inspect(target, objs)
+	if len(plan.Findings) == 0 {
+		text := fmt.Sprintf("No resources in the %s use apiVersions deprecated or removed by kagent %s.", source, target)
+		if len(unreadable) > 0 {
+			text += "\nCould not check: " + strings.Join(unreadable, "; ")
+		}
+		return mcp.NewToolResultText(text), nil
+	}
+
+	removed, failed := 0, 0
+	for _, f := range plan.Findings {
+		if f.Severity == upgrade.SeverityRemoved {
+			removed++
+		}
+		if f.Error != "" {
+			failed++
+		}
+	}
+	output, _ := json.MarshalIndent(plan, "", "  ")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `# Upgrade Plan to kagent %s (%s)
+# %d resource(s) affected: %d no longer served by %s, %d could not be converted.
+# Review the notes of each finding, then follow the steps in order.
+`, target, source, len(plan.Findings), removed, target, failed)
+	if len(unreadable) > 0 {
+		fmt.Fprintf(&b, "# Could not check: %s\n", strings.Join(unreadable, "; "))
+	}
+	fmt.Fprintf(&b, "\n%s\n", output)
+
+	if len(plan.Converted) > 0 {
+		b.WriteString("\n# Converted Manifests (in migration order)\n# Use validate_manifest and diff_manifest, then apply_manifest to deploy.\n\n")
+		b.WriteString(joinManifests(plan.Converted))
+	}
+	b.WriteString("\n# Rollback Bundle (the originals, in reverse order)\n# Apply it with the previous kagent CRDs installed, and delete the converted\n# resources whose kind changed.\n\n")
+	b.WriteString(joinManifests(plan.Rollback))
+
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// deprecatedResources lists the resources last written at an apiVersion a
+// rule for target covers. Versions the cluster does not serve are skipped;
+// versions that cannot be read are returned as unreadable.
+func (ts *ToolServer) deprecatedResources(ctx context.Context, target upgrade.Version) ([]*unstructured.Unstructured, []string, error) {
+	var objs []*unstructured.Unstructured
+	var unreadable []string
+	for _, rule := range upgrade.Rules {
+		if !rule.Applies(target) {
+			continue
+		}
+		items, err := ts.kube(ctx).ListResources(ctx, rule.GVR())
+		switch {
+		case apierrors.IsNotFound(err):
+			continue
+		case apierrors.IsForbidden(err):
+			unreadable = append(unreadable, fmt.Sprintf("%s %s (not allowed to list)", rule.Kind, rule.APIVersion))
+			continue
+		case err != nil:
+			return nil, nil, err
+		}
+		for i := range items {
+			if upgrade.AuthoredIn(&items[i], rule.APIVersion) {
+				objs = append(objs, &items[i])
+			}
+		}
+	}
+	return objs, unreadable, nil
+}
+
+// joinManifests renders objects as a multi-document YAML bundle.
+func joinManifests(objs []*unstructured.Unstructured) string {
+	docs := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		data, _ := yaml.Marshal(obj.Object)
+		docs = append(docs, string(data))
+	}
+	return strings.Join(docs, "---\n")
+}
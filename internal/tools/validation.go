@@ -0,0 +1,316 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	ruleset "github.com/kagent-dev/meta-kagent/internal/validation"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// PatchOperation is a single JSON Patch (RFC 6902) operation suggested as a
+// fix for a validation issue.
+type PatchOperation = ruleset.PatchOperation
+
+// kagentAPIVersions maps kagent kinds to the apiVersion the server expects.
+var kagentAPIVersions = map[string]string{
+	"Agent":           kubernetes.AgentGVR.GroupVersion().String(),
+	"ModelConfig":     kubernetes.ModelConfigGVR.GroupVersion().String(),
+	"MCPServer":       kubernetes.MCPServerGVR.GroupVersion().String(),
+	"RemoteMCPServer": kubernetes.RemoteMCPServerGVR.GroupVersion().String(),
+}
+
+// resourceIssue is a validation issue found in one document of a bundle.
+type resourceIssue struct {
+	Resource string
+	Issue    ValidationIssue
+}
+
+// validateBundle validates every document of a bundle. References between
+// the bundle's resources resolve even though they do not exist in the
+// cluster yet, so an Agent can be validated together with its ModelConfig.
+func (ts *ToolServer) validateBundle(ctx context.Context, docs []string, strict, checkToolNames bool) ([]resourceIssue, error) {
+	objs := make([]*unstructured.Unstructured, len(docs))
+	for i, doc := range docs {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(doc), &obj.Object); err != nil {
+			return nil, fmt.Errorf("failed to parse document %d: %w", i+1, err)
+		}
+		objs[i] = obj
+	}
+	ctx = withBundle(ctx, objs, ts.kube(ctx).Namespace())
+
+	var issues []resourceIssue
+	for i, obj := range objs {
+		resource := bundleResource(objs, i)
+		for _, issue := range ts.validateObject(ctx, obj, strict, checkToolNames) {
+			issues = append(issues, resourceIssue{Resource: resource, Issue: issue})
+		}
+	}
+	return issues, nil
+}
+
+// serverDryRunIssues sends every document of a bundle to the API server as
+// a dry-run create or update, so the CRD schema and admission webhooks
+// check it, and turns what they reject into issues. Documents whose
+// namespace the bundle itself creates cannot be checked before it exists.
+func (ts *ToolServer) serverDryRunIssues(ctx context.Context, docs []string) []resourceIssue {
+	client := ts.kube(ctx)
+	objs := make([]*unstructured.Unstructured, len(docs))
+	for i, doc := range docs {
+		objs[i] = &unstructured.Unstructured{}
+		// validateBundle has already reported documents that do not parse
+		_ = yaml.Unmarshal([]byte(doc), &objs[i].Object)
+	}
+	ctx = withBundle(ctx, objs, client.Namespace())
+
+	var issues []resourceIssue
+	if err := ts.dryRunUnavailable(); err != nil {
+		for i := range objs {
+			issues = append(issues, resourceIssue{Resource: bundleResource(objs, i), Issue: ValidationIssue{
+				Severity: "warning",
+				Message:  fmt.Sprintf("Not checked by the API server: %v", err),
+			}})
+		}
+		return issues
+	}
+	for i, obj := range objs {
+		// The local checks report documents that cannot be sent at all
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+			continue
+		}
+
+		// The server checks what apply_manifest would send
+		doc := docs[i]
+		if resolveSecretPlaceholders(obj) {
+			if resolved, err := yaml.Marshal(obj.Object); err == nil {
+				doc = string(resolved)
+			}
+		}
+
+		_, err := client.Apply(ctx, doc, true, kubernetes.Preconditions{})
+		switch {
+		case err == nil:
+			continue
+		case apierrors.IsNotFound(err):
+			// A missing namespace is reported by the local checks
+			if obj.GetNamespace() != "" && bundleDefines(ctx, "Namespace", obj.GetNamespace(), "") {
+				issues = append(issues, resourceIssue{Resource: bundleResource(objs, i), Issue: ValidationIssue{
+					Severity: "warning",
+					Field:    "metadata.namespace",
+					Message:  fmt.Sprintf("Not checked by the API server: namespace '%s' is created by this bundle", obj.GetNamespace()),
+				}})
+			}
+			continue
+		}
+		for _, issue := range dryRunIssues(err) {
+			issues = append(issues, resourceIssue{Resource: bundleResource(objs, i), Issue: issue})
+		}
+	}
+	return issues
+}
+
+// dryRunIssues turns the error of a server-side dry run into issues: one
+// per field the API server names, or one for the whole resource.
+func dryRunIssues(err error) []ValidationIssue {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		// The kind could not be mapped to a resource: its CRD is missing
+		return []ValidationIssue{{
+			Severity: "error",
+			Field:    "kind",
+			Message:  fmt.Sprintf("Server-side dry run failed: %v", err),
+		}}
+	}
+
+	s := status.Status()
+	if apierrors.IsForbidden(err) && !strings.Contains(s.Message, "admission webhook") {
+		return []ValidationIssue{{
+			Severity: "warning",
+			Field:    "kind",
+			Message:  fmt.Sprintf("Not checked by the API server: the server may not create or update this resource (%s)", s.Message),
+		}}
+	}
+
+	var issues []ValidationIssue
+	if s.Details != nil {
+		for _, cause := range s.Details.Causes {
+			if cause.Field == "" {
+				continue
+			}
+			issues = append(issues, ValidationIssue{
+				Severity: "error",
+				Field:    cause.Field,
+				Message:  fmt.Sprintf("Rejected by the API server: %s", cause.Message),
+			})
+		}
+	}
+	if len(issues) == 0 {
+		issues = append(issues, ValidationIssue{
+			Severity: "error",
+			Field:    "kind",
+			Message:  fmt.Sprintf("Rejected by the API server: %s", s.Message),
+		})
+	}
+	return issues
+}
+
+// bundleResource names the i-th object of a bundle in reports, numbering
+// it when the bundle has more than one.
+func bundleResource(objs []*unstructured.Unstructured, i int) string {
+	resource := fmt.Sprintf("%s/%s", objs[i].GetKind(), objs[i].GetName())
+	if len(objs) > 1 {
+		resource = fmt.Sprintf("[%d] %s", i+1, resource)
+	}
+	return resource
+}
+
+type bundleKey struct{}
+
+// bundle is the set of resources a bundle defines, keyed by kind, namespace
+// and name.
+type bundle struct {
+	defaultNamespace string
+	defined          map[string]bool
+}
+
+// withBundle records the resources a bundle defines. Resources without a
+// namespace are in defaultNamespace.
+func withBundle(ctx context.Context, objs []*unstructured.Unstructured, defaultNamespace string) context.Context {
+	b := bundle{defaultNamespace: defaultNamespace, defined: map[string]bool{}}
+	for _, obj := range objs {
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+		if obj.GetKind() == "Namespace" {
+			namespace = ""
+		}
+		b.defined[obj.GetKind()+"/"+namespace+"/"+obj.GetName()] = true
+	}
+	return context.WithValue(ctx, bundleKey{}, b)
+}
+
+// bundleDefines reports whether the bundle being validated defines the
+// resource a reference points to.
+func bundleDefines(ctx context.Context, kind, ref, fromNamespace string) bool {
+	b, ok := ctx.Value(bundleKey{}).(bundle)
+	if !ok {
+		return false
+	}
+	if kind == "Namespace" {
+		return b.defined["Namespace//"+ref]
+	}
+	if fromNamespace == "" {
+		fromNamespace = b.defaultNamespace
+	}
+	parsed, err := types.ParseObjectRef(ref, fromNamespace)
+	return err == nil && b.defined[kind+"/"+parsed.Namespace+"/"+parsed.Name]
+}
+
+// groupedIssue is a validation issue reported once for every resource it
+// occurs in.
+type groupedIssue struct {
+	ValidationIssue
+	Count     int      `json:"count"`
+	Resources []string `json:"resources"`
+}
+
+// dedupeIssues collapses issues with the same severity, field, and message
+// into a single entry, preserving first-seen order. The fix of the first
+// occurrence is kept; fixes are paths within each resource, so it applies to
+// every listed resource.
+func dedupeIssues(issues []resourceIssue) []groupedIssue {
+	var grouped []groupedIssue
+	index := make(map[string]int)
+
+	for _, ri := range issues {
+		key := ri.Issue.Severity + "\x00" + ri.Issue.Field + "\x00" + ri.Issue.Message
+		if i, ok := index[key]; ok {
+			grouped[i].Count++
+			grouped[i].Resources = append(grouped[i].Resources, ri.Resource)
+			continue
+		}
+		index[key] = len(grouped)
+		grouped = append(grouped, groupedIssue{
+			ValidationIssue: ri.Issue,
+			Count:           1,
+			Resources:       []string{ri.Resource},
+		})
+	}
+
+	return grouped
+}
+
+// formatValidationReport renders grouped issues as a readable summary
+// followed by the same issues as JSON, including suggested patches.
+func formatValidationReport(issues []groupedIssue) string {
+	var result strings.Builder
+	result.WriteString("Validation Results:\n\n")
+
+	hasErrors := false
+	for _, issue := range issues {
+		prefix := "⚠️  WARNING"
+		if issue.Severity == "error" {
+			prefix = "❌ ERROR"
+			hasErrors = true
+		}
+
+		where := issue.Resources[0]
+		if issue.Count > 1 {
+			where = fmt.Sprintf("x%d: %s", issue.Count, strings.Join(issue.Resources, ", "))
+		}
+		result.WriteString(fmt.Sprintf("%s [%s] (%s): %s\n", prefix, issue.Field, where, issue.Message))
+		if issue.Rule != "" {
+			result.WriteString(fmt.Sprintf("   Rule: %s\n", issue.Rule))
+		}
+
+		if len(issue.Fix) > 0 {
+			fix, _ := json.Marshal(issue.Fix)
+			result.WriteString(fmt.Sprintf("   Suggested fix (JSON patch): %s\n", fix))
+		}
+	}
+
+	result.WriteString("\n")
+	if hasErrors {
+		result.WriteString("❌ Manifest has errors and should not be applied until they are resolved.")
+	} else {
+		result.WriteString("⚠️  Manifest has warnings but can be applied. Consider addressing warnings for best practices.")
+	}
+
+	output, _ := json.MarshalIndent(issues, "", "  ")
+	result.WriteString("\n\nIssues (JSON):\n")
+	result.WriteString(string(output))
+
+	return result.String()
+}
+
+// validationComment summarizes validation issues as manifest comments.
+func validationComment(issues []groupedIssue) string {
+	if len(issues) == 0 {
+		return "# ✓ Validation passed."
+	}
+
+	errors := 0
+	var b strings.Builder
+	for _, issue := range issues {
+		prefix := "⚠️  WARNING"
+		if issue.Severity == "error" {
+			prefix = "❌ ERROR"
+			errors++
+		}
+		fmt.Fprintf(&b, "\n#   %s [%s] (%s): %s", prefix, issue.Field, strings.Join(issue.Resources, ", "), issue.Message)
+	}
+	if errors > 0 {
+		return fmt.Sprintf("# ❌ Validation found %d error(s); resolve them before applying:", errors) + b.String()
+	}
+	return "# ⚠️  Validation found warnings only; the manifest can be applied:" + b.String()
+}
@@ -0,0 +1,347 @@
+// Package topology maintains the dependency graph between agents, model
+// configs, MCP servers, Services and secrets incrementally from watch events, so that
+// who-calls-whom and reverse-dependency queries do not rescan the cluster.
+package topology
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"github.com/kagent-dev/meta-kagent/internal/kubernetes"
+	"github.com/kagent-dev/meta-kagent/pkg/types"
+)
+
+// Edge relations.
+const (
+	RelationModel  = "uses-model"
+	RelationTool   = "uses-tool"
+	RelationCalls  = "calls"
+	RelationSecret = "uses-secret"
+)
+
+// resyncPeriod is how often the informers replay their full state.
+const resyncPeriod = 10 * time.Minute
+
+// watchedKinds are the kinds whose existence the index tracks. Only Agents
+// and ModelConfigs have outgoing edges; Services are tracked so agent tools
+// referencing a missing one are flagged.
+var watchedKinds = []struct {
+	Kind string
+	GVR  schema.GroupVersionResource
+}{
+	{"Agent", kubernetes.AgentGVR},
+	{"ModelConfig", kubernetes.ModelConfigGVR},
+	{"MCPServer", kubernetes.MCPServerGVR},
+	{"RemoteMCPServer", kubernetes.RemoteMCPServerGVR},
+	{"Service", kubernetes.ServiceGVR},
+}
+
+// Node is a resource in the graph.
+type Node struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func (n Node) String() string {
+	return n.Kind + "/" + n.Namespace + "/" + n.Name
+}
+
+// Edge is a dependency of one resource on another.
+type Edge struct {
+	From     Node   `json:"from"`
+	To       Node   `json:"to"`
+	Relation string `json:"relation"`
+	// Missing is set when the target is a watched kind that does not exist.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// Index is the incrementally maintained graph. It is safe for concurrent use.
+type Index struct {
+	namespace string
+
+	mu      sync.RWMutex
+	present map[Node]bool
+	out     map[Node][]Edge
+	in      map[Node]map[Edge]bool
+
+	synced atomic.Bool
+}
+
+// NewIndex creates an empty index for namespace.
+func NewIndex(namespace string) *Index {
+	return &Index{
+		namespace: namespace,
+		present:   make(map[Node]bool),
+		out:       make(map[Node][]Edge),
+		in:        make(map[Node]map[Edge]bool),
+	}
+}
+
+// Namespace returns the namespace the index watches.
+func (x *Index) Namespace() string {
+	return x.namespace
+}
+
+// Synced reports whether the initial list of every watched kind has been
+// indexed.
+func (x *Index) Synced() bool {
+	return x.synced.Load()
+}
+
+// Run watches the namespace and keeps the index up to date until ctx is
+// cancelled.
+func (x *Index) Run(ctx context.Context, client *kubernetes.Client) error {
+	factory := client.NewInformerFactory(resyncPeriod)
+
+	var syncs []toolscache.InformerSynced
+	for _, w := range watchedKinds {
+		kind := w.Kind
+		informer := factory.ForResource(w.GVR).Informer()
+		_, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { x.upsert(kind, obj) },
+			UpdateFunc: func(_, obj interface{}) { x.upsert(kind, obj) },
+			DeleteFunc: func(obj interface{}) { x.remove(kind, obj) },
+		})
+		if err != nil {
+			return fmt.Errorf("failed to watch %s: %w", w.GVR.Resource, err)
+		}
+		syncs = append(syncs, informer.HasSynced)
+	}
+
+	factory.Start(ctx.Done())
+	if !toolscache.WaitForCacheSync(ctx.Done(), syncs...) {
+		return ctx.Err()
+	}
+	x.synced.Store(true)
+
+	<-ctx.Done()
+	factory.Shutdown()
+	return nil
+}
+
+func (x *Index) upsert(kind string, obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	node := Node{Kind: kind, Namespace: u.GetNamespace(), Name: u.GetName()}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.present[node] = true
+	switch kind {
+	case "Agent":
+		x.setEdges(node, agentEdges(node, u))
+	case "ModelConfig":
+		x.setEdges(node, modelConfigEdges(node, u))
+	}
+}
+
+func (x *Index) remove(kind string, obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	node := Node{Kind: kind, Namespace: u.GetNamespace(), Name: u.GetName()}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	delete(x.present, node)
+	x.setEdges(node, nil)
+}
+
+// setEdges replaces the outgoing edges of from. The caller holds mu.
+func (x *Index) setEdges(from Node, edges []Edge) {
+	for _, e := range x.out[from] {
+		delete(x.in[e.To], e)
+		if len(x.in[e.To]) == 0 {
+			delete(x.in, e.To)
+		}
+	}
+
+	if len(edges) == 0 {
+		delete(x.out, from)
+		return
+	}
+	x.out[from] = edges
+	for _, e := range edges {
+		if x.in[e.To] == nil {
+			x.in[e.To] = make(map[Edge]bool)
+		}
+		x.in[e.To][e] = true
+	}
+}
+
+// agentEdges returns the dependencies declared by an Agent.
+func agentEdges(from Node, obj *unstructured.Unstructured) []Edge {
+	var edges []Edge
+	add := func(kind, ref, relation string) {
+		parsed, err := types.ParseObjectRef(ref, from.Namespace)
+		if err != nil {
+			return
+		}
+		edges = append(edges, Edge{
+			From:     from,
+			To:       Node{Kind: kind, Namespace: parsed.Namespace, Name: parsed.Name},
+			Relation: relation,
+		})
+	}
+
+	if ref, _, _ := unstructured.NestedString(obj.Object, "spec", "declarative", "modelConfig"); ref != "" {
+		add("ModelConfig", ref, RelationModel)
+	}
+
+	tools, _, _ := unstructured.NestedSlice(obj.Object, "spec", "declarative", "tools")
+	for _, t := range tools {
+		tool, _ := t.(map[string]interface{})
+		if name, _, _ := unstructured.NestedString(tool, "mcpServer", "name"); name != "" {
+			kind, _, _ := unstructured.NestedString(tool, "mcpServer", "kind")
+			if kind == "" {
+				kind = "MCPServer"
+			}
+			add(kind, name, RelationTool)
+		}
+		if name, _, _ := unstructured.NestedString(tool, "agent", "name"); name != "" {
+			add("Agent", name, RelationCalls)
+		}
+	}
+	return edges
+}
+
+// modelConfigEdges returns the API key Secret a ModelConfig reads.
+func modelConfigEdges(from Node, obj *unstructured.Unstructured) []Edge {
+	name, _, _ := unstructured.NestedString(obj.Object, "spec", "apiKeySecret")
+	if name == "" {
+		return nil
+	}
+	return []Edge{{
+		From:     from,
+		To:       Node{Kind: "Secret", Namespace: from.Namespace, Name: name},
+		Relation: RelationSecret,
+	}}
+}
+
+// Dependencies returns the edges leaving node, following them through
+// dependent agents when transitive is set.
+func (x *Index) Dependencies(node Node, transitive bool) []Edge {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return x.walk(node, transitive, func(n Node) []Edge { return x.out[n] }, func(e Edge) Node { return e.To })
+}
+
+// Dependents returns the edges arriving at node, i.e. the resources that
+// depend on it, following them back through agents when transitive is set.
+func (x *Index) Dependents(node Node, transitive bool) []Edge {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return x.walk(node, transitive, func(n Node) []Edge {
+		edges := make([]Edge, 0, len(x.in[n]))
+		for e := range x.in[n] {
+			edges = append(edges, e)
+		}
+		return edges
+	}, func(e Edge) Node { return e.From })
+}
+
+// Calls returns every agent-to-agent edge.
+func (x *Index) Calls() []Edge {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var edges []Edge
+	for _, out := range x.out {
+		for _, e := range out {
+			if e.Relation == RelationCalls {
+				edges = append(edges, x.annotate(e))
+			}
+		}
+	}
+	sortEdges(edges)
+	return edges
+}
+
+// Edges returns every edge in the graph.
+func (x *Index) Edges() []Edge {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var edges []Edge
+	for _, out := range x.out {
+		for _, e := range out {
+			edges = append(edges, x.annotate(e))
+		}
+	}
+	sortEdges(edges)
+	return edges
+}
+
+// Exists reports whether node is a known resource.
+func (x *Index) Exists(node Node) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.present[node]
+}
+
+// walk collects edges breadth-first from start. The caller holds mu.
+func (x *Index) walk(start Node, transitive bool, next func(Node) []Edge, follow func(Edge) Node) []Edge {
+	var result []Edge
+	visited := map[Node]bool{start: true}
+	queue := []Node{start}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range next(n) {
+			result = append(result, x.annotate(e))
+			target := follow(e)
+			if transitive && !visited[target] {
+				visited[target] = true
+				queue = append(queue, target)
+			}
+		}
+	}
+
+	sortEdges(result)
+	return result
+}
+
+// annotate marks edges to watched kinds that do not exist. Targets in other
+// namespaces or of unwatched kinds are not judged. The caller holds mu.
+func (x *Index) annotate(e Edge) Edge {
+	if e.To.Namespace != x.namespace {
+		return e
+	}
+	for _, w := range watchedKinds {
+		if w.Kind == e.To.Kind {
+			e.Missing = !x.present[e.To]
+			break
+		}
+	}
+	return e
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if a, b := edges[i].From.String(), edges[j].From.String(); a != b {
+			return a < b
+		}
+		if a, b := edges[i].To.String(), edges[j].To.String(); a != b {
+			return a < b
+		}
+		return edges[i].Relation < edges[j].Relation
+	})
+}
@@ -0,0 +1,226 @@
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Releases the rules refer to.
+var (
+	v0_6 = Version{Major: 0, Minor: 6}
+	v0_7 = Version{Major: 0, Minor: 7}
+)
+
+// Rules are the known deprecations, oldest first.
+var Rules = []Rule{
+	{
+		ID:           "modelconfig-v1alpha1",
+		Kind:         "ModelConfig",
+		APIVersion:   "kagent.dev/v1alpha1",
+		Resource:     "modelconfigs",
+		DeprecatedIn: v0_6,
+		RemovedIn:    v0_7,
+		Description:  "ModelConfig moved to kagent.dev/v1alpha2; apiKeySecretRef is renamed to apiKeySecret",
+		Phase:        1,
+		Convert:      convertModelConfig,
+	},
+	{
+		ID:           "toolserver",
+		Kind:         "ToolServer",
+		APIVersion:   "kagent.dev/v1alpha1",
+		Resource:     "toolservers",
+		DeprecatedIn: v0_6,
+		RemovedIn:    v0_7,
+		Description:  "ToolServer is replaced by RemoteMCPServer (kagent.dev/v1alpha2) for HTTP servers and MCPServer (kagent.dev/v1alpha1) for stdio servers",
+		Phase:        2,
+		Convert:      convertToolServer,
+	},
+	{
+		ID:           "agent-v1alpha1",
+		Kind:         "Agent",
+		APIVersion:   "kagent.dev/v1alpha1",
+		Resource:     "agents",
+		DeprecatedIn: v0_6,
+		RemovedIn:    v0_7,
+		Description:  "Agent moved to kagent.dev/v1alpha2; the prompt, model and tools move under spec.declarative",
+		Phase:        3,
+		Convert:      convertAgent,
+	},
+}
+
+// convertModelConfig converts a v1alpha1 ModelConfig to v1alpha2.
+func convertModelConfig(obj *unstructured.Unstructured) (*unstructured.Unstructured, []string, error) {
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	if spec == nil {
+		return nil, nil, fmt.Errorf("ModelConfig has no spec")
+	}
+	if ref, ok := spec["apiKeySecretRef"]; ok {
+		if _, exists := spec["apiKeySecret"]; !exists {
+			spec["apiKeySecret"] = ref
+		}
+		delete(spec, "apiKeySecretRef")
+	}
+
+	converted := obj.DeepCopy()
+	converted.SetAPIVersion("kagent.dev/v1alpha2")
+	converted.Object["spec"] = spec
+	return converted, nil, nil
+}
+
+// convertToolServer converts a ToolServer to a RemoteMCPServer, or to an
+// MCPServer for stdio servers.
+func convertToolServer(obj *unstructured.Unstructured) (*unstructured.Unstructured, []string, error) {
+	description, _, _ := unstructured.NestedString(obj.Object, "spec", "description")
+	config, _, _ := unstructured.NestedMap(obj.Object, "spec", "config")
+
+	converted := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	converted.SetName(obj.GetName())
+	converted.SetNamespace(obj.GetNamespace())
+	converted.SetLabels(obj.GetLabels())
+	converted.SetAnnotations(obj.GetAnnotations())
+
+	spec := map[string]interface{}{}
+	if description != "" {
+		spec["description"] = description
+	}
+
+	var notes []string
+	switch {
+	case config["streamableHttp"] != nil, config["sse"] != nil:
+		protocol, key := "STREAMABLE_HTTP", "streamableHttp"
+		if config["sse"] != nil {
+			protocol, key = "SSE", "sse"
+		}
+		remote, _ := config[key].(map[string]interface{})
+		url, _ := remote["url"].(string)
+		if url == "" {
+			return nil, nil, fmt.Errorf("spec.config.%s.url is empty", key)
+		}
+		spec["url"] = url
+		spec["protocol"] = protocol
+		for _, field := range []string{"headersFrom", "timeout", "sseReadTimeout", "terminateOnClose"} {
+			if v, ok := remote[field]; ok {
+				spec[field] = v
+			}
+		}
+		converted.SetAPIVersion("kagent.dev/v1alpha2")
+		converted.SetKind("RemoteMCPServer")
+
+	case config["stdio"] != nil:
+		stdio, _ := config["stdio"].(map[string]interface{})
+		deployment := map[string]interface{}{}
+		if command, _ := stdio["command"].(string); command != "" {
+			deployment["cmd"] = command
+		}
+		if args, ok := stdio["args"]; ok {
+			deployment["args"] = args
+		}
+		if env, ok := stdio["env"].(map[string]interface{}); ok && len(env) > 0 {
+			deployment["env"] = envList(env)
+		}
+		spec["deployment"] = deployment
+		spec["transportType"] = "stdio"
+		spec["stdioTransport"] = map[string]interface{}{}
+		converted.SetAPIVersion("kagent.dev/v1alpha1")
+		converted.SetKind("MCPServer")
+		notes = append(notes, "stdio ToolServers ran the command in the controller; set spec.deployment.image to a container image that provides it")
+
+	default:
+		return nil, nil, fmt.Errorf("spec.config sets none of streamableHttp, sse or stdio")
+	}
+
+	converted.Object["spec"] = spec
+	return converted, notes, nil
+}
+
+// envList converts an env map to a list of name/value pairs, sorted by name.
+func envList(env map[string]interface{}) []interface{} {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		list = append(list, map[string]interface{}{"name": name, "value": fmt.Sprint(env[name])})
+	}
+	return list
+}
+
+// convertAgent converts a v1alpha1 Agent to a v1alpha2 declarative agent.
+func convertAgent(obj *unstructured.Unstructured) (*unstructured.Unstructured, []string, error) {
+	old, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	if old == nil {
+		return nil, nil, fmt.Errorf("Agent has no spec")
+	}
+
+	spec := map[string]interface{}{"type": "Declarative"}
+	declarative := map[string]interface{}{}
+	var notes []string
+	for key, value := range old {
+		switch key {
+		case "description":
+			spec["description"] = value
+		case "systemMessage", "modelConfig", "a2aConfig":
+			declarative[key] = value
+		case "tools":
+			tools, toolNotes := convertAgentTools(value)
+			if len(tools) > 0 {
+				declarative["tools"] = tools
+			}
+			notes = append(notes, toolNotes...)
+		default:
+			notes = append(notes, fmt.Sprintf("spec.%s has no v1alpha2 equivalent and was dropped", key))
+		}
+	}
+	spec["declarative"] = declarative
+	sort.Strings(notes)
+
+	converted := obj.DeepCopy()
+	converted.SetAPIVersion("kagent.dev/v1alpha2")
+	converted.Object["spec"] = spec
+	return converted, notes, nil
+}
+
+// convertAgentTools converts v1alpha1 tool references, which point at
+// ToolServers by toolServer and at agents by ref.
+func convertAgentTools(value interface{}) ([]interface{}, []string) {
+	list, _ := value.([]interface{})
+	var tools []interface{}
+	var notes []string
+	for i, t := range list {
+		tool, _ := t.(map[string]interface{})
+		switch {
+		case tool["mcpServer"] != nil:
+			ref, _ := tool["mcpServer"].(map[string]interface{})
+			name, _ := ref["toolServer"].(string)
+			if name == "" {
+				name, _ = ref["name"].(string)
+			}
+			converted := map[string]interface{}{
+				"name":     name,
+				"kind":     "RemoteMCPServer",
+				"apiGroup": "kagent.dev",
+			}
+			if names, ok := ref["toolNames"]; ok {
+				converted["toolNames"] = names
+			}
+			tools = append(tools, map[string]interface{}{"type": "McpServer", "mcpServer": converted})
+			notes = append(notes, fmt.Sprintf("spec.tools[%d] now references RemoteMCPServer '%s'; change the kind to MCPServer if its ToolServer used stdio", i, name))
+		case tool["agent"] != nil:
+			ref, _ := tool["agent"].(map[string]interface{})
+			name, _ := ref["ref"].(string)
+			if name == "" {
+				name, _ = ref["name"].(string)
+			}
+			tools = append(tools, map[string]interface{}{"type": "Agent", "agent": map[string]interface{}{"name": name}})
+		default:
+			kind, _ := tool["type"].(string)
+			notes = append(notes, fmt.Sprintf("spec.tools[%d] of type '%s' has no v1alpha2 equivalent and was dropped; serve it from an MCP server instead", i, kind))
+		}
+	}
+	return tools, notes
+}
@@ -0,0 +1,278 @@
+// Package upgrade plans the migration of kagent resources to a newer kagent
+// release: it finds resources using apiVersions the release deprecates or
+// removes, converts them to their replacements, orders the migration steps
+// so that referenced resources move before the resources referencing them,
+// and keeps the originals as a rollback bundle.
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Finding severities.
+const (
+	// SeverityRemoved means the target release no longer serves the resource.
+	SeverityRemoved = "removed"
+	// SeverityDeprecated means the target release still serves the resource
+	// but a later one will not.
+	SeverityDeprecated = "deprecated"
+)
+
+// Version is a kagent release version.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// ParseVersion parses versions such as v0.6.0, 0.6 or v0.7.1-rc.1. Pre-release
+// and build suffixes are ignored.
+func ParseVersion(s string) (Version, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(trimmed, "-+"); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	parts := strings.Split(trimmed, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q: expected MAJOR.MINOR[.PATCH]", s)
+	}
+
+	var nums [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q: %q is not a number", s, p)
+		}
+		nums[i] = n
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+// Less reports whether v is an earlier release than o.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Rule is a deprecation of one kind at one apiVersion.
+type Rule struct {
+	ID           string
+	Kind         string
+	APIVersion   string
+	Resource     string
+	DeprecatedIn Version
+	RemovedIn    Version
+	Description  string
+	// Phase orders the migration: resources in lower phases are referenced
+	// by resources in higher ones and are migrated first.
+	Phase int
+	// Convert returns the replacement of obj and notes on anything that
+	// could not be carried over.
+	Convert func(obj *unstructured.Unstructured) (*unstructured.Unstructured, []string, error)
+}
+
+// GVR returns the resource the rule's deprecated objects are served as.
+func (r Rule) GVR() schema.GroupVersionResource {
+	gv, _ := schema.ParseGroupVersion(r.APIVersion)
+	return gv.WithResource(r.Resource)
+}
+
+// Applies reports whether the rule affects an upgrade to target.
+func (r Rule) Applies(target Version) bool {
+	return !target.Less(r.DeprecatedIn)
+}
+
+// Severity returns how an upgrade to target is affected by the rule.
+func (r Rule) Severity(target Version) string {
+	if !target.Less(r.RemovedIn) {
+		return SeverityRemoved
+	}
+	return SeverityDeprecated
+}
+
+// Finding is a resource affected by a rule.
+type Finding struct {
+	Rule        string   `json:"rule"`
+	Severity    string   `json:"severity"`
+	Kind        string   `json:"kind"`
+	APIVersion  string   `json:"apiVersion"`
+	Namespace   string   `json:"namespace,omitempty"`
+	Name        string   `json:"name"`
+	ConvertsTo  string   `json:"convertsTo,omitempty"`
+	Description string   `json:"description"`
+	Notes       []string `json:"notes,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// Step is one stage of the migration.
+type Step struct {
+	Order       int      `json:"order"`
+	Description string   `json:"description"`
+	Resources   []string `json:"resources,omitempty"`
+}
+
+// Plan is the migration of a set of resources to a target release.
+type Plan struct {
+	Target   string    `json:"target"`
+	Findings []Finding `json:"findings"`
+	Steps    []Step    `json:"steps"`
+	// Converted holds the replacement manifests in migration order.
+	Converted []*unstructured.Unstructured `json:"-"`
+	// Rollback holds the original manifests in reverse migration order.
+	Rollback []*unstructured.Unstructured `json:"-"`
+}
+
+// RuleFor returns the rule affecting obj on an upgrade to target.
+func RuleFor(obj *unstructured.Unstructured, target Version) (Rule, bool) {
+	for _, r := range Rules {
+		if r.Kind == obj.GetKind() && r.APIVersion == obj.GetAPIVersion() && r.Applies(target) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Inspect plans the migration of objs to target. Objects no rule affects
+// are ignored.
+func Inspect(target Version, objs []*unstructured.Unstructured) *Plan {
+	type affected struct {
+		rule      Rule
+		original  *unstructured.Unstructured
+		converted *unstructured.Unstructured
+		finding   Finding
+	}
+
+	var items []affected
+	for _, obj := range objs {
+		rule, ok := RuleFor(obj, target)
+		if !ok {
+			continue
+		}
+		original := Clean(obj)
+		finding := Finding{
+			Rule:        rule.ID,
+			Severity:    rule.Severity(target),
+			Kind:        obj.GetKind(),
+			APIVersion:  obj.GetAPIVersion(),
+			Namespace:   obj.GetNamespace(),
+			Name:        obj.GetName(),
+			Description: rule.Description,
+		}
+		converted, notes, err := rule.Convert(original.DeepCopy())
+		if err != nil {
+			finding.Error = err.Error()
+		} else {
+			finding.ConvertsTo = converted.GetAPIVersion() + "/" + converted.GetKind()
+			finding.Notes = notes
+		}
+		items = append(items, affected{rule: rule, original: original, converted: converted, finding: finding})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].rule.Phase != items[j].rule.Phase {
+			return items[i].rule.Phase < items[j].rule.Phase
+		}
+		return resourceName(items[i].original) < resourceName(items[j].original)
+	})
+
+	plan := &Plan{Target: target.String()}
+	phases := map[int][]string{}
+	var order []int
+	var replaced []string
+	for _, item := range items {
+		plan.Findings = append(plan.Findings, item.finding)
+		plan.Rollback = append([]*unstructured.Unstructured{item.original}, plan.Rollback...)
+		if item.converted == nil {
+			continue
+		}
+		plan.Converted = append(plan.Converted, item.converted)
+		if _, ok := phases[item.rule.Phase]; !ok {
+			order = append(order, item.rule.Phase)
+		}
+		phases[item.rule.Phase] = append(phases[item.rule.Phase], resourceName(item.converted))
+		if item.converted.GetKind() != item.original.GetKind() {
+			replaced = append(replaced, resourceName(item.original))
+		}
+	}
+	if len(plan.Findings) == 0 {
+		return plan
+	}
+
+	add := func(description string, resources []string) {
+		plan.Steps = append(plan.Steps, Step{Order: len(plan.Steps) + 1, Description: description, Resources: resources})
+	}
+	add("Save the rollback bundle, which holds every affected resource as it is now", nil)
+	add(fmt.Sprintf("Upgrade the kagent CRDs to %s, so that the new apiVersions are served before anything is converted", target), nil)
+	for _, phase := range order {
+		add(phaseDescriptions[phase], phases[phase])
+	}
+	add("Check the converted resources with readiness_gate_report and list_agents before going further", nil)
+	if len(replaced) > 0 {
+		add("Delete the resources that were replaced by a different kind; their replacements are already applied", replaced)
+	}
+	add(fmt.Sprintf("Upgrade the kagent controller to %s", target), nil)
+	return plan
+}
+
+// phaseDescriptions describes each migration phase.
+var phaseDescriptions = map[int]string{
+	1: "Apply the converted ModelConfigs, which agents reference",
+	2: "Apply the converted tool servers, which agents reference",
+	3: "Apply the converted Agents",
+}
+
+// Clean returns a copy of obj without server-managed fields, ready to be
+// applied again.
+func Clean(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	clean := obj.DeepCopy()
+	delete(clean.Object, "status")
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(clean.Object, "metadata", field)
+	}
+	annotations := clean.GetAnnotations()
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	clean.SetAnnotations(annotations)
+	return clean
+}
+
+// AuthoredIn reports whether obj was last written at apiVersion, according
+// to its managed fields. When an API server serves several versions of a
+// kind, every object can be read at every version; only those last written
+// at a deprecated one need to be migrated. Objects without managed fields
+// are assumed to be.
+func AuthoredIn(obj *unstructured.Unstructured, apiVersion string) bool {
+	entries := obj.GetManagedFields()
+	if len(entries) == 0 {
+		return true
+	}
+	latest := entries[0]
+	for _, e := range entries[1:] {
+		if e.Time != nil && (latest.Time == nil || latest.Time.Before(e.Time)) {
+			latest = e
+		}
+	}
+	return latest.APIVersion == apiVersion
+}
+
+func resourceName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetKind() + "/" + obj.GetName()
+	}
+	return obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
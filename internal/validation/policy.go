@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/meta-kagent/internal/query"
+)
+
+// Policy is a rule a platform team declares without writing code: a
+// JMESPath expression over the resource that holds when the resource
+// breaks the policy.
+type Policy struct {
+	ID      string   `json:"name"`
+	Summary string   `json:"description,omitempty"`
+	ForKind []string `json:"kinds,omitempty"`
+	// Severity is error or warning, error when unset.
+	Severity string `json:"severity,omitempty"`
+	// Field is the field the issue is reported on.
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+	// Deny is the JMESPath expression; a true result (anything but false,
+	// null and empty values) reports the issue. For example
+	// "metadata.labels.team == null" denies resources without a team label.
+	Deny string `json:"deny"`
+
+	expr *query.Expression
+}
+
+// policyFile is the layout of the policies file.
+type policyFile struct {
+	Policies []Policy `json:"policies"`
+}
+
+// LoadPolicies reads the policies file at path.
+func LoadPolicies(path string) ([]Validator, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read validation policies: %w", err)
+	}
+	var f policyFile
+	if err := yaml.UnmarshalStrict(raw, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse validation policies %s: %w", path, err)
+	}
+
+	rules := make([]Validator, 0, len(f.Policies))
+	for i := range f.Policies {
+		p := &f.Policies[i]
+		switch {
+		case p.ID == "" || strings.ContainsAny(p.ID, "*= ,"):
+			return nil, fmt.Errorf("policy %d: name is required and may not contain '*', '=', ',' or spaces", i)
+		case p.Message == "":
+			return nil, fmt.Errorf("policy '%s': message is required", p.ID)
+		case p.Severity == "":
+			p.Severity = SeverityError
+		case p.Severity != SeverityError && p.Severity != SeverityWarning:
+			return nil, fmt.Errorf("policy '%s': severity must be error or warning", p.ID)
+		}
+		if p.expr, err = query.Compile(p.Deny); err != nil {
+			return nil, fmt.Errorf("policy '%s': deny: %w", p.ID, err)
+		}
+		rules = append(rules, p)
+	}
+	return rules, nil
+}
+
+// Name implements Validator.
+func (p *Policy) Name() string { return p.ID }
+
+// Description implements Validator.
+func (p *Policy) Description() string {
+	if p.Summary != "" {
+		return p.Summary
+	}
+	return p.Message
+}
+
+// Kinds implements Validator.
+func (p *Policy) Kinds() []string { return p.ForKind }
+
+// Validate implements Validator.
+func (p *Policy) Validate(ctx context.Context, obj *unstructured.Unstructured, opts Options) []Issue {
+	result, err := p.expr.Search(obj.Object)
+	if err != nil {
+		return []Issue{{
+			Severity: SeverityWarning,
+			Field:    p.Field,
+			Message:  fmt.Sprintf("Policy could not be evaluated: %v", err),
+		}}
+	}
+	if !query.Truthy(result) {
+		return nil
+	}
+	return []Issue{{Severity: p.Severity, Field: p.Field, Message: p.Message}}
+}
@@ -0,0 +1,283 @@
+// Package validation runs the rules manifests are checked against. Every
+// rule has a name, applies to some kinds, and can be disabled or have its
+// severity changed per call or server-wide, so platform teams decide
+// which checks gate their changes and can add their own.
+package validation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Severities of issues. SeverityOff disables a rule.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityOff     = "off"
+)
+
+// PatchOperation is a single JSON Patch (RFC 6902) operation suggested as a
+// fix for a validation issue.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	From  string      `json:"from,omitempty"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Issue is a validation error or warning.
+type Issue struct {
+	Severity string           `json:"severity"` // "error" or "warning"
+	Field    string           `json:"field"`
+	Message  string           `json:"message"`
+	Fix      []PatchOperation `json:"fix,omitempty"`
+	// Rule names the rule that found the issue.
+	Rule string `json:"rule,omitempty"`
+}
+
+// Options are the validate_manifest options of a check.
+type Options struct {
+	// Strict enables best practice checks.
+	Strict bool
+	// CheckToolNames connects to MCP servers to check tool names.
+	CheckToolNames bool
+}
+
+// Validator is a rule resources are checked against.
+type Validator interface {
+	// Name identifies the rule in settings and issues, e.g.
+	// "agent.system-message".
+	Name() string
+	// Description says what the rule checks.
+	Description() string
+	// Kinds are the kinds the rule applies to; none means every kind.
+	Kinds() []string
+	// Validate returns the problems the rule finds in obj.
+	Validate(ctx context.Context, obj *unstructured.Unstructured, opts Options) []Issue
+}
+
+// Rule is a Validator built from a function.
+type Rule struct {
+	ID      string
+	Summary string
+	ForKind []string
+	Check   func(ctx context.Context, obj *unstructured.Unstructured, opts Options) []Issue
+}
+
+// Name implements Validator.
+func (r Rule) Name() string { return r.ID }
+
+// Description implements Validator.
+func (r Rule) Description() string { return r.Summary }
+
+// Kinds implements Validator.
+func (r Rule) Kinds() []string { return r.ForKind }
+
+// Validate implements Validator.
+func (r Rule) Validate(ctx context.Context, obj *unstructured.Unstructured, opts Options) []Issue {
+	return r.Check(ctx, obj, opts)
+}
+
+// Registry holds the rules, run in the order they were registered.
+type Registry struct {
+	rules  []Validator
+	byName map[string]Validator
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{byName: map[string]Validator{}}
+}
+
+// Register adds rules. Names must be unique.
+func (r *Registry) Register(rules ...Validator) error {
+	for _, rule := range rules {
+		if _, ok := r.byName[rule.Name()]; ok {
+			return fmt.Errorf("validation rule '%s' is registered more than once", rule.Name())
+		}
+		r.byName[rule.Name()] = rule
+		r.rules = append(r.rules, rule)
+	}
+	return nil
+}
+
+// Rules returns the registered rules in the order they run.
+func (r *Registry) Rules() []Validator {
+	return r.rules
+}
+
+// Only returns a registry of the named rules, for checks that need some of
+// them.
+func (r *Registry) Only(names ...string) *Registry {
+	only := NewRegistry()
+	for _, rule := range r.rules {
+		for _, name := range names {
+			if rule.Name() == name {
+				only.byName[name] = rule
+				only.rules = append(only.rules, rule)
+			}
+		}
+	}
+	return only
+}
+
+// Select returns a registry of the rules patterns name, as rule names or
+// prefixes ending in ".*". Patterns that select no rule are errors; the
+// rules the others select are still returned.
+func (r *Registry) Select(patterns []string) (*Registry, error) {
+	var unknown []string
+	for _, pattern := range patterns {
+		if !r.matches(pattern) {
+			unknown = append(unknown, pattern)
+		}
+	}
+
+	selected := NewRegistry()
+	for _, rule := range r.rules {
+		for _, pattern := range patterns {
+			prefix, isPrefix := strings.CutSuffix(pattern, "*")
+			if pattern == rule.Name() || (isPrefix && strings.HasPrefix(rule.Name(), prefix)) {
+				selected.byName[rule.Name()] = rule
+				selected.rules = append(selected.rules, rule)
+				break
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		return selected, fmt.Errorf("no rule is named %s", strings.Join(unknown, ", "))
+	}
+	return selected, nil
+}
+
+// Lookup returns the rule with the given name.
+func (r *Registry) Lookup(name string) (Validator, bool) {
+	rule, ok := r.byName[name]
+	return rule, ok
+}
+
+// Names returns the names of the registered rules, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.rules))
+	for _, rule := range r.rules {
+		names = append(names, rule.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate runs the rules that apply to obj's kind and are not turned off
+// in settings, with the severities settings give them.
+func (r *Registry) Validate(ctx context.Context, obj *unstructured.Unstructured, opts Options, settings Settings) []Issue {
+	var issues []Issue
+	for _, rule := range r.rules {
+		if !appliesTo(rule, obj.GetKind()) {
+			continue
+		}
+		severity := settings.Severity(rule.Name())
+		if severity == SeverityOff {
+			continue
+		}
+		for _, issue := range rule.Validate(ctx, obj, opts) {
+			issue.Rule = rule.Name()
+			if severity != "" {
+				issue.Severity = severity
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// appliesTo reports whether a rule checks resources of kind.
+func appliesTo(rule Validator, kind string) bool {
+	kinds := rule.Kinds()
+	if len(kinds) == 0 {
+		return true
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Settings override the severity of rules, by rule name or by a prefix
+// ending in ".*" such as "agent.*". An empty severity keeps the rule's own.
+type Settings map[string]string
+
+// ParseSettings reads settings written as "<rule>=<severity>", e.g.
+// "agent.description=off". A bare rule name turns it off. Entries naming
+// rules the registry does not have, or an unknown severity, are errors;
+// the valid entries are still returned.
+func (r *Registry) ParseSettings(entries []string) (Settings, error) {
+	settings := Settings{}
+	var problems []string
+	for _, entry := range entries {
+		name, severity, found := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.TrimSpace(name)
+		severity = strings.ToLower(strings.TrimSpace(severity))
+		if !found {
+			severity = SeverityOff
+		}
+		switch {
+		case name == "":
+			continue
+		case severity != SeverityError && severity != SeverityWarning && severity != SeverityOff:
+			problems = append(problems, fmt.Sprintf("'%s': severity must be error, warning or off", entry))
+		case !r.matches(name):
+			problems = append(problems, fmt.Sprintf("'%s': no rule is named %s", entry, name))
+		default:
+			settings[name] = severity
+		}
+	}
+	if len(problems) > 0 {
+		return settings, fmt.Errorf("invalid validation rule settings: %s", strings.Join(problems, "; "))
+	}
+	return settings, nil
+}
+
+// matches reports whether a rule name or prefix selects any rule.
+func (r *Registry) matches(name string) bool {
+	if prefix, ok := strings.CutSuffix(name, "*"); ok {
+		for _, rule := range r.rules {
+			if strings.HasPrefix(rule.Name(), prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	_, ok := r.byName[name]
+	return ok
+}
+
+// Severity returns the severity settings give a rule: its own entry, else
+// that of the longest matching prefix, else "".
+func (s Settings) Severity(rule string) string {
+	if severity, ok := s[rule]; ok {
+		return severity
+	}
+	best, severity := -1, ""
+	for name, sev := range s {
+		if prefix, ok := strings.CutSuffix(name, "*"); ok && strings.HasPrefix(rule, prefix) && len(prefix) > best {
+			best, severity = len(prefix), sev
+		}
+	}
+	return severity
+}
+
+// Merge returns s overridden by other.
+func (s Settings) Merge(other Settings) Settings {
+	merged := Settings{}
+	for name, severity := range s {
+		merged[name] = severity
+	}
+	for name, severity := range other {
+		merged[name] = severity
+	}
+	return merged
+}
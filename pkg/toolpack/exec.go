@@ -0,0 +1,137 @@
+package toolpack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ExecPack is a pack implemented by an external executable.
+//
+// The executable is run as "<path> describe" once at startup and must print
+// a JSON description to stdout:
+//
+//	{"name": "compliance", "tools": [{"name": "check_labels", "description": "...", "inputSchema": {...}}]}
+//
+// Each tool call runs "<path> call <tool>" with the call arguments as a JSON
+// object on stdin. The executable prints the result to stdout:
+//
+//	{"text": "...", "isError": false}
+//
+// A non-zero exit status is reported as a tool error including stderr.
+type ExecPack struct {
+	path    string
+	timeout time.Duration
+	desc    execDescription
+}
+
+type execDescription struct {
+	Name  string     `json:"name"`
+	Tools []execTool `json:"tools"`
+}
+
+type execTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+type execResult struct {
+	Text    string `json:"text"`
+	IsError bool   `json:"isError"`
+}
+
+// NewExecPack loads the description of an executable pack. timeout bounds
+// each invocation of the executable.
+func NewExecPack(ctx context.Context, path string, timeout time.Duration) (*ExecPack, error) {
+	p := &ExecPack{path: path, timeout: timeout}
+
+	out, err := p.run(ctx, nil, "describe")
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe plugin %s: %w", path, err)
+	}
+	if err := json.Unmarshal(out, &p.desc); err != nil {
+		return nil, fmt.Errorf("failed to parse description of plugin %s: %w", path, err)
+	}
+	if err := ValidateName(p.desc.Name); err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Name returns the pack name reported by the executable.
+func (p *ExecPack) Name() string {
+	return p.desc.Name
+}
+
+// Tools returns a tool for each tool in the executable's description.
+func (p *ExecPack) Tools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	for _, t := range p.desc.Tools {
+		if t.Name == "" {
+			return nil, fmt.Errorf("plugin %s: tool without a name", p.path)
+		}
+
+		schema := t.InputSchema
+		if len(schema) == 0 {
+			schema = json.RawMessage(`{"type": "object", "properties": {}}`)
+		}
+
+		name := t.Name
+		tools = append(tools, Tool{
+			Tool: mcp.NewToolWithRawSchema(name, t.Description, schema),
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return p.call(ctx, name, req.Params.Arguments), nil
+			},
+		})
+	}
+	return tools, nil
+}
+
+// call runs a tool in the executable and converts its output to a result.
+func (p *ExecPack) call(ctx context.Context, tool string, args map[string]interface{}) *mcp.CallToolResult {
+	input, err := json.Marshal(args)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode arguments: %v", err))
+	}
+
+	out, err := p.run(ctx, input, "call", tool)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Plugin %s failed: %v", p.desc.Name, err))
+	}
+
+	var result execResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Plugin %s returned invalid output: %v", p.desc.Name, err))
+	}
+	if result.IsError {
+		return mcp.NewToolResultError(result.Text)
+	}
+	return mcp.NewToolResultText(result.Text)
+}
+
+// run executes the plugin with the given arguments and stdin, returning stdout.
+func (p *ExecPack) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,85 @@
+// Package toolpack is the extension point for registering additional tool
+// packs with the meta-agent without modifying its built-in tools.
+//
+// A pack is registered at build time by a package that calls Register from
+// its init function and is blank-imported into the server binary, or at
+// runtime as an executable speaking the protocol implemented by ExecPack.
+// Every tool in a pack is exposed as "<pack>_<tool>"; the server refuses to
+// start when that name is already taken by a built-in tool or another pack.
+// Packs are only offered when the server runs in apply mode.
+package toolpack
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Tool is a tool contributed by a pack. Tool.Name is the name within the
+// pack, without the pack prefix.
+type Tool struct {
+	Tool    mcp.Tool
+	Handler server.ToolHandlerFunc
+}
+
+// Pack is a named set of tools.
+type Pack interface {
+	// Name identifies the pack and prefixes its tool names.
+	Name() string
+	// Tools returns the tools provided by the pack.
+	Tools(ctx context.Context) ([]Tool, error)
+}
+
+var validName = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+var (
+	mu    sync.Mutex
+	packs = make(map[string]Pack)
+)
+
+// Register makes a pack available to the server. It panics if the name is
+// invalid or already registered, and is intended to be called from init.
+func Register(p Pack) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := ValidateName(p.Name()); err != nil {
+		panic(err)
+	}
+	if _, dup := packs[p.Name()]; dup {
+		panic(fmt.Sprintf("toolpack: pack %q registered twice", p.Name()))
+	}
+	packs[p.Name()] = p
+}
+
+// Packs returns the registered packs sorted by name.
+func Packs() []Pack {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var result []Pack
+	for _, p := range packs {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result
+}
+
+// ValidateName checks that a pack name is lowercase letters, digits, and
+// dashes, starting with a letter.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("toolpack: invalid pack name %q (use lowercase letters, digits, and dashes)", name)
+	}
+	return nil
+}
+
+// ToolName returns the name under which a pack's tool is exposed.
+func ToolName(pack, tool string) string {
+	return pack + "_" + tool
+}
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ObjectRef is a reference to a resource by name, optionally qualified with
+// its namespace as "namespace/name". kagent resolves unqualified references
+// in the namespace of the referring resource.
+type ObjectRef struct {
+	Namespace string
+	Name      string
+}
+
+// ParseObjectRef parses "name" or "namespace/name". Unqualified references
+// get defaultNamespace.
+func ParseObjectRef(ref, defaultNamespace string) (ObjectRef, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ObjectRef{}, fmt.Errorf("reference is empty")
+	}
+
+	parts := strings.Split(ref, "/")
+	var r ObjectRef
+	switch len(parts) {
+	case 1:
+		r = ObjectRef{Namespace: defaultNamespace, Name: parts[0]}
+	case 2:
+		r = ObjectRef{Namespace: parts[0], Name: parts[1]}
+		if errs := validation.IsDNS1123Label(r.Namespace); len(errs) > 0 {
+			return ObjectRef{}, fmt.Errorf("invalid namespace '%s' in reference '%s': %s", r.Namespace, ref, strings.Join(errs, "; "))
+		}
+	default:
+		return ObjectRef{}, fmt.Errorf("invalid reference '%s': expected 'name' or 'namespace/name'", ref)
+	}
+
+	if errs := validation.IsDNS1123Subdomain(r.Name); len(errs) > 0 {
+		return ObjectRef{}, fmt.Errorf("invalid name '%s' in reference '%s': %s", r.Name, ref, strings.Join(errs, "; "))
+	}
+	return r, nil
+}
+
+// String returns the reference as "namespace/name", or just the name when
+// the namespace is empty.
+func (r ObjectRef) String() string {
+	if r.Namespace == "" {
+		return r.Name
+	}
+	return r.Namespace + "/" + r.Name
+}
@@ -18,21 +18,57 @@ type AgentSpec struct {
 	Type        string           `json:"type,omitempty"` // "Declarative" or "BYO"
 	Description string           `json:"description,omitempty"`
 	Declarative *DeclarativeSpec `json:"declarative,omitempty"`
+	BYO         *BYOSpec         `json:"byo,omitempty"`
 	A2AConfig   *A2AConfig       `json:"a2aConfig,omitempty"`
 }
 
+// BYOSpec defines an agent that runs its own container image.
+type BYOSpec struct {
+	Deployment *BYODeploymentSpec `json:"deployment,omitempty"`
+}
+
+// BYODeploymentSpec defines the container deployment for a BYO agent.
+type BYODeploymentSpec struct {
+	Image        string                `json:"image,omitempty"`
+	Cmd          string                `json:"cmd,omitempty"`
+	Args         []string              `json:"args,omitempty"`
+	Replicas     *int32                `json:"replicas,omitempty"`
+	Env          []EnvVar              `json:"env,omitempty"`
+	Resources    *ResourceRequirements `json:"resources,omitempty"`
+	Volumes      []Volume              `json:"volumes,omitempty"`
+	VolumeMounts []VolumeMount         `json:"volumeMounts,omitempty"`
+}
+
 // DeclarativeSpec defines a declarative agent configuration.
 type DeclarativeSpec struct {
-	ModelConfig   string     `json:"modelConfig,omitempty"`
-	SystemMessage string     `json:"systemMessage,omitempty"`
-	Tools         []ToolSpec `json:"tools,omitempty"`
-	A2AConfig     *A2AConfig `json:"a2aConfig,omitempty"`
+	ModelConfig   string                     `json:"modelConfig,omitempty"`
+	SystemMessage string                     `json:"systemMessage,omitempty"`
+	Tools         []ToolSpec                 `json:"tools,omitempty"`
+	A2AConfig     *A2AConfig                 `json:"a2aConfig,omitempty"`
+	Deployment    *DeclarativeDeploymentSpec `json:"deployment,omitempty"`
+}
+
+// DeclarativeDeploymentSpec customizes the Deployment of a declarative agent.
+type DeclarativeDeploymentSpec struct {
+	Replicas     *int32                `json:"replicas,omitempty"`
+	Env          []EnvVar              `json:"env,omitempty"`
+	Resources    *ResourceRequirements `json:"resources,omitempty"`
+	Volumes      []Volume              `json:"volumes,omitempty"`
+	VolumeMounts []VolumeMount         `json:"volumeMounts,omitempty"`
 }
 
 // ToolSpec defines a tool reference.
 type ToolSpec struct {
-	Type      string         `json:"type,omitempty"` // "McpServer"
-	McpServer *McpServerRef  `json:"mcpServer,omitempty"`
+	Type      string        `json:"type,omitempty"` // "McpServer" or "Agent"
+	McpServer *McpServerRef `json:"mcpServer,omitempty"`
+	Agent     *AgentRef     `json:"agent,omitempty"`
+}
+
+// AgentRef references another agent used as a tool over A2A.
+type AgentRef struct {
+	Name     string `json:"name,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	APIGroup string `json:"apiGroup,omitempty"`
 }
 
 // McpServerRef references an MCP server and its tools.
@@ -89,11 +125,23 @@ type AgentCapabilities struct {
 
 // SecurityScheme defines an authentication method (per A2A spec).
 type SecurityScheme struct {
-	Type        string `json:"type,omitempty"`   // "apiKey", "http", "oauth2"
-	In          string `json:"in,omitempty"`     // "header", "query" (for apiKey)
-	Name        string `json:"name,omitempty"`   // Header/param name
-	Scheme      string `json:"scheme,omitempty"` // "bearer", "basic" (for http)
-	Description string `json:"description,omitempty"`
+	Type        string      `json:"type,omitempty"`   // "apiKey", "http", "oauth2", "mutualTLS"
+	In          string      `json:"in,omitempty"`     // "header", "query" (for apiKey)
+	Name        string      `json:"name,omitempty"`   // Header/param name
+	Scheme      string      `json:"scheme,omitempty"` // "bearer", "basic" (for http)
+	Flows       *OAuthFlows `json:"flows,omitempty"`  // for oauth2
+	Description string      `json:"description,omitempty"`
+}
+
+// OAuthFlows describes the OAuth2 flows an agent accepts.
+type OAuthFlows struct {
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+}
+
+// OAuthFlow describes a single OAuth2 flow.
+type OAuthFlow struct {
+	TokenURL string            `json:"tokenUrl"`
+	Scopes   map[string]string `json:"scopes"`
 }
 
 // AgentStatus defines the observed state of an Agent.
@@ -148,16 +196,62 @@ type ModelConfig struct {
 
 // ModelConfigSpec defines the desired state of a ModelConfig.
 type ModelConfigSpec struct {
-	Provider        string                 `json:"provider,omitempty"` // "OpenAI", "AzureOpenAI", "Anthropic", "Gemini", "Ollama", "Custom"
-	Model           string                 `json:"model,omitempty"`
-	APIKeySecret    string                 `json:"apiKeySecret,omitempty"`
-	APIKeySecretKey string                 `json:"apiKeySecretKey,omitempty"`
-	BaseURL         string                 `json:"baseUrl,omitempty"`
-	OpenAI          map[string]interface{} `json:"openai,omitempty"`
-	Anthropic       map[string]interface{} `json:"anthropic,omitempty"`
-	Gemini          map[string]interface{} `json:"gemini,omitempty"`
-	Azure           map[string]interface{} `json:"azure,omitempty"`
-	Ollama          map[string]interface{} `json:"ollama,omitempty"`
+	Provider        string             `json:"provider,omitempty"` // "OpenAI", "AzureOpenAI", "Anthropic", "Gemini", "Ollama", "Custom"
+	Model           string             `json:"model,omitempty"`
+	APIKeySecret    string             `json:"apiKeySecret,omitempty"`
+	APIKeySecretKey string             `json:"apiKeySecretKey,omitempty"`
+	BaseURL         string             `json:"baseUrl,omitempty"`
+	OpenAI          *OpenAIConfig      `json:"openai,omitempty"`
+	Anthropic       *AnthropicConfig   `json:"anthropic,omitempty"`
+	Gemini          *GeminiConfig      `json:"gemini,omitempty"`
+	Azure           *AzureOpenAIConfig `json:"azure,omitempty"`
+	Ollama          *OllamaConfig      `json:"ollama,omitempty"`
+}
+
+// OpenAIConfig holds the OpenAI provider settings. Sampling parameters are
+// decimal strings, since CRDs do not allow floats.
+type OpenAIConfig struct {
+	Organization     string `json:"organization,omitempty"`
+	Temperature      string `json:"temperature,omitempty"`      // 0 to 2
+	TopP             string `json:"topP,omitempty"`             // 0 to 1
+	FrequencyPenalty string `json:"frequencyPenalty,omitempty"` // -2 to 2
+	PresencePenalty  string `json:"presencePenalty,omitempty"`  // -2 to 2
+	MaxTokens        int    `json:"maxTokens,omitempty"`
+	Seed             *int   `json:"seed,omitempty"`
+	N                *int   `json:"n,omitempty"`
+	Timeout          *int   `json:"timeout,omitempty"`         // seconds
+	ReasoningEffort  string `json:"reasoningEffort,omitempty"` // "minimal", "low", "medium", "high"
+}
+
+// AnthropicConfig holds the Anthropic provider settings.
+type AnthropicConfig struct {
+	Temperature string `json:"temperature,omitempty"` // 0 to 1
+	TopP        string `json:"topP,omitempty"`        // 0 to 1
+	TopK        int    `json:"topK,omitempty"`
+	MaxTokens   int    `json:"maxTokens,omitempty"`
+}
+
+// GeminiConfig holds the Gemini provider settings. It has none yet; its
+// presence selects the provider's defaults.
+type GeminiConfig struct{}
+
+// AzureOpenAIConfig holds the Azure OpenAI provider settings. Endpoint and
+// APIVersion are required.
+type AzureOpenAIConfig struct {
+	Endpoint       string `json:"azureEndpoint"`
+	APIVersion     string `json:"apiVersion"`
+	DeploymentName string `json:"azureDeployment,omitempty"`
+	AzureADToken   string `json:"azureAdToken,omitempty"`
+	Temperature    string `json:"temperature,omitempty"` // 0 to 2
+	TopP           string `json:"topP,omitempty"`        // 0 to 1
+	MaxTokens      int    `json:"maxTokens,omitempty"`
+}
+
+// OllamaConfig holds the Ollama provider settings. Options are passed to
+// the model as is (e.g. num_ctx).
+type OllamaConfig struct {
+	Host    string            `json:"host,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
 }
 
 // ModelConfigList contains a list of ModelConfigs.
@@ -179,23 +273,98 @@ type MCPServerSpec struct {
 	Description    string          `json:"description,omitempty"`
 	Deployment     *DeploymentSpec `json:"deployment,omitempty"`
 	TransportType  string          `json:"transportType,omitempty"` // "stdio"
-	StdioTransport map[string]interface{} `json:"stdioTransport,omitempty"`
+	StdioTransport *StdioTransport `json:"stdioTransport,omitempty"`
 }
 
+// StdioTransport configures the stdio transport of an MCPServer. It has no
+// settings; it must be present (as {}) when transportType is stdio.
+type StdioTransport struct{}
+
 // DeploymentSpec defines the container deployment for an MCPServer.
 type DeploymentSpec struct {
-	Image     string            `json:"image,omitempty"`
-	Cmd       string            `json:"cmd,omitempty"`
-	Args      []string          `json:"args,omitempty"`
-	Port      int32             `json:"port,omitempty"`
-	Env       []EnvVar          `json:"env,omitempty"`
-	Resources *ResourceRequirements `json:"resources,omitempty"`
+	Image          string                `json:"image,omitempty"`
+	Cmd            string                `json:"cmd,omitempty"`
+	Args           []string              `json:"args,omitempty"`
+	Port           int32                 `json:"port,omitempty"`
+	Env            []EnvVar              `json:"env,omitempty"`
+	Resources      *ResourceRequirements `json:"resources,omitempty"`
+	VolumeMounts   []VolumeMount         `json:"volumeMounts,omitempty"`
+	Volumes        []Volume              `json:"volumes,omitempty"`
+	InitContainers []Container           `json:"initContainers,omitempty"`
+	Sidecars       []Container           `json:"sidecars,omitempty"`
+}
+
+// Container defines an init container or sidecar running next to the MCP
+// server container.
+type Container struct {
+	Name         string                `json:"name"`
+	Image        string                `json:"image"`
+	Command      []string              `json:"command,omitempty"`
+	Args         []string              `json:"args,omitempty"`
+	Env          []EnvVar              `json:"env,omitempty"`
+	Ports        []ContainerPort       `json:"ports,omitempty"`
+	VolumeMounts []VolumeMount         `json:"volumeMounts,omitempty"`
+	Resources    *ResourceRequirements `json:"resources,omitempty"`
+}
+
+// ContainerPort defines a port exposed by a container.
+type ContainerPort struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int32  `json:"containerPort"`
+}
+
+// Volume defines a pod volume. Exactly one source should be set.
+type Volume struct {
+	Name      string           `json:"name"`
+	ConfigMap *ConfigMapVolume `json:"configMap,omitempty"`
+	Secret    *SecretVolume    `json:"secret,omitempty"`
+	EmptyDir  *EmptyDirVolume  `json:"emptyDir,omitempty"`
+}
+
+// ConfigMapVolume mounts the keys of a ConfigMap as files.
+type ConfigMapVolume struct {
+	Name string `json:"name"`
+}
+
+// SecretVolume mounts the keys of a Secret as files.
+type SecretVolume struct {
+	SecretName string `json:"secretName"`
+}
+
+// EmptyDirVolume is scratch space shared by the pod's containers.
+type EmptyDirVolume struct {
+	Medium    string `json:"medium,omitempty"`
+	SizeLimit string `json:"sizeLimit,omitempty"`
+}
+
+// VolumeMount mounts a volume into a container.
+type VolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+	SubPath   string `json:"subPath,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
 }
 
 // EnvVar defines an environment variable.
 type EnvVar struct {
-	Name  string `json:"name,omitempty"`
-	Value string `json:"value,omitempty"`
+	Name      string        `json:"name,omitempty"`
+	Value     string        `json:"value,omitempty"`
+	ValueFrom *EnvVarSource `json:"valueFrom,omitempty"`
+}
+
+// EnvVarSource references the source of an environment variable's value.
+type EnvVarSource struct {
+	SecretKeyRef    *KeySelector `json:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *KeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// KeySelector selects a key of a Secret or ConfigMap.
+type KeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	// Optional lets the pod start when the Secret or ConfigMap, or the key,
+	// does not exist.
+	Optional *bool `json:"optional,omitempty"`
 }
 
 // ResourceRequirements defines resource requests and limits.